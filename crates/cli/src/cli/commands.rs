@@ -72,6 +72,28 @@ pub enum Commands {
                       peelbox build --spec spec.json --tag app:latest --output type=oci,dest=app.tar"
     )]
     Build(BuildArgs),
+
+    #[command(
+        about = "Compare two detection results",
+        long_about = "Compares two detection outputs (JSON from `peelbox detect`) and prints the \
+                      fields that were added, removed or changed as JSON.\n\
+                      Exits with 0 when the results match, 1 when they differ and 2 on errors.\n\n\
+                      Examples:\n  \
+                      peelbox diff before.json after.json"
+    )]
+    Diff(DiffArgs),
+
+    #[command(
+        about = "Generate a starter project",
+        long_about = "Writes a minimal project that peelbox detects as the given language, build \
+                      system and framework, or as the detection result in --spec, with a \
+                      .gitignore. The project builds and starts with the detected commands.\n\n\
+                      Examples:\n  \
+                      peelbox init --language go --framework gin --build-system modules --name myapp\n  \
+                      peelbox init --language python --framework flask --name api ./api\n  \
+                      peelbox init --spec universalbuild.json"
+    )]
+    Init(InitArgs),
 }
 
 #[derive(Parser, Debug, Clone)]
@@ -121,6 +143,14 @@ pub struct DetectArgs {
     #[arg(long, help = "Disable result caching")]
     pub no_cache: bool,
 
+    #[arg(
+        long,
+        value_name = "DIR",
+        conflicts_with = "no_cache",
+        help = "Cache detection results in DIR, keyed by the scanned files' contents, and answer unchanged repositories from it"
+    )]
+    pub cache_dir: Option<PathBuf>,
+
     #[arg(
         short = 'o',
         long,
@@ -128,6 +158,94 @@ pub struct DetectArgs {
         help = "Write output to file instead of stdout"
     )]
     pub output: Option<PathBuf>,
+
+    #[arg(
+        long,
+        value_name = "DIR",
+        conflicts_with_all = ["output", "sbom", "template", "watch", "serve"],
+        help = "Write each detected service's JSON result to DIR/<service path>/result.json and all of them to DIR/aggregate.json"
+    )]
+    pub output_dir: Option<PathBuf>,
+
+    #[arg(
+        long,
+        requires = "output_dir",
+        help = "Write into an existing --output-dir"
+    )]
+    pub force: bool,
+
+    #[arg(
+        long,
+        help = "Output a CycloneDX SBOM of the detected services instead of the detection result"
+    )]
+    pub sbom: bool,
+
+    #[arg(
+        long,
+        value_name = "TEMPLATE",
+        conflicts_with = "sbom",
+        help = "Render each detected service with a Go-style text template instead of --format; pass the template text or @file.tmpl"
+    )]
+    pub template: Option<String>,
+
+    #[arg(
+        long,
+        help = "Keep running and re-run detection when manifests or configuration files change"
+    )]
+    pub watch: bool,
+
+    #[arg(
+        long,
+        value_name = "LEVELS",
+        default_value = "10",
+        help = "Deepest directory level to scan below the repository root (0 = unlimited)"
+    )]
+    pub max_depth: usize,
+
+    #[arg(
+        long,
+        value_name = "ADDR",
+        conflicts_with_all = ["watch", "sbom", "template"],
+        help = "Serve detection over HTTP at ADDR (e.g. :8080) instead of scanning PATH"
+    )]
+    pub serve: Option<String>,
+
+    #[arg(
+        long,
+        value_name = "SECONDS",
+        default_value = "30",
+        help = "Longest a --serve request may take before it times out"
+    )]
+    pub serve_timeout: u64,
+
+    #[arg(
+        long = "gitlab-host",
+        value_name = "HOST",
+        help = "Self-hosted GitLab instance --serve may scan besides github.com and gitlab.com (repeatable)"
+    )]
+    pub gitlab_hosts: Vec<String>,
+
+    #[arg(
+        long,
+        value_name = "TOKEN",
+        requires = "serve",
+        help = "API token --serve sends to GitHub or GitLab for remote scans (defaults to GITHUB_TOKEN or GITLAB_TOKEN, by host)"
+    )]
+    pub auth_token: Option<String>,
+
+    #[arg(
+        long,
+        conflicts_with = "serve",
+        help = "Look up newer releases of Go services' direct dependencies on the module proxy (needs network access; honours GOPROXY)"
+    )]
+    pub check_upgrades: bool,
+
+    #[arg(
+        long,
+        conflicts_with = "serve",
+        help = "Suggest saving `go mod graph` output for Go services without a go_mod_graph.txt, so the depth of their dependency tree can be measured"
+    )]
+    pub dependency_depth: bool,
 }
 
 #[derive(Parser, Debug, Clone)]
@@ -252,10 +370,67 @@ pub struct BuildArgs {
     pub cache_to: Vec<String>,
 }
 
+#[derive(Parser, Debug, Clone)]
+pub struct DiffArgs {
+    #[arg(value_name = "BEFORE", help = "Previous detection result (JSON)")]
+    pub before: PathBuf,
+
+    #[arg(value_name = "AFTER", help = "Current detection result (JSON)")]
+    pub after: PathBuf,
+}
+
+#[derive(Parser, Debug, Clone)]
+pub struct InitArgs {
+    #[arg(
+        value_name = "PATH",
+        help = "Directory to generate the project in (defaults to current directory)"
+    )]
+    pub target_dir: Option<PathBuf>,
+
+    #[arg(
+        long,
+        value_name = "LANGUAGE",
+        required_unless_present = "spec",
+        help = "Language of the project (e.g. go, rust, python, javascript)"
+    )]
+    pub language: Option<String>,
+
+    #[arg(
+        long,
+        value_name = "FRAMEWORK",
+        help = "Framework to build on (e.g. gin, actix, flask)"
+    )]
+    pub framework: Option<String>,
+
+    #[arg(
+        long,
+        value_name = "BUILD_SYSTEM",
+        help = "Build system (e.g. modules, cargo, pip, npm; defaults to the language's)"
+    )]
+    pub build_system: Option<String>,
+
+    #[arg(
+        long,
+        value_name = "NAME",
+        required_unless_present = "spec",
+        help = "Project name, used for the module, crate or package and the binary"
+    )]
+    pub name: Option<String>,
+
+    #[arg(
+        long,
+        value_name = "FILE",
+        conflicts_with_all = ["language", "framework", "build_system"],
+        help = "Generate the project a detection result (JSON from `peelbox detect`) describes"
+    )]
+    pub spec: Option<PathBuf>,
+}
+
 #[derive(ValueEnum, Debug, Clone, Copy, PartialEq, Eq)]
 pub enum OutputFormatArg {
     Json,
     Yaml,
+    Toml,
 }
 
 impl From<OutputFormatArg> for super::output::OutputFormat {
@@ -263,6 +438,7 @@ impl From<OutputFormatArg> for super::output::OutputFormat {
         match arg {
             OutputFormatArg::Json => super::output::OutputFormat::Json,
             OutputFormatArg::Yaml => super::output::OutputFormat::Yaml,
+            OutputFormatArg::Toml => super::output::OutputFormat::Toml,
         }
     }
 }
@@ -297,7 +473,17 @@ mod tests {
                 assert_eq!(detect_args.timeout, 60);
                 assert!(!detect_args.verbose_output);
                 assert!(!detect_args.no_cache);
+                assert!(detect_args.cache_dir.is_none());
                 assert!(detect_args.repository_path.is_none());
+                assert!(!detect_args.sbom);
+                assert!(!detect_args.watch);
+                assert_eq!(detect_args.max_depth, 10);
+                assert!(detect_args.serve.is_none());
+                assert_eq!(detect_args.serve_timeout, 30);
+                assert!(!detect_args.check_upgrades);
+                assert!(!detect_args.dependency_depth);
+                assert!(detect_args.output_dir.is_none());
+                assert!(!detect_args.force);
             }
             _ => panic!("Expected Detect command"),
         }
@@ -332,6 +518,9 @@ mod tests {
             "120",
             "--verbose-output",
             "--no-cache",
+            "--sbom",
+            "--max-depth",
+            "0",
         ]);
 
         match args.command {
@@ -342,11 +531,123 @@ mod tests {
                 assert_eq!(detect_args.timeout, 120);
                 assert!(detect_args.verbose_output);
                 assert!(detect_args.no_cache);
+                assert!(detect_args.sbom);
+                assert_eq!(detect_args.max_depth, 0);
             }
             _ => panic!("Expected Detect command"),
         }
     }
 
+    #[test]
+    fn test_detect_cache_dir() {
+        let args = CliArgs::parse_from(["peelbox", "detect", "--cache-dir", ".peelbox-cache"]);
+        match args.command {
+            Commands::Detect(detect_args) => {
+                assert_eq!(detect_args.cache_dir, Some(PathBuf::from(".peelbox-cache")));
+            }
+            _ => panic!("Expected Detect command"),
+        }
+
+        assert!(CliArgs::try_parse_from([
+            "peelbox",
+            "detect",
+            "--cache-dir",
+            ".peelbox-cache",
+            "--no-cache"
+        ])
+        .is_err());
+    }
+
+    #[test]
+    fn test_detect_check_upgrades() {
+        let args = CliArgs::parse_from(["peelbox", "detect", "--check-upgrades"]);
+        match args.command {
+            Commands::Detect(detect_args) => assert!(detect_args.check_upgrades),
+            _ => panic!("Expected Detect command"),
+        }
+    }
+
+    #[test]
+    fn test_detect_dependency_depth() {
+        let args = CliArgs::parse_from(["peelbox", "detect", "--dependency-depth"]);
+        match args.command {
+            Commands::Detect(detect_args) => assert!(detect_args.dependency_depth),
+            _ => panic!("Expected Detect command"),
+        }
+    }
+
+    #[test]
+    fn test_detect_serve() {
+        let args = CliArgs::parse_from(["peelbox", "detect", "--serve", ":8080"]);
+        match args.command {
+            Commands::Detect(detect_args) => {
+                assert_eq!(detect_args.serve.as_deref(), Some(":8080"));
+                assert!(detect_args.gitlab_hosts.is_empty());
+                assert!(detect_args.auth_token.is_none());
+            }
+            _ => panic!("Expected Detect command"),
+        }
+
+        let args = CliArgs::parse_from([
+            "peelbox",
+            "detect",
+            "--serve",
+            ":8080",
+            "--gitlab-host",
+            "gitlab.acme.dev",
+            "--gitlab-host",
+            "git.acme.dev",
+            "--auth-token",
+            "glpat-secret",
+        ]);
+        match args.command {
+            Commands::Detect(detect_args) => {
+                assert_eq!(
+                    detect_args.gitlab_hosts,
+                    ["gitlab.acme.dev", "git.acme.dev"]
+                );
+                assert_eq!(detect_args.auth_token.as_deref(), Some("glpat-secret"));
+            }
+            _ => panic!("Expected Detect command"),
+        }
+
+        assert!(
+            CliArgs::try_parse_from(["peelbox", "detect", "--serve", ":8080", "--watch"]).is_err()
+        );
+        assert!(CliArgs::try_parse_from(["peelbox", "detect", "--auth-token", "secret"]).is_err());
+        assert!(CliArgs::try_parse_from([
+            "peelbox",
+            "detect",
+            "--serve",
+            ":8080",
+            "--check-upgrades"
+        ])
+        .is_err());
+    }
+
+    #[test]
+    fn test_detect_output_dir() {
+        let args = CliArgs::parse_from(["peelbox", "detect", "--output-dir", "out", "--force"]);
+        match args.command {
+            Commands::Detect(detect_args) => {
+                assert_eq!(detect_args.output_dir, Some(PathBuf::from("out")));
+                assert!(detect_args.force);
+            }
+            _ => panic!("Expected Detect command"),
+        }
+
+        assert!(CliArgs::try_parse_from(["peelbox", "detect", "--force"]).is_err());
+        assert!(CliArgs::try_parse_from([
+            "peelbox",
+            "detect",
+            "--output-dir",
+            "out",
+            "--output",
+            "out.json"
+        ])
+        .is_err());
+    }
+
     #[test]
     fn test_health_command() {
         let args = CliArgs::parse_from(["peelbox", "health"]);
@@ -370,6 +671,57 @@ mod tests {
         }
     }
 
+    #[test]
+    fn test_diff_command() {
+        let args = CliArgs::parse_from(["peelbox", "diff", "before.json", "after.json"]);
+        match args.command {
+            Commands::Diff(diff_args) => {
+                assert_eq!(diff_args.before, PathBuf::from("before.json"));
+                assert_eq!(diff_args.after, PathBuf::from("after.json"));
+            }
+            _ => panic!("Expected Diff command"),
+        }
+    }
+
+    #[test]
+    fn test_init_command() {
+        let args = CliArgs::parse_from([
+            "peelbox",
+            "init",
+            "--language",
+            "go",
+            "--framework",
+            "gin",
+            "--build-system",
+            "modules",
+            "--name",
+            "myapp",
+        ]);
+        match args.command {
+            Commands::Init(init_args) => {
+                assert_eq!(init_args.language.as_deref(), Some("go"));
+                assert_eq!(init_args.framework.as_deref(), Some("gin"));
+                assert_eq!(init_args.build_system.as_deref(), Some("modules"));
+                assert_eq!(init_args.name.as_deref(), Some("myapp"));
+                assert!(init_args.target_dir.is_none());
+                assert!(init_args.spec.is_none());
+            }
+            _ => panic!("Expected Init command"),
+        }
+
+        assert!(CliArgs::try_parse_from(["peelbox", "init", "--language", "go"]).is_err());
+        assert!(CliArgs::try_parse_from(["peelbox", "init", "--spec", "spec.json"]).is_ok());
+        assert!(CliArgs::try_parse_from([
+            "peelbox",
+            "init",
+            "--spec",
+            "spec.json",
+            "--language",
+            "go"
+        ])
+        .is_err());
+    }
+
     #[test]
     fn test_global_verbose_flag() {
         let args = CliArgs::parse_from(["peelbox", "-v", "detect"]);
@@ -1,5 +1,10 @@
 pub mod commands;
 pub mod output;
+pub mod output_dir;
+pub mod scaffold;
+pub mod serve;
+pub mod template;
+pub mod upgrades;
 
-pub use commands::{BuildArgs, CliArgs, Commands, DetectArgs, HealthArgs};
+pub use commands::{BuildArgs, CliArgs, Commands, DetectArgs, HealthArgs, InitArgs};
 pub use output::{OutputFormat, OutputFormatter};
@@ -9,6 +9,19 @@ use peelbox_core::output::schema::UniversalBuild;
 pub enum OutputFormat {
     Json,
     Yaml,
+    Toml,
+}
+
+/// TOML has no top-level arrays, so multiple builds go under a `[[builds]]` table array
+#[derive(serde::Serialize, serde::Deserialize)]
+pub struct TomlBuilds {
+    pub builds: Vec<UniversalBuild>,
+}
+
+#[derive(serde::Serialize)]
+struct HealthReport<'a> {
+    health_status: &'a HashMap<String, HealthStatus>,
+    environment_variables: &'a HashMap<String, Vec<EnvVarInfo>>,
 }
 
 pub struct OutputFormatter {
@@ -30,6 +43,7 @@ impl OutputFormatter {
                     .context("Failed to serialize UniversalBuild to JSON")
             }
             OutputFormat::Yaml => result.to_yaml(),
+            OutputFormat::Toml => result.to_toml(),
         }
     }
 
@@ -44,6 +58,10 @@ impl OutputFormatter {
             }
             OutputFormat::Yaml => serde_yaml::to_string(results)
                 .context("Failed to serialize UniversalBuild array to YAML"),
+            OutputFormat::Toml => toml::to_string_pretty(&TomlBuilds {
+                builds: results.to_vec(),
+            })
+            .context("Failed to serialize UniversalBuild array to TOML"),
         }
     }
 
@@ -71,6 +89,8 @@ impl OutputFormatter {
                 .context("Failed to serialize health status to JSON"),
             OutputFormat::Yaml => serde_yaml::to_string(health_results)
                 .context("Failed to serialize health status to YAML"),
+            OutputFormat::Toml => toml::to_string_pretty(health_results)
+                .context("Failed to serialize health status to TOML"),
         }
     }
 
@@ -82,6 +102,11 @@ impl OutputFormatter {
         match self.format {
             OutputFormat::Json => self.format_health_with_env_vars_json(health_results, env_vars),
             OutputFormat::Yaml => self.format_health_with_env_vars_yaml(health_results, env_vars),
+            OutputFormat::Toml => toml::to_string_pretty(&HealthReport {
+                health_status: health_results,
+                environment_variables: env_vars,
+            })
+            .context("Failed to serialize health status with env vars to TOML"),
         }
     }
 
@@ -167,7 +192,13 @@ mod tests {
                 build_system: "cargo".to_string(),
                 framework: None,
                 reasoning: "Detected Cargo.toml with standard Rust project structure".to_string(),
+                ..Default::default()
             },
+            confidence: Default::default(),
+            conflicts: vec![],
+            warnings: vec![],
+            suggestions: vec![],
+            validation_errors: vec![],
             build: BuildStage {
                 packages: vec!["rust".to_string(), "build-base".to_string()],
                 env: HashMap::new(),
@@ -184,6 +215,7 @@ mod tests {
                 command: vec!["/usr/local/bin/app".to_string()],
                 ports: vec![],
                 health: None,
+                ..Default::default()
             },
         }
     }
@@ -214,6 +246,60 @@ mod tests {
         let _parsed: UniversalBuild = serde_yaml::from_str(&output).unwrap();
     }
 
+    #[test]
+    fn test_toml_format() {
+        let result = create_test_result();
+        let formatter = OutputFormatter::new(OutputFormat::Toml);
+        let output = formatter.format(&result).unwrap();
+
+        assert!(output.contains("[metadata]"));
+        assert!(output.contains("cargo"));
+
+        // Verify it's valid TOML
+        let _parsed: UniversalBuild = toml::from_str(&output).unwrap();
+    }
+
+    #[test]
+    fn test_yaml_multiline_commands_use_block_scalars() {
+        let mut result = create_test_result();
+        result.build.commands = vec!["cargo build --release \\\n  --locked".to_string()];
+        let output = OutputFormatter::new(OutputFormat::Yaml)
+            .format(&result)
+            .unwrap();
+
+        assert!(output.contains("- |-\n"));
+    }
+
+    #[test]
+    fn test_round_trip_preserves_structure() {
+        let mut second = create_test_result();
+        second.metadata.project_name = Some("worker".to_string());
+        second
+            .runtime
+            .env
+            .insert("RUST_LOG".to_string(), "info".to_string());
+        second.runtime.ports = vec![8080];
+        let results = vec![create_test_result(), second];
+        let expected = serde_json::to_value(&results).unwrap();
+
+        for format in [OutputFormat::Json, OutputFormat::Yaml, OutputFormat::Toml] {
+            let output = OutputFormatter::new(format)
+                .format_multiple(&results)
+                .unwrap();
+            let parsed: Vec<UniversalBuild> = match format {
+                OutputFormat::Json => serde_json::from_str(&output).unwrap(),
+                OutputFormat::Yaml => serde_yaml::from_str(&output).unwrap(),
+                OutputFormat::Toml => toml::from_str::<TomlBuilds>(&output).unwrap().builds,
+            };
+            assert_eq!(
+                serde_json::to_value(&parsed).unwrap(),
+                expected,
+                "{:?} round trip changed the result",
+                format
+            );
+        }
+    }
+
     #[test]
     fn test_health_status_creation() {
         let status = HealthStatus::available("Ollama is running".to_string());
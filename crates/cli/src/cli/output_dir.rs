@@ -0,0 +1,191 @@
+//! `detect --output-dir` - one result file per detected service, laid out like the repository,
+//! next to the aggregate that `detect` otherwise prints
+
+use super::output::{OutputFormat, OutputFormatter};
+use anyhow::{bail, Context, Result};
+use peelbox_core::output::schema::UniversalBuild;
+use std::collections::BTreeMap;
+use std::path::{Component, Path, PathBuf};
+
+pub const RESULT_FILE: &str = "result.json";
+pub const AGGREGATE_FILE: &str = "aggregate.json";
+
+/// Writes `<dir>/<service path>/result.json` for each service and every result to
+/// `<dir>/aggregate.json`, returning the files written relative to `dir`
+///
+/// A service's file holds its result, or an array when several share the directory. `dir` is
+/// created and must not exist yet unless `force`, which overwrites the files in place. Every
+/// file is rendered before any is written, and each has a path of its own.
+pub fn write_output_dir(
+    results: &[UniversalBuild],
+    dir: &Path,
+    force: bool,
+) -> Result<Vec<PathBuf>> {
+    if dir.exists() && !force {
+        bail!(
+            "{} already exists; pass --force to write into it",
+            dir.display()
+        );
+    }
+
+    let mut services: BTreeMap<PathBuf, Vec<UniversalBuild>> = BTreeMap::new();
+    for result in results {
+        services
+            .entry(service_dir(result)?)
+            .or_default()
+            .push(result.clone());
+    }
+
+    let formatter = OutputFormatter::new(OutputFormat::Json);
+    let mut files = Vec::new();
+    for (service_dir, builds) in &services {
+        let content = match builds.as_slice() {
+            [build] => formatter.format(build)?,
+            _ => formatter.format_multiple(builds)?,
+        };
+        files.push((service_dir.join(RESULT_FILE), content));
+    }
+    files.push((
+        PathBuf::from(AGGREGATE_FILE),
+        formatter.format_multiple(results)?,
+    ));
+
+    let mut written = Vec::new();
+    for (path, content) in files {
+        let target = dir.join(&path);
+        if let Some(parent) = target.parent() {
+            std::fs::create_dir_all(parent)
+                .with_context(|| format!("Failed to create {}", parent.display()))?;
+        }
+        std::fs::write(&target, content)
+            .with_context(|| format!("Failed to write {}", target.display()))?;
+        written.push(path);
+    }
+    Ok(written)
+}
+
+/// The service's directory relative to the repository root, empty for the root itself
+fn service_dir(result: &UniversalBuild) -> Result<PathBuf> {
+    let service_path = Path::new(result.metadata.service_path.as_deref().unwrap_or("."));
+    let mut dir = PathBuf::new();
+    for component in service_path.components() {
+        match component {
+            Component::Normal(part) => dir.push(part),
+            Component::CurDir => {}
+            _ => bail!(
+                "Service path {} is outside the repository",
+                service_path.display()
+            ),
+        }
+    }
+    Ok(dir)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::output::schema::{BuildMetadata, BuildStage, RuntimeStage};
+    use tempfile::TempDir;
+
+    fn build(name: &str, service_path: &str) -> UniversalBuild {
+        UniversalBuild {
+            version: "1.0".to_string(),
+            metadata: BuildMetadata {
+                project_name: Some(name.to_string()),
+                language: "Go".to_string(),
+                build_system: "go mod".to_string(),
+                service_path: Some(service_path.to_string()),
+                ..Default::default()
+            },
+            confidence: Default::default(),
+            conflicts: vec![],
+            warnings: vec![],
+            suggestions: vec![],
+            validation_errors: vec![],
+            build: BuildStage::default(),
+            runtime: RuntimeStage::default(),
+        }
+    }
+
+    fn read(path: PathBuf) -> serde_json::Value {
+        serde_json::from_str(&std::fs::read_to_string(path).unwrap()).unwrap()
+    }
+
+    #[test]
+    fn test_writes_each_service_and_the_aggregate() {
+        let temp = TempDir::new().unwrap();
+        let dir = temp.path().join("out");
+        let results = [
+            build("api", "services/api"),
+            build("worker", "services/worker"),
+            build("tool", "."),
+        ];
+
+        let written = write_output_dir(&results, &dir, false).unwrap();
+        assert_eq!(
+            written,
+            [
+                "result.json",
+                "services/api/result.json",
+                "services/worker/result.json",
+                "aggregate.json",
+            ]
+            .map(PathBuf::from)
+        );
+        assert_eq!(
+            read(dir.join("services/api/result.json"))["metadata"]["project_name"],
+            "api"
+        );
+        assert_eq!(
+            read(dir.join("result.json"))["metadata"]["project_name"],
+            "tool"
+        );
+        assert_eq!(
+            read(dir.join("aggregate.json")).as_array().unwrap().len(),
+            3
+        );
+    }
+
+    #[test]
+    fn test_services_sharing_a_directory() {
+        let temp = TempDir::new().unwrap();
+        let results = [build("api", "app"), build("web", "app")];
+
+        write_output_dir(&results, temp.path(), true).unwrap();
+        let shared = read(temp.path().join("app/result.json"));
+        assert_eq!(shared.as_array().unwrap().len(), 2);
+    }
+
+    #[test]
+    fn test_existing_directory_needs_force() {
+        let temp = TempDir::new().unwrap();
+        let results = [build("api", ".")];
+
+        let error = write_output_dir(&results, temp.path(), false)
+            .unwrap_err()
+            .to_string();
+        assert!(error.contains("pass --force"), "{}", error);
+        assert!(!temp.path().join("aggregate.json").exists());
+
+        std::fs::write(temp.path().join("result.json"), "stale").unwrap();
+        write_output_dir(&results, temp.path(), true).unwrap();
+        assert_eq!(
+            read(temp.path().join("result.json"))["metadata"]["project_name"],
+            "api"
+        );
+    }
+
+    #[test]
+    fn test_rejects_paths_outside_the_repository() {
+        let temp = TempDir::new().unwrap();
+        for service_path in ["../elsewhere", "/etc"] {
+            let results = [build("api", service_path)];
+            assert!(
+                write_output_dir(&results, &temp.path().join("out"), false).is_err(),
+                "{}",
+                service_path
+            );
+        }
+        assert!(!temp.path().join("out").exists());
+    }
+}
@@ -0,0 +1,378 @@
+//! Project scaffolding - the inverse of detection: writes a minimal project that detects as a
+//! given result
+//!
+//! Each supported stack embeds its files as templates under `scaffold/`, rendered with the
+//! `--template` engine against the spec, so `{{ .metadata.project_name }}` names the module,
+//! crate or package. A generated project builds and starts with the commands detection emits
+//! for it.
+
+use super::template::render_template;
+use anyhow::{anyhow, bail, Context, Result};
+use peelbox_core::output::schema::{BuildMetadata, BuildStage, RuntimeStage, UniversalBuild};
+use std::path::{Path, PathBuf};
+
+/// A stack `peelbox init` generates, named the way detection reports it
+pub struct Template {
+    pub language: &'static str,
+    pub build_system: &'static str,
+    pub framework: Option<&'static str>,
+    /// Other accepted spellings of the language, build system or framework, e.g. "golang" or
+    /// "modules"
+    aliases: &'static [&'static str],
+    /// Port the generated server listens on
+    pub port: u16,
+    /// Output path relative to the target directory, and its template
+    files: &'static [(&'static str, &'static str)],
+}
+
+pub const TEMPLATES: [Template; 4] = [
+    Template {
+        language: "Go",
+        build_system: "go mod",
+        framework: Some("Gin"),
+        aliases: &["golang", "modules", "gomod"],
+        port: 8080,
+        files: &[
+            ("go.mod", include_str!("scaffold/go-gin/go.mod.tmpl")),
+            ("go.sum", include_str!("scaffold/go-gin/go.sum.tmpl")),
+            ("main.go", include_str!("scaffold/go-gin/main.go.tmpl")),
+            (".gitignore", include_str!("scaffold/go-gin/gitignore.tmpl")),
+        ],
+    },
+    Template {
+        language: "Rust",
+        build_system: "Cargo",
+        framework: Some("Actix Web"),
+        aliases: &["actix"],
+        port: 8080,
+        files: &[
+            (
+                "Cargo.toml",
+                include_str!("scaffold/rust-actix/Cargo.toml.tmpl"),
+            ),
+            (
+                "src/main.rs",
+                include_str!("scaffold/rust-actix/src/main.rs.tmpl"),
+            ),
+            (
+                ".gitignore",
+                include_str!("scaffold/rust-actix/gitignore.tmpl"),
+            ),
+        ],
+    },
+    Template {
+        language: "Python",
+        build_system: "pip",
+        framework: Some("Flask"),
+        aliases: &[],
+        port: 5000,
+        files: &[
+            (
+                "requirements.txt",
+                include_str!("scaffold/python-flask/requirements.txt.tmpl"),
+            ),
+            // `flask run` finds the app in app.py
+            ("app.py", include_str!("scaffold/python-flask/app.py.tmpl")),
+            (
+                ".gitignore",
+                include_str!("scaffold/python-flask/gitignore.tmpl"),
+            ),
+        ],
+    },
+    Template {
+        language: "JavaScript",
+        build_system: "npm",
+        // Plain node:http, so the lockfile `npm ci` needs holds no dependencies
+        framework: None,
+        aliases: &["node", "nodejs", "js"],
+        port: 3000,
+        files: &[
+            (
+                "package.json",
+                include_str!("scaffold/node-npm/package.json.tmpl"),
+            ),
+            (
+                "package-lock.json",
+                include_str!("scaffold/node-npm/package-lock.json.tmpl"),
+            ),
+            ("index.js", include_str!("scaffold/node-npm/index.js.tmpl")),
+            (
+                ".gitignore",
+                include_str!("scaffold/node-npm/gitignore.tmpl"),
+            ),
+        ],
+    },
+];
+
+impl Template {
+    /// `language/build system/framework`, e.g. "Go/go mod/Gin"
+    pub fn name(&self) -> String {
+        match self.framework {
+            Some(framework) => format!("{}/{}/{}", self.language, self.build_system, framework),
+            None => format!("{}/{}", self.language, self.build_system),
+        }
+    }
+
+    fn matches(&self, canonical: &str, requested: &str) -> bool {
+        let requested = normalize(requested);
+        normalize(canonical) == requested
+            || self
+                .aliases
+                .iter()
+                .any(|alias| normalize(alias) == requested)
+    }
+}
+
+/// Case and separators don't matter: "go-mod", "Go Mod" and "gomod" are the same
+fn normalize(name: &str) -> String {
+    name.chars()
+        .filter(char::is_ascii_alphanumeric)
+        .map(|c| c.to_ascii_lowercase())
+        .collect()
+}
+
+/// The template for a spec's language and, when it names them, build system and framework
+pub fn find_template(metadata: &BuildMetadata) -> Result<&'static Template> {
+    if metadata.language.is_empty() {
+        bail!("The spec names no language");
+    }
+    TEMPLATES
+        .iter()
+        .find(|template| {
+            template.matches(template.language, &metadata.language)
+                && (metadata.build_system.is_empty()
+                    || template.matches(template.build_system, &metadata.build_system))
+                && metadata.framework.as_deref().is_none_or(|framework| {
+                    template
+                        .framework
+                        .is_some_and(|canonical| template.matches(canonical, framework))
+                })
+        })
+        .ok_or_else(|| {
+            let supported: Vec<String> = TEMPLATES.iter().map(Template::name).collect();
+            anyhow!(
+                "No template for {}{}{}; supported: {}",
+                metadata.language,
+                format_part(&metadata.build_system),
+                metadata
+                    .framework
+                    .as_deref()
+                    .map(format_part)
+                    .unwrap_or_default(),
+                supported.join(", ")
+            )
+        })
+}
+
+fn format_part(part: &str) -> String {
+    if part.is_empty() {
+        String::new()
+    } else {
+        format!("/{}", part)
+    }
+}
+
+/// The detection result a scaffolded project is expected to produce, from `peelbox init` flags
+pub fn spec(
+    language: &str,
+    build_system: Option<&str>,
+    framework: Option<&str>,
+    name: &str,
+) -> Result<UniversalBuild> {
+    let template = find_template(&BuildMetadata {
+        language: language.to_string(),
+        build_system: build_system.unwrap_or_default().to_string(),
+        framework: framework.map(str::to_string),
+        ..Default::default()
+    })?;
+    Ok(UniversalBuild {
+        version: "1.0".to_string(),
+        metadata: BuildMetadata {
+            project_name: Some(name.to_string()),
+            language: template.language.to_string(),
+            build_system: template.build_system.to_string(),
+            framework: template.framework.map(str::to_string),
+            reasoning: format!("Scaffolded from the {} template", template.name()),
+            ..Default::default()
+        },
+        confidence: Default::default(),
+        conflicts: vec![],
+        warnings: vec![],
+        suggestions: vec![],
+        validation_errors: vec![],
+        build: BuildStage::default(),
+        runtime: RuntimeStage {
+            ports: vec![template.port],
+            ..Default::default()
+        },
+    })
+}
+
+/// Writes the project `spec` describes into `target_dir`, returning the files written
+/// relative to it
+///
+/// Nothing is written when any of the files already exists.
+pub fn scaffold(spec: &UniversalBuild, target_dir: &Path) -> Result<Vec<PathBuf>> {
+    let template = find_template(&spec.metadata)?;
+    let name = spec
+        .metadata
+        .project_name
+        .as_deref()
+        .ok_or_else(|| anyhow!("The spec names no project"))?;
+    validate_name(name)?;
+
+    if let Some((existing, _)) = template
+        .files
+        .iter()
+        .find(|(path, _)| target_dir.join(path).exists())
+    {
+        bail!(
+            "{} already exists; scaffold into an empty directory",
+            target_dir.join(existing).display()
+        );
+    }
+
+    // Render everything before writing anything, so a bad template leaves no partial project
+    let mut rendered = Vec::new();
+    for (path, source) in template.files {
+        let content = render_template(spec, source)
+            .with_context(|| format!("Failed to render {} for {}", path, template.name()))?;
+        rendered.push((PathBuf::from(path), content));
+    }
+
+    let mut written = Vec::new();
+    for (path, content) in rendered {
+        let target = target_dir.join(&path);
+        if let Some(parent) = target.parent() {
+            std::fs::create_dir_all(parent)
+                .with_context(|| format!("Failed to create {}", parent.display()))?;
+        }
+        std::fs::write(&target, content)
+            .with_context(|| format!("Failed to write {}", target.display()))?;
+        written.push(path);
+    }
+    Ok(written)
+}
+
+/// The name becomes a Go module path, crate and npm package name, so it keeps to what all of
+/// them accept
+fn validate_name(name: &str) -> Result<()> {
+    let valid = name.starts_with(|c: char| c.is_ascii_lowercase())
+        && name
+            .chars()
+            .all(|c| c.is_ascii_lowercase() || c.is_ascii_digit() || c == '-' || c == '_');
+    if !valid {
+        bail!(
+            "Invalid project name '{}': use lowercase letters, digits, '-' and '_', starting with a letter",
+            name
+        );
+    }
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    #[test]
+    fn test_find_template() {
+        let template = |language: &str, build_system: &str, framework: Option<&str>| {
+            find_template(&BuildMetadata {
+                language: language.to_string(),
+                build_system: build_system.to_string(),
+                framework: framework.map(str::to_string),
+                ..Default::default()
+            })
+            .map(Template::name)
+        };
+
+        assert_eq!(
+            template("go", "modules", Some("gin")).unwrap(),
+            "Go/go mod/Gin"
+        );
+        assert_eq!(template("Go", "go mod", None).unwrap(), "Go/go mod/Gin");
+        assert_eq!(
+            template("rust", "", Some("actix")).unwrap(),
+            "Rust/Cargo/Actix Web"
+        );
+        assert_eq!(template("node", "npm", None).unwrap(), "JavaScript/npm");
+
+        let error = template("go", "", Some("echo")).unwrap_err().to_string();
+        assert!(error.contains("No template for go/echo"), "{}", error);
+        assert!(error.contains("Python/pip/Flask"), "{}", error);
+        assert!(template("", "", None).is_err());
+    }
+
+    #[test]
+    fn test_spec() {
+        let spec = spec("golang", Some("modules"), Some("gin"), "myapp").unwrap();
+        assert_eq!(spec.metadata.project_name.as_deref(), Some("myapp"));
+        assert_eq!(spec.metadata.language, "Go");
+        assert_eq!(spec.metadata.build_system, "go mod");
+        assert_eq!(spec.metadata.framework.as_deref(), Some("Gin"));
+        assert_eq!(spec.runtime.ports, vec![8080]);
+    }
+
+    #[test]
+    fn test_scaffold_renders_every_template() {
+        for template in &TEMPLATES {
+            let dir = TempDir::new().unwrap();
+            let spec = spec(
+                template.language,
+                Some(template.build_system),
+                template.framework,
+                "myapp",
+            )
+            .unwrap();
+
+            let written = scaffold(&spec, dir.path()).unwrap();
+            assert_eq!(written.len(), template.files.len(), "{}", template.name());
+            assert!(written.contains(&PathBuf::from(".gitignore")));
+            for path in written {
+                let content = std::fs::read_to_string(dir.path().join(&path)).unwrap();
+                assert!(
+                    !content.contains("{{"),
+                    "{} of {} left actions unrendered",
+                    path.display(),
+                    template.name()
+                );
+            }
+        }
+    }
+
+    #[test]
+    fn test_scaffold_go_gin() {
+        let dir = TempDir::new().unwrap();
+        let spec = spec("go", None, Some("gin"), "myapp").unwrap();
+        scaffold(&spec, dir.path()).unwrap();
+
+        let go_mod = std::fs::read_to_string(dir.path().join("go.mod")).unwrap();
+        assert!(go_mod.starts_with("module myapp\n"));
+        assert!(go_mod.contains("require github.com/gin-gonic/gin v1.9.1"));
+        let main = std::fs::read_to_string(dir.path().join("main.go")).unwrap();
+        assert!(main.contains("Hello from myapp"));
+        assert!(main.contains("r.GET(\"/health\""));
+    }
+
+    #[test]
+    fn test_scaffold_refuses_to_overwrite() {
+        let dir = TempDir::new().unwrap();
+        std::fs::write(dir.path().join("main.go"), "package main\n").unwrap();
+        let spec = spec("go", None, None, "myapp").unwrap();
+
+        let error = scaffold(&spec, dir.path()).unwrap_err().to_string();
+        assert!(error.contains("main.go already exists"), "{}", error);
+        assert!(!dir.path().join("go.mod").exists());
+    }
+
+    #[test]
+    fn test_project_name_validation() {
+        let dir = TempDir::new().unwrap();
+        for name in ["MyApp", "my app", "1app", ""] {
+            let spec = spec("python", None, None, name).unwrap();
+            assert!(scaffold(&spec, dir.path()).is_err(), "{}", name);
+        }
+        assert!(validate_name("my-app_2").is_ok());
+    }
+}
@@ -0,0 +1,318 @@
+//! HTTP mode of `detect`: `POST /scan` runs detection on a repository URL or an uploaded zip
+//! archive, for tooling that would rather not run peelbox as a subprocess
+
+use anyhow::{bail, Context, Result};
+use axum::body::Bytes;
+use axum::extract::{DefaultBodyLimit, Request, State};
+use axum::http::{header, StatusCode};
+use axum::middleware::{self, Next};
+use axum::response::{IntoResponse, Response};
+use axum::routing::{get, post};
+use axum::{Json, Router};
+use base64::Engine;
+use peelbox_core::fs::{ArchiveFileSystem, ArchiveFormat, ArchiveLimits, RemoteRepository};
+use peelbox_pipeline::detection::service::DetectionService;
+use peelbox_pipeline::pipeline::phases::scan::ScanConfig;
+use serde::Deserialize;
+use serde_json::json;
+use std::io::Cursor;
+use std::path::PathBuf;
+use std::sync::Arc;
+use std::time::{Duration, Instant};
+use tracing::{info, warn};
+
+const OPENAPI_SPEC: &str = include_str!("openapi.yaml");
+
+/// Largest request body; a base64 archive is a third larger than the zip itself
+const MAX_BODY_BYTES: usize = 64 * 1024 * 1024;
+
+/// Files above 1 MiB are left out of uploaded archives, which may expand to 256 MiB in all
+const ARCHIVE_LIMITS: ArchiveLimits = ArchiveLimits {
+    max_entry_bytes: 1024 * 1024,
+    max_total_bytes: 256 * 1024 * 1024,
+};
+
+#[derive(Debug, Clone)]
+pub struct ServeConfig {
+    /// Longest a request may take, reading the repository included
+    pub timeout: Duration,
+}
+
+impl Default for ServeConfig {
+    fn default() -> Self {
+        Self {
+            timeout: Duration::from_secs(30),
+        }
+    }
+}
+
+/// Body of `POST /scan`: exactly one of `url` and `archive`
+#[derive(Debug, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct ScanRequest {
+    /// GitHub or GitLab web URL, e.g. `https://github.com/owner/repo`
+    #[serde(default)]
+    pub url: Option<String>,
+    /// Base64-encoded zip archive of the repository
+    #[serde(default)]
+    pub archive: Option<String>,
+}
+
+struct ServeState {
+    service: Arc<DetectionService>,
+}
+
+/// `:8080` listens on every interface, like Go's `net.Listen`
+pub fn listen_address(addr: &str) -> String {
+    if addr.starts_with(':') {
+        format!("0.0.0.0{}", addr)
+    } else {
+        addr.to_string()
+    }
+}
+
+/// Routes with request logging and the request timeout applied
+pub fn router(service: Arc<DetectionService>, config: ServeConfig) -> Router {
+    Router::new()
+        .route("/scan", post(scan))
+        .route("/openapi.yaml", get(openapi))
+        .layer(DefaultBodyLimit::max(MAX_BODY_BYTES))
+        .layer(middleware::from_fn_with_state(config.timeout, timeout))
+        .layer(middleware::from_fn(log_request))
+        .with_state(Arc::new(ServeState { service }))
+}
+
+/// Serves until Ctrl-C
+pub async fn serve(addr: &str, service: DetectionService, config: ServeConfig) -> Result<()> {
+    let listener = tokio::net::TcpListener::bind(listen_address(addr))
+        .await
+        .with_context(|| format!("Failed to listen on {}", addr))?;
+    info!(
+        addr = %listener.local_addr()?,
+        timeout_secs = config.timeout.as_secs(),
+        "Serving detection over HTTP"
+    );
+
+    axum::serve(listener, router(Arc::new(service), config))
+        .with_graceful_shutdown(async {
+            tokio::signal::ctrl_c().await.ok();
+        })
+        .await
+        .context("HTTP server failed")
+}
+
+async fn scan(State(state): State<Arc<ServeState>>, body: Bytes) -> Response {
+    // Parsed by hand so malformed bodies get a JSON error like every other failure
+    let request: ScanRequest = match serde_json::from_slice(&body) {
+        Ok(request) => request,
+        Err(e) => return error(StatusCode::BAD_REQUEST, format!("Invalid request: {}", e)),
+    };
+
+    let detected = match scan_source(request, state.service.scan_config()) {
+        Ok(ScanSource::Remote(repository)) => state.service.detect_remote(repository).await,
+        Ok(ScanSource::Archive(zip)) => {
+            let fs = match tokio::task::spawn_blocking(move || read_archive(zip)).await {
+                Ok(Ok(fs)) => fs,
+                Ok(Err(e)) => return error(StatusCode::BAD_REQUEST, format!("{:#}", e)),
+                Err(e) => {
+                    return error(
+                        StatusCode::INTERNAL_SERVER_ERROR,
+                        format!("Reading the archive failed: {}", e),
+                    )
+                }
+            };
+            state.service.detect_file_system(Arc::new(fs)).await
+        }
+        Err(e) => return error(StatusCode::BAD_REQUEST, format!("{:#}", e)),
+    };
+
+    match detected {
+        Ok(results) => Json(results).into_response(),
+        Err(e) => error(StatusCode::UNPROCESSABLE_ENTITY, e.to_string()),
+    }
+}
+
+async fn openapi() -> Response {
+    ([(header::CONTENT_TYPE, "application/yaml")], OPENAPI_SPEC).into_response()
+}
+
+async fn timeout(State(limit): State<Duration>, request: Request, next: Next) -> Response {
+    match tokio::time::timeout(limit, next.run(request)).await {
+        Ok(response) => response,
+        Err(_) => error(
+            StatusCode::REQUEST_TIMEOUT,
+            format!("Request took longer than {}s", limit.as_secs()),
+        ),
+    }
+}
+
+async fn log_request(request: Request, next: Next) -> Response {
+    let method = request.method().clone();
+    let path = request.uri().path().to_string();
+    let start = Instant::now();
+    let response = next.run(request).await;
+    info!(
+        method = %method,
+        path = %path,
+        status = response.status().as_u16(),
+        duration_ms = start.elapsed().as_millis(),
+        "Handled request"
+    );
+    response
+}
+
+fn error(status: StatusCode, message: String) -> Response {
+    if status.is_server_error() {
+        warn!("{}", message);
+    }
+    (status, Json(json!({ "error": message }))).into_response()
+}
+
+/// What a `POST /scan` asks to detect
+enum ScanSource {
+    /// Read through the host's API, from the hosts `scan_config.gitlab_hosts` allows
+    Remote(RemoteRepository),
+    /// Decoded zip archive
+    Archive(Vec<u8>),
+}
+
+fn scan_source(request: ScanRequest, scan_config: &ScanConfig) -> Result<ScanSource> {
+    match (request.url, request.archive) {
+        (Some(url), None) => Ok(ScanSource::Remote(RemoteRepository::parse(
+            &url,
+            &scan_config.gitlab_hosts,
+        )?)),
+        (None, Some(archive)) => Ok(ScanSource::Archive(
+            base64::engine::general_purpose::STANDARD
+                .decode(archive.trim())
+                .context("Archive is not valid base64")?,
+        )),
+        (Some(_), Some(_)) => bail!("Pass either \"url\" or \"archive\", not both"),
+        (None, None) => bail!("Pass a repository \"url\" or a base64 zip \"archive\""),
+    }
+}
+
+/// Reads an uploaded zip within the decompression limits, rooted below the single directory
+/// that wraps it, if any
+fn read_archive(zip: Vec<u8>) -> Result<ArchiveFileSystem> {
+    let fs =
+        ArchiveFileSystem::read_with_limits(Cursor::new(zip), ArchiveFormat::Zip, ARCHIVE_LIMITS)?;
+    let files = fs.files();
+    if files.is_empty() {
+        bail!("Archive contains no files");
+    }
+    Ok(fs.subtree(&archive_root(&files)))
+}
+
+/// The single directory wrapping every file, as in GitHub's "Download ZIP" archives
+fn archive_root(files: &[PathBuf]) -> PathBuf {
+    let first = files[0].components().next();
+    let wrapped = files
+        .iter()
+        .all(|file| file.components().count() > 1 && file.components().next() == first);
+    match first {
+        Some(component) if wrapped => PathBuf::from(component.as_os_str()),
+        _ => PathBuf::new(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_listen_address() {
+        assert_eq!(listen_address(":8080"), "0.0.0.0:8080");
+        assert_eq!(listen_address("127.0.0.1:9000"), "127.0.0.1:9000");
+    }
+
+    #[test]
+    fn test_archive_root() {
+        let wrapped = [
+            PathBuf::from("repo-main/go.mod"),
+            PathBuf::from("repo-main/cmd/api/main.go"),
+        ];
+        assert_eq!(archive_root(&wrapped), PathBuf::from("repo-main"));
+
+        let flat = [PathBuf::from("go.mod"), PathBuf::from("cmd/api/main.go")];
+        assert_eq!(archive_root(&flat), PathBuf::new());
+
+        let single = [PathBuf::from("go.mod")];
+        assert_eq!(archive_root(&single), PathBuf::new());
+    }
+
+    #[test]
+    fn test_read_archive() {
+        use std::io::Write;
+
+        let mut writer = zip::ZipWriter::new(Cursor::new(Vec::new()));
+        let files = [
+            ("repo-main/go.mod", "module example.com/app\n".to_string()),
+            ("repo-main/main.go", "package main\n".to_string()),
+            ("repo-main/assets/video.bin", "0".repeat(2 * 1024 * 1024)),
+        ];
+        for (path, content) in &files {
+            writer
+                .start_file(*path, zip::write::FileOptions::default())
+                .unwrap();
+            writer.write_all(content.as_bytes()).unwrap();
+        }
+        let zip = writer.finish().unwrap().into_inner();
+
+        let fs = read_archive(zip).unwrap();
+        assert_eq!(
+            fs.files(),
+            vec![PathBuf::from("go.mod"), PathBuf::from("main.go")]
+        );
+
+        let empty = zip::ZipWriter::new(Cursor::new(Vec::new()))
+            .finish()
+            .unwrap()
+            .into_inner();
+        let err = read_archive(empty).err().unwrap();
+        assert!(err.to_string().contains("no files"));
+    }
+
+    #[test]
+    fn test_rejects_ambiguous_requests() {
+        let config = ScanConfig::default();
+        let both = ScanRequest {
+            url: Some("https://github.com/owner/repo".to_string()),
+            archive: Some(String::new()),
+        };
+        assert!(scan_source(both, &config).is_err());
+
+        let neither = ScanRequest {
+            url: None,
+            archive: None,
+        };
+        assert!(scan_source(neither, &config).is_err());
+
+        let not_base64 = ScanRequest {
+            url: None,
+            archive: Some("not base64!".to_string()),
+        };
+        let err = scan_source(not_base64, &config).unwrap_err();
+        assert!(err.to_string().contains("base64"));
+    }
+
+    #[test]
+    fn test_url_host_allowlist() {
+        let request = |url: &str| ScanRequest {
+            url: Some(url.to_string()),
+            archive: None,
+        };
+        let mut config = ScanConfig::default();
+        let err = scan_source(request("https://gitlab.acme.dev/team/app"), &config)
+            .err()
+            .unwrap();
+        assert!(err.to_string().contains("Unsupported repository host"));
+
+        config.gitlab_hosts = vec!["gitlab.acme.dev".to_string()];
+        let source = scan_source(request("https://gitlab.acme.dev/team/app"), &config).unwrap();
+        assert!(matches!(
+            source,
+            ScanSource::Remote(RemoteRepository { ref path, .. }) if path == "team/app"
+        ));
+    }
+}
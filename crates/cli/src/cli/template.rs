@@ -0,0 +1,743 @@
+//! Output templates - renders a detection result with a subset of Go's text/template syntax
+//!
+//! The template runs against the JSON form of a `UniversalBuild`, so `.metadata.language` or
+//! `.runtime.ports` read the same fields `--format json` prints. Supported actions are
+//! `{{ pipeline }}`, `{{ if }}`/`{{ else if }}`/`{{ else }}`, `{{ range }}`, `{{ with }}`,
+//! `{{ end }}`, `{{/* comments */}}` and the `{{-`/`-}}` whitespace trim markers. Fields
+//! missing from the result render as empty text. See docs/OUTPUT_TEMPLATES.md for examples.
+
+use anyhow::{anyhow, bail, Context, Result};
+use peelbox_core::output::schema::UniversalBuild;
+use serde_json::Value;
+
+/// Functions callable from a template; `join` takes the separator first, so
+/// `{{ .build.commands | join " && " }}` reads naturally
+const FUNCTIONS: [&str; 11] = [
+    "join", "quote", "upper", "lower", "len", "index", "eq", "ne", "not", "and", "or",
+];
+
+/// Reads a `--template` argument: template text, or `@path` naming a template file
+pub fn load_template(arg: &str) -> Result<String> {
+    match arg.strip_prefix('@') {
+        Some(path) => std::fs::read_to_string(path)
+            .with_context(|| format!("Failed to read template file {}", path)),
+        None => Ok(arg.to_string()),
+    }
+}
+
+/// Executes `template` against `result`
+pub fn render_template(result: &UniversalBuild, template: &str) -> Result<String> {
+    let nodes = Parser::new(lex(template)?).parse()?;
+    let data =
+        serde_json::to_value(result).context("Failed to convert UniversalBuild to JSON value")?;
+    let mut out = String::new();
+    render(&nodes, &data, &data, &mut out)?;
+    Ok(out)
+}
+
+#[derive(Debug, Clone, PartialEq)]
+enum Token {
+    /// A function name or keyword
+    Word(String),
+    /// `.` or `.metadata.language`, relative to the current value
+    Field(Vec<String>),
+    /// `$` or `$.metadata.language`, relative to the result
+    Root(Vec<String>),
+    Literal(Value),
+    Pipe,
+    Open,
+    Close,
+}
+
+enum Item {
+    Text(String),
+    Action { line: usize, tokens: Vec<Token> },
+}
+
+/// Splits the template into text and the tokens of each action
+fn lex(template: &str) -> Result<Vec<Item>> {
+    let mut items = Vec::new();
+    let mut offset = 0;
+    let mut trim_next = false;
+
+    while offset < template.len() {
+        let rest = &template[offset..];
+        let Some(start) = rest.find("{{") else {
+            push_text(&mut items, rest, trim_next, false);
+            break;
+        };
+        let line = template[..offset + start].matches('\n').count() + 1;
+
+        let mut inner_start = start + 2;
+        let trim_before = rest[inner_start..].starts_with('-')
+            && rest[inner_start + 1..].starts_with(char::is_whitespace);
+        if trim_before {
+            inner_start += 1;
+        }
+        push_text(&mut items, &rest[..start], trim_next, trim_before);
+
+        let close = inner_start
+            + action_end(&rest[inner_start..])
+                .ok_or_else(|| anyhow!("template:{}: unclosed action", line))?;
+        let mut inner = &rest[inner_start..close];
+        trim_next = inner.ends_with('-') && inner[..inner.len() - 1].ends_with(char::is_whitespace);
+        if trim_next {
+            inner = &inner[..inner.len() - 1];
+        }
+        offset += close + 2;
+
+        let inner = inner.trim();
+        if inner.starts_with("/*") {
+            if !inner.ends_with("*/") {
+                bail!("template:{}: unclosed comment", line);
+            }
+            continue;
+        }
+        let tokens = tokenize(inner).map_err(|e| anyhow!("template:{}: {}", line, e))?;
+        if tokens.is_empty() {
+            bail!("template:{}: missing value for command", line);
+        }
+        items.push(Item::Action { line, tokens });
+    }
+    Ok(items)
+}
+
+fn push_text(items: &mut Vec<Item>, text: &str, trim_start: bool, trim_end: bool) {
+    let text = if trim_start { text.trim_start() } else { text };
+    let text = if trim_end { text.trim_end() } else { text };
+    if !text.is_empty() {
+        items.push(Item::Text(text.to_string()));
+    }
+}
+
+/// Offset of the `}}` closing an action, skipping over quoted strings
+fn action_end(action: &str) -> Option<usize> {
+    let mut quote: Option<char> = None;
+    let mut escaped = false;
+    for (index, c) in action.char_indices() {
+        match quote {
+            Some(_) if escaped => escaped = false,
+            Some('"') if c == '\\' => escaped = true,
+            Some(q) if c == q => quote = None,
+            Some(_) => {}
+            None if c == '"' || c == '`' => quote = Some(c),
+            None if action[index..].starts_with("}}") => return Some(index),
+            None => {}
+        }
+    }
+    None
+}
+
+fn tokenize(action: &str) -> Result<Vec<Token>> {
+    let chars: Vec<char> = action.chars().collect();
+    let mut tokens = Vec::new();
+    let mut i = 0;
+    let word_end = |mut end: usize| {
+        while end < chars.len()
+            && (chars[end].is_alphanumeric() || matches!(chars[end], '_' | '.' | '-'))
+        {
+            end += 1;
+        }
+        end
+    };
+    let path = |word: &str| -> Vec<String> {
+        word.split('.')
+            .filter(|key| !key.is_empty())
+            .map(str::to_string)
+            .collect()
+    };
+
+    while i < chars.len() {
+        let c = chars[i];
+        match c {
+            _ if c.is_whitespace() => i += 1,
+            '|' => {
+                tokens.push(Token::Pipe);
+                i += 1;
+            }
+            '(' => {
+                tokens.push(Token::Open);
+                i += 1;
+            }
+            ')' => {
+                tokens.push(Token::Close);
+                i += 1;
+            }
+            '"' => {
+                let mut value = String::new();
+                i += 1;
+                loop {
+                    match chars.get(i) {
+                        None => bail!("unterminated quoted string"),
+                        Some('"') => break,
+                        Some('\\') => {
+                            value.push(match chars.get(i + 1) {
+                                Some('n') => '\n',
+                                Some('t') => '\t',
+                                Some(c @ ('"' | '\\')) => *c,
+                                _ => bail!("invalid escape in quoted string"),
+                            });
+                            i += 1;
+                        }
+                        Some(&other) => value.push(other),
+                    }
+                    i += 1;
+                }
+                tokens.push(Token::Literal(Value::String(value)));
+                i += 1;
+            }
+            '`' => {
+                let end = chars[i + 1..]
+                    .iter()
+                    .position(|&c| c == '`')
+                    .ok_or_else(|| anyhow!("unterminated raw string"))?;
+                let value: String = chars[i + 1..i + 1 + end].iter().collect();
+                tokens.push(Token::Literal(Value::String(value)));
+                i += end + 2;
+            }
+            '.' | '$' => {
+                let end = word_end(i + 1);
+                let word: String = chars[i + 1..end].iter().collect();
+                tokens.push(if c == '.' {
+                    Token::Field(path(&word))
+                } else if word.is_empty() || word.starts_with('.') {
+                    Token::Root(path(&word))
+                } else {
+                    bail!("undefined variable \"${}\"", word)
+                });
+                i = end;
+            }
+            _ if c.is_ascii_digit()
+                || (c == '-' && chars.get(i + 1).is_some_and(char::is_ascii_digit)) =>
+            {
+                let end = word_end(i + 1);
+                let word: String = chars[i..end].iter().collect();
+                let number: serde_json::Number = word
+                    .parse()
+                    .map_err(|_| anyhow!("bad number syntax: {}", word))?;
+                tokens.push(Token::Literal(Value::Number(number)));
+                i = end;
+            }
+            _ if c.is_alphabetic() || c == '_' => {
+                let end = word_end(i + 1);
+                let word: String = chars[i..end].iter().collect();
+                tokens.push(match word.as_str() {
+                    "true" => Token::Literal(Value::Bool(true)),
+                    "false" => Token::Literal(Value::Bool(false)),
+                    "nil" => Token::Literal(Value::Null),
+                    _ => Token::Word(word),
+                });
+                i = end;
+            }
+            _ => bail!("unexpected {:?} in command", c),
+        }
+    }
+    Ok(tokens)
+}
+
+#[derive(Debug)]
+enum Operand {
+    Field(Vec<String>),
+    Root(Vec<String>),
+    Literal(Value),
+    Function(String),
+    Nested(Pipeline),
+}
+
+/// Commands separated by `|`; each command's value is passed as the last argument of the next
+#[derive(Debug)]
+struct Pipeline {
+    line: usize,
+    commands: Vec<Vec<Operand>>,
+}
+
+#[derive(Debug)]
+enum Node {
+    Text(String),
+    Output(Pipeline),
+    If {
+        branches: Vec<(Pipeline, Vec<Node>)>,
+        otherwise: Vec<Node>,
+    },
+    Range {
+        pipeline: Pipeline,
+        body: Vec<Node>,
+        otherwise: Vec<Node>,
+    },
+    With {
+        pipeline: Pipeline,
+        body: Vec<Node>,
+        otherwise: Vec<Node>,
+    },
+}
+
+/// The action ending a list of nodes
+enum Stop {
+    End,
+    Else,
+    ElseIf(Pipeline),
+}
+
+struct Parser {
+    items: std::vec::IntoIter<Item>,
+}
+
+impl Parser {
+    fn new(items: Vec<Item>) -> Self {
+        Self {
+            items: items.into_iter(),
+        }
+    }
+
+    fn parse(mut self) -> Result<Vec<Node>> {
+        match self.parse_list()? {
+            (nodes, None) => Ok(nodes),
+            (_, Some((line, Stop::End))) => bail!("template:{}: unexpected {{{{end}}}}", line),
+            (_, Some((line, _))) => bail!("template:{}: unexpected {{{{else}}}}", line),
+        }
+    }
+
+    fn parse_list(&mut self) -> Result<(Vec<Node>, Option<(usize, Stop)>)> {
+        let mut nodes = Vec::new();
+        while let Some(item) = self.items.next() {
+            let (line, tokens) = match item {
+                Item::Text(text) => {
+                    nodes.push(Node::Text(text));
+                    continue;
+                }
+                Item::Action { line, tokens } => (line, tokens),
+            };
+            let keyword = match tokens.first() {
+                Some(Token::Word(word)) => word.as_str(),
+                _ => "",
+            };
+            let node = match keyword {
+                "end" if tokens.len() == 1 => return Ok((nodes, Some((line, Stop::End)))),
+                "else" if tokens.len() == 1 => return Ok((nodes, Some((line, Stop::Else)))),
+                "else" if tokens.get(1) == Some(&Token::Word("if".to_string())) => {
+                    let condition = parse_pipeline(line, &tokens[2..])?;
+                    return Ok((nodes, Some((line, Stop::ElseIf(condition)))));
+                }
+                "if" => self.parse_if(line, parse_pipeline(line, &tokens[1..])?)?,
+                "range" | "with" => {
+                    let pipeline = parse_pipeline(line, &tokens[1..])?;
+                    let (body, otherwise) = self.parse_block(line, keyword)?;
+                    if keyword == "range" {
+                        Node::Range {
+                            pipeline,
+                            body,
+                            otherwise,
+                        }
+                    } else {
+                        Node::With {
+                            pipeline,
+                            body,
+                            otherwise,
+                        }
+                    }
+                }
+                "end" | "else" => bail!(
+                    "template:{}: unexpected {:?} in {}",
+                    line,
+                    tokens[1],
+                    keyword
+                ),
+                _ => Node::Output(parse_pipeline(line, &tokens)?),
+            };
+            nodes.push(node);
+        }
+        Ok((nodes, None))
+    }
+
+    fn parse_if(&mut self, line: usize, condition: Pipeline) -> Result<Node> {
+        let mut branches = Vec::new();
+        let mut condition = condition;
+        loop {
+            let (body, stop) = self.parse_list()?;
+            branches.push((condition, body));
+            match stop {
+                Some((_, Stop::End)) => {
+                    return Ok(Node::If {
+                        branches,
+                        otherwise: Vec::new(),
+                    })
+                }
+                Some((_, Stop::Else)) => {
+                    let (otherwise, stop) = self.parse_list()?;
+                    return match stop {
+                        Some((_, Stop::End)) => Ok(Node::If {
+                            branches,
+                            otherwise,
+                        }),
+                        Some((line, _)) => bail!("template:{}: expected {{{{end}}}}", line),
+                        None => bail!("template:{}: unclosed {{{{if}}}}", line),
+                    };
+                }
+                Some((_, Stop::ElseIf(next))) => condition = next,
+                None => bail!("template:{}: unclosed {{{{if}}}}", line),
+            }
+        }
+    }
+
+    /// Body and `{{ else }}` branch of a `range` or `with`
+    fn parse_block(&mut self, line: usize, keyword: &str) -> Result<(Vec<Node>, Vec<Node>)> {
+        let (body, stop) = self.parse_list()?;
+        match stop {
+            Some((_, Stop::End)) => Ok((body, Vec::new())),
+            Some((_, Stop::Else)) => match self.parse_list()? {
+                (otherwise, Some((_, Stop::End))) => Ok((body, otherwise)),
+                (_, Some((line, _))) => bail!("template:{}: expected {{{{end}}}}", line),
+                (_, None) => bail!("template:{}: unclosed {{{{{}}}}}", line, keyword),
+            },
+            Some((line, Stop::ElseIf(_))) => {
+                bail!(
+                    "template:{}: {{{{else if}}}} is only allowed in {{{{if}}}}",
+                    line
+                )
+            }
+            None => bail!("template:{}: unclosed {{{{{}}}}}", line, keyword),
+        }
+    }
+}
+
+fn parse_pipeline(line: usize, tokens: &[Token]) -> Result<Pipeline> {
+    let mut commands = Vec::new();
+    let mut command = Vec::new();
+    let mut i = 0;
+    while i < tokens.len() {
+        match &tokens[i] {
+            Token::Pipe => {
+                if command.is_empty() {
+                    bail!("template:{}: missing command before |", line);
+                }
+                commands.push(std::mem::take(&mut command));
+            }
+            Token::Open => {
+                let mut depth = 0;
+                let close = tokens[i..]
+                    .iter()
+                    .position(|token| {
+                        match token {
+                            Token::Open => depth += 1,
+                            Token::Close => depth -= 1,
+                            _ => {}
+                        }
+                        depth == 0
+                    })
+                    .ok_or_else(|| anyhow!("template:{}: unclosed left paren", line))?;
+                command.push(Operand::Nested(parse_pipeline(
+                    line,
+                    &tokens[i + 1..i + close],
+                )?));
+                i += close;
+            }
+            Token::Close => bail!("template:{}: unexpected right paren", line),
+            Token::Word(name) if FUNCTIONS.contains(&name.as_str()) => {
+                command.push(Operand::Function(name.clone()))
+            }
+            Token::Word(name) => bail!("template:{}: function {:?} not defined", line, name),
+            Token::Field(path) => command.push(Operand::Field(path.clone())),
+            Token::Root(path) => command.push(Operand::Root(path.clone())),
+            Token::Literal(value) => command.push(Operand::Literal(value.clone())),
+        }
+        i += 1;
+    }
+    if command.is_empty() {
+        bail!("template:{}: missing value for command", line);
+    }
+    commands.push(command);
+    Ok(Pipeline { line, commands })
+}
+
+fn render(nodes: &[Node], dot: &Value, root: &Value, out: &mut String) -> Result<()> {
+    for node in nodes {
+        match node {
+            Node::Text(text) => out.push_str(text),
+            Node::Output(pipeline) => out.push_str(&text(&eval(pipeline, dot, root)?)),
+            Node::If {
+                branches,
+                otherwise,
+            } => {
+                let mut taken = None;
+                for (condition, body) in branches {
+                    if truthy(&eval(condition, dot, root)?) {
+                        taken = Some(body);
+                        break;
+                    }
+                }
+                render(taken.unwrap_or(otherwise), dot, root, out)?;
+            }
+            Node::Range {
+                pipeline,
+                body,
+                otherwise,
+            } => {
+                let elements = match eval(pipeline, dot, root)? {
+                    Value::Array(elements) => elements,
+                    Value::Object(map) => {
+                        let mut entries: Vec<(String, Value)> = map.into_iter().collect();
+                        entries.sort_by(|(a, _), (b, _)| a.cmp(b));
+                        entries.into_iter().map(|(_, value)| value).collect()
+                    }
+                    Value::Null => Vec::new(),
+                    other => bail!(
+                        "template:{}: range can't iterate over {}",
+                        pipeline.line,
+                        text(&other)
+                    ),
+                };
+                if elements.is_empty() {
+                    render(otherwise, dot, root, out)?;
+                }
+                for element in &elements {
+                    render(body, element, root, out)?;
+                }
+            }
+            Node::With {
+                pipeline,
+                body,
+                otherwise,
+            } => {
+                let value = eval(pipeline, dot, root)?;
+                if truthy(&value) {
+                    render(body, &value, root, out)?;
+                } else {
+                    render(otherwise, dot, root, out)?;
+                }
+            }
+        }
+    }
+    Ok(())
+}
+
+fn eval(pipeline: &Pipeline, dot: &Value, root: &Value) -> Result<Value> {
+    let mut piped: Option<Value> = None;
+    for command in &pipeline.commands {
+        let value = match command.split_first() {
+            Some((Operand::Function(name), args)) => {
+                let mut values = args
+                    .iter()
+                    .map(|arg| operand(arg, dot, root))
+                    .collect::<Result<Vec<_>>>()?;
+                values.extend(piped.take());
+                call(name, values).map_err(|e| anyhow!("template:{}: {}", pipeline.line, e))?
+            }
+            Some((single, [])) if piped.is_none() => operand(single, dot, root)?,
+            _ => bail!(
+                "template:{}: only functions take arguments or piped values",
+                pipeline.line
+            ),
+        };
+        piped = Some(value);
+    }
+    Ok(piped.unwrap_or(Value::Null))
+}
+
+fn operand(operand: &Operand, dot: &Value, root: &Value) -> Result<Value> {
+    Ok(match operand {
+        Operand::Field(path) => lookup(dot, path),
+        Operand::Root(path) => lookup(root, path),
+        Operand::Literal(value) => value.clone(),
+        Operand::Function(name) => call(name, Vec::new())?,
+        Operand::Nested(pipeline) => eval(pipeline, dot, root)?,
+    })
+}
+
+fn lookup(value: &Value, path: &[String]) -> Value {
+    path.iter()
+        .try_fold(value, |value, key| value.get(key))
+        .cloned()
+        .unwrap_or(Value::Null)
+}
+
+fn call(name: &str, args: Vec<Value>) -> Result<Value> {
+    Ok(match (name, args.as_slice()) {
+        ("join", [separator, list]) => match list {
+            Value::Array(elements) => Value::String(
+                elements
+                    .iter()
+                    .map(text)
+                    .collect::<Vec<_>>()
+                    .join(&text(separator)),
+            ),
+            Value::Null => Value::String(String::new()),
+            other => bail!("join expects a list, got {}", text(other)),
+        },
+        ("quote", [value]) => Value::String(serde_json::to_string(&text(value))?),
+        ("upper", [value]) => Value::String(text(value).to_uppercase()),
+        ("lower", [value]) => Value::String(text(value).to_lowercase()),
+        ("len", [value]) => Value::from(match value {
+            Value::Array(elements) => elements.len(),
+            Value::Object(map) => map.len(),
+            Value::String(s) => s.chars().count(),
+            Value::Null => 0,
+            other => bail!("len of {}", text(other)),
+        }),
+        ("index", [value, keys @ ..]) => keys.iter().try_fold(value.clone(), |value, key| {
+            Ok::<_, anyhow::Error>(match (&value, key) {
+                (Value::Array(elements), Value::Number(n)) => n
+                    .as_u64()
+                    .and_then(|n| elements.get(n as usize))
+                    .cloned()
+                    .ok_or_else(|| anyhow!("index {} out of range", n))?,
+                (Value::Object(map), Value::String(key)) => {
+                    map.get(key).cloned().unwrap_or(Value::Null)
+                }
+                (Value::Null, _) => Value::Null,
+                _ => bail!("can't index {} with {}", text(&value), text(key)),
+            })
+        })?,
+        ("eq", [value, others @ ..]) if !others.is_empty() => {
+            Value::Bool(others.iter().any(|other| other == value))
+        }
+        ("ne", [a, b]) => Value::Bool(a != b),
+        ("not", [value]) => Value::Bool(!truthy(value)),
+        ("and", [.., last]) => args
+            .iter()
+            .find(|value| !truthy(value))
+            .unwrap_or(last)
+            .clone(),
+        ("or", [.., last]) => args
+            .iter()
+            .find(|value| truthy(value))
+            .unwrap_or(last)
+            .clone(),
+        _ => bail!("wrong number of args for {}: got {}", name, args.len()),
+    })
+}
+
+/// Strings print as-is, missing values as nothing, lists and objects as compact JSON
+fn text(value: &Value) -> String {
+    match value {
+        Value::String(s) => s.clone(),
+        Value::Null => String::new(),
+        Value::Bool(b) => b.to_string(),
+        Value::Number(n) => n.to_string(),
+        other => other.to_string(),
+    }
+}
+
+/// Go's truth: false, 0, empty strings, lists and objects, and missing values are false
+fn truthy(value: &Value) -> bool {
+    match value {
+        Value::Null => false,
+        Value::Bool(b) => *b,
+        Value::Number(n) => n.as_f64() != Some(0.0),
+        Value::String(s) => !s.is_empty(),
+        Value::Array(elements) => !elements.is_empty(),
+        Value::Object(map) => !map.is_empty(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn fixture(path: &str) -> UniversalBuild {
+        let json = std::fs::read_to_string(format!(
+            "{}/tests/fixtures/{}/universalbuild.json",
+            env!("CARGO_MANIFEST_DIR"),
+            path
+        ))
+        .unwrap();
+        let mut builds: Vec<UniversalBuild> = serde_json::from_str(&json).unwrap();
+        builds.remove(0)
+    }
+
+    #[test]
+    fn test_shell_exports() {
+        let result = fixture("single-language/go-air");
+        let template = r#"{{- with .metadata -}}
+export APP_NAME={{ .project_name | quote }}
+export APP_LANGUAGE={{ .language | lower }}
+{{ end -}}
+export APP_PORT={{ index .runtime.ports 0 }}
+export BUILD_COMMAND={{ .build.commands | join " && " | quote }}
+"#;
+        assert_eq!(
+            render_template(&result, template).unwrap(),
+            "export APP_NAME=\"blog\"\nexport APP_LANGUAGE=go\nexport APP_PORT=8080\nexport BUILD_COMMAND=\"go mod download && go build -o app .\"\n"
+        );
+    }
+
+    #[test]
+    fn test_helm_values() {
+        let result = fixture("deployment/go-k8s-deployment");
+        let template = r#"image:
+  repository: {{ .metadata.project_name }}
+service:
+  port: {{ index .runtime.ports 0 }}
+{{- with .runtime.health }}
+livenessProbe:
+  path: {{ .endpoint }}
+{{- end }}
+{{- with .metadata.kubernetes }}
+resources:
+  requests:
+    cpu: {{ .cpu_request }}
+    memory: {{ .memory_request }}
+{{- end }}
+env:
+{{- range .runtime.env }}
+  - {{ . }}
+{{- else }} []
+{{- end }}
+packages:
+{{- range .runtime.packages }}
+  - {{ . | upper }}
+{{- end }}
+"#;
+        assert_eq!(
+            render_template(&result, template).unwrap(),
+            "image:\n  repository: inventory\nservice:\n  port: 8080\nlivenessProbe:\n  path: /healthz\nresources:\n  requests:\n    cpu: 100m\n    memory: 128Mi\nenv: []\npackages:\n  - GLIBC\n  - CA-CERTIFICATES\n"
+        );
+    }
+
+    #[test]
+    fn test_conditionals_and_missing_fields() {
+        let result = fixture("single-language/go-air");
+        let template = "{{ if eq .metadata.language \"Rust\" }}rust{{ else if .metadata.dev_run_command }}dev: {{ .metadata.dev_run_command }}{{ else }}none{{ end }}|{{ .metadata.framework }}|{{ len .build.cache }}|{{ not .metadata.framework }}|{{/* ignored */}}{{ $.version }}";
+        assert_eq!(
+            render_template(&result, template).unwrap(),
+            "dev: air||2|true|1.0"
+        );
+    }
+
+    #[test]
+    fn test_template_errors() {
+        let result = fixture("single-language/go-air");
+        let error = |template: &str| render_template(&result, template).unwrap_err().to_string();
+
+        assert_eq!(
+            error("{{ .metadata.language | title }}"),
+            "template:1: function \"title\" not defined"
+        );
+        assert_eq!(
+            error("line one\n{{ if .metadata.language }}go"),
+            "template:2: unclosed {{if}}"
+        );
+        assert_eq!(
+            error("{{ .metadata.language "),
+            "template:1: unclosed action"
+        );
+        assert_eq!(error("{{ end }}"), "template:1: unexpected {{end}}");
+        assert_eq!(
+            error("{{ join .build.commands }}"),
+            "template:1: wrong number of args for join: got 1"
+        );
+    }
+
+    #[test]
+    fn test_load_template_file() {
+        let dir = tempfile::tempdir().unwrap();
+        let path = dir.path().join("exports.tmpl");
+        std::fs::write(&path, "{{ .version }}\n").unwrap();
+
+        assert_eq!(
+            load_template(&format!("@{}", path.display())).unwrap(),
+            "{{ .version }}\n"
+        );
+        assert_eq!(load_template("{{ .version }}").unwrap(), "{{ .version }}");
+        assert!(load_template("@/nonexistent/exports.tmpl").is_err());
+    }
+}
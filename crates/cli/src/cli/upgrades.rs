@@ -0,0 +1,358 @@
+//! `detect --check-upgrades`: newer releases of a Go module's direct requirements, looked up
+//! on the Go module proxy
+//!
+//! The check is the only part of detection that needs the network. It is an ordinary future,
+//! so dropping it (on Ctrl-C or a timeout) cancels the request in flight and every one after.
+
+use anyhow::{bail, Context, Result};
+use peelbox_core::output::schema::{UniversalBuild, UpgradeAvailable};
+use peelbox_pipeline::extractors::build_dependency_graph;
+use peelbox_pipeline::extractors::framework_version::semver_key;
+use serde::Deserialize;
+use std::path::Path;
+use std::time::Duration;
+use tokio::time::MissedTickBehavior;
+use tracing::{debug, warn};
+
+pub const GO_PROXY: &str = "https://proxy.golang.org";
+
+/// Spacing between proxy requests, so a long go.mod does not turn into a burst
+const DEFAULT_INTERVAL: Duration = Duration::from_millis(100);
+
+/// Body of `GET <proxy>/<module>/@latest`
+#[derive(Debug, Deserialize)]
+struct ModuleInfo {
+    #[serde(rename = "Version")]
+    version: String,
+}
+
+pub struct UpgradeChecker {
+    client: reqwest::Client,
+    proxy: String,
+    interval: Duration,
+}
+
+impl UpgradeChecker {
+    pub fn new(proxy: &str) -> Self {
+        let client = reqwest::Client::builder()
+            .timeout(Duration::from_secs(10))
+            .user_agent(concat!("peelbox/", env!("CARGO_PKG_VERSION")))
+            .build()
+            .unwrap_or_else(|_| reqwest::Client::new());
+        Self {
+            client,
+            proxy: proxy.trim_end_matches('/').to_string(),
+            interval: DEFAULT_INTERVAL,
+        }
+    }
+
+    /// The first HTTP proxy of `GOPROXY`, else proxy.golang.org
+    pub fn from_env() -> Self {
+        let proxy = std::env::var("GOPROXY")
+            .ok()
+            .and_then(|value| {
+                value
+                    .split([',', '|'])
+                    .find(|entry| entry.starts_with("http://") || entry.starts_with("https://"))
+                    .map(str::to_string)
+            })
+            .unwrap_or_else(|| GO_PROXY.to_string());
+        Self::new(&proxy)
+    }
+
+    pub fn with_interval(mut self, interval: Duration) -> Self {
+        self.interval = interval;
+        self
+    }
+
+    /// Direct requirements of `go_mod` with a newer release, in go.mod order
+    ///
+    /// Besides `@latest`, which stays within a major version, the next major version's module
+    /// path (`<module>/v2` for v1) is asked for. Modules the proxy does not serve, such as
+    /// private ones, are skipped; any other failure fails the whole check.
+    pub async fn check_upgrades(&self, go_mod: &str) -> Result<Vec<UpgradeAvailable>> {
+        let graph = build_dependency_graph(go_mod, "")?;
+        let mut ticker = tokio::time::interval(self.interval);
+        ticker.set_missed_tick_behavior(MissedTickBehavior::Delay);
+
+        let mut upgrades = Vec::new();
+        for module in graph.direct_dependencies() {
+            let Some(current) = module.version else {
+                continue;
+            };
+
+            ticker.tick().await;
+            let mut latest = self
+                .latest(&module.path)
+                .await?
+                .filter(|latest| semver_key(latest) > semver_key(&current));
+            if let Some(next) = next_major_path(&module.path, &current) {
+                ticker.tick().await;
+                if let Some(version) = self.latest(&next).await? {
+                    latest = Some(version);
+                }
+            }
+
+            if let Some(latest) = latest {
+                upgrades.push(UpgradeAvailable {
+                    breaking_change: major(&latest) != major(&current),
+                    module: module.path,
+                    current,
+                    latest,
+                });
+            }
+        }
+        Ok(upgrades)
+    }
+
+    /// `None` when the proxy has no such module (404 or 410)
+    async fn latest(&self, module: &str) -> Result<Option<String>> {
+        let url = format!("{}/{}/@latest", self.proxy, escape_path(module));
+        let response = self
+            .client
+            .get(&url)
+            .send()
+            .await
+            .with_context(|| format!("Failed to query {}", url))?;
+
+        let status = response.status();
+        if status == reqwest::StatusCode::NOT_FOUND || status == reqwest::StatusCode::GONE {
+            debug!(module, "Module proxy does not serve module");
+            return Ok(None);
+        }
+        if !status.is_success() {
+            bail!("{} returned {}", url, status);
+        }
+        let info: ModuleInfo = response
+            .json()
+            .await
+            .with_context(|| format!("Invalid response from {}", url))?;
+        Ok(Some(info.version))
+    }
+}
+
+/// Fills `upgrades_available` of every go mod result from its go.mod; a failed check is
+/// logged and leaves the result as it was
+pub async fn annotate(checker: &UpgradeChecker, results: &mut [UniversalBuild], repo_path: &Path) {
+    for result in results
+        .iter_mut()
+        .filter(|result| result.metadata.build_system == "go mod")
+    {
+        let go_mod = match result.metadata.service_path.as_deref() {
+            Some(path) => repo_path.join(path).join("go.mod"),
+            None => repo_path.join("go.mod"),
+        };
+        let Ok(content) = std::fs::read_to_string(&go_mod) else {
+            continue;
+        };
+        match checker.check_upgrades(&content).await {
+            Ok(upgrades) => result.metadata.upgrades_available = upgrades,
+            Err(e) => warn!("Upgrade check for {} failed: {:#}", go_mod.display(), e),
+        }
+    }
+}
+
+/// The module path of the next major version; `None` below v1, for `+incompatible` versions
+/// and for gopkg.in, whose paths carry the major version as `.vN`
+fn next_major_path(path: &str, version: &str) -> Option<String> {
+    if path.starts_with("gopkg.in/") || version.ends_with("+incompatible") {
+        return None;
+    }
+    let current = major(version).filter(|major| *major >= 1)?;
+    let base = match path.rsplit_once("/v") {
+        Some((base, suffix)) if suffix.parse::<u64>().is_ok_and(|n| n == current) => base,
+        _ => path,
+    };
+    Some(format!("{}/v{}", base, current + 1))
+}
+
+fn major(version: &str) -> Option<u64> {
+    semver_key(version).0.first().copied()
+}
+
+/// Module proxy path escaping: capitals become `!` and their lowercase letter
+fn escape_path(module: &str) -> String {
+    let mut escaped = String::with_capacity(module.len());
+    for c in module.chars() {
+        if c.is_ascii_uppercase() {
+            escaped.push('!');
+            escaped.push(c.to_ascii_lowercase());
+        } else {
+            escaped.push(c);
+        }
+    }
+    escaped
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use axum::http::{StatusCode, Uri};
+    use axum::Router;
+    use std::collections::HashMap;
+    use std::sync::{Arc, Mutex};
+    use std::time::Instant;
+
+    const GO_MOD: &str = r#"module example.com/app
+
+go 1.22
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/gin-gonic/gin v1.9.1
+	github.com/jackc/pgx/v4 v4.18.1
+	example.com/internal/auth v0.3.0
+)
+
+require golang.org/x/net v0.10.0 // indirect
+"#;
+
+    /// Serves `@latest` for `modules` (escaped path, version); every other path is a 404,
+    /// and `fail` answers 500
+    async fn mock_proxy(
+        modules: &[(&str, &str)],
+        fail: Option<&str>,
+    ) -> (String, Arc<Mutex<Vec<String>>>) {
+        let modules: HashMap<String, String> = modules
+            .iter()
+            .map(|(path, version)| (format!("/{}/@latest", path), version.to_string()))
+            .collect();
+        let fail = fail.map(|path| format!("/{}/@latest", path));
+        let requests = Arc::new(Mutex::new(Vec::new()));
+
+        let log = requests.clone();
+        let app = Router::new().fallback(move |uri: Uri| {
+            let path = uri.path().to_string();
+            log.lock().unwrap().push(path.clone());
+            let response = if fail.as_deref() == Some(path.as_str()) {
+                (StatusCode::INTERNAL_SERVER_ERROR, String::new())
+            } else {
+                match modules.get(&path) {
+                    Some(version) => (
+                        StatusCode::OK,
+                        format!(
+                            r#"{{"Version":"{}","Time":"2024-05-01T00:00:00Z"}}"#,
+                            version
+                        ),
+                    ),
+                    None => (StatusCode::NOT_FOUND, "not found".to_string()),
+                }
+            };
+            async move { response }
+        });
+
+        let listener = tokio::net::TcpListener::bind("127.0.0.1:0").await.unwrap();
+        let addr = listener.local_addr().unwrap();
+        tokio::spawn(async move { axum::serve(listener, app).await });
+        (format!("http://{}", addr), requests)
+    }
+
+    #[tokio::test]
+    async fn test_check_upgrades() {
+        let (proxy, requests) = mock_proxy(
+            &[
+                ("github.com/!burnt!sushi/toml", "v1.3.2"),
+                ("github.com/gin-gonic/gin", "v1.10.0"),
+                ("github.com/jackc/pgx/v4", "v4.18.3"),
+                ("github.com/jackc/pgx/v5", "v5.5.5"),
+            ],
+            None,
+        )
+        .await;
+        let checker = UpgradeChecker::new(&proxy).with_interval(Duration::from_millis(1));
+
+        let upgrades = checker.check_upgrades(GO_MOD).await.unwrap();
+        assert_eq!(
+            upgrades,
+            vec![
+                UpgradeAvailable {
+                    module: "github.com/gin-gonic/gin".to_string(),
+                    current: "v1.9.1".to_string(),
+                    latest: "v1.10.0".to_string(),
+                    breaking_change: false,
+                },
+                UpgradeAvailable {
+                    module: "github.com/jackc/pgx/v4".to_string(),
+                    current: "v4.18.1".to_string(),
+                    latest: "v5.5.5".to_string(),
+                    breaking_change: true,
+                },
+            ]
+        );
+        // Indirect requirements are left alone; modules below v1 have no next major path
+        assert_eq!(
+            *requests.lock().unwrap(),
+            vec![
+                "/github.com/!burnt!sushi/toml/@latest",
+                "/github.com/!burnt!sushi/toml/v2/@latest",
+                "/github.com/gin-gonic/gin/@latest",
+                "/github.com/gin-gonic/gin/v2/@latest",
+                "/github.com/jackc/pgx/v4/@latest",
+                "/github.com/jackc/pgx/v5/@latest",
+                "/example.com/internal/auth/@latest",
+            ]
+        );
+    }
+
+    #[tokio::test]
+    async fn test_requests_are_rate_limited() {
+        let (proxy, requests) = mock_proxy(&[], None).await;
+        let interval = Duration::from_millis(40);
+        let checker = UpgradeChecker::new(&proxy).with_interval(interval);
+
+        let start = Instant::now();
+        assert_eq!(checker.check_upgrades(GO_MOD).await.unwrap(), vec![]);
+        let sent = requests.lock().unwrap().len() as u32;
+        assert_eq!(sent, 7);
+        // The first request goes out at once, every later one waits its turn
+        assert!(start.elapsed() >= interval * (sent - 1));
+    }
+
+    #[tokio::test]
+    async fn test_proxy_errors_fail_the_check() {
+        let (proxy, _) = mock_proxy(&[], Some("github.com/gin-gonic/gin")).await;
+        let checker = UpgradeChecker::new(&proxy).with_interval(Duration::from_millis(1));
+
+        let err = checker.check_upgrades(GO_MOD).await.unwrap_err();
+        assert!(err.to_string().contains("500"), "{}", err);
+    }
+
+    #[tokio::test]
+    async fn test_dropping_the_check_cancels_it() {
+        let (proxy, requests) = mock_proxy(&[], None).await;
+        let checker = UpgradeChecker::new(&proxy).with_interval(Duration::from_secs(60));
+
+        let check =
+            tokio::time::timeout(Duration::from_millis(200), checker.check_upgrades(GO_MOD));
+        assert!(check.await.is_err());
+        tokio::time::sleep(Duration::from_millis(50)).await;
+        assert_eq!(requests.lock().unwrap().len(), 1);
+    }
+
+    #[test]
+    fn test_next_major_path() {
+        assert_eq!(
+            next_major_path("github.com/gin-gonic/gin", "v1.9.1").as_deref(),
+            Some("github.com/gin-gonic/gin/v2")
+        );
+        assert_eq!(
+            next_major_path("github.com/jackc/pgx/v4", "v4.18.1").as_deref(),
+            Some("github.com/jackc/pgx/v5")
+        );
+        assert_eq!(next_major_path("golang.org/x/net", "v0.10.0"), None);
+        assert_eq!(next_major_path("gopkg.in/yaml.v3", "v3.0.1"), None);
+        assert_eq!(
+            next_major_path("github.com/docker/docker", "v24.0.7+incompatible"),
+            None
+        );
+    }
+
+    #[test]
+    fn test_escape_path() {
+        assert_eq!(
+            escape_path("github.com/BurntSushi/toml"),
+            "github.com/!burnt!sushi/toml"
+        );
+        assert_eq!(escape_path("golang.org/x/net"), "golang.org/x/net");
+    }
+}
@@ -4,13 +4,27 @@ use peelbox_buildkit::{
     progress::ProgressTracker, AttestationConfig, BuildKitConnection, BuildSession, CacheExport,
     CacheImport, ProvenanceMode,
 };
-use peelbox_cli::cli::commands::{BuildArgs, CliArgs, Commands, DetectArgs, HealthArgs};
+use peelbox_cli::cli::commands::{
+    BuildArgs, CliArgs, Commands, DetectArgs, DiffArgs, HealthArgs, InitArgs,
+};
 use peelbox_cli::cli::output::{EnvVarInfo, HealthStatus, OutputFormat, OutputFormatter};
+use peelbox_cli::cli::output_dir::write_output_dir;
+use peelbox_cli::cli::scaffold;
+use peelbox_cli::cli::serve::{self, ServeConfig};
+use peelbox_cli::cli::template::{load_template, render_template};
+use peelbox_cli::cli::upgrades::{self, UpgradeChecker};
 use peelbox_cli::{NAME, VERSION};
 use peelbox_core::config::PeelboxConfig;
+use peelbox_core::fs::RealFileSystem;
+use peelbox_core::output::diff::diff_json;
+use peelbox_core::output::sbom::{generate_sbom, CycloneDxBom};
 use peelbox_core::output::schema::UniversalBuild;
 use peelbox_llm::{RecordingLLMClient, RecordingMode};
 use peelbox_pipeline::detection::service::DetectionService;
+use peelbox_pipeline::detection::WatchConfig;
+use peelbox_pipeline::extractors::DependencyDepthAnalyzer;
+use peelbox_pipeline::pipeline::phases::scan::ScanConfig;
+use peelbox_pipeline::LogLevel;
 
 use clap::Parser;
 use std::collections::HashMap;
@@ -19,6 +33,7 @@ use std::fs;
 use std::path::{Path, PathBuf};
 use std::process;
 use std::sync::Arc;
+use std::time::Duration;
 use tracing::{debug, error, info, warn, Level};
 use tracing_subscriber::{fmt, layer::SubscriberExt, util::SubscriberInitExt, EnvFilter};
 
@@ -31,9 +46,13 @@ async fn main() {
     debug!("Arguments: {:?}", args);
 
     let exit_code = match &args.command {
-        Commands::Detect(detect_args) => handle_detect(detect_args, args.quiet, args.verbose).await,
+        Commands::Detect(detect_args) => {
+            handle_detect(detect_args, LogLevel::from_flags(args.quiet, args.verbose)).await
+        }
         Commands::Health(health_args) => handle_health(health_args).await,
         Commands::Build(build_args) => handle_build(build_args, args.quiet, args.verbose).await,
+        Commands::Diff(diff_args) => handle_diff(diff_args),
+        Commands::Init(init_args) => handle_init(init_args),
     };
 
     process::exit(exit_code);
@@ -46,10 +65,8 @@ fn init_logging_from_args(args: &CliArgs) {
     INIT.call_once(|| {
         let level = if let Some(level_str) = &args.log_level {
             parse_level(level_str)
-        } else if args.verbose {
-            Level::DEBUG
-        } else if args.quiet {
-            Level::ERROR
+        } else if args.verbose || args.quiet {
+            LogLevel::from_flags(args.quiet, args.verbose).max_level()
         } else {
             let level_str = env::var("PEELBOX_LOG_LEVEL").unwrap_or_else(|_| "info".to_string());
             parse_level(&level_str)
@@ -89,7 +106,7 @@ fn parse_level(level_str: &str) -> Level {
     }
 }
 
-async fn handle_detect(args: &DetectArgs, quiet: bool, _verbose: bool) -> i32 {
+async fn handle_detect(args: &DetectArgs, log_level: LogLevel) -> i32 {
     info!("Starting build system detection");
 
     let repo_path = args
@@ -174,7 +191,6 @@ async fn handle_detect(args: &DetectArgs, quiet: bool, _verbose: bool) -> i32 {
         debug!("Using explicitly specified backend: {:?}", config.provider);
 
         use peelbox_llm::GenAIClient;
-        use std::time::Duration;
 
         let client = match GenAIClient::new(
             config.provider,
@@ -235,6 +251,14 @@ async fn handle_detect(args: &DetectArgs, quiet: bool, _verbose: bool) -> i32 {
         DetectionService::new(client)
     };
 
+    let service = service.with_scan_config(ScanConfig {
+        max_depth: args.max_depth,
+        cache_dir: args.cache_dir.clone(),
+        auth_token: args.auth_token.clone(),
+        gitlab_hosts: args.gitlab_hosts.clone(),
+        ..ScanConfig::default()
+    });
+
     info!(
         "Using backend: {} ({})",
         service.backend_name(),
@@ -243,9 +267,22 @@ async fn handle_detect(args: &DetectArgs, quiet: bool, _verbose: bool) -> i32 {
             .unwrap_or_else(|| "default".to_string())
     );
 
+    if let Some(addr) = &args.serve {
+        let config = ServeConfig {
+            timeout: Duration::from_secs(args.serve_timeout),
+        };
+        return match serve::serve(addr, service, config).await {
+            Ok(()) => 0,
+            Err(e) => {
+                error!("{:#}", e);
+                1
+            }
+        };
+    }
+
     info!("Analyzing repository: {}", repo_path.display());
 
-    let results: Vec<UniversalBuild> = match service.detect(repo_path.clone()).await {
+    let mut results: Vec<UniversalBuild> = match service.detect(repo_path.clone()).await {
         Ok(r) => r,
         Err(e) => {
             error!("Detection failed: {}", e);
@@ -255,14 +292,104 @@ async fn handle_detect(args: &DetectArgs, quiet: bool, _verbose: bool) -> i32 {
 
     info!("Detection complete: {} projects detected", results.len());
 
-    let format: OutputFormat = args.format.into();
-    let formatter = OutputFormatter::new(format);
+    if args.check_upgrades {
+        info!("Checking Go dependencies for upgrades");
+        let checker = UpgradeChecker::from_env();
+        tokio::select! {
+            _ = upgrades::annotate(&checker, &mut results, &repo_path) => {}
+            _ = tokio::signal::ctrl_c() => warn!("Upgrade check cancelled"),
+        }
+    }
+    if args.dependency_depth {
+        DependencyDepthAnalyzer::suggest_missing_graphs(&mut results, &repo_path, &RealFileSystem);
+    }
 
-    let output = match formatter.format_multiple(&results) {
-        Ok(out) => out,
+    let exit_code = emit_detection(args, &results, &repo_path, log_level);
+    if !args.watch || exit_code != 0 {
+        return exit_code;
+    }
+
+    let shutdown = async {
+        tokio::signal::ctrl_c().await.ok();
+    };
+    let watched = service
+        .watch(
+            repo_path.clone(),
+            WatchConfig {
+                scan: service.scan_config().clone(),
+                ..WatchConfig::default()
+            },
+            shutdown,
+            |results| {
+                info!("Detection updated: {} projects detected", results.len());
+                emit_detection(args, &results, &repo_path, log_level);
+            },
+        )
+        .await;
+    match watched {
+        Ok(()) => 0,
         Err(e) => {
-            error!("Failed to format output: {}", e);
-            return 1;
+            error!("{}", e);
+            1
+        }
+    }
+}
+
+/// Writes the detection result (or its SBOM) to `--output`, `--output-dir` or stdout
+fn emit_detection(
+    args: &DetectArgs,
+    results: &[UniversalBuild],
+    repo_path: &Path,
+    log_level: LogLevel,
+) -> i32 {
+    if let Some(output_dir) = &args.output_dir {
+        return match write_output_dir(results, output_dir, args.force) {
+            Ok(written) => {
+                info!("Wrote {} files to: {}", written.len(), output_dir.display());
+                if log_level != LogLevel::Quiet {
+                    eprintln!("Output written to: {}", output_dir.display());
+                }
+                0
+            }
+            Err(e) => {
+                error!("Failed to write output directory: {:#}", e);
+                1
+            }
+        };
+    }
+
+    let output = if args.sbom {
+        match sbom_output(results, repo_path) {
+            Ok(out) => out,
+            Err(e) => {
+                error!("Failed to generate SBOM: {}", e);
+                return 1;
+            }
+        }
+    } else if let Some(template) = &args.template {
+        let rendered = load_template(template).and_then(|template| {
+            results
+                .iter()
+                .map(|result| render_template(result, &template))
+                .collect::<anyhow::Result<String>>()
+        });
+        match rendered {
+            Ok(out) => out,
+            Err(e) => {
+                error!("Failed to render template: {}", e);
+                return 1;
+            }
+        }
+    } else {
+        let format: OutputFormat = args.format.into();
+        let formatter = OutputFormatter::new(format);
+
+        match formatter.format_multiple(results) {
+            Ok(out) => out,
+            Err(e) => {
+                error!("Failed to format output: {}", e);
+                return 1;
+            }
         }
     };
 
@@ -270,8 +397,8 @@ async fn handle_detect(args: &DetectArgs, quiet: bool, _verbose: bool) -> i32 {
         match std::fs::write(output_file, &output) {
             Ok(_) => {
                 info!("Output written to: {}", output_file.display());
-                if !quiet {
-                    println!("Output written to: {}", output_file.display());
+                if log_level != LogLevel::Quiet {
+                    eprintln!("Output written to: {}", output_file.display());
                 }
             }
             Err(e) => {
@@ -279,6 +406,9 @@ async fn handle_detect(args: &DetectArgs, quiet: bool, _verbose: bool) -> i32 {
                 return 1;
             }
         }
+    } else if args.template.is_some() {
+        // Templates control their own trailing newline
+        print!("{}", output);
     } else {
         println!("{}", output);
     }
@@ -286,6 +416,136 @@ async fn handle_detect(args: &DetectArgs, quiet: bool, _verbose: bool) -> i32 {
     0
 }
 
+/// CycloneDX JSON for a single service; a JSON array with one BOM per service otherwise
+fn sbom_output(results: &[UniversalBuild], repo_path: &Path) -> anyhow::Result<String> {
+    let boms = results
+        .iter()
+        .map(|result| {
+            let service_dir = match result.metadata.service_path.as_deref() {
+                Some(path) => repo_path.join(path),
+                None => repo_path.to_path_buf(),
+            };
+            generate_sbom(result, &service_dir)
+        })
+        .collect::<anyhow::Result<Vec<CycloneDxBom>>>()?;
+
+    match boms.as_slice() {
+        [bom] => bom.to_json(),
+        _ => Ok(serde_json::to_string_pretty(&boms)?),
+    }
+}
+
+/// Exit codes follow diff(1): 0 when the results match, 1 when they differ, 2 on errors
+fn handle_diff(args: &DiffArgs) -> i32 {
+    let read = |path: &Path| -> Option<serde_json::Value> {
+        let content = match fs::read_to_string(path) {
+            Ok(content) => content,
+            Err(e) => {
+                error!("Failed to read {}: {}", path.display(), e);
+                return None;
+            }
+        };
+        match serde_json::from_str(&content) {
+            Ok(value) => Some(value),
+            Err(e) => {
+                error!("Failed to parse {} as JSON: {}", path.display(), e);
+                None
+            }
+        }
+    };
+
+    let (Some(before), Some(after)) = (read(&args.before), read(&args.after)) else {
+        return 2;
+    };
+
+    let diff = diff_json(&before, &after);
+    match serde_json::to_string_pretty(&diff) {
+        Ok(output) => println!("{}", output),
+        Err(e) => {
+            error!("Failed to format diff: {}", e);
+            return 2;
+        }
+    }
+
+    if diff.is_empty() {
+        0
+    } else {
+        1
+    }
+}
+
+fn handle_init(args: &InitArgs) -> i32 {
+    let spec = match (&args.spec, &args.language, &args.name) {
+        (Some(path), _, _) => read_init_spec(path).map(|mut spec| {
+            if let Some(name) = &args.name {
+                spec.metadata.project_name = Some(name.clone());
+            }
+            spec
+        }),
+        (None, Some(language), Some(name)) => scaffold::spec(
+            language,
+            args.build_system.as_deref(),
+            args.framework.as_deref(),
+            name,
+        ),
+        _ => Err(anyhow::anyhow!(
+            "--language and --name are required without --spec"
+        )),
+    };
+    let spec = match spec {
+        Ok(spec) => spec,
+        Err(e) => {
+            error!("{:#}", e);
+            return 1;
+        }
+    };
+
+    let target_dir = args
+        .target_dir
+        .clone()
+        .unwrap_or_else(|| PathBuf::from("."));
+    if let Err(e) = fs::create_dir_all(&target_dir) {
+        error!("Failed to create {}: {}", target_dir.display(), e);
+        return 1;
+    }
+
+    match scaffold::scaffold(&spec, &target_dir) {
+        Ok(written) => {
+            for path in written {
+                println!("{}", target_dir.join(path).display());
+            }
+            info!(
+                "Scaffolded {} project '{}' in {}",
+                spec.metadata.language,
+                spec.metadata.project_name.as_deref().unwrap_or_default(),
+                target_dir.display()
+            );
+            0
+        }
+        Err(e) => {
+            error!("Failed to scaffold project: {:#}", e);
+            1
+        }
+    }
+}
+
+/// A `peelbox detect` result with a single service, as an object or a one-element array
+fn read_init_spec(path: &Path) -> anyhow::Result<UniversalBuild> {
+    let content = fs::read_to_string(path)
+        .map_err(|e| anyhow::anyhow!("Failed to read spec file {}: {}", path.display(), e))?;
+    let mut specs = serde_json::from_str::<Vec<UniversalBuild>>(&content)
+        .or_else(|_| serde_json::from_str::<UniversalBuild>(&content).map(|spec| vec![spec]))
+        .map_err(|e| anyhow::anyhow!("Failed to parse spec file {}: {}", path.display(), e))?;
+    if specs.len() != 1 {
+        anyhow::bail!(
+            "{} describes {} services; init generates one",
+            path.display(),
+            specs.len()
+        );
+    }
+    Ok(specs.remove(0))
+}
+
 fn mask_api_key(value: &str) -> String {
     if value.len() <= 8 {
         "*".repeat(value.len())
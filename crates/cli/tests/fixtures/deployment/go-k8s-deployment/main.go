@@ -0,0 +1,18 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+func main() {
+	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	http.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]string{"widget", "gadget"})
+	})
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
@@ -0,0 +1,14 @@
+resource "aws_eks_cluster" "orders" {
+  name     = "orders"
+  role_arn = var.cluster_role_arn
+
+  vpc_config {
+    subnet_ids = var.subnet_ids
+  }
+}
+
+resource "kubernetes_namespace" "orders" {
+  metadata {
+    name = "orders"
+  }
+}
@@ -0,0 +1,29 @@
+terraform {
+  required_version = ">= 1.6.0"
+
+  backend "s3" {
+    bucket = "orders-terraform-state"
+    key    = "orders/terraform.tfstate"
+    region = "us-east-1"
+  }
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    kubernetes = {
+      source  = "hashicorp/kubernetes"
+      version = "~> 2.27"
+    }
+  }
+}
+
+provider "aws" {
+  region = var.region
+}
+
+provider "kubernetes" {
+  host                   = aws_eks_cluster.orders.endpoint
+  cluster_ca_certificate = base64decode(aws_eks_cluster.orders.certificate_authority[0].data)
+}
@@ -0,0 +1,9 @@
+variable "region" {
+  default = "us-east-1"
+}
+
+variable "cluster_role_arn" {}
+
+variable "subnet_ids" {
+  type = list(string)
+}
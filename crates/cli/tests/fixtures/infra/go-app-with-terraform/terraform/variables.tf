@@ -0,0 +1,5 @@
+variable "region" {
+  description = "AWS region the shop is deployed to"
+  type        = string
+  default     = "eu-west-1"
+}
@@ -0,0 +1,3 @@
+module example.com/nx-go-node/api
+
+go 1.22
@@ -0,0 +1,13 @@
+import express from "express";
+import { formatPrice } from "@shop/shared";
+
+const app = express();
+const port = Number(process.env.PORT ?? 3000);
+
+app.get("/api/products", (_req, res) => {
+  res.json([{ id: "1", price: formatPrice(1999) }]);
+});
+
+app.listen(port, () => {
+  console.log(`api listening on ${port}`);
+});
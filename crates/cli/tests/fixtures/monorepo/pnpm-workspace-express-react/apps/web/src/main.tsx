@@ -0,0 +1,8 @@
+import { createRoot } from "react-dom/client";
+import { formatPrice } from "@shop/shared";
+
+function App() {
+  return <h1>Today only: {formatPrice(1999)}</h1>;
+}
+
+createRoot(document.getElementById("root")!).render(<App />);
@@ -0,0 +1,13 @@
+import { useEffect, useState } from "react";
+
+export default function App() {
+  const [orders, setOrders] = useState([]);
+
+  useEffect(() => {
+    fetch("/api/orders")
+      .then((response) => response.json())
+      .then(setOrders);
+  }, []);
+
+  return <ul>{orders.map((order) => <li key={order.id}>{order.total}</li>)}</ul>;
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"example.com/shop/internal/store"
+)
+
+// Register mounts the order endpoints the frontend calls
+func Register(mux *http.ServeMux, orders *store.Orders) {
+	mux.HandleFunc("/api/orders", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(orders.List())
+	})
+}
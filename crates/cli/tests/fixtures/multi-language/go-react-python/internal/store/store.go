@@ -0,0 +1,20 @@
+package store
+
+// Order is a placed order
+type Order struct {
+	ID    string  `json:"id"`
+	Total float64 `json:"total"`
+}
+
+// Orders keeps orders in memory
+type Orders struct {
+	items []Order
+}
+
+func New() *Orders {
+	return &Orders{}
+}
+
+func (o *Orders) List() []Order {
+	return o.items
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"example.com/shop/internal/api"
+	"example.com/shop/internal/store"
+)
+
+func main() {
+	mux := http.NewServeMux()
+	api.Register(mux, store.New())
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	log.Fatal(http.ListenAndServe(":8080", mux))
+}
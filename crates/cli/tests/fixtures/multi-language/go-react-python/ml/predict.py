@@ -0,0 +1,12 @@
+import pickle
+import sys
+
+
+def predict(value):
+    with open("model.pkl", "rb") as f:
+        model = pickle.load(f)
+    return model.predict([[value]])[0]
+
+
+if __name__ == "__main__":
+    print(predict(float(sys.argv[1])))
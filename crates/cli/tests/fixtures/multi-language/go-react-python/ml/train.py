@@ -0,0 +1,15 @@
+import pickle
+
+import numpy as np
+from sklearn.linear_model import LinearRegression
+
+
+def main():
+    totals = np.array([[1], [2], [3]])
+    model = LinearRegression().fit(totals, np.array([10.0, 20.0, 30.0]))
+    with open("model.pkl", "wb") as f:
+        pickle.dump(model, f)
+
+
+if __name__ == "__main__":
+    main()
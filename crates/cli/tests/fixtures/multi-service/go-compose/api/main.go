@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+)
+
+func main() {
+	databaseURL := os.Getenv("DATABASE_URL")
+
+	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	log.Printf("connecting to %s", databaseURL)
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
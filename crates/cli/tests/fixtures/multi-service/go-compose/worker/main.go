@@ -0,0 +1,16 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+func main() {
+	databaseURL := os.Getenv("DATABASE_URL")
+
+	for {
+		log.Printf("polling jobs from %s", databaseURL)
+		time.Sleep(10 * time.Second)
+	}
+}
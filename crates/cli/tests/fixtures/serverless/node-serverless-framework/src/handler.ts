@@ -0,0 +1,11 @@
+interface Order {
+  id: number;
+  item: string;
+}
+
+const orders: Order[] = [{ id: 1, item: 'coffee' }];
+
+export const listOrders = async () => ({
+  statusCode: 200,
+  body: JSON.stringify({ orders }),
+});
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	r := gin.Default()
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	r.GET("/greet/:name", func(c *gin.Context) {
+		c.String(http.StatusOK, "Hello, %s!", c.Param("name"))
+	})
+	r.Run(":8080")
+}
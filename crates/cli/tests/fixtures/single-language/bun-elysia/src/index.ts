@@ -0,0 +1,8 @@
+import { Elysia } from "elysia";
+
+const app = new Elysia()
+  .get("/", () => "Hello from Elysia")
+  .get("/health", () => ({ status: "ok" }))
+  .listen(3000);
+
+console.log(`Listening on ${app.server?.hostname}:${app.server?.port}`);
@@ -0,0 +1,17 @@
+import 'dart:io';
+
+import 'package:shelf/shelf.dart';
+import 'package:shelf/shelf_io.dart';
+import 'package:shelf_router/shelf_router.dart';
+
+final _router = Router()
+  ..get('/health', (Request request) => Response.ok('ok'))
+  ..get('/notes', (Request request) => Response.ok('[]'));
+
+void main(List<String> args) async {
+  final handler = Pipeline().addMiddleware(logRequests()).addHandler(_router.call);
+
+  final port = int.parse(Platform.environment['PORT'] ?? '8080');
+  final server = await serve(handler, InternetAddress.anyIPv4, port);
+  print('Server listening on port ${server.port}');
+}
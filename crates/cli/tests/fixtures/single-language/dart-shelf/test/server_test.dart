@@ -0,0 +1,9 @@
+import 'package:http/http.dart';
+import 'package:test/test.dart';
+
+void main() {
+  test('health', () async {
+    final response = await get(Uri.parse('http://localhost:8080/health'));
+    expect(response.statusCode, 200);
+  });
+}
@@ -0,0 +1,18 @@
+import { Application, Router } from "oak";
+
+const items = [{ id: 1, name: "widget", quantity: 12 }];
+
+const router = new Router();
+router.get("/health", (ctx) => {
+  ctx.response.body = { status: "ok" };
+});
+router.get("/items", (ctx) => {
+  ctx.response.body = items;
+});
+
+const app = new Application();
+app.use(router.routes());
+app.use(router.allowedMethods());
+
+const port = Number(Deno.env.get("PORT") ?? "8000");
+await app.listen({ port });
@@ -0,0 +1,21 @@
+var builder = WebApplication.CreateBuilder(args);
+var app = builder.Build();
+
+var orders = new List<Order>
+{
+    new(1, "widget", 3),
+    new(2, "gadget", 1),
+};
+
+app.MapGet("/health", () => Results.Ok(new { status = "healthy" }));
+
+app.MapGet("/orders", () => orders);
+
+app.MapGet("/orders/{id:int}", (int id) =>
+    orders.FirstOrDefault(o => o.Id == id) is { } order
+        ? Results.Ok(order)
+        : Results.NotFound());
+
+app.Run();
+
+record Order(int Id, string Item, int Quantity);
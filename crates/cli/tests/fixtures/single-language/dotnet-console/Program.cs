@@ -0,0 +1,3 @@
+var name = args.Length > 0 ? args[0] : Environment.GetEnvironmentVariable("GREET_NAME") ?? "world";
+
+Console.WriteLine($"Hello, {name}!");
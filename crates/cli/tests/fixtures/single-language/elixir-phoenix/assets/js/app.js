@@ -0,0 +1 @@
+import "phoenix_html";
@@ -0,0 +1,9 @@
+import Config
+
+config :shop, ShopWeb.Endpoint,
+  url: [host: "localhost"],
+  render_errors: [formats: [json: ShopWeb.ErrorJSON], layout: false]
+
+config :phoenix, :json_library, Jason
+
+import_config "#{config_env()}.exs"
@@ -0,0 +1,8 @@
+import Config
+
+config :shop, ShopWeb.Endpoint,
+  http: [ip: {0, 0, 0, 0}, port: 4000],
+  cache_static_manifest: "priv/static/cache_manifest.json",
+  server: true
+
+config :logger, level: :info
@@ -0,0 +1,11 @@
+import Config
+
+if config_env() == :prod do
+  secret_key_base =
+    System.get_env("SECRET_KEY_BASE") ||
+      raise "environment variable SECRET_KEY_BASE is missing."
+
+  config :shop, ShopWeb.Endpoint,
+    url: [host: System.get_env("PHX_HOST") || "example.com", port: 443, scheme: "https"],
+    secret_key_base: secret_key_base
+end
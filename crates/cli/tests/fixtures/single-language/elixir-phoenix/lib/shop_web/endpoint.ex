@@ -0,0 +1,7 @@
+defmodule ShopWeb.Endpoint do
+  use Phoenix.Endpoint, otp_app: :shop
+
+  plug Plug.Static, at: "/", from: :shop, gzip: true, only: ~w(assets favicon.ico robots.txt)
+  plug Plug.Parsers, parsers: [:urlencoded, :json], json_decoder: Jason
+  plug ShopWeb.Router
+end
@@ -0,0 +1,13 @@
+defmodule ShopWeb.Router do
+  use Phoenix.Router
+
+  pipeline :api do
+    plug :accepts, ["json"]
+  end
+
+  scope "/", ShopWeb do
+    pipe_through :api
+
+    get "/health", HealthController, :show
+  end
+end
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const spool = "/var/spool/reports"
+
+// Every hour, removes reports older than a day from the spool directory
+func main() {
+	for range time.Tick(time.Hour) {
+		if err := prune(spool, 24*time.Hour); err != nil {
+			fmt.Fprintln(os.Stderr, "prune:", err)
+		}
+	}
+}
+
+func prune(dir string, maxAge time.Duration) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if time.Since(info.ModTime()) > maxAge {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
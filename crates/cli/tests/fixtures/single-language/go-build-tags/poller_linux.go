@@ -0,0 +1,11 @@
+//go:build linux && (amd64 || arm64)
+
+package main
+
+import "syscall"
+
+// startPoller creates the epoll instance used to watch connections
+func startPoller() error {
+	_, err := syscall.EpollCreate1(0)
+	return err
+}
@@ -0,0 +1,24 @@
+package main
+
+/*
+static int add(int a, int b) {
+	return a + b;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func main() {
+	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "2 + 3 = %d\n", int(C.add(2, 3)))
+	})
+
+	http.ListenAndServe(":8080", nil)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "greet [name]",
+	Short: "Print a greeting",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := viper.GetString("name")
+		if len(args) == 1 {
+			name = args[0]
+		}
+		fmt.Printf("%s, %s!\n", viper.GetString("greeting"), name)
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().String("greeting", "Hello", "greeting to print")
+	viper.BindPFlag("greeting", rootCmd.PersistentFlags().Lookup("greeting"))
+	viper.SetDefault("name", "world")
+	viper.SetEnvPrefix("greet")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
+}
+
+// Execute runs the root command and exits non-zero on error
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,8 @@
+module example.com/greet
+
+go 1.22
+
+require (
+	github.com/spf13/cobra v1.8.1
+	github.com/spf13/viper v1.19.0
+)
@@ -0,0 +1,7 @@
+package main
+
+import "example.com/greet/cmd"
+
+func main() {
+	cmd.Execute()
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("addr", ":3000", "listen address")
+	flag.Parse()
+
+	http.HandleFunc("/notes", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "[]")
+	})
+
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
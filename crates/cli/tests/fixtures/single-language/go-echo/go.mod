@@ -0,0 +1,5 @@
+module example.com/echoapp
+
+go 1.22
+
+require github.com/labstack/echo/v4 v4.11.4
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"log"
+	"os"
+)
+
+type Config struct {
+	DatabaseURL string
+	SecretKey   string
+	LogLevel    string
+}
+
+func Load() Config {
+	secret, ok := os.LookupEnv("SECRET_KEY")
+	if !ok {
+		log.Fatal("SECRET_KEY must be set")
+	}
+
+	level := os.Getenv("LOG_LEVEL")
+	if level == "" {
+		level = "info"
+	}
+
+	return Config{
+		DatabaseURL: os.Getenv("DATABASE_URL"),
+		SecretKey:   secret,
+		LogLevel:    level,
+	}
+}
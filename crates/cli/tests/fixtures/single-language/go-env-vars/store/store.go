@@ -0,0 +1,20 @@
+package store
+
+import (
+	"database/sql"
+	"os"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+func Open(url string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	if os.Getenv("DATABASE_URL") != "" {
+		db.SetConnMaxLifetime(5 * time.Minute)
+	}
+	return db, nil
+}
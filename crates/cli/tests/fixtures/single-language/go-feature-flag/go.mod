@@ -0,0 +1,12 @@
+module example.com/checkout
+
+go 1.22
+
+require github.com/thomaspoignant/go-feature-flag v1.28.2
+
+require (
+	github.com/BurntSushi/toml v1.3.2 // indirect
+	github.com/nikunjy/rules v1.5.0 // indirect
+	golang.org/x/exp v0.0.0-20240409090435-93d18d7e34b8 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	ffclient "github.com/thomaspoignant/go-feature-flag"
+	"github.com/thomaspoignant/go-feature-flag/ffcontext"
+	"github.com/thomaspoignant/go-feature-flag/retriever/fileretriever"
+)
+
+func main() {
+	err := ffclient.Init(ffclient.Config{
+		PollingInterval: 10 * time.Second,
+		Context:         context.Background(),
+		Retriever:       &fileretriever.Retriever{Path: "flags.goff.yaml"},
+	})
+	if err != nil {
+		log.Fatalf("unable to load feature flags: %v", err)
+	}
+	defer ffclient.Close()
+
+	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	http.HandleFunc("/checkout", func(w http.ResponseWriter, r *http.Request) {
+		user := ffcontext.NewEvaluationContext(r.Header.Get("X-User-ID"))
+		newCheckout, _ := ffclient.BoolVariation("new-checkout", user, false)
+		json.NewEncoder(w).Encode(map[string]bool{"new_checkout": newCheckout})
+	})
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"example.com/gin-health/routes"
+)
+
+func main() {
+	r := gin.Default()
+	routes.Register(r)
+	r.Run(":8080")
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Register(r *gin.Engine) {
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	api := r.Group("/api")
+	api.GET("/items", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"items": []string{"a", "b"}})
+	})
+}
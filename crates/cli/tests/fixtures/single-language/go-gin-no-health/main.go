@@ -0,0 +1,20 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	r := gin.Default()
+
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	r.GET("/items", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"items": []string{"a", "b"}})
+	})
+
+	r.Run(":8080")
+}
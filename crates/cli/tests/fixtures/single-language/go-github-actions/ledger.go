@@ -0,0 +1,10 @@
+package main
+
+// Balance sums entries in cents
+func Balance(entries []int64) int64 {
+	var total int64
+	for _, entry := range entries {
+		total += entry
+	}
+	return total
+}
@@ -0,0 +1,9 @@
+package main
+
+import "testing"
+
+func TestBalance(t *testing.T) {
+	if got := Balance([]int64{1250, -250}); got != 1000 {
+		t.Fatalf("Balance() = %d, want 1000", got)
+	}
+}
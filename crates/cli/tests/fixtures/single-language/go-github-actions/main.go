@@ -0,0 +1,15 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+func main() {
+	http.HandleFunc("/balance", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]int64{"cents": Balance(nil)})
+	})
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE products (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    price_cents INTEGER NOT NULL
+);
+
+-- +goose Down
+DROP TABLE products;
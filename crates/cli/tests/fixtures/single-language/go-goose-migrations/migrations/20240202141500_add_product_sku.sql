@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE products ADD COLUMN sku TEXT UNIQUE;
+
+-- +goose Down
+ALTER TABLE products DROP COLUMN sku;
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func main() {
+	r := mux.NewRouter()
+
+	r.HandleFunc("/items", func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode([]string{"a", "b"})
+	}).Methods(http.MethodGet)
+
+	log.Fatal(http.ListenAndServe(":8080", r))
+}
@@ -0,0 +1,8 @@
+module example.com/todos
+
+go 1.22
+
+require (
+	github.com/99designs/gqlgen v0.17.49
+	github.com/vektah/gqlparser/v2 v2.5.16
+)
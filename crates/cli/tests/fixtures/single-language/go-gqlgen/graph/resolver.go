@@ -0,0 +1,8 @@
+package graph
+
+import "example.com/todos/graph/model"
+
+// Resolver is the root of the dependency graph handed to every resolver.
+type Resolver struct {
+	todos []*model.Todo
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"log"
+	"net"
+
+	"google.golang.org/grpc"
+
+	pb "example.com/greeter/gen/greeter/v1"
+	"example.com/greeter/server"
+)
+
+func main() {
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := grpc.NewServer()
+	pb.RegisterGreeterServer(s, &server.Greeter{})
+
+	log.Printf("listening on %s", lis.Addr())
+	log.Fatal(s.Serve(lis))
+}
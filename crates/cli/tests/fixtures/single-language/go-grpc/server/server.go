@@ -0,0 +1,15 @@
+package server
+
+import (
+	"context"
+
+	pb "example.com/greeter/proto"
+)
+
+type Greeter struct {
+	pb.UnimplementedGreeterServer
+}
+
+func (g *Greeter) SayHello(ctx context.Context, req *pb.HelloRequest) (*pb.HelloReply, error) {
+	return &pb.HelloReply{Message: "Hello " + req.GetName()}, nil
+}
@@ -0,0 +1,15 @@
+// Package slugify turns titles into URL path segments.
+package slugify
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Make lowercases s and joins its runs of letters and digits with hyphens.
+func Make(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.ToLower(strings.Join(words, "-"))
+}
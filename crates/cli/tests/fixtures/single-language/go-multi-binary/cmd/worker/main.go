@@ -0,0 +1,13 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+func main() {
+	for {
+		log.Println("processing queue")
+		time.Sleep(10 * time.Second)
+	}
+}
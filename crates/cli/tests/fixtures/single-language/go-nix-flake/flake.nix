@@ -0,0 +1,18 @@
+{
+  description = "Ledger service";
+
+  inputs.nixpkgs.url = "github:NixOS/nixpkgs/nixos-24.05";
+
+  outputs = { self, nixpkgs }:
+    let
+      pkgs = nixpkgs.legacyPackages.x86_64-linux;
+    in {
+      devShells.x86_64-linux.default = pkgs.mkShell {
+        packages = [
+          pkgs.go_1_22
+          pkgs.gopls
+          pkgs.postgresql_16
+        ];
+      };
+    };
+}
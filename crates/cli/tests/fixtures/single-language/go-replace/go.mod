@@ -0,0 +1,14 @@
+module example.com/replacer
+
+go 1.22
+
+require (
+	example.com/shared v0.0.0
+	github.com/pkg/errors v0.9.1
+)
+
+// Fork carrying an unreleased fix
+replace github.com/pkg/errors => github.com/acme/errors v0.9.2
+
+// Sibling checkout, only present next to this module locally
+replace example.com/shared => ../shared
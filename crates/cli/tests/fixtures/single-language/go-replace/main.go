@@ -0,0 +1,22 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"example.com/shared/greeting"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(greeting.Hello()))
+	})
+
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		log.Fatal(errors.Wrap(err, "serve"))
+	}
+}
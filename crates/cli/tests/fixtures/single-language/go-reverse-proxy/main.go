@@ -0,0 +1,24 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+func main() {
+	target, err := url.Parse("http://backend:9000")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/", httputil.NewSingleHostReverseProxy(target))
+
+	log.Println("proxying :8080 to", target)
+	log.Fatal(http.ListenAndServe(":8080", mux))
+}
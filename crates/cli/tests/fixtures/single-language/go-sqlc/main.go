@@ -0,0 +1,36 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+
+	_ "github.com/lib/pq"
+
+	"example.com/bookstore/internal/db"
+)
+
+func main() {
+	conn, err := sql.Open("postgres", os.Getenv("DATABASE_URL"))
+	if err != nil {
+		log.Fatalf("unable to open database: %v", err)
+	}
+	defer conn.Close()
+	queries := db.New(conn)
+
+	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	http.HandleFunc("/books", func(w http.ResponseWriter, r *http.Request) {
+		books, err := queries.ListBooks(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(books)
+	})
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
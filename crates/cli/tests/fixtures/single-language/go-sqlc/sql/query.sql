@@ -0,0 +1,2 @@
+-- name: ListBooks :many
+SELECT id, title, author FROM books ORDER BY title;
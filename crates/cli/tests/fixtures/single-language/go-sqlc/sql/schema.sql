@@ -0,0 +1,5 @@
+CREATE TABLE books (
+  id     BIGSERIAL PRIMARY KEY,
+  title  TEXT NOT NULL,
+  author TEXT NOT NULL
+);
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+func ReserveInventory(ctx context.Context, order Order) error {
+	if order.Quantity <= 0 {
+		return fmt.Errorf("order %s: invalid quantity %d", order.ID, order.Quantity)
+	}
+	return nil
+}
+
+func ChargePayment(ctx context.Context, order Order) error {
+	if order.Cents <= 0 {
+		return fmt.Errorf("order %s: nothing to charge", order.ID)
+	}
+	return nil
+}
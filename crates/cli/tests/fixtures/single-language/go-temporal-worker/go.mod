@@ -0,0 +1,14 @@
+module example.com/orders-worker
+
+go 1.22
+
+require go.temporal.io/sdk v1.26.1
+
+require (
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/golang/mock v1.6.0 // indirect
+	github.com/pborman/uuid v1.2.1 // indirect
+	go.temporal.io/api v1.29.2 // indirect
+	google.golang.org/grpc v1.62.1 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
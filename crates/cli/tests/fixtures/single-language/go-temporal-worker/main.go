@@ -0,0 +1,33 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+const taskQueue = "orders"
+
+func main() {
+	address := os.Getenv("TEMPORAL_ADDRESS")
+	if address == "" {
+		address = client.DefaultHostPort
+	}
+
+	c, err := client.Dial(client.Options{HostPort: address})
+	if err != nil {
+		log.Fatalf("unable to connect to Temporal: %v", err)
+	}
+	defer c.Close()
+
+	w := worker.New(c, taskQueue, worker.Options{})
+	w.RegisterWorkflow(OrderWorkflow)
+	w.RegisterActivity(ReserveInventory)
+	w.RegisterActivity(ChargePayment)
+
+	if err := w.Run(worker.InterruptCh()); err != nil {
+		log.Fatalf("worker stopped: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+type Order struct {
+	ID       string
+	SKU      string
+	Quantity int
+	Cents    int64
+}
+
+func OrderWorkflow(ctx workflow.Context, order Order) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+	})
+
+	if err := workflow.ExecuteActivity(ctx, ReserveInventory, order).Get(ctx, nil); err != nil {
+		return err
+	}
+	return workflow.ExecuteActivity(ctx, ChargePayment, order).Get(ctx, nil)
+}
@@ -0,0 +1,24 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strconv"
+	"syscall/js"
+)
+
+func main() {
+	document := js.Global().Get("document")
+	label := document.Call("getElementById", "count")
+	count := 0
+
+	increment := js.FuncOf(func(this js.Value, args []js.Value) any {
+		count++
+		label.Set("textContent", strconv.Itoa(count))
+		return nil
+	})
+	document.Call("getElementById", "increment").Call("addEventListener", "click", increment)
+
+	// Keep the Go runtime alive for the callbacks
+	select {}
+}
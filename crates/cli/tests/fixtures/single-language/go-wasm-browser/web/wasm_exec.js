@@ -0,0 +1,21 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Trimmed for the fixture: only the loader entry point detection looks for.
+
+"use strict";
+
+(() => {
+	globalThis.Go = class {
+		constructor() {
+			this.argv = ["js"];
+			this.env = {};
+			this.importObject = { gojs: {} };
+		}
+
+		async run(instance) {
+			this._inst = instance;
+		}
+	};
+})();
@@ -0,0 +1,8 @@
+module example.com/inventory
+
+go 1.22
+
+require (
+	github.com/google/wire v0.6.0
+	go.uber.org/mock v0.4.0
+)
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"example.com/inventory/store"
+)
+
+type Server struct {
+	store store.Store
+}
+
+func NewServer(s store.Store) *Server {
+	return &Server{store: s}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(s.store.List())
+}
@@ -0,0 +1,19 @@
+package store
+
+//go:generate mockgen -source=store.go -destination=mock_store.go -package=store
+
+type Store interface {
+	List() []string
+}
+
+type memoryStore struct {
+	items []string
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{}
+}
+
+func (m *memoryStore) List() []string {
+	return m.items
+}
@@ -0,0 +1,14 @@
+//go:build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/inventory/store"
+)
+
+func InitializeServer() *Server {
+	wire.Build(store.NewMemoryStore, NewServer)
+	return nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer logger.Sync()
+
+	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	http.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		logger.Info("listing orders", zap.String("remote_addr", r.RemoteAddr))
+		w.Write([]byte("[]\n"))
+	})
+
+	logger.Info("listening", zap.String("addr", ":8080"))
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		logger.Fatal("server stopped", zap.Error(err))
+	}
+}
@@ -0,0 +1,12 @@
+{-# LANGUAGE OverloadedStrings #-}
+
+module Main (main) where
+
+import Web.Scotty
+
+main :: IO ()
+main = scotty 3000 $ do
+  get "/health" $ text "ok"
+  get "/hello/:name" $ do
+    name <- pathParam "name"
+    text ("Hello, " <> name)
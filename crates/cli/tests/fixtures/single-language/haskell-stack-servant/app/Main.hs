@@ -0,0 +1,7 @@
+module Main (main) where
+
+import Api (app)
+import Network.Wai.Handler.Warp (run)
+
+main :: IO ()
+main = run 8080 app
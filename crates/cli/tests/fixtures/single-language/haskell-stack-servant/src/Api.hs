@@ -0,0 +1,26 @@
+{-# LANGUAGE DataKinds #-}
+{-# LANGUAGE DeriveGeneric #-}
+{-# LANGUAGE TypeOperators #-}
+
+module Api (app) where
+
+import Data.Aeson (ToJSON)
+import GHC.Generics (Generic)
+import Servant
+
+data Item = Item
+  { itemId :: Int
+  , itemName :: String
+  } deriving (Generic)
+
+instance ToJSON Item
+
+type CatalogApi =
+       "health" :> Get '[JSON] NoContent
+  :<|> "items" :> Get '[JSON] [Item]
+
+server :: Server CatalogApi
+server = pure NoContent :<|> pure [Item 1 "Widget"]
+
+app :: Application
+app = serve (Proxy :: Proxy CatalogApi) server
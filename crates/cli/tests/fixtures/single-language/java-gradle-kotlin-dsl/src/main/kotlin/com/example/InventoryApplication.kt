@@ -0,0 +1,19 @@
+package com.example
+
+import org.springframework.boot.autoconfigure.SpringBootApplication
+import org.springframework.boot.runApplication
+import org.springframework.web.bind.annotation.GetMapping
+import org.springframework.web.bind.annotation.RestController
+
+@SpringBootApplication
+class InventoryApplication
+
+@RestController
+class InventoryController {
+    @GetMapping("/items")
+    fun items() = listOf(mapOf("sku" to "A-100", "quantity" to 3))
+}
+
+fun main(args: Array<String>) {
+    runApplication<InventoryApplication>(*args)
+}
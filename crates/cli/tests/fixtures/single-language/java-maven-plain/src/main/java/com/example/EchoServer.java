@@ -0,0 +1,21 @@
+package com.example;
+
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+import java.net.ServerSocket;
+import java.net.Socket;
+
+public class EchoServer {
+    public static void main(String[] args) throws IOException {
+        try (ServerSocket server = new ServerSocket(9000)) {
+            while (true) {
+                try (Socket client = server.accept();
+                     InputStream in = client.getInputStream();
+                     OutputStream out = client.getOutputStream()) {
+                    in.transferTo(out);
+                }
+            }
+        }
+    }
+}
@@ -0,0 +1,28 @@
+plugins {
+    kotlin("jvm") version "1.9.23"
+    application
+}
+
+group = "com.example"
+version = "0.1.0"
+
+application {
+    mainClass.set("com.example.ApplicationKt")
+}
+
+java {
+    toolchain {
+        languageVersion = JavaLanguageVersion.of(17)
+    }
+}
+
+repositories {
+    mavenCentral()
+}
+
+dependencies {
+    implementation("io.ktor:ktor-server-core-jvm:2.3.10")
+    implementation("io.ktor:ktor-server-netty-jvm:2.3.10")
+    implementation("ch.qos.logback:logback-classic:1.4.14")
+    testImplementation("io.ktor:ktor-server-tests-jvm:2.3.10")
+}
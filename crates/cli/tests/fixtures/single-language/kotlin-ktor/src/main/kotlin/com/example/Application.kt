@@ -0,0 +1,20 @@
+package com.example
+
+import io.ktor.server.application.*
+import io.ktor.server.engine.*
+import io.ktor.server.netty.*
+import io.ktor.server.response.*
+import io.ktor.server.routing.*
+
+fun main() {
+    embeddedServer(Netty, port = 8080, host = "0.0.0.0") {
+        routing {
+            get("/") {
+                call.respondText("Hello from Ktor")
+            }
+            get("/health") {
+                call.respondText("OK")
+            }
+        }
+    }.start(wait = true)
+}
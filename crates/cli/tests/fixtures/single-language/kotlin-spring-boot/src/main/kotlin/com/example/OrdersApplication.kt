@@ -0,0 +1,19 @@
+package com.example
+
+import org.springframework.boot.autoconfigure.SpringBootApplication
+import org.springframework.boot.runApplication
+import org.springframework.web.bind.annotation.GetMapping
+import org.springframework.web.bind.annotation.RestController
+
+@SpringBootApplication
+class OrdersApplication
+
+@RestController
+class OrderController {
+    @GetMapping("/orders")
+    fun orders(): List<String> = listOf("order-1", "order-2")
+}
+
+fun main(args: Array<String>) {
+    runApplication<OrdersApplication>(*args)
+}
@@ -0,0 +1,9 @@
+const express = require("express");
+
+const app = express();
+const port = process.env.PORT || 3000;
+
+app.get("/health", (_req, res) => res.json({ status: "ok" }));
+app.get("/", (_req, res) => res.send("Hello from Express"));
+
+app.listen(port, () => console.log(`listening on ${port}`));
@@ -0,0 +1,13 @@
+<?php
+
+namespace App\Http\Controllers;
+
+use Illuminate\Http\JsonResponse;
+
+class StatusController
+{
+    public function index(): JsonResponse
+    {
+        return response()->json(['message' => 'Hello from Laravel']);
+    }
+}
@@ -0,0 +1,7 @@
+<?php
+
+use App\Http\Controllers\StatusController;
+use Illuminate\Support\Facades\Route;
+
+Route::get('/', [StatusController::class, 'index']);
+Route::get('/health', fn () => response()->json(['status' => 'ok']));
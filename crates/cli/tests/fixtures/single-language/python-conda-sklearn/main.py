@@ -0,0 +1,28 @@
+import os
+
+import joblib
+import pandas as pd
+from sklearn.linear_model import LogisticRegression
+from sklearn.model_selection import train_test_split
+
+DATA_PATH = os.environ.get("DATA_PATH", "data/customers.csv")
+MODEL_PATH = os.environ.get("MODEL_PATH", "model.joblib")
+
+
+def main():
+    customers = pd.read_csv(DATA_PATH)
+    features = customers[["tenure_months", "monthly_charges", "support_tickets"]]
+    labels = customers["churned"]
+
+    x_train, x_test, y_train, y_test = train_test_split(
+        features, labels, test_size=0.2, random_state=42
+    )
+    model = LogisticRegression(max_iter=1000)
+    model.fit(x_train, y_train)
+
+    print(f"Test accuracy: {model.score(x_test, y_test):.3f}")
+    joblib.dump(model, MODEL_PATH)
+
+
+if __name__ == "__main__":
+    main()
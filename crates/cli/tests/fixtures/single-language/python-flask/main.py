@@ -0,0 +1,19 @@
+import os
+
+from flask import Flask, jsonify
+
+app = Flask(__name__)
+
+
+@app.route("/health")
+def health():
+    return jsonify(status="ok")
+
+
+@app.route("/")
+def index():
+    return jsonify(message="Hello from Flask")
+
+
+if __name__ == "__main__":
+    app.run(host="0.0.0.0", port=int(os.getenv("PORT", "5000")))
@@ -0,0 +1,5 @@
+class ProductsController < ActionController::Base
+  def index
+    render json: [{ id: 1, name: "Widget" }]
+  end
+end
@@ -0,0 +1,11 @@
+require_relative "boot"
+
+require "rails"
+require "action_controller/railtie"
+
+module Storefront
+  class Application < Rails::Application
+    config.load_defaults 7.1
+    config.eager_load = true
+  end
+end
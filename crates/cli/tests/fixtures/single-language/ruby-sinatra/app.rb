@@ -0,0 +1,15 @@
+require 'sinatra'
+require 'json'
+
+set :bind, '0.0.0.0'
+set :port, ENV.fetch('PORT', 4567).to_i
+
+get '/' do
+  content_type :json
+  { message: 'Hello from Sinatra' }.to_json
+end
+
+get '/health' do
+  content_type :json
+  { status: 'ok' }.to_json
+end
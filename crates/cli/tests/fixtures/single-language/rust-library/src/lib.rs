@@ -0,0 +1,14 @@
+/// Collapses runs of whitespace into single spaces.
+pub fn normalize_whitespace(input: &str) -> String {
+    input.split_whitespace().collect::<Vec<_>>().join(" ")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn collapses_whitespace() {
+        assert_eq!(normalize_whitespace("a   b\n c"), "a b c");
+    }
+}
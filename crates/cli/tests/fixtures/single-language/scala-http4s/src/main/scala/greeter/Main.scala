@@ -0,0 +1,22 @@
+package greeter
+
+import cats.effect.{IO, IOApp}
+import com.comcast.ip4s._
+import org.http4s.HttpRoutes
+import org.http4s.dsl.io._
+import org.http4s.ember.server.EmberServerBuilder
+
+object Main extends IOApp.Simple {
+  private val routes = HttpRoutes.of[IO] {
+    case GET -> Root / "hello" / name => Ok(s"Hello, $name")
+  }
+
+  val run: IO[Unit] =
+    EmberServerBuilder
+      .default[IO]
+      .withHost(ipv4"0.0.0.0")
+      .withPort(port"8080")
+      .withHttpApp(routes.orNotFound)
+      .build
+      .useForever
+}
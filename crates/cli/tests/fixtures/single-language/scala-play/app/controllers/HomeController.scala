@@ -0,0 +1,17 @@
+package controllers
+
+import javax.inject._
+import play.api.mvc._
+
+@Singleton
+class HomeController @Inject() (val controllerComponents: ControllerComponents)
+    extends BaseController {
+
+  def index(): Action[AnyContent] = Action {
+    Ok("storefront")
+  }
+
+  def products(): Action[AnyContent] = Action {
+    Ok("[]")
+  }
+}
@@ -0,0 +1,13 @@
+// swift-tools-version:5.10
+import PackageDescription
+
+let package = Package(
+    name: "Slugify",
+    products: [
+        .library(name: "Slugify", targets: ["Slugify"]),
+    ],
+    targets: [
+        .target(name: "Slugify"),
+        .testTarget(name: "SlugifyTests", dependencies: ["Slugify"]),
+    ]
+)
@@ -0,0 +1,5 @@
+public func slugify(_ text: String) -> String {
+    text.lowercased()
+        .split(whereSeparator: { !$0.isLetter && !$0.isNumber })
+        .joined(separator: "-")
+}
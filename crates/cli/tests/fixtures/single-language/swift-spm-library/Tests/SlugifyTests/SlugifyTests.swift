@@ -0,0 +1,8 @@
+import XCTest
+@testable import Slugify
+
+final class SlugifyTests: XCTestCase {
+    func testSlugify() {
+        XCTAssertEqual(slugify("Hello, World!"), "hello-world")
+    }
+}
@@ -0,0 +1,6 @@
+import Vapor
+
+public func configure(_ app: Application) async throws {
+    app.http.server.configuration.port = 8080
+    try routes(app)
+}
@@ -0,0 +1,11 @@
+import Vapor
+
+func routes(_ app: Application) throws {
+    app.get("health") { _ in
+        "ok"
+    }
+
+    app.get("todos") { _ -> [String] in
+        ["write fixtures", "ship it"]
+    }
+}
@@ -0,0 +1,306 @@
+//! Detection accuracy against the ground-truth registry
+//!
+//! Every fixture in `tests/fixtures/ground_truth.json` is detected in static mode and its
+//! builds are matched with the registry by project name. A tracked field scores a true
+//! positive when the detected value equals the canonical one, a false positive when a value
+//! is detected that is not the canonical one and a false negative when the canonical value is
+//! not detected, so a wrong value counts as both. Fields the registry leaves out of a project
+//! are not scored. The per-field precision/recall matrix goes to stdout, and the test fails
+//! when a score drops below `tests/fixtures/accuracy_baseline.json`.
+
+mod support;
+
+use serde::{Deserialize, Serialize};
+use serde_json::{json, Value};
+use serial_test::serial;
+use std::collections::BTreeMap;
+use std::path::Path;
+use support::e2e::{run_detection_with_mode, UPDATE_FIXTURES_ENV};
+
+const GROUND_TRUTH: &str = "tests/fixtures/ground_truth.json";
+const BASELINE: &str = "tests/fixtures/accuracy_baseline.json";
+
+/// Canonical values of a project's tracked fields, keyed by JSON path (`runtime.ports`)
+type Fields = BTreeMap<String, Value>;
+
+/// Fixture path under tests/fixtures → project name → tracked fields
+type GroundTruth = BTreeMap<String, BTreeMap<String, Fields>>;
+
+#[derive(Debug, Default, Clone, Copy, PartialEq)]
+struct Counts {
+    true_positives: usize,
+    false_positives: usize,
+    false_negatives: usize,
+}
+
+impl Counts {
+    fn record(&mut self, truth: &Value, detected: &Value) {
+        match (is_absent(truth), is_absent(detected)) {
+            (true, true) => {}
+            (true, false) => self.false_positives += 1,
+            (false, true) => self.false_negatives += 1,
+            (false, false) if truth == detected => self.true_positives += 1,
+            (false, false) => {
+                self.false_positives += 1;
+                self.false_negatives += 1;
+            }
+        }
+    }
+
+    fn score(&self) -> Score {
+        Score {
+            precision: ratio(
+                self.true_positives,
+                self.true_positives + self.false_positives,
+            ),
+            recall: ratio(
+                self.true_positives,
+                self.true_positives + self.false_negatives,
+            ),
+        }
+    }
+}
+
+/// `None` when nothing was detected or expected for the field, so there is nothing to rate
+#[derive(Debug, Clone, Copy, PartialEq, Serialize, Deserialize)]
+struct Score {
+    precision: Option<f64>,
+    recall: Option<f64>,
+}
+
+fn ratio(numerator: usize, denominator: usize) -> Option<f64> {
+    // Four decimals keep the committed baseline readable
+    (denominator > 0).then(|| (numerator as f64 / denominator as f64 * 10_000.0).round() / 10_000.0)
+}
+
+/// null, "" and [] all mean nothing was detected
+fn is_absent(value: &Value) -> bool {
+    match value {
+        Value::Null => true,
+        Value::String(value) => value.is_empty(),
+        Value::Array(values) => values.is_empty(),
+        Value::Object(fields) => fields.is_empty(),
+        _ => false,
+    }
+}
+
+/// The value at a dotted path of a build, null when it is absent
+fn field<'a>(build: &'a Value, path: &str) -> &'a Value {
+    path.split('.').fold(build, |value, key| &value[key])
+}
+
+/// Scores the tracked fields of a fixture's projects against the builds detected for it
+///
+/// Detected projects missing from the registry are not scored; the static e2e tests already
+/// check how many projects a fixture has.
+fn score_fixture(
+    projects: &BTreeMap<String, Fields>,
+    detected: &[Value],
+    counts: &mut BTreeMap<String, Counts>,
+) {
+    for (project, fields) in projects {
+        let build = detected
+            .iter()
+            .find(|build| build["metadata"]["project_name"] == project.as_str())
+            .unwrap_or(&Value::Null);
+        for (path, truth) in fields {
+            counts
+                .entry(path.clone())
+                .or_default()
+                .record(truth, field(build, path));
+        }
+    }
+}
+
+fn render_matrix(counts: &BTreeMap<String, Counts>) -> String {
+    let rate = |rate: Option<f64>| rate.map_or("-".to_string(), |rate| format!("{:.4}", rate));
+    let mut out = format!(
+        "{:<28} {:>5} {:>5} {:>5} {:>10} {:>10}\n",
+        "field", "TP", "FP", "FN", "precision", "recall"
+    );
+    for (path, counts) in counts {
+        let score = counts.score();
+        out.push_str(&format!(
+            "{:<28} {:>5} {:>5} {:>5} {:>10} {:>10}\n",
+            path,
+            counts.true_positives,
+            counts.false_positives,
+            counts.false_negatives,
+            rate(score.precision),
+            rate(score.recall)
+        ));
+    }
+    out
+}
+
+/// Fields scoring below their baseline; fields new to the registry have no baseline yet
+fn regressions(
+    baseline: &BTreeMap<String, Score>,
+    scores: &BTreeMap<String, Score>,
+) -> Vec<String> {
+    let mut regressions = Vec::new();
+    for (path, previous) in baseline {
+        let current = scores.get(path).copied().unwrap_or(Score {
+            precision: None,
+            recall: None,
+        });
+        for (metric, previous, current) in [
+            ("precision", previous.precision, current.precision),
+            ("recall", previous.recall, current.recall),
+        ] {
+            if let Some(previous) = previous {
+                let current = current.unwrap_or(0.0);
+                if current < previous {
+                    regressions.push(format!(
+                        "  {} {}: {:.4} < {:.4}",
+                        path, metric, current, previous
+                    ));
+                }
+            }
+        }
+    }
+    regressions
+}
+
+fn read_json<T: serde::de::DeserializeOwned>(path: &str) -> T {
+    let content =
+        std::fs::read_to_string(path).unwrap_or_else(|e| panic!("Failed to read {}: {}", path, e));
+    serde_json::from_str(&content).unwrap_or_else(|e| panic!("Failed to parse {}: {}", path, e))
+}
+
+#[test]
+#[serial]
+fn test_accuracy() {
+    let truth: GroundTruth = read_json(GROUND_TRUTH);
+    assert!(!truth.is_empty(), "{} lists no fixtures", GROUND_TRUTH);
+
+    let mut counts = BTreeMap::new();
+    for (fixture, projects) in &truth {
+        let name = fixture.rsplit('/').next().unwrap_or(fixture);
+        let test_name = format!("e2e_test_{}_static", name.replace('-', "_"));
+        let results = run_detection_with_mode(
+            Path::new("tests/fixtures").join(fixture),
+            &test_name,
+            Some("static"),
+        )
+        .unwrap_or_else(|e| panic!("Detection failed for {}: {}", fixture, e));
+        let detected: Vec<Value> = results
+            .iter()
+            .map(|build| serde_json::to_value(build).expect("Failed to serialize build"))
+            .collect();
+        score_fixture(projects, &detected, &mut counts);
+    }
+
+    println!("{}", render_matrix(&counts));
+    let scores: BTreeMap<String, Score> = counts
+        .iter()
+        .map(|(path, counts)| (path.clone(), counts.score()))
+        .collect();
+
+    if std::env::var_os(UPDATE_FIXTURES_ENV).is_some() {
+        let json = serde_json::to_string_pretty(&scores).expect("Failed to serialize scores");
+        std::fs::write(BASELINE, json + "\n")
+            .unwrap_or_else(|e| panic!("Failed to write {}: {}", BASELINE, e));
+        eprintln!("Updated {}", BASELINE);
+        return;
+    }
+
+    let baseline: BTreeMap<String, Score> = read_json(BASELINE);
+    let regressions = regressions(&baseline, &scores);
+    assert!(
+        regressions.is_empty(),
+        "Detection accuracy regressed below {}:\n{}",
+        BASELINE,
+        regressions.join("\n")
+    );
+}
+
+/// The registry must agree with the fixtures' expected output wherever both spell out a field
+#[test]
+fn test_ground_truth_matches_fixtures() {
+    let truth: GroundTruth = read_json(GROUND_TRUTH);
+    for (fixture, projects) in &truth {
+        let expected: Value = read_json(&format!("tests/fixtures/{}/universalbuild.json", fixture));
+        let builds = match expected {
+            Value::Array(builds) => builds,
+            build => vec![build],
+        };
+        for (project, fields) in projects {
+            let build = builds
+                .iter()
+                .find(|build| build["metadata"]["project_name"] == project.as_str())
+                .unwrap_or_else(|| panic!("{} has no project '{}'", fixture, project));
+            for (path, value) in fields {
+                assert_eq!(
+                    field(build, path),
+                    value,
+                    "{} of '{}' in {} disagrees with its universalbuild.json",
+                    path,
+                    project,
+                    fixture
+                );
+            }
+        }
+    }
+}
+
+#[test]
+fn test_counts_and_scores() {
+    let mut counts = BTreeMap::new();
+    let projects = BTreeMap::from([(
+        "api".to_string(),
+        Fields::from([
+            ("metadata.language".to_string(), json!("Go")),
+            ("metadata.framework".to_string(), json!("Gin")),
+            ("runtime.health_check_path".to_string(), Value::Null),
+            ("runtime.ports".to_string(), json!([8080])),
+        ]),
+    )]);
+    let detected = [json!({
+        "metadata": {"project_name": "api", "language": "Go", "framework": "Echo"},
+        "runtime": {"health_check_path": "/health", "ports": []}
+    })];
+    score_fixture(&projects, &detected, &mut counts);
+
+    let count = |path: &str| counts[path];
+    assert_eq!(count("metadata.language").true_positives, 1);
+    assert_eq!(
+        count("metadata.framework"),
+        Counts {
+            true_positives: 0,
+            false_positives: 1,
+            false_negatives: 1,
+        }
+    );
+    assert_eq!(count("runtime.health_check_path").false_positives, 1);
+    assert_eq!(count("runtime.ports").false_negatives, 1);
+    assert_eq!(
+        count("runtime.ports").score(),
+        Score {
+            precision: None,
+            recall: Some(0.0),
+        }
+    );
+    assert!(render_matrix(&counts).contains("metadata.language"));
+}
+
+#[test]
+fn test_regressions_against_baseline() {
+    let score = |precision: Option<f64>, recall: Option<f64>| Score { precision, recall };
+    let baseline = BTreeMap::from([
+        ("metadata.language".to_string(), score(Some(1.0), Some(1.0))),
+        ("runtime.ports".to_string(), score(Some(0.9), None)),
+    ]);
+    let scores = BTreeMap::from([
+        (
+            "metadata.language".to_string(),
+            score(Some(1.0), Some(0.95)),
+        ),
+        ("runtime.ports".to_string(), score(Some(0.92), Some(0.5))),
+        ("runtime.command".to_string(), score(Some(0.1), Some(0.1))),
+    ]);
+    assert_eq!(
+        regressions(&baseline, &scores),
+        vec!["  metadata.language recall: 0.9500 < 1.0000"]
+    );
+}
@@ -277,6 +277,33 @@ fn test_global_quiet_flag() {
     assert!(output.status.success() || output.status.code() == Some(2));
 }
 
+#[test]
+#[serial]
+fn test_detect_verbose_logs_to_stderr() {
+    let temp_dir = TempDir::new().expect("Failed to create temp dir");
+    let repo_path = create_rust_repo(&temp_dir);
+
+    let output = Command::new(peelbox_bin())
+        .env("PEELBOX_DETECTION_MODE", "static")
+        .env_remove("RUST_LOG")
+        .arg("--verbose")
+        .arg("detect")
+        .arg(repo_path)
+        .arg("--format")
+        .arg("json")
+        .output()
+        .expect("Failed to execute peelbox");
+
+    // Logs must never interleave with the result, so `peelbox detect . | jq .` keeps working
+    if output.status.success() {
+        let stdout = String::from_utf8_lossy(&output.stdout);
+        let stderr = String::from_utf8_lossy(&output.stderr);
+        assert!(serde_json::from_str::<serde_json::Value>(&stdout).is_ok());
+        assert!(stderr.contains("Detected language"));
+        assert!(stderr.contains("reason=Cargo.toml is a Cargo manifest"));
+    }
+}
+
 #[test]
 fn test_log_level_flag() {
     let output = Command::new(peelbox_bin())
@@ -7,28 +7,102 @@
 
 mod support;
 
+use serde_json::{json, Value};
 use serial_test::serial;
+use std::path::{Path, PathBuf};
 use support::e2e::{assert_detection_with_mode, fixture_path, run_detection_with_mode};
+use support::json_diff::{self, Difference};
 use yare::parameterized;
 
 // Single-language fixtures - Static mode
 #[parameterized(
     rust_cargo_static = { "rust-cargo", Some("static") },
+    rust_binary_static = { "rust-binary", Some("static") },
+    rust_library_static = { "rust-library", Some("static") },
     node_npm_static = { "node-npm", Some("static") },
     python_pip_static = { "python-pip", Some("static") },
+    python_flask_static = { "python-flask", Some("static") },
+    python_fastapi_static = { "python-fastapi", Some("static") },
+    python_django_static = { "python-django", Some("static") },
     java_maven_static = { "java-maven", Some("static") },
+    java_maven_spring_boot_static = { "java-maven-spring-boot", Some("static") },
+    java_maven_plain_static = { "java-maven-plain", Some("static") },
     node_yarn_static = { "node-yarn", Some("static") },
     node_pnpm_static = { "node-pnpm", Some("static") },
+    node_express_npm_static = { "node-express-npm", Some("static") },
+    node_nextjs_yarn_static = { "node-nextjs-yarn", Some("static") },
+    node_nestjs_pnpm_static = { "node-nestjs-pnpm", Some("static") },
     python_poetry_static = { "python-poetry", Some("static") },
+    python_pdm_static = { "python-pdm", Some("static") },
+    python_hatch_static = { "python-hatch", Some("static") },
+    python_conda_sklearn_static = { "python-conda-sklearn", Some("static") },
     java_gradle_static = { "java-gradle", Some("static") },
+    java_gradle_groovy_static = { "java-gradle-groovy", Some("static") },
+    java_gradle_kotlin_dsl_static = { "java-gradle-kotlin-dsl", Some("static") },
     kotlin_gradle_static = { "kotlin-gradle", Some("static") },
+    kotlin_ktor_static = { "kotlin-ktor", Some("static") },
+    kotlin_spring_boot_static = { "kotlin-spring-boot", Some("static") },
+    scala_play_static = { "scala-play", Some("static") },
+    scala_http4s_static = { "scala-http4s", Some("static") },
+    haskell_stack_servant_static = { "haskell-stack-servant", Some("static") },
+    haskell_cabal_scotty_static = { "haskell-cabal-scotty", Some("static") },
+    deno_oak_static = { "deno-oak", Some("static") },
+    bun_elysia_static = { "bun-elysia", Some("static") },
+    dart_shelf_static = { "dart-shelf", Some("static") },
+    flutter_app_static = { "flutter-app", Some("static") },
+    bazel_go_static = { "bazel-go", Some("static") },
+    go_cgo_static = { "go-cgo", Some("static") },
+    go_cobra_cli_static = { "go-cobra-cli", Some("static") },
+    go_library_static = { "go-library", Some("static") },
+    go_batch_job_static = { "go-batch-job", Some("static") },
+    go_reverse_proxy_static = { "go-reverse-proxy", Some("static") },
+    go_embed_static_static = { "go-embed-static", Some("static") },
+    go_replace_static = { "go-replace", Some("static") },
+    go_with_linting_static = { "go-with-linting", Some("static") },
+    go_build_tags_static = { "go-build-tags", Some("static") },
+    go_wire_mockgen_static = { "go-wire-mockgen", Some("static") },
+    go_goose_migrations_static = { "go-goose-migrations", Some("static") },
+    go_gqlgen_static = { "go-gqlgen", Some("static") },
+    go_sqlc_static = { "go-sqlc", Some("static") },
+    go_wasm_browser_static = { "go-wasm-browser", Some("static") },
+    go_air_static = { "go-air", Some("static") },
+    go_nix_flake_static = { "go-nix-flake", Some("static") },
     dotnet_csproj_static = { "dotnet-csproj", Some("static") },
+    dotnet_aspnet_static = { "dotnet-aspnet", Some("static") },
+    dotnet_console_static = { "dotnet-console", Some("static") },
     go_mod_static = { "go-mod", Some("static") },
+    go_mod_openapi_static = { "go-mod-openapi", Some("static") },
+    go_goreleaser_static = { "go-goreleaser", Some("static") },
+    go_deprecated_modules_static = { "go-deprecated-modules", Some("static") },
+    go_deep_dependencies_static = { "go-deep-dependencies", Some("static") },
+    go_workspace_static = { "go-workspace", Some("static") },
+    go_multi_binary_static = { "go-multi-binary", Some("static") },
+    go_port_env_static = { "go-port-env", Some("static") },
+    go_feature_flag_static = { "go-feature-flag", Some("static") },
+    go_devcontainer_static = { "go-devcontainer", Some("static") },
+    go_github_actions_static = { "go-github-actions", Some("static") },
+    go_env_vars_static = { "go-env-vars", Some("static") },
+    go_gorilla_mux_static = { "go-gorilla-mux", Some("static") },
+    go_chi_static = { "go-chi", Some("static") },
+    go_echo_static = { "go-echo", Some("static") },
+    go_gin_health_static = { "go-gin-health", Some("static") },
+    go_gin_no_health_static = { "go-gin-no-health", Some("static") },
+    go_makefile_static = { "go-makefile", Some("static") },
+    go_grpc_static = { "go-grpc", Some("static") },
+    go_grpc_buf_static = { "go-grpc-buf", Some("static") },
+    go_temporal_worker_static = { "go-temporal-worker", Some("static") },
+    go_zap_logging_static = { "go-zap-logging", Some("static") },
     ruby_bundler_static = { "ruby-bundler", Some("static") },
+    ruby_rails_static = { "ruby-rails", Some("static") },
+    ruby_sinatra_static = { "ruby-sinatra", Some("static") },
     php_composer_static = { "php-composer", Some("static") },
+    php_laravel_static = { "php-laravel", Some("static") },
     php_symfony_static = { "php-symfony", Some("static") },
     cpp_cmake_static = { "cpp-cmake", Some("static") },
     elixir_mix_static = { "elixir-mix", Some("static") },
+    elixir_phoenix_static = { "elixir-phoenix", Some("static") },
+    swift_vapor_static = { "swift-vapor", Some("static") },
+    swift_spm_library_static = { "swift-spm-library", Some("static") },
 )]
 #[serial]
 fn test_single_language(fixture_name: &str, mode: Option<&str>) {
@@ -43,9 +117,11 @@ fn test_single_language(fixture_name: &str, mode: Option<&str>) {
     npm_workspaces_static = { "npm-workspaces", Some("static") },
     cargo_workspace_static = { "cargo-workspace", Some("static") },
     turborepo_static = { "turborepo", Some("static") },
+    pnpm_workspace_express_react_static = { "pnpm-workspace-express-react", Some("static") },
     gradle_multiproject_static = { "gradle-multiproject", Some("static") },
     maven_multimodule_static = { "maven-multimodule", Some("static") },
     polyglot_static = { "polyglot", Some("static") },
+    nx_go_node_static = { "nx-go-node", Some("static") },
 )]
 #[serial]
 fn test_monorepo(fixture_name: &str, mode: Option<&str>) {
@@ -54,3 +130,121 @@ fn test_monorepo(fixture_name: &str, mode: Option<&str>) {
     let results = run_detection_with_mode(fixture, &test_name, mode).expect("Detection failed");
     assert_detection_with_mode(&results, "monorepo", fixture_name, mode);
 }
+
+// Fixtures of the smaller categories - Static mode
+#[parameterized(
+    go_compose_static = { "multi-service", "go-compose", Some("static") },
+    go_lambda_sam_static = { "serverless", "go-lambda-sam", Some("static") },
+    node_serverless_framework_static = { "serverless", "node-serverless-framework", Some("static") },
+    go_app_with_terraform_static = { "infra", "go-app-with-terraform", Some("static") },
+    go_api_tf_aws_static = { "infra", "go-api-tf-aws", Some("static") },
+    go_react_python_static = { "multi-language", "go-react-python", Some("static") },
+    go_k8s_deployment_static = { "deployment", "go-k8s-deployment", Some("static") },
+    go_with_dashboards_static = { "observability", "go-with-dashboards", Some("static") },
+)]
+#[serial]
+fn test_category(category: &str, fixture_name: &str, mode: Option<&str>) {
+    let fixture = fixture_path(category, fixture_name);
+    let test_name = format!("e2e_test_{}_static", fixture_name.replace("-", "_"));
+    let results = run_detection_with_mode(fixture, &test_name, mode).expect("Detection failed");
+    assert_detection_with_mode(&results, category, fixture_name, mode);
+}
+
+/// Fields every expected build must spell out, as JSON paths from the build object
+const REQUIRED_FIELDS: [&[&str]; 6] = [
+    &["version"],
+    &["metadata", "language"],
+    &["metadata", "build_system"],
+    &["build", "packages"],
+    &["build", "commands"],
+    &["runtime", "packages"],
+];
+
+fn expected_files(dir: &Path, files: &mut Vec<PathBuf>) {
+    for entry in std::fs::read_dir(dir).unwrap() {
+        let path = entry.unwrap().path();
+        if path.is_dir() {
+            expected_files(&path, files);
+        } else if path
+            .file_name()
+            .is_some_and(|name| name == "universalbuild.json")
+        {
+            files.push(path);
+        }
+    }
+}
+
+#[test]
+fn test_fixture_consistency() {
+    let mut files = Vec::new();
+    expected_files(Path::new("tests/fixtures"), &mut files);
+    assert!(
+        !files.is_empty(),
+        "No universalbuild.json under tests/fixtures"
+    );
+
+    for file in files {
+        let content = std::fs::read_to_string(&file).unwrap();
+        let expected: Value = serde_json::from_str(&content)
+            .unwrap_or_else(|e| panic!("{} is not valid JSON: {}", file.display(), e));
+        let builds = match expected {
+            Value::Array(builds) => builds,
+            build => vec![build],
+        };
+        assert!(!builds.is_empty(), "{} has no builds", file.display());
+
+        for (i, build) in builds.iter().enumerate() {
+            for field in REQUIRED_FIELDS {
+                let value = field.iter().try_fold(build, |value, key| value.get(key));
+                assert!(
+                    value.is_some_and(|value| !value.is_null()),
+                    "{} build {} is missing {}",
+                    file.display(),
+                    i,
+                    field.join(".")
+                );
+            }
+        }
+    }
+}
+
+#[test]
+fn test_json_diff_ignores_key_order() {
+    let expected: Value = serde_json::from_str(r#"{"a": 1, "b": [1, 2]}"#).unwrap();
+    let actual: Value = serde_json::from_str("{\"b\":[1,2],\n\"a\":1}").unwrap();
+    assert!(json_diff::diff(&expected, &actual).is_empty());
+}
+
+#[test]
+fn test_json_diff_reports_paths() {
+    let expected = json!({"metadata": {"language": "Go", "port": 8080}, "tags": ["a"]});
+    let actual = json!({"metadata": {"language": "Go", "cgo": true}, "tags": ["a", "b"]});
+
+    let differences = json_diff::diff(&expected, &actual);
+    assert_eq!(
+        differences,
+        vec![
+            Difference {
+                path: "$.metadata.cgo".to_string(),
+                expected: None,
+                actual: Some(json!(true)),
+            },
+            Difference {
+                path: "$.metadata.port".to_string(),
+                expected: Some(json!(8080)),
+                actual: None,
+            },
+            Difference {
+                path: "$.tags[1]".to_string(),
+                expected: None,
+                actual: Some(json!("b")),
+            },
+        ]
+    );
+
+    std::env::set_var("NO_COLOR", "1");
+    assert_eq!(
+        json_diff::render(&differences[1..2]),
+        "  $.metadata.port\n-   8080\n"
+    );
+}
@@ -0,0 +1,79 @@
+//! Per-service output directory tests
+//!
+//! A monorepo fixture is detected in static mode with `--output-dir`; each service's result
+//! must land under its own path, next to an aggregate of all of them, and an existing
+//! directory must only be written into with `--force`.
+
+mod support;
+
+use peelbox_core::output::schema::UniversalBuild;
+use serial_test::serial;
+use support::e2e::{fixture_path, run_detect_command};
+use tempfile::TempDir;
+
+fn detect_into(dir: &std::path::Path, force: bool) -> std::process::Output {
+    let dir = dir.to_str().unwrap();
+    let mut args = vec!["--output-dir", dir];
+    if force {
+        args.push("--force");
+    }
+    run_detect_command(
+        fixture_path("monorepo", "npm-workspaces"),
+        "e2e_test_output_dir_static",
+        Some("static"),
+        &args,
+    )
+}
+
+fn read(path: std::path::PathBuf) -> String {
+    std::fs::read_to_string(&path)
+        .unwrap_or_else(|e| panic!("Failed to read {}: {}", path.display(), e))
+}
+
+#[test]
+#[serial]
+fn test_output_dir_per_service() {
+    let temp = TempDir::new().unwrap();
+    let dir = temp.path().join("results");
+
+    let output = detect_into(&dir, false);
+    assert!(
+        output.status.success(),
+        "{}",
+        String::from_utf8_lossy(&output.stderr)
+    );
+    assert!(output.stdout.is_empty(), "results belong in the directory");
+
+    for service in ["apps/web", "packages/api", "packages/ui"] {
+        let result: UniversalBuild =
+            serde_json::from_str(&read(dir.join(service).join("result.json")))
+                .unwrap_or_else(|e| panic!("{} result.json is not a result: {}", service, e));
+        assert_eq!(result.metadata.service_path.as_deref(), Some(service));
+    }
+    let aggregate: Vec<UniversalBuild> =
+        serde_json::from_str(&read(dir.join("aggregate.json"))).unwrap();
+    assert_eq!(aggregate.len(), 3);
+    assert!(!dir.join("result.json").exists());
+}
+
+#[test]
+#[serial]
+fn test_output_dir_requires_force_to_overwrite() {
+    let temp = TempDir::new().unwrap();
+    let aggregate = temp.path().join("aggregate.json");
+    std::fs::write(&aggregate, "stale").unwrap();
+
+    let output = detect_into(temp.path(), false);
+    assert!(!output.status.success());
+    assert!(String::from_utf8_lossy(&output.stderr).contains("--force"));
+    assert_eq!(read(aggregate.clone()), "stale");
+
+    let output = detect_into(temp.path(), true);
+    assert!(
+        output.status.success(),
+        "{}",
+        String::from_utf8_lossy(&output.stderr)
+    );
+    let results: Vec<UniversalBuild> = serde_json::from_str(&read(aggregate)).unwrap();
+    assert_eq!(results.len(), 3);
+}
@@ -0,0 +1,140 @@
+//! Scaffolding round-trip tests
+//!
+//! Each stack `peelbox init` supports is generated into a temporary directory and detected
+//! again in static mode; the result must name the stack, project and port it was generated
+//! for, and carry the commands that build and start it.
+
+mod support;
+
+use peelbox_cli::cli::scaffold::{Template, TEMPLATES};
+use serial_test::serial;
+use std::process::Command;
+use support::e2e::run_detection_with_mode;
+use support::get_peelbox_binary;
+use tempfile::TempDir;
+
+fn init(template: &Template, name: &str, dir: &std::path::Path) -> std::process::Output {
+    let mut cmd = Command::new(get_peelbox_binary());
+    cmd.arg("--quiet")
+        .arg("init")
+        .arg("--language")
+        .arg(template.language)
+        .arg("--build-system")
+        .arg(template.build_system)
+        .arg("--name")
+        .arg(name);
+    if let Some(framework) = template.framework {
+        cmd.arg("--framework").arg(framework);
+    }
+    cmd.arg(dir).output().expect("Failed to execute peelbox")
+}
+
+#[test]
+#[serial]
+fn test_scaffold_round_trip() {
+    for template in &TEMPLATES {
+        let temp = TempDir::new().unwrap();
+        let dir = temp.path().join("myapp");
+        let output = init(template, "myapp", &dir);
+        assert!(
+            output.status.success(),
+            "init failed for {}: {}",
+            template.name(),
+            String::from_utf8_lossy(&output.stderr)
+        );
+        let written = String::from_utf8_lossy(&output.stdout);
+        assert!(
+            written.lines().any(|line| line.ends_with(".gitignore")),
+            "{} wrote no .gitignore:\n{}",
+            template.name(),
+            written
+        );
+
+        let results = run_detection_with_mode(
+            dir.clone(),
+            &format!("e2e_test_scaffold_{}", template.language.to_lowercase()),
+            Some("static"),
+        )
+        .unwrap_or_else(|e| panic!("Detection failed for {}: {}", template.name(), e));
+        assert_eq!(results.len(), 1, "{}", template.name());
+        let detected = &results[0];
+
+        assert_eq!(detected.metadata.language, template.language);
+        assert_eq!(detected.metadata.build_system, template.build_system);
+        assert_eq!(
+            detected.metadata.framework.as_deref(),
+            template.framework,
+            "{}",
+            template.name()
+        );
+        // requirements.txt carries no project name, so detection falls back to a default one
+        if template.build_system != "pip" {
+            assert_eq!(
+                detected.metadata.project_name.as_deref(),
+                Some("myapp"),
+                "{}",
+                template.name()
+            );
+        }
+        assert_eq!(
+            detected.runtime.ports,
+            vec![template.port],
+            "{}",
+            template.name()
+        );
+        assert!(
+            !detected.build.commands.is_empty(),
+            "{} has no build commands",
+            template.name()
+        );
+        assert!(
+            !detected.runtime.command.is_empty(),
+            "{} has no run command",
+            template.name()
+        );
+    }
+}
+
+#[test]
+fn test_init_refuses_to_overwrite() {
+    let temp = TempDir::new().unwrap();
+    std::fs::write(temp.path().join("go.mod"), "module existing\n").unwrap();
+
+    let output = init(&TEMPLATES[0], "myapp", temp.path());
+    assert!(!output.status.success());
+    assert_eq!(
+        std::fs::read_to_string(temp.path().join("go.mod")).unwrap(),
+        "module existing\n"
+    );
+    assert!(!temp.path().join("main.go").exists());
+}
+
+#[test]
+fn test_init_from_spec() {
+    let temp = TempDir::new().unwrap();
+    let spec = temp.path().join("universalbuild.json");
+    std::fs::write(
+        &spec,
+        r#"{"metadata": {"project_name": "api", "language": "Python", "build_system": "pip", "framework": "Flask"}}"#,
+    )
+    .unwrap();
+    let dir = temp.path().join("api");
+
+    let output = Command::new(get_peelbox_binary())
+        .arg("--quiet")
+        .arg("init")
+        .arg("--spec")
+        .arg(&spec)
+        .arg(&dir)
+        .output()
+        .expect("Failed to execute peelbox");
+    assert!(
+        output.status.success(),
+        "{}",
+        String::from_utf8_lossy(&output.stderr)
+    );
+    assert!(std::fs::read_to_string(dir.join("requirements.txt"))
+        .unwrap()
+        .contains("flask"));
+    assert!(dir.join("app.py").exists());
+}
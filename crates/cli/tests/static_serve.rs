@@ -0,0 +1,137 @@
+//! `detect --serve` integration tests
+//!
+//! The HTTP router runs in-process on an ephemeral port; requests go through a real HTTP
+//! client and detection runs in Static mode.
+
+mod support;
+
+use base64::Engine;
+use peelbox_cli::cli::serve::{router, ServeConfig};
+use peelbox_core::output::schema::UniversalBuild;
+use peelbox_llm::MockLLMClient;
+use peelbox_pipeline::detection::service::DetectionService;
+use serial_test::serial;
+use std::io::Write;
+use std::path::Path;
+use std::sync::Arc;
+use support::e2e::{fixture_path, load_expected, setup_test_apkindex_cache};
+
+/// Starts the server and returns its base URL; it stops with the test's runtime
+async fn start_server() -> String {
+    setup_test_apkindex_cache();
+    std::env::set_var("PEELBOX_DETECTION_MODE", "static");
+
+    let service = DetectionService::new(Arc::new(MockLLMClient::new()));
+    let app = router(Arc::new(service), ServeConfig::default());
+    let listener = tokio::net::TcpListener::bind("127.0.0.1:0").await.unwrap();
+    let addr = listener.local_addr().unwrap();
+    tokio::spawn(async move { axum::serve(listener, app).await });
+    format!("http://{}", addr)
+}
+
+/// Zips a fixture the way GitHub's "Download ZIP" does, inside a `<name>-main/` directory
+fn zip_fixture(dir: &Path) -> Vec<u8> {
+    fn add_dir(zip: &mut zip::ZipWriter<std::io::Cursor<Vec<u8>>>, dir: &Path, prefix: &str) {
+        let mut entries: Vec<_> = std::fs::read_dir(dir)
+            .unwrap()
+            .map(|entry| entry.unwrap().path())
+            .collect();
+        entries.sort();
+        for path in entries {
+            let name = path.file_name().unwrap().to_str().unwrap();
+            let zip_name = format!("{}/{}", prefix, name);
+            if path.is_dir() {
+                if name != ".git" {
+                    add_dir(zip, &path, &zip_name);
+                }
+            } else if name != "universalbuild.json" {
+                zip.start_file(zip_name, zip::write::FileOptions::default())
+                    .unwrap();
+                zip.write_all(&std::fs::read(&path).unwrap()).unwrap();
+            }
+        }
+    }
+
+    let mut zip = zip::ZipWriter::new(std::io::Cursor::new(Vec::new()));
+    let prefix = format!("{}-main", dir.file_name().unwrap().to_str().unwrap());
+    add_dir(&mut zip, dir, &prefix);
+    zip.finish().unwrap().into_inner()
+}
+
+#[tokio::test]
+#[serial]
+async fn test_scan_archive() {
+    let base = start_server().await;
+    let archive = zip_fixture(&fixture_path("single-language", "rust-cargo"));
+
+    let response = reqwest::Client::new()
+        .post(format!("{}/scan", base))
+        .json(&serde_json::json!({
+            "archive": base64::engine::general_purpose::STANDARD.encode(archive),
+        }))
+        .send()
+        .await
+        .unwrap();
+
+    assert_eq!(response.status(), 200);
+    assert_eq!(
+        response.headers()["content-type"].to_str().unwrap(),
+        "application/json"
+    );
+    let results: Vec<UniversalBuild> = response.json().await.unwrap();
+    let expected = load_expected("single-language", "rust-cargo", Some("static")).unwrap();
+    assert_eq!(results.len(), expected.len());
+    for (actual, expected) in results.iter().zip(&expected) {
+        assert_eq!(actual.metadata.project_name, expected.metadata.project_name);
+        assert_eq!(actual.metadata.language, expected.metadata.language);
+        assert_eq!(actual.metadata.build_system, expected.metadata.build_system);
+        assert_eq!(actual.build.packages, expected.build.packages);
+        assert_eq!(actual.runtime.packages, expected.runtime.packages);
+    }
+}
+
+#[tokio::test]
+#[serial]
+async fn test_scan_rejects_bad_requests() {
+    let base = start_server().await;
+    let client = reqwest::Client::new();
+
+    for body in [
+        serde_json::json!({}),
+        serde_json::json!({"archive": "not base64!"}),
+        serde_json::json!({"url": "https://example.com/owner/repo"}),
+        serde_json::json!({"path": "/etc"}),
+    ] {
+        let response = client
+            .post(format!("{}/scan", base))
+            .json(&body)
+            .send()
+            .await
+            .unwrap();
+        assert_eq!(response.status(), 400, "body: {}", body);
+        let error: serde_json::Value = response.json().await.unwrap();
+        assert!(error["error"].is_string(), "body: {}", body);
+    }
+
+    let response = client
+        .post(format!("{}/scan", base))
+        .body("{not json")
+        .send()
+        .await
+        .unwrap();
+    assert_eq!(response.status(), 400);
+}
+
+#[tokio::test]
+#[serial]
+async fn test_openapi_spec() {
+    let base = start_server().await;
+    let response = reqwest::get(format!("{}/openapi.yaml", base))
+        .await
+        .unwrap();
+
+    assert_eq!(response.status(), 200);
+    let spec: serde_yaml::Value = serde_yaml::from_str(&response.text().await.unwrap()).unwrap();
+    assert_eq!(spec["openapi"].as_str(), Some("3.0.3"));
+    assert!(spec["paths"]["/scan"]["post"].is_mapping());
+}
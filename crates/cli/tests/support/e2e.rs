@@ -1,5 +1,7 @@
+use super::json_diff;
 use super::ContainerTestHarness;
 use peelbox_core::output::schema::UniversalBuild;
+use serde::Serialize;
 use std::env;
 use std::path::PathBuf;
 use std::process::Command;
@@ -73,43 +75,110 @@ pub fn fixture_path(category: &str, name: &str) -> PathBuf {
     PathBuf::from("tests/fixtures").join(category).join(name)
 }
 
-/// Helper to load expected UniversalBuild(s) from JSON
-/// Loads universalbuild.json from the fixture directory itself (same for all modes)
+/// Helper to load expected UniversalBuild(s) from JSON or YAML
+/// Loads universalbuild.json and/or universalbuild.yaml from the fixture directory itself
+/// (same for all modes); when a fixture has both they must describe the same builds
 #[allow(dead_code)]
 pub fn load_expected(
     category: &str,
     fixture_name: &str,
     _mode: Option<&str>,
 ) -> Option<Vec<UniversalBuild>> {
-    let expected_path = PathBuf::from("tests/fixtures")
+    let fixture_dir = PathBuf::from("tests/fixtures")
         .join(category)
-        .join(fixture_name)
-        .join("universalbuild.json");
+        .join(fixture_name);
+
+    let from_json = load_expected_file(&fixture_dir.join("universalbuild.json"), |content| {
+        serde_json::from_str::<Vec<UniversalBuild>>(content)
+            .map_err(|e| e.to_string())
+            .or_else(|e1| {
+                serde_json::from_str::<UniversalBuild>(content)
+                    .map(|single| vec![single])
+                    .map_err(|e2| {
+                        format!("As Vec<UniversalBuild>: {}\nAs UniversalBuild: {}", e1, e2)
+                    })
+            })
+    });
+    let from_yaml = load_expected_file(&fixture_dir.join("universalbuild.yaml"), |content| {
+        serde_yaml::from_str::<Vec<UniversalBuild>>(content)
+            .map_err(|e| e.to_string())
+            .or_else(|e1| {
+                serde_yaml::from_str::<UniversalBuild>(content)
+                    .map(|single| vec![single])
+                    .map_err(|e2| {
+                        format!("As Vec<UniversalBuild>: {}\nAs UniversalBuild: {}", e1, e2)
+                    })
+            })
+    });
+
+    if let (Some(json), Some(yaml)) = (&from_json, &from_yaml) {
+        assert_eq!(
+            serde_json::to_value(json).unwrap(),
+            serde_json::to_value(yaml).unwrap(),
+            "universalbuild.json and universalbuild.yaml disagree for fixture '{}'",
+            fixture_name
+        );
+    }
+    from_json.or(from_yaml)
+}
 
-    if !expected_path.exists() {
+fn load_expected_file(
+    path: &std::path::Path,
+    parse: impl Fn(&str) -> Result<Vec<UniversalBuild>, String>,
+) -> Option<Vec<UniversalBuild>> {
+    if !path.exists() {
         return None;
     }
 
-    let content = std::fs::read_to_string(&expected_path)
-        .unwrap_or_else(|_| panic!("Failed to read expected JSON: {}", expected_path.display()));
-
-    // Try parsing as array of UniversalBuild first (for monorepos)
-    match serde_json::from_str::<Vec<UniversalBuild>>(&content) {
-        Ok(multi) => Some(multi),
-        Err(e1) => {
-            // Try parsing as single UniversalBuild
-            match serde_json::from_str::<UniversalBuild>(&content) {
-                Ok(single) => Some(vec![single]),
-                Err(e2) => {
-                    panic!(
-                        "Failed to parse expected JSON: {}\nAs Vec<UniversalBuild>: {}\nAs UniversalBuild: {}",
-                        expected_path.display(),
-                        e1,
-                        e2
-                    );
-                }
-            }
-        }
+    let content = std::fs::read_to_string(path)
+        .unwrap_or_else(|_| panic!("Failed to read expected output: {}", path.display()));
+    match parse(&content) {
+        Ok(builds) => Some(builds),
+        Err(e) => panic!("Failed to parse expected output: {}\n{}", path.display(), e),
+    }
+}
+
+/// Set to rewrite each fixture's expected output with what detection produced instead of
+/// comparing against it
+pub const UPDATE_FIXTURES_ENV: &str = "PEELBOX_UPDATE_FIXTURES";
+
+/// Overwrites `universalbuild.json`, and `universalbuild.yaml` when the fixture has one, with
+/// `results`
+fn update_expected(category: &str, fixture_name: &str, results: &[UniversalBuild]) {
+    let fixture_dir = fixture_path(category, fixture_name);
+
+    let json = serde_json::to_string_pretty(results).expect("Failed to serialize results");
+    let json_path = fixture_dir.join("universalbuild.json");
+    std::fs::write(&json_path, json + "\n")
+        .unwrap_or_else(|_| panic!("Failed to write {}", json_path.display()));
+
+    let yaml_path = fixture_dir.join("universalbuild.yaml");
+    if yaml_path.exists() {
+        let yaml = serde_yaml::to_string(results).expect("Failed to serialize results");
+        std::fs::write(&yaml_path, yaml)
+            .unwrap_or_else(|_| panic!("Failed to write {}", yaml_path.display()));
+    }
+    eprintln!("Updated expected output of fixture '{}'", fixture_name);
+}
+
+/// Compares `detected` with `expected` as JSON values and panics with a colored diff of
+/// every path that differs
+fn assert_json_eq<T: Serialize + ?Sized>(
+    what: &str,
+    project_name: &str,
+    detected: &T,
+    expected: &T,
+) {
+    let detected = serde_json::to_value(detected).expect("Failed to serialize detected value");
+    let expected = serde_json::to_value(expected).expect("Failed to serialize expected value");
+    let differences = json_diff::diff(&expected, &detected);
+    if !differences.is_empty() {
+        panic!(
+            "{} mismatch for project '{}' (- expected, + detected):\n{}",
+            what,
+            project_name,
+            json_diff::render(&differences)
+        );
     }
 }
 
@@ -139,6 +208,50 @@ pub fn run_detection_with_mode(
     test_name: &str,
     mode: Option<&str>,
 ) -> Result<Vec<UniversalBuild>, String> {
+    let output = run_detect_command(fixture, test_name, mode, &["--format", "json"]);
+
+    if !output.status.success() {
+        let stderr = String::from_utf8_lossy(&output.stderr);
+        return Err(stderr.to_string());
+    }
+
+    if quiet_mode(mode) && !output.stderr.is_empty() {
+        return Err(format!(
+            "Expected no stderr output in quiet mode, got:\n{}",
+            String::from_utf8_lossy(&output.stderr)
+        ));
+    }
+
+    let stdout = String::from_utf8_lossy(&output.stdout);
+
+    // Try parsing as array first (for monorepos)
+    if let Ok(results) = serde_json::from_str::<Vec<UniversalBuild>>(&stdout) {
+        return Ok(results);
+    }
+
+    // Try parsing as single object
+    if let Ok(result) = serde_json::from_str::<UniversalBuild>(&stdout) {
+        return Ok(vec![result]);
+    }
+
+    Err(format!("Failed to parse output as JSON: {}", stdout))
+}
+
+/// RUST_LOG overrides --quiet, so stderr is only expected to stay empty without it
+#[allow(dead_code)]
+fn quiet_mode(mode: Option<&str>) -> bool {
+    mode == Some("static") && std::env::var("RUST_LOG").is_err()
+}
+
+/// Runs `peelbox detect <fixture> <args>` with the test environment (embedded backend,
+/// recordings, a throwaway cache) and returns its output unparsed
+#[allow(dead_code)]
+pub fn run_detect_command(
+    fixture: PathBuf,
+    test_name: &str,
+    mode: Option<&str>,
+    args: &[&str],
+) -> std::process::Output {
     let temp_cache_dir =
         std::env::temp_dir().join(format!("peelbox-cache-{}", uuid::Uuid::new_v4()));
     // Ensure cleanup happens when this guard is dropped (end of function)
@@ -180,32 +293,15 @@ pub fn run_detection_with_mode(
         cmd.env("PEELBOX_DETECTION_MODE", detection_mode);
     }
 
-    let output = cmd
-        .arg("detect")
-        .arg(fixture)
-        .arg("--format")
-        .arg("json")
-        .output()
-        .expect("Failed to execute peelbox");
-
-    if !output.status.success() {
-        let stderr = String::from_utf8_lossy(&output.stderr);
-        return Err(stderr.to_string());
-    }
-
-    let stdout = String::from_utf8_lossy(&output.stdout);
-
-    // Try parsing as array first (for monorepos)
-    if let Ok(results) = serde_json::from_str::<Vec<UniversalBuild>>(&stdout) {
-        return Ok(results);
-    }
-
-    // Try parsing as single object
-    if let Ok(result) = serde_json::from_str::<UniversalBuild>(&stdout) {
-        return Ok(vec![result]);
+    if quiet_mode(mode) {
+        cmd.arg("--quiet");
     }
 
-    Err(format!("Failed to parse output as JSON: {}", stdout))
+    cmd.arg("detect")
+        .arg(fixture)
+        .args(args)
+        .output()
+        .expect("Failed to execute peelbox")
 }
 
 /// Helper to assert detection results against expected output
@@ -229,10 +325,15 @@ pub fn assert_detection_with_mode(
         return;
     }
 
+    if env::var_os(UPDATE_FIXTURES_ENV).is_some() {
+        update_expected(category, fixture_name, results);
+        return;
+    }
+
     // Load and validate against expected JSON (required, same for all modes)
     let mut expected = load_expected(category, fixture_name, mode).unwrap_or_else(|| {
         panic!(
-            "Expected JSON file not found for fixture '{}'. Expected file: tests/fixtures/{}/{}/universalbuild.json",
+            "Expected output not found for fixture '{}'. Expected file: tests/fixtures/{}/{}/universalbuild.json (or .yaml)",
             fixture_name, category, fixture_name
         )
     });
@@ -261,28 +362,718 @@ pub fn assert_detection_with_mode(
             "Project name mismatch at position {}: expected '{:?}', got '{:?}'",
             i, expected_build.metadata.project_name, detected.metadata.project_name
         );
-        assert_eq!(
-            detected.metadata.language, expected_build.metadata.language,
-            "Language mismatch for project '{}': expected '{}', got '{}'",
-            project_name, expected_build.metadata.language, detected.metadata.language
+        assert_json_eq(
+            "Language",
+            project_name,
+            &detected.metadata.language,
+            &expected_build.metadata.language,
         );
-        assert_eq!(
-            detected.metadata.build_system, expected_build.metadata.build_system,
-            "Build system mismatch for project '{}': expected '{}', got '{}'",
-            project_name, expected_build.metadata.build_system, detected.metadata.build_system
+        assert_json_eq(
+            "Build system",
+            project_name,
+            &detected.metadata.build_system,
+            &expected_build.metadata.build_system,
         );
         // Wolfi-first architecture - base images removed from schema
         // Packages are now validated instead
-        assert_eq!(
-            detected.build.packages, expected_build.build.packages,
-            "Build packages mismatch for project '{}': expected {:?}, got {:?}",
-            project_name, expected_build.build.packages, detected.build.packages
+        assert_json_eq(
+            "Build packages",
+            project_name,
+            &detected.build.packages,
+            &expected_build.build.packages,
         );
-        assert_eq!(
-            detected.runtime.packages, expected_build.runtime.packages,
-            "Runtime packages mismatch for project '{}': expected {:?}, got {:?}",
-            project_name, expected_build.runtime.packages, detected.runtime.packages
+        assert_json_eq(
+            "Runtime packages",
+            project_name,
+            &detected.runtime.packages,
+            &expected_build.runtime.packages,
         );
+        if expected_build.metadata.django.is_some() {
+            assert_json_eq(
+                "Django metadata",
+                project_name,
+                &detected.metadata.django,
+                &expected_build.metadata.django,
+            );
+        }
+        if expected_build.metadata.node.is_some() {
+            assert_json_eq(
+                "Node metadata",
+                project_name,
+                &detected.metadata.node,
+                &expected_build.metadata.node,
+            );
+        }
+        if expected_build.metadata.framework_version.is_some() {
+            assert_json_eq(
+                "Framework version",
+                project_name,
+                &detected.metadata.framework_version,
+                &expected_build.metadata.framework_version,
+            );
+        }
+        if expected_build.metadata.crate_type.is_some() {
+            assert_json_eq(
+                "Crate type",
+                project_name,
+                &detected.metadata.crate_type,
+                &expected_build.metadata.crate_type,
+            );
+            // A library crate has nothing to run
+            assert_json_eq(
+                "Runtime command and ports",
+                project_name,
+                &(&detected.runtime.command, &detected.runtime.ports),
+                &(
+                    &expected_build.runtime.command,
+                    &expected_build.runtime.ports,
+                ),
+            );
+        }
+        if expected_build.metadata.runtime_version.is_some() {
+            assert_json_eq(
+                "Runtime version",
+                project_name,
+                &detected.metadata.runtime_version,
+                &expected_build.metadata.runtime_version,
+            );
+        }
+        if expected_build.metadata.sdk_version.is_some() {
+            assert_json_eq(
+                "SDK version",
+                project_name,
+                &detected.metadata.sdk_version,
+                &expected_build.metadata.sdk_version,
+            );
+        }
+        if expected_build.metadata.workspace.is_some() {
+            assert_json_eq(
+                "Workspace metadata",
+                project_name,
+                &detected.metadata.workspace,
+                &expected_build.metadata.workspace,
+            );
+        }
+        if expected_build.metadata.monorepo.is_some() {
+            assert_json_eq(
+                "Monorepo metadata",
+                project_name,
+                &detected.metadata.monorepo,
+                &expected_build.metadata.monorepo,
+            );
+        }
+        if expected_build.metadata.compose.is_some() {
+            assert_json_eq(
+                "Compose service",
+                project_name,
+                &detected.metadata.compose,
+                &expected_build.metadata.compose,
+            );
+            assert_json_eq(
+                "External services",
+                project_name,
+                &detected.metadata.external_services,
+                &expected_build.metadata.external_services,
+            );
+            assert_json_eq(
+                "Required env vars",
+                project_name,
+                &detected.metadata.required_env_vars,
+                &expected_build.metadata.required_env_vars,
+            );
+        }
+        if expected_build.metadata.compose.is_none()
+            && !expected_build.metadata.required_env_vars.is_empty()
+        {
+            assert_json_eq(
+                "Required env vars",
+                project_name,
+                &detected.metadata.required_env_vars,
+                &expected_build.metadata.required_env_vars,
+            );
+        }
+        if !expected_build.metadata.backing_services.is_empty() {
+            assert_json_eq(
+                "Backing services",
+                project_name,
+                &detected.metadata.backing_services,
+                &expected_build.metadata.backing_services,
+            );
+        }
+        if expected_build.metadata.observability.is_some() {
+            assert_json_eq(
+                "Observability",
+                project_name,
+                &detected.metadata.observability,
+                &expected_build.metadata.observability,
+            );
+        }
+        if expected_build.metadata.observability_artifacts.is_some() {
+            assert_json_eq(
+                "Observability artifacts",
+                project_name,
+                &detected.metadata.observability_artifacts,
+                &expected_build.metadata.observability_artifacts,
+            );
+        }
+        if expected_build.metadata.feature_flags.is_some() {
+            assert_json_eq(
+                "Feature flags",
+                project_name,
+                &detected.metadata.feature_flags,
+                &expected_build.metadata.feature_flags,
+            );
+        }
+        if expected_build.metadata.devcontainer.is_some() {
+            assert_json_eq(
+                "Dev container",
+                project_name,
+                &detected.metadata.devcontainer,
+                &expected_build.metadata.devcontainer,
+            );
+            // Forwarded ports take precedence over ports found in source
+            assert_json_eq(
+                "Ports",
+                project_name,
+                &detected.runtime.ports,
+                &expected_build.runtime.ports,
+            );
+        }
+        if !expected_build.metadata.ci_commands.is_empty() {
+            assert_json_eq(
+                "CI commands",
+                project_name,
+                &detected.metadata.ci_commands,
+                &expected_build.metadata.ci_commands,
+            );
+            for warning in &expected_build.warnings {
+                assert!(
+                    detected.warnings.contains(warning),
+                    "Missing warning {:?} for project '{}': {:?}",
+                    warning,
+                    project_name,
+                    detected.warnings
+                );
+            }
+        }
+        if !expected_build.metadata.go_tools.is_empty() {
+            assert_json_eq(
+                "Go tools",
+                project_name,
+                &detected.metadata.go_tools,
+                &expected_build.metadata.go_tools,
+            );
+        }
+        if expected_build.metadata.workflow_engine.is_some() {
+            assert_json_eq(
+                "Workflow engine",
+                project_name,
+                &(
+                    &detected.metadata.workflow_engine,
+                    &detected.metadata.service_role,
+                ),
+                &(
+                    &expected_build.metadata.workflow_engine,
+                    &expected_build.metadata.service_role,
+                ),
+            );
+        }
+        if !expected_build.metadata.deprecated_dependencies.is_empty() {
+            assert_json_eq(
+                "Deprecated dependencies",
+                project_name,
+                &detected.metadata.deprecated_dependencies,
+                &expected_build.metadata.deprecated_dependencies,
+            );
+        }
+        if expected_build.metadata.dependency_depth.is_some() {
+            assert_json_eq(
+                "Dependency depth",
+                project_name,
+                &(
+                    &detected.metadata.dependency_depth,
+                    &detected.metadata.dependency_count,
+                ),
+                &(
+                    &expected_build.metadata.dependency_depth,
+                    &expected_build.metadata.dependency_count,
+                ),
+            );
+        }
+        if expected_build.metadata.release_tool.is_some()
+            || expected_build.metadata.container_build_tool.is_some()
+        {
+            assert_json_eq(
+                "Release tooling",
+                project_name,
+                &(
+                    (
+                        &detected.metadata.release_tool,
+                        &detected.metadata.release_config,
+                        &detected.metadata.release_command,
+                    ),
+                    &detected.metadata.release_binaries,
+                    &detected.metadata.cross_compile_targets,
+                    &detected.metadata.release_archive_formats,
+                    &detected.metadata.container_build_tool,
+                    &detected.metadata.container_build_command,
+                ),
+                &(
+                    (
+                        &expected_build.metadata.release_tool,
+                        &expected_build.metadata.release_config,
+                        &expected_build.metadata.release_command,
+                    ),
+                    &expected_build.metadata.release_binaries,
+                    &expected_build.metadata.cross_compile_targets,
+                    &expected_build.metadata.release_archive_formats,
+                    &expected_build.metadata.container_build_tool,
+                    &expected_build.metadata.container_build_command,
+                ),
+            );
+        }
+        if expected_build.metadata.project_type.is_some() {
+            assert_json_eq(
+                "Project type",
+                project_name,
+                &(
+                    &detected.metadata.project_type,
+                    &detected.metadata.project_type_confidence,
+                    &detected.metadata.secondary_project_types,
+                    &detected.metadata.cli_framework,
+                    &detected.metadata.config_library,
+                    &detected.metadata.run_command,
+                ),
+                &(
+                    &expected_build.metadata.project_type,
+                    &expected_build.metadata.project_type_confidence,
+                    &expected_build.metadata.secondary_project_types,
+                    &expected_build.metadata.cli_framework,
+                    &expected_build.metadata.config_library,
+                    &expected_build.metadata.run_command,
+                ),
+            );
+            // Only services and proxies listen; CLI tools, batch jobs and libraries get no port
+            assert_json_eq(
+                "Ports",
+                project_name,
+                &(&detected.runtime.ports, &detected.suggestions),
+                &(&expected_build.runtime.ports, &expected_build.suggestions),
+            );
+        }
+        if expected_build.metadata.kotlin.is_some() {
+            assert_json_eq(
+                "Kotlin metadata",
+                project_name,
+                &detected.metadata.kotlin,
+                &expected_build.metadata.kotlin,
+            );
+        }
+        if expected_build.metadata.scala.is_some() {
+            assert_json_eq(
+                "Scala metadata",
+                project_name,
+                &detected.metadata.scala,
+                &expected_build.metadata.scala,
+            );
+        }
+        if expected_build.metadata.haskell.is_some() {
+            assert_json_eq(
+                "Haskell metadata",
+                project_name,
+                &detected.metadata.haskell,
+                &expected_build.metadata.haskell,
+            );
+        }
+        if expected_build.metadata.deno.is_some() {
+            assert_json_eq(
+                "Deno metadata",
+                project_name,
+                &detected.metadata.deno,
+                &expected_build.metadata.deno,
+            );
+        }
+        if expected_build.metadata.dart.is_some() {
+            assert_json_eq(
+                "Dart metadata",
+                project_name,
+                &detected.metadata.dart,
+                &expected_build.metadata.dart,
+            );
+        }
+        if expected_build.metadata.python.is_some() {
+            assert_json_eq(
+                "Python metadata",
+                project_name,
+                &detected.metadata.python,
+                &expected_build.metadata.python,
+            );
+        }
+        if expected_build.metadata.bazel.is_some() {
+            assert_json_eq(
+                "Bazel metadata",
+                project_name,
+                &detected.metadata.bazel,
+                &expected_build.metadata.bazel,
+            );
+        }
+        if expected_build.metadata.cgo {
+            assert_json_eq(
+                "cgo",
+                project_name,
+                &(detected.metadata.cgo, &detected.metadata.build_flags),
+                &(
+                    expected_build.metadata.cgo,
+                    &expected_build.metadata.build_flags,
+                ),
+            );
+        }
+        if !expected_build.metadata.embedded_assets.is_empty() {
+            assert_json_eq(
+                "Embedded assets",
+                project_name,
+                &(
+                    &detected.metadata.embedded_assets,
+                    detected.metadata.has_embedded_frontend,
+                ),
+                &(
+                    &expected_build.metadata.embedded_assets,
+                    expected_build.metadata.has_embedded_frontend,
+                ),
+            );
+        }
+        if expected_build.metadata.test_framework.is_some() {
+            assert_json_eq(
+                "Test setup",
+                project_name,
+                &(
+                    &detected.metadata.test_framework,
+                    &detected.metadata.test_command,
+                    detected.metadata.has_integration_tests,
+                ),
+                &(
+                    &expected_build.metadata.test_framework,
+                    &expected_build.metadata.test_command,
+                    expected_build.metadata.has_integration_tests,
+                ),
+            );
+        }
+        if expected_build.metadata.migration_tool.is_some() {
+            assert_json_eq(
+                "Migrations",
+                project_name,
+                &(
+                    &detected.metadata.migration_tool,
+                    &detected.metadata.migration_path,
+                    &detected.metadata.migration_command,
+                ),
+                &(
+                    &expected_build.metadata.migration_tool,
+                    &expected_build.metadata.migration_path,
+                    &expected_build.metadata.migration_command,
+                ),
+            );
+        }
+        if !expected_build.metadata.required_tools.is_empty() {
+            assert_json_eq(
+                "Required tools",
+                project_name,
+                &detected.metadata.required_tools,
+                &expected_build.metadata.required_tools,
+            );
+        }
+        if expected_build.metadata.build_constraints.is_some() {
+            assert_json_eq(
+                "Build constraints",
+                project_name,
+                &detected.metadata.build_constraints,
+                &expected_build.metadata.build_constraints,
+            );
+        }
+        if !expected_build.metadata.secondary_languages.is_empty() {
+            assert_json_eq(
+                "Secondary languages",
+                project_name,
+                &(
+                    &detected.metadata.secondary_languages,
+                    &detected.metadata.primary_language_heuristic,
+                ),
+                &(
+                    &expected_build.metadata.secondary_languages,
+                    &expected_build.metadata.primary_language_heuristic,
+                ),
+            );
+        }
+        if expected_build.metadata.dev_run_command.is_some() {
+            assert_json_eq(
+                "Dev run command",
+                project_name,
+                &(
+                    &detected.metadata.dev_run_command,
+                    &detected.runtime.command,
+                ),
+                &(
+                    &expected_build.metadata.dev_run_command,
+                    &expected_build.runtime.command,
+                ),
+            );
+        }
+        if expected_build.metadata.build_environment.is_some() {
+            assert_json_eq(
+                "Build environment",
+                project_name,
+                &(
+                    &detected.metadata.build_environment,
+                    &detected.metadata.build_environment_tools,
+                    &detected.metadata.nix_develop_command,
+                ),
+                &(
+                    &expected_build.metadata.build_environment,
+                    &expected_build.metadata.build_environment_tools,
+                    &expected_build.metadata.nix_develop_command,
+                ),
+            );
+        }
+        if expected_build.metadata.wasm_target.is_some() {
+            assert_json_eq(
+                "WebAssembly target",
+                project_name,
+                &detected.metadata.wasm_target,
+                &expected_build.metadata.wasm_target,
+            );
+        }
+        if expected_build.metadata.kubernetes.is_some() {
+            assert_json_eq(
+                "Kubernetes workload",
+                project_name,
+                &detected.metadata.kubernetes,
+                &expected_build.metadata.kubernetes,
+            );
+        }
+        if expected_build.metadata.iac_tool.is_some() {
+            assert_json_eq(
+                "IaC project",
+                project_name,
+                &(
+                    &detected.metadata.iac_tool,
+                    &detected.metadata.iac_path,
+                    &detected.metadata.iac_providers,
+                    &detected.metadata.iac_version_constraint,
+                    &detected.metadata.iac_deploy_command,
+                    &detected.metadata.cloud_providers,
+                    &detected.metadata.iac_state_backend,
+                    &detected.metadata.infra_subdirectory,
+                ),
+                &(
+                    &expected_build.metadata.iac_tool,
+                    &expected_build.metadata.iac_path,
+                    &expected_build.metadata.iac_providers,
+                    &expected_build.metadata.iac_version_constraint,
+                    &expected_build.metadata.iac_deploy_command,
+                    &expected_build.metadata.cloud_providers,
+                    &expected_build.metadata.iac_state_backend,
+                    &expected_build.metadata.infra_subdirectory,
+                ),
+            );
+        }
+        if expected_build.metadata.deployment_target.is_some() {
+            assert_json_eq(
+                "Serverless deployment",
+                project_name,
+                &(
+                    &detected.metadata.deployment_target,
+                    &detected.metadata.serverless_platform,
+                    &detected.metadata.serverless_config,
+                    &detected.metadata.deploy_command,
+                    &detected.metadata.serverless_handler,
+                ),
+                &(
+                    &expected_build.metadata.deployment_target,
+                    &expected_build.metadata.serverless_platform,
+                    &expected_build.metadata.serverless_config,
+                    &expected_build.metadata.deploy_command,
+                    &expected_build.metadata.serverless_handler,
+                ),
+            );
+        }
+        if expected_build.metadata.license.is_some() {
+            assert_json_eq(
+                "License",
+                project_name,
+                &detected.metadata.license,
+                &expected_build.metadata.license,
+            );
+        }
+        if !expected_build.metadata.lint_tools.is_empty() {
+            assert_json_eq(
+                "Lint tools",
+                project_name,
+                &detected.metadata.lint_tools,
+                &expected_build.metadata.lint_tools,
+            );
+        }
+        if !expected_build.metadata.local_replacements.is_empty() {
+            assert_json_eq(
+                "Local replacements",
+                project_name,
+                &detected.metadata.local_replacements,
+                &expected_build.metadata.local_replacements,
+            );
+        }
+        if expected_build.metadata.runtime.is_some() {
+            assert_json_eq(
+                "Runtime",
+                project_name,
+                &detected.metadata.runtime,
+                &expected_build.metadata.runtime,
+            );
+        }
+        if expected_build.metadata.logging_library.is_some() {
+            assert_json_eq(
+                "Logging metadata",
+                project_name,
+                &(
+                    &detected.metadata.logging_library,
+                    &detected.metadata.log_format_hint,
+                    detected.metadata.log_json_configured,
+                ),
+                &(
+                    &expected_build.metadata.logging_library,
+                    &expected_build.metadata.log_format_hint,
+                    expected_build.metadata.log_json_configured,
+                ),
+            );
+        }
+        if expected_build.metadata.grpc.is_some() {
+            assert_json_eq(
+                "gRPC metadata",
+                project_name,
+                &detected.metadata.grpc,
+                &expected_build.metadata.grpc,
+            );
+        }
+        if expected_build.metadata.buf.is_some() {
+            assert_json_eq(
+                "buf metadata",
+                project_name,
+                &(
+                    &detected.metadata.buf,
+                    &detected.metadata.proto_toolchain,
+                    &detected.metadata.pre_build_commands,
+                    &detected.metadata.required_tools,
+                ),
+                &(
+                    &expected_build.metadata.buf,
+                    &expected_build.metadata.proto_toolchain,
+                    &expected_build.metadata.pre_build_commands,
+                    &expected_build.metadata.required_tools,
+                ),
+            );
+        }
+        if expected_build.metadata.sqlc.is_some() {
+            assert_json_eq(
+                "sqlc metadata",
+                project_name,
+                &(
+                    &detected.metadata.sqlc,
+                    &detected.metadata.pre_build_commands,
+                ),
+                &(
+                    &expected_build.metadata.sqlc,
+                    &expected_build.metadata.pre_build_commands,
+                ),
+            );
+        }
+        if expected_build.metadata.api_schema.is_some() {
+            assert_json_eq(
+                "API schema",
+                project_name,
+                &detected.metadata.api_schema,
+                &expected_build.metadata.api_schema,
+            );
+            assert_json_eq(
+                "API schema generation",
+                project_name,
+                &detected.metadata.api_schema_generated,
+                &expected_build.metadata.api_schema_generated,
+            );
+        }
+        if expected_build.metadata.api_type.is_some() {
+            assert_json_eq(
+                "GraphQL metadata",
+                project_name,
+                &(
+                    &detected.metadata.api_type,
+                    &detected.metadata.schema_files,
+                    detected.metadata.graphql_subscriptions,
+                    &detected.metadata.graphql_model_dir,
+                    &detected.metadata.graphql_resolver_dir,
+                    &detected.metadata.pre_build_commands,
+                ),
+                &(
+                    &expected_build.metadata.api_type,
+                    &expected_build.metadata.schema_files,
+                    expected_build.metadata.graphql_subscriptions,
+                    &expected_build.metadata.graphql_model_dir,
+                    &expected_build.metadata.graphql_resolver_dir,
+                    &expected_build.metadata.pre_build_commands,
+                ),
+            );
+        }
+        if !expected_build.metadata.runtime_versions.is_empty() {
+            assert_json_eq(
+                "Runtime versions",
+                project_name,
+                &detected.metadata.runtime_versions,
+                &expected_build.metadata.runtime_versions,
+            );
+        }
+        if !expected_build.metadata.makefile_targets.is_empty() {
+            assert_json_eq(
+                "Makefile targets",
+                project_name,
+                &detected.metadata.makefile_targets,
+                &expected_build.metadata.makefile_targets,
+            );
+        }
+        if expected_build.metadata.build_command_source.is_some() {
+            assert_json_eq(
+                "Build command source",
+                project_name,
+                &detected.metadata.build_command_source,
+                &expected_build.metadata.build_command_source,
+            );
+            assert_json_eq(
+                "Build commands",
+                project_name,
+                &detected.build.commands,
+                &expected_build.build.commands,
+            );
+        }
+        if expected_build.runtime.health_check_path.is_some()
+            || !expected_build.suggestions.is_empty()
+        {
+            assert_json_eq(
+                "Health check path",
+                project_name,
+                &detected.runtime.health_check_path,
+                &expected_build.runtime.health_check_path,
+            );
+            assert_json_eq(
+                "Suggestions",
+                project_name,
+                &detected.suggestions,
+                &expected_build.suggestions,
+            );
+        }
+        if !expected_build.confidence.is_empty() {
+            assert_json_eq(
+                "Confidence",
+                project_name,
+                &detected.confidence,
+                &expected_build.confidence,
+            );
+        }
     }
 }
 
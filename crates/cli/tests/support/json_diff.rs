@@ -0,0 +1,92 @@
+//! Semantic JSON comparison for golden files
+//!
+//! Both sides are compared as parsed values, so key order and formatting never count as a
+//! difference. Differences are reported one per JSON path, colored like a diff: red lines
+//! are in the expected output only, green lines in the detected output only.
+
+use serde_json::Value;
+use std::io::IsTerminal;
+
+const RED: &str = "\x1b[31m";
+const GREEN: &str = "\x1b[32m";
+const RESET: &str = "\x1b[0m";
+
+/// A JSON path whose value differs; `None` when the path is absent on that side
+#[derive(Debug, PartialEq)]
+pub struct Difference {
+    pub path: String,
+    pub expected: Option<Value>,
+    pub actual: Option<Value>,
+}
+
+/// Differences between `expected` and `actual`, objects by sorted key and arrays by index
+pub fn diff(expected: &Value, actual: &Value) -> Vec<Difference> {
+    let mut differences = Vec::new();
+    diff_at("$", Some(expected), Some(actual), &mut differences);
+    differences
+}
+
+fn diff_at(
+    path: &str,
+    expected: Option<&Value>,
+    actual: Option<&Value>,
+    differences: &mut Vec<Difference>,
+) {
+    match (expected, actual) {
+        (Some(Value::Object(expected)), Some(Value::Object(actual))) => {
+            let mut keys: Vec<&String> = expected.keys().chain(actual.keys()).collect();
+            keys.sort();
+            keys.dedup();
+            for key in keys {
+                diff_at(
+                    &format!("{}.{}", path, key),
+                    expected.get(key),
+                    actual.get(key),
+                    differences,
+                );
+            }
+        }
+        (Some(Value::Array(expected)), Some(Value::Array(actual))) => {
+            for i in 0..expected.len().max(actual.len()) {
+                diff_at(
+                    &format!("{}[{}]", path, i),
+                    expected.get(i),
+                    actual.get(i),
+                    differences,
+                );
+            }
+        }
+        (expected, actual) if expected != actual => differences.push(Difference {
+            path: path.to_string(),
+            expected: expected.cloned(),
+            actual: actual.cloned(),
+        }),
+        _ => {}
+    }
+}
+
+/// One `path` line per difference followed by its removed and added value
+pub fn render(differences: &[Difference]) -> String {
+    let (red, green, reset) = if use_color() {
+        (RED, GREEN, RESET)
+    } else {
+        ("", "", "")
+    };
+
+    let mut out = String::new();
+    for difference in differences {
+        out.push_str(&format!("  {}\n", difference.path));
+        if let Some(expected) = &difference.expected {
+            out.push_str(&format!("{}-   {}{}\n", red, expected, reset));
+        }
+        if let Some(actual) = &difference.actual {
+            out.push_str(&format!("{}+   {}{}\n", green, actual, reset));
+        }
+    }
+    out
+}
+
+/// Colors only on a terminal, and never with `NO_COLOR` set
+fn use_color() -> bool {
+    std::env::var_os("NO_COLOR").is_none() && std::io::stderr().is_terminal()
+}
@@ -1,5 +1,6 @@
 pub mod container_harness;
 pub mod e2e;
+pub mod json_diff;
 
 pub use container_harness::ContainerTestHarness;
 
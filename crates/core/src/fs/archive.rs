@@ -0,0 +1,460 @@
+use super::{DirEntry, FileMetadata, FileSystem, FileType};
+use anyhow::{anyhow, bail, Context, Result};
+use std::collections::{BTreeMap, BTreeSet};
+use std::io::{self, Cursor, Read};
+use std::path::{Component, Path, PathBuf};
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ArchiveFormat {
+    Tar,
+    TarGz,
+    TarBz2,
+    Zip,
+}
+
+/// Bounds on what an archive may decompress to, so a small upload cannot expand without limit
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct ArchiveLimits {
+    /// Entries larger than this are left out; detection reads manifests and sources, not
+    /// assets
+    pub max_entry_bytes: u64,
+    /// Reading fails once the decompressed entries add up to more than this
+    pub max_total_bytes: u64,
+}
+
+impl Default for ArchiveLimits {
+    fn default() -> Self {
+        Self {
+            max_entry_bytes: 16 * 1024 * 1024,
+            max_total_bytes: 1024 * 1024 * 1024,
+        }
+    }
+}
+
+/// Read-only file system over the regular files of an archive, held in memory
+///
+/// Paths are relative to the archive root. Symlinks and entries escaping the root are
+/// dropped.
+#[derive(Debug, Default)]
+pub struct ArchiveFileSystem {
+    files: BTreeMap<PathBuf, Vec<u8>>,
+    dirs: BTreeSet<PathBuf>,
+}
+
+impl ArchiveFileSystem {
+    pub fn read<R: Read>(reader: R, format: ArchiveFormat) -> Result<Self> {
+        Self::read_with_limits(reader, format, ArchiveLimits::default())
+    }
+
+    /// Reads the archive, enforcing `limits` while it is decompressed rather than trusting
+    /// the sizes its headers declare
+    pub fn read_with_limits<R: Read>(
+        reader: R,
+        format: ArchiveFormat,
+        limits: ArchiveLimits,
+    ) -> Result<Self> {
+        let mut fs = Self::default();
+        fs.dirs.insert(PathBuf::new());
+        match format {
+            ArchiveFormat::Tar => fs.read_tar(reader, limits)?,
+            ArchiveFormat::TarGz => fs.read_tar(flate2::read::GzDecoder::new(reader), limits)?,
+            ArchiveFormat::TarBz2 => fs.read_tar(bzip2::read::BzDecoder::new(reader), limits)?,
+            ArchiveFormat::Zip => fs.read_zip(reader, limits)?,
+        }
+        Ok(fs)
+    }
+
+    /// The files and directories below `dir`, with `dir` as the new root
+    pub fn subtree(self, dir: &Path) -> Self {
+        let files = self
+            .files
+            .into_iter()
+            .filter_map(|(path, content)| {
+                Some((path.strip_prefix(dir).ok()?.to_path_buf(), content))
+            })
+            .collect();
+        let dirs = self
+            .dirs
+            .into_iter()
+            .filter_map(|path| Some(path.strip_prefix(dir).ok()?.to_path_buf()))
+            .collect();
+        Self { files, dirs }
+    }
+
+    /// Every regular file in the archive, sorted
+    pub fn files(&self) -> Vec<PathBuf> {
+        self.files.keys().cloned().collect()
+    }
+
+    fn read_tar<R: Read>(&mut self, reader: R, limits: ArchiveLimits) -> Result<()> {
+        // Skipped entries are decompressed too, so the whole stream is bounded
+        let mut archive = tar::Archive::new(ExpansionLimit {
+            inner: reader,
+            remaining: limits.max_total_bytes,
+            limit: limits.max_total_bytes,
+        });
+        for entry in archive.entries().context("Failed to read tar archive")? {
+            let mut entry = entry.context("Failed to read tar entry")?;
+            let entry_type = entry.header().entry_type();
+            let Some(path) = entry.path().ok().and_then(|p| relative_path(&p)) else {
+                continue;
+            };
+            if entry_type.is_dir() {
+                self.add_dir(path);
+            } else if entry_type.is_file() && entry.size() <= limits.max_entry_bytes {
+                let mut content = Vec::new();
+                entry
+                    .read_to_end(&mut content)
+                    .with_context(|| format!("Failed to read {} from tar", path.display()))?;
+                self.add_file(path, content);
+            }
+        }
+        Ok(())
+    }
+
+    fn read_zip<R: Read>(&mut self, mut reader: R, limits: ArchiveLimits) -> Result<()> {
+        // The central directory sits at the end of a zip, so it has to be buffered to seek
+        let mut buffer = Vec::new();
+        reader
+            .read_to_end(&mut buffer)
+            .context("Failed to read zip archive")?;
+        let mut archive =
+            zip::ZipArchive::new(Cursor::new(buffer)).context("Failed to read zip archive")?;
+        let mut remaining = limits.max_total_bytes;
+        for index in 0..archive.len() {
+            let mut file = archive
+                .by_index(index)
+                .context("Failed to read zip entry")?;
+            let Some(path) = file.enclosed_name().and_then(relative_path) else {
+                continue;
+            };
+            if file.is_dir() {
+                self.add_dir(path);
+            } else if file.size() <= limits.max_entry_bytes {
+                // The declared size may lie, so at most one byte past either limit is inflated
+                let mut content = Vec::new();
+                (&mut file)
+                    .take(limits.max_entry_bytes.min(remaining) + 1)
+                    .read_to_end(&mut content)
+                    .with_context(|| format!("Failed to read {} from zip", path.display()))?;
+                let size = content.len() as u64;
+                if size > remaining {
+                    bail!(
+                        "Zip archive expands beyond {} bytes",
+                        limits.max_total_bytes
+                    );
+                }
+                remaining -= size;
+                if size <= limits.max_entry_bytes {
+                    self.add_file(path, content);
+                }
+            }
+        }
+        Ok(())
+    }
+
+    fn add_file(&mut self, path: PathBuf, content: Vec<u8>) {
+        if let Some(parent) = path.parent() {
+            self.add_dir(parent.to_path_buf());
+        }
+        self.files.insert(path, content);
+    }
+
+    fn add_dir(&mut self, path: PathBuf) {
+        for dir in path.ancestors() {
+            self.dirs.insert(dir.to_path_buf());
+        }
+    }
+
+    fn entry(&self, path: &Path) -> Result<&[u8]> {
+        relative_path(path)
+            .and_then(|path| self.files.get(&path))
+            .map(Vec::as_slice)
+            .ok_or_else(|| anyhow!("File not found in archive: {:?}", path))
+    }
+}
+
+/// Fails reads once more than `limit` bytes have come through, bounding what a compressed
+/// stream may expand to
+struct ExpansionLimit<R> {
+    inner: R,
+    remaining: u64,
+    limit: u64,
+}
+
+impl<R: Read> Read for ExpansionLimit<R> {
+    fn read(&mut self, buf: &mut [u8]) -> io::Result<usize> {
+        let read = self.inner.read(buf)?;
+        self.remaining = self.remaining.checked_sub(read as u64).ok_or_else(|| {
+            io::Error::new(
+                io::ErrorKind::Other,
+                format!("archive expands beyond {} bytes", self.limit),
+            )
+        })?;
+        Ok(read)
+    }
+}
+
+/// `path` without `.` components, or `None` when it is absolute or leaves the root
+pub(super) fn relative_path(path: &Path) -> Option<PathBuf> {
+    let mut relative = PathBuf::new();
+    for component in path.components() {
+        match component {
+            Component::Normal(part) => relative.push(part),
+            Component::CurDir => {}
+            Component::ParentDir | Component::RootDir | Component::Prefix(_) => return None,
+        }
+    }
+    Some(relative)
+}
+
+impl FileSystem for ArchiveFileSystem {
+    fn exists(&self, path: &Path) -> bool {
+        self.is_file(path) || self.is_dir(path)
+    }
+
+    fn is_dir(&self, path: &Path) -> bool {
+        relative_path(path).is_some_and(|path| self.dirs.contains(&path))
+    }
+
+    fn is_file(&self, path: &Path) -> bool {
+        relative_path(path).is_some_and(|path| self.files.contains_key(&path))
+    }
+
+    fn metadata(&self, path: &Path) -> Result<FileMetadata> {
+        if self.is_dir(path) {
+            return Ok(FileMetadata {
+                size: 0,
+                file_type: FileType::Directory,
+            });
+        }
+        Ok(FileMetadata {
+            size: self.entry(path)?.len() as u64,
+            file_type: FileType::File,
+        })
+    }
+
+    fn read_to_string(&self, path: &Path) -> Result<String> {
+        String::from_utf8(self.entry(path)?.to_vec())
+            .with_context(|| format!("File is not valid UTF-8: {:?}", path))
+    }
+
+    fn read_bytes(&self, path: &Path, max_bytes: usize) -> Result<Vec<u8>> {
+        let content = self.entry(path)?;
+        Ok(content[..content.len().min(max_bytes)].to_vec())
+    }
+
+    fn read_dir(&self, path: &Path) -> Result<Vec<DirEntry>> {
+        let dir = relative_path(path)
+            .filter(|dir| self.dirs.contains(dir))
+            .ok_or_else(|| anyhow!("Directory not found in archive: {:?}", path))?;
+
+        let dirs = self.dirs.iter().map(|p| (p, FileType::Directory));
+        let files = self.files.keys().map(|p| (p, FileType::File));
+        Ok(dirs
+            .chain(files)
+            .filter(|(p, _)| p.parent() == Some(dir.as_path()))
+            .map(|(p, file_type)| DirEntry {
+                path: p.clone(),
+                name: p
+                    .file_name()
+                    .and_then(|n| n.to_str())
+                    .unwrap_or("")
+                    .to_string(),
+                file_type,
+            })
+            .collect())
+    }
+
+    fn canonicalize(&self, path: &Path) -> Result<PathBuf> {
+        relative_path(path)
+            .filter(|_| self.exists(path))
+            .ok_or_else(|| anyhow!("Path not found in archive: {:?}", path))
+    }
+
+    fn listed_files(&self) -> Option<Vec<PathBuf>> {
+        Some(self.files())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::io::Write;
+
+    const FILES: [(&str, &str); 3] = [
+        ("go.mod", "module example.com/app\n\ngo 1.22\n"),
+        ("main.go", "package main\n\nfunc main() {}\n"),
+        ("internal/api/handler.go", "package api\n"),
+    ];
+
+    fn tar_bytes() -> Vec<u8> {
+        let mut builder = tar::Builder::new(Vec::new());
+        for (path, content) in FILES {
+            let mut header = tar::Header::new_gnu();
+            header.set_size(content.len() as u64);
+            header.set_mode(0o644);
+            header.set_cksum();
+            builder
+                .append_data(&mut header, path, content.as_bytes())
+                .unwrap();
+        }
+        builder.into_inner().unwrap()
+    }
+
+    fn zip_bytes() -> Vec<u8> {
+        let mut writer = zip::ZipWriter::new(Cursor::new(Vec::new()));
+        writer
+            .add_directory("internal/", zip::write::FileOptions::default())
+            .unwrap();
+        for (path, content) in FILES {
+            writer
+                .start_file(path, zip::write::FileOptions::default())
+                .unwrap();
+            writer.write_all(content.as_bytes()).unwrap();
+        }
+        writer.finish().unwrap().into_inner()
+    }
+
+    fn assert_contents(fs: &ArchiveFileSystem) {
+        assert_eq!(
+            fs.files(),
+            vec![
+                PathBuf::from("go.mod"),
+                PathBuf::from("internal/api/handler.go"),
+                PathBuf::from("main.go"),
+            ]
+        );
+        assert_eq!(
+            fs.read_to_string(Path::new("go.mod")).unwrap(),
+            "module example.com/app\n\ngo 1.22\n"
+        );
+        assert!(fs.is_file(Path::new("./main.go")));
+        assert!(fs.is_dir(Path::new("internal/api")));
+        assert!(!fs.exists(Path::new("missing.go")));
+
+        let mut names: Vec<String> = fs
+            .read_dir(Path::new(""))
+            .unwrap()
+            .into_iter()
+            .map(|e| e.name)
+            .collect();
+        names.sort();
+        assert_eq!(names, vec!["go.mod", "internal", "main.go"]);
+    }
+
+    #[test]
+    fn test_tar() {
+        let fs = ArchiveFileSystem::read(tar_bytes().as_slice(), ArchiveFormat::Tar).unwrap();
+        assert_contents(&fs);
+    }
+
+    #[test]
+    fn test_tar_gz() {
+        let mut encoder = flate2::write::GzEncoder::new(Vec::new(), flate2::Compression::default());
+        encoder.write_all(&tar_bytes()).unwrap();
+        let archive = encoder.finish().unwrap();
+
+        let fs = ArchiveFileSystem::read(archive.as_slice(), ArchiveFormat::TarGz).unwrap();
+        assert_contents(&fs);
+    }
+
+    #[test]
+    fn test_tar_bz2() {
+        let mut encoder = bzip2::write::BzEncoder::new(Vec::new(), bzip2::Compression::default());
+        encoder.write_all(&tar_bytes()).unwrap();
+        let archive = encoder.finish().unwrap();
+
+        let fs = ArchiveFileSystem::read(archive.as_slice(), ArchiveFormat::TarBz2).unwrap();
+        assert_contents(&fs);
+    }
+
+    #[test]
+    fn test_zip() {
+        let fs = ArchiveFileSystem::read(zip_bytes().as_slice(), ArchiveFormat::Zip).unwrap();
+        assert_contents(&fs);
+    }
+
+    #[test]
+    fn test_entries_outside_root_are_dropped() {
+        let mut builder = tar::Builder::new(Vec::new());
+        let content = "root:x:0:0\n";
+        let mut header = tar::Header::new_gnu();
+        header.set_size(content.len() as u64);
+        header.set_mode(0o644);
+        // append_data refuses `..`, so write the name into the header directly
+        header.as_gnu_mut().unwrap().name[..13].copy_from_slice(b"../etc/passwd");
+        header.set_cksum();
+        builder.append(&header, content.as_bytes()).unwrap();
+        let archive = builder.into_inner().unwrap();
+
+        let fs = ArchiveFileSystem::read(archive.as_slice(), ArchiveFormat::Tar).unwrap();
+        assert!(fs.files().is_empty());
+    }
+
+    #[test]
+    fn test_oversized_entries_are_left_out() {
+        let limits = ArchiveLimits {
+            max_entry_bytes: 20,
+            ..ArchiveLimits::default()
+        };
+        for (archive, format) in [
+            (tar_bytes(), ArchiveFormat::Tar),
+            (zip_bytes(), ArchiveFormat::Zip),
+        ] {
+            let fs =
+                ArchiveFileSystem::read_with_limits(archive.as_slice(), format, limits).unwrap();
+            // go.mod and main.go are over 20 bytes
+            assert_eq!(fs.files(), vec![PathBuf::from("internal/api/handler.go")]);
+        }
+    }
+
+    #[test]
+    fn test_total_expansion_is_bounded() {
+        let zeros = vec![0u8; 1024 * 1024];
+        let limits = ArchiveLimits {
+            max_entry_bytes: 64,
+            max_total_bytes: 64 * 1024,
+        };
+
+        let mut builder = tar::Builder::new(Vec::new());
+        let mut header = tar::Header::new_gnu();
+        header.set_size(zeros.len() as u64);
+        header.set_mode(0o644);
+        header.set_cksum();
+        builder
+            .append_data(&mut header, "zeros.bin", zeros.as_slice())
+            .unwrap();
+        let mut encoder = flate2::write::GzEncoder::new(Vec::new(), flate2::Compression::default());
+        encoder.write_all(&builder.into_inner().unwrap()).unwrap();
+        let tar_gz = encoder.finish().unwrap();
+        let err =
+            ArchiveFileSystem::read_with_limits(tar_gz.as_slice(), ArchiveFormat::TarGz, limits)
+                .unwrap_err();
+        assert!(format!("{:#}", err).contains("expands beyond"));
+
+        // Many entries, each under the per-entry limit, still add up
+        let mut writer = zip::ZipWriter::new(Cursor::new(Vec::new()));
+        for index in 0..2048 {
+            writer
+                .start_file(format!("{}.txt", index), zip::write::FileOptions::default())
+                .unwrap();
+            writer.write_all(&zeros[..64]).unwrap();
+        }
+        let zip = writer.finish().unwrap().into_inner();
+        let err = ArchiveFileSystem::read_with_limits(zip.as_slice(), ArchiveFormat::Zip, limits)
+            .unwrap_err();
+        assert!(err.to_string().contains("expands beyond"));
+    }
+
+    #[test]
+    fn test_subtree() {
+        let fs = ArchiveFileSystem::read(tar_bytes().as_slice(), ArchiveFormat::Tar)
+            .unwrap()
+            .subtree(Path::new("internal"));
+        assert_eq!(fs.files(), vec![PathBuf::from("api/handler.go")]);
+        assert!(fs.is_dir(Path::new("")));
+        assert!(fs.is_dir(Path::new("api")));
+        assert!(!fs.exists(Path::new("go.mod")));
+    }
+}
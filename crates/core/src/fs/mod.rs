@@ -1,9 +1,13 @@
 //! FileSystem abstraction for testable file operations
 
+mod archive;
 mod mock;
 mod real;
+mod remote;
 mod r#trait;
 
+pub use archive::{ArchiveFileSystem, ArchiveFormat, ArchiveLimits};
 pub use mock::MockFileSystem;
 pub use r#trait::{DirEntry, FileMetadata, FileSystem, FileType};
 pub use real::RealFileSystem;
+pub use remote::{RemoteFileSystem, RemoteHost, RemoteRepository};
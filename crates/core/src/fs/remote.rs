@@ -0,0 +1,544 @@
+use super::archive::relative_path;
+use super::{DirEntry, FileMetadata, FileSystem, FileType};
+use anyhow::{anyhow, bail, Context, Result};
+use reqwest::blocking::{Client, RequestBuilder};
+use reqwest::redirect::Policy;
+use serde::Deserialize;
+use std::collections::{BTreeMap, BTreeSet, HashMap};
+use std::path::{Path, PathBuf};
+use std::sync::Mutex;
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum RemoteHost {
+    GitHub,
+    GitLab,
+}
+
+impl RemoteHost {
+    /// Environment variable holding the host's API token when none is configured
+    pub fn token_env(&self) -> &'static str {
+        match self {
+            RemoteHost::GitHub => "GITHUB_TOKEN",
+            RemoteHost::GitLab => "GITLAB_TOKEN",
+        }
+    }
+}
+
+/// A GitHub or GitLab repository, addressed through the host's REST API
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct RemoteRepository {
+    pub host: RemoteHost,
+    /// `owner/repo` on GitHub, the full (possibly nested) project path on GitLab
+    pub path: String,
+    /// Branch, tag or commit, possibly followed by a directory (`/tree/main/docs`) that
+    /// [`RemoteFileSystem::connect`] splits off; the default branch when unset
+    pub reference: Option<String>,
+    /// Root of the REST API: GitHub API v3 or GitLab API v4
+    pub api_base: String,
+}
+
+impl RemoteRepository {
+    /// Parses a web URL such as `https://github.com/owner/repo`, optionally followed by
+    /// `/tree/<ref>` (GitHub) or `/-/tree/<ref>` (GitLab)
+    ///
+    /// Only https URLs on github.com, gitlab.com and the self-hosted GitLab instances in
+    /// `gitlab_hosts` are accepted, so a token is never sent to a host the operator did not
+    /// configure, nor in the clear.
+    pub fn parse(url: &str, gitlab_hosts: &[String]) -> Result<Self> {
+        if url.starts_with("http://") {
+            bail!("Repository URL must use https: {}", url);
+        }
+        let rest = url
+            .strip_prefix("https://")
+            .ok_or_else(|| anyhow!("Not an https repository URL: {}", url))?;
+        let (host, path) = rest
+            .split_once('/')
+            .ok_or_else(|| anyhow!("Repository URL has no path: {}", url))?;
+        let path = path.trim_end_matches('/');
+        let host = host.to_ascii_lowercase();
+        let is_gitlab = host == "gitlab.com"
+            || gitlab_hosts
+                .iter()
+                .any(|allowed| allowed.eq_ignore_ascii_case(&host));
+
+        let (host_kind, path, reference, api_base) = if host == "github.com" {
+            let segments: Vec<&str> = path.split('/').collect();
+            if segments.len() < 2 {
+                bail!("GitHub URL does not name owner/repo: {}", url);
+            }
+            let reference = match &segments[2..] {
+                ["tree", reference @ ..] if !reference.is_empty() => Some(reference.join("/")),
+                _ => None,
+            };
+            let repo = segments[1].trim_end_matches(".git");
+            (
+                RemoteHost::GitHub,
+                format!("{}/{}", segments[0], repo),
+                reference,
+                "https://api.github.com".to_string(),
+            )
+        } else if is_gitlab {
+            let (project, reference) = match path.split_once("/-/") {
+                Some((project, rest)) => (project, rest.strip_prefix("tree/").map(str::to_string)),
+                None => (path, None),
+            };
+            (
+                RemoteHost::GitLab,
+                project.trim_end_matches(".git").to_string(),
+                reference,
+                format!("https://{}/api/v4", host),
+            )
+        } else {
+            bail!(
+                "Unsupported repository host {} (expected github.com, gitlab.com or a configured GitLab host)",
+                host
+            );
+        };
+
+        Ok(Self {
+            host: host_kind,
+            path,
+            reference,
+            api_base,
+        })
+    }
+
+    /// Talks to `api_base` instead of the public API, e.g. GitHub Enterprise or a test server
+    pub fn with_api_base(mut self, api_base: impl Into<String>) -> Self {
+        self.api_base = api_base.into().trim_end_matches('/').to_string();
+        self
+    }
+}
+
+#[derive(Deserialize)]
+struct GitHubTree {
+    tree: Vec<TreeEntry>,
+    #[serde(default)]
+    truncated: bool,
+}
+
+#[derive(Deserialize)]
+struct TreeEntry {
+    path: String,
+    #[serde(rename = "type")]
+    kind: String,
+    #[serde(default)]
+    size: Option<u64>,
+}
+
+/// Read-only file system over a remote repository's tree
+///
+/// The tree is listed once up front; file contents are downloaded on first read and kept in
+/// memory. Requests run on a thread of their own, so reads are safe from async tasks too.
+pub struct RemoteFileSystem {
+    repo: RemoteRepository,
+    token: Option<String>,
+    client: Client,
+    /// Directory of the repository the file system is rooted at, split off the reference
+    subdir: PathBuf,
+    /// Listed blobs with their size, 0 when the host does not report it
+    files: BTreeMap<PathBuf, u64>,
+    dirs: BTreeSet<PathBuf>,
+    contents: Mutex<HashMap<PathBuf, Vec<u8>>>,
+}
+
+impl RemoteFileSystem {
+    /// Lists the repository tree, authenticating with `token` when given
+    pub fn connect(repo: RemoteRepository, token: Option<&str>) -> Result<Self> {
+        off_runtime(|| Self::connect_blocking(repo, token))
+    }
+
+    fn connect_blocking(repo: RemoteRepository, token: Option<&str>) -> Result<Self> {
+        let client = Client::builder()
+            .user_agent(concat!("peelbox/", env!("CARGO_PKG_VERSION")))
+            .redirect(same_host_redirects())
+            .build()
+            .context("Failed to create HTTP client")?;
+        let mut fs = Self {
+            repo,
+            token: token.map(str::to_string),
+            client,
+            subdir: PathBuf::new(),
+            files: BTreeMap::new(),
+            dirs: BTreeSet::from([PathBuf::new()]),
+            contents: Mutex::new(HashMap::new()),
+        };
+
+        for entry in fs.resolve_tree()? {
+            let Some(path) = relative_path(Path::new(&entry.path))
+                .and_then(|path| Some(path.strip_prefix(&fs.subdir).ok()?.to_path_buf()))
+                .filter(|path| !path.as_os_str().is_empty())
+            else {
+                continue;
+            };
+            match entry.kind.as_str() {
+                "blob" => {
+                    if let Some(parent) = path.parent() {
+                        fs.add_dir(parent);
+                    }
+                    fs.files.insert(path, entry.size.unwrap_or(0));
+                }
+                "tree" => fs.add_dir(&path),
+                // Submodules ("commit") have no content in this repository
+                _ => {}
+            }
+        }
+        if fs.files.is_empty() && !fs.subdir.as_os_str().is_empty() {
+            bail!(
+                "No directory {} in {} at {}",
+                fs.subdir.display(),
+                fs.repo.path,
+                fs.repo.reference.as_deref().unwrap_or("HEAD")
+            );
+        }
+        Ok(fs)
+    }
+
+    /// Lists the tree of the reference, splitting a trailing directory off it first
+    ///
+    /// `/tree/main/docs` reads the same as a branch named `main/docs`, so the longest prefix the
+    /// host lists a tree for is the reference and the rest is the directory to scan.
+    fn resolve_tree(&mut self) -> Result<Vec<TreeEntry>> {
+        let Some(reference) = self.repo.reference.clone() else {
+            return self.list_tree();
+        };
+        let segments: Vec<&str> = reference.split('/').collect();
+        let mut first_error = None;
+        for split in (1..=segments.len()).rev() {
+            self.repo.reference = Some(segments[..split].join("/"));
+            match self.list_tree() {
+                Ok(entries) => {
+                    self.subdir = segments[split..].iter().collect();
+                    return Ok(entries);
+                }
+                Err(e) => {
+                    first_error.get_or_insert(e);
+                }
+            }
+        }
+        self.repo.reference = Some(reference);
+        Err(first_error.expect("a reference has at least one segment"))
+    }
+
+    /// Every listed file, sorted
+    pub fn files(&self) -> Vec<PathBuf> {
+        self.files.keys().cloned().collect()
+    }
+
+    fn list_tree(&self) -> Result<Vec<TreeEntry>> {
+        match self.repo.host {
+            RemoteHost::GitHub => {
+                let url = format!(
+                    "{}/repos/{}/git/trees/{}?recursive=1",
+                    self.repo.api_base,
+                    self.repo.path,
+                    encode(self.repo.reference.as_deref().unwrap_or("HEAD"))
+                );
+                let tree: GitHubTree = self
+                    .send(
+                        self.client
+                            .get(&url)
+                            .header("Accept", "application/vnd.github+json"),
+                    )?
+                    .json()
+                    .with_context(|| format!("Invalid tree listing from {}", url))?;
+                if tree.truncated {
+                    bail!(
+                        "GitHub truncated the tree of {}; the repository is too large to scan remotely",
+                        self.repo.path
+                    );
+                }
+                Ok(tree.tree)
+            }
+            RemoteHost::GitLab => {
+                // GitLab pages the recursive tree; x-next-page is empty on the last page
+                let mut entries = Vec::new();
+                let mut page = "1".to_string();
+                loop {
+                    let mut url = format!(
+                        "{}/projects/{}/repository/tree?recursive=true&per_page=100&page={}",
+                        self.repo.api_base,
+                        encode(&self.repo.path),
+                        page
+                    );
+                    if let Some(reference) = &self.repo.reference {
+                        url.push_str(&format!("&ref={}", encode(reference)));
+                    }
+                    let response = self.send(self.client.get(&url))?;
+                    let next = response
+                        .headers()
+                        .get("x-next-page")
+                        .and_then(|value| value.to_str().ok())
+                        .map(str::to_string)
+                        .unwrap_or_default();
+                    let listed: Vec<TreeEntry> = response
+                        .json()
+                        .with_context(|| format!("Invalid tree listing from {}", url))?;
+                    entries.extend(listed);
+                    if next.is_empty() {
+                        return Ok(entries);
+                    }
+                    page = next;
+                }
+            }
+        }
+    }
+
+    fn fetch(&self, path: &Path) -> Result<Vec<u8>> {
+        let path = relative_path(path)
+            .filter(|path| self.files.contains_key(path))
+            .ok_or_else(|| anyhow!("File not found in remote repository: {:?}", path))?;
+        if let Some(content) = self.cache()?.get(&path) {
+            return Ok(content.clone());
+        }
+
+        let content = off_runtime(|| self.download(&path))?;
+        self.cache()?.insert(path, content.clone());
+        Ok(content)
+    }
+
+    fn cache(&self) -> Result<std::sync::MutexGuard<'_, HashMap<PathBuf, Vec<u8>>>> {
+        self.contents
+            .lock()
+            .map_err(|_| anyhow!("Remote file cache is poisoned"))
+    }
+
+    fn download(&self, path: &Path) -> Result<Vec<u8>> {
+        let file = self.subdir.join(path).to_string_lossy().replace('\\', "/");
+        let reference = self.repo.reference.as_deref().unwrap_or("HEAD");
+        let request = match self.repo.host {
+            RemoteHost::GitHub => {
+                let encoded: Vec<String> = file.split('/').map(encode).collect();
+                let mut url = format!(
+                    "{}/repos/{}/contents/{}",
+                    self.repo.api_base,
+                    self.repo.path,
+                    encoded.join("/")
+                );
+                if let Some(reference) = &self.repo.reference {
+                    url.push_str(&format!("?ref={}", encode(reference)));
+                }
+                self.client
+                    .get(url)
+                    .header("Accept", "application/vnd.github.raw")
+            }
+            RemoteHost::GitLab => self.client.get(format!(
+                "{}/projects/{}/repository/files/{}/raw?ref={}",
+                self.repo.api_base,
+                encode(&self.repo.path),
+                encode(&file),
+                encode(reference)
+            )),
+        };
+        Ok(self
+            .send(request)?
+            .bytes()
+            .with_context(|| format!("Failed to download {}", file))?
+            .to_vec())
+    }
+
+    fn send(&self, request: RequestBuilder) -> Result<reqwest::blocking::Response> {
+        let request = match (&self.token, self.repo.host) {
+            (Some(token), RemoteHost::GitHub) => request.bearer_auth(token),
+            (Some(token), RemoteHost::GitLab) => request.header("PRIVATE-TOKEN", token),
+            (None, _) => request,
+        };
+        let response = request.send().context("Repository API request failed")?;
+        if !response.status().is_success() {
+            bail!(
+                "Repository API returned HTTP {} for {}",
+                response.status(),
+                response.url()
+            );
+        }
+        Ok(response)
+    }
+
+    fn add_dir(&mut self, path: &Path) {
+        for dir in path.ancestors() {
+            self.dirs.insert(dir.to_path_buf());
+        }
+    }
+}
+
+/// Follows redirects only within the host that was asked: reqwest drops `Authorization` on a
+/// cross-host redirect but would forward GitLab's `PRIVATE-TOKEN` header
+fn same_host_redirects() -> Policy {
+    Policy::custom(|attempt| {
+        let same_host =
+            attempt.previous().first().and_then(|url| url.host_str()) == attempt.url().host_str();
+        if attempt.previous().len() > 10 {
+            attempt.error("too many redirects")
+        } else if same_host {
+            attempt.follow()
+        } else {
+            attempt.stop()
+        }
+    })
+}
+
+/// Runs `f` on a scoped thread of its own; the blocking client panics when it is driven from
+/// within an async task, which is where pipeline phases read files
+fn off_runtime<T: Send>(f: impl FnOnce() -> T + Send) -> T {
+    std::thread::scope(|scope| match scope.spawn(f).join() {
+        Ok(value) => value,
+        Err(panic) => std::panic::resume_unwind(panic),
+    })
+}
+
+/// Percent-encodes everything but RFC 3986 unreserved characters
+fn encode(value: &str) -> String {
+    value
+        .bytes()
+        .map(|byte| match byte {
+            b'A'..=b'Z' | b'a'..=b'z' | b'0'..=b'9' | b'-' | b'_' | b'.' | b'~' => {
+                (byte as char).to_string()
+            }
+            _ => format!("%{:02X}", byte),
+        })
+        .collect()
+}
+
+impl FileSystem for RemoteFileSystem {
+    fn exists(&self, path: &Path) -> bool {
+        self.is_file(path) || self.is_dir(path)
+    }
+
+    fn is_dir(&self, path: &Path) -> bool {
+        relative_path(path).is_some_and(|path| self.dirs.contains(&path))
+    }
+
+    fn is_file(&self, path: &Path) -> bool {
+        relative_path(path).is_some_and(|path| self.files.contains_key(&path))
+    }
+
+    fn metadata(&self, path: &Path) -> Result<FileMetadata> {
+        if self.is_dir(path) {
+            return Ok(FileMetadata {
+                size: 0,
+                file_type: FileType::Directory,
+            });
+        }
+        let size = relative_path(path)
+            .and_then(|path| self.files.get(&path).copied())
+            .ok_or_else(|| anyhow!("File not found in remote repository: {:?}", path))?;
+        Ok(FileMetadata {
+            size,
+            file_type: FileType::File,
+        })
+    }
+
+    fn read_to_string(&self, path: &Path) -> Result<String> {
+        String::from_utf8(self.fetch(path)?)
+            .with_context(|| format!("File is not valid UTF-8: {:?}", path))
+    }
+
+    fn read_bytes(&self, path: &Path, max_bytes: usize) -> Result<Vec<u8>> {
+        let mut content = self.fetch(path)?;
+        content.truncate(max_bytes);
+        Ok(content)
+    }
+
+    fn read_dir(&self, path: &Path) -> Result<Vec<DirEntry>> {
+        let dir = relative_path(path)
+            .filter(|dir| self.dirs.contains(dir))
+            .ok_or_else(|| anyhow!("Directory not found in remote repository: {:?}", path))?;
+
+        let dirs = self.dirs.iter().map(|p| (p, FileType::Directory));
+        let files = self.files.keys().map(|p| (p, FileType::File));
+        Ok(dirs
+            .chain(files)
+            .filter(|(p, _)| p.parent() == Some(dir.as_path()))
+            .map(|(p, file_type)| DirEntry {
+                path: p.clone(),
+                name: p
+                    .file_name()
+                    .and_then(|n| n.to_str())
+                    .unwrap_or("")
+                    .to_string(),
+                file_type,
+            })
+            .collect())
+    }
+
+    fn canonicalize(&self, path: &Path) -> Result<PathBuf> {
+        relative_path(path)
+            .filter(|_| self.exists(path))
+            .ok_or_else(|| anyhow!("Path not found in remote repository: {:?}", path))
+    }
+
+    fn listed_files(&self) -> Option<Vec<PathBuf>> {
+        Some(self.files())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_parse_github_url() {
+        let repo = RemoteRepository::parse("https://github.com/diverofdark/peelbox", &[]).unwrap();
+        assert_eq!(
+            repo,
+            RemoteRepository {
+                host: RemoteHost::GitHub,
+                path: "diverofdark/peelbox".to_string(),
+                reference: None,
+                api_base: "https://api.github.com".to_string(),
+            }
+        );
+
+        let repo =
+            RemoteRepository::parse("https://github.com/acme/shop.git/tree/release/1.2", &[])
+                .unwrap();
+        assert_eq!(repo.path, "acme/shop");
+        assert_eq!(repo.reference.as_deref(), Some("release/1.2"));
+    }
+
+    #[test]
+    fn test_parse_gitlab_url() {
+        let repo =
+            RemoteRepository::parse("https://gitlab.com/acme/platform/billing/-/tree/main/", &[])
+                .unwrap();
+        assert_eq!(repo.host, RemoteHost::GitLab);
+        assert_eq!(repo.path, "acme/platform/billing");
+        assert_eq!(repo.reference.as_deref(), Some("main"));
+        assert_eq!(repo.api_base, "https://gitlab.com/api/v4");
+
+        let repo = RemoteRepository::parse("https://gitlab.com/team/app", &[])
+            .unwrap()
+            .with_api_base("http://127.0.0.1:8080/api/v4/");
+        assert_eq!(repo.api_base, "http://127.0.0.1:8080/api/v4");
+    }
+
+    #[test]
+    fn test_parse_self_hosted_gitlab_needs_allowlist() {
+        let url = "https://GitLab.Example.com/team/app";
+        let err = RemoteRepository::parse(url, &[]).unwrap_err();
+        assert!(err.to_string().contains("Unsupported repository host"));
+        // A name that merely contains "gitlab" is not enough
+        assert!(RemoteRepository::parse("https://gitlab.attacker.net/team/app", &[]).is_err());
+
+        let repo = RemoteRepository::parse(url, &["gitlab.example.com".to_string()]).unwrap();
+        assert_eq!(repo.host, RemoteHost::GitLab);
+        assert_eq!(repo.api_base, "https://gitlab.example.com/api/v4");
+    }
+
+    #[test]
+    fn test_parse_rejects_other_urls() {
+        assert!(RemoteRepository::parse("https://bitbucket.org/acme/app", &[]).is_err());
+        assert!(RemoteRepository::parse("https://github.com/acme", &[]).is_err());
+        assert!(RemoteRepository::parse("git@github.com:acme/app.git", &[]).is_err());
+
+        let err = RemoteRepository::parse("http://github.com/acme/app", &[]).unwrap_err();
+        assert!(err.to_string().contains("must use https"));
+    }
+
+    #[test]
+    fn test_encode() {
+        assert_eq!(encode("acme/platform/billing"), "acme%2Fplatform%2Fbilling");
+        assert_eq!(encode("go.mod"), "go.mod");
+        assert_eq!(encode("my file"), "my%20file");
+    }
+}
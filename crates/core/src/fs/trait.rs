@@ -66,6 +66,55 @@ pub trait FileSystem: Send + Sync {
     fn join(&self, base: &Path, path: &str) -> PathBuf {
         base.join(path)
     }
+
+    /// Every file, sorted and relative to the root, when the file system holds a fixed listing
+    /// (an archive or a remote tree) instead of a directory to walk
+    fn listed_files(&self) -> Option<Vec<PathBuf>> {
+        None
+    }
+}
+
+/// Lets generic extractors borrow a shared file system, such as the pipeline's `dyn FileSystem`
+impl<F: FileSystem + ?Sized> FileSystem for &F {
+    fn exists(&self, path: &Path) -> bool {
+        (**self).exists(path)
+    }
+
+    fn is_dir(&self, path: &Path) -> bool {
+        (**self).is_dir(path)
+    }
+
+    fn is_file(&self, path: &Path) -> bool {
+        (**self).is_file(path)
+    }
+
+    fn metadata(&self, path: &Path) -> Result<FileMetadata> {
+        (**self).metadata(path)
+    }
+
+    fn read_to_string(&self, path: &Path) -> Result<String> {
+        (**self).read_to_string(path)
+    }
+
+    fn read_bytes(&self, path: &Path, max_bytes: usize) -> Result<Vec<u8>> {
+        (**self).read_bytes(path, max_bytes)
+    }
+
+    fn read_dir(&self, path: &Path) -> Result<Vec<DirEntry>> {
+        (**self).read_dir(path)
+    }
+
+    fn canonicalize(&self, path: &Path) -> Result<PathBuf> {
+        (**self).canonicalize(path)
+    }
+
+    fn join(&self, base: &Path, path: &str) -> PathBuf {
+        (**self).join(base, path)
+    }
+
+    fn listed_files(&self) -> Option<Vec<PathBuf>> {
+        (**self).listed_files()
+    }
 }
 
 #[cfg(test)]
@@ -0,0 +1,226 @@
+//! Structured differences between two detection results
+
+use super::schema::UniversalBuild;
+use anyhow::{Context, Result};
+use serde::{Deserialize, Serialize};
+use serde_json::Value;
+use std::collections::BTreeMap;
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum ChangeKind {
+    Added,
+    Removed,
+    Changed,
+}
+
+/// One field that differs, addressed by a dotted path starting with the project name
+/// (e.g. `api.runtime.ports`)
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct FieldChange {
+    pub path: String,
+    pub kind: ChangeKind,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub before: Option<Value>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub after: Option<Value>,
+}
+
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+pub struct DetectionDiff {
+    /// Changes sorted by path
+    pub changes: Vec<FieldChange>,
+}
+
+impl DetectionDiff {
+    pub fn is_empty(&self) -> bool {
+        self.changes.is_empty()
+    }
+}
+
+pub fn diff_results(before: &[UniversalBuild], after: &[UniversalBuild]) -> Result<DetectionDiff> {
+    let before = serde_json::to_value(before).context("Failed to serialize previous results")?;
+    let after = serde_json::to_value(after).context("Failed to serialize current results")?;
+    Ok(diff_json(&before, &after))
+}
+
+/// Diffs two detection outputs as plain JSON, so results from other peelbox versions (whose
+/// fields may have changed type) still compare
+///
+/// Builds are matched by `metadata.project_name`. Objects are compared field by field; arrays
+/// and scalars are compared as whole values.
+pub fn diff_json(before: &Value, after: &Value) -> DetectionDiff {
+    let mut changes = Vec::new();
+    diff_objects(
+        "",
+        &keyed_builds(before),
+        &keyed_builds(after),
+        &mut changes,
+    );
+    changes.sort_by(|a, b| a.path.cmp(&b.path));
+    DetectionDiff { changes }
+}
+
+/// Builds keyed by project name; unnamed builds fall back to their position (`#0`, `#1`, ...)
+fn keyed_builds(value: &Value) -> serde_json::Map<String, Value> {
+    let builds = match value {
+        Value::Array(builds) => builds.as_slice(),
+        single => std::slice::from_ref(single),
+    };
+    builds
+        .iter()
+        .enumerate()
+        .map(|(i, build)| {
+            let name = build["metadata"]["project_name"]
+                .as_str()
+                .map(String::from)
+                .unwrap_or_else(|| format!("#{}", i));
+            (name, build.clone())
+        })
+        .collect()
+}
+
+fn diff_objects(
+    prefix: &str,
+    before: &serde_json::Map<String, Value>,
+    after: &serde_json::Map<String, Value>,
+    changes: &mut Vec<FieldChange>,
+) {
+    let mut keys: BTreeMap<&str, (Option<&Value>, Option<&Value>)> = BTreeMap::new();
+    for (key, value) in before {
+        keys.entry(key).or_default().0 = Some(value);
+    }
+    for (key, value) in after {
+        keys.entry(key).or_default().1 = Some(value);
+    }
+
+    for (key, pair) in keys {
+        let path = if prefix.is_empty() {
+            key.to_string()
+        } else {
+            format!("{}.{}", prefix, key)
+        };
+        match pair {
+            (Some(Value::Object(a)), Some(Value::Object(b))) => diff_objects(&path, a, b, changes),
+            (Some(a), Some(b)) if a != b => changes.push(FieldChange {
+                path,
+                kind: ChangeKind::Changed,
+                before: Some(a.clone()),
+                after: Some(b.clone()),
+            }),
+            (Some(a), None) => changes.push(FieldChange {
+                path,
+                kind: ChangeKind::Removed,
+                before: Some(a.clone()),
+                after: None,
+            }),
+            (None, Some(b)) => changes.push(FieldChange {
+                path,
+                kind: ChangeKind::Added,
+                before: None,
+                after: Some(b.clone()),
+            }),
+            _ => {}
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use serde_json::json;
+
+    fn build(name: &str, framework: Option<&str>, ports: Value) -> Value {
+        let mut metadata = json!({"project_name": name, "language": "Go"});
+        if let Some(framework) = framework {
+            metadata["framework"] = json!(framework);
+        }
+        json!({"metadata": metadata, "runtime": {"ports": ports}})
+    }
+
+    #[test]
+    fn test_identical_results() {
+        let results = json!([build("api", Some("Gin"), json!([8080]))]);
+        assert!(diff_json(&results, &results).is_empty());
+    }
+
+    #[test]
+    fn test_changed_field() {
+        let before = json!([build("api", Some("Gin"), json!([8080]))]);
+        let after = json!([build("api", Some("Gin"), json!([3000]))]);
+
+        assert_eq!(
+            diff_json(&before, &after).changes,
+            vec![FieldChange {
+                path: "api.runtime.ports".to_string(),
+                kind: ChangeKind::Changed,
+                before: Some(json!([8080])),
+                after: Some(json!([3000])),
+            }]
+        );
+    }
+
+    #[test]
+    fn test_added_and_removed_fields() {
+        let before = json!([build("api", None, json!([8080]))]);
+        let after = json!([build("api", Some("Gin"), json!([8080]))]);
+
+        let added = diff_json(&before, &after);
+        assert_eq!(added.changes.len(), 1);
+        assert_eq!(added.changes[0].path, "api.metadata.framework");
+        assert_eq!(added.changes[0].kind, ChangeKind::Added);
+        assert_eq!(added.changes[0].after, Some(json!("Gin")));
+
+        let removed = diff_json(&after, &before);
+        assert_eq!(removed.changes[0].kind, ChangeKind::Removed);
+        assert_eq!(removed.changes[0].before, Some(json!("Gin")));
+        assert_eq!(removed.changes[0].after, None);
+    }
+
+    #[test]
+    fn test_added_and_removed_builds() {
+        let before = json!([build("api", None, json!([8080]))]);
+        let after = json!([build("worker", None, json!([]))]);
+
+        let diff = diff_json(&before, &after);
+        let summary: Vec<(&str, ChangeKind)> = diff
+            .changes
+            .iter()
+            .map(|c| (c.path.as_str(), c.kind))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![("api", ChangeKind::Removed), ("worker", ChangeKind::Added)]
+        );
+    }
+
+    #[test]
+    fn test_field_changing_type() {
+        let before = json!({"metadata": {"project_name": "api", "framework": "Gin"}});
+        let after = json!({"metadata": {"project_name": "api", "framework": ["Gin", "Echo"]}});
+
+        let diff = diff_json(&before, &after);
+        assert_eq!(diff.changes.len(), 1);
+        assert_eq!(diff.changes[0].kind, ChangeKind::Changed);
+        assert_eq!(diff.changes[0].before, Some(json!("Gin")));
+        assert_eq!(diff.changes[0].after, Some(json!(["Gin", "Echo"])));
+
+        // An object replacing a scalar is a single change too, not a list of added fields
+        let after = json!({"metadata": {"project_name": "api", "framework": {"name": "Gin"}}});
+        let diff = diff_json(&before, &after);
+        assert_eq!(diff.changes.len(), 1);
+        assert_eq!(diff.changes[0].path, "api.metadata.framework");
+    }
+
+    #[test]
+    fn test_diff_results() {
+        let before: UniversalBuild =
+            serde_json::from_value(build("api", None, json!([8080]))).expect("valid build");
+        let mut after = before.clone();
+        after.runtime.ports = vec![9090];
+
+        let diff = diff_results(&[before], &[after]).unwrap();
+        assert_eq!(diff.changes.len(), 1);
+        assert_eq!(diff.changes[0].path, "api.runtime.ports");
+    }
+}
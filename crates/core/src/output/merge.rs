@@ -0,0 +1,243 @@
+//! One aggregate report over the detection results of a repository's services
+
+use super::schema::{BackingService, UniversalBuild};
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, BTreeSet};
+
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct AggregateResult {
+    /// Results keyed by service path, else project name, else position (`#0`, `#1`, ...)
+    pub services: BTreeMap<String, UniversalBuild>,
+    /// Languages of more than half the services
+    pub shared_languages: Vec<String>,
+    pub unique_frameworks: BTreeSet<String>,
+    /// Backing services of every service, one entry per name
+    pub backing_services_union: Vec<BackingService>,
+    /// Frameworks resolved at more than one version across services
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub version_drift: Vec<VersionDrift>,
+}
+
+/// A framework whose services do not agree on its version
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct VersionDrift {
+    pub framework: String,
+    /// Services keyed by the version they resolve; services sharing a version share an entry
+    pub versions: BTreeMap<String, Vec<String>>,
+}
+
+pub fn merge_results(results: &[UniversalBuild]) -> AggregateResult {
+    let mut services = BTreeMap::new();
+    for (i, result) in results.iter().enumerate() {
+        let key = result
+            .metadata
+            .service_path
+            .clone()
+            .or_else(|| result.metadata.project_name.clone())
+            .unwrap_or_else(|| format!("#{}", i));
+        // Two unnamed services at the same path still get an entry each
+        let key = if services.contains_key(&key) {
+            format!("{}#{}", key, i)
+        } else {
+            key
+        };
+        services.insert(key, result.clone());
+    }
+
+    let mut language_counts: BTreeMap<&str, usize> = BTreeMap::new();
+    for result in services.values() {
+        if !result.metadata.language.is_empty() {
+            *language_counts
+                .entry(result.metadata.language.as_str())
+                .or_default() += 1;
+        }
+    }
+    let shared_languages = language_counts
+        .into_iter()
+        .filter(|(_, count)| count * 2 > services.len())
+        .map(|(language, _)| language.to_string())
+        .collect();
+
+    let unique_frameworks = services
+        .values()
+        .filter_map(|result| result.metadata.framework.clone())
+        .collect();
+
+    let mut backing: BTreeMap<String, BackingService> = BTreeMap::new();
+    for service in services
+        .values()
+        .flat_map(|result| &result.metadata.backing_services)
+    {
+        let merged = backing
+            .entry(service.name.clone())
+            .or_insert_with(|| BackingService {
+                name: service.name.clone(),
+                detected_via: vec![],
+                confidence: 0.0,
+            });
+        for signal in &service.detected_via {
+            if !merged.detected_via.contains(signal) {
+                merged.detected_via.push(signal.clone());
+            }
+        }
+        merged.detected_via.sort();
+        merged.confidence = merged.confidence.max(service.confidence);
+    }
+
+    let mut versions: BTreeMap<&str, BTreeMap<String, Vec<String>>> = BTreeMap::new();
+    for (key, result) in &services {
+        if let Some(resolved) = &result.metadata.framework_version {
+            versions
+                .entry(resolved.framework.as_str())
+                .or_default()
+                .entry(resolved.version.clone())
+                .or_default()
+                .push(key.clone());
+        }
+    }
+    let version_drift = versions
+        .into_iter()
+        .filter(|(_, versions)| versions.len() > 1)
+        .map(|(framework, versions)| VersionDrift {
+            framework: framework.to_string(),
+            versions,
+        })
+        .collect();
+
+    AggregateResult {
+        services,
+        shared_languages,
+        unique_frameworks,
+        backing_services_union: backing.into_values().collect(),
+        version_drift,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::output::schema::{BuildMetadata, FrameworkVersion};
+
+    fn service(path: &str, language: &str, framework: Option<(&str, &str)>) -> UniversalBuild {
+        UniversalBuild {
+            version: "1.0".to_string(),
+            metadata: BuildMetadata {
+                project_name: Some(path.rsplit('/').next().unwrap_or(path).to_string()),
+                language: language.to_string(),
+                service_path: Some(path.to_string()),
+                framework: framework.map(|(name, _)| name.to_string()),
+                framework_version: framework.map(|(name, version)| FrameworkVersion {
+                    framework: name.to_string(),
+                    version: version.to_string(),
+                    module: String::new(),
+                }),
+                ..Default::default()
+            },
+            confidence: Default::default(),
+            conflicts: vec![],
+            warnings: vec![],
+            suggestions: vec![],
+            validation_errors: vec![],
+            build: Default::default(),
+            runtime: Default::default(),
+        }
+    }
+
+    fn backing(name: &str, detected_via: &[&str], confidence: f64) -> BackingService {
+        BackingService {
+            name: name.to_string(),
+            detected_via: detected_via.iter().map(|s| s.to_string()).collect(),
+            confidence,
+        }
+    }
+
+    #[test]
+    fn test_merge_nothing() {
+        let aggregate = merge_results(&[]);
+        assert!(aggregate.services.is_empty());
+        assert!(aggregate.shared_languages.is_empty());
+        assert!(aggregate.unique_frameworks.is_empty());
+        assert!(aggregate.backing_services_union.is_empty());
+        assert!(aggregate.version_drift.is_empty());
+    }
+
+    #[test]
+    fn test_merge_single_service() {
+        let mut api = service("services/api", "Go", Some(("Gin", "v1.9.1")));
+        api.metadata.backing_services = vec![backing("redis", &["import"], 0.6)];
+
+        let aggregate = merge_results(&[api]);
+        assert_eq!(
+            aggregate.services.keys().collect::<Vec<_>>(),
+            vec!["services/api"]
+        );
+        assert_eq!(aggregate.shared_languages, vec!["Go"]);
+        assert_eq!(
+            aggregate.unique_frameworks,
+            BTreeSet::from(["Gin".to_string()])
+        );
+        assert_eq!(
+            aggregate.backing_services_union,
+            vec![backing("redis", &["import"], 0.6)]
+        );
+        assert!(aggregate.version_drift.is_empty());
+    }
+
+    #[test]
+    fn test_merge_services() {
+        let mut api = service("services/api", "Go", Some(("Gin", "v1.9.1")));
+        api.metadata.backing_services = vec![
+            backing("postgresql", &["import", "env_var"], 0.8),
+            backing("redis", &["import"], 0.6),
+        ];
+        let mut admin = service("services/admin", "Go", Some(("Gin", "v1.9.1")));
+        admin.metadata.backing_services = vec![backing("postgresql", &["compose"], 0.6)];
+        let gateway = service("services/gateway", "Go", Some(("Gin", "v1.10.0")));
+        let web = service("web", "TypeScript", Some(("Next.js", "14.2.3")));
+
+        let aggregate = merge_results(&[api, admin, gateway, web]);
+        assert_eq!(aggregate.services.len(), 4);
+        assert_eq!(aggregate.shared_languages, vec!["Go"]);
+        assert_eq!(
+            aggregate.unique_frameworks,
+            BTreeSet::from(["Gin".to_string(), "Next.js".to_string()])
+        );
+        assert_eq!(
+            aggregate.backing_services_union,
+            vec![
+                backing("postgresql", &["compose", "env_var", "import"], 0.8),
+                backing("redis", &["import"], 0.6),
+            ]
+        );
+        assert_eq!(
+            aggregate.version_drift,
+            vec![VersionDrift {
+                framework: "Gin".to_string(),
+                versions: BTreeMap::from([
+                    ("v1.10.0".to_string(), vec!["services/gateway".to_string()]),
+                    (
+                        "v1.9.1".to_string(),
+                        vec!["services/admin".to_string(), "services/api".to_string()]
+                    ),
+                ]),
+            }]
+        );
+    }
+
+    #[test]
+    fn test_half_is_not_shared() {
+        let aggregate =
+            merge_results(&[service("api", "Go", None), service("web", "Python", None)]);
+        assert!(aggregate.shared_languages.is_empty());
+
+        // Results without a service path or project name are keyed by position
+        let mut unnamed = service("api", "Go", None);
+        unnamed.metadata.service_path = None;
+        unnamed.metadata.project_name = None;
+        let aggregate = merge_results(&[unnamed.clone(), unnamed]);
+        assert_eq!(
+            aggregate.services.keys().collect::<Vec<_>>(),
+            vec!["#0", "#1"]
+        );
+    }
+}
@@ -1,3 +1,6 @@
+pub mod diff;
+pub mod merge;
+pub mod sbom;
 pub mod schema;
 
 pub use schema::UniversalBuild;
@@ -0,0 +1,266 @@
+//! CycloneDX software bill of materials for a detected service
+
+use super::schema::UniversalBuild;
+use anyhow::{Context, Result};
+use serde::{Deserialize, Serialize};
+use std::path::Path;
+
+pub const CYCLONEDX_SPEC_VERSION: &str = "1.4";
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(rename_all = "camelCase")]
+pub struct CycloneDxBom {
+    /// Always `CycloneDX`
+    pub bom_format: String,
+    pub spec_version: String,
+    pub version: u32,
+    pub metadata: BomMetadata,
+    #[serde(default)]
+    pub components: Vec<Component>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct BomMetadata {
+    pub tools: Vec<Tool>,
+    /// The detected application the BOM describes
+    pub component: Component,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct Tool {
+    pub name: String,
+    pub version: String,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct Component {
+    /// `application` for the service itself, `library` for its dependencies
+    #[serde(rename = "type")]
+    pub component_type: String,
+    #[serde(rename = "bom-ref")]
+    pub bom_ref: String,
+    pub name: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub version: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub purl: Option<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub hashes: Vec<Hash>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct Hash {
+    pub alg: String,
+    /// Hex-encoded digest
+    pub content: String,
+}
+
+impl CycloneDxBom {
+    pub fn to_json(&self) -> Result<String> {
+        serde_json::to_string_pretty(self).context("Failed to serialize SBOM to JSON")
+    }
+}
+
+/// Builds a CycloneDX BOM for `result`, reading lockfiles from `service_dir`
+///
+/// Go dependencies come from go.sum: every module version with a source checksum becomes a
+/// library component carrying that checksum.
+pub fn generate_sbom(result: &UniversalBuild, service_dir: &Path) -> Result<CycloneDxBom> {
+    let name = result
+        .metadata
+        .project_name
+        .clone()
+        .unwrap_or_else(|| "app".to_string());
+
+    let mut application = Component {
+        component_type: "application".to_string(),
+        bom_ref: name.clone(),
+        name,
+        version: None,
+        purl: None,
+        hashes: vec![],
+    };
+    let mut components = Vec::new();
+
+    if result.metadata.language == "Go" {
+        if let Ok(go_mod) = std::fs::read_to_string(service_dir.join("go.mod")) {
+            if let Some(module) = go_module_path(&go_mod) {
+                application.purl = Some(format!("pkg:golang/{}", module));
+            }
+        }
+        let go_sum_path = service_dir.join("go.sum");
+        if go_sum_path.is_file() {
+            let go_sum = std::fs::read_to_string(&go_sum_path)
+                .with_context(|| format!("Failed to read {}", go_sum_path.display()))?;
+            components.extend(go_sum_components(&go_sum));
+        }
+    }
+
+    Ok(CycloneDxBom {
+        bom_format: "CycloneDX".to_string(),
+        spec_version: CYCLONEDX_SPEC_VERSION.to_string(),
+        version: 1,
+        metadata: BomMetadata {
+            tools: vec![Tool {
+                name: "peelbox".to_string(),
+                version: env!("CARGO_PKG_VERSION").to_string(),
+            }],
+            component: application,
+        },
+        components,
+    })
+}
+
+fn go_module_path(go_mod: &str) -> Option<&str> {
+    go_mod
+        .lines()
+        .find_map(|line| line.trim().strip_prefix("module "))
+        .map(|module| module.trim().trim_matches('"'))
+}
+
+/// One library per `module version h1:...` line; `/go.mod`-only entries are modules whose
+/// go.mod was read during resolution but whose code is not built
+fn go_sum_components(go_sum: &str) -> Vec<Component> {
+    let mut components: Vec<Component> = Vec::new();
+    for line in go_sum.lines() {
+        let mut fields = line.split_whitespace();
+        let (Some(module), Some(version), Some(checksum)) =
+            (fields.next(), fields.next(), fields.next())
+        else {
+            continue;
+        };
+        if version.ends_with("/go.mod") {
+            continue;
+        }
+
+        let purl = format!("pkg:golang/{}@{}", module, version);
+        if components.iter().any(|c| c.bom_ref == purl) {
+            continue;
+        }
+        components.push(Component {
+            component_type: "library".to_string(),
+            bom_ref: purl.clone(),
+            name: module.to_string(),
+            version: Some(version.to_string()),
+            purl: Some(purl),
+            hashes: checksum
+                .strip_prefix("h1:")
+                .and_then(base64_to_hex)
+                .map(|content| Hash {
+                    alg: "SHA-256".to_string(),
+                    content,
+                })
+                .into_iter()
+                .collect(),
+        });
+    }
+    components
+}
+
+/// go.sum stores its SHA-256 digests base64-encoded; CycloneDX wants hex
+fn base64_to_hex(encoded: &str) -> Option<String> {
+    const ALPHABET: &[u8] = b"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/";
+
+    let mut bytes = Vec::new();
+    let (mut buffer, mut bits) = (0u32, 0);
+    for c in encoded.trim_end_matches('=').bytes() {
+        let value = ALPHABET.iter().position(|&a| a == c)? as u32;
+        buffer = (buffer << 6) | value;
+        bits += 6;
+        if bits >= 8 {
+            bits -= 8;
+            bytes.push((buffer >> bits) as u8);
+            buffer &= (1 << bits) - 1;
+        }
+    }
+    Some(bytes.iter().map(|b| format!("{:02x}", b)).collect())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::output::schema::BuildMetadata;
+
+    const GO_SUM: &str =
+        "github.com/gin-gonic/gin v1.9.1 h1:4idEAncQnU5cB7BeOkPtxjfCSye0AAm1R0RVIqJ+Jmg=
+github.com/gin-gonic/gin v1.9.1/go.mod h1:hPrL7YrpYKXt5YId3A/Tnip5kqbEAP+KLuI3SUcPTeU=
+github.com/go-playground/locales v0.14.1 h1:EWaQ/wswjilfKLTECiXz7Rh+3BjFhfDFKv/oXslEjJA=
+github.com/go-playground/locales v0.14.1/go.mod h1:hxrqLVvrK65+Rwrd5Fc6F2O76J/NuW9t0sjnWqG1slY=
+github.com/stretchr/objx v0.1.0/go.mod h1:HFkY916IF+rwdDfMAkV7OtwuqBVzrE8GR6GFx+wExME=
+";
+
+    fn go_build() -> UniversalBuild {
+        UniversalBuild {
+            version: "1.0".to_string(),
+            metadata: BuildMetadata {
+                project_name: Some("gin-health".to_string()),
+                language: "Go".to_string(),
+                build_system: "go mod".to_string(),
+                ..Default::default()
+            },
+            confidence: Default::default(),
+            conflicts: vec![],
+            warnings: vec![],
+            suggestions: vec![],
+            validation_errors: vec![],
+            build: Default::default(),
+            runtime: Default::default(),
+        }
+    }
+
+    #[test]
+    fn test_go_sum_dependencies_become_components() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("go.mod"),
+            "module example.com/gin-health\n\ngo 1.21\n",
+        )
+        .unwrap();
+        std::fs::write(dir.path().join("go.sum"), GO_SUM).unwrap();
+
+        let bom = generate_sbom(&go_build(), dir.path()).unwrap();
+        let json: serde_json::Value = serde_json::from_str(&bom.to_json().unwrap()).unwrap();
+
+        assert_eq!(json["bomFormat"], "CycloneDX");
+        assert_eq!(json["specVersion"], "1.4");
+        assert_eq!(json["version"], 1);
+        assert_eq!(json["metadata"]["component"]["type"], "application");
+        assert_eq!(json["metadata"]["component"]["name"], "gin-health");
+        assert_eq!(
+            json["metadata"]["component"]["purl"],
+            "pkg:golang/example.com/gin-health"
+        );
+
+        let components = json["components"].as_array().unwrap();
+        let purls: Vec<&str> = components
+            .iter()
+            .map(|c| c["purl"].as_str().unwrap())
+            .collect();
+        assert_eq!(
+            purls,
+            vec![
+                "pkg:golang/github.com/gin-gonic/gin@v1.9.1",
+                "pkg:golang/github.com/go-playground/locales@v0.14.1",
+            ]
+        );
+        for component in components {
+            assert_eq!(component["type"], "library");
+            assert!(component["bom-ref"].is_string());
+            let hash = &component["hashes"][0];
+            assert_eq!(hash["alg"], "SHA-256");
+            assert_eq!(hash["content"].as_str().unwrap().len(), 64);
+        }
+        assert_eq!(
+            components[0]["hashes"][0]["content"],
+            "e227440277109d4e5c07b05e3a43edc637c24b27b40009b547445522a27e2668"
+        );
+    }
+
+    #[test]
+    fn test_without_lockfile() {
+        let dir = tempfile::tempdir().unwrap();
+        let bom = generate_sbom(&go_build(), dir.path()).unwrap();
+        assert!(bom.components.is_empty());
+        assert_eq!(bom.metadata.component.purl, None);
+    }
+}
@@ -1,6 +1,6 @@
 use anyhow::{Context, Result};
 use serde::{Deserialize, Deserializer, Serialize};
-use std::collections::HashMap;
+use std::collections::{BTreeMap, HashMap};
 use std::fmt;
 
 #[derive(Debug, Clone, Serialize, Deserialize)]
@@ -23,6 +23,10 @@ where
     Ok(Option::deserialize(deserializer)?.unwrap_or_else(default_version))
 }
 
+fn is_false(value: &bool) -> bool {
+    !value
+}
+
 fn default_version() -> String {
     "1.0".to_string()
 }
@@ -36,12 +40,29 @@ pub struct UniversalBuild {
     pub version: String,
     #[serde(default, deserialize_with = "deserialize_null_default")]
     pub metadata: BuildMetadata,
+    /// Confidence in each detected field, keyed by field name
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub confidence: FieldConfidence,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub conflicts: Vec<DetectionConflict>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub warnings: Vec<String>,
+    /// Changes to the repository that would make the build more complete (e.g., adding a
+    /// health endpoint)
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub suggestions: Vec<String>,
+    /// Fields of this result that contradict each other
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub validation_errors: Vec<ValidationError>,
     #[serde(default, deserialize_with = "deserialize_null_default")]
     pub build: BuildStage,
     #[serde(default, deserialize_with = "deserialize_null_default")]
     pub runtime: RuntimeStage,
 }
 
+/// Detection confidence per output field (e.g. "language", "framework"), each in 0.0..=1.0
+pub type FieldConfidence = BTreeMap<String, f64>;
+
 #[derive(Debug, Clone, Serialize, Deserialize, Default)]
 pub struct BuildMetadata {
     #[serde(skip_serializing_if = "Option::is_none")]
@@ -52,8 +73,873 @@ pub struct BuildMetadata {
     pub build_system: String,
     #[serde(skip_serializing_if = "Option::is_none")]
     pub framework: Option<String>,
+    /// Exact framework version from go.sum or the lockfile, else the manifest's requirement
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub framework_version: Option<FrameworkVersion>,
     #[serde(default, deserialize_with = "deserialize_null_default")]
     pub reasoning: String,
+    /// Service directory relative to the repository root (`.` for the root)
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub service_path: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub workspace: Option<WorkspaceMetadata>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub monorepo: Option<MonorepoMetadata>,
+    /// Services in other languages nested in this service's directory, e.g. a frontend
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub secondary_languages: Vec<SecondaryLanguage>,
+    /// Why this service's language is the primary one when it has secondary languages
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub primary_language_heuristic: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub django: Option<DjangoMetadata>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub node: Option<NodeMetadata>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub kotlin: Option<KotlinMetadata>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub scala: Option<ScalaMetadata>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub haskell: Option<HaskellMetadata>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub deno: Option<DenoMetadata>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub dart: Option<DartMetadata>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub python: Option<PythonMetadata>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub bazel: Option<BazelMetadata>,
+    /// Cargo crate kind: "binary" or "library"
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub crate_type: Option<String>,
+    /// Runtime executing the code when the language has more than one (`deno` for TypeScript)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub runtime: Option<String>,
+    /// Runtime version constraint declared by the manifest (e.g., composer.json `require.php`)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub runtime_version: Option<String>,
+    /// .NET SDK pinned by global.json (`sdk.version`)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub sdk_version: Option<String>,
+    /// Runtime versions pinned by .tool-versions or `.<runtime>-version` files, keyed by runtime
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub runtime_versions: BTreeMap<String, String>,
+    /// Recipes of the Makefile's build, run, start, test, lint, install and deploy targets
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub makefile_targets: BTreeMap<String, Vec<String>>,
+    /// Where `build.commands` comes from: "native" (the build system) or "makefile"
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub build_command_source: Option<String>,
+    /// Base image of the service Dockerfile's final stage
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub dockerfile_from: Option<String>,
+    /// Ports the service Dockerfile EXPOSEs
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub dockerfile_expose: Vec<u16>,
+    /// The service Dockerfile's ENTRYPOINT and CMD
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub dockerfile_cmd: Option<Vec<String>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub compose: Option<ComposeMetadata>,
+    /// Databases and caches the service depends on in Docker Compose
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub external_services: Vec<ExternalService>,
+    /// Environment variables the service reads in code or Docker Compose sets for it
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub required_env_vars: Vec<String>,
+    /// Databases and caches the service needs, from client libraries, env vars and Compose
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub backing_services: Vec<BackingService>,
+    /// "temporal" when the service is built on a Temporal SDK
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub workflow_engine: Option<String>,
+    /// Temporal role: "worker", "client" or "both"
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub service_role: Option<String>,
+    /// "service", "cli", "library", "batch" for a scheduled job or "proxy" for a reverse proxy
+    /// or gateway
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub project_type: Option<String>,
+    /// How strongly the signals support `project_type`, 0.0..=1.0
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub project_type_confidence: Option<f64>,
+    /// Other types the project shows signals of, e.g. "cli" for a service started by a Cobra
+    /// command
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub secondary_project_types: Vec<String>,
+    /// "cobra" when the commands are built with Cobra
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub cli_framework: Option<String>,
+    /// "viper" when configuration is read with Viper
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub config_library: Option<String>,
+    /// How to invoke a CLI tool, which has no port to probe
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub run_command: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub observability: Option<ObservabilityMetadata>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub observability_artifacts: Option<ObservabilityArtifacts>,
+    /// Logging library: zap, zerolog, logrus, slog, structlog or loguru
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub logging_library: Option<String>,
+    /// "json" or "text", the format the service's log records come out in
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub log_format_hint: Option<String>,
+    /// The source configures JSON logging itself, e.g. with `zap.NewProduction()`
+    #[serde(default, skip_serializing_if = "is_false")]
+    pub log_json_configured: bool,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub feature_flags: Option<FeatureFlagsMetadata>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub grpc: Option<GrpcMetadata>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub buf: Option<BufMetadata>,
+    /// "buf" when buf generates the gRPC stubs instead of a protoc invocation
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub proto_toolchain: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub sqlc: Option<SqlcMetadata>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub api_schema: Option<ApiSchema>,
+    /// The API schema is generated from code or generates it (swag, oapi-codegen)
+    #[serde(default, skip_serializing_if = "is_false")]
+    pub api_schema_generated: bool,
+    /// Go code imports "C", so the build runs with `CGO_ENABLED=1` and a C compiler
+    #[serde(default, skip_serializing_if = "is_false")]
+    pub cgo: bool,
+    /// `CGO_ENABLED=0` is set in a `.env` file or shell script despite cgo imports
+    #[serde(default, skip_serializing_if = "is_false")]
+    pub cgo_disabled: bool,
+    /// Flags the build command should pass, e.g. `-tags cgo`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub build_flags: Vec<String>,
+    /// `go generate` steps to run before the build, from the package that declares them
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub pre_build_commands: Vec<String>,
+    /// Code generators the pre-build commands need: Go module paths or binary names
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub build_dependencies: Vec<String>,
+    /// `//go:embed` patterns compiled into the binary
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub embedded_assets: Vec<EmbeddedAsset>,
+    /// An embedded pattern covers frontend build output (`dist/`, `build/`, `public/`)
+    #[serde(default, skip_serializing_if = "is_false")]
+    pub has_embedded_frontend: bool,
+    /// go.mod `replace` directives pointing at directories rather than module versions
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub local_replacements: Vec<GoReplacement>,
+    /// Library the tests are written with (`testing`, `testify`, `ginkgo`, `gomega`)
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub test_framework: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub test_command: Option<String>,
+    /// Build, test and deploy commands the repository's GitHub Actions workflows run in the
+    /// service directory
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub ci_commands: Vec<CiCommand>,
+    /// Tests kept apart behind an `integration` build tag or in `integration_test.go`
+    #[serde(default, skip_serializing_if = "is_false")]
+    pub has_integration_tests: bool,
+    /// Linters and static analysis tools with a config file in the service
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub lint_tools: Vec<LintTool>,
+    /// SPDX identifier, or `unknown` for a license file matching no known text
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub license: Option<String>,
+    /// `serverless` when a function-as-a-service config deploys the service
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub deployment_target: Option<String>,
+    /// aws-lambda, gcp-cloud-functions, gcp-app-engine or azure-functions
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub serverless_platform: Option<String>,
+    /// Serverless config relative to the repository
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub serverless_config: Option<String>,
+    /// Run from the directory holding `serverless_config`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub deploy_command: Option<String>,
+    /// Handler of the SAM function built from the service directory
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub serverless_handler: Option<String>,
+    /// Infrastructure-as-code tool provisioning the service: terraform, pulumi or cdk
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub iac_tool: Option<String>,
+    /// Directory of the IaC project relative to the repository
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub iac_path: Option<String>,
+    /// Terraform provider source addresses
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub iac_providers: Vec<String>,
+    /// Clouds the Terraform providers deploy to: aws, google, azurerm or kubernetes
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub cloud_providers: Vec<String>,
+    /// Terraform state backend, e.g. `s3`, `gcs` or `terraform-cloud`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub iac_state_backend: Option<String>,
+    /// IaC directory inside the service, e.g. `infra/`; it is not built as part of the service
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub infra_subdirectory: Option<String>,
+    /// Language of the Pulumi program or CDK app
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub iac_language: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub iac_version_constraint: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub iac_deploy_command: Option<String>,
+    /// "goreleaser" when a GoReleaser config cuts the service's releases
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub release_tool: Option<String>,
+    /// GoReleaser config relative to the repository
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub release_config: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub release_command: Option<String>,
+    /// Binaries the release builds
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub release_binaries: Vec<String>,
+    /// GOOS/GOARCH pairs the release builds, e.g. `linux/arm64`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub cross_compile_targets: Vec<String>,
+    /// Archives the release packages binaries in, e.g. `tar.gz` and `zip`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub release_archive_formats: Vec<String>,
+    /// "ko" when images are built from the Go source without a Dockerfile
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub container_build_tool: Option<String>,
+    /// Builds and pushes the image to `KO_DOCKER_REPO`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub container_build_command: Option<String>,
+    /// GOOS/GOARCH named by Go build constraints
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub build_constraints: Option<BuildConstraints>,
+    /// External binaries the build invokes besides the language toolchain
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub required_tools: Vec<RequiredTool>,
+    /// golang-migrate, goose, atlas, flyway or liquibase
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub migration_tool: Option<String>,
+    /// Migrations directory relative to the service
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub migration_path: Option<String>,
+    /// Applies pending migrations; run from the service directory with `DATABASE_URL` set
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub migration_command: Option<String>,
+    /// Whether go.sum sits next to go.mod; unset for services without go.mod
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub go_sum_present: Option<bool>,
+    /// Whether go.sum records every module go.mod requires
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub go_sum_complete: Option<bool>,
+    /// Requirements missing from go.sum, as `module@version`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub go_sum_missing: Vec<String>,
+    /// `graphql` when the service is a gqlgen or graphql-go server
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub api_type: Option<String>,
+    /// GraphQL schema files relative to the service
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub schema_files: Vec<String>,
+    /// The GraphQL schema declares a `Subscription` type, served over WebSockets
+    #[serde(default, skip_serializing_if = "is_false")]
+    pub graphql_subscriptions: bool,
+    /// gqlgen's model output directory relative to the service
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub graphql_model_dir: Option<String>,
+    /// gqlgen's resolver output directory relative to the service
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub graphql_resolver_dir: Option<String>,
+    /// Language version of the go.mod `go` directive
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub go_module_version: Option<String>,
+    /// Minimum Go toolchain from the go.mod `toolchain` directive, without the `go` prefix
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub go_toolchain_version: Option<String>,
+    /// `key=value` settings of go.mod `godebug` directives
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub godebug_settings: Vec<String>,
+    /// Tools pinned by go.mod `tool` directives, run with `go tool <name>`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub go_tools: Vec<GoTool>,
+    /// Direct go.mod requirements with a newer release on the module proxy; only filled in
+    /// by `detect --check-upgrades`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub upgrades_available: Vec<UpgradeAvailable>,
+    /// Direct go.mod requirements on abandoned, archived or superseded modules
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub deprecated_dependencies: Vec<DeprecatedDependency>,
+    /// Longest chain of transitive Go module requirements, from go_mod_graph.txt
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub dependency_depth: Option<usize>,
+    /// Modules in the Go module graph besides the main one, from go_mod_graph.txt
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub dependency_count: Option<usize>,
+    /// `browser` for `GOOS=js` WebAssembly builds, `wasi` for `GOOS=wasip1`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub wasm_target: Option<String>,
+    /// Live-reloading command for local development, e.g. `air`; the image runs
+    /// `runtime.command`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub dev_run_command: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub resource_hints: Option<ResourceHints>,
+    /// `nix` when flake.nix or shell.nix declares the development environment
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub build_environment: Option<String>,
+    /// Tools the Nix shell provides, e.g. `Go 1.22`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub build_environment_tools: Vec<String>,
+    /// `nix develop` for a flake, `nix-shell` for shell.nix
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub nix_develop_command: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub kubernetes: Option<KubernetesMetadata>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub devcontainer: Option<DevContainerMetadata>,
+}
+
+/// A field where detection from source disagrees with what the repository declares
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct DetectionConflict {
+    pub field: String,
+    /// Value detected from source code and config
+    pub detected: String,
+    /// Conflicting declared value
+    pub declared: String,
+    /// Where the declared value comes from (e.g., "Dockerfile")
+    pub source: String,
+}
+
+/// An internal inconsistency in a detection result
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct ValidationError {
+    /// Path of the offending field, e.g. `runtime.copy[0].from`
+    pub field: String,
+    /// Stable identifier of the check, e.g. `artifact_not_built`
+    pub code: String,
+    pub message: String,
+}
+
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct WorkspaceMetadata {
+    #[serde(default, deserialize_with = "deserialize_null_default")]
+    pub manifest: String,
+    #[serde(default, deserialize_with = "deserialize_null_default")]
+    pub members: Vec<String>,
+    /// Root devDependencies that workspace members depend on too
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub shared_tooling: Vec<String>,
+}
+
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct DjangoMetadata {
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub settings_module: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub wsgi_application: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub asgi_application: Option<String>,
+    #[serde(default, skip_serializing_if = "is_false")]
+    pub asgi_capable: bool,
+}
+
+/// Monorepo orchestrator (Nx, Turborepo, Lerna) managing this package
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct MonorepoMetadata {
+    #[serde(default, deserialize_with = "deserialize_null_default")]
+    pub tool: String,
+    /// Package paths, relative to the repository root
+    #[serde(default, deserialize_with = "deserialize_null_default")]
+    pub packages: Vec<String>,
+    /// Scripts from the root package.json
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub root_scripts: BTreeMap<String, String>,
+}
+
+/// A nested service whose language differs from the enclosing one
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct SecondaryLanguage {
+    /// Service directory relative to the repository root
+    pub path: String,
+    pub language: String,
+    pub build_system: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub framework: Option<String>,
+}
+
+/// Docker Compose service built from this service's directory
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct ComposeMetadata {
+    #[serde(default, deserialize_with = "deserialize_null_default")]
+    pub file: String,
+    #[serde(default, deserialize_with = "deserialize_null_default")]
+    pub service: String,
+    /// Compose services this one depends on, directly or transitively
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub depends_on: Vec<String>,
+}
+
+/// A backing service (database, cache) declared in Docker Compose
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct ExternalService {
+    /// Compose service name
+    pub name: String,
+    /// "postgres", "mysql", "redis", "mongodb" or "temporal"
+    pub kind: String,
+    pub image: String,
+}
+
+/// A backing service the app needs and the signals that point to it
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct BackingService {
+    /// "postgresql", "mysql", "redis", "mongodb" or "temporal"
+    pub name: String,
+    /// Signals found: "import", "env_var" and/or "compose"
+    pub detected_via: Vec<String>,
+    /// Grows with the number of corroborating signals
+    pub confidence: f64,
+}
+
+/// Tracing and metrics libraries a service instruments itself with
+#[derive(Debug, Clone, Default, Serialize, Deserialize, PartialEq)]
+pub struct ObservabilityMetadata {
+    /// "opentelemetry" or "jaeger"
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub tracing: Option<String>,
+    /// "prometheus"
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub metrics: Option<String>,
+    /// OpenTelemetry exporter: "otlpgrpc", "otlphttp", "zipkin" or "jaeger"
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub otel_exporter: Option<String>,
+    /// Path the Prometheus handler is registered on, e.g. "/metrics"
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub metrics_path: Option<String>,
+}
+
+/// Grafana dashboards and Prometheus alerting rules kept next to a service, relative to it
+#[derive(Debug, Clone, Default, Serialize, Deserialize, PartialEq)]
+pub struct ObservabilityArtifacts {
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub dashboards: Vec<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub alert_rules: Vec<String>,
+    /// Data sources the dashboards query, in dashboard order
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub datasources: Vec<DashboardDatasource>,
+}
+
+/// A data source a Grafana dashboard queries
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct DashboardDatasource {
+    pub dashboard: String,
+    /// Data source name, plugin type or import label, e.g. "PostgreSQL"
+    pub name: String,
+    /// The detected backing service the data source is named after, e.g. "postgresql"
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub backing_service: Option<String>,
+}
+
+/// Feature flag SDK and the flag definitions a service ships with
+#[derive(Debug, Clone, Default, Serialize, Deserialize, PartialEq)]
+pub struct FeatureFlagsMetadata {
+    /// "openfeature", "go-feature-flag", "launchdarkly" or "unleash"
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub sdk: Option<String>,
+    /// OpenFeature provider, e.g. "flagd" or "go-feature-flag"
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub provider_hint: Option<String>,
+    /// JSON or YAML flag definition files, relative to the service
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub config_files: Vec<String>,
+}
+
+/// Rough starting resource requests, estimated from the runtime and dependencies
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct ResourceHints {
+    pub min_memory_mb: u32,
+    pub recommended_memory_mb: u32,
+    pub cpu_millicores: u32,
+    /// A machine learning library suggests scheduling on a GPU node
+    #[serde(default, skip_serializing_if = "is_false")]
+    pub gpu: bool,
+    /// What raised the estimate above the runtime baseline
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub notes: Vec<String>,
+    /// "medium" or "low"; these are heuristics, never measurements
+    pub estimation_confidence: String,
+}
+
+/// The first container of the Deployment or StatefulSet a Kubernetes manifest declares
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct KubernetesMetadata {
+    /// Manifest holding the workload, relative to the repository
+    pub manifest: String,
+    /// Deployment or StatefulSet
+    pub kind: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub image: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub cpu_request: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub memory_request: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub cpu_limit: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub memory_limit: Option<String>,
+    /// `httpGet.path` of the liveness probe
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub liveness_path: Option<String>,
+    /// `httpGet.path` of the readiness probe
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub readiness_path: Option<String>,
+}
+
+/// The development environment a devcontainer.json declares
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct DevContainerMetadata {
+    /// devcontainer.json, relative to the repository
+    pub config_file: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub image: Option<String>,
+    /// Dockerfile the dev container is built from, relative to devcontainer.json
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub dockerfile: Option<String>,
+    /// `postCreateCommand` as a single shell line
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub post_create: Option<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub forward_ports: Vec<u16>,
+    /// Dev container feature references, e.g. `ghcr.io/devcontainers/features/go:1`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub features: Vec<String>,
+}
+
+/// A command a CI workflow runs
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct CiCommand {
+    /// "build", "test" or "deploy"
+    pub kind: String,
+    pub command: String,
+    /// Always "ci_workflow"
+    pub source: String,
+    /// Workflow file, relative to the repository
+    pub workflow: String,
+}
+
+/// A requirement on a deprecated module and the module to move to
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct DeprecatedDependency {
+    pub module: String,
+    pub replacement: String,
+    /// "abandoned", "archived", "renamed" or "superseded"
+    pub reason: String,
+}
+
+/// A go.mod requirement pinned below the latest release
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct UpgradeAvailable {
+    pub module: String,
+    pub current: String,
+    /// Newest release; a new major version lives at `<module>/vN`
+    pub latest: String,
+    /// The major version differs, so the upgrade may break callers
+    pub breaking_change: bool,
+}
+
+/// A tool dependency declared by a go.mod `tool` directive
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct GoTool {
+    pub name: String,
+    pub module: String,
+}
+
+/// The dependency a framework was detected from and the version the build resolves
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct FrameworkVersion {
+    pub framework: String,
+    pub version: String,
+    /// Go module path or package name of the dependency
+    pub module: String,
+}
+
+/// Protobuf definitions of a gRPC service and whether their Go stubs are checked in
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct GrpcMetadata {
+    /// `.proto` files, relative to the repository root
+    pub proto_files: Vec<String>,
+    /// Whether generated stubs (`*_grpc.pb.go`) exist
+    pub generated: bool,
+    /// protoc invocation generating the stubs, to run before the build
+    pub build_command_prefix: String,
+}
+
+/// buf configuration of a protobuf module; paths are relative to the repository root
+#[derive(Debug, Clone, Default, Serialize, Deserialize, PartialEq)]
+pub struct BufMetadata {
+    /// buf.yaml, the module and its lint and breaking-change rules
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub config_file: Option<String>,
+    /// buf.gen.yaml, the plugins `buf generate` runs
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub template_file: Option<String>,
+    /// Plugins by protoc plugin name, e.g. "protoc-gen-go-grpc", in template order
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub plugins: Vec<String>,
+    /// Plugins run from PATH rather than on the Buf Schema Registry
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub local_plugins: Vec<String>,
+    /// Modules pinned in buf.lock, e.g. "buf.build/googleapis/googleapis"
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub dependencies: Vec<String>,
+}
+
+/// sqlc config of a service and the Go packages it generates from SQL
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct SqlcMetadata {
+    /// "sqlc.yaml", "sqlc.yml" or "sqlc.json", relative to the service
+    pub config_file: String,
+    pub packages: Vec<SqlcPackage>,
+}
+
+/// A Go package sqlc generates; paths are relative to the service
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct SqlcPackage {
+    /// "postgresql", "mysql" or "sqlite"
+    pub engine: String,
+    /// Query files or directories
+    pub queries: Vec<String>,
+    /// Schema files or migration directories
+    pub schema: Vec<String>,
+    /// Go package name
+    pub package: String,
+    /// Output directory
+    pub out: String,
+    /// The output directory holds generated code; false in a fresh clone that ignores it
+    pub generated: bool,
+    /// A Go file outside the output directory imports the package
+    pub imported: bool,
+}
+
+/// A `//go:embed` pattern and the directive declaring it
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct EmbeddedAsset {
+    pub pattern: String,
+    /// Go file relative to the service directory
+    pub file: String,
+    /// 1-based line of the directive
+    pub line: usize,
+}
+
+/// A go.mod `replace` directive swapping a module for a directory
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct GoReplacement {
+    pub module: String,
+    /// Directory as written in go.mod, relative to the module root unless absolute
+    pub path: String,
+}
+
+/// A linter configured for the service and how to run it
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct LintTool {
+    pub tool: String,
+    /// Config file relative to the service directory
+    pub config: String,
+    pub command: String,
+}
+
+/// An external binary needed at build time
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct RequiredTool {
+    pub name: String,
+    /// Pinned version, or a `>=` lower bound from the module requirement
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub version_hint: Option<String>,
+    /// Only known for tools installable with `go install`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub install_command: Option<String>,
+}
+
+/// Operating systems and architectures Go build constraints target or exclude
+#[derive(Debug, Clone, Default, Serialize, Deserialize, PartialEq)]
+pub struct BuildConstraints {
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub os: Vec<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub arch: Vec<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub excluded_os: Vec<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub excluded_arch: Vec<String>,
+    /// Distinct `//go:build` expressions, legacy `// +build` lines joined with `&&`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub expressions: Vec<String>,
+}
+
+/// OpenAPI or Swagger document describing the service's HTTP API
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct ApiSchema {
+    /// Relative to the service directory
+    pub path: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub title: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub version: Option<String>,
+    /// Prefix all routes share: Swagger `basePath` or the path of the first server URL
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub base_path: Option<String>,
+}
+
+/// package.json scripts and entry point
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct NodeMetadata {
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub entry_point: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub start_script: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub build_script: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub dev_script: Option<String>,
+    /// package.json `packageManager` without its version, overridden by a Bun lockfile
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub package_manager: Option<String>,
+}
+
+/// Kotlin plugin facts read from build.gradle.kts
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct KotlinMetadata {
+    /// Version of the `kotlin("jvm")` or `kotlin("multiplatform")` plugin
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub version: Option<String>,
+    #[serde(default, skip_serializing_if = "is_false")]
+    pub multiplatform: bool,
+    /// Multiplatform targets configured in the `kotlin { }` block (e.g., "jvm", "linuxX64")
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub targets: Vec<String>,
+    /// Gradle task running the app locally: `./gradlew run` (Ktor) or `./gradlew bootRun`
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub run_command: Option<String>,
+}
+
+/// Local sbt or Mill commands read from build.sbt / build.sc
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct ScalaMetadata {
+    /// `scalaVersion` of the build
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub version: Option<String>,
+    /// Packaging command, e.g. `sbt dist` for Play
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub build_command: Option<String>,
+    /// `sbt run`, or `mill <module>.run` for the Mill module declaring `mainClass`
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub run_command: Option<String>,
+}
+
+/// Tasks and imports read from deno.json / deno.jsonc
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct DenoMetadata {
+    /// Command of the `start` task
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub run_command: Option<String>,
+    /// Command of the `build` task
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub build_command: Option<String>,
+    /// `importMap` file the config points to
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub import_map: Option<String>,
+    /// Import specifiers and the modules they map to, from the config and its import map
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub dependencies: BTreeMap<String, String>,
+}
+
+/// Dart or Flutter project facts read from pubspec.yaml and the test/ directory
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct DartMetadata {
+    /// Depends on the Flutter SDK
+    #[serde(default)]
+    pub flutter: bool,
+    /// `environment.sdk` constraint
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub sdk: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub run_command: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub build_command: Option<String>,
+    /// `dart test` or `flutter test`, when test/ holds tests
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub test_command: Option<String>,
+    /// `*_test.dart` files under test/
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub test_files: Vec<String>,
+}
+
+/// Python project facts read from pyproject.toml or a Conda environment.yml
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct PythonMetadata {
+    /// Set for package managers other than the pyproject tool, i.e. `conda`
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub package_manager: Option<String>,
+    /// Tool behind `[build-system].build-backend`: Poetry, PDM, Hatch or setuptools
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub build_backend: Option<String>,
+    /// Sets up the project environment, e.g. `pdm install` or `hatch env create`
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub install_command: Option<String>,
+    /// Activates a named Conda environment, e.g. `conda activate churn-model`
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub activate_command: Option<String>,
+    /// `web` or `data-science`, from the packages a Conda environment installs
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub workload: Option<String>,
+    /// Console scripts from `[project.scripts]` or `[tool.poetry.scripts]`, by name
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub scripts: BTreeMap<String, String>,
+    /// Commands running each script through the project's tool, e.g. `pdm run ledger`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub run_commands: Vec<String>,
+}
+
+/// The Bazel target a service is built from and the command that builds it
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct BazelMetadata {
+    /// Label of the primary binary, e.g. `//cmd/server:server`
+    pub target: String,
+    /// `cc_binary`, `go_binary`, `java_binary` or `py_binary`
+    pub rule: String,
+    pub build_command: String,
+    /// `bazelisk` when .bazelversion pins a release, else `bazel`
+    pub tool: String,
+    /// Release pinned by .bazelversion
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub version: Option<String>,
+}
+
+/// Stack or Cabal project layout and the commands for working on it locally
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct HaskellMetadata {
+    /// Stackage snapshot from stack.yaml, e.g. `lts-22.7`
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub resolver: Option<String>,
+    /// Package directories listed in stack.yaml
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub packages: Vec<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub executables: Vec<HaskellExecutable>,
+    /// `stack build` or `cabal build`
+    pub build_command: String,
+    /// `stack exec -- <exe>` or `cabal run <exe>` for the first executable
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub run_command: Option<String>,
+}
+
+/// An `executable` stanza of a .cabal file (or hpack `executables` entry)
+#[derive(Debug, Clone, Serialize, Deserialize, Default, PartialEq)]
+pub struct HaskellExecutable {
+    pub name: String,
+    /// Module file holding `main`
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub main_is: Option<String>,
 }
 
 #[derive(Debug, Clone, Serialize, Deserialize, Default)]
@@ -81,6 +967,21 @@ pub struct RuntimeStage {
     pub ports: Vec<u16>,
     #[serde(skip_serializing_if = "Option::is_none")]
     pub health: Option<HealthCheck>,
+    /// Path to probe for health checks; always present so `null` reads as "none found"
+    #[serde(default)]
+    pub health_check_path: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub health_check_hint: Option<String>,
+    #[serde(
+        default,
+        deserialize_with = "deserialize_null_default",
+        skip_serializing_if = "Vec::is_empty"
+    )]
+    pub auxiliary_commands: Vec<Vec<String>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub detected_port: Option<u16>,
+    #[serde(default, skip_serializing_if = "is_false")]
+    pub port_from_env: bool,
 }
 
 #[derive(Debug, Clone, Serialize, Deserialize, Default)]
@@ -104,6 +1005,10 @@ impl UniversalBuild {
     pub fn to_yaml(&self) -> Result<String> {
         serde_yaml::to_string(self).context("Failed to serialize UniversalBuild to YAML")
     }
+
+    pub fn to_toml(&self) -> Result<String> {
+        toml::to_string_pretty(self).context("Failed to serialize UniversalBuild to TOML")
+    }
 }
 
 #[cfg(test)]
@@ -119,7 +1024,13 @@ mod tests {
                 build_system: "cargo".to_string(),
                 framework: None,
                 reasoning: "Detected Cargo.toml".to_string(),
+                ..Default::default()
             },
+            confidence: Default::default(),
+            conflicts: vec![],
+            warnings: vec![],
+            suggestions: vec![],
+            validation_errors: vec![],
             build: BuildStage {
                 packages: vec!["rust".to_string(), "build-base".to_string()],
                 env: HashMap::new(),
@@ -136,6 +1047,7 @@ mod tests {
                 command: vec!["/usr/local/bin/app".to_string()],
                 ports: vec![],
                 health: None,
+                ..Default::default()
             },
         }
     }
@@ -168,6 +1080,19 @@ mod tests {
         assert_eq!(build.runtime.packages, deserialized.runtime.packages);
     }
 
+    #[test]
+    fn test_toml_round_trip() {
+        let build = create_minimal_valid_build();
+        let toml_str = build.to_toml().unwrap();
+        assert!(toml_str.contains("[metadata]"));
+
+        let deserialized: UniversalBuild = toml::from_str(&toml_str).unwrap();
+        assert_eq!(
+            serde_json::to_value(&build).unwrap(),
+            serde_json::to_value(&deserialized).unwrap()
+        );
+    }
+
     #[test]
     fn test_display_yaml_format() {
         let build = create_minimal_valid_build();
@@ -279,6 +1204,20 @@ mod tests {
         assert!(build.runtime.copy.is_empty());
     }
 
+    #[test]
+    fn test_detected_port_serialization() {
+        let mut build = create_minimal_valid_build();
+        let json = serde_json::to_value(&build).unwrap();
+        assert!(json["runtime"].get("detected_port").is_none());
+        assert!(json["runtime"].get("port_from_env").is_none());
+
+        build.runtime.detected_port = Some(3000);
+        build.runtime.port_from_env = true;
+        let json = serde_json::to_value(&build).unwrap();
+        assert_eq!(json["runtime"]["detected_port"], 3000);
+        assert_eq!(json["runtime"]["port_from_env"], true);
+    }
+
     #[test]
     fn test_deserialize_missing_optional_fields() {
         let json = r#"{
@@ -339,7 +1278,13 @@ mod tests {
                 build_system: "".to_string(),
                 framework: None,
                 reasoning: "".to_string(),
+                ..Default::default()
             },
+            confidence: Default::default(),
+            conflicts: vec![],
+            warnings: vec![],
+            suggestions: vec![],
+            validation_errors: vec![],
             build: BuildStage {
                 packages: vec![],
                 env: HashMap::new(),
@@ -353,6 +1298,7 @@ mod tests {
                 command: vec![],
                 ports: vec![],
                 health: None,
+                ..Default::default()
             },
         };
     }
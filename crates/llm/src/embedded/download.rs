@@ -193,20 +193,20 @@ impl ModelDownloader {
         }
     }
 
-    /// Prompt the user to confirm model download
+    /// Prompt the user to confirm model download, on stderr so stdout stays machine-readable
     fn prompt_download(model: &EmbeddedModel) -> Result<bool> {
-        println!();
-        println!("peelbox needs to download an embedded LLM model for local inference.");
-        println!();
-        println!(
+        eprintln!();
+        eprintln!("peelbox needs to download an embedded LLM model for local inference.");
+        eprintln!();
+        eprintln!(
             "  Model: {} ({} parameters)",
             model.display_name, model.params
         );
-        println!("  Requires: ~{:.1} GB RAM", model.ram_required_gb);
-        println!("  Source: huggingface.co/{}", model.repo_id);
-        println!();
-        print!("Download model? [Y/n] ");
-        io::stdout().flush()?;
+        eprintln!("  Requires: ~{:.1} GB RAM", model.ram_required_gb);
+        eprintln!("  Source: huggingface.co/{}", model.repo_id);
+        eprintln!();
+        eprint!("Download model? [Y/n] ");
+        io::stderr().flush()?;
 
         let mut input = String::new();
         io::stdin().read_line(&mut input)?;
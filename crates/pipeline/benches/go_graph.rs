@@ -0,0 +1,83 @@
+//! Go module graph benchmarks on a go.mod/go.sum pair the size of a typical web service
+//!
+//! Run with `cargo bench -p peelbox-pipeline --bench go_graph`.
+
+use peelbox_pipeline::extractors::build_dependency_graph;
+use std::hint::black_box;
+use std::time::{Duration, Instant};
+
+/// Modules in the generated graph; go.sum records two lines for each, plus older versions
+const MODULES: usize = 160;
+const ITERATIONS: u32 = 200;
+
+fn go_mod() -> String {
+    let mut go_mod = String::from("module example.com/shop\n\ngo 1.22\n\nrequire (\n");
+    for i in 0..MODULES {
+        let indirect = if i % 4 == 0 { "" } else { " // indirect" };
+        go_mod.push_str(&format!(
+            "\tgithub.com/vendor{}/module{} v1.{}.0{}\n",
+            i % 20,
+            i,
+            i % 7,
+            indirect
+        ));
+    }
+    go_mod.push_str(")\n\nreplace github.com/vendor0/module0 => github.com/shop/module0 v1.0.1\n");
+    go_mod
+}
+
+fn go_sum() -> String {
+    let hash = "h1:4idEAncQnU5cB7BeOkPtxjfCSye0AAm1R0RVIqJ+Jmg=";
+    let mut go_sum = String::new();
+    for i in 0..MODULES {
+        let module = format!("github.com/vendor{}/module{}", i % 20, i);
+        if i % 3 == 0 {
+            go_sum.push_str(&format!("{} v1.0.0/go.mod {}\n", module, hash));
+        }
+        go_sum.push_str(&format!("{} v1.{}.0 {}\n", module, i % 7, hash));
+        go_sum.push_str(&format!("{} v1.{}.0/go.mod {}\n", module, i % 7, hash));
+    }
+    go_sum
+}
+
+fn bench(name: &str, mut run: impl FnMut()) {
+    run();
+    let started = Instant::now();
+    for _ in 0..ITERATIONS {
+        run();
+    }
+    let per_iteration: Duration = started.elapsed() / ITERATIONS;
+    println!(
+        "{:<28} {:>10.1} µs/iter",
+        name,
+        per_iteration.as_secs_f64() * 1e6
+    );
+}
+
+fn main() {
+    let go_mod = go_mod();
+    let go_sum = go_sum();
+    println!(
+        "go.mod with {} requirements, go.sum with {} lines",
+        MODULES,
+        go_sum.lines().count()
+    );
+
+    bench("build_dependency_graph", || {
+        black_box(build_dependency_graph(black_box(&go_mod), black_box(&go_sum)).unwrap());
+    });
+
+    let graph = build_dependency_graph(&go_mod, &go_sum).unwrap();
+    bench("direct_dependencies", || {
+        black_box(graph.direct_dependencies());
+    });
+    bench("all_transitive_dependencies", || {
+        black_box(graph.all_transitive_dependencies());
+    });
+    bench("find_cycles", || {
+        black_box(graph.find_cycles());
+    });
+    bench("to_dot", || {
+        black_box(graph.to_dot());
+    });
+}
@@ -0,0 +1,27 @@
+//! Streams detection events for a repository to stdout as JSON lines
+//!
+//! cargo run -p peelbox-pipeline --example stream_detection -- path/to/repo
+
+use peelbox_core::config::DetectionMode;
+use peelbox_pipeline::{AnalysisContext, PipelineOrchestrator};
+use peelbox_stack::StackRegistry;
+use std::path::PathBuf;
+use std::sync::Arc;
+
+#[tokio::main]
+async fn main() -> anyhow::Result<()> {
+    let repo_path = PathBuf::from(std::env::args().nth(1).unwrap_or_else(|| ".".to_string()));
+
+    let context = AnalysisContext::new(
+        &repo_path,
+        Arc::new(StackRegistry::with_defaults(None)),
+        Arc::new(peelbox_wolfi::WolfiPackageIndex::fetch()?),
+        DetectionMode::StaticOnly,
+    );
+
+    let mut events = PipelineOrchestrator::new().stream(repo_path, context);
+    while let Some(event) = events.recv().await {
+        println!("{}", serde_json::to_string(&event)?);
+    }
+    Ok(())
+}
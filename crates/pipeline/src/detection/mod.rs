@@ -1,3 +1,5 @@
 pub mod service;
+pub mod watch;
 
 pub use service::{DetectionService, ServiceError};
+pub use watch::{RepoWatcher, WatchConfig};
@@ -1,11 +1,15 @@
+use super::watch::{RepoWatcher, WatchConfig};
+use crate::pipeline::phases::scan::ScanConfig;
+use peelbox_core::fs::{FileSystem, RealFileSystem, RemoteFileSystem, RemoteRepository};
 use peelbox_core::output::schema::UniversalBuild;
 use peelbox_core::BackendError;
 use peelbox_llm::LLMClient;
+use std::future::Future;
 use std::path::{Path, PathBuf};
 use std::sync::Arc;
 use std::time::Instant;
 use thiserror::Error;
-use tracing::info;
+use tracing::{info, warn};
 
 #[derive(Debug, Error)]
 pub enum ServiceError {
@@ -26,6 +30,9 @@ pub enum ServiceError {
 
     #[error("Detection failed: {0}")]
     DetectionFailed(String),
+
+    #[error("Failed to watch repository: {0}")]
+    WatchFailed(String),
 }
 
 impl ServiceError {
@@ -182,12 +189,23 @@ impl ServiceError {
                     msg
                 )
             }
+            ServiceError::WatchFailed(msg) => {
+                format!(
+                    "Error: Cannot watch the repository for changes\n\n\
+                    Help: The file watcher could not be started. Try:\n\
+                    - Check the repository is readable\n\
+                    - Raise the inotify watch limit (fs.inotify.max_user_watches) on Linux\n\n\
+                    Details: {}",
+                    msg
+                )
+            }
         }
     }
 }
 
 pub struct DetectionService {
     client: Arc<dyn LLMClient>,
+    scan_config: ScanConfig,
 }
 
 impl std::fmt::Debug for DetectionService {
@@ -205,7 +223,20 @@ impl DetectionService {
             client.name()
         );
 
-        Self { client }
+        Self {
+            client,
+            scan_config: ScanConfig::default(),
+        }
+    }
+
+    /// Scans repositories with `scan_config` instead of the defaults
+    pub fn with_scan_config(mut self, scan_config: ScanConfig) -> Self {
+        self.scan_config = scan_config;
+        self
+    }
+
+    pub fn scan_config(&self) -> &ScanConfig {
+        &self.scan_config
     }
 
     pub async fn detect(&self, repo_path: PathBuf) -> Result<Vec<UniversalBuild>, ServiceError> {
@@ -218,9 +249,65 @@ impl DetectionService {
         repo_path: PathBuf,
         mode: peelbox_core::config::DetectionMode,
     ) -> Result<Vec<UniversalBuild>, ServiceError> {
-        let start = Instant::now();
-
         self.validate_repo_path(&repo_path)?;
+        self.run_detection(
+            repo_path,
+            Arc::new(RealFileSystem),
+            mode,
+            self.scan_config.clone(),
+        )
+        .await
+    }
+
+    /// Detects the repository held in `fs`, such as an archive, without writing it to disk
+    ///
+    /// The file system's root is the repository root, so the results' paths are relative to it.
+    pub async fn detect_file_system(
+        &self,
+        fs: Arc<dyn FileSystem>,
+    ) -> Result<Vec<UniversalBuild>, ServiceError> {
+        let mode = peelbox_core::config::DetectionMode::from_env();
+        self.run_detection(PathBuf::new(), fs, mode, self.scan_config.clone())
+            .await
+    }
+
+    /// Detects a GitHub or GitLab repository through the host's API, downloading only the
+    /// files detection reads
+    ///
+    /// `scan_config.auth_token`, else the host's token variable (`GITHUB_TOKEN` or
+    /// `GITLAB_TOKEN`), authenticates the requests; parse `repository` with
+    /// `scan_config.gitlab_hosts` so it only reaches hosts the operator configured. The
+    /// detection cache is skipped: keying it reads every file, which is the download a remote
+    /// scan exists to avoid.
+    pub async fn detect_remote(
+        &self,
+        repository: RemoteRepository,
+    ) -> Result<Vec<UniversalBuild>, ServiceError> {
+        let token = self
+            .scan_config
+            .auth_token
+            .clone()
+            .or_else(|| std::env::var(repository.host.token_env()).ok())
+            .filter(|token| !token.is_empty());
+        let fs = RemoteFileSystem::connect(repository, token.as_deref())
+            .map_err(|e| ServiceError::DetectionFailed(format!("{:#}", e)))?;
+        let mode = peelbox_core::config::DetectionMode::from_env();
+        let scan_config = ScanConfig {
+            cache_dir: None,
+            ..self.scan_config.clone()
+        };
+        self.run_detection(PathBuf::new(), Arc::new(fs), mode, scan_config)
+            .await
+    }
+
+    async fn run_detection(
+        &self,
+        repo_path: PathBuf,
+        fs: Arc<dyn FileSystem>,
+        mode: peelbox_core::config::DetectionMode,
+        scan_config: ScanConfig,
+    ) -> Result<Vec<UniversalBuild>, ServiceError> {
+        let start = Instant::now();
 
         info!(
             repo = %repo_path.display(),
@@ -248,11 +335,12 @@ impl DetectionService {
             Arc::new(StackRegistry::with_defaults(llm_client)),
             Arc::new(wolfi_index),
             mode,
-        );
+        )
+        .with_file_system(fs);
 
-        let orchestrator = PipelineOrchestrator::new();
+        let orchestrator = PipelineOrchestrator::with_scan_config(scan_config);
 
-        let results = orchestrator
+        let mut results = orchestrator
             .execute(&repo_path, &mut context)
             .await
             .map_err(|e| {
@@ -275,6 +363,9 @@ impl DetectionService {
                 }));
             }
         }
+        for build in &mut results {
+            build.validation_errors = crate::validation::validate_consistency(build);
+        }
 
         let elapsed = start.elapsed();
 
@@ -287,6 +378,47 @@ impl DetectionService {
         Ok(results)
     }
 
+    /// Re-runs detection whenever a manifest or configuration file under `repo_path` changes,
+    /// passing each new result to `on_change`, until `shutdown` completes
+    ///
+    /// Failed re-runs are logged and watching continues.
+    pub async fn watch<S, F>(
+        &self,
+        repo_path: PathBuf,
+        config: WatchConfig,
+        shutdown: S,
+        mut on_change: F,
+    ) -> Result<(), ServiceError>
+    where
+        S: Future<Output = ()>,
+        F: FnMut(Vec<UniversalBuild>),
+    {
+        self.validate_repo_path(&repo_path)?;
+        let mut watcher = RepoWatcher::new(&repo_path, &config)
+            .map_err(|e| ServiceError::WatchFailed(format!("{:#}", e)))?;
+        info!(repo = %repo_path.display(), "Watching repository for changes");
+
+        tokio::pin!(shutdown);
+        loop {
+            let changed = tokio::select! {
+                _ = &mut shutdown => break,
+                changed = watcher.next_change() => changed,
+            };
+            let Some(changed) = changed else {
+                break;
+            };
+            info!(files = ?changed, "Detection inputs changed, re-running detection");
+
+            match self.detect(repo_path.clone()).await {
+                Ok(results) => on_change(results),
+                Err(e) => warn!(error = %e, "Detection failed after change"),
+            }
+        }
+
+        info!("Stopped watching repository");
+        Ok(())
+    }
+
     fn validate_repo_path(&self, path: &Path) -> Result<(), ServiceError> {
         if !path.exists() {
             return Err(ServiceError::PathNotFound(path.to_path_buf()));
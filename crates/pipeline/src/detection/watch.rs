@@ -0,0 +1,212 @@
+//! Watches a repository and reports changes to the files detection reads
+
+use crate::extractors::nix::NIX_FILES;
+use crate::extractors::parsers::version_files::is_version_file;
+use crate::pipeline::phases::scan::{
+    exclude_overrides, is_manifest, manifest_patterns, ScanConfig,
+};
+use anyhow::{Context, Result};
+use ignore::overrides::Override;
+use notify::{Event, EventKind, RecommendedWatcher, RecursiveMode, Watcher};
+use peelbox_stack::buildsystem::bazel::BUILD_FILES;
+use peelbox_stack::StackRegistry;
+use std::path::{Path, PathBuf};
+use std::time::Duration;
+use tokio::sync::mpsc;
+use tracing::{debug, warn};
+
+/// Inputs besides the registered manifests that detection reads, named exactly
+const DETECTION_FILES: [&str; 2] = ["go.sum", "Dockerfile"];
+
+/// Extensions of configuration files and lockfiles; source files are left out
+const DETECTION_EXTENSIONS: [&str; 5] = ["toml", "json", "yaml", "yml", "lock"];
+
+#[derive(Debug, Clone)]
+pub struct WatchConfig {
+    /// Quiet period after the last change before detection re-runs
+    pub debounce: Duration,
+    /// Directories whose changes are ignored, as for the scan
+    pub scan: ScanConfig,
+}
+
+impl Default for WatchConfig {
+    fn default() -> Self {
+        Self {
+            debounce: Duration::from_millis(500),
+            scan: ScanConfig::default(),
+        }
+    }
+}
+
+/// Whether a change to `path` can alter the detection result: a manifest the registry
+/// detects (`manifests`, as from the scan), a Bazel package, version pin, Nix shell or
+/// configuration file
+pub fn is_detection_input(path: &Path, manifests: &[String]) -> bool {
+    let Some(name) = path.file_name().and_then(|n| n.to_str()) else {
+        return false;
+    };
+    is_manifest(path, manifests)
+        || DETECTION_FILES.contains(&name)
+        || BUILD_FILES.contains(&name)
+        || NIX_FILES.contains(&name)
+        || is_version_file(name)
+        || name.starts_with(".env")
+        || path
+            .extension()
+            .and_then(|ext| ext.to_str())
+            .is_some_and(|ext| DETECTION_EXTENSIONS.contains(&ext))
+}
+
+/// Recursive file-system watch over a repository, yielding debounced batches of changed
+/// detection inputs
+pub struct RepoWatcher {
+    repo_path: PathBuf,
+    debounce: Duration,
+    excludes: Override,
+    manifests: Vec<String>,
+    events: mpsc::UnboundedReceiver<notify::Result<Event>>,
+    // Dropping the watcher stops the notifications
+    _watcher: RecommendedWatcher,
+}
+
+impl RepoWatcher {
+    pub fn new(repo_path: &Path, config: &WatchConfig) -> Result<Self> {
+        let (tx, events) = mpsc::unbounded_channel();
+        let mut watcher = notify::recommended_watcher(move |event| {
+            let _ = tx.send(event);
+        })
+        .context("Failed to create file watcher")?;
+        watcher
+            .watch(repo_path, RecursiveMode::Recursive)
+            .with_context(|| format!("Failed to watch {}", repo_path.display()))?;
+
+        let registry = StackRegistry::with_defaults(None);
+        Ok(Self {
+            repo_path: repo_path.to_path_buf(),
+            debounce: config.debounce,
+            excludes: exclude_overrides(repo_path, &registry, &config.scan),
+            manifests: manifest_patterns(&registry),
+            events,
+            _watcher: watcher,
+        })
+    }
+
+    /// Waits for a change to a detection input, then for the debounce period to pass without
+    /// further events, and returns the changed paths relative to the repository
+    ///
+    /// Returns `None` once the watcher has shut down.
+    pub async fn next_change(&mut self) -> Option<Vec<PathBuf>> {
+        let mut changed = Vec::new();
+        while changed.is_empty() {
+            let event = self.events.recv().await?;
+            self.collect(event, &mut changed);
+        }
+
+        loop {
+            match tokio::time::timeout(self.debounce, self.events.recv()).await {
+                Ok(Some(event)) => self.collect(event, &mut changed),
+                Ok(None) | Err(_) => break,
+            }
+        }
+        changed.sort();
+        Some(changed)
+    }
+
+    fn collect(&self, event: notify::Result<Event>, changed: &mut Vec<PathBuf>) {
+        let event = match event {
+            Ok(event) => event,
+            Err(err) => {
+                warn!(error = %err, "File watcher error");
+                return;
+            }
+        };
+        if matches!(event.kind, EventKind::Access(_)) {
+            return;
+        }
+
+        for path in event.paths {
+            let relative = path.strip_prefix(&self.repo_path).unwrap_or(&path);
+            if !is_detection_input(relative, &self.manifests) || self.is_excluded(relative) {
+                continue;
+            }
+            debug!(path = %relative.display(), "Detection input changed");
+            if !changed.iter().any(|p| p == relative) {
+                changed.push(relative.to_path_buf());
+            }
+        }
+    }
+
+    fn is_excluded(&self, relative: &Path) -> bool {
+        relative
+            .ancestors()
+            .skip(1)
+            .filter(|dir| !dir.as_os_str().is_empty())
+            .any(|dir| self.excludes.matched(dir, true).is_ignore())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::fs;
+
+    #[test]
+    fn test_detection_inputs() {
+        let manifests = manifest_patterns(&StackRegistry::with_defaults(None));
+        for path in [
+            "go.mod",
+            "api/go.sum",
+            "package.json",
+            "app/.env",
+            "config.yml",
+            "src/Api/Api.csproj",
+            "Package.swift",
+            "mix.exs",
+            "flake.nix",
+            "shell.nix",
+            "BUILD.bazel",
+            "WORKSPACE",
+            "setup.py",
+            ".tool-versions",
+            ".python-version",
+            "web/.node-version",
+        ] {
+            assert!(is_detection_input(Path::new(path), &manifests), "{}", path);
+        }
+        for path in ["main.go", "src/lib.rs", "README.md"] {
+            assert!(!is_detection_input(Path::new(path), &manifests), "{}", path);
+        }
+    }
+
+    #[tokio::test]
+    async fn test_reports_go_mod_change() {
+        let dir = tempfile::tempdir().unwrap();
+        let repo = dir.path().canonicalize().unwrap();
+        fs::write(repo.join("go.mod"), "module example.com/app\n\ngo 1.21\n").unwrap();
+        fs::write(repo.join("main.go"), "package main\n").unwrap();
+        fs::create_dir_all(repo.join("vendor/example.com/lib")).unwrap();
+
+        let config = WatchConfig {
+            debounce: Duration::from_millis(100),
+            ..WatchConfig::default()
+        };
+        let mut watcher = RepoWatcher::new(&repo, &config).unwrap();
+
+        // Source and vendored changes alone do not trigger detection
+        fs::write(repo.join("main.go"), "package main\n\nfunc main() {}\n").unwrap();
+        fs::write(repo.join("vendor/example.com/lib/go.mod"), "module lib\n").unwrap();
+        let quiet = tokio::time::timeout(Duration::from_millis(500), watcher.next_change()).await;
+        assert!(quiet.is_err(), "unexpected change: {:?}", quiet);
+
+        fs::write(
+            repo.join("go.mod"),
+            "module example.com/app\n\ngo 1.22\n\nrequire github.com/gin-gonic/gin v1.9.1\n",
+        )
+        .unwrap();
+        let changed = tokio::time::timeout(Duration::from_secs(5), watcher.next_change())
+            .await
+            .expect("go.mod change was not reported")
+            .unwrap();
+        assert_eq!(changed, vec![PathBuf::from("go.mod")]);
+    }
+}
@@ -0,0 +1,266 @@
+//! Backing service detector - databases, caches and workflow servers a service needs, from
+//! several signals
+
+use crate::extractors::parsers::docker_compose::backing_service_kind;
+use peelbox_core::output::schema::BackingService;
+use std::collections::BTreeMap;
+
+/// Evidence kinds, in the order they are reported
+const IMPORT: &str = "import";
+const ENV_VAR: &str = "env_var";
+const COMPOSE: &str = "compose";
+
+/// Client libraries by service, matched as prefixes of dependency names
+const CLIENT_LIBRARIES: &[(&str, &[&str])] = &[
+    (
+        "postgresql",
+        &[
+            "github.com/lib/pq",
+            "github.com/jackc/pgx",
+            "pg",
+            "postgres",
+            "psycopg",
+            "asyncpg",
+        ],
+    ),
+    (
+        "mysql",
+        &[
+            "github.com/go-sql-driver/mysql",
+            "mysql2",
+            "mysqlclient",
+            "pymysql",
+        ],
+    ),
+    (
+        "redis",
+        &[
+            "github.com/redis/go-redis",
+            "github.com/go-redis/redis",
+            "github.com/gomodule/redigo",
+            "redis",
+            "ioredis",
+        ],
+    ),
+    (
+        "mongodb",
+        &[
+            "go.mongodb.org/mongo-driver",
+            "mongodb",
+            "mongoose",
+            "pymongo",
+        ],
+    ),
+    ("temporal", &["go.temporal.io/sdk", "temporalio"]),
+];
+
+/// Environment variable name prefixes by service
+const ENV_PREFIXES: &[(&str, &[&str])] = &[
+    ("postgresql", &["POSTGRES_", "POSTGRESQL_", "PG_"]),
+    ("mysql", &["MYSQL_"]),
+    ("redis", &["REDIS_"]),
+    ("mongodb", &["MONGO_", "MONGODB_"]),
+    ("temporal", &["TEMPORAL_"]),
+];
+
+/// libpq's own variables
+const LIBPQ_ENV_VARS: &[&str] = &["PGHOST", "PGPORT", "PGUSER", "PGPASSWORD", "PGDATABASE"];
+
+pub struct BackingServiceDetector;
+
+impl BackingServiceDetector {
+    /// Combines dependency names, environment variable names and Compose images
+    ///
+    /// `DATABASE_URL` names no database, so it backs whichever SQL database another signal
+    /// found, and PostgreSQL (the usual convention) when none did. Confidence grows with the
+    /// number of independent signals.
+    pub fn detect(
+        dependencies: &[String],
+        env_vars: &[String],
+        compose_images: &[String],
+    ) -> Vec<BackingService> {
+        let mut evidence: BTreeMap<&'static str, Vec<&'static str>> = BTreeMap::new();
+
+        for dependency in dependencies {
+            if let Some(service) = library_service(dependency) {
+                add_signal(&mut evidence, service, IMPORT);
+            }
+        }
+        for image in compose_images {
+            if let Some(kind) = backing_service_kind(image) {
+                add_signal(&mut evidence, compose_kind_service(kind), COMPOSE);
+            }
+        }
+        for name in env_vars {
+            if let Some(service) = env_var_service(name) {
+                add_signal(&mut evidence, service, ENV_VAR);
+            }
+        }
+
+        if env_vars.iter().any(|name| name == "DATABASE_URL") {
+            let sql: Vec<&'static str> = ["postgresql", "mysql"]
+                .into_iter()
+                .filter(|service| evidence.contains_key(service))
+                .collect();
+            if sql.is_empty() {
+                add_signal(&mut evidence, "postgresql", ENV_VAR);
+            }
+            for service in sql {
+                add_signal(&mut evidence, service, ENV_VAR);
+            }
+        }
+
+        evidence
+            .into_iter()
+            .map(|(name, mut signals)| {
+                signals.sort_by_key(|signal| {
+                    [IMPORT, ENV_VAR, COMPOSE].iter().position(|s| s == signal)
+                });
+                BackingService {
+                    name: name.to_string(),
+                    confidence: confidence(signals.len()),
+                    detected_via: signals.into_iter().map(String::from).collect(),
+                }
+            })
+            .collect()
+    }
+}
+
+fn add_signal(
+    evidence: &mut BTreeMap<&'static str, Vec<&'static str>>,
+    service: &'static str,
+    signal: &'static str,
+) {
+    let signals = evidence.entry(service).or_default();
+    if !signals.contains(&signal) {
+        signals.push(signal);
+    }
+}
+
+fn library_service(dependency: &str) -> Option<&'static str> {
+    CLIENT_LIBRARIES.iter().find_map(|(service, libraries)| {
+        libraries
+            .iter()
+            .any(|lib| {
+                dependency == *lib
+                    || dependency
+                        .strip_prefix(lib)
+                        .is_some_and(|rest| rest.starts_with(['/', '-']))
+            })
+            .then_some(*service)
+    })
+}
+
+fn env_var_service(name: &str) -> Option<&'static str> {
+    if LIBPQ_ENV_VARS.contains(&name) {
+        return Some("postgresql");
+    }
+    ENV_PREFIXES.iter().find_map(|(service, prefixes)| {
+        prefixes
+            .iter()
+            .any(|prefix| name.starts_with(prefix))
+            .then_some(*service)
+    })
+}
+
+/// Compose kinds use the image name ("postgres"); backing services use the product name
+fn compose_kind_service(kind: &'static str) -> &'static str {
+    match kind {
+        "postgres" => "postgresql",
+        other => other,
+    }
+}
+
+fn confidence(signals: usize) -> f64 {
+    match signals {
+        0 | 1 => 0.6,
+        2 => 0.85,
+        _ => 0.95,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn strings(values: &[&str]) -> Vec<String> {
+        values.iter().map(|v| v.to_string()).collect()
+    }
+
+    #[test]
+    fn test_single_signal() {
+        let services = BackingServiceDetector::detect(&strings(&["github.com/lib/pq"]), &[], &[]);
+
+        assert_eq!(services.len(), 1);
+        assert_eq!(services[0].name, "postgresql");
+        assert_eq!(services[0].detected_via, vec!["import"]);
+        assert_eq!(services[0].confidence, 0.6);
+    }
+
+    #[test]
+    fn test_corroborating_signals_raise_confidence() {
+        let services = BackingServiceDetector::detect(
+            &strings(&["github.com/redis/go-redis/v9", "github.com/gin-gonic/gin"]),
+            &strings(&["REDIS_URL", "PORT"]),
+            &strings(&["redis:7-alpine"]),
+        );
+
+        assert_eq!(services.len(), 1);
+        assert_eq!(services[0].name, "redis");
+        assert_eq!(
+            services[0].detected_via,
+            vec!["import", "env_var", "compose"]
+        );
+        assert!(services[0].confidence > 0.9);
+
+        let two = BackingServiceDetector::detect(
+            &strings(&["go.mongodb.org/mongo-driver"]),
+            &strings(&["MONGO_URI"]),
+            &[],
+        );
+        assert_eq!(two[0].name, "mongodb");
+        assert_eq!(two[0].detected_via, vec!["import", "env_var"]);
+        assert_eq!(two[0].confidence, 0.85);
+    }
+
+    #[test]
+    fn test_database_url_backs_detected_sql_database() {
+        let mysql = BackingServiceDetector::detect(
+            &strings(&["github.com/go-sql-driver/mysql"]),
+            &strings(&["DATABASE_URL"]),
+            &[],
+        );
+        assert_eq!(mysql.len(), 1);
+        assert_eq!(mysql[0].name, "mysql");
+        assert_eq!(mysql[0].detected_via, vec!["import", "env_var"]);
+
+        let alone = BackingServiceDetector::detect(&[], &strings(&["DATABASE_URL"]), &[]);
+        assert_eq!(alone[0].name, "postgresql");
+        assert_eq!(alone[0].detected_via, vec!["env_var"]);
+    }
+
+    #[test]
+    fn test_unrelated_names_are_ignored() {
+        let services = BackingServiceDetector::detect(
+            &strings(&["pgx-helpers-fake", "redisson", "github.com/lib/pqueue"]),
+            &strings(&["PGP_KEY", "REDISTRIBUTE", "MONGOOSE_DEBUG", "PAGE_SIZE"]),
+            &strings(&["nginx:latest"]),
+        );
+        assert!(services.is_empty());
+    }
+
+    #[test]
+    fn test_temporal_server() {
+        let services = BackingServiceDetector::detect(
+            &strings(&["go.temporal.io/sdk", "go.temporal.io/api"]),
+            &strings(&["TEMPORAL_ADDRESS"]),
+            &strings(&["temporalio/auto-setup:1.24"]),
+        );
+        assert_eq!(services.len(), 1);
+        assert_eq!(services[0].name, "temporal");
+        assert_eq!(
+            services[0].detected_via,
+            vec!["import", "env_var", "compose"]
+        );
+    }
+}
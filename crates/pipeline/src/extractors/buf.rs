@@ -0,0 +1,282 @@
+//! Buf detector - protobuf modules built, linted and generated with the buf CLI
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::{BufMetadata, RequiredTool};
+use serde_yaml::Value;
+use std::path::{Path, PathBuf};
+
+/// Runs every plugin of buf.gen.yaml over the module's protos
+pub const BUF_GENERATE: &str = "buf generate";
+
+/// Reported as `proto_toolchain` when buf generates a gRPC service's stubs
+pub const BUF_TOOLCHAIN: &str = "buf";
+
+/// BSR remote plugins by the protoc plugin they run, matched without the `:version` suffix
+const REMOTE_PLUGINS: [(&str, &str); 4] = [
+    ("buf.build/protocolbuffers/go", "protoc-gen-go"),
+    ("buf.build/grpc/go", "protoc-gen-go-grpc"),
+    ("buf.build/connectrpc/go", "protoc-gen-connect-go"),
+    (
+        "buf.build/grpc-ecosystem/gateway",
+        "protoc-gen-grpc-gateway",
+    ),
+];
+
+/// Local plugins installable with `go install`
+const GO_PLUGINS: [(&str, &str); 4] = [
+    (
+        "protoc-gen-go",
+        "google.golang.org/protobuf/cmd/protoc-gen-go",
+    ),
+    (
+        "protoc-gen-go-grpc",
+        "google.golang.org/grpc/cmd/protoc-gen-go-grpc",
+    ),
+    (
+        "protoc-gen-connect-go",
+        "connectrpc.com/connect/cmd/protoc-gen-connect-go",
+    ),
+    (
+        "protoc-gen-grpc-gateway",
+        "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-grpc-gateway",
+    ),
+];
+
+pub struct BufDetector;
+
+impl BufDetector {
+    /// Reads `buf.yaml`, `buf.gen.yaml` and `buf.lock` from the service directory, else the
+    /// repository root, among `file_tree` (repository-relative)
+    ///
+    /// Plugins are reported by their protoc plugin name (`protoc-gen-go`); remote plugins
+    /// outside the well-known ones keep their BSR reference. Both v1 and v2 files are read.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<BufMetadata> {
+        let find = |name: &str| {
+            [service_path, Path::new("")]
+                .into_iter()
+                .map(|dir| dir.join(name))
+                .find(|path| file_tree.contains(path))
+        };
+        let read = |path: &Path| {
+            fs.read_to_string(&repo_path.join(path))
+                .ok()
+                .and_then(|content| serde_yaml::from_str::<Value>(&content).ok())
+        };
+        let display = |path: &Path| path.to_string_lossy().replace('\\', "/");
+
+        let config = find("buf.yaml");
+        let template = find("buf.gen.yaml");
+        if config.is_none() && template.is_none() {
+            return None;
+        }
+
+        let mut plugins = Vec::new();
+        let mut local_plugins = Vec::new();
+        if let Some(template) = template.as_deref().and_then(read) {
+            for (name, local) in template["plugins"]
+                .as_sequence()
+                .into_iter()
+                .flatten()
+                .filter_map(plugin)
+            {
+                if local {
+                    local_plugins.push(name.clone());
+                }
+                plugins.push(name);
+            }
+        }
+        // buf.lock sits next to the buf.yaml it pins
+        let lock = config
+            .as_deref()
+            .and_then(Path::parent)
+            .map(|dir| dir.join("buf.lock"))
+            .filter(|path| file_tree.contains(path));
+        let dependencies = lock
+            .as_deref()
+            .and_then(read)
+            .map(|lock| {
+                lock["deps"]
+                    .as_sequence()
+                    .into_iter()
+                    .flatten()
+                    .filter_map(lock_dependency)
+                    .collect()
+            })
+            .unwrap_or_default();
+
+        Some(BufMetadata {
+            config_file: config.as_deref().map(display),
+            template_file: template.as_deref().map(display),
+            plugins,
+            local_plugins,
+            dependencies,
+        })
+    }
+
+    /// `buf generate` needs a generation template
+    pub fn generate_command(buf: &BufMetadata) -> Option<&'static str> {
+        buf.template_file.is_some().then_some(BUF_GENERATE)
+    }
+
+    /// The buf CLI, then the local plugins it runs that `go install` provides
+    pub fn required_tools(buf: &BufMetadata) -> Vec<RequiredTool> {
+        let mut tools = vec![RequiredTool {
+            name: "buf".to_string(),
+            version_hint: None,
+            install_command: Some("go install github.com/bufbuild/buf/cmd/buf@latest".to_string()),
+        }];
+        tools.extend(buf.local_plugins.iter().filter_map(|plugin| {
+            GO_PLUGINS
+                .iter()
+                .find(|(name, _)| *name == plugin.as_str())
+                .map(|(name, module)| RequiredTool {
+                    name: name.to_string(),
+                    version_hint: None,
+                    install_command: Some(format!("go install {}@latest", module)),
+                })
+        }));
+        tools
+    }
+}
+
+/// A buf.gen.yaml plugin entry's name and whether it runs locally, from PATH, rather than
+/// on the BSR
+///
+/// v1 names a plugin with `plugin`/`name` (`go` for protoc-gen-go, or a remote reference)
+/// or `remote`; v2 with `local` (a binary or an argv) or `remote`. Plugins built into
+/// protoc (`protoc_builtin`) are left out.
+fn plugin(entry: &Value) -> Option<(String, bool)> {
+    if let Some(local) = entry["local"].as_str().or_else(|| {
+        entry["local"]
+            .as_sequence()
+            .and_then(|argv| argv.first())
+            .and_then(Value::as_str)
+    }) {
+        return Some((local.to_string(), true));
+    }
+    let reference = entry["remote"]
+        .as_str()
+        .or_else(|| entry["plugin"].as_str())
+        .or_else(|| entry["name"].as_str())?;
+    if !reference.contains('/') {
+        return Some((format!("protoc-gen-{}", reference), true));
+    }
+    let remote = reference.split(':').next().unwrap_or(reference);
+    let name = REMOTE_PLUGINS
+        .iter()
+        .find(|(plugin, _)| *plugin == remote)
+        .map_or(remote, |(_, name)| name);
+    Some((name.to_string(), false))
+}
+
+/// `buf.build/googleapis/googleapis` from a v2 `name`, or v1 `remote`/`owner`/`repository`
+fn lock_dependency(entry: &Value) -> Option<String> {
+    if let Some(name) = entry["name"].as_str() {
+        return Some(name.to_string());
+    }
+    Some(format!(
+        "{}/{}/{}",
+        entry["remote"].as_str()?,
+        entry["owner"].as_str()?,
+        entry["repository"].as_str()?
+    ))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn detect(files: &[(&str, &str)], service_path: &str) -> Option<BufMetadata> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        BufDetector::detect(Path::new(""), Path::new(service_path), &tree, &fs)
+    }
+
+    #[test]
+    fn test_v2_config_with_lock() {
+        let buf = detect(
+            &[
+                (
+                    "buf.yaml",
+                    "version: v2\nmodules:\n  - path: proto\ndeps:\n  - buf.build/googleapis/googleapis\n",
+                ),
+                (
+                    "buf.gen.yaml",
+                    "version: v2\nplugins:\n  - remote: buf.build/protocolbuffers/go:v1.34.2\n    out: gen\n    opt: paths=source_relative\n  - local: protoc-gen-go-grpc\n    out: gen\n  - remote: buf.build/connectrpc/go\n    out: gen\n",
+                ),
+                (
+                    "buf.lock",
+                    "# Generated by buf. DO NOT EDIT.\nversion: v2\ndeps:\n  - name: buf.build/googleapis/googleapis\n    commit: 62f35d8aed1149c291d606d958a7ce32\n    digest: b5:d66bf04adc77a0870bdc9328aaf887c7188a36fb02b83a480dc45ef9dc031b4d\n",
+                ),
+                ("proto/greeter/v1/greeter.proto", "syntax = \"proto3\";\n"),
+            ],
+            "",
+        )
+        .unwrap();
+
+        assert_eq!(
+            buf,
+            BufMetadata {
+                config_file: Some("buf.yaml".to_string()),
+                template_file: Some("buf.gen.yaml".to_string()),
+                plugins: vec![
+                    "protoc-gen-go".to_string(),
+                    "protoc-gen-go-grpc".to_string(),
+                    "protoc-gen-connect-go".to_string(),
+                ],
+                local_plugins: vec!["protoc-gen-go-grpc".to_string()],
+                dependencies: vec!["buf.build/googleapis/googleapis".to_string()],
+            }
+        );
+        assert_eq!(BufDetector::generate_command(&buf), Some(BUF_GENERATE));
+        let tools: Vec<String> = BufDetector::required_tools(&buf)
+            .into_iter()
+            .map(|tool| tool.name)
+            .collect();
+        assert_eq!(tools, vec!["buf", "protoc-gen-go-grpc"]);
+    }
+
+    #[test]
+    fn test_v1_files_in_service() {
+        let buf = detect(
+            &[
+                ("api/buf.yaml", "version: v1\nlint:\n  use:\n    - DEFAULT\n"),
+                (
+                    "api/buf.gen.yaml",
+                    "version: v1\nplugins:\n  - plugin: go\n    out: gen\n  - plugin: buf.build/grpc/go:v1.4.0\n    out: gen\n",
+                ),
+                (
+                    "api/buf.lock",
+                    "version: v1\ndeps:\n  - remote: buf.build\n    owner: envoyproxy\n    repository: protoc-gen-validate\n    commit: 71881f09a0c5420a9545a07987a86728\n",
+                ),
+            ],
+            "api",
+        )
+        .unwrap();
+
+        assert_eq!(buf.config_file.as_deref(), Some("api/buf.yaml"));
+        assert_eq!(buf.plugins, vec!["protoc-gen-go", "protoc-gen-go-grpc"]);
+        assert_eq!(buf.local_plugins, vec!["protoc-gen-go"]);
+        assert_eq!(
+            buf.dependencies,
+            vec!["buf.build/envoyproxy/protoc-gen-validate"]
+        );
+    }
+
+    #[test]
+    fn test_lint_only_module() {
+        let buf = detect(&[("buf.yaml", "version: v2\n")], "").unwrap();
+        assert_eq!(buf.template_file, None);
+        assert_eq!(BufDetector::generate_command(&buf), None);
+        assert_eq!(detect(&[("proto/a.proto", "")], ""), None);
+    }
+}
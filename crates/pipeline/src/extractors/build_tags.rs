@@ -0,0 +1,319 @@
+//! Build tag detector - GOOS/GOARCH constraints from `//go:build` lines and file names
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::BuildConstraints;
+use std::collections::BTreeSet;
+use std::path::{Path, PathBuf};
+
+const GOOS: [&str; 18] = [
+    "aix",
+    "android",
+    "darwin",
+    "dragonfly",
+    "freebsd",
+    "hurd",
+    "illumos",
+    "ios",
+    "js",
+    "linux",
+    "netbsd",
+    "openbsd",
+    "plan9",
+    "solaris",
+    "unix",
+    "wasip1",
+    "windows",
+    "zos",
+];
+
+const GOARCH: [&str; 15] = [
+    "386", "amd64", "arm", "arm64", "loong64", "mips", "mipsle", "mips64", "mips64le", "ppc64",
+    "ppc64le", "riscv64", "s390x", "sparc64", "wasm",
+];
+
+pub struct BuildTagDetector;
+
+impl BuildTagDetector {
+    /// Collects the constraints of every `*.go` file of the service among `file_tree`
+    /// (repository-relative)
+    ///
+    /// A `//go:build` line takes precedence over legacy `// +build` lines in the same file, as
+    /// it does for the go command. `_GOOS`/`_GOARCH` file name suffixes count as targeting.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<BuildConstraints> {
+        let mut expressions = BTreeSet::new();
+        let mut targeted = BTreeSet::new();
+        let mut excluded = BTreeSet::new();
+
+        for relative in file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| path.extension().is_some_and(|ext| ext == "go"))
+        {
+            if let Some(name) = relative.file_stem().and_then(|n| n.to_str()) {
+                targeted.extend(file_name_constraints(name));
+            }
+
+            let Ok(content) = fs.read_to_string(&repo_path.join(service_path).join(relative))
+            else {
+                continue;
+            };
+            let (go_build, plus_build) = constraint_lines(&content);
+            let terms = match go_build {
+                Some(expression) => {
+                    expressions.insert(expression.to_string());
+                    go_build_terms(expression)
+                }
+                None if !plus_build.is_empty() => {
+                    expressions.insert(plus_build.join(" && "));
+                    plus_build
+                        .iter()
+                        .flat_map(|line| plus_build_terms(line))
+                        .collect()
+                }
+                None => continue,
+            };
+            for (term, negated) in terms {
+                if negated {
+                    excluded.insert(term);
+                } else {
+                    targeted.insert(term);
+                }
+            }
+        }
+
+        if expressions.is_empty() && targeted.is_empty() {
+            return None;
+        }
+
+        let select = |terms: &BTreeSet<String>, known: &[&str]| -> Vec<String> {
+            terms
+                .iter()
+                .filter(|term| known.contains(&term.as_str()))
+                .cloned()
+                .collect()
+        };
+        Some(BuildConstraints {
+            os: select(&targeted, &GOOS),
+            arch: select(&targeted, &GOARCH),
+            excluded_os: select(&excluded, &GOOS),
+            excluded_arch: select(&excluded, &GOARCH),
+            expressions: expressions.into_iter().collect(),
+        })
+    }
+
+    /// Linux is the only operating system any file targets
+    pub fn linux_only(constraints: &BuildConstraints) -> bool {
+        constraints.os == ["linux"]
+    }
+}
+
+/// The `//go:build` expression and `// +build` lines of the header before `package`
+fn constraint_lines(content: &str) -> (Option<&str>, Vec<&str>) {
+    let mut go_build = None;
+    let mut plus_build = Vec::new();
+    for line in content.lines().map(str::trim) {
+        if line.starts_with("package ") {
+            break;
+        }
+        if let Some(expression) = line.strip_prefix("//go:build ") {
+            go_build.get_or_insert(expression.trim());
+        } else if let Some(options) = line
+            .strip_prefix("//")
+            .map(str::trim_start)
+            .and_then(|rest| rest.strip_prefix("+build "))
+        {
+            plus_build.push(options.trim());
+        }
+    }
+    (go_build, plus_build)
+}
+
+/// Tags of a `//go:build` expression, each with whether it sits under an odd number of `!`
+fn go_build_terms(expression: &str) -> Vec<(String, bool)> {
+    let mut terms = Vec::new();
+    // Negation of each open parenthesis group, outermost first
+    let mut groups = vec![false];
+    let mut pending = false;
+    let mut chars = expression.chars().peekable();
+
+    while let Some(c) = chars.next() {
+        let negated = *groups.last().unwrap_or(&false) != pending;
+        match c {
+            '!' => pending = !pending,
+            '(' => {
+                groups.push(negated);
+                pending = false;
+            }
+            ')' => {
+                groups.pop();
+                pending = false;
+            }
+            c if c.is_alphanumeric() || c == '_' || c == '.' => {
+                let mut tag = c.to_string();
+                while let Some(&next) = chars.peek() {
+                    if !(next.is_alphanumeric() || next == '_' || next == '.') {
+                        break;
+                    }
+                    tag.push(next);
+                    chars.next();
+                }
+                terms.push((tag, negated));
+                pending = false;
+            }
+            _ => {}
+        }
+    }
+    terms
+}
+
+/// Tags of a `// +build` line: spaces separate alternatives, commas conjunctions
+fn plus_build_terms(options: &str) -> Vec<(String, bool)> {
+    options
+        .split(|c: char| c.is_whitespace() || c == ',')
+        .filter(|term| !term.is_empty())
+        .map(|term| match term.strip_prefix('!') {
+            Some(tag) => (tag.to_string(), true),
+            None => (term.to_string(), false),
+        })
+        .collect()
+}
+
+/// GOOS and GOARCH implied by a `name_GOOS_GOARCH`, `name_GOOS` or `name_GOARCH` file stem
+fn file_name_constraints(stem: &str) -> Vec<String> {
+    let stem = stem.strip_suffix("_test").unwrap_or(stem);
+    let parts: Vec<&str> = stem.split('_').collect();
+    let n = parts.len();
+
+    if n >= 3 && GOOS.contains(&parts[n - 2]) && GOARCH.contains(&parts[n - 1]) {
+        return vec![parts[n - 2].to_string(), parts[n - 1].to_string()];
+    }
+    // "unix" is only a build tag, not a file name suffix
+    if n >= 2
+        && parts[n - 1] != "unix"
+        && (GOOS.contains(&parts[n - 1]) || GOARCH.contains(&parts[n - 1]))
+    {
+        return vec![parts[n - 1].to_string()];
+    }
+    vec![]
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    #[test]
+    fn test_linux_only_service() {
+        let fs = MockFileSystem::new();
+        fs.add_file("main.go", "package main\n\nfunc main() {}\n");
+        fs.add_file(
+            "poll.go",
+            "//go:build linux && (amd64 || arm64)\n\npackage main\n",
+        );
+        fs.add_file(
+            "signals.go",
+            "// Copyright 2024 Example\n\n//go:build !windows\n// +build !windows\n\npackage main\n",
+        );
+        fs.add_file("sys_linux.go", "package main\n");
+
+        let constraints = BuildTagDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["main.go", "poll.go", "signals.go", "sys_linux.go"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(
+            constraints,
+            BuildConstraints {
+                os: vec!["linux".to_string()],
+                arch: vec!["amd64".to_string(), "arm64".to_string()],
+                excluded_os: vec!["windows".to_string()],
+                excluded_arch: vec![],
+                expressions: vec![
+                    "!windows".to_string(),
+                    "linux && (amd64 || arm64)".to_string(),
+                ],
+            }
+        );
+        assert!(BuildTagDetector::linux_only(&constraints));
+    }
+
+    #[test]
+    fn test_legacy_plus_build() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "svc/term.go",
+            "// +build darwin freebsd\n// +build amd64,!386\n\npackage svc\n",
+        );
+        let constraints = BuildTagDetector::detect(
+            Path::new(""),
+            Path::new("svc"),
+            &paths(&["svc/term.go"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(constraints.os, vec!["darwin", "freebsd"]);
+        assert_eq!(constraints.arch, vec!["amd64"]);
+        assert_eq!(constraints.excluded_arch, vec!["386"]);
+        assert_eq!(
+            constraints.expressions,
+            vec!["darwin freebsd && amd64,!386"]
+        );
+        assert!(!BuildTagDetector::linux_only(&constraints));
+    }
+
+    #[test]
+    fn test_negated_group() {
+        assert_eq!(
+            go_build_terms("!(darwin || windows) && !cgo"),
+            vec![
+                ("darwin".to_string(), true),
+                ("windows".to_string(), true),
+                ("cgo".to_string(), true),
+            ]
+        );
+        assert_eq!(
+            go_build_terms("!(!linux)"),
+            vec![("linux".to_string(), false)]
+        );
+    }
+
+    #[test]
+    fn test_file_name_constraints() {
+        assert_eq!(
+            file_name_constraints("zsys_linux_arm64"),
+            vec!["linux", "arm64"]
+        );
+        assert_eq!(file_name_constraints("exec_windows_test"), vec!["windows"]);
+        assert_eq!(file_name_constraints("asm_amd64"), vec!["amd64"]);
+        assert!(file_name_constraints("linux").is_empty());
+        assert!(file_name_constraints("fd_unix").is_empty());
+        assert!(file_name_constraints("handler").is_empty());
+    }
+
+    #[test]
+    fn test_unconstrained_service() {
+        let fs = MockFileSystem::new();
+        fs.add_file("main.go", "package main\n");
+        fs.add_file("main_test.go", "package main\n\n//go:build linux\n");
+        assert_eq!(
+            BuildTagDetector::detect(
+                Path::new(""),
+                Path::new(""),
+                &paths(&["main.go", "main_test.go"]),
+                &fs
+            ),
+            None
+        );
+    }
+}
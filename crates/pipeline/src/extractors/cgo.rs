@@ -0,0 +1,161 @@
+//! cgo detector - Go packages that call into C and need a C toolchain to build
+
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+/// Build tag hint recorded for cgo builds
+pub const CGO_BUILD_FLAG: &str = "-tags cgo";
+
+/// Go files importing the pseudo-package "C" and whether cgo is switched off on purpose
+#[derive(Debug, Clone, PartialEq, Default)]
+pub struct CgoUsage {
+    /// Non-test `.go` files with `import "C"`, relative to the service
+    pub files: Vec<String>,
+    /// `CGO_ENABLED=0` is set in a `.env` file or shell script
+    pub disabled: bool,
+}
+
+impl CgoUsage {
+    /// cgo is used and not disabled, so the build needs `CGO_ENABLED=1` and a C compiler
+    pub fn required(&self) -> bool {
+        !self.files.is_empty() && !self.disabled
+    }
+}
+
+pub struct CgoDetector;
+
+impl CgoDetector {
+    /// Scans the service's Go files among `file_tree` (repository-relative) for `import "C"`
+    /// and its `.env` files and shell scripts for `CGO_ENABLED=0`
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<CgoUsage> {
+        // `import "C"` must be its own declaration, though it may be parenthesized
+        let import_c = Regex::new(r#"(?m)^import\s*(?:\(\s*)?"C""#).expect("valid import regex");
+        let disabled_re =
+            Regex::new(r#"\bCGO_ENABLED\s*=\s*["']?0\b"#).expect("valid CGO_ENABLED regex");
+
+        let read = |relative: &Path| {
+            fs.read_to_string(&repo_path.join(service_path).join(relative))
+                .ok()
+        };
+        let service_files: Vec<&Path> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .collect();
+
+        let files: Vec<String> = service_files
+            .iter()
+            .copied()
+            .filter(|path| {
+                file_name(path)
+                    .is_some_and(|name| name.ends_with(".go") && !name.ends_with("_test.go"))
+            })
+            .filter(|path| read(*path).is_some_and(|content| import_c.is_match(&content)))
+            .map(|path| path.to_string_lossy().replace('\\', "/"))
+            .collect();
+        if files.is_empty() {
+            return None;
+        }
+
+        let disabled = service_files
+            .iter()
+            .copied()
+            .filter(|path| file_name(path).is_some_and(is_env_or_shell_file))
+            .any(|path| read(path).is_some_and(|content| disabled_re.is_match(&content)));
+
+        Some(CgoUsage { files, disabled })
+    }
+}
+
+fn file_name(path: &Path) -> Option<&str> {
+    path.file_name().and_then(|name| name.to_str())
+}
+
+/// `.env`, `.env.production`, `.envrc`, shell profiles and `*.sh` scripts
+fn is_env_or_shell_file(name: &str) -> bool {
+    name == ".env"
+        || name.starts_with(".env.")
+        || name == ".envrc"
+        || matches!(name, ".bashrc" | ".zshrc" | ".profile")
+        || name.ends_with(".sh")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const CGO_MAIN: &str = r#"package main
+
+/*
+#include <stdlib.h>
+static int add(int a, int b) { return a + b; }
+*/
+import "C"
+
+import "fmt"
+
+func main() {
+	fmt.Println(C.add(1, 2))
+}
+"#;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    #[test]
+    fn test_detects_import_c() {
+        let fs = MockFileSystem::new();
+        fs.add_file("/repo/main.go", CGO_MAIN);
+        fs.add_file("/repo/util.go", "package main\n\nimport \"strings\"\n");
+        fs.add_file("/repo/main_test.go", "package main\n\nimport \"C\"\n");
+
+        let usage = CgoDetector::detect(
+            Path::new("/repo"),
+            Path::new(""),
+            &paths(&["main.go", "util.go", "main_test.go"]),
+            &fs,
+        )
+        .unwrap();
+
+        assert_eq!(usage.files, vec!["main.go"]);
+        assert!(usage.required());
+    }
+
+    #[test]
+    fn test_cgo_disabled_in_env_file() {
+        let fs = MockFileSystem::new();
+        fs.add_file("/repo/api/sqlite.go", CGO_MAIN);
+        fs.add_file("/repo/api/.env", "PORT=8080\nCGO_ENABLED=0\n");
+
+        let usage = CgoDetector::detect(
+            Path::new("/repo"),
+            Path::new("api"),
+            &paths(&["api/sqlite.go", "api/.env"]),
+            &fs,
+        )
+        .unwrap();
+
+        assert!(usage.disabled);
+        assert!(!usage.required());
+    }
+
+    #[test]
+    fn test_no_cgo() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "/repo/main.go",
+            "package main\n\n// import \"C\" is not used here\n",
+        );
+        assert!(
+            CgoDetector::detect(Path::new("/repo"), Path::new(""), &paths(&["main.go"]), &fs)
+                .is_none()
+        );
+    }
+}
@@ -0,0 +1,229 @@
+//! CLI detector - Go command-line tools built on Cobra, told apart from servers that use Cobra
+//! only for their flags
+
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+const COBRA_MODULE: &str = "github.com/spf13/cobra";
+const VIPER_MODULE: &str = "github.com/spf13/viper";
+
+/// Import path prefixes of HTTP and RPC server frameworks
+pub(crate) const SERVER_IMPORTS: [&str; 7] = [
+    "github.com/gin-gonic/gin",
+    "github.com/labstack/echo",
+    "github.com/gofiber/fiber",
+    "github.com/go-chi/chi",
+    "github.com/gorilla/mux",
+    "github.com/valyala/fasthttp",
+    "google.golang.org/grpc",
+];
+
+/// net/http serving requests rather than making them
+pub(crate) const SERVE_PATTERN: &str = r"\bhttp\.(?:ListenAndServe(?:TLS)?\(|Server\s*\{)";
+
+/// A Cobra application
+#[derive(Debug, Clone, PartialEq)]
+pub struct CliUsage {
+    /// `Use` of the root command, e.g. "greet" for `Use: "greet [name]"`
+    pub root_command: Option<String>,
+    /// A server framework is imported or net/http serves requests, so a subcommand runs the
+    /// service
+    pub serves: bool,
+}
+
+impl CliUsage {
+    pub const FRAMEWORK: &'static str = "cobra";
+
+    /// A command-line tool rather than a service
+    pub fn is_cli(&self) -> bool {
+        !self.serves
+    }
+
+    /// Invocation that checks the binary runs without starting anything
+    pub fn run_command(binary: &str) -> String {
+        format!("./{} --help", binary)
+    }
+}
+
+pub struct CliDetector;
+
+impl CliDetector {
+    /// Detects Cobra among the service's go.mod `dependencies`, then scans its Go files among
+    /// `file_tree` (repository-relative) for `cobra.Command` literals and for serving code
+    ///
+    /// net/http counts only when it serves (`http.ListenAndServe`, an `http.Server`), since
+    /// tools import it for their HTTP clients as well.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        dependencies: &[String],
+    ) -> Option<CliUsage> {
+        if !has_module(dependencies, COBRA_MODULE) {
+            return None;
+        }
+
+        // rootCmd := &cobra.Command{Use: "greet [name]", Short: "..."}
+        let command_re =
+            Regex::new(r#"(?:(\w+)\s*:?=\s*)?&?cobra\.Command\s*\{[^}]*?\bUse:\s*"([^"\s]+)"#)
+                .expect("valid cobra command regex");
+        let serve_re = Regex::new(SERVE_PATTERN).expect("valid net/http serve regex");
+
+        let mut commands: Vec<(Option<String>, String)> = Vec::new();
+        let mut serves = false;
+        for path in file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| {
+                path.file_name()
+                    .and_then(|name| name.to_str())
+                    .is_some_and(|name| name.ends_with(".go") && !name.ends_with("_test.go"))
+            })
+        {
+            let Ok(content) = fs.read_to_string(&repo_path.join(service_path).join(path)) else {
+                continue;
+            };
+            commands.extend(command_re.captures_iter(&content).map(|caps| {
+                (
+                    caps.get(1).map(|m| m.as_str().to_string()),
+                    caps[2].to_string(),
+                )
+            }));
+            serves |= serve_re.is_match(&content)
+                || SERVER_IMPORTS
+                    .iter()
+                    .any(|import| content.contains(&format!("\"{}", import)));
+        }
+
+        // The root is conventionally `rootCmd`; otherwise the first command declared
+        let root_command = commands
+            .iter()
+            .find(|(variable, _)| {
+                variable
+                    .as_deref()
+                    .is_some_and(|variable| variable.eq_ignore_ascii_case("rootCmd"))
+            })
+            .or(commands.first())
+            .map(|(_, name)| name.clone());
+
+        Some(CliUsage {
+            root_command,
+            serves,
+        })
+    }
+
+    /// "viper" when the service reads its configuration with Viper
+    pub fn config_library(dependencies: &[String]) -> Option<&'static str> {
+        has_module(dependencies, VIPER_MODULE).then_some("viper")
+    }
+}
+
+fn has_module(dependencies: &[String], module: &str) -> bool {
+    dependencies
+        .iter()
+        .any(|dependency| dependency == module || dependency.starts_with(&format!("{}/", module)))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const ROOT: &str = r#"package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+var client = &http.Client{}
+
+var rootCmd = &cobra.Command{
+	Use:   "greet [name]",
+	Short: "Print a greeting",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println("hello")
+		return nil
+	},
+}
+"#;
+
+    const VERSION: &str = r#"package cmd
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version",
+}
+"#;
+
+    const SERVE: &str = r#"package cmd
+
+var serveCmd = &cobra.Command{
+	Use: "serve",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return http.ListenAndServe(":8080", nil)
+	},
+}
+"#;
+
+    fn detect(files: &[(&str, &str)], dependencies: &[&str]) -> Option<CliUsage> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        let dependencies: Vec<String> = dependencies.iter().map(|d| d.to_string()).collect();
+        CliDetector::detect(Path::new(""), Path::new(""), &tree, &fs, &dependencies)
+    }
+
+    #[test]
+    fn test_cli_tool() {
+        let usage = detect(
+            &[("cmd/version.go", VERSION), ("cmd/root.go", ROOT)],
+            &[COBRA_MODULE],
+        )
+        .unwrap();
+        assert_eq!(usage.root_command.as_deref(), Some("greet"));
+        assert!(usage.is_cli());
+        assert_eq!(CliUsage::run_command("greet"), "./greet --help");
+    }
+
+    #[test]
+    fn test_cobra_server() {
+        let usage = detect(
+            &[("cmd/root.go", ROOT), ("cmd/serve.go", SERVE)],
+            &[COBRA_MODULE],
+        )
+        .unwrap();
+        assert!(!usage.is_cli());
+        assert!(usage.serves);
+
+        let gin = detect(
+            &[(
+                "main.go",
+                "package main\n\nimport \"github.com/gin-gonic/gin\"\n",
+            )],
+            &[COBRA_MODULE, "github.com/gin-gonic/gin"],
+        )
+        .unwrap();
+        assert!(gin.serves);
+        assert_eq!(gin.root_command, None);
+    }
+
+    #[test]
+    fn test_requires_cobra() {
+        assert_eq!(detect(&[("cmd/root.go", ROOT)], &[VIPER_MODULE]), None);
+        assert_eq!(
+            CliDetector::config_library(&[VIPER_MODULE.to_string()]),
+            Some("viper")
+        );
+        assert_eq!(
+            CliDetector::config_library(&[COBRA_MODULE.to_string()]),
+            None
+        );
+    }
+}
@@ -45,6 +45,19 @@ pub fn scan_directory_with_language_filter<F, C>(
     }
 }
 
+/// Test sources by naming convention: `main_test.go`, `test_app.py`, `app.test.ts`,
+/// `app.spec.js`
+pub fn is_test_file(path: &Path) -> bool {
+    let stem = path
+        .file_stem()
+        .and_then(|s| s.to_str())
+        .unwrap_or_default();
+    stem.ends_with("_test")
+        || stem.starts_with("test_")
+        || stem.ends_with(".test")
+        || stem.ends_with(".spec")
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -73,6 +86,14 @@ mod tests {
         assert!(found_files[0].ends_with("server.js"));
     }
 
+    #[test]
+    fn test_is_test_file() {
+        assert!(is_test_file(Path::new("pkg/server_test.go")));
+        assert!(is_test_file(Path::new("tests/test_app.py")));
+        assert!(is_test_file(Path::new("src/app.spec.ts")));
+        assert!(!is_test_file(Path::new("src/contest.go")));
+    }
+
     #[test]
     fn test_scan_directory_handles_empty_dir() {
         let fs = MockFileSystem::new();
@@ -0,0 +1,362 @@
+//! Dependency depth analyzer - how deep and how wide a Go module's requirement graph is, from
+//! `go mod graph` output saved next to go.mod
+
+use super::required_tools::go_mod_requirements;
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::UniversalBuild;
+use std::collections::{HashMap, HashSet};
+use std::path::{Path, PathBuf};
+
+/// Where `go mod graph > go_mod_graph.txt` leaves the graph for detection to read
+pub const GO_MOD_GRAPH: &str = "go_mod_graph.txt";
+
+/// Levels of transitive requirements beyond which the tree is fragile
+pub const MAX_DEPTH: usize = 20;
+/// Modules in the graph beyond which it is worth pruning
+pub const MAX_COUNT: usize = 500;
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct DependencyDepth {
+    /// Longest chain of requirements from the main module; its direct requirements are 1
+    pub depth: usize,
+    /// Modules besides the main one, each counted once whatever versions the graph holds
+    pub count: usize,
+}
+
+impl DependencyDepth {
+    pub fn excessive(&self) -> bool {
+        self.depth > MAX_DEPTH || self.count > MAX_COUNT
+    }
+}
+
+pub struct DependencyDepthAnalyzer;
+
+impl DependencyDepthAnalyzer {
+    /// Analyzes the service's go_mod_graph.txt among `file_tree` (repository-relative);
+    /// `None` when there is none
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<DependencyDepth> {
+        if !file_tree
+            .iter()
+            .any(|path| path.strip_prefix(service_path).ok() == Some(Path::new(GO_MOD_GRAPH)))
+        {
+            return None;
+        }
+        let graph = fs
+            .read_to_string(&repo_path.join(service_path).join(GO_MOD_GRAPH))
+            .ok()?;
+        Some(Self::analyze(&graph))
+    }
+
+    /// Suggests saving the module graph to every go mod result whose depth is unknown and
+    /// whose go.mod requires anything; `detect --dependency-depth` asks for this
+    pub fn suggest_missing_graphs(
+        results: &mut [UniversalBuild],
+        repo_path: &Path,
+        fs: &dyn FileSystem,
+    ) {
+        for result in results.iter_mut().filter(|result| {
+            result.metadata.build_system == "go mod" && result.metadata.dependency_depth.is_none()
+        }) {
+            let go_mod = match result.metadata.service_path.as_deref() {
+                Some(path) => repo_path.join(path).join("go.mod"),
+                None => repo_path.join("go.mod"),
+            };
+            if fs
+                .read_to_string(&go_mod)
+                .is_ok_and(|content| !go_mod_requirements(&content).is_empty())
+            {
+                result.suggestions.push(format!(
+                    "Run `go mod graph > {}` so detection can measure the depth of the dependency tree",
+                    GO_MOD_GRAPH
+                ));
+            }
+        }
+    }
+
+    /// Depth and size of `go mod graph` output: one `<module>[@<version>] <module>@<version>`
+    /// requirement per line
+    ///
+    /// The main modules are the nodes without a version, several in a workspace. The `go@` and
+    /// `toolchain@` pseudo-modules are left out. Module versions requiring each other back form
+    /// one group whose members each add a level, however the chain enters it, so the result
+    /// does not depend on the order of the lines.
+    pub fn analyze(graph: &str) -> DependencyDepth {
+        let mut nodes: Vec<&str> = Vec::new();
+        let mut index: HashMap<&str, usize> = HashMap::new();
+        let mut successors: Vec<Vec<usize>> = Vec::new();
+        for line in graph.lines() {
+            let mut fields = line.split_whitespace();
+            let (Some(from), Some(to)) = (fields.next(), fields.next()) else {
+                continue;
+            };
+            if is_toolchain(to) {
+                continue;
+            }
+            let [from, to] = [from, to].map(|node| {
+                *index.entry(node).or_insert_with(|| {
+                    nodes.push(node);
+                    successors.push(Vec::new());
+                    nodes.len() - 1
+                })
+            });
+            successors[from].push(to);
+        }
+
+        let (component, components) = strongly_connected_components(&successors);
+        let mut members: Vec<Vec<usize>> = vec![Vec::new(); components];
+        for (node, &group) in component.iter().enumerate() {
+            members[group].push(node);
+        }
+
+        // A group is numbered only after every group it requires, so each one's chain is
+        // known before the groups requiring it are reached
+        let mut chain = vec![0; components];
+        for group in 0..components {
+            chain[group] = members[group]
+                .iter()
+                .flat_map(|&node| &successors[node])
+                .map(|&next| component[next])
+                .filter(|&next| next != group)
+                .map(|next| members[next].len() + chain[next])
+                .max()
+                .unwrap_or(0);
+        }
+        let depth = (0..nodes.len())
+            .filter(|&node| !nodes[node].contains('@'))
+            .map(|root| chain[component[root]])
+            .max()
+            .unwrap_or(0);
+        let count = nodes
+            .iter()
+            .filter_map(|node| node.split_once('@').map(|(path, _)| path))
+            .collect::<HashSet<_>>()
+            .len();
+
+        DependencyDepth { depth, count }
+    }
+}
+
+fn is_toolchain(node: &str) -> bool {
+    node.starts_with("go@") || node.starts_with("toolchain@")
+}
+
+/// Each node's strongly connected component and how many there are, numbered by Tarjan's
+/// algorithm so a component comes after every component it reaches
+fn strongly_connected_components(successors: &[Vec<usize>]) -> (Vec<usize>, usize) {
+    let mut tarjan = Tarjan {
+        successors,
+        index: vec![None; successors.len()],
+        low: vec![0; successors.len()],
+        stack: Vec::new(),
+        on_stack: vec![false; successors.len()],
+        component: vec![0; successors.len()],
+        visited: 0,
+        components: 0,
+    };
+    for node in 0..successors.len() {
+        if tarjan.index[node].is_none() {
+            tarjan.visit(node);
+        }
+    }
+    (tarjan.component, tarjan.components)
+}
+
+struct Tarjan<'a> {
+    successors: &'a [Vec<usize>],
+    index: Vec<Option<usize>>,
+    low: Vec<usize>,
+    stack: Vec<usize>,
+    on_stack: Vec<bool>,
+    component: Vec<usize>,
+    visited: usize,
+    components: usize,
+}
+
+impl Tarjan<'_> {
+    fn visit(&mut self, node: usize) {
+        self.index[node] = Some(self.visited);
+        self.low[node] = self.visited;
+        self.visited += 1;
+        self.stack.push(node);
+        self.on_stack[node] = true;
+
+        let successors = self.successors;
+        for &next in &successors[node] {
+            match self.index[next] {
+                None => {
+                    self.visit(next);
+                    self.low[node] = self.low[node].min(self.low[next]);
+                }
+                Some(index) if self.on_stack[next] => self.low[node] = self.low[node].min(index),
+                Some(_) => {}
+            }
+        }
+
+        if self.index[node] == Some(self.low[node]) {
+            while let Some(member) = self.stack.pop() {
+                self.on_stack[member] = false;
+                self.component[member] = self.components;
+                if member == node {
+                    break;
+                }
+            }
+            self.components += 1;
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    /// `example.com/app` requiring a chain of `length` modules, each requiring the next
+    fn chain(length: usize) -> String {
+        let mut graph = String::from("example.com/app go@1.22.0\n");
+        let mut from = "example.com/app".to_string();
+        for level in 1..=length {
+            let to = format!("example.com/dep{}@v1.0.{}", level, level);
+            graph.push_str(&format!("{} {}\n", from, to));
+            from = to;
+        }
+        graph
+    }
+
+    #[test]
+    fn test_depth_and_count() {
+        let graph = r#"example.com/app github.com/gin-gonic/gin@v1.9.1
+example.com/app golang.org/x/net@v0.17.0
+example.com/app go@1.22.0
+example.com/app toolchain@go1.22.3
+github.com/gin-gonic/gin@v1.9.1 github.com/go-playground/validator/v10@v10.14.0
+github.com/gin-gonic/gin@v1.9.1 golang.org/x/net@v0.10.0
+github.com/go-playground/validator/v10@v10.14.0 golang.org/x/crypto@v0.9.0
+golang.org/x/crypto@v0.9.0 golang.org/x/sys@v0.8.0
+golang.org/x/net@v0.10.0 golang.org/x/sys@v0.8.0
+golang.org/x/net@v0.17.0 go@1.18
+"#;
+        assert_eq!(
+            DependencyDepthAnalyzer::analyze(graph),
+            DependencyDepth { depth: 4, count: 5 }
+        );
+        assert_eq!(
+            DependencyDepthAnalyzer::analyze(""),
+            DependencyDepth { depth: 0, count: 0 }
+        );
+    }
+
+    #[test]
+    fn test_thresholds() {
+        let shallow = DependencyDepthAnalyzer::analyze(&chain(MAX_DEPTH));
+        assert_eq!(shallow.depth, MAX_DEPTH);
+        assert!(!shallow.excessive());
+        assert!(DependencyDepthAnalyzer::analyze(&chain(MAX_DEPTH + 1)).excessive());
+
+        let mut wide = String::new();
+        for module in 0..=MAX_COUNT {
+            wide.push_str(&format!("example.com/app example.com/m{}@v1.0.0\n", module));
+        }
+        let wide = DependencyDepthAnalyzer::analyze(&wide);
+        assert_eq!(
+            wide,
+            DependencyDepth {
+                depth: 1,
+                count: MAX_COUNT + 1
+            }
+        );
+        assert!(wide.excessive());
+    }
+
+    #[test]
+    fn test_cycles_and_workspaces() {
+        // Module versions can require each other back
+        let graph = r#"example.com/api example.com/a@v1.0.0
+example.com/worker example.com/b@v1.0.0
+example.com/a@v1.0.0 example.com/b@v1.0.0
+example.com/b@v1.0.0 example.com/a@v1.0.0
+example.com/b@v1.0.0 example.com/c@v1.0.0
+"#;
+        assert_eq!(
+            DependencyDepthAnalyzer::analyze(graph),
+            DependencyDepth { depth: 3, count: 3 }
+        );
+    }
+
+    #[test]
+    fn test_cycles_do_not_depend_on_line_order() {
+        // Whichever of a and b is reached first must not leave the other a shorter chain
+        let lines = [
+            "example.com/app example.com/a@v1.0.0",
+            "example.com/app example.com/b@v1.0.0",
+            "example.com/a@v1.0.0 example.com/b@v1.0.0",
+            "example.com/b@v1.0.0 example.com/a@v1.0.0",
+            "example.com/b@v1.0.0 example.com/c@v1.0.0",
+            "example.com/c@v1.0.0 example.com/d@v1.0.0",
+        ];
+        let expected = DependencyDepth { depth: 4, count: 4 };
+        assert_eq!(
+            DependencyDepthAnalyzer::analyze(&lines.join("\n")),
+            expected
+        );
+
+        let mut reordered = lines;
+        reordered.swap(0, 1);
+        reordered.swap(2, 3);
+        assert_eq!(
+            DependencyDepthAnalyzer::analyze(&reordered.join("\n")),
+            expected
+        );
+    }
+
+    #[test]
+    fn test_suggest_missing_graphs() {
+        let build = |service_path: &str| -> UniversalBuild {
+            serde_json::from_value(serde_json::json!({
+                "metadata": {
+                    "project_name": service_path,
+                    "language": "Go",
+                    "build_system": "go mod",
+                    "service_path": service_path
+                }
+            }))
+            .unwrap()
+        };
+        let fs = MockFileSystem::new();
+        let requiring = "module example.com/api\n\nrequire github.com/gin-gonic/gin v1.9.1\n";
+        fs.add_file("api/go.mod", requiring);
+        fs.add_file("bare/go.mod", "module example.com/bare\n\ngo 1.22\n");
+        fs.add_file("measured/go.mod", requiring);
+
+        let mut results = vec![build("api"), build("bare"), build("measured")];
+        results[2].metadata.dependency_depth = Some(3);
+        DependencyDepthAnalyzer::suggest_missing_graphs(&mut results, Path::new(""), &fs);
+
+        assert_eq!(
+            results[0].suggestions,
+            vec!["Run `go mod graph > go_mod_graph.txt` so detection can measure the depth of the dependency tree"]
+        );
+        assert!(results[1].suggestions.is_empty());
+        assert!(results[2].suggestions.is_empty());
+    }
+
+    #[test]
+    fn test_detect() {
+        let fs = MockFileSystem::new();
+        fs.add_file("api/go_mod_graph.txt", &chain(3));
+        let tree = vec![PathBuf::from("api/go_mod_graph.txt")];
+
+        assert_eq!(
+            DependencyDepthAnalyzer::detect(Path::new(""), Path::new("api"), &tree, &fs),
+            Some(DependencyDepth { depth: 3, count: 3 })
+        );
+        assert_eq!(
+            DependencyDepthAnalyzer::detect(Path::new(""), Path::new(""), &tree, &fs),
+            None
+        );
+    }
+}
@@ -0,0 +1,169 @@
+//! Deprecation checker - go.mod requirements on modules that are abandoned, archived or
+//! superseded, from the curated deprecated_modules.json
+
+use super::go_graph::build_dependency_graph;
+use super::go_replace::ReplaceDirectiveAnalyzer;
+use peelbox_core::output::schema::DeprecatedDependency;
+
+/// Module path, replacement and reason ("abandoned", "archived", "renamed" or "superseded") of
+/// each deprecated module, embedded so the list ships with the binary
+const DEPRECATED_MODULES: &str = include_str!("deprecated_modules.json");
+
+pub struct DeprecationChecker;
+
+impl DeprecationChecker {
+    /// The curated list of deprecated modules
+    pub fn deprecated_modules() -> Vec<DeprecatedDependency> {
+        serde_json::from_str(DEPRECATED_MODULES).expect("valid deprecated_modules.json")
+    }
+
+    /// Direct requirements of `go_mod` on deprecated modules, in go.mod order
+    ///
+    /// Major versions count as the module (`github.com/go-redis/redis/v8`). Indirect
+    /// requirements are left out since only the modules requiring them can move off them,
+    /// and so are modules a `replace` directive swaps for another module, such as a fork.
+    pub fn check(go_mod: &str) -> Vec<DeprecatedDependency> {
+        let Ok(graph) = build_dependency_graph(go_mod, "") else {
+            return vec![];
+        };
+        let replaced: Vec<String> = ReplaceDirectiveAnalyzer::analyze(go_mod)
+            .into_iter()
+            .filter(|directive| directive.target != directive.module)
+            .map(|directive| directive.module)
+            .collect();
+        let deprecated = Self::deprecated_modules();
+
+        graph
+            .direct_dependencies()
+            .into_iter()
+            .filter(|module| !replaced.contains(&module.path))
+            .filter_map(|module| {
+                deprecated
+                    .iter()
+                    .find(|entry| is_major_version_of(&module.path, &entry.module))
+                    .map(|entry| DeprecatedDependency {
+                        module: module.path,
+                        ..entry.clone()
+                    })
+            })
+            .collect()
+    }
+}
+
+/// `path` is `module` or one of its `/vN` major versions
+fn is_major_version_of(path: &str, module: &str) -> bool {
+    match path.strip_prefix(module) {
+        Some("") => true,
+        Some(suffix) => suffix
+            .strip_prefix("/v")
+            .is_some_and(|major| !major.is_empty() && major.chars().all(|c| c.is_ascii_digit())),
+        None => false,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_each_deprecated_module() {
+        let deprecated = DeprecationChecker::deprecated_modules();
+        assert!(!deprecated.is_empty());
+
+        for entry in &deprecated {
+            let go_mod = format!(
+                "module example.com/app\n\ngo 1.22\n\nrequire {} v1.0.0\n",
+                entry.module
+            );
+            assert_eq!(
+                DeprecationChecker::check(&go_mod),
+                vec![entry.clone()],
+                "{}",
+                entry.module
+            );
+            assert_ne!(entry.replacement, entry.module);
+            assert!(
+                ["abandoned", "archived", "renamed", "superseded"].contains(&entry.reason.as_str()),
+                "{} has reason {}",
+                entry.module,
+                entry.reason
+            );
+        }
+    }
+
+    #[test]
+    fn test_recommended_replacements() {
+        let go_mod = r#"module example.com/app
+
+go 1.22
+
+require (
+	github.com/dgrijalva/jwt-go v3.2.0+incompatible
+	github.com/gin-gonic/gin v1.9.1
+	github.com/go-redis/redis/v8 v8.11.5
+	github.com/golang/protobuf v1.5.3
+	github.com/gomodule/redigo v1.8.9
+)
+"#;
+        let replacements: Vec<(String, String, String)> = DeprecationChecker::check(go_mod)
+            .into_iter()
+            .map(|d| (d.module, d.replacement, d.reason))
+            .collect();
+        let expected = [
+            (
+                "github.com/dgrijalva/jwt-go",
+                "github.com/golang-jwt/jwt/v5",
+                "abandoned",
+            ),
+            (
+                "github.com/go-redis/redis/v8",
+                "github.com/redis/go-redis/v9",
+                "renamed",
+            ),
+            (
+                "github.com/golang/protobuf",
+                "google.golang.org/protobuf",
+                "superseded",
+            ),
+            (
+                "github.com/gomodule/redigo",
+                "github.com/redis/go-redis/v9",
+                "superseded",
+            ),
+        ]
+        .map(|(module, replacement, reason)| {
+            (
+                module.to_string(),
+                replacement.to_string(),
+                reason.to_string(),
+            )
+        });
+        assert_eq!(replacements, expected);
+    }
+
+    #[test]
+    fn test_skips_indirect_and_replaced_requirements() {
+        let go_mod = r#"module example.com/app
+
+go 1.22
+
+require (
+	github.com/pkg/errors v0.9.1
+	github.com/golang/mock v1.6.0 // indirect
+	github.com/mitchellh/mapstructure v1.5.0
+	github.com/golang/protobuf-extensions v1.0.0
+)
+
+replace github.com/pkg/errors => github.com/acme/errors v0.9.2
+
+replace github.com/mitchellh/mapstructure => github.com/mitchellh/mapstructure v1.5.1
+"#;
+        let modules: Vec<String> = DeprecationChecker::check(go_mod)
+            .into_iter()
+            .map(|d| d.module)
+            .collect();
+        // Pinning another version of the same module still builds the deprecated one
+        assert_eq!(modules, vec!["github.com/mitchellh/mapstructure"]);
+        assert!(DeprecationChecker::check("go 1.22\n").is_empty());
+    }
+}
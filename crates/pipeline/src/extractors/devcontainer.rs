@@ -0,0 +1,259 @@
+//! Dev container detector - development environments declared for VS Code Dev Containers and
+//! GitHub Codespaces
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::DevContainerMetadata;
+use peelbox_stack::buildsystem::deno::strip_jsonc;
+use peelbox_stack::BuildSystemId;
+use serde_json::Value;
+use std::path::{Path, PathBuf};
+
+const CONFIG_FILES: [&str; 2] = [".devcontainer/devcontainer.json", ".devcontainer.json"];
+
+/// Ports of databases and brokers a dev container commonly forwards next to the application
+const BACKING_PORTS: [u16; 8] = [3306, 5432, 5672, 6379, 9092, 11211, 15672, 27017];
+
+/// Install commands by the build system they belong to; global tool installs are not matched
+const INSTALL_COMMANDS: &[(&str, BuildSystemId)] = &[
+    ("go mod download", BuildSystemId::GoMod),
+    ("go mod tidy", BuildSystemId::GoMod),
+    ("npm install", BuildSystemId::Npm),
+    ("npm ci", BuildSystemId::Npm),
+    ("yarn install", BuildSystemId::Yarn),
+    ("pnpm install", BuildSystemId::Pnpm),
+    ("bun install", BuildSystemId::Bun),
+    ("pip install -r", BuildSystemId::Pip),
+    ("pip install -e", BuildSystemId::Pip),
+    ("pip install .", BuildSystemId::Pip),
+    ("poetry install", BuildSystemId::Poetry),
+    ("pipenv install", BuildSystemId::Pipenv),
+    ("pdm install", BuildSystemId::Pdm),
+    ("bundle install", BuildSystemId::Bundler),
+    ("composer install", BuildSystemId::Composer),
+    ("cargo build", BuildSystemId::Cargo),
+    ("cargo fetch", BuildSystemId::Cargo),
+    ("mvn ", BuildSystemId::Maven),
+    ("./mvnw ", BuildSystemId::Maven),
+    ("gradle ", BuildSystemId::Gradle),
+    ("./gradlew ", BuildSystemId::Gradle),
+    ("dotnet restore", BuildSystemId::DotNet),
+    ("mix deps.get", BuildSystemId::Mix),
+];
+
+pub struct DevContainerDetector;
+
+impl DevContainerDetector {
+    /// Reads `.devcontainer/devcontainer.json`, else `.devcontainer.json`, in the service
+    /// directory or the repository root among `file_tree` (repository-relative)
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<DevContainerMetadata> {
+        let path = [service_path, Path::new("")]
+            .into_iter()
+            .flat_map(|dir| CONFIG_FILES.iter().map(move |name| dir.join(name)))
+            .find(|path| file_tree.contains(path))?;
+        let content = fs.read_to_string(&repo_path.join(&path)).ok()?;
+        let json: Value = serde_json::from_str(&strip_jsonc(&content)).ok()?;
+
+        let mut features: Vec<String> = json["features"]
+            .as_object()
+            .map(|features| features.keys().cloned().collect())
+            .unwrap_or_default();
+        features.sort();
+
+        Some(DevContainerMetadata {
+            config_file: path.to_string_lossy().replace('\\', "/"),
+            image: json["image"].as_str().map(str::to_string),
+            dockerfile: json["build"]["dockerfile"]
+                .as_str()
+                .or_else(|| json["dockerFile"].as_str())
+                .map(str::to_string),
+            post_create: lifecycle_command(&json["postCreateCommand"]),
+            forward_ports: json["forwardPorts"]
+                .as_array()
+                .map(|ports| ports.iter().filter_map(forwarded_port).collect())
+                .unwrap_or_default(),
+            features,
+        })
+    }
+
+    /// Forwarded ports that are not a well-known backing service's, in declaration order
+    pub fn app_ports(devcontainer: &DevContainerMetadata) -> Vec<u16> {
+        devcontainer
+            .forward_ports
+            .iter()
+            .copied()
+            .filter(|port| !BACKING_PORTS.contains(port))
+            .collect()
+    }
+
+    /// Build systems whose install commands `postCreateCommand` runs, in command order
+    pub fn installs(devcontainer: &DevContainerMetadata) -> Vec<BuildSystemId> {
+        let Some(post_create) = devcontainer.post_create.as_deref() else {
+            return vec![];
+        };
+        let mut build_systems: Vec<BuildSystemId> = Vec::new();
+        for command in post_create.split(['&', ';', '|']).map(str::trim) {
+            if command
+                .split_whitespace()
+                .any(|arg| arg == "-g" || arg == "--global")
+            {
+                continue;
+            }
+            let command = format!("{} ", command);
+            let installed = INSTALL_COMMANDS
+                .iter()
+                .find(|(prefix, _)| command.starts_with(prefix))
+                .map(|(_, build_system)| build_system.clone());
+            if let Some(build_system) = installed {
+                if !build_systems.contains(&build_system) {
+                    build_systems.push(build_system);
+                }
+            }
+        }
+        build_systems
+    }
+}
+
+/// A lifecycle command as a shell line: arrays are argv, objects name commands run in parallel
+fn lifecycle_command(value: &Value) -> Option<String> {
+    let argv = |args: &Vec<Value>| {
+        args.iter()
+            .filter_map(Value::as_str)
+            .collect::<Vec<_>>()
+            .join(" ")
+    };
+    let command = match value {
+        Value::String(command) => command.trim().to_string(),
+        Value::Array(args) => argv(args),
+        Value::Object(commands) => commands
+            .values()
+            .filter_map(|command| match command {
+                Value::String(command) => Some(command.trim().to_string()),
+                Value::Array(args) => Some(argv(args)),
+                _ => None,
+            })
+            .collect::<Vec<_>>()
+            .join(" && "),
+        _ => return None,
+    };
+    (!command.is_empty()).then_some(command)
+}
+
+/// `3000` or `"localhost:3000"`; `"db:5432"` forwards another container's port
+fn forwarded_port(value: &Value) -> Option<u16> {
+    match value {
+        Value::Number(port) => port.as_u64().and_then(|port| u16::try_from(port).ok()),
+        Value::String(port) => match port.split_once(':') {
+            Some(("localhost" | "127.0.0.1", port)) => port.parse().ok(),
+            Some(_) => None,
+            None => port.parse().ok(),
+        },
+        _ => None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn detect(files: &[(&str, &str)], service_path: &str) -> Option<DevContainerMetadata> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        DevContainerDetector::detect(Path::new(""), Path::new(service_path), &tree, &fs)
+    }
+
+    #[test]
+    fn test_image_devcontainer() {
+        let devcontainer = detect(
+            &[(
+                ".devcontainer/devcontainer.json",
+                r#"{
+                    // Go with the GitHub CLI
+                    "name": "api",
+                    "image": "mcr.microsoft.com/devcontainers/go:1.22",
+                    "features": {
+                        "ghcr.io/devcontainers/features/github-cli:1": {},
+                        "ghcr.io/devcontainers/features/docker-in-docker:2": {},
+                    },
+                    "forwardPorts": [8080, "db:5432", "localhost:6379"],
+                    "postCreateCommand": "go mod download",
+                }"#,
+            )],
+            "",
+        )
+        .unwrap();
+
+        assert_eq!(
+            devcontainer,
+            DevContainerMetadata {
+                config_file: ".devcontainer/devcontainer.json".to_string(),
+                image: Some("mcr.microsoft.com/devcontainers/go:1.22".to_string()),
+                dockerfile: None,
+                post_create: Some("go mod download".to_string()),
+                forward_ports: vec![8080, 6379],
+                features: vec![
+                    "ghcr.io/devcontainers/features/docker-in-docker:2".to_string(),
+                    "ghcr.io/devcontainers/features/github-cli:1".to_string(),
+                ],
+            }
+        );
+        assert_eq!(DevContainerDetector::app_ports(&devcontainer), vec![8080]);
+        assert_eq!(
+            DevContainerDetector::installs(&devcontainer),
+            vec![BuildSystemId::GoMod]
+        );
+    }
+
+    #[test]
+    fn test_dockerfile_devcontainer_in_service() {
+        let devcontainer = detect(
+            &[
+                (
+                    ".devcontainer/devcontainer.json",
+                    r#"{"image": "mcr.microsoft.com/devcontainers/base"}"#,
+                ),
+                (
+                    "services/web/.devcontainer.json",
+                    r#"{
+                        "build": {"dockerfile": "Dockerfile.dev"},
+                        "postCreateCommand": {
+                            "deps": ["npm", "ci"],
+                            "tools": "npm install -g pnpm"
+                        }
+                    }"#,
+                ),
+            ],
+            "services/web",
+        )
+        .unwrap();
+
+        assert_eq!(devcontainer.config_file, "services/web/.devcontainer.json");
+        assert_eq!(devcontainer.image, None);
+        assert_eq!(devcontainer.dockerfile.as_deref(), Some("Dockerfile.dev"));
+        assert_eq!(
+            devcontainer.post_create.as_deref(),
+            Some("npm ci && npm install -g pnpm")
+        );
+        assert_eq!(
+            DevContainerDetector::installs(&devcontainer),
+            vec![BuildSystemId::Npm]
+        );
+    }
+
+    #[test]
+    fn test_no_devcontainer() {
+        assert_eq!(detect(&[("go.mod", "module example.com/app\n")], ""), None);
+        assert_eq!(
+            detect(&[(".devcontainer/devcontainer.json", "{ not json")], ""),
+            None
+        );
+    }
+}
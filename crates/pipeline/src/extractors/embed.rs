@@ -0,0 +1,124 @@
+//! go:embed detector - static assets compiled into a Go binary
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::EmbeddedAsset;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+/// Directories frontend tooling writes its production build to
+const FRONTEND_OUTPUT_DIRS: [&str; 3] = ["dist", "build", "public"];
+
+pub struct EmbedDetector;
+
+impl EmbedDetector {
+    /// Patterns of every `//go:embed` directive in the service's `.go` files among
+    /// `file_tree` (repository-relative), one entry per pattern
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Vec<EmbeddedAsset> {
+        let directive = Regex::new(r"^//go:embed\s+(.+?)\s*$").expect("valid directive regex");
+        let mut assets = Vec::new();
+
+        for relative in file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| path.extension().is_some_and(|ext| ext == "go"))
+        {
+            let Ok(content) = fs.read_to_string(&repo_path.join(service_path).join(relative))
+            else {
+                continue;
+            };
+            let file = relative.to_string_lossy().replace('\\', "/");
+
+            for (index, line) in content.lines().enumerate() {
+                let Some(caps) = directive.captures(line.trim_end()) else {
+                    continue;
+                };
+                for pattern in split_patterns(&caps[1]) {
+                    assets.push(EmbeddedAsset {
+                        pattern,
+                        file: file.clone(),
+                        line: index + 1,
+                    });
+                }
+            }
+        }
+
+        assets
+    }
+
+    /// Whether an embedded pattern picks up a frontend's build output (`dist/`, `build/`,
+    /// `public/`), which has to exist before `go build` runs
+    pub fn is_frontend_output(pattern: &str) -> bool {
+        pattern
+            .trim_start_matches("all:")
+            .split('/')
+            .any(|segment| FRONTEND_OUTPUT_DIRS.contains(&segment))
+    }
+}
+
+/// Space-separated patterns; Go also accepts them double-quoted or back-quoted
+fn split_patterns(patterns: &str) -> Vec<String> {
+    let mut result = Vec::new();
+    let mut rest = patterns.trim();
+    while !rest.is_empty() {
+        let (pattern, remainder) = match rest.chars().next() {
+            Some(quote @ ('"' | '`')) => match rest[1..].find(quote) {
+                Some(end) => (&rest[1..end + 1], &rest[end + 2..]),
+                None => (&rest[1..], ""),
+            },
+            _ => rest.split_once(char::is_whitespace).unwrap_or((rest, "")),
+        };
+        if !pattern.is_empty() {
+            result.push(pattern.to_string());
+        }
+        rest = remainder.trim_start();
+    }
+    result
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    #[test]
+    fn test_collects_patterns_with_positions() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "server.go",
+            "package main\n\nimport \"embed\"\n\n//go:embed templates/*.html \"static files\"\nvar content embed.FS\n\n//go:embed all:web/dist\nvar frontend embed.FS\n",
+        );
+        fs.add_file(
+            "version.go",
+            "package main\n\n//go:embed VERSION\nvar version string\n",
+        );
+        let tree = ["server.go", "version.go"].map(PathBuf::from);
+
+        let assets = EmbedDetector::detect(Path::new(""), Path::new(""), &tree, &fs);
+        let found: Vec<(&str, &str, usize)> = assets
+            .iter()
+            .map(|a| (a.pattern.as_str(), a.file.as_str(), a.line))
+            .collect();
+        assert_eq!(
+            found,
+            vec![
+                ("templates/*.html", "server.go", 5),
+                ("static files", "server.go", 5),
+                ("all:web/dist", "server.go", 8),
+                ("VERSION", "version.go", 3),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_frontend_output() {
+        assert!(EmbedDetector::is_frontend_output("all:web/dist"));
+        assert!(EmbedDetector::is_frontend_output("public/*"));
+        assert!(!EmbedDetector::is_frontend_output("assets"));
+        assert!(!EmbedDetector::is_frontend_output("distribution.txt"));
+    }
+}
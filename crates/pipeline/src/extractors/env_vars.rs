@@ -4,7 +4,31 @@ use crate::extractors::ServiceContext;
 use peelbox_core::fs::FileSystem;
 use peelbox_stack::registry::StackRegistry;
 use regex::Regex;
-use std::collections::HashMap;
+use std::collections::{BTreeSet, HashMap};
+use std::path::PathBuf;
+
+/// Variables every process sees; reading them says nothing about app configuration
+const SYSTEM_ENV_VARS: &[&str] = &[
+    "PATH",
+    "HOME",
+    "USER",
+    "SHELL",
+    "PWD",
+    "TMPDIR",
+    "TMP",
+    "TEMP",
+    "LANG",
+    "TERM",
+    "HOSTNAME",
+    "GOPATH",
+    "GOROOT",
+    "GOOS",
+    "GOARCH",
+    "GOFLAGS",
+    "GOCACHE",
+    "GOMODCACHE",
+    "CGO_ENABLED",
+];
 
 #[derive(Debug, Clone, PartialEq, Eq)]
 pub struct EnvVarInfo {
@@ -54,6 +78,54 @@ impl<F: FileSystem> EnvVarExtractor<F> {
         env_vars.into_values().collect()
     }
 
+    /// Variables the service's source files read, sorted and without system variables
+    ///
+    /// Unlike [`extract`](Self::extract), which only looks at the service's entrypoint files,
+    /// this reads every given file written in the service language, skipping tests.
+    pub fn required_env_vars(&self, context: &ServiceContext, files: &[PathBuf]) -> Vec<String> {
+        let lang = match context
+            .language
+            .as_ref()
+            .and_then(|id| self.registry.get_language(id.clone()))
+        {
+            Some(l) => l,
+            None => return vec![],
+        };
+
+        let extensions = lang.extensions();
+        let patterns: Vec<Regex> = lang
+            .env_var_patterns()
+            .iter()
+            .filter_map(|(pattern, _)| Regex::new(pattern).ok())
+            .collect();
+
+        let mut names = BTreeSet::new();
+        for file in files {
+            let is_source = file
+                .extension()
+                .and_then(|ext| ext.to_str())
+                .is_some_and(|ext| extensions.iter().any(|e| e == ext));
+            if !is_source || crate::extractors::common::is_test_file(file) {
+                continue;
+            }
+            let Ok(content) = self.fs.read_to_string(file) else {
+                continue;
+            };
+            for re in &patterns {
+                names.extend(
+                    re.captures_iter(&content)
+                        .filter_map(|cap| cap.get(1))
+                        .map(|m| m.as_str().to_string()),
+                );
+            }
+        }
+
+        names
+            .into_iter()
+            .filter(|name| !SYSTEM_ENV_VARS.contains(&name.as_str()))
+            .collect()
+    }
+
     fn extract_from_env_file(
         &self,
         content: &str,
@@ -324,6 +396,45 @@ fn main() {
         assert!(matches!(port_var.source, EnvVarSource::EnvExample));
     }
 
+    #[test]
+    fn test_required_env_vars_across_go_files() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "main.go",
+            r#"port := os.Getenv("PORT")
+home := os.Getenv("HOME")"#,
+        );
+        fs.add_file(
+            "internal/config/config.go",
+            r#"secret, ok := os.LookupEnv("SECRET_KEY")
+url := os.Getenv("DATABASE_URL")
+again := os.Getenv("PORT")"#,
+        );
+        fs.add_file("main_test.go", r#"os.Getenv("TEST_ONLY")"#);
+        fs.add_file("README.md", r#"os.Getenv("DOCS_ONLY")"#);
+
+        let extractor = EnvVarExtractor::new(fs);
+        let context = ServiceContext::with_detection(
+            PathBuf::from("."),
+            Some(peelbox_stack::LanguageId::Go),
+            None,
+        );
+        let files: Vec<PathBuf> = [
+            "main.go",
+            "internal/config/config.go",
+            "main_test.go",
+            "README.md",
+        ]
+        .iter()
+        .map(PathBuf::from)
+        .collect();
+
+        assert_eq!(
+            extractor.required_env_vars(&context, &files),
+            vec!["DATABASE_URL", "PORT", "SECRET_KEY"]
+        );
+    }
+
     #[test]
     fn test_no_env_vars_found() {
         let fs = MockFileSystem::new();
@@ -0,0 +1,240 @@
+//! Feature flag detector - the flag SDK a service evaluates with, its provider and the flag
+//! definition files it reads
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::FeatureFlagsMetadata;
+use std::path::{Path, PathBuf};
+
+/// SDKs by the name reported for them, matched as exact dependency names or `/` prefixes
+const SDKS: [(&str, &[&str]); 4] = [
+    ("openfeature", &["github.com/open-feature/go-sdk"]),
+    (
+        "go-feature-flag",
+        &["github.com/thomaspoignant/go-feature-flag"],
+    ),
+    (
+        "launchdarkly",
+        &[
+            "github.com/launchdarkly/go-server-sdk",
+            "launchdarkly-server-sdk",
+            "ldclient",
+            "ldclient-py",
+        ],
+    ),
+    (
+        "unleash",
+        &[
+            "unleash-client",
+            "UnleashClient",
+            "github.com/Unleash/unleash-client-go",
+        ],
+    ),
+];
+
+/// OpenFeature Go providers live in go-sdk-contrib, one module per provider
+const OPENFEATURE_PROVIDERS: &str = "github.com/open-feature/go-sdk-contrib/providers/";
+
+/// Environment variables an SDK needs to reach its flag service, by the conventions of each
+/// vendor's documentation
+const SDK_ENV_VARS: [(&str, &[&str]); 2] = [
+    ("launchdarkly", &["LAUNCHDARKLY_SDK_KEY"]),
+    ("unleash", &["UNLEASH_URL", "UNLEASH_API_TOKEN"]),
+];
+
+pub struct FeatureFlagDetector;
+
+impl FeatureFlagDetector {
+    /// Detects the SDK among the service's `dependencies` and flag definition files among
+    /// `file_tree` (repository-relative)
+    ///
+    /// An OpenFeature provider comes from its go-sdk-contrib module, or is flagd when the service
+    /// keeps flagd-format definitions. Definition files are JSON or YAML files named after flags
+    /// that declare variants.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        dependencies: &[String],
+    ) -> Option<FeatureFlagsMetadata> {
+        let sdk = SDKS.iter().find_map(|(sdk, packages)| {
+            dependencies
+                .iter()
+                .any(|dependency| {
+                    packages.iter().any(|package| {
+                        dependency == package || dependency.starts_with(&format!("{}/", package))
+                    })
+                })
+                .then_some(*sdk)
+        });
+
+        let mut flagd_format = false;
+        let config_files: Vec<String> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| is_flag_file_name(path))
+            .filter(|path| {
+                let Ok(content) = fs.read_to_string(&repo_path.join(service_path).join(path))
+                else {
+                    return false;
+                };
+                // flagd: {"flags": {"new-checkout": {"variants": {...}, "defaultVariant": "off"}}}
+                if content.contains("defaultVariant") {
+                    flagd_format = true;
+                    return true;
+                }
+                // go-feature-flag: new-checkout: {variations: {...}, defaultRule: {...}}
+                content.contains("variations")
+            })
+            .map(|path| path.to_string_lossy().replace('\\', "/"))
+            .collect();
+
+        if sdk.is_none() && config_files.is_empty() {
+            return None;
+        }
+
+        let provider_hint = match sdk {
+            Some("openfeature") => dependencies
+                .iter()
+                .find_map(|dependency| dependency.strip_prefix(OPENFEATURE_PROVIDERS))
+                .map(|provider| provider.split('/').next().unwrap_or(provider).to_string())
+                .or_else(|| flagd_format.then(|| "flagd".to_string())),
+            _ => None,
+        };
+
+        Some(FeatureFlagsMetadata {
+            sdk: sdk.map(String::from),
+            provider_hint,
+            config_files,
+        })
+    }
+
+    /// Variables holding the flag service URL or SDK key the SDK connects with
+    pub fn required_env_vars(flags: &FeatureFlagsMetadata) -> &'static [&'static str] {
+        SDK_ENV_VARS
+            .iter()
+            .find(|(sdk, _)| flags.sdk.as_deref() == Some(*sdk))
+            .map(|(_, vars)| *vars)
+            .unwrap_or_default()
+    }
+}
+
+/// `flags.yaml`, `flag-config.yaml`, `flags.goff.yaml`, `featureflags.json`, ...
+fn is_flag_file_name(path: &Path) -> bool {
+    let Some(name) = path.file_name().and_then(|name| name.to_str()) else {
+        return false;
+    };
+    let name = name.to_lowercase();
+    name.contains("flag")
+        && [".json", ".yaml", ".yml"]
+            .iter()
+            .any(|ext| name.ends_with(ext))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use peelbox_core::fs::MockFileSystem;
+
+    const GOFF_FLAGS: &str = r#"new-checkout:
+  variations:
+    enabled: true
+    disabled: false
+  defaultRule:
+    percentage:
+      enabled: 20
+      disabled: 80
+"#;
+
+    const FLAGD_FLAGS: &str = r#"{
+  "flags": {
+    "new-checkout": {
+      "state": "ENABLED",
+      "variants": { "on": true, "off": false },
+      "defaultVariant": "off"
+    }
+  }
+}
+"#;
+
+    fn detect(files: &[(&str, &str)], dependencies: &[&str]) -> Option<FeatureFlagsMetadata> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        let dependencies: Vec<String> = dependencies.iter().map(|d| d.to_string()).collect();
+        FeatureFlagDetector::detect(Path::new(""), Path::new(""), &tree, &fs, &dependencies)
+    }
+
+    #[test]
+    fn test_go_feature_flag_with_config_file() {
+        let flags = detect(
+            &[
+                ("config/flags.goff.yaml", GOFF_FLAGS),
+                ("config/app.yaml", "port: 8080\n"),
+                ("flag-notes.json", "{\"owner\": \"payments\"}"),
+            ],
+            &["github.com/thomaspoignant/go-feature-flag"],
+        )
+        .unwrap();
+        assert_eq!(flags.sdk.as_deref(), Some("go-feature-flag"));
+        assert_eq!(flags.provider_hint, None);
+        assert_eq!(flags.config_files, vec!["config/flags.goff.yaml"]);
+        assert!(FeatureFlagDetector::required_env_vars(&flags).is_empty());
+    }
+
+    #[test]
+    fn test_openfeature_provider() {
+        let contrib = detect(
+            &[],
+            &[
+                "github.com/open-feature/go-sdk",
+                "github.com/open-feature/go-sdk-contrib/providers/go-feature-flag",
+            ],
+        )
+        .unwrap();
+        assert_eq!(contrib.sdk.as_deref(), Some("openfeature"));
+        assert_eq!(contrib.provider_hint.as_deref(), Some("go-feature-flag"));
+
+        let flagd = detect(
+            &[("flags.flagd.json", FLAGD_FLAGS)],
+            &["github.com/open-feature/go-sdk"],
+        )
+        .unwrap();
+        assert_eq!(flagd.provider_hint.as_deref(), Some("flagd"));
+        assert_eq!(flagd.config_files, vec!["flags.flagd.json"]);
+    }
+
+    #[test]
+    fn test_hosted_sdks_need_env_vars() {
+        let launchdarkly = detect(&[], &["github.com/launchdarkly/go-server-sdk/v7"]).unwrap();
+        assert_eq!(launchdarkly.sdk.as_deref(), Some("launchdarkly"));
+        assert_eq!(
+            FeatureFlagDetector::required_env_vars(&launchdarkly),
+            &["LAUNCHDARKLY_SDK_KEY"]
+        );
+
+        let python = detect(&[], &["ldclient"]).unwrap();
+        assert_eq!(python.sdk.as_deref(), Some("launchdarkly"));
+
+        let unleash = detect(&[], &["express", "unleash-client"]).unwrap();
+        assert_eq!(unleash.sdk.as_deref(), Some("unleash"));
+        assert_eq!(
+            FeatureFlagDetector::required_env_vars(&unleash),
+            &["UNLEASH_URL", "UNLEASH_API_TOKEN"]
+        );
+    }
+
+    #[test]
+    fn test_nothing_to_report() {
+        assert_eq!(
+            detect(
+                &[("i18n/flags.json", "{\"en\": \"🇬🇧\"}")],
+                &["github.com/gin-gonic/gin"]
+            ),
+            None
+        );
+    }
+}
@@ -0,0 +1,227 @@
+//! Framework version resolver - exact framework versions pinned by go.sum and lockfiles
+
+use anyhow::{anyhow, Result};
+use peelbox_core::fs::FileSystem;
+use std::path::{Path, PathBuf};
+
+/// Lockfiles in the order they are consulted; a service normally has only one of them
+const LOCKFILES: [&str; 6] = [
+    "go.sum",
+    "package-lock.json",
+    "Cargo.lock",
+    "poetry.lock",
+    "uv.lock",
+    "Pipfile.lock",
+];
+
+pub struct FrameworkVersionResolver;
+
+impl FrameworkVersionResolver {
+    /// Version of `package` (a Go module path, npm, crate or PyPI name) pinned by the lockfile
+    /// of the service, or of the repository root for workspaces, among `file_tree`
+    /// (repository-relative)
+    pub fn resolve<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        package: &str,
+    ) -> Option<String> {
+        [service_path, Path::new("")]
+            .into_iter()
+            .flat_map(|dir| LOCKFILES.iter().map(move |name| dir.join(name)))
+            .filter(|lockfile| file_tree.contains(lockfile))
+            .find_map(|lockfile| {
+                let content = fs.read_to_string(&repo_path.join(&lockfile)).ok()?;
+                match lockfile.file_name().and_then(|name| name.to_str())? {
+                    "go.sum" => resolve_version(package, &content).ok(),
+                    "package-lock.json" => npm_lock_version(package, &content),
+                    "Pipfile.lock" => pipfile_lock_version(package, &content),
+                    // Cargo.lock, poetry.lock and uv.lock share the [[package]] layout
+                    _ => toml_lock_version(package, &content),
+                }
+            })
+    }
+}
+
+/// Version of `module` that go.sum records, e.g. `v1.9.1`
+///
+/// go.sum keeps every version the module graph touched, so the highest one is what minimal
+/// version selection builds. Versions with a module hash win over those only recorded with a
+/// `/go.mod` hash, which were never downloaded. Other major versions (`module/v2`) are distinct
+/// module paths and never match.
+pub fn resolve_version(module: &str, go_sum: &str) -> Result<String> {
+    let entries: Vec<(&str, bool)> = go_sum
+        .lines()
+        .filter_map(|line| {
+            let mut fields = line.split_whitespace();
+            (fields.next()? == module).then_some(())?;
+            let version = fields.next()?;
+            Some(match version.strip_suffix("/go.mod") {
+                Some(version) => (version, false),
+                None => (version, true),
+            })
+        })
+        .collect();
+
+    let downloaded = entries.iter().any(|(_, downloaded)| *downloaded);
+    entries
+        .into_iter()
+        .filter(|(_, has_module_hash)| *has_module_hash || !downloaded)
+        .map(|(version, _)| version)
+        .max_by(|a, b| semver_key(a).cmp(&semver_key(b)))
+        .map(str::to_string)
+        .ok_or_else(|| anyhow!("{} is not recorded in go.sum", module))
+}
+
+/// Orders `vMAJOR.MINOR.PATCH[-pre][+build]` versions; prereleases sort before their release
+pub fn semver_key(version: &str) -> (Vec<u64>, bool, String) {
+    let version = version.trim_start_matches('v');
+    let version = version.split('+').next().unwrap_or(version);
+    let (release, prerelease) = version.split_once('-').unwrap_or((version, ""));
+    let numbers = release
+        .split('.')
+        .map(|part| part.parse().unwrap_or(0))
+        .collect();
+    (numbers, prerelease.is_empty(), prerelease.to_string())
+}
+
+/// `packages["node_modules/<name>"]` (lockfile v2+) or `dependencies[<name>]` (v1)
+fn npm_lock_version(package: &str, content: &str) -> Option<String> {
+    let lock: serde_json::Value = serde_json::from_str(content).ok()?;
+    lock.get("packages")
+        .and_then(|packages| packages.get(format!("node_modules/{}", package)))
+        .or_else(|| lock.get("dependencies")?.get(package))?
+        .get("version")?
+        .as_str()
+        .map(str::to_string)
+}
+
+/// `default[<name>].version` without its `==`
+fn pipfile_lock_version(package: &str, content: &str) -> Option<String> {
+    let lock: serde_json::Value = serde_json::from_str(content).ok()?;
+    let packages = lock.get("default")?.as_object()?;
+    packages
+        .iter()
+        .find(|(name, _)| normalize_name(name) == normalize_name(package))?
+        .1
+        .get("version")?
+        .as_str()
+        .map(|version| version.trim_start_matches("==").to_string())
+}
+
+/// `version` of the `[[package]]` table named `package`
+fn toml_lock_version(package: &str, content: &str) -> Option<String> {
+    let wanted = normalize_name(package);
+    let mut in_package = false;
+    let mut name_matches = false;
+    for line in content.lines().map(str::trim) {
+        if line.starts_with('[') {
+            in_package = line == "[[package]]";
+            name_matches = false;
+            continue;
+        }
+        if !in_package {
+            continue;
+        }
+        let Some((key, value)) = line.split_once('=') else {
+            continue;
+        };
+        let value = value.trim().trim_matches('"');
+        match key.trim() {
+            "name" => name_matches = normalize_name(value) == wanted,
+            "version" if name_matches => return Some(value.to_string()),
+            _ => {}
+        }
+    }
+    None
+}
+
+/// PyPI names compare case-insensitively with `-`, `_` and `.` equivalent; crate names
+/// treat `-` and `_` alike too
+fn normalize_name(name: &str) -> String {
+    name.to_lowercase().replace(['_', '.'], "-")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const GO_SUM: &str = "\
+github.com/labstack/echo v3.3.10+incompatible h1:pGRcYk231ExFAyoAjAfD85kQzRJCRI8bbnE7CX5OEgg=
+github.com/labstack/echo v3.3.10+incompatible/go.mod h1:0INS7j/VjnFxD4E2wkz67b8cVwCLbBmJyDaka6Cmk1s=
+github.com/labstack/echo/v4 v4.10.2 h1:n1jAhnq/elIFTHr1EYpiYtyKgx4RW9ccVgkqByZaN2M=
+github.com/labstack/echo/v4 v4.10.2/go.mod h1:OEyqf2//K1DFdE57vw2DRgWY0M7s65IVQO2FzvI4J5k=
+github.com/labstack/echo/v4 v4.11.4 h1:vDZmA+qNeh1pd/cCkEicDMrjtrnMGQ1QFI9gWN1zGq8=
+github.com/labstack/echo/v4 v4.11.4/go.mod h1:YuYRTSM3CHs2ybfrL8Px48bO6BAnYIN4l8wSTMP6BDQ=
+github.com/labstack/echo/v4 v4.12.0-rc1/go.mod h1:UP9Cr2DJXbOK3Kr9ONYzNowSh7HP0aG0ShAyycHSJvM=
+github.com/labstack/gommon v0.4.2 h1:F8qTUNXgG1+6WQmqoUWnz8WiEU60mXVVw0P4ht1WRA0=
+";
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    #[test]
+    fn test_resolve_version_per_major_version() {
+        assert_eq!(
+            resolve_version("github.com/labstack/echo/v4", GO_SUM).unwrap(),
+            "v4.11.4"
+        );
+        assert_eq!(
+            resolve_version("github.com/labstack/echo", GO_SUM).unwrap(),
+            "v3.3.10+incompatible"
+        );
+        assert!(resolve_version("github.com/labstack/echo/v5", GO_SUM).is_err());
+    }
+
+    #[test]
+    fn test_resolve_version_from_go_mod_hashes_only() {
+        let go_sum = "github.com/gin-gonic/gin v1.9.0/go.mod h1:W1Me9+hsUSyj3CePGrd1/QrKJMSJ1Tu/0hFEH89961k=\n\
+                      github.com/gin-gonic/gin v1.9.1/go.mod h1:hPrL7YrpYKXt5YId3A/Tnip5kqbEAP+KLuI3SUcPTeU=\n";
+        assert_eq!(
+            resolve_version("github.com/gin-gonic/gin", go_sum).unwrap(),
+            "v1.9.1"
+        );
+    }
+
+    #[test]
+    fn test_semver_order() {
+        assert!(semver_key("v1.10.0") > semver_key("v1.9.1"));
+        assert!(semver_key("v1.9.0-rc.1") < semver_key("v1.9.0"));
+    }
+
+    #[test]
+    fn test_resolve_from_lockfiles() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "web/package-lock.json",
+            r#"{"lockfileVersion": 3, "packages": {"": {}, "node_modules/express": {"version": "4.18.2"}}}"#,
+        );
+        fs.add_file(
+            "Cargo.lock",
+            "version = 3\n\n[[package]]\nname = \"axum\"\nversion = \"0.7.5\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n\n[[package]]\nname = \"axum-core\"\nversion = \"0.4.3\"\n",
+        );
+        fs.add_file(
+            "api/Pipfile.lock",
+            r#"{"default": {"fastapi": {"version": "==0.110.0"}}, "develop": {}}"#,
+        );
+        let tree = paths(&["Cargo.lock", "api/Pipfile.lock", "web/package-lock.json"]);
+        let resolve = |service: &str, package: &str| {
+            FrameworkVersionResolver::resolve(
+                Path::new(""),
+                Path::new(service),
+                &tree,
+                &fs,
+                package,
+            )
+        };
+
+        assert_eq!(resolve("web", "express").as_deref(), Some("4.18.2"));
+        // Workspace members share the root Cargo.lock
+        assert_eq!(resolve("crates/server", "axum").as_deref(), Some("0.7.5"));
+        assert_eq!(resolve("api", "FastAPI").as_deref(), Some("0.110.0"));
+        assert_eq!(resolve("web", "koa"), None);
+    }
+}
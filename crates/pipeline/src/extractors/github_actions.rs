@@ -0,0 +1,415 @@
+//! GitHub Actions detector - the build, test and deploy commands CI workflows run
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::CiCommand;
+use regex::Regex;
+use serde_yaml::Value;
+use std::path::{Path, PathBuf};
+
+/// `source` of every command taken from a workflow
+pub const CI_WORKFLOW_SOURCE: &str = "ci_workflow";
+
+/// Command patterns by kind, tried in order: `docker build` deploys rather than builds, and
+/// `mvn verify` tests rather than packages
+const COMMAND_PATTERNS: [(&str, &str); 3] = [
+    (
+        "deploy",
+        r"^(?:docker\s+(?:buildx\s+)?(?:build|push)|kubectl\s+(?:apply|set\s+image|rollout)|helm\s+(?:upgrade|install)|(?:fly|flyctl)\s+deploy|gcloud\s.*\bdeploy|aws\s+ecs\s+update-service|terraform\s+apply|goreleaser\s+release|ko\s+(?:apply|build|publish))\b",
+    ),
+    (
+        "test",
+        r"^(?:go\s+test|make\s+(?:test|check)|(?:npm|pnpm|yarn|bun)\s+(?:run\s+)?test|cargo\s+(?:test|nextest)|pytest|python3?\s+-m\s+pytest|(?:mvn|\./mvnw)\s.*\b(?:test|verify)|(?:gradle|\./gradlew)\s.*\btest|dotnet\s+test|bundle\s+exec\s+(?:rspec|rake\s+test)|mix\s+test)\b",
+    ),
+    (
+        "build",
+        r"^(?:go\s+build|make\s+build|make$|(?:npm|pnpm|yarn|bun)\s+run\s+build|cargo\s+build|(?:mvn|\./mvnw)\s.*\b(?:package|install)|(?:gradle|\./gradlew)\s.*\b(?:build|assemble)|dotnet\s+(?:build|publish)|goreleaser\s+build)(?:\s|$)",
+    ),
+];
+
+/// A Go version an `actions/setup-go` step installs
+#[derive(Debug, Clone, PartialEq)]
+pub struct CiGoVersion {
+    pub version: String,
+    /// Workflow file, relative to the repository
+    pub workflow: String,
+}
+
+/// What the repository's workflows run for a service
+#[derive(Debug, Clone, PartialEq, Default)]
+pub struct CiWorkflows {
+    /// Unique commands in workflow, job and step order
+    pub commands: Vec<CiCommand>,
+    /// Go versions set up by any job, including every version of a build matrix
+    pub go_versions: Vec<CiGoVersion>,
+}
+
+impl CiWorkflows {
+    /// Whether a workflow runs `command` as `kind`, comparing the program and its subcommand
+    /// (`go build -o app .` confirms `go build -o server ./cmd/server`)
+    pub fn confirms(&self, kind: &str, command: &str) -> bool {
+        fn head(command: &str) -> Vec<&str> {
+            without_env(command).split_whitespace().take(2).collect()
+        }
+        self.commands
+            .iter()
+            .any(|ci| ci.kind == kind && head(&ci.command) == head(command))
+    }
+}
+
+pub struct GitHubActionsDetector;
+
+impl GitHubActionsDetector {
+    /// Reads every `.github/workflows/*.yml` among `file_tree` (repository-relative), keeping
+    /// the `run:` commands executed in the service directory
+    ///
+    /// A step runs in its `working-directory`, else the job's or the workflow's
+    /// `defaults.run.working-directory`, else the repository root; a leading `cd <dir> &&`
+    /// moves it further.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<CiWorkflows> {
+        let patterns: Vec<(&str, Regex)> = COMMAND_PATTERNS
+            .iter()
+            .map(|(kind, pattern)| (*kind, Regex::new(pattern).expect("valid command regex")))
+            .collect();
+        let service_dir = normalize_dir(&service_path.to_string_lossy());
+
+        let mut workflows: Vec<&PathBuf> = file_tree
+            .iter()
+            .filter(|path| {
+                path.parent() == Some(Path::new(".github/workflows"))
+                    && path
+                        .extension()
+                        .is_some_and(|ext| ext == "yml" || ext == "yaml")
+            })
+            .collect();
+        if workflows.is_empty() {
+            return None;
+        }
+        workflows.sort();
+
+        let mut ci = CiWorkflows::default();
+        for path in workflows {
+            let Some(workflow) = fs
+                .read_to_string(&repo_path.join(path))
+                .ok()
+                .and_then(|content| serde_yaml::from_str::<Value>(&content).ok())
+            else {
+                continue;
+            };
+            let workflow_file = path.to_string_lossy().replace('\\', "/");
+            let Some(jobs) = workflow.get("jobs").and_then(Value::as_mapping) else {
+                continue;
+            };
+            let workflow_dir = default_working_directory(&workflow);
+
+            for job in jobs.values() {
+                let job_dir = default_working_directory(job).or_else(|| workflow_dir.clone());
+                let steps = job.get("steps").and_then(Value::as_sequence);
+                for step in steps.into_iter().flatten() {
+                    let uses = step.get("uses").and_then(Value::as_str);
+                    if uses.is_some_and(|uses| uses.starts_with("actions/setup-go@")) {
+                        for version in setup_go_versions(step, job) {
+                            let version = CiGoVersion {
+                                version,
+                                workflow: workflow_file.clone(),
+                            };
+                            if !ci.go_versions.contains(&version) {
+                                ci.go_versions.push(version);
+                            }
+                        }
+                    }
+
+                    let Some(run) = step.get("run").and_then(Value::as_str) else {
+                        continue;
+                    };
+                    let step_dir = step
+                        .get("working-directory")
+                        .and_then(Value::as_str)
+                        .map(normalize_dir)
+                        .or_else(|| job_dir.clone())
+                        .unwrap_or_default();
+
+                    for line in run_lines(run) {
+                        let (dir, command) = match line
+                            .strip_prefix("cd ")
+                            .and_then(|rest| rest.split_once("&&"))
+                        {
+                            Some((dir, command)) => {
+                                (join_dir(&step_dir, dir.trim()), command.trim().to_string())
+                            }
+                            None => (step_dir.clone(), line),
+                        };
+                        if dir != service_dir {
+                            continue;
+                        }
+                        let Some(kind) = patterns
+                            .iter()
+                            .find(|(_, pattern)| pattern.is_match(without_env(&command)))
+                            .map(|(kind, _)| kind.to_string())
+                        else {
+                            continue;
+                        };
+                        if !ci
+                            .commands
+                            .iter()
+                            .any(|ci| ci.kind == kind && ci.command == command)
+                        {
+                            ci.commands.push(CiCommand {
+                                kind,
+                                command,
+                                source: CI_WORKFLOW_SOURCE.to_string(),
+                                workflow: workflow_file.clone(),
+                            });
+                        }
+                    }
+                }
+            }
+        }
+
+        (ci != CiWorkflows::default()).then_some(ci)
+    }
+}
+
+fn default_working_directory(scope: &Value) -> Option<String> {
+    scope
+        .get("defaults")?
+        .get("run")?
+        .get("working-directory")?
+        .as_str()
+        .map(normalize_dir)
+}
+
+/// `go-version` of a setup-go step; `${{ matrix.<key> }}` expands to the job's matrix values
+fn setup_go_versions(step: &Value, job: &Value) -> Vec<String> {
+    let Some(version) = step.get("with").and_then(|with| with.get("go-version")) else {
+        return vec![];
+    };
+    let versions = match scalar(version) {
+        Some(version) if version.starts_with("${{") => {
+            let key = version
+                .trim_start_matches("${{")
+                .trim_end_matches("}}")
+                .trim()
+                .strip_prefix("matrix.")
+                .unwrap_or_default()
+                .to_string();
+            job.get("strategy")
+                .and_then(|strategy| strategy.get("matrix"))
+                .and_then(|matrix| matrix.get(key.as_str()))
+                .and_then(Value::as_sequence)
+                .map(|values| values.iter().filter_map(scalar).collect())
+                .unwrap_or_default()
+        }
+        Some(version) => vec![version],
+        None => vec![],
+    };
+    // `stable` and `oldstable` follow Go releases rather than pinning one
+    versions
+        .into_iter()
+        .filter(|version| version.starts_with(|c: char| c.is_ascii_digit()))
+        .collect()
+}
+
+/// YAML reads `go-version: 1.20` as the number 1.2, which is what setup-go then installs
+fn scalar(value: &Value) -> Option<String> {
+    match value {
+        Value::String(value) => Some(value.trim().to_string()),
+        Value::Number(value) => Some(value.to_string()),
+        _ => None,
+    }
+}
+
+/// Commands of a `run:` script, with `\` continuations joined and comments dropped
+fn run_lines(run: &str) -> Vec<String> {
+    let mut lines = Vec::new();
+    let mut current = String::new();
+    for line in run.lines() {
+        let line = line.trim();
+        if line.starts_with('#') {
+            continue;
+        }
+        match line.strip_suffix('\\') {
+            Some(continued) => {
+                current.push_str(continued.trim());
+                current.push(' ');
+            }
+            None => {
+                current.push_str(line);
+                let command = current.trim().to_string();
+                if !command.is_empty() {
+                    lines.push(command);
+                }
+                current.clear();
+            }
+        }
+    }
+    lines
+}
+
+/// A command without its leading `NAME=value` environment assignments
+fn without_env(command: &str) -> &str {
+    let mut rest = command.trim_start();
+    while let Some((word, tail)) = rest.split_once(char::is_whitespace) {
+        let assignment = word.split_once('=').is_some_and(|(name, _)| {
+            !name.is_empty() && name.chars().all(|c| c == '_' || c.is_ascii_alphanumeric())
+        });
+        if !assignment {
+            break;
+        }
+        rest = tail.trim_start();
+    }
+    rest
+}
+
+fn normalize_dir(dir: &str) -> String {
+    dir.split('/')
+        .filter(|part| !part.is_empty() && *part != ".")
+        .collect::<Vec<_>>()
+        .join("/")
+}
+
+fn join_dir(base: &str, dir: &str) -> String {
+    normalize_dir(&format!("{}/{}", base, dir))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const CI: &str = r#"name: CI
+on:
+  push:
+    branches: [main]
+  pull_request:
+
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        go: ["1.21", "1.22"]
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+        with:
+          go-version: ${{ matrix.go }}
+      - run: go vet ./...
+      - run: go test -race ./...
+      - name: Build
+        run: |
+          # static binary for the image
+          CGO_ENABLED=0 go build -o bin/api ./cmd/api
+          go build \
+            -o bin/worker ./cmd/worker
+  image:
+    needs: test
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: docker build -t $IMAGE .
+      - run: docker push $IMAGE
+  web:
+    runs-on: ubuntu-latest
+    defaults:
+      run:
+        working-directory: ./web
+    steps:
+      - run: npm ci
+      - run: npm test
+"#;
+
+    fn detect(files: &[(&str, &str)], service_path: &str) -> Option<CiWorkflows> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        GitHubActionsDetector::detect(Path::new(""), Path::new(service_path), &tree, &fs)
+    }
+
+    fn command(kind: &str, command: &str) -> CiCommand {
+        CiCommand {
+            kind: kind.to_string(),
+            command: command.to_string(),
+            source: CI_WORKFLOW_SOURCE.to_string(),
+            workflow: ".github/workflows/ci.yml".to_string(),
+        }
+    }
+
+    #[test]
+    fn test_go_workflow() {
+        let ci = detect(&[(".github/workflows/ci.yml", CI)], "").unwrap();
+        assert_eq!(
+            ci.commands,
+            vec![
+                command("test", "go test -race ./..."),
+                command("build", "CGO_ENABLED=0 go build -o bin/api ./cmd/api"),
+                command("build", "go build -o bin/worker ./cmd/worker"),
+                command("deploy", "docker build -t $IMAGE ."),
+                command("deploy", "docker push $IMAGE"),
+            ]
+        );
+        assert_eq!(
+            ci.go_versions
+                .iter()
+                .map(|v| v.version.as_str())
+                .collect::<Vec<_>>(),
+            vec!["1.21", "1.22"]
+        );
+        assert!(ci.confirms("test", "go test ./..."));
+        assert!(ci.confirms("build", "go build -o app ."));
+        assert!(!ci.confirms("build", "cargo build --release"));
+    }
+
+    #[test]
+    fn test_working_directory_scopes_commands() {
+        let ci = detect(
+            &[
+                (".github/workflows/ci.yml", CI),
+                (
+                    ".github/workflows/release.yaml",
+                    "jobs:\n  release:\n    steps:\n      - uses: actions/setup-go@v5\n        with:\n          go-version: 1.20\n      - run: cd web && npm run build\n",
+                ),
+            ],
+            "web",
+        )
+        .unwrap();
+        assert_eq!(
+            ci.commands
+                .iter()
+                .map(|c| (c.kind.as_str(), c.command.as_str(), c.workflow.as_str()))
+                .collect::<Vec<_>>(),
+            vec![
+                ("test", "npm test", ".github/workflows/ci.yml"),
+                ("build", "npm run build", ".github/workflows/release.yaml"),
+            ]
+        );
+        assert_eq!(
+            ci.go_versions.last(),
+            Some(&CiGoVersion {
+                version: "1.2".to_string(),
+                workflow: ".github/workflows/release.yaml".to_string(),
+            })
+        );
+    }
+
+    #[test]
+    fn test_no_workflows() {
+        assert_eq!(detect(&[("go.mod", "module example.com/app\n")], ""), None);
+        assert_eq!(
+            detect(
+                &[(
+                    ".github/workflows/lint.yml",
+                    "jobs:\n  lint:\n    steps:\n      - run: golangci-lint run\n"
+                )],
+                ""
+            ),
+            None
+        );
+    }
+}
@@ -0,0 +1,261 @@
+//! go:generate detector - code generation that has to run before `go build`
+
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+/// Generators run by their binary name and the module that installs them
+pub(crate) const KNOWN_GENERATORS: [(&str, &str); 9] = [
+    ("mockgen", "go.uber.org/mock/mockgen"),
+    ("wire", "github.com/google/wire/cmd/wire"),
+    ("stringer", "golang.org/x/tools/cmd/stringer"),
+    (
+        "protoc-gen-go",
+        "google.golang.org/protobuf/cmd/protoc-gen-go",
+    ),
+    (
+        "protoc-gen-go-grpc",
+        "google.golang.org/grpc/cmd/protoc-gen-go-grpc",
+    ),
+    ("sqlc", "github.com/sqlc-dev/sqlc/cmd/sqlc"),
+    ("swag", "github.com/swaggo/swag/cmd/swag"),
+    (
+        "oapi-codegen",
+        "github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen",
+    ),
+    ("gqlgen", "github.com/99designs/gqlgen"),
+];
+
+/// Generation steps found in a Go service and the tools they need
+#[derive(Debug, Clone, PartialEq, Default)]
+pub struct GoGenerate {
+    /// Unique `//go:generate` commands in file order, prefixed with `cd <dir> &&` when the
+    /// directive lives below the service root, since `go generate` runs each in its own package
+    pub commands: Vec<String>,
+    /// Tools the commands invoke: a module path for Go generators, else the binary name
+    pub tools: Vec<String>,
+}
+
+pub struct GoGenerateDetector;
+
+impl GoGenerateDetector {
+    /// Collects `//go:generate` directives from the service's `.go` files among `file_tree`
+    /// (repository-relative)
+    ///
+    /// Packages with a `wire.go` injector need `wire` even when no directive asks for it.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<GoGenerate> {
+        let directive =
+            Regex::new(r"(?m)^//go:generate\s+(.+?)\s*$").expect("valid directive regex");
+        let mut generate = GoGenerate::default();
+        let mut wire_dirs = Vec::new();
+
+        for relative in file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| path.extension().is_some_and(|ext| ext == "go"))
+        {
+            let dir = relative
+                .parent()
+                .map(|dir| dir.to_string_lossy().replace('\\', "/"))
+                .unwrap_or_default();
+            let Ok(content) = fs.read_to_string(&repo_path.join(service_path).join(relative))
+            else {
+                continue;
+            };
+
+            for caps in directive.captures_iter(&content) {
+                generate.add(&dir, &caps[1]);
+            }
+            if relative.file_name().is_some_and(|name| name == "wire.go") {
+                wire_dirs.push(dir);
+            }
+        }
+
+        let wire = generator_module("wire");
+        for dir in wire_dirs {
+            let prefix = in_dir(&dir, "");
+            let runs_wire = generate.commands.iter().any(|command| {
+                command.starts_with(&prefix) && command_tools(command).iter().any(|t| t == wire)
+            });
+            if !runs_wire {
+                generate.add(&dir, "wire");
+            }
+        }
+
+        (!generate.commands.is_empty()).then_some(generate)
+    }
+}
+
+impl GoGenerate {
+    fn add(&mut self, dir: &str, command: &str) {
+        let command = in_dir(dir, command);
+        if self.commands.contains(&command) {
+            return;
+        }
+        for tool in command_tools(&command) {
+            if !self.tools.contains(&tool) {
+                self.tools.push(tool);
+            }
+        }
+        self.commands.push(command);
+    }
+}
+
+fn in_dir(dir: &str, command: &str) -> String {
+    if dir.is_empty() {
+        command.to_string()
+    } else {
+        format!("cd {} && {}", dir, command)
+    }
+}
+
+/// Tools a directive needs: `go run <module>` needs the module, `protoc` its Go plugins
+fn command_tools(command: &str) -> Vec<String> {
+    let command = command.rsplit("&& ").next().unwrap_or(command);
+    let args: Vec<&str> = command.split_whitespace().collect();
+    let Some(&program) = args.first() else {
+        return vec![];
+    };
+
+    match program {
+        // `go generate ./...` recurses into directives already collected
+        "go" if args.get(1) == Some(&"generate") => vec![],
+        // `go tool` runs a tool go.mod already pins
+        "go" if args.get(1) == Some(&"tool") => vec![],
+        "go" if args.get(1) == Some(&"run") => args[2..]
+            .iter()
+            .find(|arg| !arg.starts_with('-'))
+            .filter(|package| !package.ends_with(".go"))
+            .map(|package| vec![package.split('@').next().unwrap_or(package).to_string()])
+            .unwrap_or_default(),
+        "protoc" => {
+            let mut tools = vec!["protoc".to_string()];
+            for (flag, plugin) in [
+                ("--go_out", "protoc-gen-go"),
+                ("--go-grpc_out", "protoc-gen-go-grpc"),
+            ] {
+                if args.iter().any(|arg| arg.starts_with(flag)) {
+                    tools.push(generator_module(plugin).to_string());
+                }
+            }
+            tools
+        }
+        other => vec![generator_module(other).to_string()],
+    }
+}
+
+fn generator_module(program: &str) -> &str {
+    KNOWN_GENERATORS
+        .iter()
+        .find(|(name, _)| *name == program)
+        .map(|(_, module)| *module)
+        .unwrap_or(program)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    #[test]
+    fn test_collects_directives_across_files_and_directories() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "gen.go",
+            "package main\n\n//go:generate go generate ./...\n",
+        );
+        fs.add_file(
+            "internal/store/store.go",
+            "package store\n\n//go:generate mockgen -source=store.go -destination=mock_store.go -package=store\n",
+        );
+        fs.add_file(
+            "internal/store/kind.go",
+            "package store\n\n//go:generate stringer -type=Kind\ntype Kind int\n",
+        );
+        fs.add_file(
+            "internal/status/status.go",
+            "package status\n\n//go:generate stringer -type=Kind\n",
+        );
+        fs.add_file(
+            "api/api.go",
+            "package api\n\n//go:generate protoc --go_out=. --go-grpc_out=. api.proto\n//go:generate go run github.com/99designs/gqlgen@v0.17.45 generate\n",
+        );
+        let tree = paths(&[
+            "api/api.go",
+            "gen.go",
+            "internal/status/status.go",
+            "internal/store/kind.go",
+            "internal/store/store.go",
+        ]);
+
+        let generate =
+            GoGenerateDetector::detect(Path::new(""), Path::new(""), &tree, &fs).unwrap();
+        assert_eq!(
+            generate.commands,
+            vec![
+                "cd api && protoc --go_out=. --go-grpc_out=. api.proto",
+                "cd api && go run github.com/99designs/gqlgen@v0.17.45 generate",
+                "go generate ./...",
+                "cd internal/status && stringer -type=Kind",
+                "cd internal/store && stringer -type=Kind",
+                "cd internal/store && mockgen -source=store.go -destination=mock_store.go -package=store",
+            ]
+        );
+        assert_eq!(
+            generate.tools,
+            vec![
+                "protoc",
+                "google.golang.org/protobuf/cmd/protoc-gen-go",
+                "google.golang.org/grpc/cmd/protoc-gen-go-grpc",
+                "github.com/99designs/gqlgen",
+                "golang.org/x/tools/cmd/stringer",
+                "go.uber.org/mock/mockgen",
+            ]
+        );
+    }
+
+    #[test]
+    fn test_wire_injector_without_directive() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "services/api/cmd/server/wire.go",
+            "//go:build wireinject\n\npackage main\n",
+        );
+        fs.add_file("services/api/cmd/server/main.go", "package main\n");
+        let tree = paths(&[
+            "services/api/cmd/server/main.go",
+            "services/api/cmd/server/wire.go",
+        ]);
+
+        let generate =
+            GoGenerateDetector::detect(Path::new(""), Path::new("services/api"), &tree, &fs)
+                .unwrap();
+        assert_eq!(generate.commands, vec!["cd cmd/server && wire"]);
+        assert_eq!(generate.tools, vec!["github.com/google/wire/cmd/wire"]);
+    }
+
+    #[test]
+    fn test_no_directives() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "main.go",
+            "package main\n\n// go:generate is not a directive with a space\n",
+        );
+        assert!(GoGenerateDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["main.go"]),
+            &fs
+        )
+        .is_none());
+    }
+}
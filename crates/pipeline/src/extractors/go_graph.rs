@@ -0,0 +1,403 @@
+//! Go module graph - modules of a go.mod/go.sum pair and the requirements between them
+
+use super::framework_version::semver_key;
+use super::go_replace::{ReplaceDirectiveAnalyzer, ReplaceKind};
+use anyhow::{anyhow, bail, Result};
+use std::collections::HashMap;
+
+/// A node of the graph: a module path and the version the build selects
+///
+/// The main module and directory replacements have no version.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Module {
+    pub path: String,
+    pub version: Option<String>,
+}
+
+/// Why one module points at another
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum DependencyKind {
+    /// A `require` the main module's code imports
+    Direct,
+    /// A `require` marked `// indirect`, needed only by other dependencies
+    Indirect,
+    /// A `replace` directive, from the replaced module to its replacement
+    Replace,
+}
+
+/// Directed graph of a Go module's dependencies, in go.mod order
+///
+/// go.sum records every module the build graph touched but not who requires it, so modules
+/// only found there are nodes without incoming edges.
+#[derive(Debug, Clone, PartialEq)]
+pub struct DependencyGraph {
+    modules: Vec<Module>,
+    edges: Vec<(usize, usize, DependencyKind)>,
+    index: HashMap<String, usize>,
+}
+
+/// Builds the graph of the main module declared by `go_mod`
+///
+/// Fails when go.mod has no `module` directive or go.sum has a line that is not
+/// `<module> <version>[/go.mod] <hash>`.
+pub fn build_dependency_graph(go_mod: &str, go_sum: &str) -> Result<DependencyGraph> {
+    let main = go_mod
+        .lines()
+        .find_map(|line| {
+            let line = line.split("//").next().unwrap_or_default().trim();
+            line.strip_prefix("module ")
+        })
+        .map(unquote)
+        .ok_or_else(|| anyhow!("go.mod has no module directive"))?;
+
+    let mut graph = DependencyGraph {
+        modules: Vec::new(),
+        edges: Vec::new(),
+        index: HashMap::new(),
+    };
+    let root = graph.add(&main, None);
+
+    for (path, version, indirect) in requirements(go_mod) {
+        let module = graph.add(&path, Some(version));
+        let kind = if indirect {
+            DependencyKind::Indirect
+        } else {
+            DependencyKind::Direct
+        };
+        graph.edges.push((root, module, kind));
+    }
+
+    for directive in ReplaceDirectiveAnalyzer::analyze(go_mod) {
+        // `replace m => m v1.2.3` only pins another version of the same module
+        if directive.target == directive.module {
+            continue;
+        }
+        let replaced = graph.add(&directive.module, directive.version);
+        let version = match directive.kind {
+            ReplaceKind::Remote => directive.target_version,
+            ReplaceKind::Local => None,
+        };
+        let replacement = graph.add(&directive.target, version);
+        graph
+            .edges
+            .push((replaced, replacement, DependencyKind::Replace));
+    }
+
+    let mut recorded: Vec<_> = sum_versions(go_sum)?.into_iter().collect();
+    recorded.sort();
+    for (path, version) in recorded {
+        graph.add(path, Some(version.to_string()));
+    }
+
+    Ok(graph)
+}
+
+impl DependencyGraph {
+    /// Every node, the main module first
+    pub fn modules(&self) -> &[Module] {
+        &self.modules
+    }
+
+    /// The main module's requirements that are not marked `// indirect`, in go.mod order
+    pub fn direct_dependencies(&self) -> Vec<Module> {
+        self.edges
+            .iter()
+            .filter(|(from, _, kind)| *from == 0 && *kind == DependencyKind::Direct)
+            .map(|(_, to, _)| self.modules[*to].clone())
+            .collect()
+    }
+
+    /// Every module besides the main one: requirements, replacements and modules go.sum
+    /// records, sorted by path
+    pub fn all_transitive_dependencies(&self) -> Vec<Module> {
+        let mut modules: Vec<Module> = self.modules[1..].to_vec();
+        modules.sort_by(|a, b| a.path.cmp(&b.path));
+        modules
+    }
+
+    /// Elementary cycles as module paths, each starting at the module declared first
+    ///
+    /// Requirements only leave the main module, so cycles come from `replace` directives that
+    /// lead back to a module already replaced, or to the main module itself.
+    pub fn find_cycles(&self) -> Vec<Vec<String>> {
+        let mut successors = vec![Vec::new(); self.modules.len()];
+        for (from, to, _) in &self.edges {
+            successors[*from].push(*to);
+        }
+
+        let mut cycles = Vec::new();
+        for start in 0..self.modules.len() {
+            let mut path = vec![start];
+            self.walk(start, &successors, &mut path, &mut cycles);
+        }
+        cycles
+    }
+
+    /// Extends `path` through modules declared after its first one, so each cycle is only
+    /// found from its earliest module
+    fn walk(
+        &self,
+        start: usize,
+        successors: &[Vec<usize>],
+        path: &mut Vec<usize>,
+        cycles: &mut Vec<Vec<String>>,
+    ) {
+        let current = *path.last().expect("path starts non-empty");
+        for &next in &successors[current] {
+            if next == start {
+                cycles.push(path.iter().map(|&i| self.modules[i].path.clone()).collect());
+            } else if next > start && !path.contains(&next) {
+                path.push(next);
+                self.walk(start, successors, path, cycles);
+                path.pop();
+            }
+        }
+    }
+
+    /// Graphviz rendering: indirect requirements are dashed, replacements labelled
+    pub fn to_dot(&self) -> String {
+        let mut dot = format!("digraph {} {{\n", quote(&self.modules[0].path));
+        for module in &self.modules {
+            dot.push_str(&match &module.version {
+                Some(version) => format!(
+                    "  {} [label={}];\n",
+                    quote(&module.path),
+                    quote(&format!("{}@{}", module.path, version))
+                ),
+                None => format!("  {};\n", quote(&module.path)),
+            });
+        }
+        for (from, to, kind) in &self.edges {
+            let attributes = match kind {
+                DependencyKind::Direct => "",
+                DependencyKind::Indirect => " [style=dashed]",
+                DependencyKind::Replace => " [label=\"replace\"]",
+            };
+            dot.push_str(&format!(
+                "  {} -> {}{};\n",
+                quote(&self.modules[*from].path),
+                quote(&self.modules[*to].path),
+                attributes
+            ));
+        }
+        dot.push_str("}\n");
+        dot
+    }
+
+    /// Index of the node for `path`, added with `version` when it is new
+    fn add(&mut self, path: &str, version: Option<String>) -> usize {
+        if let Some(&index) = self.index.get(path) {
+            return index;
+        }
+        self.modules.push(Module {
+            path: path.to_string(),
+            version,
+        });
+        self.index.insert(path.to_string(), self.modules.len() - 1);
+        self.modules.len() - 1
+    }
+}
+
+/// `(module, version, indirect)` of the single-line and block `require` directives
+fn requirements(go_mod: &str) -> Vec<(String, String, bool)> {
+    let mut requirements = Vec::new();
+    let mut in_block = false;
+
+    for line in go_mod.lines() {
+        let (code, comment) = line.split_once("//").unwrap_or((line, ""));
+        let code = code.trim();
+        let requirement = if in_block {
+            if code == ")" {
+                in_block = false;
+                continue;
+            }
+            code
+        } else if let Some(rest) = code.strip_prefix("require") {
+            let rest = rest.trim_start();
+            if rest.starts_with('(') {
+                in_block = true;
+                continue;
+            }
+            rest
+        } else {
+            continue;
+        };
+
+        let mut fields = requirement.split_whitespace();
+        if let (Some(path), Some(version)) = (fields.next(), fields.next()) {
+            let indirect = comment.trim().starts_with("indirect");
+            requirements.push((unquote(path), version.to_string(), indirect));
+        }
+    }
+
+    requirements
+}
+
+/// Version of each go.sum module that minimal version selection builds, as in
+/// [`resolve_version`](super::resolve_version), in a single pass
+fn sum_versions(go_sum: &str) -> Result<HashMap<&str, &str>> {
+    let mut selected: HashMap<&str, (&str, bool)> = HashMap::new();
+    for (number, line) in go_sum.lines().enumerate() {
+        if line.trim().is_empty() {
+            continue;
+        }
+        let fields: Vec<&str> = line.split_whitespace().collect();
+        let [module, version, hash] = fields[..] else {
+            bail!("go.sum line {} is malformed: {}", number + 1, line);
+        };
+        if !hash.contains(':') {
+            bail!("go.sum line {} has no hash: {}", number + 1, line);
+        }
+
+        let (version, downloaded) = match version.strip_suffix("/go.mod") {
+            Some(version) => (version, false),
+            None => (version, true),
+        };
+        let entry = selected.entry(module).or_insert((version, downloaded));
+        let better = (downloaded, semver_key(version)) > (entry.1, semver_key(entry.0));
+        if better {
+            *entry = (version, downloaded);
+        }
+    }
+    Ok(selected
+        .into_iter()
+        .map(|(module, (version, _))| (module, version))
+        .collect())
+}
+
+fn unquote(value: &str) -> String {
+    value.trim_matches(|c| c == '"' || c == '`').to_string()
+}
+
+/// A DOT identifier in double quotes
+fn quote(value: &str) -> String {
+    format!("\"{}\"", value.replace('\\', "\\\\").replace('"', "\\\""))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const GO_MOD: &str = r#"module example.com/app
+
+go 1.22
+
+require github.com/gin-gonic/gin v1.9.1
+
+require (
+	github.com/pkg/errors v0.9.1
+	golang.org/x/net v0.10.0 // indirect
+)
+
+replace github.com/pkg/errors v0.9.1 => github.com/acme/errors v0.9.2
+
+replace example.com/shared => ../shared
+"#;
+
+    const GO_SUM: &str =
+        "github.com/acme/errors v0.9.2 h1:Y/yl/+YNO8GZSjAhjMsSuLt29uWRFHdHYUb5lYOV9qE=\n\
+github.com/bytedance/sonic v1.9.1/go.mod h1:RHrZQHXnP2xjPF+u1gW/2HnVO7nvIa9PG3Gm+fLHvGI=\n\
+github.com/gin-gonic/gin v1.9.1 h1:4idEAncQnU5cB7BeOkPtxjfCSye0AAm1R0RVIqJ+Jmg=\n\
+golang.org/x/net v0.10.0 h1:0qNGK6F8kojg2nk9dLZ2mShWaEBan6FAoqfSigmmuDg=\n\
+golang.org/x/net v0.9.0/go.mod h1:d48xBJpPfHeWQsugry2m+kC02ZBRGRgulfHnEXEuWns=\n\
+golang.org/x/sys v0.8.0 h1:EBmGv8NaZBZTWvrbjNoL6HVt+IVy3QDQpJs7VRIw3tU=\n\
+golang.org/x/sys v0.10.0 h1:SqMFp9UcQJZa+pmYuAKjd9xq1f0j5rLcDIk0mj4qAsA=\n";
+
+    fn module(path: &str, version: Option<&str>) -> Module {
+        Module {
+            path: path.to_string(),
+            version: version.map(str::to_string),
+        }
+    }
+
+    #[test]
+    fn test_direct_and_transitive_dependencies() {
+        let graph = build_dependency_graph(GO_MOD, GO_SUM).unwrap();
+        assert_eq!(graph.modules()[0], module("example.com/app", None));
+        assert_eq!(
+            graph.direct_dependencies(),
+            vec![
+                module("github.com/gin-gonic/gin", Some("v1.9.1")),
+                module("github.com/pkg/errors", Some("v0.9.1")),
+            ]
+        );
+
+        let all: Vec<(String, Option<String>)> = graph
+            .all_transitive_dependencies()
+            .into_iter()
+            .map(|m| (m.path, m.version))
+            .collect();
+        let expected = [
+            ("../shared", None),
+            ("example.com/shared", None),
+            ("github.com/acme/errors", Some("v0.9.2")),
+            ("github.com/bytedance/sonic", Some("v1.9.1")),
+            ("github.com/gin-gonic/gin", Some("v1.9.1")),
+            ("github.com/pkg/errors", Some("v0.9.1")),
+            ("golang.org/x/net", Some("v0.10.0")),
+            ("golang.org/x/sys", Some("v0.10.0")),
+        ];
+        let expected: Vec<(String, Option<String>)> = expected
+            .iter()
+            .map(|(path, version)| (path.to_string(), version.map(str::to_string)))
+            .collect();
+        assert_eq!(all, expected);
+        assert!(graph.find_cycles().is_empty());
+    }
+
+    #[test]
+    fn test_replace_cycles() {
+        let go_mod = "module example.com/app\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v1.0.0\n)\n\nreplace example.com/a => example.com/b v1.0.0\nreplace example.com/b => example.com/a v1.0.0\nreplace example.com/c => example.com/app v0.0.0\nreplace example.com/d => example.com/d v1.1.0\n";
+        let graph = build_dependency_graph(go_mod, "").unwrap();
+        assert_eq!(
+            graph.find_cycles(),
+            vec![vec![
+                "example.com/a".to_string(),
+                "example.com/b".to_string()
+            ]]
+        );
+
+        let go_mod = "module example.com/app\n\nrequire example.com/lib v1.0.0\n\nreplace example.com/lib => example.com/app v0.0.0\n";
+        let graph = build_dependency_graph(go_mod, "").unwrap();
+        assert_eq!(
+            graph.find_cycles(),
+            vec![vec![
+                "example.com/app".to_string(),
+                "example.com/lib".to_string()
+            ]]
+        );
+    }
+
+    #[test]
+    fn test_to_dot() {
+        let go_mod = "module example.com/app\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.9.1\n\tgolang.org/x/net v0.10.0 // indirect\n)\n\nreplace github.com/gin-gonic/gin => ../gin\n";
+        let graph = build_dependency_graph(go_mod, "").unwrap();
+        assert_eq!(
+            graph.to_dot(),
+            r#"digraph "example.com/app" {
+  "example.com/app";
+  "github.com/gin-gonic/gin" [label="github.com/gin-gonic/gin@v1.9.1"];
+  "golang.org/x/net" [label="golang.org/x/net@v0.10.0"];
+  "../gin";
+  "example.com/app" -> "github.com/gin-gonic/gin";
+  "example.com/app" -> "golang.org/x/net" [style=dashed];
+  "github.com/gin-gonic/gin" -> "../gin" [label="replace"];
+}
+"#
+        );
+    }
+
+    #[test]
+    fn test_malformed_input() {
+        let error = build_dependency_graph("go 1.22\n", "").unwrap_err();
+        assert_eq!(error.to_string(), "go.mod has no module directive");
+
+        let error =
+            build_dependency_graph("module example.com/app\n", "\ngolang.org/x/net v0.10.0\n")
+                .unwrap_err();
+        assert_eq!(
+            error.to_string(),
+            "go.sum line 2 is malformed: golang.org/x/net v0.10.0"
+        );
+    }
+}
@@ -0,0 +1,163 @@
+//! go.mod replace analyzer - module replacements that depend on the local checkout
+
+use peelbox_core::output::schema::GoReplacement;
+
+/// Where a `replace` directive points
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ReplaceKind {
+    /// Another module path and version, typically a fork
+    Remote,
+    /// A directory on disk, which has to exist wherever the module is built
+    Local,
+}
+
+/// A single `replace old [version] => new [version]` directive
+#[derive(Debug, Clone, PartialEq)]
+pub struct ReplaceDirective {
+    pub module: String,
+    pub version: Option<String>,
+    pub target: String,
+    pub target_version: Option<String>,
+    pub kind: ReplaceKind,
+}
+
+pub struct ReplaceDirectiveAnalyzer;
+
+impl ReplaceDirectiveAnalyzer {
+    /// Parses the single-line and block `replace` directives of a go.mod file
+    pub fn analyze(go_mod: &str) -> Vec<ReplaceDirective> {
+        let mut directives = Vec::new();
+        let mut in_block = false;
+
+        for line in go_mod.lines() {
+            let line = line.split("//").next().unwrap_or_default().trim();
+            let directive = if in_block {
+                if line == ")" {
+                    in_block = false;
+                    continue;
+                }
+                line
+            } else if let Some(rest) = line.strip_prefix("replace") {
+                let rest = rest.trim_start();
+                if rest.starts_with('(') {
+                    in_block = true;
+                    continue;
+                }
+                rest
+            } else {
+                continue;
+            };
+
+            if let Some(directive) = parse_directive(directive) {
+                directives.push(directive);
+            }
+        }
+
+        directives
+    }
+
+    /// Replacements pointing at directories, in go.mod order
+    pub fn local_replacements(go_mod: &str) -> Vec<GoReplacement> {
+        Self::analyze(go_mod)
+            .into_iter()
+            .filter(|directive| directive.kind == ReplaceKind::Local)
+            .map(|directive| GoReplacement {
+                module: directive.module,
+                path: directive.target,
+            })
+            .collect()
+    }
+}
+
+fn parse_directive(directive: &str) -> Option<ReplaceDirective> {
+    let (old, new) = directive.split_once("=>")?;
+    let mut old = old.split_whitespace();
+    let mut new = new.split_whitespace();
+    let module = unquote(old.next()?);
+    let target = unquote(new.next()?);
+
+    // Go treats the right-hand side as a directory when it is an absolute or `./`/`../` path
+    let kind = if target.starts_with("./")
+        || target.starts_with("../")
+        || target.starts_with('/')
+        || target == "."
+        || target == ".."
+    {
+        ReplaceKind::Local
+    } else {
+        ReplaceKind::Remote
+    };
+
+    Some(ReplaceDirective {
+        module,
+        version: old.next().map(unquote),
+        target,
+        target_version: new.next().map(unquote),
+        kind,
+    })
+}
+
+fn unquote(value: &str) -> String {
+    value.trim_matches(|c| c == '"' || c == '`').to_string()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const GO_MOD: &str = r#"module example.com/app
+
+go 1.21
+
+require (
+	github.com/pkg/errors v0.9.1
+	example.com/shared v0.0.0
+)
+
+replace github.com/pkg/errors v0.9.1 => github.com/acme/errors v0.9.2 // security fix
+
+replace (
+	example.com/shared => ../shared
+	example.com/tools => ./tools
+)
+"#;
+
+    #[test]
+    fn test_categorizes_replacements() {
+        let directives = ReplaceDirectiveAnalyzer::analyze(GO_MOD);
+        assert_eq!(
+            directives[0],
+            ReplaceDirective {
+                module: "github.com/pkg/errors".to_string(),
+                version: Some("v0.9.1".to_string()),
+                target: "github.com/acme/errors".to_string(),
+                target_version: Some("v0.9.2".to_string()),
+                kind: ReplaceKind::Remote,
+            }
+        );
+        let kinds: Vec<ReplaceKind> = directives.iter().map(|d| d.kind).collect();
+        assert_eq!(
+            kinds,
+            vec![ReplaceKind::Remote, ReplaceKind::Local, ReplaceKind::Local]
+        );
+    }
+
+    #[test]
+    fn test_local_replacements() {
+        let local = ReplaceDirectiveAnalyzer::local_replacements(GO_MOD);
+        let found: Vec<(&str, &str)> = local
+            .iter()
+            .map(|r| (r.module.as_str(), r.path.as_str()))
+            .collect();
+        assert_eq!(
+            found,
+            vec![
+                ("example.com/shared", "../shared"),
+                ("example.com/tools", "./tools")
+            ]
+        );
+        assert!(
+            ReplaceDirectiveAnalyzer::local_replacements("module example.com/app\n").is_empty()
+        );
+    }
+}
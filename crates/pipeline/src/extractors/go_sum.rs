@@ -0,0 +1,198 @@
+//! go.sum validator - checksums `go build` needs for every module go.mod requires
+
+use super::required_tools::go_mod_requirements;
+use peelbox_core::fs::FileSystem;
+use std::collections::HashSet;
+use std::path::{Path, PathBuf};
+
+/// `.env` variables that relax checksum verification for some or all modules
+const CHECKSUM_ENV_VARS: [&str; 3] = ["GONOSUMCHECK", "GONOSUMDB", "GOFLAGS"];
+
+/// How well the service's go.sum covers its go.mod requirements
+#[derive(Debug, Clone, PartialEq, Default)]
+pub struct GoSumStatus {
+    pub present: bool,
+    /// Present, or not needed because go.mod requires nothing
+    pub complete: bool,
+    /// Requirements without a go.sum line, as `module@version`
+    pub missing: Vec<String>,
+    /// Checksum-related variables set in the service's `.env`
+    pub env_hints: Vec<String>,
+}
+
+pub struct GoSumValidator;
+
+impl GoSumValidator {
+    /// Checks the go.sum next to the service's go.mod among `file_tree` (repository-relative)
+    ///
+    /// A requirement counts as recorded when go.sum has either its module hash or its
+    /// `/go.mod` hash. Returns `None` when the service has no go.mod.
+    pub fn validate<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<GoSumStatus> {
+        let read = |name: &str| {
+            file_tree
+                .iter()
+                .any(|path| path.strip_prefix(service_path).ok() == Some(Path::new(name)))
+                .then(|| {
+                    fs.read_to_string(&repo_path.join(service_path).join(name))
+                        .ok()
+                })
+                .flatten()
+        };
+
+        let requirements = go_mod_requirements(&read("go.mod")?);
+        let go_sum = read("go.sum");
+        let recorded: HashSet<(&str, &str)> = go_sum
+            .as_deref()
+            .unwrap_or_default()
+            .lines()
+            .filter_map(|line| {
+                let mut fields = line.split_whitespace();
+                let module = fields.next()?;
+                let version = fields.next()?;
+                Some((module, version.trim_end_matches("/go.mod")))
+            })
+            .collect();
+
+        let missing: Vec<String> = requirements
+            .iter()
+            .map(|(module, version)| (module, format!("v{}", version)))
+            .filter(|(module, version)| !recorded.contains(&(module.as_str(), version.as_str())))
+            .map(|(module, version)| format!("{}@{}", module, version))
+            .collect();
+
+        let env_hints = read(".env")
+            .map(|content| {
+                content
+                    .lines()
+                    .filter_map(|line| {
+                        let line = line.trim().trim_start_matches("export ");
+                        let (name, value) = line.split_once('=')?;
+                        let name = name.trim();
+                        let relaxes = CHECKSUM_ENV_VARS.contains(&name)
+                            && (name != "GOFLAGS" || value.contains("-mod=mod"));
+                        relaxes.then(|| name.to_string())
+                    })
+                    .collect()
+            })
+            .unwrap_or_default();
+
+        Some(GoSumStatus {
+            present: go_sum.is_some(),
+            complete: missing.is_empty(),
+            missing,
+            env_hints,
+        })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const GO_MOD: &str = "module example.com/app\n\ngo 1.22\n\nrequire github.com/gin-gonic/gin v1.9.1\n\nrequire (\n\tgithub.com/gin-contrib/sse v0.1.0 // indirect\n\tgolang.org/x/net v0.10.0 // indirect\n)\n";
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    #[test]
+    fn test_complete_go_sum() {
+        let fs = MockFileSystem::new();
+        fs.add_file("go.mod", GO_MOD);
+        fs.add_file(
+            "go.sum",
+            "github.com/gin-contrib/sse v0.1.0 h1:Y/yl/+YNO8GZSjAhjMsSuLt29uWRFHdHYUb5lYOV9qE=\n\
+             github.com/gin-contrib/sse v0.1.0/go.mod h1:RHrZQHXnP2xjPF+u1gW/2HnVO7nvIa9PG3Gm+fLHvGI=\n\
+             github.com/gin-gonic/gin v1.9.1 h1:4idEAncQnU5cB7BeOkPtxjfCSye0AAm1R0RVIqJ+Jmg=\n\
+             golang.org/x/net v0.10.0/go.mod h1:0qNGK6F8kojg2nk9dLZ2mShWaEBan6FAoqfSigmmuDg=\n",
+        );
+
+        let status = GoSumValidator::validate(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["go.mod", "go.sum"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(
+            status,
+            GoSumStatus {
+                present: true,
+                complete: true,
+                missing: vec![],
+                env_hints: vec![],
+            }
+        );
+    }
+
+    #[test]
+    fn test_partial_go_sum() {
+        let fs = MockFileSystem::new();
+        fs.add_file("api/go.mod", GO_MOD);
+        fs.add_file(
+            "api/go.sum",
+            "github.com/gin-gonic/gin v1.9.1 h1:4idEAncQnU5cB7BeOkPtxjfCSye0AAm1R0RVIqJ+Jmg=\n\
+             golang.org/x/net v0.9.0/go.mod h1:d48xBJpPfHeWQsugry2m+kC02ZBRGRgulfHnEXEuWns=\n",
+        );
+        fs.add_file(
+            "api/.env",
+            "export GONOSUMDB=example.com/private\nPORT=8080\n",
+        );
+
+        let status = GoSumValidator::validate(
+            Path::new(""),
+            Path::new("api"),
+            &paths(&["api/.env", "api/go.mod", "api/go.sum"]),
+            &fs,
+        )
+        .unwrap();
+        assert!(status.present);
+        assert!(!status.complete);
+        assert_eq!(
+            status.missing,
+            vec![
+                "github.com/gin-contrib/sse@v0.1.0",
+                "golang.org/x/net@v0.10.0"
+            ]
+        );
+        assert_eq!(status.env_hints, vec!["GONOSUMDB"]);
+    }
+
+    #[test]
+    fn test_missing_go_sum() {
+        let fs = MockFileSystem::new();
+        fs.add_file("go.mod", GO_MOD);
+        fs.add_file(".env", "GOFLAGS=-mod=mod\n");
+
+        let status = GoSumValidator::validate(
+            Path::new(""),
+            Path::new(""),
+            &paths(&[".env", "go.mod"]),
+            &fs,
+        )
+        .unwrap();
+        assert!(!status.present);
+        assert!(!status.complete);
+        assert_eq!(status.missing.len(), 3);
+        assert_eq!(status.env_hints, vec!["GOFLAGS"]);
+    }
+
+    #[test]
+    fn test_no_requirements_needs_no_go_sum() {
+        let fs = MockFileSystem::new();
+        fs.add_file("go.mod", "module example.com/app\n\ngo 1.22\n");
+
+        let status =
+            GoSumValidator::validate(Path::new(""), Path::new(""), &paths(&["go.mod"]), &fs)
+                .unwrap();
+        assert!(!status.present);
+        assert!(status.complete);
+        assert!(GoSumValidator::validate(Path::new(""), Path::new(""), &[], &fs).is_none());
+    }
+}
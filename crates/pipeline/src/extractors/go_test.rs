@@ -0,0 +1,284 @@
+//! Go test detector - test files, the assertion library they use and integration suites
+
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+/// Runs every package's tests, whichever library the assertions come from
+pub const GO_TEST_COMMAND: &str = "go test ./...";
+
+/// Test setup found among a Go service's `*_test.go` files
+#[derive(Debug, Clone, PartialEq)]
+pub struct GoTests {
+    /// `ginkgo`, `gomega`, `testify` or the standard library's `testing`
+    pub framework: String,
+    pub command: String,
+    /// An `integration_test.go` file or a `//go:build integration` constraint
+    pub has_integration_tests: bool,
+    /// Makefile, GitHub workflow and shell script paths running `go test -race`, relative to
+    /// the service except for workflows, which are relative to the repository
+    pub race_sources: Vec<String>,
+    /// Non-test code imports `sync` and starts goroutines with `go func(`
+    pub concurrent: bool,
+}
+
+impl GoTests {
+    /// `-race` is run elsewhere but missing from the suggested test command
+    pub fn race_missing_from_command(&self) -> bool {
+        !self.race_sources.is_empty() && !self.command.contains("-race")
+    }
+}
+
+pub struct GoTestDetector;
+
+impl GoTestDetector {
+    /// Inspects the service's `*_test.go` files among `file_tree` (repository-relative)
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<GoTests> {
+        let integration_tag =
+            Regex::new(r"(?m)^//(?:go:build|\s*\+build)\s.*\bintegration\b").expect("valid tag");
+
+        let test_files: Vec<&Path> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| {
+                path.file_name()
+                    .and_then(|name| name.to_str())
+                    .is_some_and(|name| name.ends_with("_test.go"))
+            })
+            .collect();
+        if test_files.is_empty() {
+            return None;
+        }
+
+        let (mut ginkgo, mut gomega, mut testify) = (false, false, false);
+        let mut has_integration_tests = false;
+        for relative in test_files {
+            let name = relative
+                .file_name()
+                .and_then(|name| name.to_str())
+                .unwrap_or_default();
+            if name == "integration_test.go" || name.ends_with("_integration_test.go") {
+                has_integration_tests = true;
+            }
+            let Ok(content) = fs.read_to_string(&repo_path.join(service_path).join(relative))
+            else {
+                continue;
+            };
+
+            // A suite bootstrap (`ginkgo bootstrap`, testify's `suite.Run`) settles the style
+            // even when the assertions live in other files
+            ginkgo |= content.contains("github.com/onsi/ginkgo") || content.contains("RunSpecs(");
+            gomega |= content.contains("github.com/onsi/gomega");
+            testify |=
+                content.contains("github.com/stretchr/testify") || content.contains("suite.Run(");
+            has_integration_tests |= integration_tag.is_match(&content);
+        }
+
+        let race = Regex::new(r"\bgo\s+test\b.*\s-race\b").expect("valid race regex");
+        let mut race_sources = Vec::new();
+        let (mut imports_sync, mut starts_goroutines) = (false, false);
+        for path in file_tree {
+            let workflow = path.starts_with(".github/workflows")
+                && path
+                    .extension()
+                    .is_some_and(|ext| ext == "yml" || ext == "yaml");
+            let relative = path.strip_prefix(service_path).ok();
+            let (source, is_go) = match relative {
+                _ if workflow => (path.display().to_string(), false),
+                Some(relative) if relative == Path::new("Makefile") => {
+                    (relative.display().to_string(), false)
+                }
+                Some(relative) if relative.extension().is_some_and(|ext| ext == "sh") => {
+                    (relative.display().to_string(), false)
+                }
+                Some(relative)
+                    if relative.extension().is_some_and(|ext| ext == "go")
+                        && !relative.to_string_lossy().ends_with("_test.go") =>
+                {
+                    (relative.display().to_string(), true)
+                }
+                _ => continue,
+            };
+            let Ok(content) = fs.read_to_string(&repo_path.join(path)) else {
+                continue;
+            };
+            if is_go {
+                imports_sync |= content.contains("\"sync\"");
+                starts_goroutines |= content.contains("go func(");
+            } else if content
+                .lines()
+                .any(|line| race.is_match(line.split('#').next().unwrap_or_default()))
+            {
+                race_sources.push(source);
+            }
+        }
+
+        let framework = if ginkgo {
+            "ginkgo"
+        } else if gomega {
+            "gomega"
+        } else if testify {
+            "testify"
+        } else {
+            "testing"
+        };
+
+        Some(GoTests {
+            framework: framework.to_string(),
+            command: GO_TEST_COMMAND.to_string(),
+            has_integration_tests,
+            race_sources,
+            concurrent: imports_sync && starts_goroutines,
+        })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    #[test]
+    fn test_testify_with_integration_tag() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "store/store_test.go",
+            "package store\n\nimport (\n\t\"testing\"\n\n\t\"github.com/stretchr/testify/assert\"\n)\n",
+        );
+        fs.add_file(
+            "store/postgres_test.go",
+            "//go:build integration\n\npackage store\n",
+        );
+        fs.add_file("store/store.go", "package store\n");
+
+        let tests = GoTestDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&[
+                "store/postgres_test.go",
+                "store/store.go",
+                "store/store_test.go",
+            ]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(tests.framework, "testify");
+        assert_eq!(tests.command, "go test ./...");
+        assert!(tests.has_integration_tests);
+    }
+
+    #[test]
+    fn test_ginkgo_bootstrap() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "api/api_suite_test.go",
+            "package api_test\n\nimport (\n\t. \"github.com/onsi/ginkgo/v2\"\n\t. \"github.com/onsi/gomega\"\n)\n\nfunc TestAPI(t *testing.T) {\n\tRegisterFailHandler(Fail)\n\tRunSpecs(t, \"API Suite\")\n}\n",
+        );
+        fs.add_file("api/integration_test.go", "package api_test\n");
+
+        let tests = GoTestDetector::detect(
+            Path::new(""),
+            Path::new("api"),
+            &paths(&["api/api_suite_test.go", "api/integration_test.go"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(tests.framework, "ginkgo");
+        assert!(tests.has_integration_tests);
+    }
+
+    #[test]
+    fn test_race_detector_sources() {
+        let fs = MockFileSystem::new();
+        fs.add_file("svc/main_test.go", "package main\n");
+        fs.add_file(
+            "svc/Makefile",
+            "test:\n\tgo test -race -count=1 ./...\n\nlint:\n\tgolangci-lint run\n",
+        );
+        fs.add_file(
+            ".github/workflows/ci.yml",
+            "jobs:\n  test:\n    steps:\n      - run: |\n          go vet ./...\n          go test -race -coverprofile=cover.out ./...\n",
+        );
+        fs.add_file("svc/scripts/test.sh", "#!/bin/sh\nset -e\ngo test ./...\n");
+        let tree = paths(&[
+            ".github/workflows/ci.yml",
+            "svc/Makefile",
+            "svc/main_test.go",
+            "svc/scripts/test.sh",
+        ]);
+
+        let tests = GoTestDetector::detect(Path::new(""), Path::new("svc"), &tree, &fs).unwrap();
+        assert_eq!(
+            tests.race_sources,
+            vec![".github/workflows/ci.yml", "Makefile"]
+        );
+        assert!(tests.race_missing_from_command());
+        assert!(!tests.concurrent);
+
+        fs.add_file("svc/scripts/test.sh", "#!/bin/sh\ngo test -v -race ./...\n");
+        fs.add_file(
+            "svc/Makefile",
+            "test:\n\tgo test ./... # -race is too slow here\n",
+        );
+        fs.add_file(".github/workflows/ci.yml", "steps:\n  - run: make test\n");
+        let tests = GoTestDetector::detect(Path::new(""), Path::new("svc"), &tree, &fs).unwrap();
+        assert_eq!(tests.race_sources, vec!["scripts/test.sh"]);
+    }
+
+    #[test]
+    fn test_concurrent_code_without_race_detector() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "worker_test.go",
+            "package main\n\nfunc TestWorker(t *testing.T) { go func() {}() }\n",
+        );
+        fs.add_file(
+            "main.go",
+            "package main\n\nimport \"fmt\"\n\nfunc main() { go func() { fmt.Println() }() }\n",
+        );
+        let tree = paths(&["main.go", "worker_test.go"]);
+
+        let tests = GoTestDetector::detect(Path::new(""), Path::new(""), &tree, &fs).unwrap();
+        assert!(!tests.concurrent);
+
+        fs.add_file(
+            "pool.go",
+            "package main\n\nimport \"sync\"\n\nvar wg sync.WaitGroup\n",
+        );
+        let tree = paths(&["main.go", "pool.go", "worker_test.go"]);
+        let tests = GoTestDetector::detect(Path::new(""), Path::new(""), &tree, &fs).unwrap();
+        assert!(tests.concurrent);
+        assert!(tests.race_sources.is_empty());
+        assert!(!tests.race_missing_from_command());
+    }
+
+    #[test]
+    fn test_standard_library_and_no_tests() {
+        let fs = MockFileSystem::new();
+        fs.add_file("main_test.go", "package main\n\nimport \"testing\"\n");
+        fs.add_file("main.go", "package main\n");
+
+        let tests = GoTestDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["main.go", "main_test.go"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(tests.framework, "testing");
+        assert!(!tests.has_integration_tests);
+        assert!(
+            GoTestDetector::detect(Path::new(""), Path::new(""), &paths(&["main.go"]), &fs)
+                .is_none()
+        );
+    }
+}
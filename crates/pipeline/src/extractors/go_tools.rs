@@ -0,0 +1,161 @@
+//! go.mod `tool` directive detector - tool dependencies pinned with Go 1.24's `go get -tool`
+
+use super::go_generate::GoGenerate;
+use peelbox_core::output::schema::GoTool;
+
+pub struct GoToolDirectiveDetector;
+
+impl GoToolDirectiveDetector {
+    /// Parses single-line and block `tool` directives of a go.mod file, in go.mod order
+    pub fn detect(go_mod: &str) -> Vec<GoTool> {
+        let mut tools: Vec<GoTool> = Vec::new();
+        let mut in_tool = false;
+
+        for line in go_mod.lines() {
+            let line = line.split("//").next().unwrap_or_default().trim();
+            let module = if in_tool {
+                if line == ")" {
+                    in_tool = false;
+                    continue;
+                }
+                line
+            } else {
+                match line.split_once(char::is_whitespace) {
+                    Some(("tool", rest)) if rest.trim() == "(" => {
+                        in_tool = true;
+                        continue;
+                    }
+                    Some(("tool", rest)) => rest.trim(),
+                    _ => continue,
+                }
+            };
+
+            let module = module.trim_matches('"');
+            if module.is_empty() || tools.iter().any(|tool| tool.module == module) {
+                continue;
+            }
+            tools.push(GoTool {
+                name: tool_name(module).to_string(),
+                module: module.to_string(),
+            });
+        }
+
+        tools
+    }
+
+    /// Tools no `//go:generate` directive runs, by binary name, `go tool` or `go run`
+    pub fn unused<'a>(tools: &'a [GoTool], generate: &GoGenerate) -> Vec<&'a GoTool> {
+        tools
+            .iter()
+            .filter(|tool| {
+                !generate.tools.contains(&tool.module)
+                    && !generate
+                        .commands
+                        .iter()
+                        .any(|command| invokes(command, tool))
+            })
+            .collect()
+    }
+}
+
+/// The name `go tool` runs a module by: its last path element, skipping a `/vN` major
+/// version suffix
+fn tool_name(module: &str) -> &str {
+    let mut elements = module.rsplit('/');
+    let last = elements.next().unwrap_or(module);
+    let major = last
+        .strip_prefix('v')
+        .is_some_and(|n| !n.is_empty() && n.chars().all(|c| c.is_ascii_digit()));
+    match elements.next() {
+        Some(parent) if major => parent,
+        _ => last,
+    }
+}
+
+fn invokes(command: &str, tool: &GoTool) -> bool {
+    let command = command.rsplit("&& ").next().unwrap_or(command);
+    let args: Vec<&str> = command.split_whitespace().collect();
+    match args.as_slice() {
+        ["go", "tool", program, ..] => *program == tool.name || *program == tool.module,
+        [program, ..] => *program == tool.name,
+        [] => false,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const GO_MOD: &str = r#"module example.com/app
+
+go 1.24
+
+tool golang.org/x/tools/cmd/stringer // enum strings
+
+tool (
+	go.uber.org/mock/mockgen
+	github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen
+	golang.org/x/tools/cmd/stringer
+)
+
+require golang.org/x/tools v0.29.0
+"#;
+
+    fn tool(name: &str, module: &str) -> GoTool {
+        GoTool {
+            name: name.to_string(),
+            module: module.to_string(),
+        }
+    }
+
+    #[test]
+    fn test_single_line_and_block_directives() {
+        assert_eq!(
+            GoToolDirectiveDetector::detect(GO_MOD),
+            vec![
+                tool("stringer", "golang.org/x/tools/cmd/stringer"),
+                tool("mockgen", "go.uber.org/mock/mockgen"),
+                tool(
+                    "oapi-codegen",
+                    "github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen"
+                ),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_major_version_suffix() {
+        assert_eq!(tool_name("github.com/bufbuild/buf/v2"), "buf");
+        assert_eq!(tool_name("github.com/golang/mock/mockgen"), "mockgen");
+        assert_eq!(tool_name("example.com/v"), "v");
+    }
+
+    #[test]
+    fn test_unused_tools() {
+        let tools = GoToolDirectiveDetector::detect(GO_MOD);
+        let generate = GoGenerate {
+            commands: vec![
+                "cd internal/store && go tool mockgen -source=store.go -destination=mock_store.go"
+                    .to_string(),
+                "stringer -type=Kind".to_string(),
+            ],
+            tools: vec!["golang.org/x/tools/cmd/stringer".to_string()],
+        };
+
+        assert_eq!(
+            GoToolDirectiveDetector::unused(&tools, &generate),
+            vec![&tool(
+                "oapi-codegen",
+                "github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen"
+            )]
+        );
+    }
+
+    #[test]
+    fn test_no_tool_directives() {
+        assert!(GoToolDirectiveDetector::detect(
+            "module example.com/app\n\ngo 1.22\n\n// tool golang.org/x/tools/cmd/stringer\n"
+        )
+        .is_empty());
+    }
+}
@@ -0,0 +1,212 @@
+//! GraphQL detector - gqlgen and graphql-go servers and the schema they serve
+
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use serde_yaml::Value;
+use std::path::{Path, PathBuf};
+
+const GQLGEN_MODULE: &str = "github.com/99designs/gqlgen";
+const GRAPHQL_GO_MODULE: &str = "github.com/graph-gophers/graphql-go";
+
+const GQLGEN_CONFIGS: [&str; 2] = ["gqlgen.yml", "gqlgen.yaml"];
+
+/// Regenerates gqlgen's models and resolver stubs from the schema
+pub const GQLGEN_GENERATE: &str = "go run github.com/99designs/gqlgen generate";
+
+/// A GraphQL server library used by the service and its schema
+#[derive(Debug, Clone, PartialEq, Default)]
+pub struct GraphQL {
+    /// `*.graphql` and `*.graphqls` files relative to the service
+    pub schema_files: Vec<String>,
+    /// The schema declares a `Subscription` type
+    pub subscriptions: bool,
+    /// Directory gqlgen writes models to, from `model.filename` in gqlgen.yml
+    pub model_dir: Option<String>,
+    /// Directory gqlgen writes resolvers to, from `resolver.dir` or `resolver.filename`
+    pub resolver_dir: Option<String>,
+    /// Code generation to run before the build; only gqlgen generates from the schema
+    pub generate_command: Option<String>,
+}
+
+pub struct GraphQLDetector;
+
+impl GraphQLDetector {
+    /// Detects a GraphQL server from the service's go.mod modules in `dependencies`, then
+    /// collects schema files and the gqlgen config among `file_tree` (repository-relative)
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        dependencies: &[String],
+    ) -> Option<GraphQL> {
+        let has_module = |module: &str| dependencies.iter().any(|dep| dep == module);
+        let gqlgen = has_module(GQLGEN_MODULE);
+        if !gqlgen && !has_module(GRAPHQL_GO_MODULE) {
+            return None;
+        }
+
+        let files: Vec<&Path> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .collect();
+        let read = |relative: &Path| {
+            fs.read_to_string(&repo_path.join(service_path).join(relative))
+                .ok()
+        };
+
+        let schemas: Vec<&Path> = files
+            .iter()
+            .filter(|path| {
+                path.extension()
+                    .is_some_and(|ext| ext == "graphql" || ext == "graphqls")
+            })
+            .copied()
+            .collect();
+        let subscription = Regex::new(r"(?m)^\s*(?:extend\s+)?type\s+Subscription\b")
+            .expect("valid subscription regex");
+        let subscriptions = schemas
+            .iter()
+            .any(|path| read(*path).is_some_and(|content| subscription.is_match(&content)));
+
+        let config: Option<Value> = GQLGEN_CONFIGS
+            .iter()
+            .find(|name| files.contains(&Path::new(name)))
+            .and_then(|name| read(Path::new(name)))
+            .and_then(|content| serde_yaml::from_str(&content).ok());
+        let setting = |section: &str, key: &str| {
+            config
+                .as_ref()?
+                .get(section)?
+                .get(key)?
+                .as_str()
+                .map(str::to_string)
+        };
+
+        Some(GraphQL {
+            schema_files: schemas
+                .iter()
+                .map(|path| path.display().to_string())
+                .collect(),
+            subscriptions,
+            model_dir: setting("model", "filename").map(|file| parent_dir(&file)),
+            resolver_dir: setting("resolver", "dir")
+                .or_else(|| setting("resolver", "filename").map(|file| parent_dir(&file))),
+            generate_command: (gqlgen && !schemas.is_empty()).then(|| GQLGEN_GENERATE.to_string()),
+        })
+    }
+}
+
+/// Directory of a config-relative file, `.` for the service root
+fn parent_dir(file: &str) -> String {
+    match Path::new(file).parent().and_then(|dir| dir.to_str()) {
+        Some("") | None => ".".to_string(),
+        Some(dir) => dir.to_string(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    fn deps(values: &[&str]) -> Vec<String> {
+        values.iter().map(|v| v.to_string()).collect()
+    }
+
+    #[test]
+    fn test_gqlgen_project() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "api/gqlgen.yml",
+            "schema:\n  - graph/*.graphqls\nmodel:\n  filename: graph/model/models_gen.go\n  package: model\nresolver:\n  layout: follow-schema\n  dir: graph\n  package: graph\n",
+        );
+        fs.add_file(
+            "api/graph/schema.graphqls",
+            "type Query {\n  todos: [Todo!]!\n}\n\ntype Subscription {\n  todoAdded: Todo!\n}\n",
+        );
+
+        let graphql = GraphQLDetector::detect(
+            Path::new(""),
+            Path::new("api"),
+            &paths(&[
+                "api/gqlgen.yml",
+                "api/graph/schema.graphqls",
+                "api/server.go",
+            ]),
+            &fs,
+            &deps(&[GQLGEN_MODULE]),
+        )
+        .unwrap();
+        assert_eq!(
+            graphql,
+            GraphQL {
+                schema_files: vec!["graph/schema.graphqls".to_string()],
+                subscriptions: true,
+                model_dir: Some("graph/model".to_string()),
+                resolver_dir: Some("graph".to_string()),
+                generate_command: Some(GQLGEN_GENERATE.to_string()),
+            }
+        );
+    }
+
+    #[test]
+    fn test_graphql_go_without_subscriptions() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "schema.graphql",
+            "schema {\n  query: Query\n}\n\ntype Query {\n  hello: String!\n}\n",
+        );
+
+        let graphql = GraphQLDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["main.go", "schema.graphql"]),
+            &fs,
+            &deps(&[GRAPHQL_GO_MODULE]),
+        )
+        .unwrap();
+        assert_eq!(graphql.schema_files, vec!["schema.graphql"]);
+        assert!(!graphql.subscriptions);
+        assert_eq!(graphql.model_dir, None);
+        assert_eq!(graphql.generate_command, None);
+    }
+
+    #[test]
+    fn test_schema_without_server_library() {
+        let fs = MockFileSystem::new();
+        fs.add_file("schema.graphql", "type Query { hello: String }\n");
+        assert!(GraphQLDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["schema.graphql"]),
+            &fs,
+            &deps(&["github.com/gin-gonic/gin"])
+        )
+        .is_none());
+    }
+
+    #[test]
+    fn test_resolver_filename() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "gqlgen.yaml",
+            "model:\n  filename: models_gen.go\nresolver:\n  filename: resolvers/resolver.go\n",
+        );
+        let graphql = GraphQLDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["gqlgen.yaml"]),
+            &fs,
+            &deps(&[GQLGEN_MODULE]),
+        )
+        .unwrap();
+        assert_eq!(graphql.model_dir.as_deref(), Some("."));
+        assert_eq!(graphql.resolver_dir.as_deref(), Some("resolvers"));
+        assert_eq!(graphql.generate_command, None);
+    }
+}
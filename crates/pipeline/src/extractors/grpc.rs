@@ -0,0 +1,127 @@
+//! gRPC detector - protobuf definitions and the Go stubs generated from them
+
+use peelbox_core::output::schema::GrpcMetadata;
+use std::path::PathBuf;
+
+const GRPC_MODULE: &str = "google.golang.org/grpc";
+/// Current protobuf runtime; stubs come from protoc-gen-go plus protoc-gen-go-grpc
+const PROTOBUF_MODULE: &str = "google.golang.org/protobuf";
+/// Legacy protobuf runtime; protoc-gen-go's `plugins=grpc` puts services into `.pb.go`
+const LEGACY_PROTOBUF_MODULE: &str = "github.com/golang/protobuf";
+
+pub struct GrpcDetector;
+
+impl GrpcDetector {
+    /// Detects gRPC from `.proto` files in the repository, the gRPC module in go.mod and
+    /// generated stubs
+    ///
+    /// `file_tree` holds repository-relative paths; `dependencies` are the service's go.mod
+    /// modules. Proto files alone (plain protobuf messages) are not reported.
+    pub fn detect(file_tree: &[PathBuf], dependencies: &[String]) -> Option<GrpcMetadata> {
+        let proto_files: Vec<String> = file_tree
+            .iter()
+            .filter(|path| path.extension().is_some_and(|ext| ext == "proto"))
+            .map(|path| path.display().to_string())
+            .collect();
+        if proto_files.is_empty() {
+            return None;
+        }
+
+        let has_module = |module: &str| dependencies.iter().any(|dep| dep == module);
+        let legacy = has_module(LEGACY_PROTOBUF_MODULE) && !has_module(PROTOBUF_MODULE);
+        let stub_suffix = if legacy { ".pb.go" } else { "_grpc.pb.go" };
+        let generated = file_tree.iter().any(|path| {
+            path.file_name()
+                .and_then(|name| name.to_str())
+                .is_some_and(|name| name.ends_with(stub_suffix))
+        });
+
+        if !has_module(GRPC_MODULE) && !generated {
+            return None;
+        }
+
+        let plugins = if legacy {
+            "--go_out=plugins=grpc,paths=source_relative:."
+        } else {
+            "--go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative"
+        };
+        let build_command_prefix = format!("protoc {} {}", plugins, proto_files.join(" "));
+
+        Some(GrpcMetadata {
+            proto_files,
+            generated,
+            build_command_prefix,
+        })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    fn deps(values: &[&str]) -> Vec<String> {
+        values.iter().map(|v| v.to_string()).collect()
+    }
+
+    #[test]
+    fn test_generated_stubs() {
+        let grpc = GrpcDetector::detect(
+            &paths(&[
+                "go.mod",
+                "proto/greeter.proto",
+                "proto/greeter.pb.go",
+                "proto/greeter_grpc.pb.go",
+            ]),
+            &deps(&[GRPC_MODULE, PROTOBUF_MODULE]),
+        )
+        .unwrap();
+
+        assert_eq!(grpc.proto_files, vec!["proto/greeter.proto"]);
+        assert!(grpc.generated);
+        assert_eq!(
+            grpc.build_command_prefix,
+            "protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative proto/greeter.proto"
+        );
+    }
+
+    #[test]
+    fn test_missing_stubs() {
+        let grpc = GrpcDetector::detect(
+            &paths(&["api/v1/users.proto", "api/v1/orders.proto", "main.go"]),
+            &deps(&[GRPC_MODULE]),
+        )
+        .unwrap();
+
+        assert_eq!(
+            grpc.proto_files,
+            vec!["api/v1/users.proto", "api/v1/orders.proto"]
+        );
+        assert!(!grpc.generated);
+    }
+
+    #[test]
+    fn test_legacy_protobuf_module() {
+        let grpc = GrpcDetector::detect(
+            &paths(&["greeter.proto", "greeter.pb.go"]),
+            &deps(&[GRPC_MODULE, LEGACY_PROTOBUF_MODULE]),
+        )
+        .unwrap();
+
+        assert!(grpc.generated);
+        assert!(grpc
+            .build_command_prefix
+            .starts_with("protoc --go_out=plugins=grpc,paths=source_relative:. "));
+    }
+
+    #[test]
+    fn test_plain_protobuf_is_not_grpc() {
+        assert!(
+            GrpcDetector::detect(&paths(&["event.proto"]), &deps(&[PROTOBUF_MODULE])).is_none()
+        );
+        assert!(GrpcDetector::detect(&paths(&["main.go"]), &deps(&[GRPC_MODULE])).is_none());
+    }
+}
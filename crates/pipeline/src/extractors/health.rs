@@ -5,6 +5,7 @@ use peelbox_core::fs::FileSystem;
 use peelbox_stack::registry::StackRegistry;
 use regex::Regex;
 use std::collections::HashSet;
+use std::path::PathBuf;
 
 #[derive(Debug, Clone, PartialEq)]
 pub struct HealthCheckInfo {
@@ -62,6 +63,40 @@ impl<F: FileSystem> HealthCheckExtractor<F> {
         health_checks
     }
 
+    /// First health route registered in the given source files of the service language
+    ///
+    /// Files are searched in the order given, so pass them sorted for a stable answer.
+    pub fn find_route(&self, context: &ServiceContext, files: &[PathBuf]) -> Option<String> {
+        let lang = context
+            .language
+            .as_ref()
+            .and_then(|id| self.registry.get_language(id.clone()))?;
+
+        let extensions = lang.extensions();
+        let patterns: Vec<Regex> = lang
+            .health_check_patterns()
+            .iter()
+            .filter_map(|(pattern, _)| Regex::new(pattern).ok())
+            .collect();
+
+        files
+            .iter()
+            .filter(|file| {
+                file.extension()
+                    .and_then(|ext| ext.to_str())
+                    .is_some_and(|ext| extensions.iter().any(|e| e == ext))
+                    && !crate::extractors::common::is_test_file(file)
+            })
+            .find_map(|file| {
+                let content = self.fs.read_to_string(file).ok()?;
+                patterns.iter().find_map(|re| {
+                    re.captures(&content)
+                        .and_then(|cap| cap.get(1))
+                        .map(|m| m.as_str().to_string())
+                })
+            })
+    }
+
     fn extract_from_code_routes(
         &self,
         context: &ServiceContext,
@@ -328,6 +363,37 @@ public class HealthController {
         assert_eq!(health_checks[0].source, HealthCheckSource::Dockerfile);
     }
 
+    #[test]
+    fn test_find_go_health_route() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "main.go",
+            r#"r := gin.Default()
+r.GET("/users", listUsers)"#,
+        );
+        fs.add_file(
+            "internal/server/routes.go",
+            r#"r.GET("/api/healthcheck", legacy)
+r.GET("/healthz", func(c *gin.Context) { c.Status(200) })"#,
+        );
+        fs.add_file("main_test.go", r#"r.GET("/ping", pong)"#);
+
+        let extractor = HealthCheckExtractor::new(fs);
+        let context = ServiceContext::with_detection(
+            PathBuf::from("."),
+            Some(peelbox_stack::LanguageId::Go),
+            None,
+        );
+        let files = ["main.go", "internal/server/routes.go", "main_test.go"].map(PathBuf::from);
+
+        assert_eq!(
+            extractor.find_route(&context, &files),
+            Some("/healthz".to_string())
+        );
+        assert_eq!(extractor.find_route(&context, &files[..1]), None);
+        assert_eq!(extractor.find_route(&context, &files[2..]), None);
+    }
+
     #[test]
     fn test_no_health_checks_found() {
         let fs = MockFileSystem::new();
@@ -0,0 +1,547 @@
+//! IaC detector - Terraform, Pulumi and AWS CDK projects deploying the service
+
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use serde_yaml::Value;
+use std::path::{Path, PathBuf};
+
+/// An infrastructure-as-code project found next to the application
+#[derive(Debug, Clone, PartialEq)]
+pub struct IacProject {
+    /// terraform, pulumi or cdk
+    pub tool: String,
+    /// Directory holding the project, relative to the repository
+    pub path: String,
+    /// Terraform `required_providers`, as their source addresses
+    pub providers: Vec<String>,
+    /// Clouds the Terraform providers deploy to, e.g. `aws` or `kubernetes`
+    pub cloud_providers: Vec<String>,
+    /// Where Terraform keeps its state: the `backend` type, or `terraform-cloud`
+    pub state_backend: Option<String>,
+    /// The project's directory with a trailing slash when it is a subdirectory of the service,
+    /// so the application rather than the IaC is the primary project
+    pub infra_subdirectory: Option<String>,
+    /// Pulumi runtime or the language of the CDK app
+    pub language: Option<String>,
+    pub version_constraint: Option<String>,
+    pub deploy_command: String,
+}
+
+/// Terraform provider types credentials are configured for, with the google-beta variant
+const CLOUD_PROVIDERS: &[(&str, &str)] = &[
+    ("aws", "aws"),
+    ("google", "google"),
+    ("google-beta", "google"),
+    ("azurerm", "azurerm"),
+    ("kubernetes", "kubernetes"),
+];
+
+pub struct IacDetector;
+
+impl IacDetector {
+    /// The IaC project closest to the service: one inside the service directory if there is
+    /// one, otherwise the shallowest in the repository
+    ///
+    /// `file_tree` is repository-relative. Terraform wins over Pulumi, and Pulumi over CDK,
+    /// when one directory holds several. Provider caches under `.terraform` are ignored.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<IacProject> {
+        let files: Vec<&PathBuf> = file_tree
+            .iter()
+            .filter(|path| !path.components().any(|c| c.as_os_str() == ".terraform"))
+            .collect();
+        let in_service: Vec<&PathBuf> = files
+            .iter()
+            .copied()
+            .filter(|path| path.starts_with(service_path))
+            .collect();
+
+        let mut project = [in_service, files]
+            .iter()
+            .find_map(|scope| Self::detect_in(repo_path, scope, fs))?;
+        let dir = Path::new(&project.path);
+        if project.path != "." && dir != service_path && dir.starts_with(service_path) {
+            project.infra_subdirectory = Some(format!("{}/", project.path));
+        }
+        Some(project)
+    }
+
+    fn detect_in<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        files: &[&PathBuf],
+        fs: &F,
+    ) -> Option<IacProject> {
+        let read = |relative: &Path| fs.read_to_string(&repo_path.join(relative)).ok();
+
+        let (dir, _) = files
+            .iter()
+            .filter_map(|path| {
+                let tool = match path.file_name()?.to_str()? {
+                    name if name.ends_with(".tf") => 0,
+                    "Pulumi.yaml" | "Pulumi.yml" => 1,
+                    "cdk.json" => 2,
+                    _ => return None,
+                };
+                let dir = path.parent().unwrap_or(Path::new(""));
+                Some((dir, tool))
+            })
+            .min_by_key(|(dir, tool)| (dir.components().count(), *tool, *dir))?;
+
+        let in_dir: Vec<&PathBuf> = files
+            .iter()
+            .copied()
+            .filter(|path| path.parent() == Some(dir))
+            .collect();
+        let find = |name: &str| {
+            in_dir
+                .iter()
+                .find(|path| path.file_name().and_then(|n| n.to_str()) == Some(name))
+        };
+        let tf_files: Vec<&PathBuf> = in_dir
+            .iter()
+            .copied()
+            .filter(|path| path.extension().is_some_and(|ext| ext == "tf"))
+            .collect();
+
+        if !tf_files.is_empty() {
+            let sources: Vec<String> = tf_files.iter().filter_map(|path| read(path)).collect();
+            let mut providers: Vec<String> = sources
+                .iter()
+                .flat_map(|content| required_providers(content))
+                .collect();
+            providers.sort();
+            providers.dedup();
+
+            let mut cloud_providers: Vec<String> = sources
+                .iter()
+                .flat_map(|content| provider_blocks(content))
+                .chain(
+                    providers
+                        .iter()
+                        .map(|source| source.rsplit('/').next().unwrap_or(source).to_string()),
+                )
+                .filter_map(|name| {
+                    CLOUD_PROVIDERS
+                        .iter()
+                        .find(|(provider, _)| *provider == name)
+                        .map(|(_, cloud)| cloud.to_string())
+                })
+                .collect();
+            cloud_providers.sort();
+            cloud_providers.dedup();
+
+            let version_constraint = find(".terraform-version")
+                .or_else(|| {
+                    files
+                        .iter()
+                        .find(|path| path.as_path() == Path::new(".terraform-version"))
+                })
+                .and_then(|path| read(path))
+                .map(|version| version.trim().to_string())
+                .filter(|version| !version.is_empty())
+                .or_else(|| sources.iter().find_map(|content| required_version(content)));
+
+            return Some(IacProject {
+                tool: "terraform".to_string(),
+                path: dir_name(dir),
+                providers,
+                cloud_providers,
+                state_backend: sources.iter().find_map(|content| state_backend(content)),
+                infra_subdirectory: None,
+                language: None,
+                version_constraint,
+                deploy_command: if dir.as_os_str().is_empty() {
+                    "terraform init && terraform apply".to_string()
+                } else {
+                    format!(
+                        "terraform -chdir={0} init && terraform -chdir={0} apply",
+                        dir.display()
+                    )
+                },
+            });
+        }
+
+        if let Some(project) = find("Pulumi.yaml").or_else(|| find("Pulumi.yml")) {
+            let project: Option<Value> = read(project).and_then(|c| serde_yaml::from_str(&c).ok());
+            let runtime = project.as_ref().and_then(|p| p.get("runtime"));
+            return Some(IacProject {
+                tool: "pulumi".to_string(),
+                path: dir_name(dir),
+                providers: vec![],
+                cloud_providers: vec![],
+                state_backend: None,
+                infra_subdirectory: None,
+                language: runtime
+                    .and_then(|r| r.get("name").unwrap_or(r).as_str())
+                    .map(str::to_string),
+                version_constraint: project
+                    .as_ref()
+                    .and_then(|p| p.get("requiredPulumiVersion"))
+                    .and_then(Value::as_str)
+                    .map(str::to_string),
+                deploy_command: with_cwd(dir, "pulumi up"),
+            });
+        }
+
+        let app = find("cdk.json")
+            .and_then(|path| read(path))
+            .and_then(|content| serde_json::from_str::<serde_json::Value>(&content).ok())
+            .and_then(|cdk| cdk["app"].as_str().map(str::to_string));
+        Some(IacProject {
+            tool: "cdk".to_string(),
+            path: dir_name(dir),
+            providers: vec![],
+            cloud_providers: vec![],
+            state_backend: None,
+            infra_subdirectory: None,
+            language: app
+                .as_deref()
+                .and_then(cdk_app_language)
+                .map(str::to_string),
+            version_constraint: None,
+            deploy_command: with_cwd(dir, "npx cdk deploy"),
+        })
+    }
+}
+
+fn dir_name(dir: &Path) -> String {
+    if dir.as_os_str().is_empty() {
+        ".".to_string()
+    } else {
+        dir.display().to_string()
+    }
+}
+
+fn with_cwd(dir: &Path, command: &str) -> String {
+    if dir.as_os_str().is_empty() {
+        command.to_string()
+    } else {
+        format!("cd {} && {}", dir.display(), command)
+    }
+}
+
+/// The body of the first `<keyword> {` block, braces balanced
+fn block<'a>(content: &'a str, keyword: &str) -> Option<&'a str> {
+    let start = content
+        .match_indices(keyword)
+        .map(|(index, _)| index + keyword.len())
+        .find(|&end| content[end..].trim_start().starts_with('{'))?;
+    let open = start + content[start..].find('{')? + 1;
+
+    let mut depth = 1;
+    for (offset, c) in content[open..].char_indices() {
+        match c {
+            '{' => depth += 1,
+            '}' => {
+                depth -= 1;
+                if depth == 0 {
+                    return Some(&content[open..open + offset]);
+                }
+            }
+            _ => {}
+        }
+    }
+    None
+}
+
+/// Source addresses of the providers in `terraform { required_providers { ... } }`; a
+/// provider without a `source` is reported by its local name
+fn required_providers(content: &str) -> Vec<String> {
+    let Some(body) = block(content, "required_providers") else {
+        return vec![];
+    };
+
+    let mut providers = Vec::new();
+    let mut current: Option<(&str, String)> = None;
+    let mut depth = 0;
+    for line in body.lines().map(str::trim) {
+        if depth == 0 {
+            if let Some((name, value)) = line.split_once('=') {
+                if value.trim_start().starts_with('{') {
+                    current = Some((name.trim(), String::new()));
+                } else {
+                    // Pre-0.13 shorthand: `aws = "~> 3.0"`
+                    providers.push(name.trim().to_string());
+                }
+            }
+        }
+        if let Some((_, entry)) = current.as_mut() {
+            entry.push_str(line);
+            entry.push('\n');
+        }
+
+        depth += line.matches('{').count() as i32 - line.matches('}').count() as i32;
+        if depth == 0 {
+            if let Some((name, entry)) = current.take() {
+                providers.push(attribute(&entry, "source").unwrap_or_else(|| name.to_string()));
+            }
+        }
+    }
+    providers
+}
+
+/// Local names of the `provider "<name>" { ... }` blocks
+fn provider_blocks(content: &str) -> Vec<String> {
+    let provider =
+        Regex::new(r#"(?m)^\s*provider\s+"([\w-]+)"\s*\{"#).expect("valid provider regex");
+    provider
+        .captures_iter(content)
+        .map(|caps| caps[1].to_string())
+        .collect()
+}
+
+/// The `backend "<type>"` of the `terraform` block; the `remote` backend and the `cloud`
+/// block store state in Terraform Cloud
+fn state_backend(content: &str) -> Option<String> {
+    let terraform = block(content, "terraform")?;
+    if block(terraform, "cloud").is_some() {
+        return Some("terraform-cloud".to_string());
+    }
+    let backend = Regex::new(r#"\bbackend\s+"(\w+)""#).expect("valid backend regex");
+    let kind = backend.captures(terraform)?[1].to_string();
+    Some(if kind == "remote" {
+        "terraform-cloud".to_string()
+    } else {
+        kind
+    })
+}
+
+/// `required_version` of the `terraform` block
+fn required_version(content: &str) -> Option<String> {
+    attribute(block(content, "terraform")?, "required_version")
+}
+
+/// Value of a `key = "value"` attribute in an HCL body
+fn attribute(body: &str, key: &str) -> Option<String> {
+    body.split(|c| c == '\n' || c == ',' || c == '{')
+        .filter_map(|part| part.split_once('='))
+        .find(|(name, _)| name.trim() == key)
+        .map(|(_, value)| value.trim().trim_end_matches('}').trim().trim_matches('"'))
+        .map(str::to_string)
+}
+
+/// Language of a CDK app from the command in cdk.json's `app`
+fn cdk_app_language(app: &str) -> Option<&'static str> {
+    let words: Vec<&str> = app.split_whitespace().collect();
+    let has = |suffix: &str| words.iter().any(|w| w.ends_with(suffix));
+    if has(".ts") {
+        Some("typescript")
+    } else if has(".js") || words.first() == Some(&"node") {
+        Some("javascript")
+    } else if words.first() == Some(&"go") || has(".go") {
+        Some("go")
+    } else if has(".py") || words.first().is_some_and(|w| w.starts_with("python")) {
+        Some("python")
+    } else if words
+        .first()
+        .is_some_and(|w| matches!(*w, "mvn" | "gradle" | "./gradlew"))
+    {
+        Some("java")
+    } else if words.first() == Some(&"dotnet") {
+        Some("csharp")
+    } else {
+        None
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const MAIN_TF: &str = r#"terraform {
+  required_version = ">= 1.5.0"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    random = { source = "hashicorp/random", version = "~> 3.5" }
+  }
+}
+
+provider "aws" {
+  region = var.region
+}
+"#;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    #[test]
+    fn test_terraform_next_to_go_app() {
+        let fs = MockFileSystem::new();
+        fs.add_file("infra/main.tf", MAIN_TF);
+        fs.add_file(
+            "infra/variables.tf",
+            "variable \"region\" {\n  default = \"eu-west-1\"\n}\n",
+        );
+        fs.add_file(".terraform-version", "1.7.5\n");
+
+        let project = IacDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&[
+                ".terraform-version",
+                "go.mod",
+                "infra/.terraform/modules/vpc/main.tf",
+                "infra/main.tf",
+                "infra/modules/db/main.tf",
+                "infra/variables.tf",
+                "main.go",
+            ]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(
+            project,
+            IacProject {
+                tool: "terraform".to_string(),
+                path: "infra".to_string(),
+                providers: vec!["hashicorp/aws".to_string(), "hashicorp/random".to_string()],
+                cloud_providers: vec!["aws".to_string()],
+                state_backend: None,
+                infra_subdirectory: Some("infra/".to_string()),
+                language: None,
+                version_constraint: Some("1.7.5".to_string()),
+                deploy_command: "terraform -chdir=infra init && terraform -chdir=infra apply"
+                    .to_string(),
+            }
+        );
+    }
+
+    #[test]
+    fn test_terraform_required_version_fallback() {
+        let fs = MockFileSystem::new();
+        fs.add_file("main.tf", MAIN_TF);
+        let project =
+            IacDetector::detect(Path::new(""), Path::new(""), &paths(&["main.tf"]), &fs).unwrap();
+        assert_eq!(project.path, ".");
+        assert_eq!(project.version_constraint.as_deref(), Some(">= 1.5.0"));
+        assert_eq!(project.deploy_command, "terraform init && terraform apply");
+    }
+
+    #[test]
+    fn test_cloud_providers_and_state_backend() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "main.tf",
+            "terraform {\n  backend \"gcs\" {\n    bucket = \"tf-state\"\n  }\n}\n\nprovider \"google-beta\" {\n  project = var.project\n}\n\nprovider \"kubernetes\" {\n  config_path = \"~/.kube/config\"\n}\n\nprovider \"random\" {}\n",
+        );
+        let project =
+            IacDetector::detect(Path::new(""), Path::new(""), &paths(&["main.tf"]), &fs).unwrap();
+        assert_eq!(project.cloud_providers, vec!["google", "kubernetes"]);
+        assert_eq!(project.state_backend.as_deref(), Some("gcs"));
+        assert_eq!(project.infra_subdirectory, None);
+
+        assert_eq!(
+            state_backend("terraform {\n  cloud {\n    organization = \"acme\"\n  }\n}\n")
+                .as_deref(),
+            Some("terraform-cloud")
+        );
+        assert_eq!(
+            state_backend(
+                "terraform {\n  backend \"remote\" {\n    organization = \"acme\"\n  }\n}\n"
+            )
+            .as_deref(),
+            Some("terraform-cloud")
+        );
+        assert_eq!(state_backend(MAIN_TF), None);
+    }
+
+    #[test]
+    fn test_legacy_provider_constraints() {
+        assert_eq!(
+            required_providers(
+                "terraform {\n  required_providers {\n    aws = \"~> 3.0\"\n  }\n}\n"
+            ),
+            vec!["aws"]
+        );
+    }
+
+    #[test]
+    fn test_pulumi() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "deploy/Pulumi.yaml",
+            "name: shop\nruntime:\n  name: go\n  options:\n    binary: shop\nrequiredPulumiVersion: \">=3.100.0\"\n",
+        );
+        let project = IacDetector::detect(
+            Path::new(""),
+            Path::new("api"),
+            &paths(&["api/go.mod", "deploy/Pulumi.yaml", "deploy/main.go"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(project.tool, "pulumi");
+        assert_eq!(project.path, "deploy");
+        assert_eq!(project.language.as_deref(), Some("go"));
+        assert_eq!(project.version_constraint.as_deref(), Some(">=3.100.0"));
+        assert_eq!(project.deploy_command, "cd deploy && pulumi up");
+    }
+
+    #[test]
+    fn test_cdk() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "cdk.json",
+            r#"{"app": "npx ts-node --prefer-ts-exts bin/app.ts", "context": {}}"#,
+        );
+        let project =
+            IacDetector::detect(Path::new(""), Path::new(""), &paths(&["cdk.json"]), &fs).unwrap();
+        assert_eq!(project.tool, "cdk");
+        assert_eq!(project.language.as_deref(), Some("typescript"));
+        assert_eq!(project.deploy_command, "npx cdk deploy");
+
+        assert_eq!(
+            cdk_app_language("go mod download && go run cdk.go"),
+            Some("go")
+        );
+        assert_eq!(cdk_app_language("python3 app.py"), Some("python"));
+        assert_eq!(
+            cdk_app_language("mvn -e -q compile exec:java"),
+            Some("java")
+        );
+        assert_eq!(
+            cdk_app_language("dotnet run -p src/Infra.csproj"),
+            Some("csharp")
+        );
+    }
+
+    #[test]
+    fn test_service_scope_wins() {
+        let fs = MockFileSystem::new();
+        fs.add_file("infra/main.tf", MAIN_TF);
+        fs.add_file("api/cdk.json", r#"{"app": "go run cdk.go"}"#);
+        let project = IacDetector::detect(
+            Path::new(""),
+            Path::new("api"),
+            &paths(&["api/cdk.json", "api/go.mod", "infra/main.tf"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(project.tool, "cdk");
+        assert_eq!(project.path, "api");
+        assert_eq!(project.deploy_command, "cd api && npx cdk deploy");
+    }
+
+    #[test]
+    fn test_no_iac() {
+        let fs = MockFileSystem::new();
+        assert_eq!(
+            IacDetector::detect(
+                Path::new(""),
+                Path::new(""),
+                &paths(&["go.mod", "main.go"]),
+                &fs
+            ),
+            None
+        );
+    }
+}
@@ -0,0 +1,445 @@
+//! Kubernetes detector - the workload a Deployment or StatefulSet manifest runs
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::KubernetesMetadata;
+use serde::Deserialize;
+use serde_yaml::Value;
+use std::path::{Component, Path, PathBuf};
+
+const KUSTOMIZATION: [&str; 3] = ["kustomization.yaml", "kustomization.yml", "Kustomization"];
+const WORKLOAD_KINDS: [&str; 2] = ["Deployment", "StatefulSet"];
+/// Directories never holding plain manifests: Helm templates are not YAML until rendered
+const SKIPPED_DIRS: [&str; 3] = ["node_modules", "templates", ".github"];
+/// Overlays referencing bases referencing bases; deeper chains are not followed
+const MAX_KUSTOMIZE_DEPTH: usize = 4;
+
+/// An `images` entry of a kustomization.yaml
+#[derive(Debug, Clone, PartialEq)]
+struct ImageOverride {
+    name: String,
+    new_name: Option<String>,
+    new_tag: Option<String>,
+    digest: Option<String>,
+}
+
+pub struct KubernetesDetector;
+
+impl KubernetesDetector {
+    /// The first container of the first Deployment or StatefulSet among the YAML files in
+    /// `file_tree` (repository-relative), preferring the service directory over the rest of
+    /// the repository
+    ///
+    /// Files may hold several `---`-separated documents. When the scope has a
+    /// kustomization.yaml, only the resources it lists are read, in order, and its `images`
+    /// overrides apply to the container image; an overlay no other kustomization references is
+    /// preferred over the bases it builds on, the shallowest first.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<KubernetesMetadata> {
+        let mut files: Vec<&PathBuf> = file_tree
+            .iter()
+            .filter(|path| is_yaml(path) || is_kustomization(path))
+            .filter(|path| {
+                !path
+                    .components()
+                    .any(|c| SKIPPED_DIRS.iter().any(|dir| c.as_os_str() == *dir))
+            })
+            .collect();
+        files.sort();
+        let in_service: Vec<&PathBuf> = files
+            .iter()
+            .copied()
+            .filter(|path| path.starts_with(service_path))
+            .collect();
+
+        [in_service, files]
+            .iter()
+            .find_map(|scope| Self::detect_in(repo_path, scope, fs))
+    }
+
+    fn detect_in<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        files: &[&PathBuf],
+        fs: &F,
+    ) -> Option<KubernetesMetadata> {
+        let read = |relative: &Path| fs.read_to_string(&repo_path.join(relative)).ok();
+
+        let kustomizations: Vec<&PathBuf> = files
+            .iter()
+            .copied()
+            .filter(|path| is_kustomization(path))
+            .collect();
+        let referenced: Vec<PathBuf> = kustomizations
+            .iter()
+            .filter_map(|path| read_kustomization(path, &read))
+            .flat_map(|(path, config)| resource_paths(&path, &config))
+            .collect();
+        let kustomization = kustomizations
+            .iter()
+            .filter(|path| {
+                !referenced
+                    .iter()
+                    .any(|resource| path.parent() == Some(resource.as_path()))
+            })
+            .min_by_key(|path| path.components().count());
+        let mut overrides = Vec::new();
+        let manifests: Vec<PathBuf> = match kustomization {
+            Some(path) => kustomize_resources(path, &read, &mut overrides, 0),
+            None => files
+                .iter()
+                .filter(|path| is_yaml(path))
+                .map(|path| path.to_path_buf())
+                .collect(),
+        };
+
+        manifests.iter().find_map(|manifest| {
+            let (kind, container) = workload(&read(manifest)?)?;
+            let resource = |section: &str, name: &str| {
+                scalar(container.get("resources")?.get(section)?.get(name)?)
+            };
+            let probe_path = |probe: &str| {
+                container
+                    .get(probe)?
+                    .get("httpGet")?
+                    .get("path")?
+                    .as_str()
+                    .map(str::to_string)
+            };
+            Some(KubernetesMetadata {
+                manifest: manifest.display().to_string(),
+                kind,
+                image: container
+                    .get("image")
+                    .and_then(Value::as_str)
+                    .map(|image| apply_overrides(image, &overrides)),
+                cpu_request: resource("requests", "cpu"),
+                memory_request: resource("requests", "memory"),
+                cpu_limit: resource("limits", "cpu"),
+                memory_limit: resource("limits", "memory"),
+                liveness_path: probe_path("livenessProbe"),
+                readiness_path: probe_path("readinessProbe"),
+            })
+        })
+    }
+}
+
+fn is_yaml(path: &Path) -> bool {
+    path.extension()
+        .is_some_and(|ext| ext == "yaml" || ext == "yml")
+        && !is_kustomization(path)
+}
+
+fn is_kustomization(path: &Path) -> bool {
+    path.file_name()
+        .and_then(|name| name.to_str())
+        .is_some_and(|name| KUSTOMIZATION.contains(&name))
+}
+
+/// Manifests a kustomization lists under `resources` (or the older `bases`), following
+/// directories holding their own kustomization; `images` entries are collected outermost first
+fn kustomize_resources(
+    kustomization: &Path,
+    read: &dyn Fn(&Path) -> Option<String>,
+    overrides: &mut Vec<ImageOverride>,
+    depth: usize,
+) -> Vec<PathBuf> {
+    let Some((_, config)) = read_kustomization(kustomization, read) else {
+        return vec![];
+    };
+    let text = |value: &Value, key: &str| value.get(key).and_then(scalar);
+
+    overrides.extend(
+        config
+            .get("images")
+            .and_then(Value::as_sequence)
+            .into_iter()
+            .flatten()
+            .filter_map(|image| {
+                Some(ImageOverride {
+                    name: text(image, "name")?,
+                    new_name: text(image, "newName"),
+                    new_tag: text(image, "newTag"),
+                    digest: text(image, "digest"),
+                })
+            }),
+    );
+
+    let mut manifests = Vec::new();
+    for path in resource_paths(kustomization, &config) {
+        if is_yaml(&path) {
+            manifests.push(path);
+        } else if depth < MAX_KUSTOMIZE_DEPTH {
+            if let Some(nested) = KUSTOMIZATION
+                .iter()
+                .map(|name| path.join(name))
+                .find(|nested| read(nested).is_some())
+            {
+                manifests.extend(kustomize_resources(&nested, read, overrides, depth + 1));
+            }
+        }
+    }
+    manifests
+}
+
+fn read_kustomization(
+    path: &Path,
+    read: &dyn Fn(&Path) -> Option<String>,
+) -> Option<(PathBuf, Value)> {
+    let config = serde_yaml::from_str(&read(path)?).ok()?;
+    Some((path.to_path_buf(), config))
+}
+
+/// Local `resources` and `bases` entries of a kustomization, repository-relative; remote
+/// bases are skipped
+fn resource_paths(kustomization: &Path, config: &Value) -> Vec<PathBuf> {
+    let dir = kustomization.parent().unwrap_or(Path::new(""));
+    ["resources", "bases"]
+        .iter()
+        .filter_map(|key| config.get(key)?.as_sequence())
+        .flatten()
+        .filter_map(Value::as_str)
+        .filter(|entry| !entry.contains("://"))
+        .map(|entry| normalize(&dir.join(entry)))
+        .collect()
+}
+
+/// Resolves `..` in a repository-relative path
+fn normalize(path: &Path) -> PathBuf {
+    let mut normalized = PathBuf::new();
+    for component in path.components() {
+        match component {
+            Component::ParentDir => {
+                normalized.pop();
+            }
+            Component::CurDir => {}
+            other => normalized.push(other),
+        }
+    }
+    normalized
+}
+
+/// Kind and first container of the first Deployment or StatefulSet document
+fn workload(content: &str) -> Option<(String, Value)> {
+    serde_yaml::Deserializer::from_str(content)
+        .filter_map(|document| Value::deserialize(document).ok())
+        .find_map(|document| {
+            let kind = document.get("kind")?.as_str()?;
+            if !WORKLOAD_KINDS.contains(&kind) {
+                return None;
+            }
+            let container = document
+                .get("spec")?
+                .get("template")?
+                .get("spec")?
+                .get("containers")?
+                .as_sequence()?
+                .first()?
+                .clone();
+            Some((kind.to_string(), container))
+        })
+}
+
+/// Quantities such as `cpu: 1` are numbers in YAML
+fn scalar(value: &Value) -> Option<String> {
+    match value {
+        Value::String(s) => Some(s.clone()),
+        Value::Number(n) => Some(n.to_string()),
+        _ => None,
+    }
+}
+
+/// Applies the kustomize `images` entry matching the image name
+fn apply_overrides(image: &str, overrides: &[ImageOverride]) -> String {
+    let (reference, digest) = match image.split_once('@') {
+        Some((reference, digest)) => (reference, Some(digest)),
+        None => (image, None),
+    };
+    // A colon after the last slash separates the tag; one before it belongs to a registry port
+    let (name, tag) = match reference.rsplit_once(':') {
+        Some((name, tag)) if !tag.contains('/') => (name, Some(tag)),
+        _ => (reference, None),
+    };
+    let Some(entry) = overrides.iter().find(|entry| entry.name == name) else {
+        return image.to_string();
+    };
+
+    let name = entry.new_name.as_deref().unwrap_or(name);
+    match (
+        entry.digest.as_deref().or(digest),
+        entry.new_tag.as_deref().or(tag),
+    ) {
+        (Some(digest), _) => format!("{}@{}", name, digest),
+        (None, Some(tag)) => format!("{}:{}", name, tag),
+        (None, None) => name.to_string(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    const DEPLOYMENT: &str = r#"apiVersion: v1
+kind: Service
+metadata:
+  name: orders
+spec:
+  ports:
+    - port: 80
+      targetPort: 8080
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: orders
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: orders
+          image: orders:latest
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              cpu: 1
+              memory: 256Mi
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: 8080
+          readinessProbe:
+            httpGet:
+              path: /readyz
+              port: 8080
+        - name: proxy
+          image: envoyproxy/envoy:v1.30.1
+"#;
+
+    #[test]
+    fn test_multi_document_deployment() {
+        let fs = MockFileSystem::new();
+        fs.add_file("k8s/app.yaml", DEPLOYMENT);
+        let manifest = KubernetesDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["go.mod", "k8s/app.yaml", "main.go"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(
+            manifest,
+            KubernetesMetadata {
+                manifest: "k8s/app.yaml".to_string(),
+                kind: "Deployment".to_string(),
+                image: Some("orders:latest".to_string()),
+                cpu_request: Some("100m".to_string()),
+                memory_request: Some("128Mi".to_string()),
+                cpu_limit: Some("1".to_string()),
+                memory_limit: Some("256Mi".to_string()),
+                liveness_path: Some("/healthz".to_string()),
+                readiness_path: Some("/readyz".to_string()),
+            }
+        );
+    }
+
+    #[test]
+    fn test_stateful_set_without_resources() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "deploy/db.yml",
+            "apiVersion: apps/v1\nkind: StatefulSet\nspec:\n  template:\n    spec:\n      containers:\n        - name: queue\n          image: registry.local:5000/queue\n",
+        );
+        let manifest = KubernetesDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["deploy/db.yml"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(manifest.kind, "StatefulSet");
+        assert_eq!(manifest.image.as_deref(), Some("registry.local:5000/queue"));
+        assert_eq!(manifest.memory_request, None);
+        assert_eq!(manifest.liveness_path, None);
+    }
+
+    #[test]
+    fn test_kustomize_overlay() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "deploy/base/kustomization.yaml",
+            "resources:\n  - service.yaml\n  - deployment.yaml\n",
+        );
+        fs.add_file(
+            "deploy/base/service.yaml",
+            "apiVersion: v1\nkind: Service\nmetadata:\n  name: orders\n",
+        );
+        fs.add_file("deploy/base/deployment.yaml", DEPLOYMENT);
+        fs.add_file(
+            "deploy/overlays/prod/kustomization.yaml",
+            "resources:\n  - ../../base\nimages:\n  - name: orders\n    newName: ghcr.io/acme/orders\n    newTag: \"1.4.2\"\n",
+        );
+        fs.add_file(
+            "deploy/unused.yaml",
+            "apiVersion: apps/v1\nkind: Deployment\nspec:\n  template:\n    spec:\n      containers:\n        - image: unused\n",
+        );
+        let tree = paths(&[
+            "deploy/base/deployment.yaml",
+            "deploy/base/kustomization.yaml",
+            "deploy/base/service.yaml",
+            "deploy/overlays/prod/kustomization.yaml",
+            "deploy/unused.yaml",
+        ]);
+
+        let manifest =
+            KubernetesDetector::detect(Path::new(""), Path::new(""), &tree, &fs).unwrap();
+        assert_eq!(manifest.manifest, "deploy/base/deployment.yaml");
+        assert_eq!(manifest.image.as_deref(), Some("ghcr.io/acme/orders:1.4.2"));
+    }
+
+    #[test]
+    fn test_image_overrides() {
+        let overrides = vec![ImageOverride {
+            name: "orders".to_string(),
+            new_name: Some("ghcr.io/acme/orders".to_string()),
+            new_tag: Some("1.4.2".to_string()),
+            digest: None,
+        }];
+        assert_eq!(
+            apply_overrides("orders:latest", &overrides),
+            "ghcr.io/acme/orders:1.4.2"
+        );
+        assert_eq!(
+            apply_overrides("billing:latest", &overrides),
+            "billing:latest"
+        );
+    }
+
+    #[test]
+    fn test_helm_templates_and_other_kinds_ignored() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "chart/templates/deployment.yaml",
+            "kind: Deployment\nspec:\n  replicas: {{ .Values.replicas }}\n",
+        );
+        fs.add_file("config.yaml", "kind: ConfigMap\ndata:\n  LOG_LEVEL: info\n");
+        assert_eq!(
+            KubernetesDetector::detect(
+                Path::new(""),
+                Path::new(""),
+                &paths(&["chart/templates/deployment.yaml", "config.yaml"]),
+                &fs
+            ),
+            None
+        );
+    }
+}
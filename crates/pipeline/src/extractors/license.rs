@@ -0,0 +1,448 @@
+//! License detector - SPDX identifier of the license a service is distributed under
+
+use peelbox_core::fs::FileSystem;
+use std::collections::HashMap;
+use std::path::{Path, PathBuf};
+
+/// License files looked for in the service directory, then the repository root
+const LICENSE_FILES: [&str; 3] = ["LICENSE", "LICENSE.md", "LICENSE.txt"];
+
+/// Bytes of a license file compared against the known texts
+const SAMPLE_BYTES: usize = 1000;
+
+/// Cosine similarity a license file needs to be reported as a known license
+const MATCH_THRESHOLD: f64 = 0.9;
+
+/// Reported for a license file that matches no known text
+pub const UNKNOWN_LICENSE: &str = "unknown";
+
+const SPDX_TAG: &str = "SPDX-License-Identifier:";
+
+/// Opening of each known license as it appears in a LICENSE file
+const KNOWN_LICENSES: [(&str, &str); 9] = [
+    (
+        "MIT",
+        r#"MIT License
+
+Copyright (c) <year> <copyright holders>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+"#,
+    ),
+    (
+        "Apache-2.0",
+        r#"
+                                 Apache License
+                           Version 2.0, January 2004
+                        http://www.apache.org/licenses/
+
+   TERMS AND CONDITIONS FOR USE, REPRODUCTION, AND DISTRIBUTION
+
+   1. Definitions.
+
+      "License" shall mean the terms and conditions for use, reproduction,
+      and distribution as defined by Sections 1 through 9 of this document.
+
+      "Licensor" shall mean the copyright owner or entity authorized by
+      the copyright owner that is granting the License.
+
+      "Legal Entity" shall mean the union of the acting entity and all
+      other entities that control, are controlled by, or are under common
+      control with that entity. For the purposes of this definition,
+      "control" means (i) the power, direct or indirect, to cause the
+      direction or management of such entity, whether by contract or
+      otherwise, or (ii) ownership of fifty percent (50%) or more of the
+      outstanding shares, or (iii) beneficial ownership of such entity.
+"#,
+    ),
+    (
+        "GPL-2.0",
+        r#"                    GNU GENERAL PUBLIC LICENSE
+                       Version 2, June 1991
+
+ Copyright (C) 1989, 1991 Free Software Foundation, Inc.,
+ 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA
+ Everyone is permitted to copy and distribute verbatim copies
+ of this license document, but changing it is not allowed.
+
+                            Preamble
+
+  The licenses for most software are designed to take away your
+freedom to share and change it.  By contrast, the GNU General Public
+License is intended to guarantee your freedom to share and change free
+software--to make sure the software is free for all its users.  This
+General Public License applies to most of the Free Software
+Foundation's software and to any other program whose authors commit to
+using it.  (Some other Free Software Foundation software is covered by
+the GNU Lesser General Public License instead.)  You can apply it to
+your programs, too.
+
+  When we speak of free software, we are referring to freedom, not
+price.  Our General Public Licenses are designed to make sure that you
+have the freedom to distribute copies of free software (and charge for
+this service if you wish), that you receive source code or can get it
+"#,
+    ),
+    (
+        "GPL-3.0",
+        r#"                    GNU GENERAL PUBLIC LICENSE
+                       Version 3, 29 June 2007
+
+ Copyright (C) 2007 Free Software Foundation, Inc. <https://fsf.org/>
+ Everyone is permitted to copy and distribute verbatim copies
+ of this license document, but changing it is not allowed.
+
+                            Preamble
+
+  The GNU General Public License is a free, copyleft license for
+software and other kinds of works.
+
+  The licenses for most software and other practical works are designed
+to take away your freedom to share and change the works.  By contrast,
+the GNU General Public License is intended to guarantee your freedom to
+share and change all versions of a program--to make sure it remains free
+software for all its users.  We, the Free Software Foundation, use the
+GNU General Public License for most of our software; it applies also to
+any other work released this way by its authors.  You can apply it to
+your programs, too.
+
+  When we speak of free software, we are referring to freedom, not
+price.  Our General Public Licenses are designed to make sure that you
+"#,
+    ),
+    (
+        "AGPL-3.0",
+        r#"                    GNU AFFERO GENERAL PUBLIC LICENSE
+                       Version 3, 19 November 2007
+
+ Copyright (C) 2007 Free Software Foundation, Inc. <https://fsf.org/>
+ Everyone is permitted to copy and distribute verbatim copies
+ of this license document, but changing it is not allowed.
+
+                            Preamble
+
+  The GNU Affero General Public License is a free, copyleft license for
+software and other kinds of works, specifically designed to ensure
+cooperation with the community in the case of network server software.
+
+  The licenses for most software and other practical works are designed
+to take away your freedom to share and change the works.  By contrast,
+our General Public Licenses are intended to guarantee your freedom to
+share and change all versions of a program--to make sure it remains free
+software for all its users.
+
+  When we speak of free software, we are referring to freedom, not
+price.  Our General Public Licenses are designed to make sure that you
+have the freedom to distribute copies of free software (and charge for
+"#,
+    ),
+    (
+        "MPL-2.0",
+        r#"Mozilla Public License Version 2.0
+==================================
+
+1. Definitions
+--------------
+
+1.1. "Contributor"
+    means each individual or legal entity that creates, contributes to
+    the creation of, or owns Covered Software.
+
+1.2. "Contributor Version"
+    means the combination of the Contributions of others (if any) used
+    by a Contributor and that particular Contributor's Contribution.
+
+1.3. "Contribution"
+    means Covered Software of a particular Contributor.
+
+1.4. "Covered Software"
+    means Source Code Form to which the initial Contributor has attached
+    the notice in Exhibit A, the Executable Form of such Source Code
+    Form, and Modifications of such Source Code Form, in each case
+    including portions thereof.
+
+1.5. "Incompatible With Secondary Licenses"
+    means
+
+    (a) that the initial Contributor has attached the notice described
+        in Exhibit B to the Covered Software; or
+"#,
+    ),
+    (
+        "BSD-2-Clause",
+        r#"BSD 2-Clause License
+
+Copyright (c) <year>, <copyright holder>
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+"#,
+    ),
+    (
+        "BSD-3-Clause",
+        r#"BSD 3-Clause License
+
+Copyright (c) <year>, <copyright holder>
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its
+   contributors may be used to endorse or promote products derived from
+   this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+"#,
+    ),
+    (
+        "ISC",
+        r#"ISC License
+
+Copyright (c) <year> <copyright holders>
+
+Permission to use, copy, modify, and/or distribute this software for any
+purpose with or without fee is hereby granted, provided that the above
+copyright notice and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+"#,
+    ),
+];
+
+pub struct LicenseDetector;
+
+impl LicenseDetector {
+    /// License of the service, from the first evidence found:
+    ///
+    /// 1. a `LICENSE`/`LICENSE.md` file in the service directory or repository root, matched
+    ///    against the known license texts (or its `SPDX-License-Identifier` line)
+    /// 2. the `license` field of package.json
+    /// 3. an `SPDX-License-Identifier` header in one of the service's files
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<String> {
+        let service_dir = repo_path.join(service_path);
+        let license_file = [service_dir.as_path(), repo_path]
+            .iter()
+            .flat_map(|dir| LICENSE_FILES.iter().map(move |name| dir.join(name)))
+            .find(|path| fs.is_file(path));
+        if let Some(path) = license_file {
+            let sample = fs.read_bytes(&path, SAMPLE_BYTES).ok()?;
+            return Some(identify(&String::from_utf8_lossy(&sample)));
+        }
+
+        if let Some(license) = fs
+            .read_to_string(&service_dir.join("package.json"))
+            .ok()
+            .and_then(|content| package_json_license(&content))
+        {
+            return Some(license);
+        }
+
+        file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter_map(|relative| fs.read_bytes(&service_dir.join(relative), 512).ok())
+            .find_map(|header| spdx_identifier(&String::from_utf8_lossy(&header)))
+    }
+}
+
+/// SPDX identifier of the best-matching known license, or `unknown` below the threshold
+fn identify(sample: &str) -> String {
+    if let Some(id) = spdx_identifier(sample) {
+        return id;
+    }
+
+    let sample = term_frequencies(sample);
+    KNOWN_LICENSES
+        .iter()
+        .map(|(id, text)| {
+            (
+                *id,
+                cosine_similarity(&sample, &term_frequencies(truncate(text))),
+            )
+        })
+        .filter(|(_, similarity)| *similarity > MATCH_THRESHOLD)
+        .max_by(|a, b| a.1.total_cmp(&b.1))
+        .map(|(id, _)| id.to_string())
+        .unwrap_or_else(|| UNKNOWN_LICENSE.to_string())
+}
+
+/// The known texts are compared over the same length as the sampled file
+fn truncate(text: &str) -> &str {
+    let mut end = text.len().min(SAMPLE_BYTES);
+    while !text.is_char_boundary(end) {
+        end -= 1;
+    }
+    &text[..end]
+}
+
+fn spdx_identifier(text: &str) -> Option<String> {
+    text.lines()
+        .take(10)
+        .find_map(|line| line.split_once(SPDX_TAG))
+        .map(|(_, id)| id.trim().trim_end_matches("*/").trim().to_string())
+        .filter(|id| !id.is_empty())
+}
+
+/// `"license": "MIT"`, or the older `"license": {"type": "MIT"}` form
+fn package_json_license(content: &str) -> Option<String> {
+    let json: serde_json::Value = serde_json::from_str(content).ok()?;
+    let license = json.get("license")?;
+    license
+        .as_str()
+        .or_else(|| license.get("type").and_then(|t| t.as_str()))
+        .map(str::to_string)
+}
+
+fn term_frequencies(text: &str) -> HashMap<String, f64> {
+    let mut terms = HashMap::new();
+    for word in text
+        .split(|c: char| !c.is_alphanumeric())
+        .filter(|word| !word.is_empty())
+    {
+        *terms.entry(word.to_lowercase()).or_insert(0.0) += 1.0;
+    }
+    terms
+}
+
+fn cosine_similarity(a: &HashMap<String, f64>, b: &HashMap<String, f64>) -> f64 {
+    let dot: f64 = a
+        .iter()
+        .filter_map(|(term, weight)| b.get(term).map(|other| weight * other))
+        .sum();
+    let norm = |v: &HashMap<String, f64>| v.values().map(|w| w * w).sum::<f64>().sqrt();
+    let denominator = norm(a) * norm(b);
+    if denominator == 0.0 {
+        0.0
+    } else {
+        dot / denominator
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn license_of(file: &str, content: &str) -> Option<String> {
+        let fs = MockFileSystem::new();
+        fs.add_file(file, content);
+        LicenseDetector::detect(Path::new(""), Path::new(""), &[PathBuf::from(file)], &fs)
+    }
+
+    #[test]
+    fn test_mit() {
+        let text = "The MIT License (MIT)\n\nCopyright (c) 2019-2024 Acme Corporation\n\nPermission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the \"Software\"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:\n\nThe above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.\n\nTHE SOFTWARE IS PROVIDED \"AS IS\", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.\n";
+        assert_eq!(license_of("LICENSE", text).as_deref(), Some("MIT"));
+    }
+
+    #[test]
+    fn test_apache() {
+        let text = "Apache License\nVersion 2.0, January 2004\nhttp://www.apache.org/licenses/\n\nTERMS AND CONDITIONS FOR USE, REPRODUCTION, AND DISTRIBUTION\n\n1. Definitions.\n\n\"License\" shall mean the terms and conditions for use, reproduction, and distribution as defined by Sections 1 through 9 of this document.\n\n\"Licensor\" shall mean the copyright owner or entity authorized by the copyright owner that is granting the License.\n\n\"Legal Entity\" shall mean the union of the acting entity and all other entities that control, are controlled by, or are under common control with that entity. For the purposes of this definition, \"control\" means (i) the power, direct or indirect, to cause the direction or management of such entity, whether by contract or otherwise, or (ii) ownership of fifty percent (50%) or more of the outstanding shares, or (iii) beneficial ownership of such entity.\n";
+        assert_eq!(license_of("LICENSE", text).as_deref(), Some("Apache-2.0"));
+    }
+
+    #[test]
+    fn test_gpl3_is_not_agpl() {
+        let text = "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n\nCopyright (C) 2007 Free Software Foundation, Inc. <https://fsf.org/>\nEveryone is permitted to copy and distribute verbatim copies of this license document, but changing it is not allowed.\n\nPreamble\n\nThe GNU General Public License is a free, copyleft license for software and other kinds of works.\n\nThe licenses for most software and other practical works are designed to take away your freedom to share and change the works. By contrast, the GNU General Public License is intended to guarantee your freedom to share and change all versions of a program--to make sure it remains free software for all its users.\n";
+        assert_eq!(license_of("LICENSE.md", text).as_deref(), Some("GPL-3.0"));
+    }
+
+    #[test]
+    fn test_bsd3_is_not_bsd2() {
+        let text = "Copyright (c) 2015, The Gorilla Authors. All rights reserved.\n\nRedistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:\n\n  * Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.\n\n  * Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.\n\n  * Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.\n\nTHIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS \"AS IS\" AND ANY EXPRESS OR IMPLIED WARRANTIES";
+        assert_eq!(license_of("LICENSE", text).as_deref(), Some("BSD-3-Clause"));
+    }
+
+    #[test]
+    fn test_isc() {
+        let text = "ISC License\n\nCopyright (c) 2010-2023 Isaac Z. Schlueter and Contributors\n\nPermission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.\n\nTHE SOFTWARE IS PROVIDED \"AS IS\" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.\n";
+        assert_eq!(license_of("LICENSE", text).as_deref(), Some("ISC"));
+    }
+
+    #[test]
+    fn test_mpl() {
+        let text = "Mozilla Public License Version 2.0\n==================================\n\n1. Definitions\n--------------\n\n1.1. \"Contributor\"\n    means each individual or legal entity that creates, contributes to\n    the creation of, or owns Covered Software.\n\n1.2. \"Contributor Version\"\n    means the combination of the Contributions of others (if any) used\n    by a Contributor and that particular Contributor's Contribution.\n\n1.3. \"Contribution\"\n    means Covered Software of a particular Contributor.\n\n1.4. \"Covered Software\"\n    means Source Code Form to which the initial Contributor has attached\n    the notice in Exhibit A, the Executable Form of such Source Code\n    Form, and Modifications of such Source Code Form, in each case\n    including portions thereof.\n";
+        assert_eq!(license_of("LICENSE", text).as_deref(), Some("MPL-2.0"));
+    }
+
+    #[test]
+    fn test_unrecognised_license_file() {
+        let text = "All rights reserved. No part of this software may be copied without the written consent of Acme Corporation.\n";
+        assert_eq!(license_of("LICENSE", text).as_deref(), Some("unknown"));
+    }
+
+    #[test]
+    fn test_spdx_and_package_json_fallbacks() {
+        assert_eq!(
+            license_of("LICENSE", "SPDX-License-Identifier: Apache-2.0 OR MIT\n").as_deref(),
+            Some("Apache-2.0 OR MIT")
+        );
+        assert_eq!(
+            license_of(
+                "package.json",
+                r#"{"name": "app", "license": "BSD-2-Clause"}"#
+            )
+            .as_deref(),
+            Some("BSD-2-Clause")
+        );
+        assert_eq!(
+            license_of(
+                "src/main.rs",
+                "// SPDX-License-Identifier: MPL-2.0\nfn main() {}\n"
+            )
+            .as_deref(),
+            Some("MPL-2.0")
+        );
+        assert_eq!(license_of("src/main.rs", "fn main() {}\n"), None);
+    }
+}
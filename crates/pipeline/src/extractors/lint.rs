@@ -0,0 +1,149 @@
+//! Lint detector - linters and static analysis tools configured for a service
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::LintTool;
+use std::path::{Path, PathBuf};
+
+/// Config file names, the tool reading them and the command running it
+const LINT_CONFIGS: [(&str, &str, &str); 24] = [
+    (".golangci.yml", "golangci-lint", "golangci-lint run"),
+    (".golangci.yaml", "golangci-lint", "golangci-lint run"),
+    (".golangci.toml", "golangci-lint", "golangci-lint run"),
+    (".golangci.json", "golangci-lint", "golangci-lint run"),
+    ("staticcheck.conf", "staticcheck", "staticcheck ./..."),
+    ("revive.toml", "revive", "revive -config revive.toml ./..."),
+    (".eslintrc", "eslint", "npx eslint ."),
+    (".eslintrc.js", "eslint", "npx eslint ."),
+    (".eslintrc.cjs", "eslint", "npx eslint ."),
+    (".eslintrc.json", "eslint", "npx eslint ."),
+    (".eslintrc.yml", "eslint", "npx eslint ."),
+    (".eslintrc.yaml", "eslint", "npx eslint ."),
+    ("eslint.config.js", "eslint", "npx eslint ."),
+    ("eslint.config.mjs", "eslint", "npx eslint ."),
+    ("eslint.config.cjs", "eslint", "npx eslint ."),
+    ("eslint.config.ts", "eslint", "npx eslint ."),
+    ("biome.json", "biome", "npx @biomejs/biome lint ."),
+    ("ruff.toml", "ruff", "ruff check ."),
+    (".ruff.toml", "ruff", "ruff check ."),
+    (".flake8", "flake8", "flake8"),
+    (".pylintrc", "pylint", "pylint ."),
+    ("clippy.toml", "clippy", "cargo clippy"),
+    (".rubocop.yml", "rubocop", "rubocop"),
+    ("phpstan.neon", "phpstan", "vendor/bin/phpstan analyse"),
+];
+
+pub struct LintDetector;
+
+impl LintDetector {
+    /// Lint configs at the top of the service directory, in the order of `file_tree`
+    /// (repository-relative)
+    ///
+    /// Ruff is also recognised from a `[tool.ruff]` table in pyproject.toml.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Vec<LintTool> {
+        let mut tools: Vec<LintTool> = Vec::new();
+
+        for relative in file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| path.components().count() == 1)
+        {
+            let name = relative.to_string_lossy();
+            let found = match LINT_CONFIGS.iter().find(|(config, _, _)| *config == name) {
+                Some((_, tool, command)) => Some((*tool, *command)),
+                None if name == "pyproject.toml" => fs
+                    .read_to_string(&repo_path.join(service_path).join(relative))
+                    .ok()
+                    .filter(|content| {
+                        content
+                            .lines()
+                            .any(|line| line.trim_start().starts_with("[tool.ruff"))
+                    })
+                    .map(|_| ("ruff", "ruff check .")),
+                None => None,
+            };
+
+            if let Some((tool, command)) = found {
+                if tools.iter().any(|existing| existing.tool == tool) {
+                    continue;
+                }
+                tools.push(LintTool {
+                    tool: tool.to_string(),
+                    config: name.to_string(),
+                    command: command.to_string(),
+                });
+            }
+        }
+
+        tools
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    #[test]
+    fn test_go_linters() {
+        let fs = MockFileSystem::new();
+        let tools = LintDetector::detect(
+            Path::new(""),
+            Path::new("api"),
+            &paths(&[
+                "api/.golangci.yml",
+                "api/go.mod",
+                "api/revive.toml",
+                "api/tools/.golangci.yml",
+                "web/.eslintrc.json",
+            ]),
+            &fs,
+        );
+        assert_eq!(
+            tools,
+            vec![
+                LintTool {
+                    tool: "golangci-lint".to_string(),
+                    config: ".golangci.yml".to_string(),
+                    command: "golangci-lint run".to_string(),
+                },
+                LintTool {
+                    tool: "revive".to_string(),
+                    config: "revive.toml".to_string(),
+                    command: "revive -config revive.toml ./...".to_string(),
+                },
+            ]
+        );
+    }
+
+    #[test]
+    fn test_ruff_in_pyproject() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "pyproject.toml",
+            "[project]\nname = \"app\"\n\n[tool.ruff.lint]\nselect = [\"E\", \"F\"]\n",
+        );
+        let tools = LintDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&[".eslintrc", "pyproject.toml"]),
+            &fs,
+        );
+        let found: Vec<(&str, &str)> = tools
+            .iter()
+            .map(|t| (t.tool.as_str(), t.config.as_str()))
+            .collect();
+        assert_eq!(
+            found,
+            vec![("eslint", ".eslintrc"), ("ruff", "pyproject.toml")]
+        );
+    }
+}
@@ -0,0 +1,217 @@
+//! Live reload detector - the command developers run for a reloading local server
+
+use peelbox_core::fs::FileSystem;
+use peelbox_stack::LanguageId;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+/// Module paths air was published under
+const AIR_MODULES: [&str; 2] = ["github.com/air-verse/air", "github.com/cosmtrek/air"];
+
+/// Files whose command lines start a uvicorn dev server
+const PYTHON_LAUNCHERS: [&str; 5] = [
+    "Makefile",
+    "Procfile",
+    "docker-compose.yml",
+    "docker-compose.yaml",
+    "compose.yaml",
+];
+
+pub struct LiveReloadDetector;
+
+impl LiveReloadDetector {
+    /// Dev run command for the service among `file_tree` (repository-relative): `air` for Go,
+    /// `nodemon` for Node.js, `uvicorn --reload` for Python; `None` without live reload set up
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        language: &LanguageId,
+        dependencies: &[String],
+    ) -> Option<String> {
+        let files: Vec<&Path> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .collect();
+        let read = |relative: &Path| {
+            fs.read_to_string(&repo_path.join(service_path).join(relative))
+                .ok()
+        };
+
+        match language {
+            LanguageId::Go => {
+                let tool =
+                    read(Path::new("go.mod")).is_some_and(|go_mod| declares_air_tool(&go_mod));
+                let config = [".air.toml", "air.toml"]
+                    .into_iter()
+                    .find(|name| files.contains(&Path::new(name)));
+                let air = if tool { "go tool air" } else { "air" };
+                match config {
+                    Some("air.toml") => Some(format!("{} -c air.toml", air)),
+                    Some(_) => Some(air.to_string()),
+                    None => tool.then(|| air.to_string()),
+                }
+            }
+            LanguageId::JavaScript | LanguageId::TypeScript => (files
+                .contains(&Path::new("nodemon.json"))
+                || dependencies.iter().any(|dep| dep == "nodemon"))
+            .then(|| "npx nodemon".to_string()),
+            LanguageId::Python => {
+                let command = Regex::new(r#"uvicorn\s+[\w.:]+[^\n"'\]]*--reload[^\n"'\]]*"#)
+                    .expect("valid uvicorn regex");
+                let in_code = Regex::new(r"uvicorn\.run\([^)]*reload\s*=\s*True")
+                    .expect("valid uvicorn.run regex");
+                files
+                    .iter()
+                    .filter(|path| {
+                        path.to_str()
+                            .is_some_and(|name| PYTHON_LAUNCHERS.contains(&name))
+                            || path.extension().is_some_and(|ext| ext == "sh")
+                    })
+                    .find_map(|path| {
+                        let content = read(*path)?;
+                        let found = command.find(&content)?;
+                        Some(found.as_str().trim().to_string())
+                    })
+                    .or_else(|| {
+                        files
+                            .iter()
+                            .filter(|path| path.extension().is_some_and(|ext| ext == "py"))
+                            .find(|path| {
+                                read(**path).is_some_and(|content| in_code.is_match(&content))
+                            })
+                            .map(|path| format!("python {}", path.display()))
+                    })
+            }
+            _ => None,
+        }
+    }
+}
+
+/// go.mod declares air in a `tool` directive (Go 1.24+), single-line or block
+fn declares_air_tool(go_mod: &str) -> bool {
+    let mut in_tool_block = false;
+    go_mod.lines().any(|line| {
+        let line = line.split("//").next().unwrap_or_default().trim();
+        let tool = if in_tool_block {
+            in_tool_block = line != ")";
+            line
+        } else if line == "tool (" {
+            in_tool_block = true;
+            return false;
+        } else if let Some(tool) = line.strip_prefix("tool ") {
+            tool.trim()
+        } else {
+            return false;
+        };
+        AIR_MODULES.contains(&tool)
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    fn detect(
+        fs: &MockFileSystem,
+        tree: &[&str],
+        language: LanguageId,
+        dependencies: &[&str],
+    ) -> Option<String> {
+        let dependencies: Vec<String> = dependencies.iter().map(|d| d.to_string()).collect();
+        LiveReloadDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(tree),
+            fs,
+            &language,
+            &dependencies,
+        )
+    }
+
+    #[test]
+    fn test_air_config() {
+        let fs = MockFileSystem::new();
+        fs.add_file("go.mod", "module example.com/blog\n\ngo 1.22\n");
+        assert_eq!(
+            detect(&fs, &["go.mod", ".air.toml"], LanguageId::Go, &[]).as_deref(),
+            Some("air")
+        );
+        assert_eq!(
+            detect(&fs, &["go.mod", "air.toml"], LanguageId::Go, &[]).as_deref(),
+            Some("air -c air.toml")
+        );
+        assert_eq!(detect(&fs, &["go.mod"], LanguageId::Go, &[]), None);
+    }
+
+    #[test]
+    fn test_air_tool_directive() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "go.mod",
+            "module example.com/blog\n\ngo 1.24\n\ntool (\n\tgithub.com/air-verse/air\n\tgolang.org/x/tools/cmd/stringer\n)\n",
+        );
+        assert_eq!(
+            detect(&fs, &["go.mod"], LanguageId::Go, &[]).as_deref(),
+            Some("go tool air")
+        );
+
+        fs.add_file(
+            "go.mod",
+            "module example.com/blog\n\ngo 1.24\n\ntool golang.org/x/tools/cmd/stringer\n",
+        );
+        assert_eq!(detect(&fs, &["go.mod"], LanguageId::Go, &[]), None);
+    }
+
+    #[test]
+    fn test_nodemon() {
+        let fs = MockFileSystem::new();
+        assert_eq!(
+            detect(
+                &fs,
+                &["package.json", "nodemon.json"],
+                LanguageId::JavaScript,
+                &[]
+            )
+            .as_deref(),
+            Some("npx nodemon")
+        );
+        assert_eq!(
+            detect(&fs, &["package.json"], LanguageId::TypeScript, &["nodemon"]).as_deref(),
+            Some("npx nodemon")
+        );
+        assert_eq!(
+            detect(&fs, &["package.json"], LanguageId::JavaScript, &["express"]),
+            None
+        );
+    }
+
+    #[test]
+    fn test_uvicorn_reload() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "Makefile",
+            "dev:\n\tuvicorn app.main:app --reload --port 8000\n\nrun:\n\tuvicorn app.main:app\n",
+        );
+        assert_eq!(
+            detect(&fs, &["Makefile", "app/main.py"], LanguageId::Python, &[]).as_deref(),
+            Some("uvicorn app.main:app --reload --port 8000")
+        );
+
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "main.py",
+            "import uvicorn\n\nif __name__ == \"__main__\":\n    uvicorn.run(\"main:app\", host=\"0.0.0.0\", reload=True)\n",
+        );
+        assert_eq!(
+            detect(&fs, &["main.py"], LanguageId::Python, &[]).as_deref(),
+            Some("python main.py")
+        );
+    }
+}
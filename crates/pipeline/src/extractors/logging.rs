@@ -0,0 +1,267 @@
+//! Logging detector - the structured logging library of a Go or Python service and the format
+//! its records come out in
+
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+/// Environment variable a service conventionally reads its log level from
+pub const LOG_LEVEL_ENV: &str = "LOG_LEVEL";
+
+/// The standard library's structured logger, found by import since go.mod never lists it
+const SLOG: &str = "log/slog";
+
+/// A logging library: the dependency it is detected from, the name reported for it, the format
+/// it writes unless configured otherwise, and the calls that switch it to JSON, if it has any
+struct Library {
+    dependency: &'static str,
+    name: &'static str,
+    default_format: &'static str,
+    json_config: Option<&'static str>,
+}
+
+/// Third-party libraries win over slog, which zap and zerolog can also back
+const LIBRARIES: [Library; 5] = [
+    Library {
+        dependency: "go.uber.org/zap",
+        name: "zap",
+        default_format: "json",
+        json_config: Some(r"\bzap\.NewProduction(?:Config)?\("),
+    },
+    Library {
+        dependency: "github.com/rs/zerolog",
+        name: "zerolog",
+        default_format: "json",
+        // Writes JSON and nothing else, short of a development ConsoleWriter
+        json_config: None,
+    },
+    Library {
+        dependency: "github.com/sirupsen/logrus",
+        name: "logrus",
+        default_format: "text",
+        json_config: Some(r"\bJSONFormatter\{"),
+    },
+    Library {
+        dependency: "structlog",
+        name: "structlog",
+        default_format: "json",
+        json_config: Some(r"\bJSONRenderer\("),
+    },
+    Library {
+        dependency: "loguru",
+        name: "loguru",
+        default_format: "text",
+        json_config: Some(r"\bserialize\s*=\s*True\b"),
+    },
+];
+
+const SLOG_LIBRARY: Library = Library {
+    dependency: SLOG,
+    name: "slog",
+    default_format: "json",
+    json_config: Some(r"\bslog\.NewJSONHandler\("),
+};
+
+/// A service logging through a known library
+#[derive(Debug, Clone, PartialEq)]
+pub struct LoggingUsage {
+    pub library: &'static str,
+    /// "json" or "text": the format platforms should expect to ingest
+    pub format_hint: &'static str,
+    /// The source configures JSON output itself, e.g. with `zap.NewProduction()`
+    pub json_configured: bool,
+}
+
+pub struct LoggingDetector;
+
+impl LoggingDetector {
+    /// Detects the library among `dependencies` (go.mod modules or Python requirements), or a
+    /// `log/slog` import, and scans the service's Go and Python files among `file_tree`
+    /// (repository-relative) for the calls that configure JSON output
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        dependencies: &[String],
+    ) -> Option<LoggingUsage> {
+        let sources: Vec<String> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| {
+                path.file_name()
+                    .and_then(|name| name.to_str())
+                    .is_some_and(is_source)
+            })
+            .filter_map(|path| {
+                fs.read_to_string(&repo_path.join(service_path).join(path))
+                    .ok()
+            })
+            .collect();
+
+        let library = LIBRARIES
+            .iter()
+            .find(|library| {
+                dependencies.iter().any(|dependency| {
+                    dependency.eq_ignore_ascii_case(library.dependency)
+                        || dependency.starts_with(&format!("{}/", library.dependency))
+                })
+            })
+            .or_else(|| {
+                let import = format!("\"{}\"", SLOG);
+                sources
+                    .iter()
+                    .any(|source| source.contains(&import))
+                    .then_some(&SLOG_LIBRARY)
+            })?;
+
+        let json_configured = library.json_config.is_some_and(|pattern| {
+            let json_re = Regex::new(pattern).expect("valid JSON config regex");
+            sources.iter().any(|source| json_re.is_match(source))
+        });
+
+        Some(LoggingUsage {
+            library: library.name,
+            format_hint: if json_configured {
+                "json"
+            } else {
+                library.default_format
+            },
+            json_configured,
+        })
+    }
+}
+
+/// Go and Python sources, leaving out tests, which often log through a development setup
+fn is_source(name: &str) -> bool {
+    (name.ends_with(".go") && !name.ends_with("_test.go"))
+        || (name.ends_with(".py") && !name.starts_with("test_") && !name.ends_with("_test.py"))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn detect(files: &[(&str, &str)], dependencies: &[&str]) -> Option<LoggingUsage> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        let dependencies: Vec<String> = dependencies.iter().map(|d| d.to_string()).collect();
+        LoggingDetector::detect(Path::new(""), Path::new("svc"), &tree, &fs, &dependencies)
+    }
+
+    fn usage(library: &'static str, format_hint: &'static str, json: bool) -> LoggingUsage {
+        LoggingUsage {
+            library,
+            format_hint,
+            json_configured: json,
+        }
+    }
+
+    #[test]
+    fn test_zap_production_logger() {
+        let main = "package main\n\nimport \"go.uber.org/zap\"\n\nfunc main() {\n\tlogger, _ := zap.NewProduction()\n\tdefer logger.Sync()\n}\n";
+        assert_eq!(
+            detect(&[("svc/main.go", main)], &["go.uber.org/zap"]),
+            Some(usage("zap", "json", true))
+        );
+        assert_eq!(
+            detect(
+                &[(
+                    "svc/main.go",
+                    "package main\n\nvar logger, _ = zap.NewDevelopment()\n"
+                )],
+                &["go.uber.org/zap"],
+            ),
+            Some(usage("zap", "json", false))
+        );
+    }
+
+    #[test]
+    fn test_zerolog() {
+        assert_eq!(
+            detect(
+                &[(
+                    "svc/main.go",
+                    "package main\n\nimport \"github.com/rs/zerolog/log\"\n"
+                )],
+                &["github.com/rs/zerolog"],
+            ),
+            Some(usage("zerolog", "json", false))
+        );
+    }
+
+    #[test]
+    fn test_logrus_text_unless_json_formatter() {
+        assert_eq!(
+            detect(&[], &["github.com/sirupsen/logrus"]),
+            Some(usage("logrus", "text", false))
+        );
+        assert_eq!(
+            detect(
+                &[(
+                    "svc/log.go",
+                    "package main\n\nfunc init() {\n\tlogrus.SetFormatter(&logrus.JSONFormatter{})\n}\n",
+                )],
+                &["github.com/sirupsen/logrus"],
+            ),
+            Some(usage("logrus", "json", true))
+        );
+    }
+
+    #[test]
+    fn test_slog_import() {
+        let main = "package main\n\nimport (\n\t\"log/slog\"\n\t\"os\"\n)\n\nvar logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))\n";
+        assert_eq!(
+            detect(&[("svc/main.go", main)], &["github.com/go-chi/chi/v5"]),
+            Some(usage("slog", "json", true))
+        );
+        // zap backing slog is still zap
+        assert_eq!(
+            detect(&[("svc/main.go", main)], &["go.uber.org/zap"]),
+            Some(usage("zap", "json", false))
+        );
+    }
+
+    #[test]
+    fn test_python_libraries() {
+        assert_eq!(
+            detect(
+                &[(
+                    "svc/app.py",
+                    "structlog.configure(processors=[structlog.processors.JSONRenderer()])\n",
+                )],
+                &["fastapi", "structlog"],
+            ),
+            Some(usage("structlog", "json", true))
+        );
+        assert_eq!(
+            detect(
+                &[(
+                    "svc/app.py",
+                    "from loguru import logger\n\nlogger.add(sys.stderr, serialize=True)\n",
+                )],
+                &["Loguru"],
+            ),
+            Some(usage("loguru", "json", true))
+        );
+        assert_eq!(
+            detect(&[], &["loguru"]),
+            Some(usage("loguru", "text", false))
+        );
+    }
+
+    #[test]
+    fn test_no_logging_library() {
+        assert_eq!(
+            detect(
+                &[("svc/main.go", "package main\n\nimport \"log\"\n")],
+                &["github.com/gin-gonic/gin"],
+            ),
+            None
+        );
+    }
+}
@@ -0,0 +1,336 @@
+//! Migration detector - schema migration tools to run against the database before deploying
+
+use super::required_tools::{go_mod_requirements, within_module};
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::RequiredTool;
+use std::path::{Path, PathBuf};
+
+/// Go migration libraries: tool, module prefix, CLI binary and how to install it
+const GO_TOOLS: [(&str, &str, &str, &str); 3] = [
+    (
+        "golang-migrate",
+        "github.com/golang-migrate/migrate",
+        "migrate",
+        "go install -tags 'postgres mysql sqlite3' github.com/golang-migrate/migrate/v4/cmd/migrate@latest",
+    ),
+    (
+        "goose",
+        "github.com/pressly/goose",
+        "goose",
+        "go install github.com/pressly/goose/v3/cmd/goose@latest",
+    ),
+    ("atlas", "ariga.io/atlas", "atlas", "curl -sSf https://atlasgo.sh | sh"),
+];
+
+/// Directories migrations conventionally live in, relative to the service
+const MIGRATION_DIRS: [&str; 2] = ["migrations", "db/migrations"];
+
+/// Database drivers and the dialect name goose expects for them
+const GOOSE_DRIVERS: [(&str, &str); 6] = [
+    ("github.com/jackc/pgx", "postgres"),
+    ("github.com/lib/pq", "postgres"),
+    ("github.com/go-sql-driver/mysql", "mysql"),
+    ("github.com/mattn/go-sqlite3", "sqlite3"),
+    ("modernc.org/sqlite", "sqlite3"),
+    ("github.com/microsoft/go-mssqldb", "mssql"),
+];
+
+/// A migration tool found for a service and the command applying its migrations
+#[derive(Debug, Clone, PartialEq)]
+pub struct Migrations {
+    /// golang-migrate, goose, atlas, flyway or liquibase
+    pub tool: String,
+    /// Migrations directory relative to the service
+    pub path: Option<String>,
+    /// Run from the service directory with `DATABASE_URL` set
+    pub command: String,
+    /// The migration CLI, for the service's required tools
+    pub required_tool: RequiredTool,
+}
+
+pub struct MigrationDetector;
+
+impl MigrationDetector {
+    /// Looks for a migration library in go.mod, then for Flyway and Liquibase configs, then
+    /// for a `migrations/` or `db/migrations/` directory whose file names or annotations
+    /// identify the tool, among the service's files in `file_tree` (repository-relative)
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<Migrations> {
+        let files: Vec<&Path> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .collect();
+        let read = |relative: &Path| {
+            fs.read_to_string(&repo_path.join(service_path).join(relative))
+                .ok()
+        };
+        let has = |name: &str| files.iter().any(|path| *path == Path::new(name));
+
+        let directory = MIGRATION_DIRS
+            .iter()
+            .find(|dir| files.iter().any(|path| path.starts_with(dir)))
+            .map(|dir| dir.to_string());
+        let dir = directory.as_deref().unwrap_or("migrations");
+
+        let requirements = if has("go.mod") {
+            read(Path::new("go.mod"))
+                .map(|content| go_mod_requirements(&content))
+                .unwrap_or_default()
+        } else {
+            vec![]
+        };
+        let required = |prefix: &str| {
+            requirements
+                .iter()
+                .find(|(module, _)| within_module(module, prefix))
+                .map(|(_, version)| version.clone())
+        };
+
+        for (tool, prefix, binary, install) in GO_TOOLS {
+            let Some(version) = required(prefix) else {
+                continue;
+            };
+            let command = match tool {
+                "golang-migrate" => format!("migrate -database $DATABASE_URL -path {} up", dir),
+                "goose" => {
+                    let driver = GOOSE_DRIVERS
+                        .iter()
+                        .find(|(driver, _)| required(*driver).is_some())
+                        .map_or("postgres", |(_, dialect)| *dialect);
+                    format!("goose -dir {} {} $DATABASE_URL up", dir, driver)
+                }
+                _ => format!(
+                    "atlas migrate apply --dir file://{} --url $DATABASE_URL",
+                    dir
+                ),
+            };
+            return Some(Migrations {
+                tool: tool.to_string(),
+                path: Some(dir.to_string()),
+                command,
+                required_tool: RequiredTool {
+                    name: binary.to_string(),
+                    version_hint: Some(format!(">={}", version)),
+                    install_command: Some(install.to_string()),
+                },
+            });
+        }
+
+        for config in ["flyway.conf", "conf/flyway.conf"] {
+            if has(config) {
+                return Some(Migrations::cli(
+                    "flyway",
+                    directory.clone(),
+                    format!("flyway -configFiles={} migrate", config),
+                ));
+            }
+        }
+        if has("liquibase.properties") {
+            return Some(Migrations::cli(
+                "liquibase",
+                directory,
+                "liquibase --defaults-file=liquibase.properties update".to_string(),
+            ));
+        }
+
+        // No library or config: recognise the tool from the migration files themselves
+        let directory = directory?;
+        let migrations: Vec<&Path> = files
+            .iter()
+            .filter(|path| path.starts_with(&directory))
+            .copied()
+            .collect();
+        let names = || {
+            migrations
+                .iter()
+                .filter_map(|path| path.file_name().and_then(|name| name.to_str()))
+        };
+
+        if names().any(|name| name.ends_with(".up.sql")) {
+            let command = format!("migrate -database $DATABASE_URL -path {} up", directory);
+            return Some(Migrations::cli("golang-migrate", Some(directory), command));
+        }
+        if names().any(is_flyway_migration) {
+            let command = format!(
+                "flyway -url=$DATABASE_URL -locations=filesystem:{} migrate",
+                directory
+            );
+            return Some(Migrations::cli("flyway", Some(directory), command));
+        }
+        if migrations
+            .iter()
+            .filter(|path| path.extension().is_some_and(|ext| ext == "sql"))
+            .any(|path| read(*path).is_some_and(|content| content.contains("-- +goose Up")))
+        {
+            let command = format!("goose -dir {} postgres $DATABASE_URL up", directory);
+            return Some(Migrations::cli("goose", Some(directory), command));
+        }
+        None
+    }
+}
+
+impl Migrations {
+    /// Migrations run by a CLI the service does not depend on as a library
+    fn cli(tool: &str, path: Option<String>, command: String) -> Self {
+        let (binary, install) = GO_TOOLS
+            .iter()
+            .find(|(name, ..)| *name == tool)
+            .map_or((tool, None), |(_, _, binary, install)| {
+                (*binary, Some(install.to_string()))
+            });
+        Self {
+            tool: tool.to_string(),
+            path,
+            command,
+            required_tool: RequiredTool {
+                name: binary.to_string(),
+                version_hint: None,
+                install_command: install,
+            },
+        }
+    }
+}
+
+/// Flyway's versioned migrations are named `V<version>__<description>.sql`
+fn is_flyway_migration(name: &str) -> bool {
+    name.strip_prefix('V')
+        .and_then(|rest| rest.split_once("__"))
+        .is_some_and(|(version, _)| {
+            !version.is_empty()
+                && version
+                    .chars()
+                    .all(|c| c.is_ascii_digit() || c == '.' || c == '_')
+        })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    #[test]
+    fn test_golang_migrate_from_go_mod() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "api/go.mod",
+            "module example.com/api\n\nrequire github.com/golang-migrate/migrate/v4 v4.17.0\n",
+        );
+        let tree = paths(&[
+            "api/db/migrations/000001_init.up.sql",
+            "api/db/migrations/000001_init.down.sql",
+            "api/go.mod",
+        ]);
+
+        let migrations =
+            MigrationDetector::detect(Path::new(""), Path::new("api"), &tree, &fs).unwrap();
+        assert_eq!(migrations.tool, "golang-migrate");
+        assert_eq!(migrations.path.as_deref(), Some("db/migrations"));
+        assert_eq!(
+            migrations.command,
+            "migrate -database $DATABASE_URL -path db/migrations up"
+        );
+        assert_eq!(migrations.required_tool.name, "migrate");
+        assert_eq!(
+            migrations.required_tool.version_hint.as_deref(),
+            Some(">=4.17.0")
+        );
+    }
+
+    #[test]
+    fn test_goose_dialect_from_driver() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "go.mod",
+            "module example.com/app\n\nrequire (\n\tgithub.com/go-sql-driver/mysql v1.8.1\n\tgithub.com/pressly/goose/v3 v3.21.1\n)\n",
+        );
+        let migrations = MigrationDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["go.mod", "migrations/20240101000000_init.sql"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(migrations.tool, "goose");
+        assert_eq!(
+            migrations.command,
+            "goose -dir migrations mysql $DATABASE_URL up"
+        );
+        assert_eq!(
+            migrations.required_tool.install_command.as_deref(),
+            Some("go install github.com/pressly/goose/v3/cmd/goose@latest")
+        );
+    }
+
+    #[test]
+    fn test_flyway_and_liquibase_configs() {
+        let fs = MockFileSystem::new();
+        let flyway = MigrationDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["conf/flyway.conf", "pom.xml"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(flyway.tool, "flyway");
+        assert_eq!(
+            flyway.command,
+            "flyway -configFiles=conf/flyway.conf migrate"
+        );
+        assert_eq!(flyway.required_tool.install_command, None);
+
+        let liquibase = MigrationDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["liquibase.properties"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(liquibase.tool, "liquibase");
+        assert_eq!(liquibase.path, None);
+    }
+
+    #[test]
+    fn test_tool_from_migration_files() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "migrations/001_users.sql",
+            "-- +goose Up\nCREATE TABLE users (id serial);\n",
+        );
+        let goose = MigrationDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["migrations/001_users.sql"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(goose.tool, "goose");
+
+        let flyway = MigrationDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["db/migrations/V1_2__add_orders.sql"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(
+            flyway.command,
+            "flyway -url=$DATABASE_URL -locations=filesystem:db/migrations migrate"
+        );
+
+        assert!(MigrationDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["migrations/README.md"]),
+            &fs
+        )
+        .is_none());
+    }
+}
@@ -4,14 +4,96 @@
 // runtime information like ports, environment variables, and health endpoints
 // without requiring LLM inference.
 
+pub mod backing_services;
+pub mod buf;
+pub mod build_tags;
+pub mod cgo;
+pub mod cli;
 pub mod common;
 pub mod context;
+pub mod dependency_depth;
+pub mod deprecations;
+pub mod devcontainer;
+pub mod embed;
 pub mod env_vars;
+pub mod feature_flags;
+pub mod framework_version;
+pub mod github_actions;
+pub mod go_generate;
+pub mod go_graph;
+pub mod go_replace;
+pub mod go_sum;
+pub mod go_test;
+pub mod go_tools;
+pub mod graphql;
+pub mod grpc;
 pub mod health;
+pub mod iac;
+pub mod kubernetes;
+pub mod license;
+pub mod lint;
+pub mod live_reload;
+pub mod logging;
+pub mod migrations;
+pub mod nix;
+pub mod observability;
+pub mod observability_artifacts;
+pub mod openapi;
 pub mod parsers;
+pub mod pnpm_workspace;
 pub mod port;
+pub mod project_type;
+pub mod release_tools;
+pub mod required_tools;
+pub mod resources;
+pub mod serverless;
+pub mod sqlc;
+pub mod temporal;
+pub mod toolchain;
+pub mod wasm;
 
+pub use backing_services::BackingServiceDetector;
+pub use buf::{BufDetector, BUF_GENERATE, BUF_TOOLCHAIN};
+pub use build_tags::BuildTagDetector;
+pub use cgo::{CgoDetector, CgoUsage};
+pub use cli::{CliDetector, CliUsage};
 pub use context::ServiceContext;
+pub use dependency_depth::{DependencyDepth, DependencyDepthAnalyzer, GO_MOD_GRAPH};
+pub use deprecations::DeprecationChecker;
+pub use devcontainer::DevContainerDetector;
+pub use embed::EmbedDetector;
 pub use env_vars::{EnvVarExtractor, EnvVarInfo, EnvVarSource};
+pub use feature_flags::FeatureFlagDetector;
+pub use framework_version::{resolve_version, FrameworkVersionResolver};
+pub use github_actions::{CiGoVersion, CiWorkflows, GitHubActionsDetector};
+pub use go_generate::{GoGenerate, GoGenerateDetector};
+pub use go_graph::{build_dependency_graph, DependencyGraph, DependencyKind, Module};
+pub use go_replace::{ReplaceDirective, ReplaceDirectiveAnalyzer, ReplaceKind};
+pub use go_sum::{GoSumStatus, GoSumValidator};
+pub use go_test::{GoTestDetector, GoTests};
+pub use go_tools::GoToolDirectiveDetector;
+pub use graphql::{GraphQL, GraphQLDetector};
+pub use grpc::GrpcDetector;
 pub use health::{HealthCheckExtractor, HealthCheckInfo, HealthCheckSource};
+pub use iac::{IacDetector, IacProject};
+pub use kubernetes::KubernetesDetector;
+pub use license::LicenseDetector;
+pub use lint::LintDetector;
+pub use live_reload::LiveReloadDetector;
+pub use logging::{LoggingDetector, LoggingUsage, LOG_LEVEL_ENV};
+pub use migrations::{MigrationDetector, Migrations};
+pub use nix::{NixDetector, NixEnvironment};
+pub use observability::ObservabilityDetector;
+pub use observability_artifacts::ObservabilityArtifactDetector;
+pub use openapi::OpenApiDetector;
+pub use pnpm_workspace::{PnpmWorkspace, PnpmWorkspaceDetector, PNPM_WORKSPACE};
 pub use port::{PortExtractor, PortInfo, PortSource};
+pub use project_type::{ProjectType, ProjectTypeClassification, ProjectTypeClassifier};
+pub use release_tools::{GoReleaser, Ko, ReleaseToolDetector, ReleaseTools};
+pub use required_tools::RequiredToolsDetector;
+pub use resources::ResourceEstimator;
+pub use serverless::{ServerlessDeployment, ServerlessDetector};
+pub use sqlc::{SqlcDetector, SQLC_GENERATE};
+pub use temporal::{ServiceRole, TemporalDetector, TemporalUsage};
+pub use toolchain::{GoToolchain, ToolchainDetector};
+pub use wasm::{WasmDetector, WasmTarget};
@@ -0,0 +1,206 @@
+//! Nix detector - development shells declared by flake.nix or shell.nix
+
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+const FLAKE: &str = "flake.nix";
+const SHELL_NIX: &str = "shell.nix";
+
+/// Files declaring a Nix development shell
+pub const NIX_FILES: [&str; 2] = [FLAKE, SHELL_NIX];
+
+/// Well-known nixpkgs attributes by prefix, with the name of the tool they provide; a version
+/// suffix such as `_20` or `_1_22` is carried over
+const KNOWN_PACKAGES: &[(&str, &str)] = &[
+    ("go", "Go"),
+    ("nodejs", "Node.js"),
+    ("python", "Python"),
+    ("jdk", "JDK"),
+    ("postgresql", "PostgreSQL"),
+    ("mysql", "MySQL"),
+    ("redis", "Redis"),
+    ("rustc", "Rust"),
+    ("cargo", "Cargo"),
+    ("protobuf", "protoc"),
+    ("terraform", "Terraform"),
+];
+
+/// A Nix development environment
+#[derive(Debug, Clone, PartialEq, Default)]
+pub struct NixEnvironment {
+    /// Tools the shell provides, in declaration order, e.g. `Go 1.22` or `gopls`
+    pub tools: Vec<String>,
+    /// `nix develop` for a flake, `nix-shell` for shell.nix
+    pub develop_command: String,
+}
+
+pub struct NixDetector;
+
+impl NixDetector {
+    /// Reads the `devShells` of a flake.nix, else the `buildInputs` of a shell.nix, in the
+    /// service directory or the repository root among `file_tree` (repository-relative)
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<NixEnvironment> {
+        let read = |name: &str| {
+            [service_path, Path::new("")]
+                .into_iter()
+                .map(|dir| dir.join(name))
+                .find(|path| file_tree.contains(path))
+                .and_then(|path| fs.read_to_string(&repo_path.join(path)).ok())
+        };
+
+        let (packages, develop_command) = match read(FLAKE) {
+            Some(flake) => {
+                let shells = flake.find("devShells").map(|start| &flake[start..])?;
+                (shell_packages(shells), "nix develop")
+            }
+            None => (shell_packages(&read(SHELL_NIX)?), "nix-shell"),
+        };
+
+        let mut tools: Vec<String> = Vec::new();
+        for tool in packages.iter().map(|package| tool_name(package)) {
+            if !tools.contains(&tool) {
+                tools.push(tool);
+            }
+        }
+        Some(NixEnvironment {
+            tools,
+            develop_command: develop_command.to_string(),
+        })
+    }
+}
+
+/// Attributes listed in `packages`, `buildInputs` and `nativeBuildInputs`, without `pkgs.`
+fn shell_packages(nix: &str) -> Vec<String> {
+    let without_comments: String = nix
+        .lines()
+        .map(|line| line.split('#').next().unwrap_or_default())
+        .collect::<Vec<_>>()
+        .join("\n");
+    let list = Regex::new(
+        r"\b(?:packages|buildInputs|nativeBuildInputs)\s*=\s*(?:with\s+pkgs\s*;\s*)?\[([^\]]*)\]",
+    )
+    .expect("valid nix list regex");
+    let attribute = Regex::new(r"^[A-Za-z_][\w.\-]*$").expect("valid nix attribute regex");
+
+    list.captures_iter(&without_comments)
+        .flat_map(|caps| {
+            caps[1]
+                .split_whitespace()
+                .filter(|item| attribute.is_match(item))
+                .map(|item| item.strip_prefix("pkgs.").unwrap_or(item).to_string())
+                .collect::<Vec<_>>()
+        })
+        .collect()
+}
+
+/// `go_1_22` → `Go 1.22`, `nodejs_20` → `Node.js 20`, `python312` → `Python 3.12`; unknown
+/// packages keep their name
+fn tool_name(package: &str) -> String {
+    KNOWN_PACKAGES
+        .iter()
+        .find_map(|(prefix, tool)| {
+            let version = package.strip_prefix(prefix)?;
+            let version = version.trim_start_matches(['_', '-']);
+            if version.is_empty() {
+                return Some(tool.to_string());
+            }
+            if !version.chars().all(|c| c.is_ascii_digit() || c == '_') {
+                return None;
+            }
+            // nixpkgs spells Python versions without a separator: python312
+            let version = match (*prefix, version.contains('_')) {
+                ("python", false) if version.len() > 1 => {
+                    format!("{}.{}", &version[..1], &version[1..])
+                }
+                _ => version.replace('_', "."),
+            };
+            Some(format!("{} {}", tool, version))
+        })
+        .unwrap_or_else(|| package.to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    const FLAKE_NIX: &str = r#"{
+  inputs.nixpkgs.url = "github:NixOS/nixpkgs/nixos-24.05";
+
+  outputs = { self, nixpkgs }:
+    let
+      pkgs = nixpkgs.legacyPackages.x86_64-linux;
+    in {
+      packages.x86_64-linux.default = pkgs.hello;
+
+      devShells.x86_64-linux.default = pkgs.mkShell {
+        packages = [
+          pkgs.go_1_22
+          pkgs.gopls
+          pkgs.postgresql_16 # psql for migrations
+        ];
+      };
+    };
+}
+"#;
+
+    #[test]
+    fn test_flake_dev_shell() {
+        let fs = MockFileSystem::new();
+        fs.add_file("flake.nix", FLAKE_NIX);
+        let env =
+            NixDetector::detect(Path::new(""), Path::new(""), &paths(&["flake.nix"]), &fs).unwrap();
+        assert_eq!(env.tools, vec!["Go 1.22", "gopls", "PostgreSQL 16"]);
+        assert_eq!(env.develop_command, "nix develop");
+    }
+
+    #[test]
+    fn test_shell_nix_with_pkgs() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "shell.nix",
+            "{ pkgs ? import <nixpkgs> {} }:\n\npkgs.mkShell {\n  buildInputs = with pkgs; [ nodejs_20 yarn ];\n  nativeBuildInputs = [ pkgs.python3 ];\n}\n",
+        );
+        let env = NixDetector::detect(
+            Path::new(""),
+            Path::new("web"),
+            &paths(&["shell.nix", "web/package.json"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(env.tools, vec!["Node.js 20", "yarn", "Python 3"]);
+        assert_eq!(env.develop_command, "nix-shell");
+    }
+
+    #[test]
+    fn test_flake_without_dev_shell() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "flake.nix",
+            "{\n  outputs = { self, nixpkgs }: {\n    packages.x86_64-linux.default = nixpkgs.legacyPackages.x86_64-linux.hello;\n  };\n}\n",
+        );
+        assert_eq!(
+            NixDetector::detect(Path::new(""), Path::new(""), &paths(&["flake.nix"]), &fs),
+            None
+        );
+    }
+
+    #[test]
+    fn test_tool_names() {
+        assert_eq!(tool_name("go"), "Go");
+        assert_eq!(tool_name("nodejs_20"), "Node.js 20");
+        assert_eq!(tool_name("python312"), "Python 3.12");
+        assert_eq!(tool_name("jdk17"), "JDK 17");
+        assert_eq!(tool_name("golangci-lint"), "golangci-lint");
+    }
+}
@@ -0,0 +1,258 @@
+//! Observability detector - tracing and metrics libraries of a Go service, the OpenTelemetry
+//! exporter it configures and the path Prometheus scrapes
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::ObservabilityMetadata;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+const OPENTELEMETRY: &str = "go.opentelemetry.io/otel";
+const PROMETHEUS: &str = "github.com/prometheus/client_golang";
+/// Jaeger's own client, deprecated in favour of OpenTelemetry
+const JAEGER_CLIENT: &str = "github.com/uber/jaeger-client-go";
+
+/// OpenTelemetry exporter packages by the name reported for them, matched as import path
+/// prefixes. The OTLP exporters are per signal (`otlptrace/otlptracegrpc`,
+/// `otlpmetric/otlpmetrichttp`); the name is the transport.
+const EXPORTERS: [(&str, &[&str]); 4] = [
+    (
+        "otlpgrpc",
+        &[
+            "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc",
+            "go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc",
+            "go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc",
+        ],
+    ),
+    (
+        "otlphttp",
+        &[
+            "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp",
+            "go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp",
+            "go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp",
+        ],
+    ),
+    ("zipkin", &["go.opentelemetry.io/otel/exporters/zipkin"]),
+    ("jaeger", &["go.opentelemetry.io/otel/exporters/jaeger"]),
+];
+
+pub struct ObservabilityDetector;
+
+impl ObservabilityDetector {
+    /// Detects the libraries from the service's go.mod `dependencies`, then scans its Go files
+    /// among `file_tree` (repository-relative) for exporter imports and the handler serving
+    /// Prometheus metrics
+    ///
+    /// OpenTelemetry wins over the legacy Jaeger client when a service is mid-migration.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        dependencies: &[String],
+    ) -> Option<ObservabilityMetadata> {
+        let has_module = |module: &str| {
+            dependencies.iter().any(|dependency| {
+                dependency == module || dependency.starts_with(&format!("{}/", module))
+            })
+        };
+        let tracing = if has_module(OPENTELEMETRY) {
+            Some("opentelemetry")
+        } else if has_module(JAEGER_CLIENT) {
+            Some("jaeger")
+        } else {
+            None
+        };
+        let metrics = has_module(PROMETHEUS).then_some("prometheus");
+        if tracing.is_none() && metrics.is_none() {
+            return None;
+        }
+
+        let import_re = Regex::new(r#""(go\.opentelemetry\.io/otel/exporters/[^"]+)""#)
+            .expect("valid exporter import regex");
+        // mux.Handle("/metrics", promhttp.Handler()) or r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+        let handler_re = Regex::new(r#""(/[^"]*)"\s*,[^,\n]*promhttp\.Handler(?:For)?\("#)
+            .expect("valid metrics handler regex");
+
+        let sources: Vec<String> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| {
+                path.file_name()
+                    .and_then(|name| name.to_str())
+                    .is_some_and(|name| name.ends_with(".go") && !name.ends_with("_test.go"))
+            })
+            .filter_map(|path| {
+                fs.read_to_string(&repo_path.join(service_path).join(path))
+                    .ok()
+            })
+            .collect();
+
+        let otel_exporter = tracing
+            .filter(|tracing| *tracing == "opentelemetry")
+            .and_then(|_| {
+                let imports: Vec<&str> = sources
+                    .iter()
+                    .flat_map(|source| import_re.captures_iter(source))
+                    .filter_map(|caps| caps.get(1).map(|m| m.as_str()))
+                    .collect();
+                EXPORTERS.iter().find_map(|(name, packages)| {
+                    imports
+                        .iter()
+                        .any(|import| packages.iter().any(|package| import.starts_with(package)))
+                        .then(|| name.to_string())
+                })
+            });
+
+        let metrics_path = metrics.and_then(|_| {
+            sources
+                .iter()
+                .find_map(|source| handler_re.captures(source).map(|caps| caps[1].to_string()))
+        });
+
+        Some(ObservabilityMetadata {
+            tracing: tracing.map(String::from),
+            metrics: metrics.map(String::from),
+            otel_exporter,
+            metrics_path,
+        })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const OTLP_GRPC: &str = r#"package main
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+"#;
+
+    const PROMETHEUS_HANDLER: &str = r#"package main
+
+import "github.com/prometheus/client_golang/prometheus/promhttp"
+
+func routes(mux *http.ServeMux) {
+	mux.Handle("/internal/metrics", promhttp.Handler())
+}
+"#;
+
+    fn detect(files: &[(&str, &str)], dependencies: &[&str]) -> Option<ObservabilityMetadata> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        let dependencies: Vec<String> = dependencies.iter().map(|d| d.to_string()).collect();
+        ObservabilityDetector::detect(Path::new(""), Path::new(""), &tree, &fs, &dependencies)
+    }
+
+    #[test]
+    fn test_opentelemetry_with_exporter() {
+        let observability = detect(
+            &[("tracing.go", OTLP_GRPC)],
+            &[
+                OPENTELEMETRY,
+                "go.opentelemetry.io/otel/sdk",
+                "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc",
+            ],
+        )
+        .unwrap();
+        assert_eq!(observability.tracing.as_deref(), Some("opentelemetry"));
+        assert_eq!(observability.metrics, None);
+        assert_eq!(observability.otel_exporter.as_deref(), Some("otlpgrpc"));
+        assert_eq!(observability.metrics_path, None);
+    }
+
+    #[test]
+    fn test_otel_exporters() {
+        let exporter = |import: &str| {
+            detect(
+                &[("main.go", &format!("import \"{}\"\n", import))],
+                &[OPENTELEMETRY],
+            )
+            .unwrap()
+            .otel_exporter
+        };
+        assert_eq!(
+            exporter("go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp")
+                .as_deref(),
+            Some("otlphttp")
+        );
+        assert_eq!(
+            exporter("go.opentelemetry.io/otel/exporters/zipkin").as_deref(),
+            Some("zipkin")
+        );
+        assert_eq!(
+            exporter("go.opentelemetry.io/otel/exporters/jaeger").as_deref(),
+            Some("jaeger")
+        );
+        assert_eq!(
+            exporter("go.opentelemetry.io/otel/exporters/stdout/stdouttrace"),
+            None
+        );
+    }
+
+    #[test]
+    fn test_prometheus_metrics_path() {
+        let observability = detect(&[("routes.go", PROMETHEUS_HANDLER)], &[PROMETHEUS]).unwrap();
+        assert_eq!(observability.tracing, None);
+        assert_eq!(observability.metrics.as_deref(), Some("prometheus"));
+        assert_eq!(
+            observability.metrics_path.as_deref(),
+            Some("/internal/metrics")
+        );
+
+        let gin = detect(
+            &[(
+                "main.go",
+                "r.GET(\"/metrics\", gin.WrapH(promhttp.Handler()))\n",
+            )],
+            &[PROMETHEUS],
+        )
+        .unwrap();
+        assert_eq!(gin.metrics_path.as_deref(), Some("/metrics"));
+
+        // Pushgateway users register no handler
+        let push = detect(&[("main.go", "package main\n")], &[PROMETHEUS]).unwrap();
+        assert_eq!(push.metrics_path, None);
+    }
+
+    #[test]
+    fn test_jaeger_client() {
+        let observability = detect(&[], &[JAEGER_CLIENT]).unwrap();
+        assert_eq!(observability.tracing.as_deref(), Some("jaeger"));
+        assert_eq!(observability.otel_exporter, None);
+    }
+
+    #[test]
+    fn test_library_combinations() {
+        let all = detect(
+            &[("tracing.go", OTLP_GRPC), ("routes.go", PROMETHEUS_HANDLER)],
+            &[OPENTELEMETRY, PROMETHEUS, JAEGER_CLIENT],
+        )
+        .unwrap();
+        assert_eq!(
+            all,
+            ObservabilityMetadata {
+                tracing: Some("opentelemetry".to_string()),
+                metrics: Some("prometheus".to_string()),
+                otel_exporter: Some("otlpgrpc".to_string()),
+                metrics_path: Some("/internal/metrics".to_string()),
+            }
+        );
+
+        let jaeger_and_prometheus = detect(&[], &[JAEGER_CLIENT, PROMETHEUS]).unwrap();
+        assert_eq!(jaeger_and_prometheus.tracing.as_deref(), Some("jaeger"));
+        assert_eq!(jaeger_and_prometheus.metrics.as_deref(), Some("prometheus"));
+
+        assert_eq!(
+            detect(&[("main.go", OTLP_GRPC)], &["github.com/gin-gonic/gin"]),
+            None
+        );
+    }
+}
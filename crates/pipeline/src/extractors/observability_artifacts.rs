@@ -0,0 +1,288 @@
+//! Observability artifact detector - Grafana dashboards and Prometheus alerting rules kept in
+//! the repository next to a service
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::{BackingService, DashboardDatasource, ObservabilityArtifacts};
+use serde_json::Value;
+use std::path::{Path, PathBuf};
+
+/// Data source names that are Grafana built-ins or template variables rather than a service
+const BUILTIN_DATASOURCES: [&str; 4] =
+    ["-- Grafana --", "-- Mixed --", "-- Dashboard --", "grafana"];
+
+/// Data source names that name a backing service by its short name ("postgres" plugin type,
+/// "Mongo" data source) rather than the product name
+const SERVICE_ALIASES: [(&str, &str); 2] = [("postgresql", "postgres"), ("mongodb", "mongo")];
+
+pub struct ObservabilityArtifactDetector;
+
+impl ObservabilityArtifactDetector {
+    /// Scans the service's files among `file_tree` (repository-relative) for Grafana dashboard
+    /// exports, JSON files named after a dashboard with a `schemaVersion`, and Prometheus rule
+    /// files, YAML files whose `groups` hold alerting or recording `rules`
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<ObservabilityArtifacts> {
+        let mut artifacts = ObservabilityArtifacts::default();
+
+        for path in file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+        {
+            let Some(name) = path.file_name().and_then(|name| name.to_str()) else {
+                continue;
+            };
+            let name = name.to_lowercase();
+            let is_dashboard = name.contains("dashboard") && name.ends_with(".json");
+            let is_yaml = name.ends_with(".yml") || name.ends_with(".yaml");
+            if !is_dashboard && !is_yaml {
+                continue;
+            }
+            let Ok(content) = fs.read_to_string(&repo_path.join(service_path).join(path)) else {
+                continue;
+            };
+            let relative = path.to_string_lossy().replace('\\', "/");
+
+            if is_dashboard {
+                let Some(names) = dashboard_datasources(&content) else {
+                    continue;
+                };
+                artifacts
+                    .datasources
+                    .extend(names.into_iter().map(|name| DashboardDatasource {
+                        dashboard: relative.clone(),
+                        name,
+                        backing_service: None,
+                    }));
+                artifacts.dashboards.push(relative);
+            } else if is_rule_file(&content) {
+                artifacts.alert_rules.push(relative);
+            }
+        }
+
+        (!artifacts.dashboards.is_empty() || !artifacts.alert_rules.is_empty()).then_some(artifacts)
+    }
+
+    /// Points each dashboard data source named after one of `backing_services` at it
+    pub fn link_backing_services(
+        artifacts: &mut ObservabilityArtifacts,
+        backing_services: &[BackingService],
+    ) {
+        for datasource in &mut artifacts.datasources {
+            let name = datasource.name.to_lowercase();
+            datasource.backing_service = backing_services
+                .iter()
+                .find(|service| {
+                    let short = SERVICE_ALIASES
+                        .iter()
+                        .find(|(product, _)| *product == service.name)
+                        .map_or(service.name.as_str(), |(_, alias)| alias);
+                    name.contains(short)
+                })
+                .map(|service| service.name.clone());
+        }
+    }
+}
+
+/// The data sources a Grafana dashboard queries, or `None` when the file is not a dashboard
+///
+/// Both plain exports and the `{"dashboard": {...}}` envelope of the HTTP API are accepted.
+/// Panels name a data source directly or as `{"type": "postgres", "uid": "..."}`, and
+/// exports for sharing list the data sources to pick on import under `__inputs`.
+fn dashboard_datasources(content: &str) -> Option<Vec<String>> {
+    let json: Value = serde_json::from_str(content).ok()?;
+    let dashboard = if json["dashboard"]["schemaVersion"].is_number() {
+        &json["dashboard"]
+    } else {
+        &json
+    };
+    if !dashboard["schemaVersion"].is_number() {
+        return None;
+    }
+
+    let mut names = Vec::new();
+    if let Some(inputs) = json["__inputs"].as_array() {
+        for input in inputs.iter().filter(|input| input["type"] == "datasource") {
+            add_datasource(&mut names, input["label"].as_str());
+        }
+    }
+    collect_datasources(dashboard, &mut names);
+    Some(names)
+}
+
+fn collect_datasources(value: &Value, names: &mut Vec<String>) {
+    match value {
+        Value::Object(object) => {
+            for (key, value) in object {
+                match (key.as_str(), value) {
+                    ("datasource", Value::String(name)) => add_datasource(names, Some(name)),
+                    ("datasource", Value::Object(datasource)) => {
+                        add_datasource(names, datasource.get("type").and_then(Value::as_str))
+                    }
+                    _ => collect_datasources(value, names),
+                }
+            }
+        }
+        Value::Array(values) => values
+            .iter()
+            .for_each(|value| collect_datasources(value, names)),
+        _ => {}
+    }
+}
+
+fn add_datasource(names: &mut Vec<String>, name: Option<&str>) {
+    let Some(name) = name.map(str::trim) else {
+        return;
+    };
+    // "${DS_POSTGRESQL}" is filled from __inputs on import, "datasource" is Grafana's own type
+    if name.is_empty()
+        || name.starts_with('$')
+        || name == "datasource"
+        || BUILTIN_DATASOURCES.contains(&name)
+        || names.iter().any(|known| known == name)
+    {
+        return;
+    }
+    names.push(name.to_string());
+}
+
+/// Prometheus rule files: `groups: [{name, rules: [{alert|record, expr}]}]`
+fn is_rule_file(content: &str) -> bool {
+    if !content.contains("groups:") {
+        return false;
+    }
+    let Ok(yaml) = serde_yaml::from_str::<serde_yaml::Value>(content) else {
+        return false;
+    };
+    yaml["groups"].as_sequence().is_some_and(|groups| {
+        groups.iter().any(|group| {
+            group["rules"].as_sequence().is_some_and(|rules| {
+                rules
+                    .iter()
+                    .any(|rule| rule.get("alert").is_some() || rule.get("record").is_some())
+            })
+        })
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const DASHBOARD: &str = r#"{
+  "__inputs": [
+    {"name": "DS_PROMETHEUS", "label": "Prometheus", "type": "datasource", "pluginId": "prometheus"}
+  ],
+  "title": "Orders",
+  "schemaVersion": 39,
+  "panels": [
+    {"type": "timeseries", "datasource": "${DS_PROMETHEUS}", "targets": [{"expr": "rate(http_requests_total[5m])"}]},
+    {"type": "table", "datasource": {"type": "postgres", "uid": "P44368ADAD746BC27"}},
+    {"type": "stat", "datasource": "PostgreSQL"},
+    {"type": "text", "datasource": "-- Grafana --"}
+  ]
+}"#;
+
+    const ALERTS: &str = r#"groups:
+  - name: orders
+    rules:
+      - alert: HighErrorRate
+        expr: rate(http_requests_total{status=~"5.."}[5m]) > 0.05
+        for: 10m
+        labels:
+          severity: page
+"#;
+
+    fn detect(files: &[(&str, &str)], service_path: &str) -> Option<ObservabilityArtifacts> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        ObservabilityArtifactDetector::detect(Path::new(""), Path::new(service_path), &tree, &fs)
+    }
+
+    fn datasource(name: &str, backing_service: Option<&str>) -> DashboardDatasource {
+        DashboardDatasource {
+            dashboard: "monitoring/app-dashboard.json".to_string(),
+            name: name.to_string(),
+            backing_service: backing_service.map(String::from),
+        }
+    }
+
+    #[test]
+    fn test_dashboards_and_alert_rules() {
+        let mut artifacts = detect(
+            &[
+                ("monitoring/app-dashboard.json", DASHBOARD),
+                ("alerts/high-error-rate.yml", ALERTS),
+                (
+                    "config.yml",
+                    "groups:\n  - name: admins\n    members: [ada]\n",
+                ),
+                ("package.json", r#"{"schemaVersion": 2}"#),
+            ],
+            "",
+        )
+        .unwrap();
+
+        assert_eq!(artifacts.dashboards, vec!["monitoring/app-dashboard.json"]);
+        assert_eq!(artifacts.alert_rules, vec!["alerts/high-error-rate.yml"]);
+        assert_eq!(
+            artifacts.datasources,
+            vec![
+                datasource("Prometheus", None),
+                datasource("postgres", None),
+                datasource("PostgreSQL", None),
+            ]
+        );
+
+        let postgres = BackingService {
+            name: "postgresql".to_string(),
+            detected_via: vec!["import".to_string()],
+            confidence: 0.6,
+        };
+        ObservabilityArtifactDetector::link_backing_services(&mut artifacts, &[postgres]);
+        assert_eq!(
+            artifacts.datasources,
+            vec![
+                datasource("Prometheus", None),
+                datasource("postgres", Some("postgresql")),
+                datasource("PostgreSQL", Some("postgresql")),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_api_envelope_in_service() {
+        let artifacts = detect(
+            &[(
+                "services/orders/grafana/dashboard.json",
+                r#"{"dashboard": {"title": "Orders", "schemaVersion": 16, "rows": []}, "overwrite": true}"#,
+            )],
+            "services/orders",
+        )
+        .unwrap();
+
+        assert_eq!(artifacts.dashboards, vec!["grafana/dashboard.json"]);
+        assert!(artifacts.datasources.is_empty());
+    }
+
+    #[test]
+    fn test_no_artifacts() {
+        assert_eq!(
+            detect(
+                &[
+                    ("dashboard.json", r#"{"title": "not grafana"}"#),
+                    (".github/workflows/ci.yml", "on: push\njobs: {}\n"),
+                ],
+                "",
+            ),
+            None
+        );
+    }
+}
@@ -0,0 +1,200 @@
+//! OpenAPI detector - API schema files shipped with a service
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::ApiSchema;
+use serde_yaml::Value;
+use std::path::{Path, PathBuf};
+
+const SCHEMA_FILES: [&str; 4] = [
+    "openapi.yaml",
+    "openapi.json",
+    "swagger.yaml",
+    "swagger.json",
+];
+
+/// Modules that generate the schema from Go code or Go code from the schema
+const SCHEMA_GENERATORS: [&str; 3] = [
+    "github.com/swaggo/swag",
+    "github.com/deepmap/oapi-codegen",
+    "github.com/oapi-codegen/oapi-codegen",
+];
+
+pub struct OpenApiDetector;
+
+impl OpenApiDetector {
+    /// Finds the service's OpenAPI or Swagger document among `file_tree` (repository-relative)
+    ///
+    /// A schema in the service root wins over one in `docs/`, which wins over deeper ones.
+    /// Files that are not OpenAPI documents (no `openapi` or `swagger` key) are skipped.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<ApiSchema> {
+        let mut candidates: Vec<&Path> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| {
+                path.file_name()
+                    .and_then(|name| name.to_str())
+                    .is_some_and(|name| SCHEMA_FILES.contains(&name))
+            })
+            .collect();
+        candidates.sort_by_key(|path| (location_rank(path), path.components().count(), *path));
+
+        candidates.into_iter().find_map(|relative| {
+            let content = fs
+                .read_to_string(&repo_path.join(service_path).join(relative))
+                .ok()?;
+            parse_schema(&content, relative)
+        })
+    }
+
+    /// Whether a dependency generates the schema (swag) or code from it (oapi-codegen)
+    pub fn is_generated(dependencies: &[String]) -> bool {
+        dependencies.iter().any(|dep| {
+            SCHEMA_GENERATORS
+                .iter()
+                .any(|generator| dep == generator || dep.starts_with(&format!("{}/", generator)))
+        })
+    }
+}
+
+fn location_rank(path: &Path) -> u8 {
+    match path.parent().and_then(|dir| dir.to_str()) {
+        Some("") | None => 0,
+        Some("docs") => 1,
+        _ => 2,
+    }
+}
+
+fn parse_schema(content: &str, relative: &Path) -> Option<ApiSchema> {
+    // YAML is a superset of JSON, so one parser reads both
+    let doc: Value = serde_yaml::from_str(content).ok()?;
+    if doc.get("openapi").is_none() && doc.get("swagger").is_none() {
+        return None;
+    }
+
+    let info = doc.get("info");
+    let field = |key: &str| {
+        info.and_then(|info| info.get(key))
+            .and_then(|value| match value {
+                Value::String(s) => Some(s.clone()),
+                Value::Number(n) => Some(n.to_string()),
+                _ => None,
+            })
+    };
+
+    Some(ApiSchema {
+        path: relative.display().to_string(),
+        title: field("title"),
+        version: field("version"),
+        base_path: base_path(&doc),
+    })
+}
+
+/// Swagger 2 `basePath`, or the path of the first OpenAPI 3 server URL; none when routes
+/// sit at the root
+fn base_path(doc: &Value) -> Option<String> {
+    let path = match doc.get("basePath").and_then(Value::as_str) {
+        Some(base) => base,
+        None => {
+            let url = doc.get("servers")?.get(0)?.get("url")?.as_str()?;
+            match url.split_once("://") {
+                Some((_, rest)) => rest.find('/').map_or("", |i| &rest[i..]),
+                None => url,
+            }
+        }
+    };
+    let path = path.trim_end_matches('/');
+    (!path.is_empty()).then(|| path.to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const OPENAPI: &str = r#"openapi: 3.0.3
+info:
+  title: User API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1/
+paths: {}
+"#;
+
+    #[test]
+    fn test_prefers_root_over_docs_and_deeper() {
+        let fs = MockFileSystem::new();
+        fs.add_file("openapi.yaml", OPENAPI);
+        fs.add_file(
+            "docs/swagger.json",
+            r#"{"swagger": "2.0", "info": {"title": "Docs"}}"#,
+        );
+        fs.add_file("internal/api/openapi.json", r#"{"openapi": "3.1.0"}"#);
+        let tree = [
+            "internal/api/openapi.json",
+            "docs/swagger.json",
+            "openapi.yaml",
+        ]
+        .map(PathBuf::from);
+
+        let schema = OpenApiDetector::detect(Path::new(""), Path::new(""), &tree, &fs).unwrap();
+        assert_eq!(schema.path, "openapi.yaml");
+        assert_eq!(schema.title.as_deref(), Some("User API"));
+        assert_eq!(schema.version.as_deref(), Some("1.0.0"));
+        assert_eq!(schema.base_path.as_deref(), Some("/v1"));
+
+        let schema =
+            OpenApiDetector::detect(Path::new(""), Path::new(""), &tree[..2], &fs).unwrap();
+        assert_eq!(schema.path, "docs/swagger.json");
+        assert_eq!(schema.version, None);
+    }
+
+    #[test]
+    fn test_swagger_base_path_within_service() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "services/users/docs/swagger.yaml",
+            "swagger: \"2.0\"\ninfo:\n  title: Users\n  version: \"2.1\"\nbasePath: /api/v1\n",
+        );
+        let tree = [PathBuf::from("services/users/docs/swagger.yaml")];
+
+        let schema =
+            OpenApiDetector::detect(Path::new(""), Path::new("services/users"), &tree, &fs)
+                .unwrap();
+        assert_eq!(schema.path, "docs/swagger.yaml");
+        assert_eq!(schema.version.as_deref(), Some("2.1"));
+        assert_eq!(schema.base_path.as_deref(), Some("/api/v1"));
+
+        assert!(
+            OpenApiDetector::detect(Path::new(""), Path::new("services/orders"), &tree, &fs)
+                .is_none()
+        );
+    }
+
+    #[test]
+    fn test_skips_files_that_are_not_schemas() {
+        let fs = MockFileSystem::new();
+        fs.add_file("openapi.yaml", "name: not a schema\n");
+        let tree = [PathBuf::from("openapi.yaml")];
+
+        assert!(OpenApiDetector::detect(Path::new(""), Path::new(""), &tree, &fs).is_none());
+    }
+
+    #[test]
+    fn test_is_generated() {
+        let deps = |names: &[&str]| names.iter().map(|n| n.to_string()).collect::<Vec<_>>();
+        assert!(OpenApiDetector::is_generated(&deps(&[
+            "github.com/swaggo/swag"
+        ])));
+        assert!(OpenApiDetector::is_generated(&deps(&[
+            "github.com/oapi-codegen/oapi-codegen/v2"
+        ])));
+        assert!(!OpenApiDetector::is_generated(&deps(&[
+            "github.com/swaggo/swaggerfiles"
+        ])));
+    }
+}
@@ -1,10 +1,177 @@
 //! Docker Compose file parsing utilities
 
 use crate::extractors::env_vars::{EnvVarInfo, EnvVarSource};
+use anyhow::{Context, Result};
 use peelbox_core::fs::FileSystem;
 use regex::Regex;
-use std::collections::HashMap;
-use std::path::Path;
+use serde_yaml::Value;
+use std::collections::{HashMap, HashSet};
+use std::path::{Component, Path, PathBuf};
+
+/// Compose file names, in the order Docker Compose looks for them
+pub const COMPOSE_FILE_NAMES: [&str; 4] = [
+    "compose.yaml",
+    "compose.yml",
+    "docker-compose.yaml",
+    "docker-compose.yml",
+];
+
+/// A service declared in a Compose file
+#[derive(Debug, Clone, Default, PartialEq)]
+pub struct ComposeService {
+    pub name: String,
+    pub image: Option<String>,
+    /// `build:` context, relative to the Compose file
+    pub build_context: Option<PathBuf>,
+    pub depends_on: Vec<String>,
+    /// Names of variables set under `environment:`
+    pub environment: Vec<String>,
+}
+
+#[derive(Debug, Clone, Default, PartialEq)]
+pub struct ComposeFile {
+    /// Services sorted by name
+    pub services: Vec<ComposeService>,
+}
+
+impl ComposeFile {
+    pub fn service(&self, name: &str) -> Option<&ComposeService> {
+        self.services.iter().find(|s| s.name == name)
+    }
+
+    /// The service whose local build context is `path` (relative to the Compose file)
+    pub fn service_for_context(&self, path: &Path) -> Option<&ComposeService> {
+        let path = normalize(path);
+        self.services.iter().find(|s| {
+            s.build_context
+                .as_deref()
+                .is_some_and(|context| normalize(context) == path)
+        })
+    }
+
+    /// Everything `name` depends on, directly or through other services, sorted by name
+    pub fn dependencies_of(&self, name: &str) -> Vec<&ComposeService> {
+        let mut seen = HashSet::new();
+        let mut pending = vec![name];
+        while let Some(current) = pending.pop() {
+            for dep in self
+                .service(current)
+                .map(|s| &s.depends_on)
+                .into_iter()
+                .flatten()
+            {
+                if dep != name && seen.insert(dep.as_str()) {
+                    pending.push(dep);
+                }
+            }
+        }
+
+        self.services
+            .iter()
+            .filter(|s| seen.contains(s.name.as_str()))
+            .collect()
+    }
+}
+
+pub struct ComposeParser;
+
+impl ComposeParser {
+    pub fn parse(content: &str) -> Result<ComposeFile> {
+        let value: Value = serde_yaml::from_str(content).context("Invalid Compose YAML")?;
+
+        let mut services: Vec<ComposeService> = value
+            .get("services")
+            .and_then(Value::as_mapping)
+            .into_iter()
+            .flatten()
+            .filter_map(|(name, service)| {
+                Some(ComposeService {
+                    name: name.as_str()?.to_string(),
+                    image: service
+                        .get("image")
+                        .and_then(Value::as_str)
+                        .map(String::from),
+                    build_context: build_context(service.get("build")),
+                    depends_on: names(service.get("depends_on")),
+                    environment: names(service.get("environment")),
+                })
+            })
+            .collect();
+        services.sort_by(|a, b| a.name.cmp(&b.name));
+
+        Ok(ComposeFile { services })
+    }
+
+    /// Reads the first Compose file found in `dir`, returning its file name alongside
+    pub fn parse_dir<F: FileSystem + ?Sized>(
+        dir: &Path,
+        fs: &F,
+    ) -> Option<(&'static str, ComposeFile)> {
+        COMPOSE_FILE_NAMES.iter().find_map(|file| {
+            let content = fs.read_to_string(&dir.join(file)).ok()?;
+            Some((*file, Self::parse(&content).ok()?))
+        })
+    }
+}
+
+/// Backing service an image provides, if it is one peelbox recognises
+pub fn backing_service_kind(image: &str) -> Option<&'static str> {
+    let repository = image.split([':', '@']).next().unwrap_or(image);
+    let name = repository.rsplit('/').next().unwrap_or(repository);
+
+    if name.starts_with("postgres") || name.starts_with("postgis") {
+        Some("postgres")
+    } else if name.starts_with("mysql") || name.starts_with("mariadb") {
+        Some("mysql")
+    } else if name.starts_with("redis") || name.starts_with("valkey") {
+        Some("redis")
+    } else if name.starts_with("mongo") {
+        Some("mongodb")
+    } else if repository.ends_with("temporalio/auto-setup")
+        || repository.ends_with("temporalio/server")
+    {
+        // The Temporal UI and admin-tools images are not the server
+        Some("temporal")
+    } else {
+        None
+    }
+}
+
+/// `build: ./api` or `build: { context: ./api }`
+fn build_context(build: Option<&Value>) -> Option<PathBuf> {
+    let context = match build? {
+        Value::String(context) => context.as_str(),
+        build => build.get("context").and_then(Value::as_str).unwrap_or("."),
+    };
+    // Remote contexts (git URLs) have no source in the repository
+    (!context.contains("://")).then(|| PathBuf::from(context))
+}
+
+/// Names from a list (`- DATABASE_URL=...`, `- db`) or map (`DATABASE_URL: ...`, `db: {...}`)
+fn names(value: Option<&Value>) -> Vec<String> {
+    let mut names: Vec<String> = match value {
+        Some(Value::Sequence(items)) => items
+            .iter()
+            .filter_map(Value::as_str)
+            .map(|item| item.split('=').next().unwrap_or(item).trim().to_string())
+            .collect(),
+        Some(Value::Mapping(map)) => map
+            .keys()
+            .filter_map(Value::as_str)
+            .map(String::from)
+            .collect(),
+        _ => vec![],
+    };
+    names.sort();
+    names.dedup();
+    names
+}
+
+fn normalize(path: &Path) -> PathBuf {
+    path.components()
+        .filter(|c| !matches!(c, Component::CurDir))
+        .collect()
+}
 
 /// Parse environment variables from docker-compose.yml files
 pub fn parse_env_vars<F: FileSystem>(
@@ -60,6 +227,89 @@ services:
         assert!(env_vars.contains_key("PORT"));
     }
 
+    const COMPOSE: &str = r#"
+services:
+  api:
+    build: ./api
+    ports:
+      - "8080:8080"
+    environment:
+      DATABASE_URL: postgres://app:app@db:5432/app
+      REDIS_URL: redis://cache:6379
+    depends_on:
+      db:
+        condition: service_healthy
+      worker:
+        condition: service_started
+  worker:
+    build:
+      context: ./worker
+      dockerfile: Dockerfile
+    environment:
+      - QUEUE_URL=redis://cache:6379
+    depends_on:
+      - cache
+  db:
+    image: postgres:16-alpine
+  cache:
+    image: redis:7
+"#;
+
+    #[test]
+    fn test_compose_parser_services() {
+        let compose = ComposeParser::parse(COMPOSE).unwrap();
+
+        let names: Vec<&str> = compose.services.iter().map(|s| s.name.as_str()).collect();
+        assert_eq!(names, vec!["api", "cache", "db", "worker"]);
+
+        let api = compose.service("api").unwrap();
+        assert_eq!(api.build_context, Some(PathBuf::from("./api")));
+        assert_eq!(api.depends_on, vec!["db", "worker"]);
+        assert_eq!(api.environment, vec!["DATABASE_URL", "REDIS_URL"]);
+
+        let worker = compose.service("worker").unwrap();
+        assert_eq!(worker.build_context, Some(PathBuf::from("./worker")));
+        assert_eq!(worker.environment, vec!["QUEUE_URL"]);
+        assert_eq!(
+            compose.service("db").unwrap().image.as_deref(),
+            Some("postgres:16-alpine")
+        );
+    }
+
+    #[test]
+    fn test_compose_dependency_graph() {
+        let compose = ComposeParser::parse(COMPOSE).unwrap();
+
+        let deps: Vec<&str> = compose
+            .dependencies_of("api")
+            .iter()
+            .map(|s| s.name.as_str())
+            .collect();
+        assert_eq!(deps, vec!["cache", "db", "worker"]);
+
+        assert_eq!(
+            compose
+                .service_for_context(Path::new("worker"))
+                .map(|s| s.name.as_str()),
+            Some("worker")
+        );
+        assert!(compose.service_for_context(Path::new("web")).is_none());
+    }
+
+    #[test]
+    fn test_backing_service_kind() {
+        assert_eq!(backing_service_kind("postgres:16-alpine"), Some("postgres"));
+        assert_eq!(backing_service_kind("bitnami/mysql:8.0"), Some("mysql"));
+        assert_eq!(backing_service_kind("redis"), Some("redis"));
+        assert_eq!(backing_service_kind("mongo:7"), Some("mongodb"));
+        assert_eq!(backing_service_kind("nginx:alpine"), None);
+        assert_eq!(
+            backing_service_kind("temporalio/auto-setup:1.24"),
+            Some("temporal")
+        );
+        assert_eq!(backing_service_kind("temporalio/ui:2.26.2"), None);
+    }
+
     #[test]
     fn test_no_docker_compose_file() {
         let fs = MockFileSystem::new();
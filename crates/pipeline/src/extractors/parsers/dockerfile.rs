@@ -2,11 +2,224 @@
 
 use crate::extractors::health::{HealthCheckInfo, HealthCheckSource};
 use crate::extractors::port::{PortInfo, PortSource};
+use anyhow::{Context, Result};
 use peelbox_core::fs::FileSystem;
 use regex::Regex;
-use std::collections::HashSet;
+use std::collections::{HashMap, HashSet};
+use std::io::Read;
 use std::path::Path;
 
+/// What a Dockerfile's final stage declares about the image it produces
+#[derive(Debug, Clone, Default, PartialEq)]
+pub struct DockerfileInfo {
+    /// Base image of the final stage (`image:tag`), resolved through earlier stages
+    pub from: Option<String>,
+    /// Ports exposed by the final stage, in declaration order
+    pub expose: Vec<u16>,
+    /// ENTRYPOINT followed by CMD, as Docker would run them
+    pub cmd: Option<Vec<String>>,
+}
+
+/// Image config accumulated while walking one build stage
+#[derive(Debug, Clone, Default)]
+struct Stage {
+    from: Option<String>,
+    expose: Vec<u16>,
+    entrypoint: Option<Vec<String>>,
+    cmd: Option<Vec<String>>,
+    cmd_inherited: bool,
+    vars: HashMap<String, String>,
+}
+
+impl Stage {
+    fn into_info(self) -> DockerfileInfo {
+        let cmd = match (self.entrypoint, self.cmd) {
+            (Some(mut entrypoint), Some(cmd)) => {
+                entrypoint.extend(cmd);
+                Some(entrypoint)
+            }
+            (entrypoint, cmd) => entrypoint.or(cmd),
+        };
+        DockerfileInfo {
+            from: self.from,
+            expose: self.expose,
+            cmd,
+        }
+    }
+}
+
+pub struct DockerfileParser;
+
+impl DockerfileParser {
+    /// Parses a Dockerfile, following multi-stage builds to the final stage
+    pub fn parse<R: Read>(mut reader: R) -> Result<DockerfileInfo> {
+        let mut content = String::new();
+        reader
+            .read_to_string(&mut content)
+            .context("Failed to read Dockerfile")?;
+
+        // ARGs declared before the first FROM parameterise base images
+        let mut global_args: HashMap<String, String> = HashMap::new();
+        let mut named_stages: HashMap<String, Stage> = HashMap::new();
+        let mut current: Option<(Option<String>, Stage)> = None;
+
+        for instruction in logical_lines(&content) {
+            let (keyword, rest) = instruction
+                .split_once(char::is_whitespace)
+                .unwrap_or((instruction.as_str(), ""));
+            let rest = rest.trim();
+            let keyword = keyword.to_ascii_uppercase();
+
+            if keyword == "FROM" {
+                if let Some((Some(alias), stage)) = current.take() {
+                    named_stages.insert(alias, stage);
+                }
+
+                let mut words = rest.split_whitespace().filter(|w| !w.starts_with("--"));
+                let image = substitute_vars(words.next().unwrap_or_default(), &global_args);
+                let alias = match (words.next(), words.next()) {
+                    (Some(kw), Some(name)) if kw.eq_ignore_ascii_case("as") => {
+                        Some(name.to_ascii_lowercase())
+                    }
+                    _ => None,
+                };
+
+                // FROM <earlier stage> inherits that stage's image config
+                let stage = match named_stages.get(&image.to_ascii_lowercase()) {
+                    Some(parent) => Stage {
+                        cmd_inherited: true,
+                        ..parent.clone()
+                    },
+                    None => Stage {
+                        from: Some(image),
+                        vars: global_args.clone(),
+                        ..Default::default()
+                    },
+                };
+                current = Some((alias, stage));
+                continue;
+            }
+
+            let Some((_, stage)) = current.as_mut() else {
+                if keyword == "ARG" {
+                    if let Some((name, value)) = parse_assignment(rest) {
+                        global_args.insert(name, value);
+                    }
+                }
+                continue;
+            };
+
+            match keyword.as_str() {
+                "ARG" | "ENV" => {
+                    if let Some((name, value)) = parse_assignment(rest) {
+                        // A bare `ARG NAME` re-declares the global default
+                        if keyword == "ARG" && value.is_empty() {
+                            continue;
+                        }
+                        let value = substitute_vars(&value, &stage.vars);
+                        stage.vars.insert(name, value);
+                    }
+                }
+                "EXPOSE" => {
+                    for word in rest.split_whitespace() {
+                        let word = substitute_vars(word, &stage.vars);
+                        let port = word.split('/').next().unwrap_or_default();
+                        if let Ok(port) = port.parse::<u16>() {
+                            if !stage.expose.contains(&port) {
+                                stage.expose.push(port);
+                            }
+                        }
+                    }
+                }
+                "CMD" => {
+                    stage.cmd = Some(parse_command(rest));
+                    stage.cmd_inherited = false;
+                }
+                "ENTRYPOINT" => {
+                    stage.entrypoint = Some(parse_command(rest));
+                    // Docker drops a CMD inherited from the parent when ENTRYPOINT changes
+                    if stage.cmd_inherited {
+                        stage.cmd = None;
+                    }
+                }
+                _ => {}
+            }
+        }
+
+        Ok(current
+            .map(|(_, stage)| stage.into_info())
+            .unwrap_or_default())
+    }
+}
+
+/// Joins `\` continuations and drops comments and blank lines
+fn logical_lines(content: &str) -> Vec<String> {
+    let mut lines = Vec::new();
+    let mut pending = String::new();
+
+    for line in content.lines() {
+        let trimmed = line.trim();
+        if trimmed.starts_with('#') || (trimmed.is_empty() && pending.is_empty()) {
+            continue;
+        }
+        match trimmed.strip_suffix('\\') {
+            Some(head) => {
+                pending.push_str(head.trim_end());
+                pending.push(' ');
+            }
+            None => {
+                pending.push_str(trimmed);
+                lines.push(std::mem::take(&mut pending).trim().to_string());
+            }
+        }
+    }
+    if !pending.trim().is_empty() {
+        lines.push(pending.trim().to_string());
+    }
+
+    lines
+}
+
+/// Parses `NAME=value` or legacy `NAME value`
+fn parse_assignment(rest: &str) -> Option<(String, String)> {
+    let (name, value) = rest
+        .split_once('=')
+        .or_else(|| rest.split_once(char::is_whitespace))
+        .unwrap_or((rest, ""));
+    let name = name.trim();
+    if name.is_empty() || name.contains(char::is_whitespace) {
+        return None;
+    }
+    Some((name.to_string(), value.trim().trim_matches('"').to_string()))
+}
+
+/// Exec form (`["node", "server.js"]`) or shell form (`node server.js`)
+fn parse_command(rest: &str) -> Vec<String> {
+    if rest.starts_with('[') {
+        if let Ok(args) = serde_json::from_str::<Vec<String>>(rest) {
+            return args;
+        }
+    }
+    rest.split_whitespace().map(String::from).collect()
+}
+
+/// Expands `$NAME`, `${NAME}` and `${NAME:-default}`
+fn substitute_vars(value: &str, vars: &HashMap<String, String>) -> String {
+    let re = Regex::new(r"\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}|\$([A-Za-z_][A-Za-z0-9_]*)")
+        .expect("valid regex");
+    re.replace_all(value, |caps: &regex::Captures| {
+        let name = caps
+            .get(1)
+            .or_else(|| caps.get(3))
+            .map_or("", |m| m.as_str());
+        vars.get(name)
+            .cloned()
+            .or_else(|| caps.get(2).map(|d| d.as_str().to_string()))
+            .unwrap_or_default()
+    })
+    .into_owned()
+}
+
 /// Parse EXPOSE directives from Dockerfile
 pub fn parse_expose<F: FileSystem>(
     service_path: &Path,
@@ -94,6 +307,96 @@ CMD ["node", "server.js"]
         assert!(ports.iter().all(|p| p.source == PortSource::Dockerfile));
     }
 
+    #[test]
+    fn test_parse_single_stage() {
+        let info = DockerfileParser::parse(
+            r#"# syntax=docker/dockerfile:1
+FROM node:20-alpine
+WORKDIR /app
+EXPOSE 3000/tcp 9229
+CMD ["node", "server.js"]
+"#
+            .as_bytes(),
+        )
+        .unwrap();
+
+        assert_eq!(info.from.as_deref(), Some("node:20-alpine"));
+        assert_eq!(info.expose, vec![3000, 9229]);
+        assert_eq!(
+            info.cmd,
+            Some(vec!["node".to_string(), "server.js".to_string()])
+        );
+    }
+
+    #[test]
+    fn test_parse_multi_stage_uses_final_stage() {
+        let info = DockerfileParser::parse(
+            r#"ARG GO_VERSION=1.22
+FROM golang:${GO_VERSION} AS build
+WORKDIR /src
+COPY . .
+RUN go build -o /out/api ./cmd/api
+EXPOSE 6060
+CMD ["go", "run", "."]
+
+FROM gcr.io/distroless/static:nonroot
+COPY --from=build /out/api /api
+ENV PORT=8080
+EXPOSE $PORT
+ENTRYPOINT ["/api"]
+CMD ["--log-level", \
+     "info"]
+"#
+            .as_bytes(),
+        )
+        .unwrap();
+
+        assert_eq!(
+            info.from.as_deref(),
+            Some("gcr.io/distroless/static:nonroot")
+        );
+        assert_eq!(info.expose, vec![8080]);
+        assert_eq!(
+            info.cmd,
+            Some(vec![
+                "/api".to_string(),
+                "--log-level".to_string(),
+                "info".to_string()
+            ])
+        );
+    }
+
+    #[test]
+    fn test_parse_stage_inherits_from_earlier_stage() {
+        let info = DockerfileParser::parse(
+            r#"FROM python:3.12-slim AS base
+EXPOSE 8000
+CMD python -m http.server
+
+FROM base AS runtime
+ENTRYPOINT ["gunicorn", "app:app"]
+"#
+            .as_bytes(),
+        )
+        .unwrap();
+
+        assert_eq!(info.from.as_deref(), Some("python:3.12-slim"));
+        assert_eq!(info.expose, vec![8000]);
+        // ENTRYPOINT in the child stage drops the inherited CMD
+        assert_eq!(
+            info.cmd,
+            Some(vec!["gunicorn".to_string(), "app:app".to_string()])
+        );
+    }
+
+    #[test]
+    fn test_parse_empty_dockerfile() {
+        assert_eq!(
+            DockerfileParser::parse("".as_bytes()).unwrap(),
+            DockerfileInfo::default()
+        );
+    }
+
     #[test]
     fn test_no_dockerfile() {
         let fs = MockFileSystem::new();
@@ -0,0 +1,182 @@
+//! Makefile parsing utilities
+
+use regex::Regex;
+use std::collections::{BTreeMap, HashMap};
+
+/// Targets whose recipes are surfaced as candidate commands
+pub const CANDIDATE_TARGETS: [&str; 7] =
+    ["build", "run", "start", "test", "lint", "install", "deploy"];
+
+pub struct MakefileParser;
+
+impl MakefileParser {
+    /// Recipes of the candidate targets, with simple `$(VAR)` references expanded
+    pub fn parse(content: &str) -> BTreeMap<String, Vec<String>> {
+        let lines = join_continuations(content);
+        let variables = parse_variables(&lines);
+        let target_re =
+            Regex::new(r"^([A-Za-z0-9_.\-/ ]+?)\s*::?(?:[^=]|$)").expect("valid target regex");
+
+        let mut targets = BTreeMap::new();
+        let mut current: Vec<String> = Vec::new();
+
+        for line in &lines {
+            if let Some(recipe) = line.strip_prefix('\t') {
+                let command = recipe.trim().trim_start_matches(['@', '-', '+']).trim();
+                if current.is_empty() || command.is_empty() || command.starts_with('#') {
+                    continue;
+                }
+                let command = expand(command, &variables);
+                for target in &current {
+                    targets
+                        .entry(target.clone())
+                        .or_insert_with(Vec::new)
+                        .push(command.clone());
+                }
+                continue;
+            }
+
+            let trimmed = line.trim();
+            if trimmed.is_empty() || trimmed.starts_with('#') {
+                continue;
+            }
+
+            // Any other non-recipe line ends the previous rule
+            current = target_re
+                .captures(line)
+                .map(|caps| {
+                    caps[1]
+                        .split_whitespace()
+                        .filter(|name| CANDIDATE_TARGETS.contains(name))
+                        .map(String::from)
+                        .collect()
+                })
+                .unwrap_or_default();
+        }
+
+        targets
+    }
+}
+
+/// Folds backslash-continued lines into one
+fn join_continuations(content: &str) -> Vec<String> {
+    let mut lines = Vec::new();
+    let mut pending = String::new();
+    for line in content.lines() {
+        match line.strip_suffix('\\') {
+            Some(head) => {
+                pending.push_str(head.trim_end());
+                pending.push(' ');
+            }
+            None if pending.is_empty() => lines.push(line.to_string()),
+            None => {
+                pending.push_str(line.trim_start());
+                lines.push(std::mem::take(&mut pending));
+            }
+        }
+    }
+    if !pending.is_empty() {
+        lines.push(pending);
+    }
+    lines
+}
+
+/// `NAME = value`, `NAME := value`, `NAME ::= value` and `NAME ?= value` assignments
+fn parse_variables(lines: &[String]) -> HashMap<String, String> {
+    let assign_re =
+        Regex::new(r"^(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*(?:::=|:=|\?=|=)\s*(.*)$")
+            .expect("valid assignment regex");
+    let mut variables = HashMap::new();
+    for line in lines.iter().filter(|l| !l.starts_with('\t')) {
+        if let Some(caps) = assign_re.captures(line) {
+            let name = caps[1].to_string();
+            let value = caps[2].trim().to_string();
+            // `?=` keeps an earlier value
+            if line.contains("?=") && variables.contains_key(&name) {
+                continue;
+            }
+            variables.insert(name, value);
+        }
+    }
+    variables
+}
+
+/// Expands `$(VAR)` and `${VAR}`; references to unknown variables and functions stay as written
+fn expand(command: &str, variables: &HashMap<String, String>) -> String {
+    let var_re = Regex::new(r"\$[({]([A-Za-z_][A-Za-z0-9_]*)[)}]").expect("valid variable regex");
+    let mut expanded = command.to_string();
+    // Bounded so self-referencing variables cannot loop forever
+    for _ in 0..5 {
+        let next = var_re
+            .replace_all(&expanded, |caps: &regex::Captures| {
+                variables
+                    .get(&caps[1])
+                    .cloned()
+                    .unwrap_or_else(|| caps[0].to_string())
+            })
+            .to_string();
+        if next == expanded {
+            break;
+        }
+        expanded = next;
+    }
+    expanded
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const MAKEFILE: &str = "BINARY := server
+BUILD_DIR ?= bin
+LDFLAGS = -s -w
+
+.PHONY: build run test lint clean
+
+build:
+\t@echo \"building $(BINARY)\"
+\tCGO_ENABLED=0 go build -ldflags \"$(LDFLAGS)\" \\
+\t\t-o $(BUILD_DIR)/${BINARY} .
+
+run: build
+\t./$(BUILD_DIR)/$(BINARY)
+
+test lint:
+\t-go vet ./...
+
+clean:
+\trm -rf $(BUILD_DIR)
+";
+
+    #[test]
+    fn test_parse_candidate_targets() {
+        let targets = MakefileParser::parse(MAKEFILE);
+
+        assert_eq!(
+            targets.keys().collect::<Vec<_>>(),
+            vec!["build", "lint", "run", "test"]
+        );
+        assert_eq!(
+            targets["build"],
+            vec![
+                "echo \"building server\"",
+                "CGO_ENABLED=0 go build -ldflags \"-s -w\" -o bin/server ."
+            ]
+        );
+        assert_eq!(targets["run"], vec!["./bin/server"]);
+        assert_eq!(targets["test"], vec!["go vet ./..."]);
+        assert_eq!(targets["lint"], targets["test"]);
+    }
+
+    #[test]
+    fn test_assignments_are_not_targets() {
+        let targets = MakefileParser::parse("build := fast\ninstall ::= yes\n\tnot-a-recipe\n");
+        assert!(targets.is_empty());
+    }
+
+    #[test]
+    fn test_unknown_variables_are_kept() {
+        let targets = MakefileParser::parse("build:\n\tgo build -o $(OUT) $(shell pwd)\n");
+        assert_eq!(targets["build"], vec!["go build -o $(OUT) $(shell pwd)"]);
+    }
+}
@@ -1,7 +1,8 @@
 // Shared cross-language file format parsers
 //
 // These parsers extract information from common file formats that are
-// language-agnostic (Dockerfile, .env files, YAML/JSON configs, Docker Compose, Kubernetes).
+// language-agnostic (Dockerfile, .env files, YAML/JSON configs, Docker Compose, Kubernetes,
+// runtime version pin files, Makefiles).
 // They are used by multiple extractors to avoid code duplication.
 
 pub mod config;
@@ -9,3 +10,5 @@ pub mod docker_compose;
 pub mod dockerfile;
 pub mod env_file;
 pub mod kubernetes;
+pub mod makefile;
+pub mod version_files;
@@ -0,0 +1,200 @@
+//! Runtime version pin files: asdf `.tool-versions` and `.<runtime>-version`
+
+use peelbox_core::fs::FileSystem;
+use std::cmp::Ordering;
+use std::collections::BTreeMap;
+use std::path::Path;
+
+/// Single-runtime pin files and the runtime each one pins, checked after `.tool-versions`
+const VERSION_FILES: [(&str, &str); 8] = [
+    (".node-version", "node"),
+    (".nvmrc", "node"),
+    (".python-version", "python"),
+    (".ruby-version", "ruby"),
+    (".go-version", "go"),
+    (".java-version", "java"),
+    (".php-version", "php"),
+    (".elixir-version", "elixir"),
+];
+
+/// Whether `name` is a file runtime pins are read from
+pub fn is_version_file(name: &str) -> bool {
+    name == ".tool-versions"
+        || VERSION_FILES
+            .iter()
+            .any(|(file_name, _)| *file_name == name)
+}
+
+/// A runtime version pinned by a tooling file
+#[derive(Debug, Clone, PartialEq)]
+pub struct VersionPin {
+    pub version: String,
+    /// File the pin was read from, e.g. ".tool-versions"
+    pub source: String,
+}
+
+/// Reads the runtime pins in `dir`, keyed by runtime name ("node", "go", ...)
+///
+/// When several files pin the same runtime the most specific version wins, so
+/// `nodejs 20.11.0` in `.tool-versions` beats `20` in `.node-version`.
+pub fn read_version_pins<F: FileSystem + ?Sized>(
+    dir: &Path,
+    fs: &F,
+) -> BTreeMap<String, VersionPin> {
+    let mut pins: BTreeMap<String, VersionPin> = BTreeMap::new();
+    let mut offer = |runtime: &str, version: &str, source: &str| {
+        let Some(version) = normalize_version(version) else {
+            return;
+        };
+        let more_specific = pins
+            .get(runtime)
+            .is_none_or(|pin| components(&version).len() > components(&pin.version).len());
+        if more_specific {
+            pins.insert(
+                runtime.to_string(),
+                VersionPin {
+                    version,
+                    source: source.to_string(),
+                },
+            );
+        }
+    };
+
+    if let Ok(content) = fs.read_to_string(&dir.join(".tool-versions")) {
+        for (tool, version) in parse_tool_versions(&content) {
+            offer(runtime_for_tool(tool), version, ".tool-versions");
+        }
+    }
+
+    for (file_name, runtime) in VERSION_FILES {
+        if let Ok(content) = fs.read_to_string(&dir.join(file_name)) {
+            if let Some(version) = content.lines().map(str::trim).find(|l| !l.is_empty()) {
+                offer(runtime, version, file_name);
+            }
+        }
+    }
+
+    pins
+}
+
+/// `(tool, version)` pairs from `.tool-versions`; only the first version of a line is the active one
+fn parse_tool_versions(content: &str) -> Vec<(&str, &str)> {
+    content
+        .lines()
+        .map(|line| line.split('#').next().unwrap_or_default())
+        .filter_map(|line| {
+            let mut fields = line.split_whitespace();
+            Some((fields.next()?, fields.next()?))
+        })
+        .collect()
+}
+
+/// asdf plugin names that differ from the runtime name used in results
+fn runtime_for_tool(tool: &str) -> &str {
+    match tool {
+        "nodejs" => "node",
+        "golang" => "go",
+        other => other,
+    }
+}
+
+/// Strips a leading `v`; aliases such as `lts/iron` or `system` carry no version
+fn normalize_version(version: &str) -> Option<String> {
+    let version = version.trim().trim_start_matches('v');
+    version
+        .starts_with(|c: char| c.is_ascii_digit())
+        .then(|| version.to_string())
+}
+
+/// Leading numeric components, e.g. `[1, 22, 0]` for "1.22.0" or `[3, 12]` for "3.12-dev"
+fn components(version: &str) -> Vec<u64> {
+    version
+        .split('.')
+        .map_while(|part| {
+            let digits: String = part.chars().take_while(char::is_ascii_digit).collect();
+            digits.parse().ok()
+        })
+        .collect()
+}
+
+/// Whether a pinned version satisfies the version a manifest declares
+///
+/// `>=`/`>` constraints are minimums; anything else (`go 1.21`, `^20`, `~> 3.2`) must match
+/// the pin on every component both versions specify.
+pub fn versions_agree(pinned: &str, declared: &str) -> bool {
+    let declared = declared.trim();
+    let minimum = declared.starts_with('>');
+    let pinned = components(pinned);
+    let declared = components(declared.trim_start_matches(|c: char| !c.is_ascii_digit()));
+    if pinned.is_empty() || declared.is_empty() {
+        return true;
+    }
+
+    let common = pinned.len().min(declared.len());
+    match pinned[..common].cmp(&declared[..common]) {
+        Ordering::Equal => true,
+        Ordering::Greater => minimum,
+        Ordering::Less => false,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+    use std::path::PathBuf;
+
+    #[test]
+    fn test_tool_versions() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            ".tool-versions",
+            "# runtimes\ngolang 1.22.0\nnodejs 20.11.0 18.19.0\npython system\n",
+        );
+
+        let pins = read_version_pins(&PathBuf::from("."), &fs);
+        assert_eq!(pins.len(), 2);
+        assert_eq!(pins["go"].version, "1.22.0");
+        assert_eq!(pins["node"].version, "20.11.0");
+        assert_eq!(pins["node"].source, ".tool-versions");
+    }
+
+    #[test]
+    fn test_more_specific_pin_wins() {
+        let fs = MockFileSystem::new();
+        fs.add_file(".tool-versions", "nodejs 20.11.0\nruby 3.3\n");
+        fs.add_file(".node-version", "20\n");
+        fs.add_file(".ruby-version", "3.3.1\n");
+        fs.add_file(".python-version", "3.12.2\n");
+
+        let pins = read_version_pins(&PathBuf::from("."), &fs);
+        assert_eq!(pins["node"].version, "20.11.0");
+        assert_eq!(pins["node"].source, ".tool-versions");
+        assert_eq!(pins["ruby"].version, "3.3.1");
+        assert_eq!(pins["ruby"].source, ".ruby-version");
+        assert_eq!(pins["python"].version, "3.12.2");
+    }
+
+    #[test]
+    fn test_missing_files() {
+        let fs = MockFileSystem::new();
+        fs.add_file(".nvmrc", "lts/iron\n");
+
+        assert!(read_version_pins(&PathBuf::from("."), &fs).is_empty());
+    }
+
+    #[test]
+    fn test_versions_agree() {
+        assert!(versions_agree("1.21.5", "1.21"));
+        assert!(versions_agree("20.11.0", "^20"));
+        assert!(versions_agree("20.11.0", ">=18"));
+        assert!(versions_agree("3.3.1", "~> 3.3"));
+    }
+
+    #[test]
+    fn test_versions_disagree() {
+        assert!(!versions_agree("1.22.0", "1.21"));
+        assert!(!versions_agree("18.19.0", "^20"));
+        assert!(!versions_agree("16.20.0", ">=18"));
+    }
+}
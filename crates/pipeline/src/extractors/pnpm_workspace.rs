@@ -0,0 +1,206 @@
+//! pnpm workspace detector - packages listed by pnpm-workspace.yaml at the repository root
+
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use serde_json::Value;
+use std::collections::BTreeSet;
+use std::path::{Component, Path, PathBuf};
+
+pub const PNPM_WORKSPACE: &str = "pnpm-workspace.yaml";
+
+const DEPENDENCY_KEYS: [&str; 3] = ["dependencies", "devDependencies", "peerDependencies"];
+
+/// Packages of a pnpm workspace
+#[derive(Debug, Clone, PartialEq, Default)]
+pub struct PnpmWorkspace {
+    /// Package directories relative to the repository root, sorted
+    pub members: Vec<PathBuf>,
+    /// Root `devDependencies` that workspace packages depend on too, sorted
+    pub shared_tooling: Vec<String>,
+}
+
+pub struct PnpmWorkspaceDetector;
+
+impl PnpmWorkspaceDetector {
+    /// Expands the `packages` globs of the root pnpm-workspace.yaml against the package.json
+    /// files in `file_tree` (repository-relative); `!` globs exclude packages
+    ///
+    /// package.json files under `node_modules` are never members: pnpm symlinks workspace
+    /// packages into the `node_modules` of the packages depending on them.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<PnpmWorkspace> {
+        if !file_tree
+            .iter()
+            .any(|path| path == Path::new(PNPM_WORKSPACE))
+        {
+            return None;
+        }
+        let content = fs.read_to_string(&repo_path.join(PNPM_WORKSPACE)).ok()?;
+        let config: serde_yaml::Value = serde_yaml::from_str(&content).ok()?;
+        let patterns: Vec<&str> = config
+            .get("packages")?
+            .as_sequence()?
+            .iter()
+            .filter_map(|pattern| pattern.as_str())
+            .collect();
+        let (excludes, includes): (Vec<&str>, Vec<&str>) = patterns
+            .iter()
+            .partition(|pattern| pattern.starts_with('!'));
+        let includes: Vec<Regex> = includes.iter().filter_map(|p| glob_regex(p)).collect();
+        let excludes: Vec<Regex> = excludes
+            .iter()
+            .filter_map(|p| glob_regex(&p[1..]))
+            .collect();
+
+        let mut members: Vec<PathBuf> = file_tree
+            .iter()
+            .filter(|path| path.file_name().is_some_and(|name| name == "package.json"))
+            .filter(|path| {
+                !path
+                    .components()
+                    .any(|c| c == Component::Normal("node_modules".as_ref()))
+            })
+            .filter_map(|path| path.parent())
+            .filter(|dir| !dir.as_os_str().is_empty())
+            .filter(|dir| {
+                let dir = dir.to_string_lossy();
+                includes.iter().any(|glob| glob.is_match(&dir))
+                    && !excludes.iter().any(|glob| glob.is_match(&dir))
+            })
+            .map(Path::to_path_buf)
+            .collect();
+        members.sort();
+
+        let read_manifest = |dir: &Path| -> Option<Value> {
+            serde_json::from_str(
+                &fs.read_to_string(&repo_path.join(dir).join("package.json"))
+                    .ok()?,
+            )
+            .ok()
+        };
+        let root_tooling: Vec<String> = read_manifest(Path::new(""))
+            .and_then(|root| {
+                root.get("devDependencies")?
+                    .as_object()
+                    .map(|deps| deps.keys().cloned().collect())
+            })
+            .unwrap_or_default();
+        let member_dependencies: BTreeSet<String> = members
+            .iter()
+            .filter_map(|dir| read_manifest(dir))
+            .flat_map(|manifest| {
+                DEPENDENCY_KEYS
+                    .iter()
+                    .filter_map(|key| manifest.get(key)?.as_object().cloned())
+                    .flat_map(|deps| deps.keys().cloned().collect::<Vec<_>>())
+                    .collect::<Vec<_>>()
+            })
+            .collect();
+        let mut shared_tooling: Vec<String> = root_tooling
+            .into_iter()
+            .filter(|dep| member_dependencies.contains(dep))
+            .collect();
+        shared_tooling.sort();
+
+        Some(PnpmWorkspace {
+            members,
+            shared_tooling,
+        })
+    }
+}
+
+/// pnpm globs match directories: `*` stays within one path segment, `**` spans any number
+fn glob_regex(pattern: &str) -> Option<Regex> {
+    let pattern = pattern.trim_start_matches("./").trim_end_matches('/');
+    let escaped = regex::escape(pattern)
+        .replace(r"\*\*", ".*")
+        .replace(r"\*", "[^/]*");
+    Regex::new(&format!("^{}$", escaped)).ok()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    #[test]
+    fn test_expands_package_globs() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "pnpm-workspace.yaml",
+            "packages:\n  - 'apps/*'\n  - 'packages/**'\n  - docs\n  - '!**/test/**'\n",
+        );
+        let tree = paths(&[
+            "package.json",
+            "pnpm-workspace.yaml",
+            "apps/api/package.json",
+            "apps/web/package.json",
+            "apps/web/node_modules/@shop/ui/package.json",
+            "packages/ui/package.json",
+            "packages/config/eslint/package.json",
+            "packages/ui/test/fixture/package.json",
+            "docs/package.json",
+            "scripts/package.json",
+        ]);
+
+        let workspace = PnpmWorkspaceDetector::detect(Path::new(""), &tree, &fs).unwrap();
+        assert_eq!(
+            workspace.members,
+            paths(&[
+                "apps/api",
+                "apps/web",
+                "docs",
+                "packages/config/eslint",
+                "packages/ui",
+            ])
+        );
+    }
+
+    #[test]
+    fn test_shared_tooling() {
+        let fs = MockFileSystem::new();
+        fs.add_file("pnpm-workspace.yaml", "packages:\n  - 'apps/*'\n");
+        fs.add_file(
+            "package.json",
+            r#"{"private": true, "devDependencies": {"typescript": "^5.4.0", "prettier": "^3.2.0", "eslint": "^8.57.0"}}"#,
+        );
+        fs.add_file(
+            "apps/api/package.json",
+            r#"{"name": "api", "dependencies": {"express": "^4.19.2"}, "devDependencies": {"typescript": "^5.4.0"}}"#,
+        );
+        fs.add_file(
+            "apps/web/package.json",
+            r#"{"name": "web", "devDependencies": {"eslint": "^8.57.0", "vite": "^5.2.0"}}"#,
+        );
+        let tree = paths(&[
+            "package.json",
+            "pnpm-workspace.yaml",
+            "apps/api/package.json",
+            "apps/web/package.json",
+        ]);
+
+        let workspace = PnpmWorkspaceDetector::detect(Path::new(""), &tree, &fs).unwrap();
+        assert_eq!(workspace.shared_tooling, vec!["eslint", "typescript"]);
+    }
+
+    #[test]
+    fn test_no_workspace_config() {
+        let fs = MockFileSystem::new();
+        fs.add_file("packages/ui/pnpm-workspace.yaml", "packages:\n  - '*'\n");
+        let tree = paths(&[
+            "packages/ui/pnpm-workspace.yaml",
+            "packages/ui/package.json",
+        ]);
+        assert_eq!(
+            PnpmWorkspaceDetector::detect(Path::new(""), &tree, &fs),
+            None
+        );
+    }
+}
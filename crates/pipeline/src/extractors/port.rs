@@ -66,6 +66,47 @@ impl<F: FileSystem> PortExtractor<F> {
         ports
     }
 
+    /// Whether service code reads its listen port from the `PORT` environment variable
+    pub fn reads_port_from_env(&self, context: &ServiceContext) -> bool {
+        let lang = match context
+            .language
+            .as_ref()
+            .and_then(|id| self.registry.get_language(id.clone()))
+        {
+            Some(l) => l,
+            None => return false,
+        };
+
+        let patterns: Vec<Regex> = lang
+            .env_var_patterns()
+            .iter()
+            .filter_map(|(pattern, _)| Regex::new(pattern).ok())
+            .collect();
+        if patterns.is_empty() {
+            return false;
+        }
+
+        let mut found = false;
+        crate::extractors::common::scan_directory_with_language_filter(
+            &self.fs,
+            &context.path,
+            lang.as_ref(),
+            |file_path| {
+                if found {
+                    return;
+                }
+                if let Ok(content) = self.fs.read_to_string(file_path) {
+                    found = patterns.iter().any(|re| {
+                        re.captures_iter(&content)
+                            .any(|cap| cap.get(1).map(|m| m.as_str()) == Some("PORT"))
+                    });
+                }
+            },
+        );
+
+        found
+    }
+
     fn extract_from_code_patterns(
         &self,
         context: &ServiceContext,
@@ -263,6 +304,65 @@ app.listen(3000, () => {
         assert!(matches!(ports[0].source, PortSource::CodePattern(_)));
     }
 
+    #[test]
+    fn test_extract_go_listen_port() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "main.go",
+            r#"
+package main
+
+import "net/http"
+
+func main() {
+    http.ListenAndServe(":3000", nil)
+}
+"#,
+        );
+
+        let extractor = PortExtractor::new(fs);
+        let context = ServiceContext::with_detection(
+            PathBuf::from("."),
+            Some(peelbox_stack::LanguageId::Go),
+            None,
+        );
+
+        let ports = extractor.extract(&context);
+        assert_eq!(ports.len(), 1);
+        assert_eq!(ports[0].port, 3000);
+        assert!(!extractor.reads_port_from_env(&context));
+    }
+
+    #[test]
+    fn test_go_port_from_env() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "main.go",
+            r#"
+package main
+
+import (
+    "net/http"
+    "os"
+)
+
+func main() {
+    http.ListenAndServe(":"+os.Getenv("PORT"), nil)
+}
+"#,
+        );
+
+        let extractor = PortExtractor::new(fs);
+        let context = ServiceContext::with_detection(
+            PathBuf::from("."),
+            Some(peelbox_stack::LanguageId::Go),
+            None,
+        );
+
+        assert!(extractor.extract(&context).is_empty());
+        assert!(extractor.reads_port_from_env(&context));
+    }
+
     #[test]
     fn test_deduplication() {
         let fs = MockFileSystem::new();
@@ -0,0 +1,360 @@
+//! Project type classifier - whether a Go module is a web service, command-line tool, library,
+//! batch job or proxy, from its main packages, imports and go.mod dependencies
+
+use super::cli::{CliUsage, SERVER_IMPORTS, SERVE_PATTERN};
+use crate::pipeline::confidence::{ConfidenceTracker, Evidence};
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+/// Modules of reverse proxies and API gateways
+const PROXY_MODULES: [&str; 3] = [
+    "github.com/grpc-ecosystem/grpc-gateway",
+    "github.com/vulcand/oxy",
+    "github.com/koding/websocketproxy",
+];
+
+/// Modules of in-process job schedulers
+const CRON_MODULES: [&str; 2] = ["github.com/robfig/cron", "github.com/go-co-op/gocron"];
+
+/// Directories whose main packages are demos or test data, not the module's own program
+const NON_PROGRAM_DIRS: [&str; 5] = ["vendor", "testdata", "example", "examples", "_examples"];
+
+/// Declared in order of precedence: a module showing signals of several types is classified as
+/// the first of them
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord)]
+pub enum ProjectType {
+    /// Forwards requests to other services (`httputil.ReverseProxy`, grpc-gateway)
+    Proxy,
+    /// Serves HTTP or RPC requests
+    Service,
+    /// Runs work on a schedule (`time.Tick`, cron) instead of serving requests
+    Batch,
+    /// A command-line tool
+    Cli,
+    /// No main package, so nothing to run
+    Library,
+}
+
+impl ProjectType {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            ProjectType::Proxy => "proxy",
+            ProjectType::Service => "service",
+            ProjectType::Batch => "batch",
+            ProjectType::Cli => "cli",
+            ProjectType::Library => "library",
+        }
+    }
+
+    /// Accepts connections, so deploys with a port and health check
+    pub fn listens(self) -> bool {
+        matches!(self, ProjectType::Proxy | ProjectType::Service)
+    }
+}
+
+#[derive(Debug, Clone, PartialEq)]
+pub struct ProjectTypeClassification {
+    pub primary: ProjectType,
+    /// Combined weight of the evidence for `primary`, 0.0..=1.0
+    pub confidence: f64,
+    /// Other types the module shows signals of, in order of precedence; a Cobra command
+    /// fronting a server is a service with `Cli` here
+    pub secondary: Vec<ProjectType>,
+}
+
+pub struct ProjectTypeClassifier;
+
+impl ProjectTypeClassifier {
+    /// Classifies the service from its Go files among `file_tree` (repository-relative), its
+    /// go.mod `dependencies` and the Cobra usage `CliDetector` found
+    ///
+    /// A module without a main package is a library whatever it imports. `None` when a main
+    /// package shows no signal of any type.
+    pub fn classify<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        dependencies: &[String],
+        cli: Option<&CliUsage>,
+    ) -> Option<ProjectTypeClassification> {
+        let main_re = Regex::new(r"(?m)^package\s+main\b").expect("valid main package regex");
+        let serve_re = Regex::new(SERVE_PATTERN).expect("valid net/http serve regex");
+        let proxy_re = Regex::new(r"\bhttputil\.(?:NewSingleHostReverseProxy\(|ReverseProxy\s*\{)")
+            .expect("valid reverse proxy regex");
+        // for range time.Tick(time.Hour) { ... }
+        let tick_re = Regex::new(r"\bfor\s+(?:\w+\s*:?=\s*)?range\s+time\.Tick\(")
+            .expect("valid ticker loop regex");
+
+        let mut evidence = ConfidenceTracker::new();
+        let mut record = |project_type: ProjectType, signal: Evidence| {
+            evidence.record(project_type.as_str(), signal)
+        };
+
+        for (modules, project_type) in [
+            (&SERVER_IMPORTS[..], ProjectType::Service),
+            (&PROXY_MODULES[..], ProjectType::Proxy),
+            (&CRON_MODULES[..], ProjectType::Batch),
+        ] {
+            if dependencies.iter().any(|dependency| {
+                modules
+                    .iter()
+                    .any(|module| is_module_or_package(dependency, module))
+            }) {
+                record(project_type, Evidence::Dependency);
+            }
+        }
+        if let Some(usage) = cli {
+            record(ProjectType::Cli, Evidence::Dependency);
+            if usage.root_command.is_some() {
+                record(ProjectType::Cli, Evidence::SourceFile);
+            }
+        }
+
+        let mut go_files = 0;
+        let mut has_main = false;
+        let (mut serves, mut proxies, mut ticks) = (false, false, false);
+        for path in file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| {
+                path.file_name()
+                    .and_then(|name| name.to_str())
+                    .is_some_and(|name| name.ends_with(".go") && !name.ends_with("_test.go"))
+            })
+        {
+            let Ok(content) = fs.read_to_string(&repo_path.join(service_path).join(path)) else {
+                continue;
+            };
+            go_files += 1;
+            has_main |= main_re.is_match(&content)
+                && !path.components().any(|component| {
+                    NON_PROGRAM_DIRS.contains(&component.as_os_str().to_string_lossy().as_ref())
+                });
+            serves |= serve_re.is_match(&content)
+                || SERVER_IMPORTS
+                    .iter()
+                    .any(|import| content.contains(&format!("\"{}", import)));
+            proxies |= proxy_re.is_match(&content);
+            ticks |= tick_re.is_match(&content);
+        }
+        for (found, project_type) in [
+            (serves, ProjectType::Service),
+            (proxies, ProjectType::Proxy),
+            (ticks, ProjectType::Batch),
+        ] {
+            if found {
+                record(project_type, Evidence::SourceFile);
+            }
+        }
+
+        if go_files > 0 && !has_main {
+            return Some(ProjectTypeClassification {
+                primary: ProjectType::Library,
+                confidence: Evidence::Extracted(0.9).weight(),
+                secondary: vec![],
+            });
+        }
+
+        let mut found: Vec<(ProjectType, f64)> = [
+            ProjectType::Proxy,
+            ProjectType::Service,
+            ProjectType::Batch,
+            ProjectType::Cli,
+        ]
+        .into_iter()
+        .filter_map(|project_type| Some((project_type, evidence.score(project_type.as_str())?)))
+        .collect();
+        if found.is_empty() {
+            return None;
+        }
+        let (primary, confidence) = found.remove(0);
+        Some(ProjectTypeClassification {
+            primary,
+            confidence,
+            secondary: found
+                .into_iter()
+                .map(|(project_type, _)| project_type)
+                // A proxy serves the requests it forwards
+                .filter(|project_type| {
+                    !(primary == ProjectType::Proxy && *project_type == ProjectType::Service)
+                })
+                .collect(),
+        })
+    }
+}
+
+/// `dependency` is `module`, one of its packages or one of its major versions
+fn is_module_or_package(dependency: &str, module: &str) -> bool {
+    dependency == module || dependency.starts_with(&format!("{}/", module))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn classify(
+        files: &[(&str, &str)],
+        dependencies: &[&str],
+        cli: Option<CliUsage>,
+    ) -> Option<ProjectTypeClassification> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        let dependencies: Vec<String> = dependencies.iter().map(|d| d.to_string()).collect();
+        ProjectTypeClassifier::classify(
+            Path::new(""),
+            Path::new(""),
+            &tree,
+            &fs,
+            &dependencies,
+            cli.as_ref(),
+        )
+    }
+
+    const GIN: &str = r#"package main
+
+import "github.com/gin-gonic/gin"
+
+func main() {
+	r := gin.Default()
+	r.Run(":8080")
+}
+"#;
+
+    const PROXY: &str = r#"package main
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+func main() {
+	target, _ := url.Parse("http://backend:9000")
+	http.ListenAndServe(":8080", httputil.NewSingleHostReverseProxy(target))
+}
+"#;
+
+    const TICKER: &str = r#"package main
+
+import "time"
+
+func main() {
+	for range time.Tick(time.Hour) {
+		cleanup()
+	}
+}
+"#;
+
+    #[test]
+    fn test_service() {
+        let classification =
+            classify(&[("main.go", GIN)], &["github.com/gin-gonic/gin"], None).unwrap();
+        assert_eq!(classification.primary, ProjectType::Service);
+        assert_eq!(classification.confidence, 0.98);
+        assert!(classification.secondary.is_empty());
+        assert!(classification.primary.listens());
+    }
+
+    #[test]
+    fn test_proxy() {
+        let classification = classify(&[("main.go", PROXY)], &[], None).unwrap();
+        assert_eq!(classification.primary, ProjectType::Proxy);
+        assert_eq!(classification.confidence, 0.6);
+        assert!(classification.secondary.is_empty());
+
+        let gateway = classify(
+            &[("main.go", "package main\n")],
+            &["github.com/grpc-ecosystem/grpc-gateway/v2/runtime"],
+            None,
+        )
+        .unwrap();
+        assert_eq!(gateway.primary, ProjectType::Proxy);
+    }
+
+    #[test]
+    fn test_batch_job() {
+        let classification = classify(&[("main.go", TICKER)], &[], None).unwrap();
+        assert_eq!(classification.primary, ProjectType::Batch);
+        assert!(!classification.primary.listens());
+
+        let cron = classify(
+            &[("main.go", "package main\n"), ("metrics.go", GIN)],
+            &["github.com/robfig/cron/v3"],
+            None,
+        )
+        .unwrap();
+        // Serving metrics alongside the schedule makes it a service that also runs jobs
+        assert_eq!(cron.primary, ProjectType::Service);
+        assert_eq!(cron.secondary, vec![ProjectType::Batch]);
+    }
+
+    #[test]
+    fn test_cli() {
+        let tool = CliUsage {
+            root_command: Some("greet".to_string()),
+            serves: false,
+        };
+        let classification = classify(
+            &[("main.go", "package main\n")],
+            &["github.com/spf13/cobra"],
+            Some(tool),
+        )
+        .unwrap();
+        assert_eq!(classification.primary, ProjectType::Cli);
+        assert_eq!(classification.primary.as_str(), "cli");
+        assert_eq!(classification.confidence, 0.98);
+
+        let server = CliUsage {
+            root_command: Some("api".to_string()),
+            serves: true,
+        };
+        let classification = classify(
+            &[("main.go", "package main\n"), ("cmd/serve.go", GIN)],
+            &["github.com/spf13/cobra"],
+            Some(server),
+        )
+        .unwrap();
+        assert_eq!(classification.primary.as_str(), "service");
+        assert_eq!(classification.secondary, vec![ProjectType::Cli]);
+    }
+
+    #[test]
+    fn test_library() {
+        let classification = classify(
+            &[
+                ("client.go", "package client\n\nimport \"net/http\"\n"),
+                (
+                    "server.go",
+                    GIN.replace("package main", "package client").as_str(),
+                ),
+                ("examples/basic/main.go", GIN),
+                ("client_test.go", "package main\n"),
+            ],
+            &["github.com/gin-gonic/gin"],
+            None,
+        )
+        .unwrap();
+        assert_eq!(classification.primary, ProjectType::Library);
+        assert_eq!(classification.confidence, 0.9);
+        assert!(classification.secondary.is_empty());
+    }
+
+    #[test]
+    fn test_unclassified() {
+        assert_eq!(
+            classify(
+                &[("main.go", "package main\n\nfunc main() {}\n")],
+                &[],
+                None
+            ),
+            None
+        );
+        assert_eq!(classify(&[], &[], None), None);
+    }
+}
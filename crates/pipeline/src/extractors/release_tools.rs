@@ -0,0 +1,367 @@
+//! Release tool detector - GoReleaser configs and ko, which builds Go container images without a
+//! Dockerfile
+
+use peelbox_core::fs::FileSystem;
+use serde_yaml::Value;
+use std::path::{Path, PathBuf};
+
+const GORELEASER_CONFIGS: [&str; 4] = [
+    ".goreleaser.yaml",
+    ".goreleaser.yml",
+    "goreleaser.yaml",
+    "goreleaser.yml",
+];
+const KO_CONFIGS: [&str; 2] = [".ko.yaml", "ko.yaml"];
+const KO_MODULE: &str = "github.com/google/ko";
+
+/// GoReleaser's defaults for a build that names no `goos`/`goarch`
+const DEFAULT_GOOS: [&str; 3] = ["linux", "darwin", "windows"];
+const DEFAULT_GOARCH: [&str; 3] = ["amd64", "arm64", "386"];
+/// Pairs Go no longer builds for, which GoReleaser skips
+const UNSUPPORTED_TARGETS: [&str; 1] = ["darwin/386"];
+const DEFAULT_ARCHIVE_FORMAT: &str = "tar.gz";
+
+/// A GoReleaser config and what its builds and archives produce
+#[derive(Debug, Clone, PartialEq)]
+pub struct GoReleaser {
+    /// Relative to the repository
+    pub config: String,
+    pub binaries: Vec<String>,
+    /// `goos/goarch` pairs across all builds, e.g. `linux/arm64`
+    pub targets: Vec<String>,
+    /// Archive formats, including `format_overrides`, e.g. `tar.gz` and `zip`
+    pub archive_formats: Vec<String>,
+}
+
+impl GoReleaser {
+    pub const TOOL: &'static str = "goreleaser";
+    /// Builds, archives and publishes a release from the current tag
+    pub const RELEASE_COMMAND: &'static str = "goreleaser release --clean";
+}
+
+/// ko, found from its config or a go.mod requirement on it
+#[derive(Debug, Clone, PartialEq)]
+pub struct Ko {
+    /// Relative to the repository
+    pub config: Option<String>,
+    /// `ko build` for the main package, pushing to `KO_DOCKER_REPO`
+    pub build_command: String,
+}
+
+impl Ko {
+    pub const TOOL: &'static str = "ko";
+}
+
+#[derive(Debug, Clone, PartialEq)]
+pub struct ReleaseTools {
+    pub goreleaser: Option<GoReleaser>,
+    pub ko: Option<Ko>,
+}
+
+pub struct ReleaseToolDetector;
+
+impl ReleaseToolDetector {
+    /// Reads GoReleaser and ko configs from the service directory, else the repository root,
+    /// among `file_tree` (repository-relative); ko is also detected from the service's go.mod
+    /// `dependencies`
+    ///
+    /// `project_name` is the binary a GoReleaser build without `binary` produces.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        dependencies: &[String],
+        project_name: &str,
+    ) -> Option<ReleaseTools> {
+        let find = |names: &[&str]| {
+            [service_path, Path::new("")].into_iter().find_map(|dir| {
+                names
+                    .iter()
+                    .map(|name| dir.join(name))
+                    .find(|path| file_tree.contains(path))
+            })
+        };
+        let read = |path: &Path| {
+            fs.read_to_string(&repo_path.join(path))
+                .ok()
+                .and_then(|content| serde_yaml::from_str::<Value>(&content).ok())
+        };
+        let display = |path: &Path| path.to_string_lossy().replace('\\', "/");
+
+        let goreleaser = find(&GORELEASER_CONFIGS).map(|path| {
+            let config = read(&path).unwrap_or(Value::Null);
+            let default_binary = config["project_name"].as_str().unwrap_or(project_name);
+            GoReleaser {
+                config: display(&path),
+                binaries: binaries(&config, default_binary),
+                targets: targets(&config),
+                archive_formats: archive_formats(&config),
+            }
+        });
+
+        let ko_config = find(&KO_CONFIGS);
+        let ko_dependency = dependencies
+            .iter()
+            .any(|dependency| dependency == KO_MODULE);
+        let ko = (ko_config.is_some() || ko_dependency).then(|| {
+            // A ko config's own build of the service names its main package
+            let main = ko_config
+                .as_deref()
+                .and_then(read)
+                .and_then(|config| {
+                    config["builds"]
+                        .as_sequence()
+                        .and_then(|builds| builds.first())
+                        .and_then(|build| build["main"].as_str().map(str::to_string))
+                })
+                .unwrap_or_else(|| ".".to_string());
+            Ko {
+                config: ko_config.as_deref().map(display),
+                build_command: format!("ko build {}", main),
+            }
+        });
+
+        (goreleaser.is_some() || ko.is_some()).then_some(ReleaseTools { goreleaser, ko })
+    }
+}
+
+/// Builds that are not `skip`ped, in config order; a config without `builds` has one default
+fn builds(config: &Value) -> Vec<&Value> {
+    match config["builds"].as_sequence() {
+        Some(builds) => builds
+            .iter()
+            .filter(|build| build["skip"].as_bool() != Some(true))
+            .collect(),
+        None => vec![&Value::Null],
+    }
+}
+
+fn binaries(config: &Value, default_binary: &str) -> Vec<String> {
+    let mut binaries: Vec<String> = Vec::new();
+    for build in builds(config) {
+        let binary = build["binary"]
+            .as_str()
+            .filter(|binary| !binary.contains("{{"))
+            .unwrap_or(default_binary)
+            .to_string();
+        if !binaries.contains(&binary) {
+            binaries.push(binary);
+        }
+    }
+    binaries
+}
+
+/// `goos` × `goarch` less `ignore`, or a build's explicit `targets` (`linux_amd64`)
+fn targets(config: &Value) -> Vec<String> {
+    let list = |value: &Value, default: &[&str]| -> Vec<String> {
+        value
+            .as_sequence()
+            .map(|values| values.iter().filter_map(scalar).collect())
+            .unwrap_or_else(|| default.iter().map(|value| value.to_string()).collect())
+    };
+
+    let mut targets: Vec<String> = Vec::new();
+    for build in builds(config) {
+        let explicit: Vec<String> = list(&build["targets"], &[])
+            .into_iter()
+            .filter_map(|target| {
+                let mut parts = target.splitn(3, '_');
+                Some(format!("{}/{}", parts.next()?, parts.next()?))
+            })
+            .collect();
+        let build_targets = if explicit.is_empty() {
+            let ignored: Vec<String> = build["ignore"]
+                .as_sequence()
+                .into_iter()
+                .flatten()
+                .filter_map(|ignore| {
+                    Some(format!(
+                        "{}/{}",
+                        scalar(&ignore["goos"])?,
+                        scalar(&ignore["goarch"])?
+                    ))
+                })
+                .collect();
+            let goarch = list(&build["goarch"], &DEFAULT_GOARCH);
+            list(&build["goos"], &DEFAULT_GOOS)
+                .iter()
+                .flat_map(|goos| {
+                    goarch
+                        .iter()
+                        .map(move |goarch| format!("{}/{}", goos, goarch))
+                })
+                .filter(|target| {
+                    !ignored.contains(target) && !UNSUPPORTED_TARGETS.contains(&target.as_str())
+                })
+                .collect()
+        } else {
+            explicit
+        };
+        for target in build_targets {
+            if !targets.contains(&target) {
+                targets.push(target);
+            }
+        }
+    }
+    targets
+}
+
+/// `goarch: 386` is a YAML number
+fn scalar(value: &Value) -> Option<String> {
+    match value {
+        Value::String(value) => Some(value.clone()),
+        Value::Number(value) => Some(value.to_string()),
+        _ => None,
+    }
+}
+
+/// v1 `format`, v2 `formats` and each `format_overrides` entry of every archive
+fn archive_formats(config: &Value) -> Vec<String> {
+    let mut formats: Vec<String> = Vec::new();
+    let mut add = |value: &Value| {
+        let values = match value {
+            Value::String(format) => vec![format.as_str()],
+            Value::Sequence(values) => values.iter().filter_map(Value::as_str).collect(),
+            _ => vec![],
+        };
+        for format in values {
+            if !formats.iter().any(|known| known == format) {
+                formats.push(format.to_string());
+            }
+        }
+    };
+
+    let archives = match config["archives"].as_sequence() {
+        Some(archives) => archives.iter().collect(),
+        None => vec![&Value::Null],
+    };
+    for archive in archives {
+        let format = &archive["formats"];
+        if format.is_null() && archive["format"].is_null() {
+            add(&Value::String(DEFAULT_ARCHIVE_FORMAT.to_string()));
+        }
+        add(format);
+        add(&archive["format"]);
+        for format_override in archive["format_overrides"]
+            .as_sequence()
+            .into_iter()
+            .flatten()
+        {
+            add(&format_override["formats"]);
+            add(&format_override["format"]);
+        }
+    }
+    formats
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const GORELEASER: &str = r#"version: 2
+project_name: app
+builds:
+  - id: app
+    main: .
+    binary: app
+    env:
+      - CGO_ENABLED=0
+    goos:
+      - linux
+      - darwin
+      - windows
+    goarch:
+      - amd64
+      - arm64
+    ignore:
+      - goos: windows
+        goarch: arm64
+  - id: admin
+    main: ./cmd/admin
+    binary: app-admin
+    targets:
+      - linux_amd64_v1
+archives:
+  - formats: [tar.gz]
+    format_overrides:
+      - goos: windows
+        formats: [zip]
+"#;
+
+    fn detect(files: &[(&str, &str)], dependencies: &[&str]) -> Option<ReleaseTools> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        let dependencies: Vec<String> = dependencies.iter().map(|d| d.to_string()).collect();
+        ReleaseToolDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &tree,
+            &fs,
+            &dependencies,
+            "service",
+        )
+    }
+
+    #[test]
+    fn test_goreleaser_builds_and_archives() {
+        let tools = detect(&[(".goreleaser.yaml", GORELEASER)], &[]).unwrap();
+
+        assert_eq!(
+            tools.goreleaser,
+            Some(GoReleaser {
+                config: ".goreleaser.yaml".to_string(),
+                binaries: vec!["app".to_string(), "app-admin".to_string()],
+                targets: vec![
+                    "linux/amd64".to_string(),
+                    "linux/arm64".to_string(),
+                    "darwin/amd64".to_string(),
+                    "darwin/arm64".to_string(),
+                    "windows/amd64".to_string(),
+                ],
+                archive_formats: vec!["tar.gz".to_string(), "zip".to_string()],
+            })
+        );
+        assert_eq!(tools.ko, None);
+    }
+
+    #[test]
+    fn test_goreleaser_defaults() {
+        let goreleaser = detect(&[(".goreleaser.yml", "version: 1\n")], &[])
+            .unwrap()
+            .goreleaser
+            .unwrap();
+
+        assert_eq!(goreleaser.binaries, vec!["service"]);
+        assert_eq!(goreleaser.targets.len(), 8);
+        assert!(!goreleaser.targets.contains(&"darwin/386".to_string()));
+        assert_eq!(goreleaser.archive_formats, vec!["tar.gz"]);
+    }
+
+    #[test]
+    fn test_ko() {
+        let tools = detect(
+            &[(
+                ".ko.yaml",
+                "defaultBaseImage: cgr.dev/chainguard/static\nbuilds:\n  - id: api\n    main: ./cmd/api\n",
+            )],
+            &[],
+        )
+        .unwrap();
+        assert_eq!(
+            tools.ko,
+            Some(Ko {
+                config: Some(".ko.yaml".to_string()),
+                build_command: "ko build ./cmd/api".to_string(),
+            })
+        );
+
+        let tool = detect(&[("main.go", "package main\n")], &[KO_MODULE]).unwrap();
+        assert_eq!(tool.ko.unwrap().build_command, "ko build .");
+        assert_eq!(detect(&[("main.go", "package main\n")], &[]), None);
+    }
+}
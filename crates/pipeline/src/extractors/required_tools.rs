@@ -0,0 +1,309 @@
+//! Required tools detector - external binaries the build invokes besides the toolchain
+
+use super::go_generate::{GoGenerate, KNOWN_GENERATORS};
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::RequiredTool;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+/// Non-Go tools recognised by name in Makefile recipes and npm scripts
+const KNOWN_BINARIES: [&str; 2] = ["protoc", "buf"];
+
+pub struct RequiredToolsDetector;
+
+impl RequiredToolsDetector {
+    /// Tools needed by the service's `go generate` steps, Makefile recipes and package.json
+    /// scripts, in that order
+    ///
+    /// Recipes and scripts only contribute `go install`/`go run` targets and well-known
+    /// generators, since everything else they call is ordinary shell. Versions come from a
+    /// pinned `@version` or, as a lower bound, from the go.mod requirement of the tool's module.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        generate: &GoGenerate,
+    ) -> Vec<RequiredTool> {
+        let read = |name: &str| {
+            file_tree
+                .iter()
+                .any(|path| path.strip_prefix(service_path).ok() == Some(Path::new(name)))
+                .then(|| {
+                    fs.read_to_string(&repo_path.join(service_path).join(name))
+                        .ok()
+                })
+                .flatten()
+        };
+
+        let mut commands = generate.commands.clone();
+        let mut tools: Vec<String> = generate.tools.clone();
+        for command in read("Makefile")
+            .map(|content| makefile_recipes(&content))
+            .into_iter()
+            .chain(read("package.json").map(|content| npm_scripts(&content)))
+            .flatten()
+        {
+            for tool in segment_tools(&command) {
+                if !tools.contains(&tool) {
+                    tools.push(tool);
+                }
+            }
+            commands.push(command);
+        }
+
+        let requirements = read("go.mod")
+            .map(|content| go_mod_requirements(&content))
+            .unwrap_or_default();
+        let pins = pinned_versions(&commands);
+
+        let mut required: Vec<RequiredTool> = Vec::new();
+        for tool in tools {
+            let is_module = is_go_package(&tool);
+            let name = if is_module {
+                binary_name(&tool)
+            } else {
+                tool.clone()
+            };
+            if required.iter().any(|existing| existing.name == name) {
+                continue;
+            }
+            let version_hint = is_module
+                .then(|| {
+                    pins.iter()
+                        .find(|(package, _)| *package == tool)
+                        .map(|(_, version)| version.clone())
+                        .or_else(|| {
+                            requirements
+                                .iter()
+                                .find(|(module, _)| within_module(&tool, module))
+                                .map(|(_, version)| format!(">={}", version))
+                        })
+                })
+                .flatten();
+            required.push(RequiredTool {
+                install_command: is_module.then(|| format!("go install {}@latest", tool)),
+                name,
+                version_hint,
+            });
+        }
+        required
+    }
+}
+
+/// Shell lines of Makefile recipes, without the `@`, `-` and `+` prefixes
+fn makefile_recipes(content: &str) -> Vec<String> {
+    content
+        .lines()
+        .filter_map(|line| line.strip_prefix('\t'))
+        .map(|line| line.trim_start_matches(['@', '-', '+']).trim().to_string())
+        .filter(|line| !line.is_empty())
+        .collect()
+}
+
+fn npm_scripts(content: &str) -> Vec<String> {
+    serde_json::from_str::<serde_json::Value>(content)
+        .ok()
+        .and_then(|json| json.get("scripts")?.as_object().cloned())
+        .map(|scripts| {
+            scripts
+                .values()
+                .filter_map(|script| script.as_str().map(str::to_string))
+                .collect()
+        })
+        .unwrap_or_default()
+}
+
+/// Tools called by each command of a shell line: `go install`/`go run` packages and known
+/// generator binaries, the latter mapped to their module when they are written in Go
+fn segment_tools(line: &str) -> Vec<String> {
+    line.split(['&', ';', '|'])
+        .filter_map(|segment| {
+            let args: Vec<&str> = segment
+                .split_whitespace()
+                .skip_while(|arg| arg.contains('=') || *arg == "npx" || *arg == "exec")
+                .collect();
+            match args.as_slice() {
+                ["go", "install" | "run", rest @ ..] => rest
+                    .iter()
+                    .find(|arg| !arg.starts_with('-'))
+                    .filter(|package| is_go_package(package))
+                    .map(|package| package.split('@').next().unwrap_or(package).to_string()),
+                [program, ..] => KNOWN_GENERATORS
+                    .iter()
+                    .find(|(name, _)| name == program)
+                    .map(|(_, module)| module.to_string())
+                    .or_else(|| {
+                        KNOWN_BINARIES
+                            .contains(program)
+                            .then(|| program.to_string())
+                    }),
+                [] => None,
+            }
+        })
+        .collect()
+}
+
+/// `package@vX` pins in the commands, as package and version without the `v`
+fn pinned_versions(commands: &[String]) -> Vec<(String, String)> {
+    let pin = Regex::new(r"([\w.\-/]+/[\w.\-]+)@v(\d[\w.\-+]*)").expect("valid pin regex");
+    commands
+        .iter()
+        .flat_map(|command| {
+            pin.captures_iter(command)
+                .map(|caps| (caps[1].to_string(), caps[2].to_string()))
+                .collect::<Vec<_>>()
+        })
+        .collect()
+}
+
+/// Module paths and versions (without the `v`) required by go.mod
+pub(crate) fn go_mod_requirements(content: &str) -> Vec<(String, String)> {
+    let requirement =
+        Regex::new(r"(?m)^(?:require[ \t]+|[ \t]+)([\w.\-]+(?:/[\w.\-]+)+)\s+v(\d[\w.\-+]*)")
+            .expect("valid requirement regex");
+    requirement
+        .captures_iter(content)
+        .map(|caps| (caps[1].to_string(), caps[2].to_string()))
+        .collect()
+}
+
+/// Whether `tool` is a remote Go package path rather than a binary or local script
+fn is_go_package(tool: &str) -> bool {
+    tool.contains('/')
+        && !tool.ends_with(".go")
+        && tool
+            .split('/')
+            .next()
+            .is_some_and(|host| host.contains('.'))
+        && !tool.starts_with('.')
+}
+
+pub(crate) fn within_module(package: &str, module: &str) -> bool {
+    package == module
+        || package
+            .strip_prefix(module)
+            .is_some_and(|rest| rest.starts_with('/'))
+}
+
+/// Name of the binary `go install` builds for a package: its last path element, skipping a
+/// major-version suffix
+fn binary_name(package: &str) -> String {
+    let mut elements = package.rsplit('/');
+    let last = elements.next().unwrap_or(package);
+    let is_major_version =
+        last.len() > 1 && last.starts_with('v') && last[1..].chars().all(|c| c.is_ascii_digit());
+    match elements.next() {
+        Some(parent) if is_major_version => parent.to_string(),
+        _ => last.to_string(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    fn tool(name: &str, version_hint: Option<&str>, install: Option<&str>) -> RequiredTool {
+        RequiredTool {
+            name: name.to_string(),
+            version_hint: version_hint.map(str::to_string),
+            install_command: install.map(str::to_string),
+        }
+    }
+
+    #[test]
+    fn test_aggregates_go_generate_makefile_and_npm_scripts() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "svc/go.mod",
+            "module example.com/svc\n\ngo 1.22\n\nrequire (\n\tgithub.com/google/wire v0.6.0\n\tgo.uber.org/mock v0.4.0\n)\n",
+        );
+        fs.add_file(
+            "svc/Makefile",
+            "generate:\n\t@protoc --go_out=. api.proto\n\tgo install github.com/sqlc-dev/sqlc/cmd/sqlc@v1.26.0 && sqlc generate\n",
+        );
+        fs.add_file(
+            "svc/package.json",
+            r#"{"scripts": {"docs": "swag init -g main.go", "build": "tsc"}}"#,
+        );
+        let generate = GoGenerate {
+            commands: vec![
+                "wire".to_string(),
+                "cd store && mockgen -source=store.go -destination=mock.go".to_string(),
+                "./scripts/embed-version.sh".to_string(),
+            ],
+            tools: vec![
+                "github.com/google/wire/cmd/wire".to_string(),
+                "go.uber.org/mock/mockgen".to_string(),
+                "./scripts/embed-version.sh".to_string(),
+            ],
+        };
+
+        let tools = RequiredToolsDetector::detect(
+            Path::new(""),
+            Path::new("svc"),
+            &paths(&["svc/Makefile", "svc/go.mod", "svc/package.json"]),
+            &fs,
+            &generate,
+        );
+        assert_eq!(
+            tools,
+            vec![
+                tool(
+                    "wire",
+                    Some(">=0.6.0"),
+                    Some("go install github.com/google/wire/cmd/wire@latest")
+                ),
+                tool(
+                    "mockgen",
+                    Some(">=0.4.0"),
+                    Some("go install go.uber.org/mock/mockgen@latest")
+                ),
+                tool("./scripts/embed-version.sh", None, None),
+                tool("protoc", None, None),
+                tool(
+                    "sqlc",
+                    Some("1.26.0"),
+                    Some("go install github.com/sqlc-dev/sqlc/cmd/sqlc@latest")
+                ),
+                tool(
+                    "swag",
+                    None,
+                    Some("go install github.com/swaggo/swag/cmd/swag@latest")
+                ),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_no_tools() {
+        let fs = MockFileSystem::new();
+        fs.add_file("Makefile", "build:\n\tgo build -o app .\n\trm -rf dist\n");
+        assert!(RequiredToolsDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["Makefile"]),
+            &fs,
+            &GoGenerate::default()
+        )
+        .is_empty());
+    }
+
+    #[test]
+    fn test_binary_name() {
+        assert_eq!(binary_name("github.com/google/wire/cmd/wire"), "wire");
+        assert_eq!(
+            binary_name("github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen"),
+            "oapi-codegen"
+        );
+        assert_eq!(
+            binary_name("github.com/golang-migrate/migrate/v4"),
+            "migrate"
+        );
+    }
+}
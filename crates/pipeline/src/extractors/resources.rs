@@ -0,0 +1,261 @@
+//! Resource estimator - starting CPU and memory requests for a service
+//!
+//! The numbers are rough heuristics for a first deployment, not measurements; operators should
+//! replace them with what the service actually uses under load.
+//!
+//! The estimate is built in steps:
+//!
+//! 1. A baseline for the language runtime: compiled binaries (Go, Rust, C++, Haskell, Swift)
+//!    idle in a few tens of megabytes, interpreted runtimes (Node.js, Python, Ruby, PHP,
+//!    Elixir, Dart) need more, and the JVM and .NET reserve a heap up front.
+//! 2. Framework overhead for frameworks that load much of their ecosystem at startup
+//!    (Spring Boot, Rails, Django, Next.js, NestJS).
+//! 3. Client overhead per backing service: a SQL connection pool holds about ten open
+//!    connections with their buffers and prepared statements, document and cache clients
+//!    hold fewer.
+//! 4. gRPC servers keep long-lived HTTP/2 connections with per-stream flow-control buffers.
+//! 5. Machine learning libraries (TensorFlow, PyTorch, JAX) replace the estimate with a
+//!    high-memory profile and a GPU hint, since the model rather than the code sizes the
+//!    process.
+//!
+//! Confidence is `medium` for a known runtime and `low` otherwise, or when a machine learning
+//! library is involved.
+
+use peelbox_core::output::schema::{BackingService, ResourceHints};
+use peelbox_stack::{FrameworkId, LanguageId};
+
+/// (minimum MB, recommended MB, CPU millicores) of an idle service on the runtime
+fn runtime_baseline(language: &LanguageId) -> Option<(u32, u32, u32)> {
+    match language {
+        LanguageId::Go
+        | LanguageId::Rust
+        | LanguageId::Cpp
+        | LanguageId::Haskell
+        | LanguageId::Swift => Some((64, 128, 100)),
+        LanguageId::JavaScript
+        | LanguageId::TypeScript
+        | LanguageId::Python
+        | LanguageId::Ruby
+        | LanguageId::PHP
+        | LanguageId::Elixir
+        | LanguageId::Dart => Some((128, 256, 250)),
+        LanguageId::Java
+        | LanguageId::Kotlin
+        | LanguageId::Scala
+        | LanguageId::CSharp
+        | LanguageId::FSharp => Some((256, 512, 500)),
+        _ => None,
+    }
+}
+
+/// Baseline for runtimes not listed above
+const UNKNOWN_BASELINE: (u32, u32, u32) = (128, 256, 250);
+
+/// Extra megabytes frameworks load at startup
+fn framework_overhead_mb(framework: &FrameworkId) -> u32 {
+    match framework {
+        FrameworkId::SpringBoot => 256,
+        FrameworkId::Rails | FrameworkId::NextJs => 128,
+        FrameworkId::Django | FrameworkId::NestJs => 64,
+        _ => 0,
+    }
+}
+
+/// Extra megabytes of a client's connections, by backing service name
+fn client_overhead_mb(service: &str) -> u32 {
+    match service {
+        "postgresql" | "mysql" => 32,
+        "mongodb" => 32,
+        "redis" => 16,
+        _ => 0,
+    }
+}
+
+/// Extra megabytes and millicores of a gRPC server's HTTP/2 connection state
+const GRPC_OVERHEAD: (u32, u32) = (32, 100);
+
+/// Machine learning libraries, matched as prefixes of dependency names
+const ML_LIBRARIES: &[&str] = &[
+    "tensorflow",
+    "torch",
+    "jax",
+    "keras",
+    "github.com/tensorflow/tensorflow",
+    "@tensorflow/tfjs-node",
+    "tch",
+];
+
+/// (minimum MB, recommended MB, CPU millicores) of a process loading a model
+const ML_PROFILE: (u32, u32, u32) = (2048, 4096, 1000);
+
+pub struct ResourceEstimator;
+
+impl ResourceEstimator {
+    /// Estimates resource requests from the service's runtime, framework, dependency names,
+    /// backing services and whether it serves gRPC
+    pub fn estimate(
+        language: &LanguageId,
+        framework: Option<&FrameworkId>,
+        dependencies: &[String],
+        backing_services: &[BackingService],
+        grpc: bool,
+    ) -> ResourceHints {
+        let baseline = runtime_baseline(language);
+        let (mut min, mut recommended, mut cpu) = baseline.unwrap_or(UNKNOWN_BASELINE);
+        let mut notes = Vec::new();
+
+        if let Some(overhead) = framework.map(framework_overhead_mb).filter(|mb| *mb > 0) {
+            min += overhead;
+            recommended += overhead;
+        }
+
+        for service in backing_services {
+            let overhead = client_overhead_mb(&service.name);
+            if overhead > 0 {
+                min += overhead;
+                recommended += overhead;
+                notes.push(format!(
+                    "{} client: about {}MB for its connection pool",
+                    service.name, overhead
+                ));
+            }
+        }
+
+        if grpc {
+            let (overhead, millicores) = GRPC_OVERHEAD;
+            recommended += overhead;
+            cpu += millicores;
+            notes.push(
+                "gRPC keeps long-lived HTTP/2 connections; memory grows with concurrent clients and streams"
+                    .to_string(),
+            );
+        }
+
+        let ml = dependencies.iter().find(|dependency| {
+            ML_LIBRARIES.iter().any(|lib| {
+                dependency.as_str() == *lib
+                    || dependency
+                        .strip_prefix(lib)
+                        .is_some_and(|rest| rest.starts_with(['/', '-']))
+            })
+        });
+        if let Some(library) = ml {
+            let (ml_min, ml_recommended, ml_cpu) = ML_PROFILE;
+            min = min.max(ml_min);
+            recommended = recommended.max(ml_recommended);
+            cpu = cpu.max(ml_cpu);
+            notes.push(format!(
+                "{} loads models into memory; size the limit to the model, and schedule on a GPU node for inference or training",
+                library
+            ));
+        }
+
+        ResourceHints {
+            min_memory_mb: min,
+            recommended_memory_mb: recommended,
+            cpu_millicores: cpu,
+            gpu: ml.is_some(),
+            notes,
+            estimation_confidence: if baseline.is_some() && ml.is_none() {
+                "medium"
+            } else {
+                "low"
+            }
+            .to_string(),
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn strings(values: &[&str]) -> Vec<String> {
+        values.iter().map(|v| v.to_string()).collect()
+    }
+
+    fn backing(name: &str) -> BackingService {
+        BackingService {
+            name: name.to_string(),
+            detected_via: vec!["import".to_string()],
+            confidence: 0.6,
+        }
+    }
+
+    #[test]
+    fn test_go_binary_baseline() {
+        let hints = ResourceEstimator::estimate(&LanguageId::Go, None, &[], &[], false);
+        assert_eq!(
+            (
+                hints.min_memory_mb,
+                hints.recommended_memory_mb,
+                hints.cpu_millicores
+            ),
+            (64, 128, 100)
+        );
+        assert!(!hints.gpu);
+        assert!(hints.notes.is_empty());
+        assert_eq!(hints.estimation_confidence, "medium");
+    }
+
+    #[test]
+    fn test_go_service_with_postgres_and_grpc() {
+        let hints = ResourceEstimator::estimate(
+            &LanguageId::Go,
+            Some(&FrameworkId::Gin),
+            &strings(&["github.com/jackc/pgx/v5", "google.golang.org/grpc"]),
+            &[backing("postgresql")],
+            true,
+        );
+        assert_eq!(hints.min_memory_mb, 96);
+        assert_eq!(hints.recommended_memory_mb, 192);
+        assert_eq!(hints.cpu_millicores, 200);
+        assert_eq!(hints.notes.len(), 2);
+        assert!(hints.notes[0].starts_with("postgresql client"));
+        assert!(hints.notes[1].starts_with("gRPC"));
+    }
+
+    #[test]
+    fn test_spring_boot_service() {
+        let hints = ResourceEstimator::estimate(
+            &LanguageId::Java,
+            Some(&FrameworkId::SpringBoot),
+            &[],
+            &[backing("redis")],
+            false,
+        );
+        assert_eq!(hints.min_memory_mb, 256 + 256 + 16);
+        assert_eq!(hints.recommended_memory_mb, 512 + 256 + 16);
+        assert_eq!(hints.cpu_millicores, 500);
+    }
+
+    #[test]
+    fn test_machine_learning_profile() {
+        let hints = ResourceEstimator::estimate(
+            &LanguageId::Python,
+            Some(&FrameworkId::FastApi),
+            &strings(&["fastapi", "torch", "numpy"]),
+            &[],
+            false,
+        );
+        assert_eq!(hints.min_memory_mb, 2048);
+        assert_eq!(hints.recommended_memory_mb, 4096);
+        assert_eq!(hints.cpu_millicores, 1000);
+        assert!(hints.gpu);
+        assert!(hints.notes[0].starts_with("torch"));
+        assert_eq!(hints.estimation_confidence, "low");
+    }
+
+    #[test]
+    fn test_similar_names_are_not_ml_libraries() {
+        let hints = ResourceEstimator::estimate(
+            &LanguageId::JavaScript,
+            None,
+            &strings(&["torchlight", "jaxon"]),
+            &[],
+            false,
+        );
+        assert!(!hints.gpu);
+        assert_eq!(hints.recommended_memory_mb, 256);
+    }
+}
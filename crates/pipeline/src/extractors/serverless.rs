@@ -0,0 +1,376 @@
+//! Serverless detector - function-as-a-service deployment configs
+
+use peelbox_core::fs::FileSystem;
+use serde_yaml::Value;
+use std::path::{Component, Path, PathBuf};
+
+const SERVERLESS_FRAMEWORK_CONFIGS: [&str; 3] =
+    ["serverless.yml", "serverless.yaml", "serverless.ts"];
+const SAM_TEMPLATES: [&str; 2] = ["template.yaml", "template.yml"];
+const CLOUD_FUNCTIONS_CONFIG: &str = "functions.yaml";
+const APP_ENGINE_CONFIGS: [&str; 2] = ["app.yaml", "app.yml"];
+
+const SAM_DEPLOY_COMMAND: &str = "sam build && sam deploy";
+
+/// How a service is deployed as serverless functions
+#[derive(Debug, Clone, PartialEq)]
+pub struct ServerlessDeployment {
+    /// aws-lambda, gcp-cloud-functions, gcp-app-engine or azure-functions
+    pub platform: String,
+    /// Config file relative to the repository
+    pub config: String,
+    /// Run from the directory holding the config
+    pub deploy_command: String,
+    /// Handler of the SAM function whose CodeUri is the service directory
+    pub handler: Option<String>,
+}
+
+pub struct ServerlessDetector;
+
+impl ServerlessDetector {
+    /// Serverless config at the top of the service directory, or a SAM template elsewhere in
+    /// the repository with a function built from the service directory
+    ///
+    /// Within the service directory the Serverless Framework wins over SAM, SAM over Cloud
+    /// Functions and Cloud Functions over App Engine.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<ServerlessDeployment> {
+        let top_level: Vec<&Path> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| path.components().count() == 1)
+            .collect();
+        let find = |names: &[&'static str]| {
+            names
+                .iter()
+                .copied()
+                .find(|name| top_level.iter().any(|path| *path == Path::new(name)))
+        };
+        let read = |relative: &Path| fs.read_to_string(&repo_path.join(relative)).ok();
+        let config_path = |name: &str| service_path.join(name).display().to_string();
+
+        if let Some(name) = find(&SERVERLESS_FRAMEWORK_CONFIGS) {
+            let content = read(&service_path.join(name)).unwrap_or_default();
+            return Some(ServerlessDeployment {
+                platform: serverless_framework_platform(name, &content),
+                config: config_path(name),
+                deploy_command: "serverless deploy".to_string(),
+                handler: None,
+            });
+        }
+
+        if let Some(name) = find(&SAM_TEMPLATES) {
+            if let Some(functions) = read(&service_path.join(name))
+                .as_deref()
+                .and_then(sam_functions)
+            {
+                return Some(ServerlessDeployment {
+                    platform: "aws-lambda".to_string(),
+                    config: config_path(name),
+                    deploy_command: SAM_DEPLOY_COMMAND.to_string(),
+                    handler: handler_for(&functions, service_path, service_path),
+                });
+            }
+        }
+
+        if find(&[CLOUD_FUNCTIONS_CONFIG]).is_some() {
+            return Some(ServerlessDeployment {
+                platform: "gcp-cloud-functions".to_string(),
+                config: config_path(CLOUD_FUNCTIONS_CONFIG),
+                deploy_command: "gcloud functions deploy".to_string(),
+                handler: None,
+            });
+        }
+
+        if let Some(name) = find(&APP_ENGINE_CONFIGS) {
+            let is_app_engine = read(&service_path.join(name))
+                .and_then(|content| serde_yaml::from_str::<Value>(&content).ok())
+                .is_some_and(|config| config.get("runtime").is_some());
+            if is_app_engine {
+                return Some(ServerlessDeployment {
+                    platform: "gcp-app-engine".to_string(),
+                    config: config_path(name),
+                    deploy_command: "gcloud app deploy".to_string(),
+                    handler: None,
+                });
+            }
+        }
+
+        // A SAM template at the repository root usually points CodeUri at the function's
+        // source directory, which is where the Go (or other) service was detected
+        file_tree
+            .iter()
+            .filter(|path| {
+                path.file_name()
+                    .and_then(|n| n.to_str())
+                    .is_some_and(|n| SAM_TEMPLATES.contains(&n))
+            })
+            .find_map(|template| {
+                let functions = sam_functions(&read(template.as_path())?)?;
+                let template_dir = template.parent().unwrap_or(Path::new(""));
+                let handler = handler_for(&functions, template_dir, service_path)?;
+                Some(ServerlessDeployment {
+                    platform: "aws-lambda".to_string(),
+                    config: template.display().to_string(),
+                    deploy_command: SAM_DEPLOY_COMMAND.to_string(),
+                    handler: Some(handler),
+                })
+            })
+    }
+}
+
+/// Maps the Serverless Framework `provider.name` to a platform; AWS is the default provider
+fn serverless_framework_platform(name: &str, content: &str) -> String {
+    let provider = if name.ends_with(".ts") {
+        typescript_provider_name(content)
+    } else {
+        serde_yaml::from_str::<Value>(content)
+            .ok()
+            .and_then(|config| {
+                let provider = config.get("provider")?;
+                provider
+                    .get("name")
+                    .unwrap_or(provider)
+                    .as_str()
+                    .map(str::to_string)
+            })
+    };
+
+    match provider.as_deref() {
+        None | Some("aws") => "aws-lambda".to_string(),
+        Some("google") => "gcp-cloud-functions".to_string(),
+        Some("azure") => "azure-functions".to_string(),
+        Some(other) => other.to_string(),
+    }
+}
+
+/// First `name: '...'` after `provider` in a serverless.ts config
+fn typescript_provider_name(content: &str) -> Option<String> {
+    let provider = &content[content.find("provider")?..];
+    let value = provider[provider.find("name:")? + "name:".len()..].trim_start();
+    let quote = value
+        .chars()
+        .next()
+        .filter(|c| matches!(c, '\'' | '"' | '`'))?;
+    let value = &value[1..];
+    Some(value[..value.find(quote)?].to_string())
+}
+
+/// CodeUri and Handler of every `AWS::Serverless::Function`, with `Globals.Function` as
+/// the fallback; `None` unless the document is a CloudFormation template
+fn sam_functions(content: &str) -> Option<Vec<(String, Option<String>)>> {
+    let template: Value = serde_yaml::from_str(content).ok()?;
+    template.get("AWSTemplateFormatVersion")?;
+
+    let globals = template.get("Globals").and_then(|g| g.get("Function"));
+    let property = |properties: Option<&Value>, key: &str| {
+        properties
+            .and_then(|p| p.get(key))
+            .or_else(|| globals.and_then(|g| g.get(key)))
+            .and_then(Value::as_str)
+            .map(str::to_string)
+    };
+
+    let resources = template.get("Resources")?.as_mapping()?;
+    Some(
+        resources
+            .values()
+            .filter(|resource| {
+                resource.get("Type").and_then(Value::as_str) == Some("AWS::Serverless::Function")
+            })
+            .filter_map(|resource| {
+                let properties = resource.get("Properties");
+                let code_uri = property(properties, "CodeUri")?;
+                Some((code_uri, property(properties, "Handler")))
+            })
+            .collect(),
+    )
+}
+
+/// Handler of the function whose CodeUri, relative to `template_dir`, is `service_path`
+///
+/// A function without a Handler (container images, custom runtimes named by convention)
+/// still links with an empty handler name.
+fn handler_for(
+    functions: &[(String, Option<String>)],
+    template_dir: &Path,
+    service_path: &Path,
+) -> Option<String> {
+    functions
+        .iter()
+        .find(|(code_uri, _)| {
+            !code_uri.contains("://")
+                && resolve(template_dir, code_uri).as_deref() == Some(service_path)
+        })
+        .map(|(_, handler)| handler.clone().unwrap_or_default())
+}
+
+fn resolve(base: &Path, relative: &str) -> Option<PathBuf> {
+    let mut resolved = base.to_path_buf();
+    for component in Path::new(relative).components() {
+        match component {
+            Component::CurDir => {}
+            Component::ParentDir => {
+                if !resolved.pop() {
+                    return None;
+                }
+            }
+            Component::Normal(part) => resolved.push(part),
+            Component::RootDir | Component::Prefix(_) => return None,
+        }
+    }
+    Some(resolved)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const SAM_TEMPLATE: &str = r#"AWSTemplateFormatVersion: '2010-09-09'
+Transform: AWS::Serverless-2016-10-31
+
+Globals:
+  Function:
+    Timeout: 5
+
+Resources:
+  HelloWorldFunction:
+    Type: AWS::Serverless::Function
+    Metadata:
+      BuildMethod: go1.x
+    Properties:
+      CodeUri: hello-world/
+      Handler: bootstrap
+      Runtime: provided.al2023
+      Events:
+        HelloWorld:
+          Type: Api
+          Properties:
+            Path: /hello
+            Method: GET
+Outputs:
+  HelloWorldApi:
+    Value: !Sub "https://${ServerlessRestApi}.execute-api.${AWS::Region}.amazonaws.com/Prod/hello/"
+"#;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    #[test]
+    fn test_serverless_framework() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "serverless.yml",
+            "service: orders\nprovider:\n  name: aws\n  runtime: nodejs20.x\nfunctions:\n  hello:\n    handler: handler.hello\n",
+        );
+        let deployment = ServerlessDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["package.json", "serverless.yml", "handler.js"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(
+            deployment,
+            ServerlessDeployment {
+                platform: "aws-lambda".to_string(),
+                config: "serverless.yml".to_string(),
+                deploy_command: "serverless deploy".to_string(),
+                handler: None,
+            }
+        );
+    }
+
+    #[test]
+    fn test_serverless_framework_providers() {
+        assert_eq!(
+            serverless_framework_platform("serverless.yml", "provider:\n  name: google\n"),
+            "gcp-cloud-functions"
+        );
+        assert_eq!(
+            serverless_framework_platform("serverless.yml", "provider: azure\n"),
+            "azure-functions"
+        );
+        assert_eq!(
+            serverless_framework_platform(
+                "serverless.ts",
+                "const config: AWS = {\n  service: 'orders',\n  provider: {\n    name: 'aws',\n  },\n};\n"
+            ),
+            "aws-lambda"
+        );
+    }
+
+    #[test]
+    fn test_sam_template_links_go_service() {
+        let fs = MockFileSystem::new();
+        fs.add_file("template.yaml", SAM_TEMPLATE);
+        let deployment = ServerlessDetector::detect(
+            Path::new(""),
+            Path::new("hello-world"),
+            &paths(&["template.yaml", "hello-world/go.mod", "hello-world/main.go"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(deployment.platform, "aws-lambda");
+        assert_eq!(deployment.config, "template.yaml");
+        assert_eq!(deployment.deploy_command, "sam build && sam deploy");
+        assert_eq!(deployment.handler.as_deref(), Some("bootstrap"));
+
+        // A service the template does not build stays undetected
+        assert_eq!(
+            ServerlessDetector::detect(
+                Path::new(""),
+                Path::new("worker"),
+                &paths(&["template.yaml", "worker/go.mod"]),
+                &fs,
+            ),
+            None
+        );
+    }
+
+    #[test]
+    fn test_template_without_cloudformation_version() {
+        let fs = MockFileSystem::new();
+        fs.add_file("template.yaml", "name: issue\nbody: []\n");
+        assert_eq!(
+            ServerlessDetector::detect(
+                Path::new(""),
+                Path::new(""),
+                &paths(&["template.yaml", "go.mod"]),
+                &fs,
+            ),
+            None
+        );
+    }
+
+    #[test]
+    fn test_google_configs() {
+        let fs = MockFileSystem::new();
+        fs.add_file("app.yaml", "runtime: go122\n");
+        let deployment = ServerlessDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["app.yaml", "go.mod"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(deployment.platform, "gcp-app-engine");
+        assert_eq!(deployment.deploy_command, "gcloud app deploy");
+
+        let deployment = ServerlessDetector::detect(
+            Path::new(""),
+            Path::new("fn"),
+            &paths(&["fn/functions.yaml", "fn/function.go"]),
+            &fs,
+        )
+        .unwrap();
+        assert_eq!(deployment.platform, "gcp-cloud-functions");
+        assert_eq!(deployment.config, "fn/functions.yaml");
+    }
+}
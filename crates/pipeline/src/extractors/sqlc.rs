@@ -0,0 +1,284 @@
+//! sqlc detector - type-safe Go generated from SQL queries by sqlc
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::{RequiredTool, SqlcMetadata, SqlcPackage};
+use serde_yaml::Value;
+use std::path::{Path, PathBuf};
+
+const CONFIG_FILES: [&str; 3] = ["sqlc.yaml", "sqlc.yml", "sqlc.json"];
+
+/// Regenerates every package of the config in the working directory
+pub const SQLC_GENERATE: &str = "sqlc generate";
+
+/// sqlc's default engine in version 1 configs
+const DEFAULT_ENGINE: &str = "postgresql";
+
+pub struct SqlcDetector;
+
+impl SqlcDetector {
+    /// Parses the sqlc config at the service root among `file_tree` (repository-relative)
+    ///
+    /// Both config versions are read: version 1 `packages` and version 2 `sql` entries with a
+    /// `gen.go` target. Each output directory is checked for generated files and for Go files
+    /// of the service importing it, by the module path of the service's go.mod.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+    ) -> Option<SqlcMetadata> {
+        let files: Vec<&Path> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .collect();
+        let read = |relative: &Path| {
+            fs.read_to_string(&repo_path.join(service_path).join(relative))
+                .ok()
+        };
+
+        let config_file = CONFIG_FILES
+            .iter()
+            .find(|name| files.contains(&Path::new(name)))?;
+        let config: Value = serde_yaml::from_str(&read(Path::new(config_file))?).ok()?;
+
+        let module = read(Path::new("go.mod")).and_then(|go_mod| module_path(&go_mod));
+        let go_sources: Vec<(&Path, String)> = files
+            .iter()
+            .filter(|path| path.extension().is_some_and(|ext| ext == "go"))
+            .filter_map(|path| read(path).map(|content| (*path, content)))
+            .collect();
+
+        let mut packages = targets(&config);
+        for package in &mut packages {
+            let out_dir = match package.out.as_str() {
+                "." => Path::new(""),
+                out => Path::new(out),
+            };
+            let import = module.as_ref().map(|module| match package.out.as_str() {
+                "." => format!("\"{}\"", module),
+                out => format!("\"{}/{}\"", module, out),
+            });
+            // sqlc always writes db.go, and a <queries>.sql.go per query file
+            package.generated = files.iter().any(|path| {
+                path.parent() == Some(out_dir)
+                    && path
+                        .file_name()
+                        .and_then(|name| name.to_str())
+                        .is_some_and(|name| name == "db.go" || name.ends_with(".sql.go"))
+            });
+            package.imported = import.is_some_and(|import| {
+                go_sources.iter().any(|(path, content)| {
+                    path.parent() != Some(out_dir) && content.contains(&import)
+                })
+            });
+        }
+        if packages.is_empty() {
+            return None;
+        }
+
+        Some(SqlcMetadata {
+            config_file: config_file.to_string(),
+            packages,
+        })
+    }
+
+    pub fn required_tool() -> RequiredTool {
+        RequiredTool {
+            name: "sqlc".to_string(),
+            version_hint: None,
+            install_command: Some(
+                "go install github.com/sqlc-dev/sqlc/cmd/sqlc@latest".to_string(),
+            ),
+        }
+    }
+}
+
+/// Every Go target of the config, not yet checked against the file tree
+fn targets(config: &Value) -> Vec<SqlcPackage> {
+    let package = |entry: &Value, name: Option<&str>, out: &str| SqlcPackage {
+        engine: entry["engine"]
+            .as_str()
+            .unwrap_or(DEFAULT_ENGINE)
+            .to_lowercase(),
+        queries: paths(&entry["queries"]),
+        schema: paths(&entry["schema"]),
+        package: name
+            .map(str::to_string)
+            .unwrap_or_else(|| last_element(out)),
+        out: normalize(out),
+        generated: false,
+        imported: false,
+    };
+
+    if config["version"].as_str() == Some("1") || config["packages"].is_sequence() {
+        return config["packages"]
+            .as_sequence()
+            .into_iter()
+            .flatten()
+            .filter_map(|entry| {
+                let out = entry["path"].as_str()?;
+                Some(package(entry, entry["name"].as_str(), out))
+            })
+            .collect();
+    }
+
+    config["sql"]
+        .as_sequence()
+        .into_iter()
+        .flatten()
+        .filter_map(|entry| {
+            let go = &entry["gen"]["go"];
+            let out = go["out"].as_str()?;
+            Some(package(entry, go["package"].as_str(), out))
+        })
+        .collect()
+}
+
+/// `queries` and `schema` are a path or a list of paths, files or directories
+fn paths(value: &Value) -> Vec<String> {
+    match value {
+        Value::String(path) => vec![normalize(path)],
+        Value::Sequence(paths) => paths
+            .iter()
+            .filter_map(Value::as_str)
+            .map(normalize)
+            .collect(),
+        _ => vec![],
+    }
+}
+
+/// `./internal/db/` as `internal/db`, the service root as `.`
+fn normalize(path: &str) -> String {
+    let path = path.trim().trim_start_matches("./").trim_end_matches('/');
+    if path.is_empty() {
+        ".".to_string()
+    } else {
+        path.to_string()
+    }
+}
+
+fn last_element(path: &str) -> String {
+    normalize(path)
+        .rsplit('/')
+        .next()
+        .unwrap_or_default()
+        .to_string()
+}
+
+fn module_path(go_mod: &str) -> Option<String> {
+    go_mod.lines().find_map(|line| {
+        let line = line.split("//").next().unwrap_or_default().trim();
+        line.strip_prefix("module ")
+            .map(|module| module.trim().trim_matches('"').to_string())
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const GO_MOD: &str = "module example.com/bookstore\n\ngo 1.22\n";
+
+    const CONFIG_V2: &str = r#"version: "2"
+sql:
+  - engine: "postgresql"
+    queries: "db/query/"
+    schema: "db/migrations/"
+    gen:
+      go:
+        package: "store"
+        out: "./internal/store"
+        sql_package: "pgx/v5"
+  - engine: "sqlite"
+    queries: ["cache/query.sql"]
+    schema: ["cache/schema.sql"]
+    codegen:
+      - plugin: py
+        out: gen/py
+"#;
+
+    const MAIN_GO: &str = "package main\n\nimport (\n\t\"context\"\n\n\t\"example.com/bookstore/internal/store\"\n)\n\nfunc main() {\n\t_ = store.New\n\t_ = context.Background\n}\n";
+
+    fn detect(files: &[(&str, &str)], service_path: &str) -> Option<SqlcMetadata> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        SqlcDetector::detect(Path::new(""), Path::new(service_path), &tree, &fs)
+    }
+
+    #[test]
+    fn test_version_2_config() {
+        let sqlc = detect(
+            &[
+                ("sqlc.yaml", CONFIG_V2),
+                ("go.mod", GO_MOD),
+                ("main.go", MAIN_GO),
+                ("internal/store/db.go", "package store\n"),
+                ("internal/store/query.sql.go", "package store\n"),
+            ],
+            "",
+        )
+        .unwrap();
+
+        assert_eq!(
+            sqlc,
+            SqlcMetadata {
+                config_file: "sqlc.yaml".to_string(),
+                packages: vec![SqlcPackage {
+                    engine: "postgresql".to_string(),
+                    queries: vec!["db/query".to_string()],
+                    schema: vec!["db/migrations".to_string()],
+                    package: "store".to_string(),
+                    out: "internal/store".to_string(),
+                    generated: true,
+                    imported: true,
+                }],
+            }
+        );
+    }
+
+    #[test]
+    fn test_version_1_config_before_generation() {
+        let sqlc = detect(
+            &[
+                (
+                    "api/sqlc.yml",
+                    "version: \"1\"\npackages:\n  - path: internal/db\n    queries: ./sql/query.sql\n    schema: ./sql/schema.sql\n    engine: MySQL\n",
+                ),
+                ("api/go.mod", "module example.com/api\n"),
+                ("api/main.go", "package main\n\nfunc main() {}\n"),
+            ],
+            "api",
+        )
+        .unwrap();
+
+        let package = &sqlc.packages[0];
+        assert_eq!(sqlc.config_file, "sqlc.yml");
+        assert_eq!(package.engine, "mysql");
+        assert_eq!(package.package, "db");
+        assert_eq!(package.queries, vec!["sql/query.sql"]);
+        assert!(!package.generated);
+        assert!(!package.imported);
+    }
+
+    #[test]
+    fn test_no_sqlc_config() {
+        assert_eq!(
+            detect(&[("go.mod", GO_MOD), ("main.go", MAIN_GO)], ""),
+            None
+        );
+        assert_eq!(
+            detect(
+                &[
+                    ("sqlc.yaml", "version: \"2\"\nplugins: []\n"),
+                    ("go.mod", GO_MOD)
+                ],
+                ""
+            ),
+            None
+        );
+    }
+}
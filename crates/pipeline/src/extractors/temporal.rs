@@ -0,0 +1,209 @@
+//! Temporal detector - the workflow SDK and whether a service runs workers, starts workflows
+//! or both
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::RequiredTool;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+/// Go SDK module; its packages (`go.temporal.io/sdk/worker`, `.../client`) share the prefix
+pub const GO_SDK: &str = "go.temporal.io/sdk";
+/// Python SDK distribution
+pub const PYTHON_SDK: &str = "temporalio";
+
+/// What a Temporal service does with workflows
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ServiceRole {
+    /// Polls task queues and runs workflow and activity code
+    Worker,
+    /// Starts workflows for workers elsewhere to run
+    Client,
+    Both,
+}
+
+impl ServiceRole {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            ServiceRole::Worker => "worker",
+            ServiceRole::Client => "client",
+            ServiceRole::Both => "both",
+        }
+    }
+}
+
+/// A service built on a Temporal SDK
+#[derive(Debug, Clone, PartialEq)]
+pub struct TemporalUsage {
+    /// `None` when the source neither creates a worker nor starts a workflow
+    pub role: Option<ServiceRole>,
+}
+
+impl TemporalUsage {
+    pub const WORKFLOW_ENGINE: &'static str = "temporal";
+
+    /// tctl administers the namespaces and task queues workers poll
+    pub fn required_tool() -> RequiredTool {
+        RequiredTool {
+            name: "tctl".to_string(),
+            version_hint: None,
+            install_command: Some(
+                "go install github.com/temporalio/tctl/cmd/tctl@latest".to_string(),
+            ),
+        }
+    }
+}
+
+pub struct TemporalDetector;
+
+impl TemporalDetector {
+    /// Detects a Temporal SDK among `dependencies` (go.mod modules or Python requirements) and
+    /// scans the service's Go and Python files among `file_tree` (repository-relative) for
+    /// workers and workflow starts
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        dependencies: &[String],
+    ) -> Option<TemporalUsage> {
+        let uses_sdk = dependencies.iter().any(|dependency| {
+            dependency == GO_SDK
+                || dependency.starts_with(&format!("{}/", GO_SDK))
+                || dependency.eq_ignore_ascii_case(PYTHON_SDK)
+        });
+        if !uses_sdk {
+            return None;
+        }
+
+        // worker.New(c, "orders", worker.Options{}) and Worker(client, task_queue=..., ...)
+        let worker_re = Regex::new(r"\bworker\.New\(|\bWorker\(").expect("valid worker regex");
+        // c.ExecuteWorkflow(ctx, options, OrderWorkflow) and client.start_workflow(...)
+        let client_re = Regex::new(
+            r"\.(?:ExecuteWorkflow|SignalWithStartWorkflow|execute_workflow|start_workflow)\(",
+        )
+        .expect("valid client regex");
+
+        let (mut worker, mut client) = (false, false);
+        for path in file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .filter(|path| {
+                path.file_name()
+                    .and_then(|n| n.to_str())
+                    .is_some_and(is_source)
+            })
+        {
+            let Ok(content) = fs.read_to_string(&repo_path.join(service_path).join(path)) else {
+                continue;
+            };
+            worker |= worker_re.is_match(&content);
+            client |= client_re.is_match(&content);
+        }
+
+        let role = match (worker, client) {
+            (true, true) => Some(ServiceRole::Both),
+            (true, false) => Some(ServiceRole::Worker),
+            (false, true) => Some(ServiceRole::Client),
+            (false, false) => None,
+        };
+        Some(TemporalUsage { role })
+    }
+}
+
+/// Go and Python sources, leaving out tests, which start workflows against test servers
+fn is_source(name: &str) -> bool {
+    (name.ends_with(".go") && !name.ends_with("_test.go"))
+        || (name.ends_with(".py") && !name.starts_with("test_") && !name.ends_with("_test.py"))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    const WORKER: &str = r#"package main
+
+import (
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+func main() {
+	c, _ := client.Dial(client.Options{})
+	w := worker.New(c, "orders", worker.Options{})
+	w.RegisterWorkflow(OrderWorkflow)
+	_ = w.Run(worker.InterruptCh())
+}
+"#;
+
+    const STARTER: &str = r#"package main
+
+func start(c client.Client) {
+	_, _ = c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{TaskQueue: "orders"}, "OrderWorkflow")
+}
+"#;
+
+    fn detect(files: &[(&str, &str)], dependencies: &[&str]) -> Option<TemporalUsage> {
+        let fs = MockFileSystem::new();
+        for (path, content) in files {
+            fs.add_file(path, content);
+        }
+        let tree: Vec<PathBuf> = files.iter().map(|(path, _)| PathBuf::from(path)).collect();
+        let dependencies: Vec<String> = dependencies.iter().map(|d| d.to_string()).collect();
+        TemporalDetector::detect(Path::new(""), Path::new("svc"), &tree, &fs, &dependencies)
+    }
+
+    #[test]
+    fn test_go_service_roles() {
+        let worker = detect(&[("svc/main.go", WORKER)], &[GO_SDK]).unwrap();
+        assert_eq!(worker.role, Some(ServiceRole::Worker));
+
+        let client = detect(&[("svc/start.go", STARTER)], &[GO_SDK]).unwrap();
+        assert_eq!(client.role, Some(ServiceRole::Client));
+
+        let both = detect(
+            &[("svc/main.go", WORKER), ("svc/api/start.go", STARTER)],
+            &[GO_SDK],
+        )
+        .unwrap();
+        assert_eq!(both.role.map(ServiceRole::as_str), Some("both"));
+    }
+
+    #[test]
+    fn test_python_worker() {
+        let usage = detect(
+            &[
+                (
+                    "svc/worker.py",
+                    "worker = Worker(client, task_queue=\"orders\", workflows=[OrderWorkflow])\n",
+                ),
+                (
+                    "svc/test_orders.py",
+                    "await client.execute_workflow(OrderWorkflow.run, id=\"o-1\", task_queue=\"orders\")\n",
+                ),
+            ],
+            &["fastapi", "temporalio"],
+        )
+        .unwrap();
+        assert_eq!(usage.role, Some(ServiceRole::Worker));
+    }
+
+    #[test]
+    fn test_requires_sdk_dependency() {
+        assert_eq!(
+            detect(&[("svc/main.go", WORKER)], &["go.temporal.io/api"]),
+            None
+        );
+        assert_eq!(
+            detect(&[("svc/main.go", "package main\n")], &[GO_SDK]),
+            Some(TemporalUsage { role: None })
+        );
+        // Files of another service are not scanned
+        assert_eq!(
+            detect(&[("other/main.go", WORKER)], &[GO_SDK])
+                .unwrap()
+                .role,
+            None
+        );
+    }
+}
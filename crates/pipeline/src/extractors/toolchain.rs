@@ -0,0 +1,178 @@
+//! Toolchain detector - go.mod `go`, `toolchain` and `godebug` directives
+
+use std::cmp::Ordering;
+
+/// Language version, minimum toolchain and debug settings a go.mod declares
+#[derive(Debug, Clone, PartialEq, Default)]
+pub struct GoToolchain {
+    /// Version of the `go` directive, e.g. `1.22` or `1.22.1`
+    pub module_version: Option<String>,
+    /// Version of the `toolchain` directive without the `go` prefix; unset for `default`
+    pub toolchain_version: Option<String>,
+    /// `key=value` settings of single-line and block `godebug` directives, in go.mod order
+    pub godebug: Vec<String>,
+}
+
+impl GoToolchain {
+    /// False when the toolchain is older than the `go` directive, which the go command rejects
+    pub fn consistent(&self) -> bool {
+        match (&self.module_version, &self.toolchain_version) {
+            (Some(module), Some(toolchain)) => {
+                compare_versions(toolchain, module) != Some(Ordering::Less)
+            }
+            _ => true,
+        }
+    }
+}
+
+pub struct ToolchainDetector;
+
+impl ToolchainDetector {
+    /// Parses the directives of a go.mod file; `None` when it declares none of them
+    pub fn detect(go_mod: &str) -> Option<GoToolchain> {
+        let mut toolchain = GoToolchain::default();
+        let mut in_godebug = false;
+
+        for line in go_mod.lines() {
+            let line = line.split("//").next().unwrap_or_default().trim();
+            if in_godebug {
+                if line == ")" {
+                    in_godebug = false;
+                } else if let Some(setting) = godebug_setting(line) {
+                    toolchain.godebug.push(setting);
+                }
+                continue;
+            }
+
+            let (directive, rest) = line.split_once(char::is_whitespace).unwrap_or((line, ""));
+            let rest = rest.trim();
+            match directive {
+                "go" if !rest.is_empty() => toolchain.module_version = Some(rest.to_string()),
+                "toolchain" => {
+                    toolchain.toolchain_version = rest
+                        .strip_prefix("go")
+                        .map(|version| version.split('+').next().unwrap_or(version).to_string())
+                }
+                "godebug" if rest == "(" => in_godebug = true,
+                "godebug" => toolchain.godebug.extend(godebug_setting(rest)),
+                _ => {}
+            }
+        }
+
+        (toolchain != GoToolchain::default()).then_some(toolchain)
+    }
+}
+
+fn godebug_setting(line: &str) -> Option<String> {
+    let (key, value) = line.split_once('=')?;
+    let (key, value) = (key.trim(), value.trim());
+    (!key.is_empty() && !value.is_empty()).then(|| format!("{}={}", key, value))
+}
+
+/// Orders Go versions the way the go command does: `1.21` < `1.21rc1` < `1.21.0` < `1.21.1`
+///
+/// `None` when either side is not a Go version.
+fn compare_versions(a: &str, b: &str) -> Option<Ordering> {
+    Some(version_key(a)?.cmp(&version_key(b)?))
+}
+
+/// Major, minor, release stage (language version, beta, rc, release) and patch or
+/// prerelease number
+fn version_key(version: &str) -> Option<(u32, u32, u8, u32)> {
+    let (major, rest) = version.split_once('.')?;
+    let major = major.parse().ok()?;
+    let digits = rest
+        .find(|c: char| !c.is_ascii_digit())
+        .unwrap_or(rest.len());
+    let minor = rest[..digits].parse().ok()?;
+    let suffix = &rest[digits..];
+
+    let (stage, number) = if suffix.is_empty() {
+        (0, 0)
+    } else if let Some(n) = suffix.strip_prefix("beta") {
+        (1, n.parse().ok()?)
+    } else if let Some(n) = suffix.strip_prefix("rc") {
+        (2, n.parse().ok()?)
+    } else {
+        (3, suffix.strip_prefix('.')?.parse().ok()?)
+    };
+    Some((major, minor, stage, number))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_go_directive_only() {
+        let toolchain = ToolchainDetector::detect(
+            "module example.com/app\n\ngo 1.22\n\nrequire github.com/gin-gonic/gin v1.9.1\n",
+        )
+        .unwrap();
+        assert_eq!(toolchain.module_version.as_deref(), Some("1.22"));
+        assert_eq!(toolchain.toolchain_version, None);
+        assert!(toolchain.consistent());
+    }
+
+    #[test]
+    fn test_newer_toolchain_is_consistent() {
+        let toolchain = ToolchainDetector::detect(
+            "module example.com/app\n\ngo 1.21.0\n\ntoolchain go1.22.3\n",
+        )
+        .unwrap();
+        assert_eq!(toolchain.module_version.as_deref(), Some("1.21.0"));
+        assert_eq!(toolchain.toolchain_version.as_deref(), Some("1.22.3"));
+        assert!(toolchain.consistent());
+
+        let same_release = ToolchainDetector::detect("go 1.22\ntoolchain go1.22.0\n").unwrap();
+        assert!(same_release.consistent());
+    }
+
+    #[test]
+    fn test_older_toolchain_is_inconsistent() {
+        let toolchain =
+            ToolchainDetector::detect("module example.com/app\n\ngo 1.22.1\ntoolchain go1.22.0\n")
+                .unwrap();
+        assert!(!toolchain.consistent());
+
+        let prerelease = ToolchainDetector::detect("go 1.23.0\ntoolchain go1.23rc2\n").unwrap();
+        assert!(!prerelease.consistent());
+    }
+
+    #[test]
+    fn test_default_toolchain() {
+        let toolchain = ToolchainDetector::detect("go 1.22\ntoolchain default\n").unwrap();
+        assert_eq!(toolchain.toolchain_version, None);
+        assert!(toolchain.consistent());
+    }
+
+    #[test]
+    fn test_godebug_settings() {
+        let toolchain = ToolchainDetector::detect(
+            "module example.com/app\n\ngo 1.23\n\ngodebug default=go1.21\n\ngodebug (\n\tpanicnil=1 // keep recover() returning nil\n\thttp2client=0\n)\n",
+        )
+        .unwrap();
+        assert_eq!(
+            toolchain.godebug,
+            vec!["default=go1.21", "panicnil=1", "http2client=0"]
+        );
+    }
+
+    #[test]
+    fn test_no_directives() {
+        assert_eq!(ToolchainDetector::detect("module example.com/app\n"), None);
+    }
+
+    #[test]
+    fn test_version_order() {
+        assert_eq!(compare_versions("1.21", "1.21rc1"), Some(Ordering::Less));
+        assert_eq!(compare_versions("1.21rc1", "1.21.0"), Some(Ordering::Less));
+        assert_eq!(
+            compare_versions("1.21beta1", "1.21rc1"),
+            Some(Ordering::Less)
+        );
+        assert_eq!(compare_versions("1.9.2", "1.10"), Some(Ordering::Less));
+        assert_eq!(compare_versions("1.22.0", "1.22.0"), Some(Ordering::Equal));
+        assert_eq!(compare_versions("local", "1.22"), None);
+    }
+}
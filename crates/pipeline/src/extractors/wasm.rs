@@ -0,0 +1,165 @@
+//! WebAssembly detector - Go services compiled for the browser (`GOOS=js`) or WASI (`GOOS=wasip1`)
+
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::BuildConstraints;
+use std::path::{Path, PathBuf};
+
+/// Loader the Go distribution ships for running `GOOS=js` binaries in a browser
+const WASM_EXEC_JS: &str = "wasm_exec.js";
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum WasmTarget {
+    /// `GOOS=js GOARCH=wasm`, loaded by a page through `wasm_exec.js`
+    Browser,
+    /// `GOOS=wasip1 GOARCH=wasm`, run by a WASI runtime
+    Wasi,
+}
+
+impl WasmTarget {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::Browser => "browser",
+            Self::Wasi => "wasi",
+        }
+    }
+
+    pub fn build_command(self) -> &'static str {
+        match self {
+            Self::Browser => "GOOS=js GOARCH=wasm go build -o main.wasm .",
+            Self::Wasi => "GOOS=wasip1 GOARCH=wasm go build -o main.wasm .",
+        }
+    }
+}
+
+pub struct WasmDetector;
+
+impl WasmDetector {
+    /// Detects a WebAssembly build from a `wasm_exec.js` among the service's files in
+    /// `file_tree` (repository-relative), `GOOS=` assignments in its Makefile, and the `js` or
+    /// `wasip1` operating systems its build constraints target
+    ///
+    /// Browser signals win, since a project shipping `wasm_exec.js` serves its binary to pages.
+    pub fn detect<F: FileSystem + ?Sized>(
+        repo_path: &Path,
+        service_path: &Path,
+        file_tree: &[PathBuf],
+        fs: &F,
+        constraints: Option<&BuildConstraints>,
+    ) -> Option<WasmTarget> {
+        let files: Vec<&Path> = file_tree
+            .iter()
+            .filter_map(|path| path.strip_prefix(service_path).ok())
+            .collect();
+        let makefile = files
+            .contains(&Path::new("Makefile"))
+            .then(|| {
+                fs.read_to_string(&repo_path.join(service_path).join("Makefile"))
+                    .ok()
+            })
+            .flatten()
+            .unwrap_or_default();
+        let targets = |os: &str| {
+            makefile.contains(&format!("GOOS={}", os))
+                || constraints.is_some_and(|c| c.os.iter().any(|target| target == os))
+        };
+
+        let ships_loader = files
+            .iter()
+            .any(|path| path.file_name().is_some_and(|name| name == WASM_EXEC_JS));
+        if ships_loader || targets("js") {
+            Some(WasmTarget::Browser)
+        } else if targets("wasip1") {
+            Some(WasmTarget::Wasi)
+        } else {
+            None
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    fn paths(values: &[&str]) -> Vec<PathBuf> {
+        values.iter().map(PathBuf::from).collect()
+    }
+
+    fn targeting(os: &str) -> BuildConstraints {
+        BuildConstraints {
+            os: vec![os.to_string()],
+            arch: vec!["wasm".to_string()],
+            ..BuildConstraints::default()
+        }
+    }
+
+    #[test]
+    fn test_browser_from_wasm_exec_js() {
+        let fs = MockFileSystem::new();
+        let target = WasmDetector::detect(
+            Path::new(""),
+            Path::new("app"),
+            &paths(&["app/go.mod", "app/main.go", "app/web/wasm_exec.js"]),
+            &fs,
+            None,
+        );
+        assert_eq!(target, Some(WasmTarget::Browser));
+    }
+
+    #[test]
+    fn test_wasi_from_makefile() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "Makefile",
+            "build:\n\tGOOS=wasip1 GOARCH=wasm go build -o plugin.wasm .\n",
+        );
+        let target = WasmDetector::detect(
+            Path::new(""),
+            Path::new(""),
+            &paths(&["Makefile"]),
+            &fs,
+            None,
+        );
+        assert_eq!(target, Some(WasmTarget::Wasi));
+        assert_eq!(
+            target.unwrap().build_command(),
+            "GOOS=wasip1 GOARCH=wasm go build -o main.wasm ."
+        );
+    }
+
+    #[test]
+    fn test_target_from_build_constraints() {
+        let fs = MockFileSystem::new();
+        let tree = paths(&["main.go"]);
+        assert_eq!(
+            WasmDetector::detect(
+                Path::new(""),
+                Path::new(""),
+                &tree,
+                &fs,
+                Some(&targeting("js"))
+            ),
+            Some(WasmTarget::Browser)
+        );
+        assert_eq!(
+            WasmDetector::detect(
+                Path::new(""),
+                Path::new(""),
+                &tree,
+                &fs,
+                Some(&targeting("wasip1"))
+            ),
+            Some(WasmTarget::Wasi)
+        );
+        assert_eq!(
+            WasmDetector::detect(
+                Path::new(""),
+                Path::new(""),
+                &tree,
+                &fs,
+                Some(&targeting("linux"))
+            ),
+            None
+        );
+    }
+}
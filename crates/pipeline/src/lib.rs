@@ -5,6 +5,8 @@ pub mod validation;
 
 pub use detection::service::{DetectionService, ServiceError};
 pub use pipeline::context::AnalysisContext;
+pub use pipeline::events::DetectionEvent;
+pub use pipeline::logging::{LogLevel, ScanLogger};
 pub use pipeline::orchestrator::PipelineOrchestrator;
 pub use validation::Validator;
 pub use validation::WolfiPackageIndex;
@@ -1,4 +1,6 @@
+use peelbox_core::output::schema::FieldConfidence;
 use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
 
 #[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
 #[serde(rename_all = "lowercase")]
@@ -21,3 +23,103 @@ impl Confidence {
         self.to_f64() as f32
     }
 }
+
+/// A piece of evidence supporting a detected field, weighted by how much it alone proves.
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub enum Evidence {
+    /// The manifest the service was detected from; carries the detector's own score
+    Manifest(f64),
+    /// Pulled from config or code by an extractor; carries the extractor's own score
+    Extracted(f64),
+    /// Declared as a dependency in the manifest
+    Dependency,
+    /// Found in a single source file (an import, a listen call)
+    SourceFile,
+    /// Source files with the language's extensions
+    SourceExtension,
+    /// A framework-specific config file is present
+    ConfigFile,
+    /// Declared by deployment config, such as a Dockerfile EXPOSE
+    Declared,
+    /// A built-in default of the build system, framework or language
+    Default,
+    /// Last-resort guess with nothing detected
+    Fallback,
+}
+
+impl Evidence {
+    pub fn weight(self) -> f64 {
+        match self {
+            Evidence::Manifest(score) | Evidence::Extracted(score) => score.clamp(0.0, 1.0),
+            Evidence::Dependency => Confidence::High.to_f64(),
+            Evidence::SourceFile => 0.6,
+            Evidence::SourceExtension => 0.6,
+            Evidence::ConfigFile => 0.5,
+            Evidence::Declared => Confidence::Medium.to_f64(),
+            Evidence::Default => Confidence::Low.to_f64(),
+            Evidence::Fallback => 0.2,
+        }
+    }
+}
+
+/// Accumulates evidence per output field and turns it into a 0.0..=1.0 score.
+///
+/// Signals are treated as independent, so a field's score is `1 - Π(1 - weight)`: one
+/// signal scores its own weight, and corroborating signals push the score towards 1.0
+/// without ever exceeding it. Scores are rounded to two decimals for stable output.
+#[derive(Debug, Clone, Default)]
+pub struct ConfidenceTracker {
+    signals: BTreeMap<String, Vec<Evidence>>,
+}
+
+impl ConfidenceTracker {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    pub fn record(&mut self, field: &str, evidence: Evidence) {
+        self.signals
+            .entry(field.to_string())
+            .or_default()
+            .push(evidence);
+    }
+
+    pub fn score(&self, field: &str) -> Option<f64> {
+        let evidence = self.signals.get(field)?;
+        let doubt: f64 = evidence.iter().map(|e| 1.0 - e.weight()).product();
+        Some(((1.0 - doubt) * 100.0).round() / 100.0)
+    }
+
+    pub fn scores(&self) -> FieldConfidence {
+        self.signals
+            .keys()
+            .filter_map(|field| Some((field.clone(), self.score(field)?)))
+            .collect()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_single_signal_scores_its_weight() {
+        let mut tracker = ConfidenceTracker::new();
+        tracker.record("framework", Evidence::SourceFile);
+        assert_eq!(tracker.score("framework"), Some(0.6));
+        assert_eq!(tracker.score("port"), None);
+    }
+
+    #[test]
+    fn test_corroborating_signals_combine() {
+        let mut tracker = ConfidenceTracker::new();
+        tracker.record("language", Evidence::Manifest(1.0));
+        tracker.record("language", Evidence::SourceExtension);
+        tracker.record("framework", Evidence::Dependency);
+        tracker.record("framework", Evidence::SourceFile);
+
+        let scores = tracker.scores();
+        assert_eq!(scores.get("language"), Some(&1.0));
+        assert_eq!(scores.get("framework"), Some(&0.98));
+    }
+}
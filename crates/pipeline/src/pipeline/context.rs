@@ -1,12 +1,14 @@
+use super::events::{DetectionEvent, EventSender};
 use super::phases::{root_cache::RootCacheInfo, scan::ScanResult};
 use super::service_context::ServiceContext;
 use peelbox_core::config::DetectionMode;
+use peelbox_core::fs::{FileSystem, RealFileSystem};
 use peelbox_core::output::schema::UniversalBuild;
 use peelbox_stack::orchestrator::WorkspaceStructure;
 use peelbox_stack::StackRegistry;
 use peelbox_wolfi::WolfiPackageIndex;
 use std::path::{Path, PathBuf};
-use std::sync::Arc;
+use std::sync::{Arc, Mutex};
 
 #[derive(Clone)]
 pub struct AnalysisContext {
@@ -14,11 +16,18 @@ pub struct AnalysisContext {
     pub stack_registry: Arc<StackRegistry>,
     pub wolfi_index: Arc<WolfiPackageIndex>,
     pub detection_mode: DetectionMode,
+    /// Where every phase reads the repository's files; the local disk unless an archive or
+    /// remote tree was supplied
+    pub fs: Arc<dyn FileSystem>,
     pub scan: Option<ScanResult>,
     pub workspace: Option<WorkspaceStructure>,
     pub root_cache: Option<RootCacheInfo>,
     pub service_analyses: Vec<ServiceContext>,
     pub builds: Vec<UniversalBuild>,
+    /// Where detection events go while the pipeline runs, if anyone is listening
+    pub events: Option<EventSender>,
+    /// Every event emitted while set, kept so the detection cache can replay them
+    pub event_log: Option<Arc<Mutex<Vec<DetectionEvent>>>>,
 }
 
 impl AnalysisContext {
@@ -33,11 +42,33 @@ impl AnalysisContext {
             stack_registry,
             wolfi_index,
             detection_mode,
+            fs: Arc::new(RealFileSystem),
             scan: None,
             workspace: None,
             root_cache: None,
             service_analyses: Vec::new(),
             builds: Vec::new(),
+            events: None,
+            event_log: None,
+        }
+    }
+
+    /// Reads the repository through `fs` instead of the local disk
+    ///
+    /// A file system with a fixed listing is scanned from that listing, and its paths are
+    /// relative, so `repo_path` should then be empty.
+    pub fn with_file_system(mut self, fs: Arc<dyn FileSystem>) -> Self {
+        self.fs = fs;
+        self
+    }
+
+    pub fn emit(&self, event: DetectionEvent) {
+        if let Some(log) = &self.event_log {
+            log.lock().unwrap().push(event.clone());
+        }
+        if let Some(events) = &self.events {
+            // A dropped receiver only means nobody is listening anymore
+            let _ = events.send(event);
         }
     }
 }
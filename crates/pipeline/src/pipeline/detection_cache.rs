@@ -0,0 +1,361 @@
+//! On-disk cache of detection results, keyed by the paths and contents of the scanned files
+
+use super::events::DetectionEvent;
+use anyhow::{Context, Result};
+use ignore::WalkBuilder;
+use peelbox_core::config::DetectionMode;
+use peelbox_core::fs::{FileSystem, RealFileSystem};
+use peelbox_core::output::schema::UniversalBuild;
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::path::{Path, PathBuf};
+use tracing::debug;
+
+/// Bump whenever cached entries can no longer be trusted (schema or detection changes)
+const CACHE_FORMAT_VERSION: u32 = 2;
+
+#[derive(Debug, Serialize, Deserialize)]
+pub struct CacheEntry {
+    format_version: u32,
+    peelbox_version: String,
+    /// Events the run emitted before assembling, replayed ahead of the builds
+    pub events: Vec<DetectionEvent>,
+    pub builds: Vec<UniversalBuild>,
+}
+
+pub struct DetectionCache {
+    dir: PathBuf,
+}
+
+impl DetectionCache {
+    pub fn new(dir: impl Into<PathBuf>) -> Self {
+        Self { dir: dir.into() }
+    }
+
+    /// `sha256` over the cache and peelbox versions plus the [`hash_tree`] of the scanned
+    /// files, so a checkout keeps its entries wherever it is moved or copied
+    ///
+    /// Files are read through `fs`, the one the scan walked. `None` when one of them cannot be
+    /// read, since its content is unknown and the run should not be cached.
+    pub fn key(
+        repo_path: &Path,
+        file_tree: &[PathBuf],
+        mode: DetectionMode,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let tree = match hash_tree(fs, repo_path, file_tree) {
+            Ok(tree) => tree,
+            Err(e) => {
+                debug!(error = %e, "Skipping the detection cache");
+                return None;
+            }
+        };
+
+        let mut hasher = Sha256::new();
+        hasher.update(
+            format!(
+                "v{}:{}:{:?}\n{}",
+                CACHE_FORMAT_VERSION,
+                env!("CARGO_PKG_VERSION"),
+                mode,
+                tree
+            )
+            .as_bytes(),
+        );
+        Some(format!("{:x}", hasher.finalize()))
+    }
+
+    fn entry_path(&self, key: &str) -> PathBuf {
+        self.dir.join(format!("{}.json", key))
+    }
+
+    /// The cached run for `key`; unreadable or outdated entries count as misses
+    pub fn load(&self, key: &str) -> Option<CacheEntry> {
+        let path = self.entry_path(key);
+        let content = std::fs::read_to_string(&path).ok()?;
+        let entry: CacheEntry = match serde_json::from_str(&content) {
+            Ok(entry) => entry,
+            Err(e) => {
+                debug!(path = %path.display(), error = %e, "Ignoring unreadable cache entry");
+                return None;
+            }
+        };
+
+        if entry.format_version != CACHE_FORMAT_VERSION
+            || entry.peelbox_version != env!("CARGO_PKG_VERSION")
+        {
+            debug!(path = %path.display(), "Ignoring cache entry from another peelbox version");
+            return None;
+        }
+        Some(entry)
+    }
+
+    pub fn store(
+        &self,
+        key: &str,
+        events: &[DetectionEvent],
+        builds: &[UniversalBuild],
+    ) -> Result<()> {
+        std::fs::create_dir_all(&self.dir)
+            .with_context(|| format!("Failed to create cache directory {}", self.dir.display()))?;
+
+        let entry = CacheEntry {
+            format_version: CACHE_FORMAT_VERSION,
+            peelbox_version: env!("CARGO_PKG_VERSION").to_string(),
+            events: events.to_vec(),
+            builds: builds.to_vec(),
+        };
+        let path = self.entry_path(key);
+        let content = serde_json::to_string_pretty(&entry)?;
+        std::fs::write(&path, content)
+            .with_context(|| format!("Failed to write cache entry {}", path.display()))
+    }
+}
+
+/// `sha256` over the relative path and content of every file under `root`, sorted by path
+///
+/// Two directories holding the same files hash the same wherever they are, so the hash can
+/// stand in for a test fixture's path in cache keys and survive the fixture being moved.
+pub fn hash_fixture(root: &Path) -> Result<String> {
+    let mut files = Vec::new();
+    for entry in WalkBuilder::new(root).standard_filters(false).build() {
+        let entry = entry.with_context(|| format!("Failed to walk {}", root.display()))?;
+        if entry
+            .file_type()
+            .is_some_and(|file_type| file_type.is_file())
+        {
+            files.push(entry.path().strip_prefix(root)?.to_path_buf());
+        }
+    }
+    hash_tree(&RealFileSystem, root, &files)
+}
+
+/// `sha256` over each of `files`' `/`-separated path and content, read through `fs` and
+/// sorted by path; fails if any of them cannot be read
+fn hash_tree(fs: &dyn FileSystem, root: &Path, files: &[PathBuf]) -> Result<String> {
+    let mut files: Vec<(String, &PathBuf)> = files
+        .iter()
+        .map(|rel_path| {
+            let name = rel_path
+                .components()
+                .map(|component| component.as_os_str().to_string_lossy())
+                .collect::<Vec<_>>()
+                .join("/");
+            (name, rel_path)
+        })
+        .collect();
+    files.sort();
+
+    // Each path is prefixed by its length, as is each content, so neither can run into the next
+    let mut hasher = Sha256::new();
+    for (name, rel_path) in files {
+        let path = root.join(rel_path);
+        let content =
+            read_file(fs, &path).with_context(|| format!("Failed to read {}", path.display()))?;
+        for bytes in [name.as_bytes(), &content] {
+            hasher.update((bytes.len() as u64).to_le_bytes());
+            hasher.update(bytes);
+        }
+    }
+    Ok(format!("{:x}", hasher.finalize()))
+}
+
+fn read_file(fs: &dyn FileSystem, path: &Path) -> Result<Vec<u8>> {
+    let size = fs.metadata(path)?.len();
+    fs.read_bytes(path, usize::try_from(size)?)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+    use std::fs;
+    use std::time::{Duration, SystemTime};
+    use tempfile::TempDir;
+
+    fn touch(path: &Path, secs: u64) {
+        fs::File::options()
+            .write(true)
+            .open(path)
+            .unwrap()
+            .set_modified(SystemTime::UNIX_EPOCH + Duration::from_secs(secs))
+            .unwrap();
+    }
+
+    fn write_app(dir: &Path, main: &str) {
+        fs::create_dir_all(dir.join("cmd")).unwrap();
+        fs::write(dir.join("go.mod"), "module example.com/app\n").unwrap();
+        fs::write(dir.join("cmd/main.go"), main).unwrap();
+    }
+
+    #[test]
+    fn test_key_tracks_contents() {
+        let repo = TempDir::new().unwrap();
+        fs::write(repo.path().join("go.mod"), "module example.com/app\n").unwrap();
+        fs::write(repo.path().join("main.go"), "package main\n").unwrap();
+        touch(&repo.path().join("go.mod"), 1_700_000_000);
+        touch(&repo.path().join("main.go"), 1_700_000_000);
+
+        let files = vec![PathBuf::from("main.go"), PathBuf::from("go.mod")];
+        let reversed: Vec<PathBuf> = files.iter().rev().cloned().collect();
+        let key = DetectionCache::key(
+            repo.path(),
+            &files,
+            DetectionMode::StaticOnly,
+            &RealFileSystem,
+        );
+
+        assert_eq!(
+            key,
+            DetectionCache::key(
+                repo.path(),
+                &reversed,
+                DetectionMode::StaticOnly,
+                &RealFileSystem
+            )
+        );
+        assert_ne!(
+            key,
+            DetectionCache::key(repo.path(), &files, DetectionMode::Full, &RealFileSystem)
+        );
+
+        // A fresh checkout or copy touches every file without changing any
+        touch(&repo.path().join("go.mod"), 1_700_000_001);
+        assert_eq!(
+            key,
+            DetectionCache::key(
+                repo.path(),
+                &files,
+                DetectionMode::StaticOnly,
+                &RealFileSystem
+            )
+        );
+
+        fs::write(repo.path().join("go.mod"), "module example.com/other\n").unwrap();
+        assert_ne!(
+            key,
+            DetectionCache::key(
+                repo.path(),
+                &files,
+                DetectionMode::StaticOnly,
+                &RealFileSystem
+            )
+        );
+    }
+
+    #[test]
+    fn test_key_reads_through_file_system() {
+        let mock = MockFileSystem::new();
+        mock.add_file("go.mod", "module example.com/app\n");
+        mock.add_file("empty.go", "");
+        let files = vec![PathBuf::from("go.mod"), PathBuf::from("empty.go")];
+        let key = DetectionCache::key(Path::new(""), &files, DetectionMode::StaticOnly, &mock);
+
+        // The same tree on disk hashes the same wherever the files come from
+        let repo = TempDir::new().unwrap();
+        fs::write(repo.path().join("go.mod"), "module example.com/app\n").unwrap();
+        fs::write(repo.path().join("empty.go"), "").unwrap();
+        assert_eq!(
+            key,
+            DetectionCache::key(
+                repo.path(),
+                &files,
+                DetectionMode::StaticOnly,
+                &RealFileSystem
+            )
+        );
+
+        assert!(key.is_some());
+
+        // An unreadable file is not empty, its content is unknown
+        fs::remove_file(repo.path().join("empty.go")).unwrap();
+        assert!(DetectionCache::key(
+            repo.path(),
+            &files,
+            DetectionMode::StaticOnly,
+            &RealFileSystem
+        )
+        .is_none());
+    }
+
+    #[test]
+    fn test_hash_fixture_ignores_location() {
+        let temp = TempDir::new().unwrap();
+        let original = temp.path().join("single-language/go-mod");
+        let moved = temp.path().join("archive/old/go-mod");
+        write_app(&original, "package main\n");
+        write_app(&moved, "package main\n");
+
+        let hash = hash_fixture(&original).unwrap();
+        assert_eq!(hash.len(), 64);
+        assert_eq!(hash, hash_fixture(&moved).unwrap());
+    }
+
+    #[test]
+    fn test_hash_fixture_tracks_every_byte() {
+        let temp = TempDir::new().unwrap();
+        let original = temp.path().join("a");
+        let edited = temp.path().join("b");
+        write_app(&original, "package main\n");
+        write_app(&edited, "package mainn");
+        assert_ne!(
+            hash_fixture(&original).unwrap(),
+            hash_fixture(&edited).unwrap()
+        );
+
+        // The same content under another name is another fixture
+        let renamed = temp.path().join("c");
+        write_app(&renamed, "package main\n");
+        fs::rename(renamed.join("cmd/main.go"), renamed.join("cmd/app.go")).unwrap();
+        assert_ne!(
+            hash_fixture(&original).unwrap(),
+            hash_fixture(&renamed).unwrap()
+        );
+        assert!(hash_fixture(&temp.path().join("missing")).is_err());
+    }
+
+    #[test]
+    fn test_store_and_load() {
+        let dir = TempDir::new().unwrap();
+        let cache = DetectionCache::new(dir.path().join("cache"));
+        assert!(cache.load("abc").is_none());
+
+        let build: UniversalBuild = serde_json::from_str(
+            r#"{"metadata": {"project_name": "app", "language": "Go", "build_system": "go mod"}}"#,
+        )
+        .unwrap();
+        let event = DetectionEvent::LanguageDetected {
+            service: ".".to_string(),
+            language: "Go".to_string(),
+            build_system: "go mod".to_string(),
+        };
+        cache.store("abc", &[event], &[build]).unwrap();
+
+        let entry = cache.load("abc").unwrap();
+        assert!(matches!(
+            entry.events.as_slice(),
+            [DetectionEvent::LanguageDetected { language, .. }] if language == "Go"
+        ));
+        assert_eq!(entry.builds.len(), 1);
+        assert_eq!(
+            entry.builds[0].metadata.project_name.as_deref(),
+            Some("app")
+        );
+    }
+
+    #[test]
+    fn test_outdated_entries_are_misses() {
+        let dir = TempDir::new().unwrap();
+        let cache = DetectionCache::new(dir.path());
+
+        fs::write(
+            dir.path().join("old.json"),
+            r#"{"format_version": 0, "peelbox_version": "0.0.1", "events": [], "builds": []}"#,
+        )
+        .unwrap();
+        fs::write(dir.path().join("broken.json"), "{not json").unwrap();
+
+        assert!(cache.load("old").is_none());
+        assert!(cache.load("broken").is_none());
+    }
+}
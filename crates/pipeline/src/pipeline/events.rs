@@ -0,0 +1,60 @@
+//! Detection events emitted while the pipeline runs
+//!
+//! Events for one service arrive in pipeline order: `LanguageDetected`, then `FrameworkDetected`
+//! (if any) and `BuildCommandResolved`, and later its `BuildAssembled`. Services are analyzed one
+//! after another, but consumers should only rely on the per-service order; events of different
+//! services may interleave if analysis becomes concurrent.
+//!
+//! A run answered from the detection cache replays the events stored with the entry, so
+//! consumers see the same sequence whether or not the cache is warm.
+
+use peelbox_core::output::schema::UniversalBuild;
+use serde::{Deserialize, Serialize};
+use tokio::sync::mpsc;
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(tag = "kind")]
+pub enum DetectionEvent {
+    LanguageDetected {
+        /// Service path relative to the repository root
+        service: String,
+        language: String,
+        build_system: String,
+    },
+    FrameworkDetected {
+        service: String,
+        framework: String,
+    },
+    BuildCommandResolved {
+        service: String,
+        commands: Vec<String>,
+    },
+    /// A finished build; one per detected service, in output order
+    BuildAssembled {
+        build: Box<UniversalBuild>,
+    },
+    /// A failure; a service failing analysis is skipped, a failing phase ends the stream
+    ScanError {
+        message: String,
+    },
+}
+
+pub type EventSender = mpsc::UnboundedSender<DetectionEvent>;
+pub type EventReceiver = mpsc::UnboundedReceiver<DetectionEvent>;
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_events_tagged_by_kind() {
+        let event = DetectionEvent::FrameworkDetected {
+            service: "apps/api".to_string(),
+            framework: "Gin".to_string(),
+        };
+        assert_eq!(
+            serde_json::to_value(&event).unwrap(),
+            serde_json::json!({"kind": "FrameworkDetected", "service": "apps/api", "framework": "Gin"})
+        );
+    }
+}
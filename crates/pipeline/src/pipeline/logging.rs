@@ -0,0 +1,87 @@
+//! Scan logging - how much a scan reports and where its records go
+//!
+//! The scan phase reports through the [`ScanLogger`] of its `ScanConfig`, so embedders can route
+//! its records into their own structured logger. The default forwards them to `tracing`, which
+//! the CLI writes to stderr, keeping stdout for the detection result.
+
+use std::fmt;
+use tracing::Level;
+
+/// How much the CLI reports besides the detection result
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub enum LogLevel {
+    /// Errors only
+    Quiet,
+    /// Warnings and the scan summary
+    #[default]
+    Normal,
+    /// Also per-file debug records, such as which detector matched a manifest and why
+    Verbose,
+}
+
+impl LogLevel {
+    /// Level for the `--quiet` and `--verbose` flags, which clap keeps mutually exclusive
+    pub fn from_flags(quiet: bool, verbose: bool) -> Self {
+        if quiet {
+            LogLevel::Quiet
+        } else if verbose {
+            LogLevel::Verbose
+        } else {
+            LogLevel::Normal
+        }
+    }
+
+    /// Most detailed `tracing` level shown at this output level
+    pub fn max_level(self) -> Level {
+        match self {
+            LogLevel::Quiet => Level::ERROR,
+            LogLevel::Normal => Level::INFO,
+            LogLevel::Verbose => Level::DEBUG,
+        }
+    }
+}
+
+/// Receives the records of a scan: a level, a message and structured key/value fields
+pub trait ScanLogger: fmt::Debug + Send + Sync {
+    fn log(&self, level: Level, message: &str, fields: &[(&str, String)]);
+}
+
+/// Forwards records to `tracing`, with the fields appended to the message as `key=value`
+#[derive(Debug, Default)]
+pub struct TracingLogger;
+
+impl ScanLogger for TracingLogger {
+    fn log(&self, level: Level, message: &str, fields: &[(&str, String)]) {
+        let line = fields
+            .iter()
+            .fold(message.to_string(), |line, (key, value)| {
+                format!("{} {}={}", line, key, value)
+            });
+        // tracing's macros take the level as a constant
+        if level == Level::ERROR {
+            tracing::error!("{}", line);
+        } else if level == Level::WARN {
+            tracing::warn!("{}", line);
+        } else if level == Level::INFO {
+            tracing::info!("{}", line);
+        } else if level == Level::DEBUG {
+            tracing::debug!("{}", line);
+        } else {
+            tracing::trace!("{}", line);
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_log_level_from_flags() {
+        assert_eq!(LogLevel::from_flags(false, false), LogLevel::Normal);
+        assert_eq!(LogLevel::from_flags(true, false), LogLevel::Quiet);
+        assert_eq!(LogLevel::from_flags(false, true), LogLevel::Verbose);
+        assert_eq!(LogLevel::Quiet.max_level(), Level::ERROR);
+        assert_eq!(LogLevel::Verbose.max_level(), Level::DEBUG);
+    }
+}
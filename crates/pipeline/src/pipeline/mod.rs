@@ -1,12 +1,18 @@
 pub mod confidence;
 pub mod context;
+pub mod detection_cache;
+pub mod events;
+pub mod logging;
 pub mod orchestrator;
 pub mod phase_trait;
 pub mod phases;
 pub mod service_context;
 
-pub use confidence::Confidence;
+pub use confidence::{Confidence, ConfidenceTracker, Evidence};
 pub use context::AnalysisContext;
+pub use detection_cache::DetectionCache;
+pub use events::{DetectionEvent, EventReceiver, EventSender};
+pub use logging::{LogLevel, ScanLogger, TracingLogger};
 pub use orchestrator::PipelineOrchestrator;
 pub use phase_trait::{ServicePhase, WorkflowPhase};
 pub use service_context::ServiceContext;
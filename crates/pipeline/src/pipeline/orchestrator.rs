@@ -1,16 +1,25 @@
 use super::context::AnalysisContext;
+use super::detection_cache::DetectionCache;
+use super::events::{DetectionEvent, EventReceiver};
 use super::phase_trait::WorkflowPhase;
 use super::phases::{
-    assemble::AssemblePhase, root_cache::RootCachePhase, scan::ScanPhase,
-    service_analysis::ServiceAnalysisPhase, workspace::WorkspaceStructurePhase,
+    assemble::AssemblePhase,
+    root_cache::RootCachePhase,
+    scan::{ScanConfig, ScanPhase},
+    service_analysis::ServiceAnalysisPhase,
+    workspace::WorkspaceStructurePhase,
 };
 use anyhow::{Context, Result};
 use peelbox_core::output::schema::UniversalBuild;
-use std::path::Path;
+use std::path::{Path, PathBuf};
+use std::sync::Arc;
 use std::time::Instant;
-use tracing::info;
+use tokio::sync::mpsc;
+use tracing::{info, warn};
 
-pub struct PipelineOrchestrator;
+pub struct PipelineOrchestrator {
+    scan_config: ScanConfig,
+}
 
 impl Default for PipelineOrchestrator {
     fn default() -> Self {
@@ -20,22 +29,100 @@ impl Default for PipelineOrchestrator {
 
 impl PipelineOrchestrator {
     pub fn new() -> Self {
-        Self
+        Self::with_scan_config(ScanConfig::default())
+    }
+
+    pub fn with_scan_config(scan_config: ScanConfig) -> Self {
+        Self { scan_config }
     }
 
+    /// Runs the pipeline and returns every assembled build
+    ///
+    /// Built on the event stream: the result is the run's `BuildAssembled` events, in order.
     pub async fn execute(
         &self,
         repo_path: &Path,
         context: &mut AnalysisContext,
     ) -> Result<Vec<UniversalBuild>> {
+        let (tx, mut rx) = mpsc::unbounded_channel();
+        let listener = context.events.replace(tx);
+        let result = self.run(repo_path, context).await;
+        context.events = listener;
+        context.builds.clear();
+        result?;
+
+        let mut builds = Vec::new();
+        while let Ok(event) = rx.try_recv() {
+            context.emit(event.clone());
+            if let DetectionEvent::BuildAssembled { build } = event {
+                builds.push(*build);
+            }
+        }
+        Ok(builds)
+    }
+
+    /// Runs the pipeline in the background and streams its events as they are produced
+    ///
+    /// A phase failure arrives as a final `ScanError`; the channel closes once detection ends.
+    pub fn stream(self, repo_path: PathBuf, mut context: AnalysisContext) -> EventReceiver {
+        let (tx, rx) = mpsc::unbounded_channel();
+        tokio::spawn(async move {
+            context.events = Some(tx.clone());
+            if let Err(e) = self.run(&repo_path, &mut context).await {
+                let _ = tx.send(DetectionEvent::ScanError {
+                    message: format!("{:#}", e),
+                });
+            }
+        });
+        rx
+    }
+
+    async fn run(&self, repo_path: &Path, context: &mut AnalysisContext) -> Result<()> {
         let start = Instant::now();
         info!(
             repo = %repo_path.display(),
             "Starting detection pipeline"
         );
 
+        // The scan's file tree is also the cache key's input, so the repository is only walked
+        // once whether or not the cache has an entry
+        run_phase(&ScanPhase::with_config(self.scan_config.clone()), context).await?;
+
+        let cache = self
+            .scan_config
+            .cache_dir
+            .as_deref()
+            .zip(context.scan.as_ref())
+            .and_then(|(dir, scan)| {
+                let key = DetectionCache::key(
+                    &scan.repo_path,
+                    &scan.file_tree,
+                    context.detection_mode,
+                    context.fs.as_ref(),
+                )?;
+                Some((DetectionCache::new(dir), key))
+            });
+        if let Some(entry) = cache.as_ref().and_then(|(cache, key)| cache.load(key)) {
+            info!(
+                projects_detected = entry.builds.len(),
+                total_time_ms = start.elapsed().as_millis(),
+                "Detection cache hit"
+            );
+            for event in entry.events {
+                context.emit(event);
+            }
+            for build in entry.builds {
+                context.emit(DetectionEvent::BuildAssembled {
+                    build: Box::new(build),
+                });
+            }
+            return Ok(());
+        }
+        if cache.is_some() {
+            context.event_log = Some(Arc::default());
+        }
+
         let workflow_phases: Vec<Box<dyn WorkflowPhase>> = vec![
-            Box::new(ScanPhase),
             Box::new(WorkspaceStructurePhase),
             Box::new(RootCachePhase),
             Box::new(ServiceAnalysisPhase),
@@ -43,20 +130,7 @@ impl PipelineOrchestrator {
         ];
 
         for phase in workflow_phases {
-            let phase_name = phase.name();
-            info!(phase = %phase_name, "Starting phase");
-
-            let phase_start = Instant::now();
-            phase
-                .execute(context)
-                .await
-                .with_context(|| format!("Phase {} failed", phase_name))?;
-
-            info!(
-                phase = %phase_name,
-                duration_ms = phase_start.elapsed().as_millis(),
-                "Phase complete"
-            );
+            run_phase(phase.as_ref(), context).await?;
         }
 
         info!(
@@ -65,16 +139,230 @@ impl PipelineOrchestrator {
             "Detection complete"
         );
 
-        Ok(std::mem::take(&mut context.builds))
+        let events: Vec<DetectionEvent> = context
+            .event_log
+            .take()
+            .map(|log| std::mem::take(&mut *log.lock().unwrap()))
+            .unwrap_or_default()
+            .into_iter()
+            // The builds are stored on their own and replayed as `BuildAssembled`
+            .filter(|event| !matches!(event, DetectionEvent::BuildAssembled { .. }))
+            .collect();
+        if let Some((cache, key)) = cache {
+            // A failed write only costs the next run a full scan
+            if let Err(e) = cache.store(&key, &events, &context.builds) {
+                warn!(error = %e, "Failed to cache detection results");
+            }
+        }
+
+        Ok(())
     }
 }
 
+async fn run_phase(phase: &dyn WorkflowPhase, context: &mut AnalysisContext) -> Result<()> {
+    let phase_name = phase.name();
+    info!(phase = %phase_name, "Starting phase");
+
+    let phase_start = Instant::now();
+    phase
+        .execute(context)
+        .await
+        .with_context(|| format!("Phase {} failed", phase_name))?;
+
+    info!(
+        phase = %phase_name,
+        duration_ms = phase_start.elapsed().as_millis(),
+        "Phase complete"
+    );
+    Ok(())
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
 
+    use peelbox_core::config::DetectionMode;
+    use peelbox_core::fs::{ArchiveFileSystem, ArchiveFormat};
+    use peelbox_stack::StackRegistry;
+    use std::fs;
+    use tempfile::TempDir;
+
     #[tokio::test]
     async fn test_orchestrator_creation() {
         let _orchestrator = PipelineOrchestrator::new();
     }
+
+    async fn detect(orchestrator: &PipelineOrchestrator, repo: &Path) -> Vec<UniversalBuild> {
+        let mut context = AnalysisContext::new(
+            repo,
+            Arc::new(StackRegistry::with_defaults(None)),
+            Arc::new(peelbox_wolfi::WolfiPackageIndex::for_tests()),
+            DetectionMode::StaticOnly,
+        );
+        orchestrator.execute(repo, &mut context).await.unwrap()
+    }
+
+    fn cache_entries(dir: &Path) -> Vec<PathBuf> {
+        let mut entries: Vec<PathBuf> = fs::read_dir(dir)
+            .unwrap()
+            .map(|e| e.unwrap().path())
+            .collect();
+        entries.sort();
+        entries
+    }
+
+    #[tokio::test]
+    async fn test_detection_cache_invalidation() {
+        let repo = TempDir::new().unwrap();
+        let cache_dir = TempDir::new().unwrap();
+        fs::write(
+            repo.path().join("go.mod"),
+            "module example.com/cached\n\ngo 1.22\n",
+        )
+        .unwrap();
+        fs::write(
+            repo.path().join("main.go"),
+            "package main\n\nfunc main() {}\n",
+        )
+        .unwrap();
+
+        let orchestrator = PipelineOrchestrator::with_scan_config(ScanConfig {
+            cache_dir: Some(cache_dir.path().to_path_buf()),
+            ..ScanConfig::default()
+        });
+
+        let first = detect(&orchestrator, repo.path()).await;
+        assert_eq!(first[0].metadata.project_name.as_deref(), Some("cached"));
+        let entries = cache_entries(cache_dir.path());
+        assert_eq!(entries.len(), 1);
+
+        // A hit returns the stored entry without analysing the services again
+        let stored = fs::read_to_string(&entries[0]).unwrap();
+        fs::write(&entries[0], stored.replace("\"cached\"", "\"from-cache\"")).unwrap();
+        let second = detect(&orchestrator, repo.path()).await;
+        assert_eq!(
+            second[0].metadata.project_name.as_deref(),
+            Some("from-cache")
+        );
+
+        // Changing an input file changes the key, so detection runs again
+        let go_mod = repo.path().join("go.mod");
+        fs::write(&go_mod, "module example.com/renamed\n\ngo 1.22\n").unwrap();
+        let third = detect(&orchestrator, repo.path()).await;
+        assert_eq!(third[0].metadata.project_name.as_deref(), Some("renamed"));
+        assert_eq!(cache_entries(cache_dir.path()).len(), 2);
+    }
+
+    #[tokio::test]
+    async fn test_archive_detection_assembles_builds() {
+        let mut builder = tar::Builder::new(Vec::new());
+        for (path, content) in [
+            ("go.mod", "module example.com/archived\n\ngo 1.22\n"),
+            ("main.go", "package main\n\nfunc main() {}\n"),
+        ] {
+            let mut header = tar::Header::new_gnu();
+            header.set_size(content.len() as u64);
+            header.set_mode(0o644);
+            header.set_cksum();
+            builder
+                .append_data(&mut header, path, content.as_bytes())
+                .unwrap();
+        }
+        let archive = builder.into_inner().unwrap();
+        let fs = ArchiveFileSystem::read(archive.as_slice(), ArchiveFormat::Tar).unwrap();
+
+        let mut context = AnalysisContext::new(
+            Path::new(""),
+            Arc::new(StackRegistry::with_defaults(None)),
+            Arc::new(peelbox_wolfi::WolfiPackageIndex::for_tests()),
+            DetectionMode::StaticOnly,
+        )
+        .with_file_system(Arc::new(fs));
+        let builds = PipelineOrchestrator::new()
+            .execute(Path::new(""), &mut context)
+            .await
+            .unwrap();
+
+        assert_eq!(builds.len(), 1);
+        assert_eq!(builds[0].metadata.project_name.as_deref(), Some("archived"));
+        assert_eq!(builds[0].metadata.build_system, "go mod");
+    }
+
+    #[tokio::test]
+    async fn test_stream_emits_service_events() {
+        let repo = TempDir::new().unwrap();
+        fs::write(
+            repo.path().join("go.mod"),
+            "module example.com/streamed\n\ngo 1.22\n",
+        )
+        .unwrap();
+        fs::write(
+            repo.path().join("main.go"),
+            "package main\n\nfunc main() {}\n",
+        )
+        .unwrap();
+
+        let context = AnalysisContext::new(
+            repo.path(),
+            Arc::new(StackRegistry::with_defaults(None)),
+            Arc::new(peelbox_wolfi::WolfiPackageIndex::for_tests()),
+            DetectionMode::StaticOnly,
+        );
+        let mut events = PipelineOrchestrator::new().stream(repo.path().to_path_buf(), context);
+
+        let mut kinds = Vec::new();
+        while let Some(event) = events.recv().await {
+            kinds.push(serde_json::to_value(&event).unwrap()["kind"].clone());
+        }
+        assert_eq!(
+            kinds,
+            vec!["LanguageDetected", "BuildCommandResolved", "BuildAssembled"]
+        );
+    }
+
+    async fn stream_with_cache(repo: &Path, cache_dir: &Path) -> Vec<serde_json::Value> {
+        let context = AnalysisContext::new(
+            repo,
+            Arc::new(StackRegistry::with_defaults(None)),
+            Arc::new(peelbox_wolfi::WolfiPackageIndex::for_tests()),
+            DetectionMode::StaticOnly,
+        );
+        let orchestrator = PipelineOrchestrator::with_scan_config(ScanConfig {
+            cache_dir: Some(cache_dir.to_path_buf()),
+            ..ScanConfig::default()
+        });
+        let mut events = orchestrator.stream(repo.to_path_buf(), context);
+        let mut received = Vec::new();
+        while let Some(event) = events.recv().await {
+            received.push(serde_json::to_value(&event).unwrap());
+        }
+        received
+    }
+
+    #[tokio::test]
+    async fn test_stream_replays_events_from_cache() {
+        let repo = TempDir::new().unwrap();
+        let cache_dir = TempDir::new().unwrap();
+        fs::write(
+            repo.path().join("go.mod"),
+            "module example.com/streamed\n\ngo 1.22\n",
+        )
+        .unwrap();
+        fs::write(
+            repo.path().join("main.go"),
+            "package main\n\nfunc main() {}\n",
+        )
+        .unwrap();
+
+        let cold = stream_with_cache(repo.path(), cache_dir.path()).await;
+        assert_eq!(cache_entries(cache_dir.path()).len(), 1);
+        assert_eq!(
+            cold.iter().map(|event| &event["kind"]).collect::<Vec<_>>(),
+            vec!["LanguageDetected", "BuildCommandResolved", "BuildAssembled"]
+        );
+
+        // A warm cache streams the same sequence
+        let warm = stream_with_cache(repo.path(), cache_dir.path()).await;
+        assert_eq!(warm, cold);
+    }
 }
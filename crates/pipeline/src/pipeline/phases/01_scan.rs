@@ -1,21 +1,45 @@
 use crate::pipeline::context::AnalysisContext;
+use crate::pipeline::logging::{ScanLogger, TracingLogger};
 use crate::pipeline::phase_trait::WorkflowPhase;
 use anyhow::{Context, Result};
 use async_trait::async_trait;
-use ignore::{overrides::OverrideBuilder, WalkBuilder};
+use ignore::overrides::{Override, OverrideBuilder};
+use ignore::{WalkBuilder, WalkState};
+use peelbox_core::config::DetectionMode;
+use peelbox_core::fs::FileSystem;
 use peelbox_stack::{DetectionStack, StackRegistry};
 use serde::{Deserialize, Serialize};
 use std::collections::{BTreeMap, HashMap};
 use std::path::{Path, PathBuf};
-use std::sync::Arc;
+use std::sync::atomic::{AtomicUsize, Ordering};
+use std::sync::{mpsc, Arc};
 use std::time::Instant;
-use tracing::{debug, info, trace, warn};
+use tracing::{trace, Level};
+
+/// Generated and vendored directories skipped unless `ScanConfig::exclude` says otherwise
+pub const DEFAULT_EXCLUDES: [&str; 5] = ["vendor", "node_modules", ".git", "testdata", "dist"];
 
 #[derive(Debug, Clone)]
 pub struct ScanConfig {
+    /// Deepest directory level walked, counted like a manifest's `depth` (files in the root
+    /// are at 0); 0 walks the whole tree
     pub max_depth: usize,
     pub max_files: usize,
     pub read_content: bool,
+    /// Threads walking the directory tree (defaults to the available CPU count)
+    pub workers: usize,
+    /// Directory for cached detection results; caching is off when unset
+    pub cache_dir: Option<PathBuf>,
+    /// Glob patterns, relative to the repository root, of directories skipped together with
+    /// everything below them
+    pub exclude: Vec<String>,
+    /// GitHub or GitLab API token for remote scans; public repositories need none
+    pub auth_token: Option<String>,
+    /// Self-hosted GitLab instances remote scans may reach besides github.com and gitlab.com;
+    /// `auth_token` is sent to no other host
+    pub gitlab_hosts: Vec<String>,
+    /// Receives the scan's log records; [`TracingLogger`] unless the caller injects its own
+    pub logger: Arc<dyn ScanLogger>,
 }
 
 impl Default for ScanConfig {
@@ -24,6 +48,14 @@ impl Default for ScanConfig {
             max_depth: 10,
             max_files: 1000,
             read_content: true,
+            workers: std::thread::available_parallelism()
+                .map(|n| n.get())
+                .unwrap_or(1),
+            cache_dir: None,
+            exclude: DEFAULT_EXCLUDES.iter().map(|dir| dir.to_string()).collect(),
+            auth_token: None,
+            gitlab_hosts: Vec::new(),
+            logger: Arc::new(TracingLogger),
         }
     }
 }
@@ -202,7 +234,7 @@ Use these manifest files to guide your analysis. Read them directly without sear
         let primary = detections
             .iter()
             .filter(|d| d.depth == 0)
-            .max_by(|a, b| a.confidence.partial_cmp(&b.confidence).unwrap());
+            .max_by(|a, b| a.confidence.total_cmp(&b.confidence));
 
         let has_workspace_root = detections.iter().any(|d| d.is_workspace_root);
 
@@ -257,7 +289,13 @@ fn deduplicate_detections(
                             .map(|p| p.priority)
                     })
                     .unwrap_or(0);
-                std::cmp::Reverse(priority)
+                // Ties go to registered plugins, then to the first name, so the choice never
+                // depends on the registry's iteration order
+                (
+                    std::cmp::Reverse(priority),
+                    !matches!(d.build_system, peelbox_stack::BuildSystemId::Custom(_)),
+                    d.build_system.name(),
+                )
             });
 
             if let Some(highest_priority) = detections_for_dir.first() {
@@ -276,6 +314,7 @@ fn enrich_detections(
     detections: &mut [DetectionStack],
     repo_path: &Path,
     stack_registry: &Arc<StackRegistry>,
+    fs: &dyn FileSystem,
     read_content: bool,
 ) -> Result<()> {
     for detection in detections.iter_mut() {
@@ -288,7 +327,7 @@ fn enrich_detections(
 
         if read_content {
             if let Some(filename) = detection.manifest_path.file_name().and_then(|n| n.to_str()) {
-                let content = std::fs::read_to_string(&detection.manifest_path).ok();
+                let content = fs.read_to_string(&detection.manifest_path).ok();
                 detection.is_workspace_root =
                     stack_registry.is_workspace_root(filename, content.as_deref());
             }
@@ -297,7 +336,10 @@ fn enrich_detections(
     Ok(())
 }
 
-pub struct ScanPhase;
+#[derive(Default)]
+pub struct ScanPhase {
+    config: ScanConfig,
+}
 
 #[async_trait]
 impl WorkflowPhase for ScanPhase {
@@ -311,8 +353,16 @@ impl WorkflowPhase for ScanPhase {
 }
 
 impl ScanPhase {
+    pub fn with_config(config: ScanConfig) -> Self {
+        Self { config }
+    }
+
     fn scan_repository(&self, context: &mut AnalysisContext) -> Result<()> {
-        let config = ScanConfig::default();
+        if let Some(files) = context.fs.listed_files() {
+            return self.scan_listing(context, files);
+        }
+
+        let config = &self.config;
         let repo_path = &context.repo_path;
 
         if !repo_path.exists() {
@@ -334,165 +384,381 @@ impl ScanPhase {
 
         let stack_registry = Arc::clone(&context.stack_registry);
 
-        debug!(
-            repo_path = %repo_path.display(),
-            "Starting repository scan"
+        let start = Instant::now();
+
+        config.logger.log(
+            Level::INFO,
+            "Starting repository scan",
+            &[
+                ("repo", repo_path.display().to_string()),
+                ("max_depth", config.max_depth.to_string()),
+                ("max_files", config.max_files.to_string()),
+                ("workers", config.workers.to_string()),
+            ],
         );
 
+        let file_tree = walk_file_tree(&repo_path, &stack_registry, config);
+        let result = detect_stacks(
+            repo_path,
+            file_tree,
+            context.fs.as_ref(),
+            &stack_registry,
+            config,
+            context.detection_mode,
+            start,
+        )?;
+
+        context.scan = Some(result);
+        Ok(())
+    }
+
+    /// Scans a file system that lists its files instead of a directory, such as an archive or
+    /// a remote tree
+    ///
+    /// Its root plays the repository root, so the result keeps the context's (empty)
+    /// `repo_path` and paths relative to that root. `.gitignore` files are not honoured;
+    /// `config.exclude` and the languages' excluded directories are.
+    fn scan_listing(&self, context: &mut AnalysisContext, files: Vec<PathBuf>) -> Result<()> {
+        let config = &self.config;
+        let stack_registry = Arc::clone(&context.stack_registry);
         let start = Instant::now();
 
-        info!(
-            repo = %repo_path.display(),
-            max_depth = config.max_depth,
-            max_files = config.max_files,
-            "Starting repository scan"
+        config.logger.log(
+            Level::INFO,
+            "Starting listed file scan",
+            &[
+                ("files_listed", files.len().to_string()),
+                ("max_depth", config.max_depth.to_string()),
+                ("max_files", config.max_files.to_string()),
+            ],
         );
 
-        let mut file_tree = Vec::new();
-        let mut files_scanned = 0;
-        let mut has_workspace_config = false;
+        let file_tree = listed_file_tree(files, &stack_registry, config);
+        let result = detect_stacks(
+            context.repo_path.clone(),
+            limit_file_tree(file_tree, &stack_registry, config),
+            context.fs.as_ref(),
+            &stack_registry,
+            config,
+            context.detection_mode,
+            start,
+        )?;
 
-        let mut override_builder = OverrideBuilder::new(&repo_path);
-        for excluded in stack_registry.all_excluded_dirs() {
-            override_builder.add(&format!("!{}/", excluded)).ok();
-        }
-        let overrides = override_builder
-            .build()
-            .unwrap_or_else(|_| OverrideBuilder::new(&repo_path).build().unwrap());
-
-        let has_git_dir = repo_path.join(".git").exists();
-
-        for result in WalkBuilder::new(&repo_path)
-            .max_depth(Some(config.max_depth))
-            .hidden(false)
-            .git_ignore(has_git_dir)
-            .git_global(false)
-            .git_exclude(false)
-            .overrides(overrides)
-            .build()
-        {
-            let entry = match result {
-                Ok(e) => e,
-                Err(err) => {
-                    warn!(error = %err, "Failed to read directory entry");
-                    continue;
-                }
-            };
-            let path = entry.path();
+        context.scan = Some(result);
+        Ok(())
+    }
+}
 
-            if !path.is_file() {
-                continue;
-            }
+/// Applies the depth limit and exclusions to a file listing that was not produced by a walk
+fn listed_file_tree(
+    files: Vec<PathBuf>,
+    stack_registry: &StackRegistry,
+    config: &ScanConfig,
+) -> Vec<PathBuf> {
+    let overrides = exclude_overrides(Path::new(""), stack_registry, config);
+    files
+        .into_iter()
+        .filter(|path| walk_depth(config).map_or(true, |depth| path.components().count() <= depth))
+        .filter(|path| {
+            !path
+                .ancestors()
+                .skip(1)
+                .filter(|dir| !dir.as_os_str().is_empty())
+                .any(|dir| overrides.matched(dir, true).is_ignore())
+        })
+        .collect()
+}
 
-            if files_scanned >= config.max_files {
-                warn!(
-                    files_scanned,
-                    max_files = config.max_files,
-                    "Reached file limit, stopping scan"
-                );
-                break;
+/// Detects the stacks in `file_tree`, reading manifests through `fs`; shared by directory,
+/// archive and remote scans
+fn detect_stacks(
+    repo_path: PathBuf,
+    file_tree: Vec<PathBuf>,
+    fs: &dyn FileSystem,
+    stack_registry: &Arc<StackRegistry>,
+    config: &ScanConfig,
+    detection_mode: DetectionMode,
+    start: Instant,
+) -> Result<ScanResult> {
+    let files_scanned = file_tree.len();
+    let mut has_workspace_config = false;
+
+    config.logger.log(
+        Level::INFO,
+        "File tree scan complete, running batch detection",
+        &[("files_scanned", files_scanned.to_string())],
+    );
+
+    let mut detections =
+        stack_registry.detect_all_stacks(&repo_path, &file_tree, fs, detection_mode)?;
+
+    // Register LLM languages and build systems for any Custom IDs detected (skip in StaticOnly mode)
+    if detection_mode != DetectionMode::StaticOnly {
+        for detection in &detections {
+            if matches!(detection.language, peelbox_stack::LanguageId::Custom(_)) {
+                stack_registry.register_llm_language(detection.language.clone());
+            }
+            if matches!(
+                detection.build_system,
+                peelbox_stack::BuildSystemId::Custom(_)
+            ) {
+                let manifest_path = repo_path.join(&detection.manifest_path);
+                if let Err(e) = stack_registry.register_llm_build_system(
+                    detection.build_system.clone(),
+                    &manifest_path,
+                    fs,
+                ) {
+                    config.logger.log(
+                        Level::WARN,
+                        "Failed to register LLM build system",
+                        &[
+                            ("build_system", format!("{:?}", detection.build_system)),
+                            ("error", e.to_string()),
+                        ],
+                    );
+                }
             }
-            files_scanned += 1;
+        }
+    }
 
-            let rel_path = path.strip_prefix(&repo_path).unwrap_or(path).to_path_buf();
+    enrich_detections(
+        &mut detections,
+        &repo_path,
+        stack_registry,
+        fs,
+        config.read_content,
+    )?;
 
-            file_tree.push(rel_path.clone());
+    for detection in &detections {
+        let manifest = detection
+            .manifest_path
+            .file_name()
+            .map(|name| name.to_string_lossy().into_owned())
+            .unwrap_or_default();
+        config.logger.log(
+            Level::DEBUG,
+            "Detected language",
+            &[
+                ("path", detection.manifest_path.display().to_string()),
+                ("language", detection.language.name()),
+                ("build_system", detection.build_system.name()),
+                ("confidence", detection.confidence.to_string()),
+                (
+                    "reason",
+                    format!(
+                        "{} is a {} manifest",
+                        manifest,
+                        detection.build_system.name()
+                    ),
+                ),
+            ],
+        );
 
-            trace!(
-                path = %path.display(),
-                "Added file to tree"
-            );
+        if detection.is_workspace_root {
+            has_workspace_config = true;
         }
+    }
 
-        // Sort file tree to ensure deterministic prompts and stable LLM recordings
-        file_tree.sort();
+    let elapsed = start.elapsed();
+    let scan_time_ms = elapsed.as_millis() as u64;
+
+    let detections = deduplicate_detections(detections, stack_registry);
+
+    config.logger.log(
+        Level::INFO,
+        "Repository scan completed",
+        &[
+            ("detections_found", detections.len().to_string()),
+            ("files_scanned", files_scanned.to_string()),
+            ("scan_time_ms", scan_time_ms.to_string()),
+        ],
+    );
+
+    Ok(ScanResult::from_scan(
+        repo_path,
+        detections,
+        file_tree,
+        has_workspace_config,
+        scan_time_ms,
+    ))
+}
 
-        info!(
-            files_scanned,
-            "File tree scan complete, running batch detection"
+/// Matches the directories a scan skips: every language's excluded directories and
+/// `config.exclude`
+pub(crate) fn exclude_overrides(
+    repo_path: &Path,
+    stack_registry: &StackRegistry,
+    config: &ScanConfig,
+) -> Override {
+    let mut override_builder = OverrideBuilder::new(repo_path);
+    for excluded in stack_registry.all_excluded_dirs() {
+        override_builder.add(&format!("!{}/", excluded)).ok();
+    }
+    for pattern in &config.exclude {
+        let pattern = pattern.trim_end_matches('/');
+        if override_builder.add(&format!("!{}/", pattern)).is_err() {
+            config.logger.log(
+                Level::WARN,
+                "Ignoring invalid exclude pattern",
+                &[("pattern", pattern.to_string())],
+            );
+        }
+    }
+    override_builder.build().unwrap_or_else(|e| {
+        config.logger.log(
+            Level::WARN,
+            "Ignoring exclude patterns that failed to compile",
+            &[("error", e.to_string())],
         );
+        Override::empty()
+    })
+}
 
-        let fs = peelbox_core::fs::RealFileSystem;
-        let mut detections = stack_registry.detect_all_stacks(
-            &repo_path,
-            &file_tree,
-            &fs,
-            context.detection_mode,
-        )?;
+/// Deepest level the walk counts files at; deeper directories are never skipped for the limit
+const COUNTED_DEPTHS: usize = 64;
 
-        // Register LLM languages and build systems for any Custom IDs detected (skip in StaticOnly mode)
-        use peelbox_core::config::DetectionMode;
-        if context.detection_mode != DetectionMode::StaticOnly {
-            for detection in &detections {
-                if matches!(detection.language, peelbox_stack::LanguageId::Custom(_)) {
-                    stack_registry.register_llm_language(detection.language.clone());
-                }
-                if matches!(
-                    detection.build_system,
-                    peelbox_stack::BuildSystemId::Custom(_)
-                ) {
-                    let manifest_path = repo_path.join(&detection.manifest_path);
-                    if let Err(e) = stack_registry.register_llm_build_system(
-                        detection.build_system.clone(),
-                        &manifest_path,
-                        &fs,
-                    ) {
-                        warn!(
-                            "Failed to register LLM build system {:?}: {}",
-                            detection.build_system, e
+/// Walks the repository on `config.workers` threads and returns repo-relative file paths, sorted
+///
+/// At most `config.max_files` paths are kept, shallowest first (see [`limit_file_tree`]).
+/// Walker threads share a count of the files found at each depth and skip any directory whose
+/// files could no longer make the cut, so the limit bounds the walk as well as the result.
+fn walk_file_tree(
+    repo_path: &Path,
+    stack_registry: &StackRegistry,
+    config: &ScanConfig,
+) -> Vec<PathBuf> {
+    let overrides = exclude_overrides(repo_path, stack_registry, config);
+    let has_git_dir = repo_path.join(".git").exists();
+    let found: Vec<AtomicUsize> = (0..COUNTED_DEPTHS).map(|_| AtomicUsize::new(0)).collect();
+    let found = &found;
+
+    // Each walker thread sends the files it finds; nothing else is shared but the counts
+    let (tx, rx) = mpsc::channel();
+    WalkBuilder::new(repo_path)
+        .max_depth(walk_depth(config))
+        .hidden(false)
+        .git_ignore(has_git_dir)
+        .git_global(false)
+        .git_exclude(false)
+        .overrides(overrides)
+        .threads(config.workers.max(1))
+        .build_parallel()
+        .run(|| {
+            let tx = tx.clone();
+            Box::new(move |result| {
+                let entry = match result {
+                    Ok(e) => e,
+                    Err(err) => {
+                        config.logger.log(
+                            Level::WARN,
+                            "Failed to read directory entry",
+                            &[("error", err.to_string())],
                         );
+                        return WalkState::Continue;
+                    }
+                };
+                let path = entry.path();
+                let depth = entry.depth().min(COUNTED_DEPTHS - 1);
+
+                if entry.file_type().is_some_and(|t| t.is_dir()) {
+                    // Its files sit below `depth`; once shallower files fill the limit they
+                    // would all be dropped. Counts only grow, so a skip is never premature.
+                    let shallower: usize = found[..=depth]
+                        .iter()
+                        .map(|count| count.load(Ordering::Relaxed))
+                        .sum();
+                    if depth > 0 && depth < COUNTED_DEPTHS - 1 && shallower >= config.max_files {
+                        return WalkState::Skip;
+                    }
+                } else if path.is_file() {
+                    found[depth].fetch_add(1, Ordering::Relaxed);
+                    let rel_path = path.strip_prefix(repo_path).unwrap_or(path).to_path_buf();
+                    trace!(
+                        path = %path.display(),
+                        "Added file to tree"
+                    );
+                    if tx.send(rel_path).is_err() {
+                        return WalkState::Quit;
                     }
                 }
-            }
-        }
-
-        enrich_detections(
-            &mut detections,
-            &repo_path,
-            &stack_registry,
-            config.read_content,
-        )?;
-
-        for detection in &detections {
-            debug!(
-                path = %detection.manifest_path.display(),
-                language = %detection.language.name(),
-                build_system = %detection.build_system.name(),
-                confidence = detection.confidence,
-                "Detected language"
-            );
-
-            if detection.is_workspace_root {
-                has_workspace_config = true;
-            }
-        }
-
-        let elapsed = start.elapsed();
-        let scan_time_ms = elapsed.as_millis() as u64;
+                WalkState::Continue
+            })
+        });
+    drop(tx);
+
+    // Threads finish in any order; the limit ranks every path it is given, so the same files
+    // are kept every run, in the order that keeps prompts and LLM recordings stable
+    limit_file_tree(rx.into_iter().collect(), stack_registry, config)
+}
 
-        let detections = deduplicate_detections(detections, &stack_registry);
+/// `config.max_depth` as a walker depth, where files in the root are at depth 1
+fn walk_depth(config: &ScanConfig) -> Option<usize> {
+    (config.max_depth > 0).then(|| config.max_depth + 1)
+}
 
-        info!(
-            detections_found = detections.len(),
-            files_scanned, scan_time_ms, "Repository scan completed"
+/// Keeps `config.max_files` paths, sorted
+///
+/// Shallower paths are kept first and, at the same depth, manifests before other files, so a
+/// large repository still has its root manifests detected.
+fn limit_file_tree(
+    mut file_tree: Vec<PathBuf>,
+    stack_registry: &StackRegistry,
+    config: &ScanConfig,
+) -> Vec<PathBuf> {
+    if file_tree.len() > config.max_files {
+        config.logger.log(
+            Level::WARN,
+            "Reached file limit, truncating file tree",
+            &[
+                ("files_found", file_tree.len().to_string()),
+                ("max_files", config.max_files.to_string()),
+            ],
         );
+        let manifests = manifest_patterns(stack_registry);
+        file_tree.sort_by_cached_key(|path| {
+            (
+                path.components().count(),
+                !is_manifest(path, &manifests),
+                path.clone(),
+            )
+        });
+        file_tree.truncate(config.max_files);
+    }
 
-        let result = ScanResult::from_scan(
-            repo_path,
-            detections,
-            file_tree,
-            has_workspace_config,
-            scan_time_ms,
-        );
+    file_tree.sort();
+    file_tree
+}
 
-        context.scan = Some(result);
-        Ok(())
-    }
+/// File names the registered build systems detect, `*.ext` patterns included
+pub(crate) fn manifest_patterns(stack_registry: &StackRegistry) -> Vec<String> {
+    let mut patterns: Vec<String> = stack_registry
+        .all_build_systems()
+        .iter()
+        .flat_map(|build_system| build_system.manifest_patterns())
+        .map(|pattern| pattern.filename)
+        .chain(stack_registry.all_workspace_configs())
+        .collect();
+    patterns.sort();
+    patterns.dedup();
+    patterns
+}
+
+pub(crate) fn is_manifest(path: &Path, patterns: &[String]) -> bool {
+    let Some(name) = path.file_name().and_then(|name| name.to_str()) else {
+        return false;
+    };
+    patterns
+        .iter()
+        .any(|pattern| match pattern.strip_prefix('*') {
+            Some(suffix) => name.ends_with(suffix),
+            None => name == pattern,
+        })
 }
 
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::{ArchiveFileSystem, ArchiveFormat, RemoteFileSystem, RemoteRepository};
     use std::fs;
     use std::io::Write;
     use tempfile::TempDir;
@@ -529,7 +795,6 @@ mod tests {
     }
 
     fn create_test_context(repo_path: &Path) -> AnalysisContext {
-        use peelbox_core::config::DetectionMode;
         let stack_registry = Arc::new(StackRegistry::with_defaults(None));
         let wolfi_index = Arc::new(peelbox_wolfi::WolfiPackageIndex::for_tests());
         AnalysisContext::new(repo_path, stack_registry, wolfi_index, DetectionMode::Full)
@@ -539,7 +804,7 @@ mod tests {
     async fn test_scan_execution() {
         let temp_dir = create_test_repo();
         let mut context = create_test_context(temp_dir.path());
-        let phase = ScanPhase;
+        let phase = ScanPhase::default();
         phase.execute(&mut context).await.unwrap();
         assert!(context.scan.is_some());
     }
@@ -548,7 +813,7 @@ mod tests {
     async fn test_file_tree_excludes_node_modules() {
         let temp_dir = create_test_repo();
         let mut context = create_test_context(temp_dir.path());
-        let phase = ScanPhase;
+        let phase = ScanPhase::default();
         phase.execute(&mut context).await.unwrap();
 
         let scan = context.scan.as_ref().unwrap();
@@ -565,11 +830,613 @@ mod tests {
     async fn test_find_files_by_name() {
         let temp_dir = create_test_repo();
         let mut context = create_test_context(temp_dir.path());
-        let phase = ScanPhase;
+        let phase = ScanPhase::default();
         phase.execute(&mut context).await.unwrap();
 
         let scan = context.scan.as_ref().unwrap();
         let cargo_files = scan.find_files_by_name("Cargo.toml");
         assert!(!cargo_files.is_empty());
     }
+
+    #[tokio::test]
+    async fn test_excluded_directories_are_not_scanned() {
+        let temp_dir = TempDir::new().unwrap();
+        let base = temp_dir.path();
+        fs::write(base.join("go.mod"), "module example.com/app\n\ngo 1.21\n").unwrap();
+        fs::write(base.join("main.go"), "package main\n\nfunc main() {}\n").unwrap();
+        let vendored = base.join("vendor/github.com/acme/lib");
+        fs::create_dir_all(&vendored).unwrap();
+        fs::write(
+            vendored.join("go.mod"),
+            "module github.com/acme/lib\n\ngo 1.21\n",
+        )
+        .unwrap();
+        fs::write(vendored.join("lib.go"), "package lib\n").unwrap();
+
+        let phase = ScanPhase::with_config(ScanConfig::default());
+        let mut context = create_test_context(base);
+        phase.execute(&mut context).await.unwrap();
+
+        let scan = context.scan.as_ref().unwrap();
+        let manifests: Vec<&Path> = scan
+            .detections
+            .iter()
+            .map(|d| d.manifest_path.as_path())
+            .collect();
+        assert_eq!(manifests, vec![Path::new("go.mod")]);
+        assert!(!scan.file_tree.iter().any(|p| p.starts_with("vendor")));
+    }
+
+    const ARCHIVE_FILES: [(&str, &str); 4] = [
+        ("go.mod", "module example.com/app\n\ngo 1.21\n"),
+        ("main.go", "package main\n\nfunc main() {}\n"),
+        (
+            "web/package.json",
+            "{\"name\": \"web\", \"version\": \"1.0.0\"}",
+        ),
+        (
+            "vendor/github.com/acme/lib/go.mod",
+            "module github.com/acme/lib\n\ngo 1.21\n",
+        ),
+    ];
+
+    /// Keeps every record it receives
+    #[derive(Debug, Default)]
+    struct RecordingLogger {
+        records: std::sync::Mutex<Vec<(Level, String, Vec<(String, String)>)>>,
+    }
+
+    impl ScanLogger for RecordingLogger {
+        fn log(&self, level: Level, message: &str, fields: &[(&str, String)]) {
+            let fields = fields
+                .iter()
+                .map(|(key, value)| (key.to_string(), value.clone()))
+                .collect();
+            self.records
+                .lock()
+                .unwrap()
+                .push((level, message.to_string(), fields));
+        }
+    }
+
+    #[tokio::test]
+    async fn test_injected_logger_receives_scan_records() {
+        let temp_dir = TempDir::new().unwrap();
+        fs::write(
+            temp_dir.path().join("go.mod"),
+            "module example.com/app\n\ngo 1.21\n",
+        )
+        .unwrap();
+        let logger = Arc::new(RecordingLogger::default());
+        let phase = ScanPhase::with_config(ScanConfig {
+            logger: logger.clone(),
+            ..ScanConfig::default()
+        });
+        let mut context = create_test_context(temp_dir.path());
+        phase.execute(&mut context).await.unwrap();
+
+        let records = logger.records.lock().unwrap();
+        let (level, _, fields) = records
+            .iter()
+            .find(|(_, message, _)| message == "Detected language")
+            .expect("detection record");
+        assert_eq!(*level, Level::DEBUG);
+        assert!(fields.contains(&(
+            "reason".to_string(),
+            "go.mod is a go mod manifest".to_string()
+        )));
+        assert!(records
+            .iter()
+            .any(|(level, message, _)| *level == Level::INFO
+                && message == "Repository scan completed"));
+    }
+
+    fn assert_archive_scan(scan: &ScanResult) {
+        assert_eq!(scan.repo_path, PathBuf::new());
+        assert_eq!(
+            scan.file_tree,
+            vec![
+                PathBuf::from("go.mod"),
+                PathBuf::from("main.go"),
+                PathBuf::from("web/package.json"),
+            ]
+        );
+        let manifests: Vec<&Path> = scan
+            .detections
+            .iter()
+            .map(|d| d.manifest_path.as_path())
+            .collect();
+        assert_eq!(
+            manifests,
+            vec![Path::new("go.mod"), Path::new("web/package.json")]
+        );
+    }
+
+    async fn scan_file_system(fs: Arc<dyn FileSystem>) -> ScanResult {
+        let mut context = AnalysisContext::new(
+            Path::new(""),
+            Arc::new(StackRegistry::with_defaults(None)),
+            Arc::new(peelbox_wolfi::WolfiPackageIndex::for_tests()),
+            DetectionMode::StaticOnly,
+        )
+        .with_file_system(fs);
+        ScanPhase::with_config(ScanConfig::default())
+            .execute(&mut context)
+            .await
+            .unwrap();
+        context.scan.unwrap()
+    }
+
+    #[tokio::test]
+    async fn test_scan_tar_archive() {
+        let mut builder = tar::Builder::new(Vec::new());
+        for (path, content) in ARCHIVE_FILES {
+            let mut header = tar::Header::new_gnu();
+            header.set_size(content.len() as u64);
+            header.set_mode(0o644);
+            header.set_cksum();
+            builder
+                .append_data(&mut header, path, content.as_bytes())
+                .unwrap();
+        }
+        let archive = builder.into_inner().unwrap();
+
+        let fs = ArchiveFileSystem::read(archive.as_slice(), ArchiveFormat::Tar).unwrap();
+        assert_archive_scan(&scan_file_system(Arc::new(fs)).await);
+    }
+
+    #[tokio::test]
+    async fn test_scan_zip_archive() {
+        let mut writer = zip::ZipWriter::new(std::io::Cursor::new(Vec::new()));
+        for (path, content) in ARCHIVE_FILES {
+            writer
+                .start_file(path, zip::write::FileOptions::default())
+                .unwrap();
+            writer.write_all(content.as_bytes()).unwrap();
+        }
+        let archive = writer.finish().unwrap().into_inner();
+
+        let fs = ArchiveFileSystem::read(archive.as_slice(), ArchiveFormat::Zip).unwrap();
+        assert_archive_scan(&scan_file_system(Arc::new(fs)).await);
+    }
+
+    /// Minimal GitHub API v3 serving `ARCHIVE_FILES` at `HEAD` and `main`, recording each
+    /// request path with its Authorization header; downloads of the `failing` files answer
+    /// HTTP 500
+    fn mock_github_api(
+        failing: &'static [&'static str],
+    ) -> (String, Arc<std::sync::Mutex<Vec<String>>>) {
+        use std::io::{BufRead, BufReader};
+
+        let listener = std::net::TcpListener::bind("127.0.0.1:0").unwrap();
+        let api_base = format!("http://{}", listener.local_addr().unwrap());
+        let requests = Arc::new(std::sync::Mutex::new(Vec::new()));
+        let seen = Arc::clone(&requests);
+
+        std::thread::spawn(move || {
+            for stream in listener.incoming() {
+                let mut stream = stream.unwrap();
+                let mut reader = BufReader::new(stream.try_clone().unwrap());
+                let mut request_line = String::new();
+                reader.read_line(&mut request_line).unwrap();
+                let mut authorization = String::new();
+                loop {
+                    let mut header = String::new();
+                    reader.read_line(&mut header).unwrap();
+                    if header.trim().is_empty() {
+                        break;
+                    }
+                    if header.to_ascii_lowercase().starts_with("authorization:") {
+                        authorization = header.trim().to_string();
+                    }
+                }
+                let path = request_line.split_whitespace().nth(1).unwrap_or("");
+                seen.lock()
+                    .unwrap()
+                    .push(format!("{} {}", path, authorization));
+
+                let file = path
+                    .split('?')
+                    .next()
+                    .and_then(|path| path.strip_prefix("/repos/acme/app/contents/"));
+                let (status, body) = if path == "/repos/acme/app/git/trees/HEAD?recursive=1"
+                    || path == "/repos/acme/app/git/trees/main?recursive=1"
+                {
+                    let mut tree: Vec<serde_json::Value> = ARCHIVE_FILES
+                        .iter()
+                        .map(|(path, content)| {
+                            serde_json::json!({"path": path, "type": "blob", "size": content.len()})
+                        })
+                        .collect();
+                    tree.push(serde_json::json!({"path": "web", "type": "tree"}));
+                    let listing = serde_json::json!({"tree": tree, "truncated": false});
+                    ("200 OK", listing.to_string())
+                } else if file.is_some_and(|file| failing.contains(&file)) {
+                    ("500 Internal Server Error", String::new())
+                } else if let Some((_, content)) =
+                    file.and_then(|file| ARCHIVE_FILES.iter().find(|(path, _)| *path == file))
+                {
+                    ("200 OK", content.to_string())
+                } else {
+                    ("404 Not Found", String::new())
+                };
+                write!(
+                    stream,
+                    "HTTP/1.1 {}\r\nContent-Length: {}\r\nConnection: close\r\n\r\n{}",
+                    status,
+                    body.len(),
+                    body
+                )
+                .unwrap();
+            }
+        });
+        (api_base, requests)
+    }
+
+    #[tokio::test]
+    async fn test_scan_remote_github() {
+        let (api_base, requests) = mock_github_api(&[]);
+        let repository = RemoteRepository::parse("https://github.com/acme/app", &[])
+            .unwrap()
+            .with_api_base(api_base);
+
+        let fs = RemoteFileSystem::connect(repository, Some("secret")).unwrap();
+        assert_archive_scan(&scan_file_system(Arc::new(fs)).await);
+
+        let requests = requests.lock().unwrap();
+        assert_eq!(
+            requests[0],
+            "/repos/acme/app/git/trees/HEAD?recursive=1 authorization: Bearer secret"
+        );
+        assert!(requests.iter().all(|r| r.ends_with("Bearer secret")));
+        assert!(requests
+            .iter()
+            .any(|r| r.starts_with("/repos/acme/app/contents/go.mod ")));
+        // Excluded directories are never downloaded
+        assert!(!requests.iter().any(|r| r.contains("vendor")));
+    }
+
+    #[tokio::test]
+    async fn test_scan_remote_subdirectory() {
+        let (api_base, requests) = mock_github_api(&[]);
+        let repository = RemoteRepository::parse("https://github.com/acme/app/tree/main/web", &[])
+            .unwrap()
+            .with_api_base(api_base);
+        assert_eq!(repository.reference.as_deref(), Some("main/web"));
+
+        // No branch is named main/web, so main is the reference and web the directory
+        let fs = Arc::new(RemoteFileSystem::connect(repository, None).unwrap());
+        assert_eq!(
+            fs.read_to_string(Path::new("package.json")).unwrap(),
+            ARCHIVE_FILES[2].1
+        );
+        let scan = scan_file_system(fs).await;
+        assert_eq!(scan.file_tree, vec![PathBuf::from("package.json")]);
+
+        let requests = requests.lock().unwrap();
+        assert!(requests
+            .iter()
+            .any(|r| r.starts_with("/repos/acme/app/git/trees/main%2Fweb?recursive=1 ")));
+        assert!(requests
+            .iter()
+            .any(|r| r.starts_with("/repos/acme/app/contents/web/package.json?ref=main ")));
+    }
+
+    #[tokio::test]
+    async fn test_scan_remote_tolerates_failed_reads() {
+        let (api_base, _) = mock_github_api(&["web/package.json"]);
+        let repository = RemoteRepository::parse("https://github.com/acme/app", &[])
+            .unwrap()
+            .with_api_base(api_base);
+
+        let fs = RemoteFileSystem::connect(repository, None).unwrap();
+        let scan = scan_file_system(Arc::new(fs)).await;
+        assert!(scan
+            .detections
+            .iter()
+            .any(|d| d.manifest_path == Path::new("go.mod")));
+    }
+
+    struct BrainfuckLanguage;
+
+    impl peelbox_stack::LanguageDefinition for BrainfuckLanguage {
+        fn id(&self) -> peelbox_stack::LanguageId {
+            peelbox_stack::LanguageId::Custom("brainfuck".to_string())
+        }
+
+        fn extensions(&self) -> Vec<String> {
+            vec!["bf".to_string()]
+        }
+
+        fn detect(
+            &self,
+            manifest_name: &str,
+            _manifest_content: Option<&str>,
+        ) -> Option<peelbox_stack::DetectionResult> {
+            (manifest_name == "Brainfile").then(|| peelbox_stack::DetectionResult {
+                build_system: peelbox_stack::BuildSystemId::Custom("bfc".to_string()),
+                confidence: 1.0,
+            })
+        }
+
+        fn compatible_build_systems(&self) -> Vec<String> {
+            vec!["bfc".to_string()]
+        }
+    }
+
+    struct BfcBuildSystem;
+
+    impl peelbox_stack::BuildSystem for BfcBuildSystem {
+        fn id(&self) -> peelbox_stack::BuildSystemId {
+            peelbox_stack::BuildSystemId::Custom("bfc".to_string())
+        }
+
+        fn manifest_patterns(&self) -> Vec<peelbox_stack::ManifestPattern> {
+            vec![peelbox_stack::ManifestPattern {
+                filename: "Brainfile".to_string(),
+                priority: 10,
+            }]
+        }
+
+        fn detect_all(
+            &self,
+            _repo_root: &Path,
+            file_tree: &[PathBuf],
+            _fs: &dyn FileSystem,
+        ) -> Result<Vec<DetectionStack>> {
+            Ok(file_tree
+                .iter()
+                .filter(|path| path.file_name() == Some(std::ffi::OsStr::new("Brainfile")))
+                .map(|path| {
+                    DetectionStack::new(
+                        self.id(),
+                        peelbox_stack::LanguageId::Custom("brainfuck".to_string()),
+                        path.clone(),
+                    )
+                })
+                .collect())
+        }
+
+        fn build_template(
+            &self,
+            _wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+            _service_path: &Path,
+            _manifest_content: Option<&str>,
+        ) -> peelbox_stack::BuildTemplate {
+            peelbox_stack::BuildTemplate {
+                build_packages: vec![],
+                build_commands: vec!["bfc main.bf -o app".to_string()],
+                cache_paths: vec![],
+                common_ports: vec![],
+                build_env: HashMap::new(),
+                runtime_copy: vec![],
+                runtime_env: HashMap::new(),
+                runtime_auxiliary_commands: vec![],
+            }
+        }
+
+        fn cache_dirs(&self) -> Vec<String> {
+            vec![]
+        }
+    }
+
+    #[tokio::test]
+    async fn test_registered_detector_runs_during_scan() {
+        use peelbox_stack::{BuildSystemId, LanguageDefinition, LanguageId};
+
+        peelbox_stack::register_language(Arc::new(BrainfuckLanguage));
+        peelbox_stack::register_build_system(Arc::new(BfcBuildSystem));
+
+        let temp_dir = TempDir::new().unwrap();
+        let base = temp_dir.path();
+        fs::write(base.join("Brainfile"), "entry = \"main.bf\"\n").unwrap();
+        fs::write(
+            base.join("main.bf"),
+            "++++++++[>++++[>++>+++<<-]>+<<-]>>.\n",
+        )
+        .unwrap();
+
+        let mut context = create_test_context(base);
+        ScanPhase::default().execute(&mut context).await.unwrap();
+
+        let scan = context.scan.as_ref().unwrap();
+        assert_eq!(scan.detections.len(), 1);
+        let detection = &scan.detections[0];
+        assert_eq!(
+            detection.build_system,
+            BuildSystemId::Custom("bfc".to_string())
+        );
+        assert_eq!(
+            detection.language,
+            LanguageId::Custom("brainfuck".to_string())
+        );
+        assert_eq!(detection.manifest_path, PathBuf::from("Brainfile"));
+        assert!(context
+            .stack_registry
+            .get_language(BrainfuckLanguage.id())
+            .is_some());
+    }
+
+    fn create_large_repo(packages: usize) -> TempDir {
+        let dir = TempDir::new().unwrap();
+        for i in 0..packages {
+            let package = dir.path().join(format!("packages/pkg-{:03}", i));
+            fs::create_dir_all(package.join("src")).unwrap();
+            fs::write(
+                package.join("package.json"),
+                format!("{{\"name\": \"pkg-{:03}\", \"version\": \"1.0.0\"}}", i),
+            )
+            .unwrap();
+            fs::write(package.join("src/index.js"), "module.exports = {};\n").unwrap();
+        }
+        dir
+    }
+
+    async fn scan_with_workers(repo: &Path, workers: usize) -> ScanResult {
+        let phase = ScanPhase::with_config(ScanConfig {
+            max_files: 10_000,
+            workers,
+            ..ScanConfig::default()
+        });
+        let mut context = create_test_context(repo);
+        phase.execute(&mut context).await.unwrap();
+        context.scan.unwrap()
+    }
+
+    #[tokio::test]
+    async fn test_parallel_scan_is_deterministic() {
+        let temp_dir = create_large_repo(50);
+        let sequential = scan_with_workers(temp_dir.path(), 1).await;
+        let parallel = scan_with_workers(temp_dir.path(), 8).await;
+
+        assert_eq!(sequential.file_tree.len(), 100);
+        assert_eq!(sequential.file_tree, parallel.file_tree);
+        let manifests = |scan: &ScanResult| -> Vec<PathBuf> {
+            scan.detections
+                .iter()
+                .map(|d| d.manifest_path.clone())
+                .collect()
+        };
+        assert_eq!(manifests(&sequential), manifests(&parallel));
+    }
+
+    #[tokio::test]
+    async fn test_file_limit_keeps_root_manifests() {
+        let temp_dir = create_large_repo(10);
+        fs::write(temp_dir.path().join("README.md"), "# repo\n").unwrap();
+        fs::write(
+            temp_dir.path().join("package.json"),
+            r#"{"name": "root", "version": "1.0.0", "workspaces": ["packages/*"]}"#,
+        )
+        .unwrap();
+
+        for workers in [1, 4] {
+            let phase = ScanPhase::with_config(ScanConfig {
+                max_files: 5,
+                workers,
+                ..ScanConfig::default()
+            });
+            let mut context = create_test_context(temp_dir.path());
+            phase.execute(&mut context).await.unwrap();
+
+            let scan = context.scan.as_ref().unwrap();
+            assert_eq!(
+                scan.file_tree,
+                vec![
+                    PathBuf::from("README.md"),
+                    PathBuf::from("package.json"),
+                    PathBuf::from("packages/pkg-000/package.json"),
+                    PathBuf::from("packages/pkg-001/package.json"),
+                    PathBuf::from("packages/pkg-002/package.json"),
+                ],
+                "workers {}",
+                workers
+            );
+            assert!(scan
+                .detections
+                .iter()
+                .any(|d| d.manifest_path == Path::new("package.json")));
+        }
+    }
+
+    #[test]
+    fn test_file_limit_ranks_manifests_before_other_files() {
+        let registry = StackRegistry::with_defaults(None);
+        let config = ScanConfig {
+            max_files: 2,
+            ..ScanConfig::default()
+        };
+        let file_tree = vec![
+            PathBuf::from("a.txt"),
+            PathBuf::from("b.txt"),
+            PathBuf::from("go.mod"),
+            PathBuf::from("App.csproj"),
+        ];
+        assert_eq!(
+            limit_file_tree(file_tree, &registry, &config),
+            vec![PathBuf::from("App.csproj"), PathBuf::from("go.mod")]
+        );
+    }
+
+    async fn scan_with_max_depth(repo: &Path, max_depth: usize) -> ScanResult {
+        let phase = ScanPhase::with_config(ScanConfig {
+            max_depth,
+            ..ScanConfig::default()
+        });
+        let mut context = create_test_context(repo);
+        phase.execute(&mut context).await.unwrap();
+        context.scan.unwrap()
+    }
+
+    #[tokio::test]
+    async fn test_max_depth_prunes_deeper_directories() {
+        let dir = TempDir::new().unwrap();
+        let service = dir.path().join("services/billing/api");
+        fs::create_dir_all(&service).unwrap();
+        fs::write(
+            service.join("go.mod"),
+            "module example.com/api\n\ngo 1.22\n",
+        )
+        .unwrap();
+        fs::write(service.join("main.go"), "package main\n").unwrap();
+
+        for max_depth in [0, 3] {
+            let scan = scan_with_max_depth(dir.path(), max_depth).await;
+            assert_eq!(scan.detections.len(), 1, "max_depth {}", max_depth);
+            assert_eq!(
+                scan.detections[0].manifest_path,
+                PathBuf::from("services/billing/api/go.mod")
+            );
+            assert_eq!(scan.detections[0].depth, 3);
+        }
+
+        let scan = scan_with_max_depth(dir.path(), 2).await;
+        assert!(scan.detections.is_empty());
+        assert!(scan.file_tree.is_empty());
+    }
+
+    #[tokio::test]
+    async fn test_unlimited_depth_keeps_default_excludes() {
+        let dir = TempDir::new().unwrap();
+        let base = dir.path();
+        fs::write(base.join("go.mod"), "module example.com/app\n\ngo 1.22\n").unwrap();
+        fs::create_dir_all(base.join("vendor/github.com/lib/pq")).unwrap();
+        fs::write(
+            base.join("vendor/github.com/lib/pq/go.mod"),
+            "module github.com/lib/pq\n",
+        )
+        .unwrap();
+        fs::create_dir_all(base.join("web/node_modules/left-pad")).unwrap();
+        fs::write(
+            base.join("web/node_modules/left-pad/package.json"),
+            r#"{"name": "left-pad", "version": "1.3.0"}"#,
+        )
+        .unwrap();
+
+        let scan = scan_with_max_depth(base, 0).await;
+        assert_eq!(scan.file_tree, vec![PathBuf::from("go.mod")]);
+        assert_eq!(scan.detections.len(), 1);
+    }
+
+    /// Benchmark: `cargo test -p peelbox-pipeline --release bench_scan_large_repo -- --ignored --nocapture`
+    #[tokio::test]
+    #[ignore]
+    async fn bench_scan_large_repo() {
+        let temp_dir = create_large_repo(500);
+        let workers = ScanConfig::default().workers;
+
+        let start = Instant::now();
+        let sequential = scan_with_workers(temp_dir.path(), 1).await;
+        let sequential_time = start.elapsed();
+
+        let start = Instant::now();
+        let parallel = scan_with_workers(temp_dir.path(), workers).await;
+        let parallel_time = start.elapsed();
+
+        assert_eq!(sequential.file_tree, parallel.file_tree);
+        println!(
+            "scan of 500 packages: 1 worker {:?}, {} workers {:?}",
+            sequential_time, workers, parallel_time
+        );
+    }
 }
@@ -1,8 +1,10 @@
 use super::scan::ScanResult;
+use crate::extractors::PnpmWorkspaceDetector;
 use crate::pipeline::context::AnalysisContext;
 use crate::pipeline::phase_trait::WorkflowPhase;
 use anyhow::Result;
 use async_trait::async_trait;
+use peelbox_core::fs::FileSystem;
 use peelbox_stack::orchestrator::{Package, WorkspaceStructure};
 use peelbox_stack::StackRegistry;
 
@@ -20,8 +22,12 @@ impl WorkflowPhase for WorkspaceStructurePhase {
             .as_ref()
             .expect("Scan must be available before WorkspaceStructurePhase");
 
-        let workspace_structure =
-            detect_workspace_structure(&context.repo_path, scan, &context.stack_registry)?;
+        let workspace_structure = detect_workspace_structure(
+            &context.repo_path,
+            scan,
+            &context.stack_registry,
+            context.fs.as_ref(),
+        )?;
         context.workspace = Some(workspace_structure);
         Ok(())
     }
@@ -31,22 +37,24 @@ fn extract_package_metadata(
     detection: &peelbox_stack::DetectionStack,
     repo_path: &std::path::Path,
     stack_registry: &StackRegistry,
+    fs: &dyn FileSystem,
 ) -> (String, bool) {
     stack_registry
         .get_build_system(detection.build_system.clone())
         .and_then(|bs| {
             let manifest_path = repo_path.join(&detection.manifest_path);
-            std::fs::read_to_string(&manifest_path)
+            fs.read_to_string(&manifest_path)
                 .ok()
                 .and_then(|content| bs.parse_package_metadata(&content).ok())
         })
         .unwrap_or_else(|| ("app".to_string(), true))
 }
 
-fn is_workspace_root_manifest(
+pub(crate) fn is_workspace_root_manifest(
     detection: &peelbox_stack::DetectionStack,
     repo_path: &std::path::Path,
     stack_registry: &StackRegistry,
+    fs: &dyn FileSystem,
 ) -> bool {
     // Check if manifest is at repo root (parent is empty path)
     let parent = detection
@@ -58,7 +66,7 @@ fn is_workspace_root_manifest(
     }
 
     let manifest_path = repo_path.join(&detection.manifest_path);
-    let Ok(content) = std::fs::read_to_string(&manifest_path) else {
+    let Ok(content) = fs.read_to_string(&manifest_path) else {
         return false;
     };
 
@@ -72,6 +80,7 @@ fn create_package(
     detection: &peelbox_stack::DetectionStack,
     repo_path: &std::path::Path,
     stack_registry: &StackRegistry,
+    fs: &dyn FileSystem,
 ) -> Package {
     let service_path = detection
         .manifest_path
@@ -79,7 +88,7 @@ fn create_package(
         .unwrap_or(repo_path)
         .to_path_buf();
 
-    let (name, is_application) = extract_package_metadata(detection, repo_path, stack_registry);
+    let (name, is_application) = extract_package_metadata(detection, repo_path, stack_registry, fs);
 
     Package {
         path: service_path,
@@ -88,50 +97,58 @@ fn create_package(
     }
 }
 
-fn try_workspace_build_system(
+pub(crate) fn workspace_member_paths(
     detection: &peelbox_stack::DetectionStack,
     repo_path: &std::path::Path,
     stack_registry: &StackRegistry,
-) -> Result<Option<WorkspaceStructure>> {
+    fs: &dyn FileSystem,
+) -> Result<Vec<std::path::PathBuf>> {
     let manifest_path = repo_path.join(&detection.manifest_path);
-    let Ok(manifest_content) = std::fs::read_to_string(&manifest_path) else {
-        return Ok(None);
+    let Ok(manifest_content) = fs.read_to_string(&manifest_path) else {
+        return Ok(vec![]);
     };
 
     let Some(build_system) = stack_registry.get_build_system(detection.build_system.clone()) else {
-        return Ok(None);
+        return Ok(vec![]);
     };
 
-    let workspace_patterns = build_system.parse_workspace_patterns(&manifest_content)?;
+    let mut members = Vec::new();
 
-    if workspace_patterns.is_empty() {
-        return Ok(None);
+    for pattern in build_system.parse_workspace_patterns(&manifest_content)? {
+        for package_path in build_system.glob_workspace_pattern(repo_path, &pattern, fs)? {
+            let relative_path = package_path
+                .strip_prefix(repo_path)
+                .unwrap_or(&package_path)
+                .to_path_buf();
+            members.push(relative_path);
+        }
     }
 
-    let mut packages = Vec::new();
-
-    for pattern in workspace_patterns {
-        let paths = build_system.glob_workspace_pattern(repo_path, &pattern)?;
+    Ok(members)
+}
 
-        for package_path in paths {
-            let name = package_path
+fn try_workspace_build_system(
+    detection: &peelbox_stack::DetectionStack,
+    repo_path: &std::path::Path,
+    stack_registry: &StackRegistry,
+    fs: &dyn FileSystem,
+) -> Result<Option<WorkspaceStructure>> {
+    let packages: Vec<Package> = workspace_member_paths(detection, repo_path, stack_registry, fs)?
+        .into_iter()
+        .map(|relative_path| {
+            let name = relative_path
                 .file_name()
                 .and_then(|n| n.to_str())
                 .unwrap_or("unknown")
                 .to_string();
 
-            let relative_path = package_path
-                .strip_prefix(repo_path)
-                .unwrap_or(&package_path)
-                .to_path_buf();
-
-            packages.push(Package {
+            Package {
                 path: relative_path,
                 name,
                 is_application: true,
-            });
-        }
-    }
+            }
+        })
+        .collect();
 
     if packages.is_empty() {
         Ok(None)
@@ -143,46 +160,109 @@ fn try_workspace_build_system(
     }
 }
 
+/// Adds detected modules the workspace config doesn't list; `skip_root` leaves out the root
+/// manifest when it only drives the workspace
+fn include_standalone_packages(
+    workspace_structure: &mut WorkspaceStructure,
+    repo_path: &std::path::Path,
+    scan: &ScanResult,
+    stack_registry: &StackRegistry,
+    skip_root: bool,
+    fs: &dyn FileSystem,
+) {
+    let workspace_paths: std::collections::HashSet<_> = workspace_structure
+        .packages
+        .iter()
+        .map(|p| p.path.clone())
+        .collect();
+
+    let standalone_packages: Vec<Package> = scan
+        .detections
+        .iter()
+        .filter(|d| !is_workspace_root_manifest(d, repo_path, stack_registry, fs))
+        .filter(|d| {
+            !skip_root
+                || d.manifest_path
+                    .parent()
+                    .is_some_and(|p| !p.as_os_str().is_empty())
+        })
+        .map(|d| create_package(d, repo_path, stack_registry, fs))
+        .filter(|p| !workspace_paths.contains(&p.path))
+        .collect();
+
+    workspace_structure.packages.extend(standalone_packages);
+}
+
 fn detect_workspace_structure(
     repo_path: &std::path::Path,
     scan: &ScanResult,
     stack_registry: &StackRegistry,
+    fs: &dyn FileSystem,
 ) -> Result<WorkspaceStructure> {
     for orchestrator in stack_registry.all_orchestrators() {
         for config_file in orchestrator.config_files() {
+            // Scan paths are repo-relative, so a root config file has an empty parent
             if scan
                 .find_files_by_name(&config_file)
                 .iter()
-                .any(|f| f.parent().unwrap_or(repo_path) == repo_path)
+                .any(|f| f.parent().is_some_and(|p| p.as_os_str().is_empty()))
             {
-                if let Ok(structure) = orchestrator.workspace_structure(repo_path) {
+                if let Ok(mut structure) = orchestrator.workspace_structure(repo_path, fs) {
+                    for package in &mut structure.packages {
+                        if let Ok(relative) = package.path.strip_prefix(repo_path) {
+                            package.path = relative.to_path_buf();
+                        }
+                    }
+                    // An orchestrator's root package.json only drives the workspace
+                    include_standalone_packages(
+                        &mut structure,
+                        repo_path,
+                        scan,
+                        stack_registry,
+                        true,
+                        fs,
+                    );
                     return Ok(structure);
                 }
             }
         }
     }
 
+    if let Some(pnpm) = PnpmWorkspaceDetector::detect(repo_path, &scan.file_tree, fs) {
+        let packages: Vec<Package> = pnpm
+            .members
+            .iter()
+            .filter_map(|member| {
+                scan.detections
+                    .iter()
+                    .find(|d| d.manifest_path.parent() == Some(member.as_path()))
+            })
+            .map(|d| create_package(d, repo_path, stack_registry, fs))
+            .collect();
+        if !packages.is_empty() {
+            let mut structure = WorkspaceStructure {
+                orchestrator: None,
+                packages,
+            };
+            // The root package.json of a pnpm workspace only holds shared scripts and tooling
+            include_standalone_packages(&mut structure, repo_path, scan, stack_registry, true, fs);
+            return Ok(structure);
+        }
+    }
+
     for detection in &scan.detections {
-        if is_workspace_root_manifest(detection, repo_path, stack_registry) {
+        if is_workspace_root_manifest(detection, repo_path, stack_registry, fs) {
             if let Some(mut workspace_structure) =
-                try_workspace_build_system(detection, repo_path, stack_registry)?
+                try_workspace_build_system(detection, repo_path, stack_registry, fs)?
             {
-                // Also include standalone modules not in workspace
-                let workspace_paths: std::collections::HashSet<_> = workspace_structure
-                    .packages
-                    .iter()
-                    .map(|p| p.path.clone())
-                    .collect();
-
-                let standalone_packages: Vec<Package> = scan
-                    .detections
-                    .iter()
-                    .filter(|d| !is_workspace_root_manifest(d, repo_path, stack_registry))
-                    .map(|d| create_package(d, repo_path, stack_registry))
-                    .filter(|p| !workspace_paths.contains(&p.path))
-                    .collect();
-
-                workspace_structure.packages.extend(standalone_packages);
+                include_standalone_packages(
+                    &mut workspace_structure,
+                    repo_path,
+                    scan,
+                    stack_registry,
+                    false,
+                    fs,
+                );
                 return Ok(workspace_structure);
             }
         }
@@ -191,12 +271,12 @@ fn detect_workspace_structure(
     let packages: Vec<Package> = scan
         .detections
         .iter()
-        .filter(|d| !is_workspace_root_manifest(d, repo_path, stack_registry))
-        .map(|d| create_package(d, repo_path, stack_registry))
+        .filter(|d| !is_workspace_root_manifest(d, repo_path, stack_registry, fs))
+        .map(|d| create_package(d, repo_path, stack_registry, fs))
         .collect();
 
     if packages.is_empty() && !scan.detections.is_empty() {
-        let package = create_package(&scan.detections[0], repo_path, stack_registry);
+        let package = create_package(&scan.detections[0], repo_path, stack_registry, fs);
         return Ok(WorkspaceStructure {
             orchestrator: None,
             packages: vec![package],
@@ -212,6 +292,7 @@ fn detect_workspace_structure(
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::RealFileSystem;
     use peelbox_stack::BuildSystemId;
     use peelbox_stack::LanguageId;
     use std::collections::BTreeMap;
@@ -247,7 +328,9 @@ mod tests {
         };
 
         let registry = StackRegistry::with_defaults(None);
-        let workspace = detect_workspace_structure(&PathBuf::from("."), &scan, &registry).unwrap();
+        let workspace =
+            detect_workspace_structure(&PathBuf::from("."), &scan, &registry, &RealFileSystem)
+                .unwrap();
         assert_eq!(workspace.packages.len(), 1);
         assert_eq!(workspace.packages[0].name, "app");
         assert!(workspace.packages[0].is_application);
@@ -2,8 +2,12 @@ use crate::pipeline::phase_trait::ServicePhase;
 use crate::pipeline::service_context::{ServiceContext, Stack};
 use anyhow::Result;
 use async_trait::async_trait;
+use peelbox_core::fs::FileSystem;
+use peelbox_stack::buildsystem::bazel::native_manifest;
+use peelbox_stack::buildsystem::cabal::package_description;
+use peelbox_stack::buildsystem::deno::DenoConfig;
 use peelbox_stack::{FrameworkId, LanguageId, RuntimeId, StackRegistry};
-use std::path::PathBuf;
+use std::path::{Path, PathBuf};
 use std::sync::Arc;
 
 pub struct StackIdentificationPhase;
@@ -22,6 +26,7 @@ impl ServicePhase for StackIdentificationPhase {
             &context.service.manifest,
             context.repo_path(),
             context.stack_registry(),
+            context.fs(),
         ) {
             context.stack = Some(stack);
         }
@@ -36,6 +41,7 @@ fn try_detect_stack(
     manifest_name: &str,
     repo_path: &std::path::Path,
     stack_registry: &Arc<StackRegistry>,
+    fs: &dyn FileSystem,
 ) -> Option<Stack> {
     let language_def = stack_registry.get_language(language.clone())?;
 
@@ -46,8 +52,13 @@ fn try_detect_stack(
     } else {
         RuntimeId::Native
     };
+    // Bun replaces Node as the runtime, not just as the package manager
+    let runtime = match build_system {
+        peelbox_stack::BuildSystemId::Bun => RuntimeId::Bun,
+        _ => runtime,
+    };
 
-    let framework = detect_framework(service_path, manifest_name, repo_path, stack_registry);
+    let framework = detect_framework(service_path, manifest_name, repo_path, stack_registry, fs);
 
     Some(Stack {
         language,
@@ -63,13 +74,16 @@ fn detect_framework(
     manifest_name: &str,
     repo_path: &std::path::Path,
     stack_registry: &Arc<StackRegistry>,
+    fs: &dyn FileSystem,
 ) -> Option<FrameworkId> {
-    let manifest_path = repo_path.join(service_path).join(manifest_name);
-    let manifest_content = std::fs::read_to_string(&manifest_path).ok()?;
+    let service_dir = repo_path.join(service_path);
+    let manifest_name = dependency_manifest(&service_dir, manifest_name, fs);
+    let manifest_path = service_dir.join(&manifest_name);
+    let manifest_content = fs.read_to_string(&manifest_path).ok()?;
 
     // Parse dependencies from manifest using stack registry
     let dep_info = stack_registry.parse_dependencies_by_manifest(
-        manifest_name,
+        &manifest_name,
         &manifest_content,
         std::slice::from_ref(service_path),
     )?;
@@ -91,10 +105,40 @@ fn detect_framework(
     None
 }
 
+/// Lockfiles and bunfig.toml don't list direct dependencies; read the package.json next to
+/// them instead.
+/// stack.yaml only names packages, whose package.yaml or .cabal file holds the dependencies.
+/// A Deno config that points at an import map keeps its imports in that file.
+/// Bazel workspaces list dependencies in the go.mod, pom.xml or Python manifest beside them.
+fn dependency_manifest(service_dir: &Path, manifest_name: &str, fs: &dyn FileSystem) -> String {
+    match manifest_name {
+        "package-lock.json" | "yarn.lock" | "pnpm-lock.yaml" | "bun.lockb" | "bun.lock"
+        | "bunfig.toml" => "package.json".to_string(),
+        "stack.yaml" => {
+            package_description(service_dir, fs).unwrap_or_else(|| manifest_name.to_string())
+        }
+        "deno.json" | "deno.jsonc" | "deno.lock" => {
+            let config_name = ["deno.json", "deno.jsonc"]
+                .into_iter()
+                .find(|name| fs.is_file(&service_dir.join(name)))
+                .unwrap_or(manifest_name);
+            DenoConfig::read(service_dir, fs)
+                .and_then(|config| config.import_map)
+                .filter(|import_map| fs.is_file(&service_dir.join(import_map)))
+                .unwrap_or_else(|| config_name.to_string())
+        }
+        "MODULE.bazel" | "WORKSPACE.bazel" | "WORKSPACE" => {
+            native_manifest(service_dir, fs).unwrap_or_else(|| manifest_name.to_string())
+        }
+        other => other.to_string(),
+    }
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
     use crate::pipeline::phases::service_analysis::Service;
+    use peelbox_core::fs::RealFileSystem;
 
     #[test]
     fn test_detect_stack_rust() {
@@ -115,6 +159,7 @@ mod tests {
             &service.manifest,
             &repo_path,
             &stack_registry,
+            &RealFileSystem,
         )
         .unwrap();
 
@@ -143,6 +188,7 @@ mod tests {
             &service.manifest,
             &repo_path,
             &stack_registry,
+            &RealFileSystem,
         )
         .unwrap();
 
@@ -183,6 +229,7 @@ mod tests {
             &service.manifest,
             temp_dir.path(),
             &stack_registry,
+            &RealFileSystem,
         )
         .unwrap();
 
@@ -190,4 +237,26 @@ mod tests {
         assert_eq!(stack.runtime, RuntimeId::Node);
         assert_eq!(stack.framework, Some(FrameworkId::Express));
     }
+
+    #[test]
+    fn test_detect_framework_from_package_json_next_to_lockfile() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("package.json"),
+            r#"{"name": "web", "dependencies": {"next": "14.1.0"}}"#,
+        )
+        .unwrap();
+        std::fs::write(dir.path().join("yarn.lock"), "# yarn lockfile v1\n").unwrap();
+
+        let stack_registry = Arc::new(StackRegistry::with_defaults(None));
+        let framework = detect_framework(
+            &PathBuf::from("."),
+            "yarn.lock",
+            dir.path(),
+            &stack_registry,
+            &RealFileSystem,
+        );
+
+        assert_eq!(framework, Some(FrameworkId::NextJs));
+    }
 }
@@ -1,22 +1,92 @@
 use super::service_analysis::Service;
+use crate::extractors::parsers::makefile::MakefileParser;
 use crate::pipeline::Confidence;
 use anyhow::Result;
+use peelbox_core::fs::FileSystem;
 use peelbox_stack::StackRegistry;
 use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
 use std::path::PathBuf;
 use std::sync::Arc;
 
+/// Where the build commands come from
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum BuildCommandSource {
+    /// The build system's own template (e.g., `go build`, `cargo build`)
+    #[default]
+    Native,
+    /// The `build` target of the service Makefile
+    Makefile,
+}
+
+impl BuildCommandSource {
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            BuildCommandSource::Native => "native",
+            BuildCommandSource::Makefile => "makefile",
+        }
+    }
+}
+
 #[derive(Debug, Clone, Serialize, Deserialize)]
 pub struct BuildInfo {
     pub build_cmd: Vec<String>,
     pub output_dir: Option<PathBuf>,
     pub confidence: Confidence,
+    #[serde(default)]
+    pub source: BuildCommandSource,
+    /// Recipes of the Makefile's candidate targets (build, run, test, ...)
+    #[serde(default)]
+    pub makefile_targets: BTreeMap<String, Vec<String>>,
+    /// Binary the Makefile build writes (`go build -o <artifact>`), replacing the template's
+    #[serde(default)]
+    pub artifact: Option<String>,
+}
+
+/// Applies a Makefile found next to the manifest
+///
+/// A `build` target takes precedence over the build system's native commands: projects that
+/// ship one usually wrap flags, code generation or ldflags the template cannot know about, so
+/// the build becomes `make build`. Without a `build` target the native commands stay, and the
+/// other targets are only recorded as candidates.
+fn apply_makefile(info: &mut BuildInfo, targets: BTreeMap<String, Vec<String>>) {
+    if let Some(recipe) = targets.get("build") {
+        info.build_cmd = vec!["make build".to_string()];
+        info.source = BuildCommandSource::Makefile;
+        info.artifact = recipe.iter().rev().find_map(|cmd| go_build_output(cmd));
+        if let Some(artifact) = &info.artifact {
+            info.output_dir = PathBuf::from(artifact)
+                .parent()
+                .filter(|dir| !dir.as_os_str().is_empty())
+                .map(PathBuf::from);
+        }
+    }
+    info.makefile_targets = targets;
+}
+
+/// Output path of a `go build -o <path>` command
+fn go_build_output(command: &str) -> Option<String> {
+    if !command.contains("go build") {
+        return None;
+    }
+    let mut words = command.split_whitespace();
+    while let Some(word) = words.next() {
+        if word == "-o" {
+            return words.next().map(String::from);
+        }
+        if let Some(path) = word.strip_prefix("-o=") {
+            return Some(path.to_string());
+        }
+    }
+    None
 }
 
 fn try_deterministic(
     service: &Service,
     stack_registry: &Arc<StackRegistry>,
     repo_path: &std::path::Path,
+    fs: &dyn FileSystem,
 ) -> Option<BuildInfo> {
     let build_system = stack_registry.get_build_system(service.build_system.clone())?;
 
@@ -24,10 +94,10 @@ fn try_deterministic(
 
     let service_path = repo_path.join(&service.path);
     let manifest_path = service_path.join(&service.manifest);
-    let manifest_content = std::fs::read_to_string(&manifest_path).ok();
+    let manifest_content = fs.read_to_string(&manifest_path).ok();
 
     let template =
-        build_system.build_template(&wolfi_index, &service_path, manifest_content.as_deref());
+        build_system.build_template(&wolfi_index, &service_path, manifest_content.as_deref(), fs);
 
     let build_cmd = template.build_commands.clone();
     let output_dir = template.runtime_copy.first().map(|(from, _)| {
@@ -47,11 +117,20 @@ fn try_deterministic(
         }
     });
 
-    Some(BuildInfo {
+    let mut info = BuildInfo {
         build_cmd,
         output_dir,
         confidence: Confidence::High,
-    })
+        source: BuildCommandSource::Native,
+        makefile_targets: BTreeMap::new(),
+        artifact: None,
+    };
+
+    if let Ok(content) = fs.read_to_string(&service_path.join("Makefile")) {
+        apply_makefile(&mut info, MakefileParser::parse(&content));
+    }
+
+    Some(info)
 }
 
 use crate::pipeline::phase_trait::ServicePhase;
@@ -84,6 +163,7 @@ impl ServicePhase for BuildPhase {
             &context.service,
             context.stack_registry(),
             context.repo_path(),
+            context.fs(),
         ) {
             context.build = Some(deterministic);
         }
@@ -94,6 +174,7 @@ impl ServicePhase for BuildPhase {
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::RealFileSystem;
 
     #[test]
     fn test_deterministic_cargo() {
@@ -105,7 +186,8 @@ mod tests {
             .unwrap();
 
         let service_path = std::path::PathBuf::from("/tmp/test");
-        let template = build_system.build_template(&wolfi_index, &service_path, None);
+        let template =
+            build_system.build_template(&wolfi_index, &service_path, None, &RealFileSystem);
 
         assert_eq!(
             template.build_commands.first(),
@@ -124,7 +206,8 @@ mod tests {
             .unwrap();
 
         let service_path = std::path::PathBuf::from("/tmp/test");
-        let template = build_system.build_template(&wolfi_index, &service_path, None);
+        let template =
+            build_system.build_template(&wolfi_index, &service_path, None, &RealFileSystem);
 
         assert_eq!(
             template.build_commands.first(),
@@ -132,4 +215,45 @@ mod tests {
         );
         assert!(!template.runtime_copy.is_empty());
     }
+
+    fn native_go_build() -> BuildInfo {
+        BuildInfo {
+            build_cmd: vec![
+                "go mod download".to_string(),
+                "go build -o app .".to_string(),
+            ],
+            output_dir: Some(PathBuf::from("app")),
+            confidence: Confidence::High,
+            source: BuildCommandSource::Native,
+            makefile_targets: BTreeMap::new(),
+            artifact: None,
+        }
+    }
+
+    #[test]
+    fn test_makefile_build_target_overrides_native() {
+        let mut info = native_go_build();
+        let targets = MakefileParser::parse(
+            "build:\n\tgo generate ./...\n\tgo build -ldflags \"-s\" -o bin/server ./cmd/server\n\ntest:\n\tgo test ./...\n",
+        );
+
+        apply_makefile(&mut info, targets);
+
+        assert_eq!(info.build_cmd, vec!["make build"]);
+        assert_eq!(info.source, BuildCommandSource::Makefile);
+        assert_eq!(info.artifact.as_deref(), Some("bin/server"));
+        assert_eq!(info.output_dir, Some(PathBuf::from("bin")));
+        assert_eq!(info.makefile_targets["test"], vec!["go test ./..."]);
+    }
+
+    #[test]
+    fn test_makefile_without_build_target_keeps_native() {
+        let mut info = native_go_build();
+        apply_makefile(&mut info, MakefileParser::parse("test:\n\tgo test ./...\n"));
+
+        assert_eq!(info.build_cmd[1], "go build -o app .");
+        assert_eq!(info.source, BuildCommandSource::Native);
+        assert!(info.artifact.is_none());
+        assert_eq!(info.makefile_targets.len(), 1);
+    }
 }
@@ -1,7 +1,22 @@
+use super::extractor_helper::create_service_context;
+use crate::extractors::parsers::dockerfile::DockerfileParser;
+use crate::extractors::{EnvVarExtractor, HealthCheckExtractor, PortExtractor, PortSource};
 use crate::pipeline::phase_trait::ServicePhase;
 use crate::pipeline::service_context::ServiceContext;
 use anyhow::Result;
 use async_trait::async_trait;
+use peelbox_core::output::schema::HealthCheck;
+
+/// Listen port found in service code or config
+#[derive(Debug, Clone, Default, PartialEq)]
+pub struct PortDetection {
+    pub port: Option<u16>,
+    pub from_env: bool,
+    /// Extractor's confidence in `port` (0.0 when nothing was found)
+    pub confidence: f64,
+    /// First port found outside the Dockerfile (code, .env, config files)
+    pub source_port: Option<u16>,
+}
 
 pub struct RuntimeConfigPhase;
 
@@ -32,9 +47,50 @@ impl ServicePhase for RuntimeConfigPhase {
             .as_ref()
             .and_then(|fw_id| stack_registry.get_framework(fw_id.clone()));
 
-        if let Some(config) = runtime.try_extract(&absolute_files, framework) {
+        let extractor_context = create_service_context(scan, &context.service);
+        let extractor = PortExtractor::new(context.fs());
+        let detected = extractor.extract(&extractor_context);
+        let port_detection = PortDetection {
+            port: detected.first().map(|info| info.port),
+            from_env: extractor.reads_port_from_env(&extractor_context),
+            confidence: detected.first().map_or(0.0, |info| info.confidence),
+            source_port: detected
+                .iter()
+                .find(|info| info.source != PortSource::Dockerfile)
+                .map(|info| info.port),
+        };
+
+        let service_files: Vec<std::path::PathBuf> = scan
+            .file_tree
+            .iter()
+            .filter(|p| p.starts_with(&context.service.path))
+            .map(|p| repo_path.join(p))
+            .collect();
+        let required_env_vars = EnvVarExtractor::new(context.fs())
+            .required_env_vars(&extractor_context, &service_files);
+
+        let dockerfile_path = repo_path.join(&context.service.path).join("Dockerfile");
+        let dockerfile = context
+            .fs()
+            .read_to_string(&dockerfile_path)
+            .ok()
+            .and_then(|content| DockerfileParser::parse(content.as_bytes()).ok());
+
+        if let Some(mut config) = runtime.try_extract(&absolute_files, framework, context.fs()) {
+            if port_detection.port.is_some() {
+                config.port = port_detection.port;
+            }
+            // Conventional framework endpoints (e.g., Spring Boot Actuator) need no scan
+            if config.health.is_none() {
+                config.health = HealthCheckExtractor::new(context.fs())
+                    .find_route(&extractor_context, &service_files)
+                    .map(|endpoint| HealthCheck { endpoint });
+            }
             context.runtime_config = Some(config);
         }
+        context.port_detection = Some(port_detection);
+        context.dockerfile = dockerfile;
+        context.required_env_vars = required_env_vars;
 
         Ok(())
     }
@@ -4,6 +4,7 @@ use super::runtime_config::RuntimeConfigPhase;
 use super::scan::ScanResult;
 use super::stack::StackIdentificationPhase;
 use crate::pipeline::context::AnalysisContext;
+use crate::pipeline::events::DetectionEvent;
 use crate::pipeline::phase_trait::{ServicePhase, WorkflowPhase};
 use crate::pipeline::service_context::ServiceContext;
 use anyhow::{Context as AnyhowContext, Result};
@@ -50,6 +51,7 @@ impl WorkflowPhase for ServiceAnalysisPhase {
 
             match analysis_result {
                 Ok(result) => {
+                    Self::emit_service_events(context, &result);
                     context.service_analyses.push(result);
                 }
                 Err(e) => {
@@ -58,6 +60,9 @@ impl WorkflowPhase for ServiceAnalysisPhase {
                         service.path.display(),
                         e
                     );
+                    context.emit(DetectionEvent::ScanError {
+                        message: format!("{:#}", e),
+                    });
                 }
             }
         }
@@ -67,6 +72,29 @@ impl WorkflowPhase for ServiceAnalysisPhase {
 }
 
 impl ServiceAnalysisPhase {
+    fn emit_service_events(context: &AnalysisContext, result: &ServiceContext) {
+        let service = result.service.path.display().to_string();
+        if let Some(stack) = &result.stack {
+            context.emit(DetectionEvent::LanguageDetected {
+                service: service.clone(),
+                language: stack.language.name().to_string(),
+                build_system: stack.build_system.name().to_string(),
+            });
+            if let Some(framework) = &stack.framework {
+                context.emit(DetectionEvent::FrameworkDetected {
+                    service: service.clone(),
+                    framework: framework.name().to_string(),
+                });
+            }
+        }
+        if let Some(build) = &result.build {
+            context.emit(DetectionEvent::BuildCommandResolved {
+                service,
+                commands: build.build_cmd.clone(),
+            });
+        }
+    }
+
     /// Convert workspace packages into Service structs by matching with scan detections
     fn service_from_detection(detection: &DetectionStack, service_path: PathBuf) -> Service {
         Service {
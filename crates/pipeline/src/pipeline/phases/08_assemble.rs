@@ -1,14 +1,55 @@
+use super::build::BuildCommandSource;
 use super::root_cache::RootCacheInfo;
+use super::scan::ScanResult;
+use super::workspace::{is_workspace_root_manifest, workspace_member_paths};
+use crate::extractors::cgo::CGO_BUILD_FLAG;
+use crate::extractors::parsers::docker_compose::{
+    backing_service_kind, ComposeFile, ComposeParser,
+};
+use crate::extractors::parsers::version_files::{read_version_pins, versions_agree, VersionPin};
+use crate::extractors::pnpm_workspace::PNPM_WORKSPACE;
+use crate::extractors::{
+    BackingServiceDetector, BufDetector, BuildTagDetector, CgoDetector, CgoUsage, CliDetector,
+    CliUsage, DependencyDepthAnalyzer, DeprecationChecker, DevContainerDetector, EmbedDetector,
+    FeatureFlagDetector, FrameworkVersionResolver, GitHubActionsDetector, GoGenerateDetector,
+    GoReleaser, GoSumValidator, GoTestDetector, GoToolDirectiveDetector, GraphQLDetector,
+    GrpcDetector, IacDetector, Ko, KubernetesDetector, LicenseDetector, LintDetector,
+    LiveReloadDetector, LoggingDetector, MigrationDetector, NixDetector,
+    ObservabilityArtifactDetector, ObservabilityDetector, OpenApiDetector, PnpmWorkspaceDetector,
+    ProjectType, ProjectTypeClassifier, ReleaseToolDetector, ReplaceDirectiveAnalyzer,
+    RequiredToolsDetector, ResourceEstimator, ServerlessDetector, SqlcDetector, TemporalDetector,
+    TemporalUsage, ToolchainDetector, WasmDetector, WasmTarget, BUF_TOOLCHAIN, LOG_LEVEL_ENV,
+    SQLC_GENERATE,
+};
+use crate::pipeline::confidence::{Confidence, ConfidenceTracker, Evidence};
 use crate::pipeline::context::AnalysisContext;
+use crate::pipeline::events::DetectionEvent;
 use crate::pipeline::phase_trait::WorkflowPhase;
-use crate::pipeline::service_context::ServiceContext;
+use crate::pipeline::service_context::{ServiceContext, Stack};
 use anyhow::Result;
 use async_trait::async_trait;
+use peelbox_core::fs::FileSystem;
 use peelbox_core::output::schema::{
-    BuildMetadata, BuildStage, CopySpec, RuntimeStage, UniversalBuild,
+    BuildMetadata, BuildStage, ComposeMetadata, CopySpec, DetectionConflict, DevContainerMetadata,
+    ExternalService, FrameworkVersion, HealthCheck, KubernetesMetadata, MonorepoMetadata,
+    RuntimeStage, SecondaryLanguage, UniversalBuild, WorkspaceMetadata,
+};
+use peelbox_stack::buildsystem::bazel::inspect_bazel_workspace;
+use peelbox_stack::buildsystem::cargo::classify_crate;
+use peelbox_stack::buildsystem::composer::php_version_constraint;
+use peelbox_stack::buildsystem::dart_pub::Pubspec;
+use peelbox_stack::buildsystem::deno::lock_runtime_version;
+use peelbox_stack::buildsystem::dotnet::global_json_sdk_version;
+use peelbox_stack::buildsystem::{CondaDetector, PyprojectDetector};
+use peelbox_stack::framework::django::inspect_django_project;
+use peelbox_stack::language::{
+    inspect_dart_project, inspect_deno_project, inspect_haskell_project, parse_kotlin_metadata,
+    parse_node_metadata, parse_scala_metadata, Dependency,
 };
 use peelbox_stack::registry::StackRegistry;
-use std::collections::HashMap;
+use peelbox_stack::{BuildSystemId, FrameworkId, LanguageId, RuntimeId};
+use std::collections::{BTreeMap, HashMap};
+use std::path::Path;
 
 pub struct AssemblePhase;
 
@@ -24,33 +65,366 @@ impl WorkflowPhase for AssemblePhase {
             .as_ref()
             .expect("Root cache must be available before assemble");
 
+        let workspace = workspace_metadata(context)?;
+        let monorepo = monorepo_metadata(context);
+        let compose = ComposeParser::parse_dir(&context.repo_path, context.fs.as_ref());
+
         let builds = execute_assemble(
             &context.service_analyses,
             root_cache,
+            workspace.as_ref(),
+            monorepo.as_ref(),
+            compose.as_ref(),
             &context.stack_registry,
             &context.wolfi_index,
         )?;
+        for build in &builds {
+            context.emit(DetectionEvent::BuildAssembled {
+                build: Box::new(build.clone()),
+            });
+        }
         context.builds = builds;
         Ok(())
     }
 }
 
+fn workspace_metadata(context: &AnalysisContext) -> Result<Option<WorkspaceMetadata>> {
+    let Some(scan) = context.scan.as_ref() else {
+        return Ok(None);
+    };
+
+    let Some(root) = scan.detections.iter().find(|d| {
+        is_workspace_root_manifest(
+            d,
+            &context.repo_path,
+            &context.stack_registry,
+            context.fs.as_ref(),
+        )
+    }) else {
+        return Ok(pnpm_workspace_metadata(context, scan));
+    };
+
+    let mut members: Vec<String> = workspace_member_paths(
+        root,
+        &context.repo_path,
+        &context.stack_registry,
+        context.fs.as_ref(),
+    )?
+    .iter()
+    .map(|p| p.display().to_string())
+    .collect();
+    members.sort();
+
+    if members.is_empty() {
+        return Ok(None);
+    }
+
+    Ok(Some(WorkspaceMetadata {
+        manifest: root.manifest_path.display().to_string(),
+        members,
+        shared_tooling: Vec::new(),
+    }))
+}
+
+fn pnpm_workspace_metadata(
+    context: &AnalysisContext,
+    scan: &ScanResult,
+) -> Option<WorkspaceMetadata> {
+    let workspace =
+        PnpmWorkspaceDetector::detect(&context.repo_path, &scan.file_tree, context.fs.as_ref())?;
+    if workspace.members.is_empty() {
+        return None;
+    }
+
+    Some(WorkspaceMetadata {
+        manifest: PNPM_WORKSPACE.to_string(),
+        members: workspace
+            .members
+            .iter()
+            .map(|p| p.display().to_string())
+            .collect(),
+        shared_tooling: workspace.shared_tooling,
+    })
+}
+
+fn monorepo_metadata(context: &AnalysisContext) -> Option<MonorepoMetadata> {
+    let workspace = context.workspace.as_ref()?;
+    let tool = workspace.orchestrator.as_ref()?;
+
+    let mut packages: Vec<String> = workspace
+        .packages
+        .iter()
+        .map(|p| p.path.display().to_string())
+        .collect();
+    packages.sort();
+
+    let root_scripts = context
+        .fs
+        .read_to_string(&context.repo_path.join("package.json"))
+        .ok()
+        .and_then(|content| serde_json::from_str::<serde_json::Value>(&content).ok())
+        .and_then(|package| {
+            package["scripts"].as_object().map(|scripts| {
+                scripts
+                    .iter()
+                    .filter_map(|(name, cmd)| Some((name.clone(), cmd.as_str()?.to_string())))
+                    .collect()
+            })
+        })
+        .unwrap_or_default();
+
+    Some(MonorepoMetadata {
+        tool: tool.name(),
+        packages,
+        root_scripts,
+    })
+}
+
+/// Lists on each service the services of other languages nested in its directory, and why
+/// the enclosing service's language counts as primary: its manifest's position and whether it
+/// has the most source files once nested services are set aside
+fn annotate_secondary_languages(
+    builds: &mut [UniversalBuild],
+    results: &[ServiceContext],
+    registry: &StackRegistry,
+) {
+    let source_files = |result: &ServiceContext, excluded: &[&Path]| {
+        let extensions = registry
+            .get_language(result.service.language.clone())
+            .map(|language| language.extensions())
+            .unwrap_or_default();
+        result.scan().map_or(0, |scan| {
+            scan.file_tree
+                .iter()
+                .filter(|path| path.starts_with(&result.service.path))
+                .filter(|path| !excluded.iter().any(|dir| path.starts_with(dir)))
+                .filter(|path| {
+                    path.extension()
+                        .and_then(|ext| ext.to_str())
+                        .is_some_and(|ext| extensions.iter().any(|known| known == ext))
+                })
+                .count()
+        })
+    };
+
+    for (index, outer) in results.iter().enumerate() {
+        let nested: Vec<usize> = results
+            .iter()
+            .enumerate()
+            .filter(|(other, inner)| {
+                *other != index
+                    && inner.service.path != outer.service.path
+                    && inner.service.path.starts_with(&outer.service.path)
+            })
+            .map(|(other, _)| other)
+            .collect();
+        let secondary: Vec<usize> = nested
+            .iter()
+            .copied()
+            .filter(|other| results[*other].service.language != outer.service.language)
+            .collect();
+        if secondary.is_empty() {
+            continue;
+        }
+
+        let nested_dirs: Vec<&Path> = nested
+            .iter()
+            .map(|other| results[*other].service.path.as_path())
+            .collect();
+        let own_files = source_files(outer, &nested_dirs);
+        let language = builds[index].metadata.language.clone();
+        let location = if outer.service.path.as_os_str().is_empty() {
+            format!("{} at the repository root", outer.service.manifest)
+        } else {
+            format!(
+                "{} in {} encloses the other services",
+                outer.service.manifest,
+                outer.service.path.display()
+            )
+        };
+        let volume = match secondary
+            .iter()
+            .map(|other| (source_files(&results[*other], &[]), *other))
+            .max()
+        {
+            Some((files, other)) if files > own_files => format!(
+                "although {} has more source files ({} vs {})",
+                builds[other].metadata.language, files, own_files
+            ),
+            _ => format!(
+                "largest code volume ({} {} source files)",
+                own_files, language
+            ),
+        };
+
+        let languages = secondary
+            .iter()
+            .map(|other| SecondaryLanguage {
+                path: results[*other].service.path.display().to_string(),
+                language: builds[*other].metadata.language.clone(),
+                build_system: builds[*other].metadata.build_system.clone(),
+                framework: builds[*other].metadata.framework.clone(),
+            })
+            .collect();
+        builds[index].metadata.secondary_languages = languages;
+        builds[index].metadata.primary_language_heuristic =
+            Some(format!("{}, {}", location, volume));
+    }
+}
+
 fn execute_assemble(
     analysis_results: &[ServiceContext],
     root_cache: &RootCacheInfo,
+    workspace: Option<&WorkspaceMetadata>,
+    monorepo: Option<&MonorepoMetadata>,
+    compose: Option<&(&str, ComposeFile)>,
     registry: &std::sync::Arc<StackRegistry>,
     wolfi_index: &std::sync::Arc<peelbox_wolfi::WolfiPackageIndex>,
 ) -> Result<Vec<UniversalBuild>> {
     let mut builds = Vec::new();
 
     for result in analysis_results {
-        let build = assemble_single_service(result, root_cache, registry, wolfi_index)?;
+        let mut build = assemble_single_service(result, root_cache, registry, wolfi_index)?;
+
+        let service_path = result.service.path.display().to_string();
+        build.metadata.workspace = workspace
+            .filter(|ws| ws.members.contains(&service_path))
+            .cloned();
+        build.metadata.monorepo = monorepo
+            .filter(|mr| mr.packages.contains(&service_path))
+            .cloned();
+        if let Some((file, compose)) = compose {
+            apply_compose(&mut build.metadata, file, compose, &result.service.path);
+        }
+        let compose_images: Vec<String> = build
+            .metadata
+            .external_services
+            .iter()
+            .map(|svc| svc.image.clone())
+            .collect();
+        let dependencies = service_dependencies(result, registry);
+        build.metadata.backing_services = BackingServiceDetector::detect(
+            &dependencies,
+            &build.metadata.required_env_vars,
+            &compose_images,
+        );
+        if let Some(artifacts) = &mut build.metadata.observability_artifacts {
+            ObservabilityArtifactDetector::link_backing_services(
+                artifacts,
+                &build.metadata.backing_services,
+            );
+        }
+        build.metadata.resource_hints = Some(ResourceEstimator::estimate(
+            &result.service.language,
+            result
+                .stack
+                .as_ref()
+                .and_then(|stack| stack.framework.as_ref()),
+            &dependencies,
+            &build.metadata.backing_services,
+            build.metadata.grpc.is_some(),
+        ));
+
         builds.push(build);
     }
+    annotate_secondary_languages(&mut builds, analysis_results, registry);
 
     Ok(builds)
 }
 
+/// External dependency names declared by the service manifest
+fn service_dependencies(result: &ServiceContext, registry: &StackRegistry) -> Vec<String> {
+    external_dependencies(result, registry)
+        .into_iter()
+        .map(|dep| dep.name)
+        .collect()
+}
+
+/// External dependencies declared by the service manifest, with their declared versions
+fn external_dependencies(result: &ServiceContext, registry: &StackRegistry) -> Vec<Dependency> {
+    let manifest_path = result
+        .repo_path()
+        .join(&result.service.path)
+        .join(&result.service.manifest);
+    let (Some(language), Ok(content)) = (
+        registry.get_language(result.service.language.clone()),
+        result.fs().read_to_string(&manifest_path),
+    ) else {
+        return vec![];
+    };
+    language.parse_dependencies(&content, &[]).external_deps
+}
+
+/// The dependency the framework was detected from, at the version the lockfile pins or, failing
+/// that, the version the manifest requires
+fn framework_version(
+    result: &ServiceContext,
+    stack: &Stack,
+    registry: &StackRegistry,
+) -> Option<FrameworkVersion> {
+    let framework_id = stack.framework.as_ref()?;
+    let patterns = registry
+        .get_framework(framework_id.clone())?
+        .dependency_patterns();
+    let dependency = external_dependencies(result, registry)
+        .into_iter()
+        .find(|dep| patterns.iter().any(|pattern| pattern.matches(dep)))?;
+    let scan = result.scan().ok()?;
+    let version = FrameworkVersionResolver::resolve(
+        result.repo_path(),
+        &result.service.path,
+        &scan.file_tree,
+        result.fs(),
+        &dependency.name,
+    )
+    .or_else(|| match stack.build_system {
+        // The go.mod parser drops the `v` go.sum and module queries use
+        BuildSystemId::GoMod => dependency.version.map(|version| format!("v{}", version)),
+        _ => dependency.version,
+    })?;
+    Some(FrameworkVersion {
+        framework: framework_id.name().to_string(),
+        version,
+        module: dependency.name,
+    })
+}
+
+/// Links the build to the Compose service built from its directory and records what that
+/// service expects: backing services it depends on and the environment Compose sets
+fn apply_compose(
+    metadata: &mut BuildMetadata,
+    file: &str,
+    compose: &ComposeFile,
+    service_path: &Path,
+) {
+    let Some(service) = compose.service_for_context(service_path) else {
+        return;
+    };
+    let dependencies = compose.dependencies_of(&service.name);
+
+    metadata.compose = Some(ComposeMetadata {
+        file: file.to_string(),
+        service: service.name.clone(),
+        depends_on: dependencies.iter().map(|dep| dep.name.clone()).collect(),
+    });
+    metadata.external_services = dependencies
+        .iter()
+        .filter_map(|dep| {
+            let image = dep.image.as_deref()?;
+            Some(ExternalService {
+                name: dep.name.clone(),
+                kind: backing_service_kind(image)?.to_string(),
+                image: image.to_string(),
+            })
+        })
+        .collect();
+    metadata
+        .required_env_vars
+        .extend(service.environment.iter().cloned());
+    metadata.required_env_vars.sort();
+    metadata.required_env_vars.dedup();
+}
+
 fn assemble_single_service(
     result: &ServiceContext,
     root_cache: &RootCacheInfo,
@@ -58,17 +432,21 @@ fn assemble_single_service(
     wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
 ) -> Result<UniversalBuild> {
     let _language_def = registry.get_language(result.service.language.clone());
+    let fs = result.fs();
 
     // Read manifest content for version parsing
     let service_path = result.repo_path().join(&result.service.path);
     let manifest_path = service_path.join(&result.service.manifest);
-    let manifest_content = std::fs::read_to_string(&manifest_path).ok();
+    let manifest_content = fs.read_to_string(&manifest_path).ok();
 
     let build_system = registry.get_build_system(result.service.build_system.clone());
 
     let template = build_system
         .as_ref()
-        .map(|bs| bs.build_template(wolfi_index, &service_path, manifest_content.as_deref()));
+        .map(|bs| bs.build_template(wolfi_index, &service_path, manifest_content.as_deref(), fs));
+    let runnable = build_system
+        .as_ref()
+        .is_none_or(|bs| bs.produces_runnable(&service_path, manifest_content.as_deref(), fs));
 
     let project_name = manifest_content
         .as_deref()
@@ -93,36 +471,859 @@ fn assemble_single_service(
 
     // Extract from runtime_config with defaults
     let runtime_config = result.runtime_config.as_ref();
-    let entrypoint_cmd = runtime_config
-        .and_then(|rc| rc.entrypoint.clone())
-        .unwrap_or_else(|| "/usr/local/bin/{project_name}".to_string());
-    let port = runtime_config
+    // package.json sits next to the lockfile that may have been detected as the manifest
+    let package_json = matches!(
+        stack.language,
+        LanguageId::JavaScript | LanguageId::TypeScript
+    )
+    .then(|| fs.read_to_string(&service_path.join("package.json")).ok())
+    .flatten();
+    let (entrypoint_cmd, command_evidence) = build_system
+        .as_ref()
+        .and_then(|bs| bs.runtime_command(&service_path, manifest_content.as_deref(), fs))
+        .map(|cmd| (cmd, Evidence::Manifest(Confidence::High.to_f64())))
+        .or_else(|| {
+            runtime_config
+                .and_then(|rc| rc.entrypoint.clone())
+                .map(|cmd| (cmd, Evidence::Default))
+        })
+        .or_else(|| {
+            let language = registry.get_language(result.service.language.clone())?;
+            let content = package_json.as_deref().or(manifest_content.as_deref())?;
+            language
+                .parse_entrypoint_from_manifest(content)
+                .map(|cmd| (cmd, Evidence::Manifest(Confidence::Medium.to_f64())))
+        })
+        .unwrap_or_else(|| {
+            let binary = "/usr/local/bin/{project_name}".to_string();
+            // Only a guess unless the build actually installs a binary there
+            let installs_binary = template
+                .as_ref()
+                .is_some_and(|t| t.runtime_copy.iter().any(|(_, to)| *to == binary));
+            let evidence = if installs_binary {
+                Evidence::Default
+            } else {
+                Evidence::Fallback
+            };
+            (binary, evidence)
+        });
+    let devcontainer = result.scan().ok().and_then(|scan| {
+        DevContainerDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+        )
+    });
+    let (port, port_evidence) = runtime_config
         .and_then(|rc| rc.port)
+        .map(|port| match result.port_detection.as_ref() {
+            Some(pd) if pd.port == Some(port) => (port, Evidence::Extracted(pd.confidence)),
+            _ => (port, Evidence::Default),
+        })
         .or_else(|| {
             registry
                 .get_language(result.service.language.clone())
                 .and_then(|lang| lang.default_port())
+                .map(|port| (port, Evidence::Default))
         })
-        .unwrap_or(8080);
+        .unwrap_or((8080, Evidence::Fallback));
+    // The ports a dev container forwards are declared for this repository, so they outrank
+    // ports scanned from source
+    let forwarded = devcontainer
+        .as_ref()
+        .map(DevContainerDetector::app_ports)
+        .unwrap_or_default();
+    let (port, port_evidence) = match forwarded.as_slice() {
+        [] => (port, port_evidence),
+        ports if ports.contains(&port) => (port, Evidence::Declared),
+        [first, ..] => (*first, Evidence::Declared),
+    };
     let _env_vars = runtime_config
         .map(|rc| &rc.env_vars)
         .cloned()
         .unwrap_or_default();
+    let version_pins = service_version_pins(result.repo_path(), &service_path, fs);
+    let mut warnings = version_pin_warnings(
+        &version_pins,
+        registry,
+        result,
+        package_json.as_deref().or(manifest_content.as_deref()),
+    );
+    warnings.extend(lockfile_conflict_warnings(
+        &stack.build_system,
+        &service_path,
+        fs,
+    ));
     let _native_deps = runtime_config
         .map(|rc| &rc.native_deps)
         .cloned()
         .unwrap_or_default();
 
+    let dependencies = service_dependencies(result, registry);
+    let cgo = match stack.language {
+        LanguageId::Go => result.scan().ok().and_then(|scan| {
+            CgoDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+            )
+        }),
+        _ => None,
+    };
+    let cgo_required = cgo.as_ref().is_some_and(CgoUsage::required);
+    let go_generate = match stack.language {
+        LanguageId::Go => result.scan().ok().and_then(|scan| {
+            GoGenerateDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+            )
+        }),
+        _ => None,
+    }
+    .unwrap_or_default();
+    let graphql = match stack.language {
+        LanguageId::Go => result.scan().ok().and_then(|scan| {
+            GraphQLDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+                &dependencies,
+            )
+        }),
+        _ => None,
+    };
+    let mut pre_build_commands = go_generate.commands.clone();
+    // gqlgen projects usually regenerate through a go:generate directive already
+    if let Some(command) = graphql.as_ref().and_then(|g| g.generate_command.clone()) {
+        if !pre_build_commands.iter().any(|c| c.contains("gqlgen")) {
+            pre_build_commands.push(command);
+        }
+    }
+    let sqlc = match stack.language {
+        LanguageId::Go => result.scan().ok().and_then(|scan| {
+            SqlcDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+            )
+        }),
+        _ => None,
+    };
+    if sqlc.is_some() && !pre_build_commands.iter().any(|c| c.contains("sqlc")) {
+        pre_build_commands.push(SQLC_GENERATE.to_string());
+    }
+    let buf = match stack.language {
+        LanguageId::Go => result.scan().ok().and_then(|scan| {
+            BufDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+            )
+        }),
+        _ => None,
+    };
+    let buf_generate = buf.as_ref().and_then(BufDetector::generate_command);
+    if let Some(command) = buf_generate {
+        if !pre_build_commands.iter().any(|c| c.contains(command)) {
+            pre_build_commands.push(command.to_string());
+        }
+    }
+    let mut grpc = match stack.language {
+        LanguageId::Go => result
+            .scan()
+            .ok()
+            .and_then(|scan| GrpcDetector::detect(&scan.file_tree, &dependencies)),
+        _ => None,
+    };
+    // With a buf.gen.yaml, stubs come from `buf generate` rather than a protoc invocation
+    let proto_toolchain = match (grpc.as_mut(), buf_generate) {
+        (Some(grpc), Some(command)) => {
+            grpc.build_command_prefix = command.to_string();
+            Some(BUF_TOOLCHAIN.to_string())
+        }
+        _ => None,
+    };
+    let mut required_tools = result
+        .scan()
+        .map(|scan| {
+            RequiredToolsDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+                &go_generate,
+            )
+        })
+        .unwrap_or_default();
+    let migrations = result.scan().ok().and_then(|scan| {
+        MigrationDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+        )
+    });
+    if let Some(migrations) = &migrations {
+        if !required_tools
+            .iter()
+            .any(|tool| tool.name == migrations.required_tool.name)
+        {
+            required_tools.push(migrations.required_tool.clone());
+        }
+    }
+    let temporal = result.scan().ok().and_then(|scan| {
+        TemporalDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+            &dependencies,
+        )
+    });
+    if temporal.is_some() && !required_tools.iter().any(|tool| tool.name == "tctl") {
+        required_tools.push(TemporalUsage::required_tool());
+    }
+    let cli = match stack.language {
+        LanguageId::Go => result.scan().ok().and_then(|scan| {
+            CliDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+                &dependencies,
+            )
+        }),
+        _ => None,
+    };
+    let project_type = match stack.language {
+        LanguageId::Go => result.scan().ok().and_then(|scan| {
+            ProjectTypeClassifier::classify(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+                &dependencies,
+                cli.as_ref(),
+            )
+        }),
+        _ => None,
+    };
+    // Command-line tools, batch jobs and libraries listen on nothing, so they get no port or
+    // health check
+    let listens = runnable
+        && project_type
+            .as_ref()
+            .is_none_or(|classification| classification.primary.listens());
+    let is_cli = project_type
+        .as_ref()
+        .is_some_and(|classification| classification.primary == ProjectType::Cli);
+    let logging = result.scan().ok().and_then(|scan| {
+        LoggingDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+            &dependencies,
+        )
+    });
+    if sqlc.is_some() && !required_tools.iter().any(|tool| tool.name == "sqlc") {
+        required_tools.push(SqlcDetector::required_tool());
+    }
+    for tool in buf.iter().flat_map(BufDetector::required_tools) {
+        if !required_tools
+            .iter()
+            .any(|existing| existing.name == tool.name)
+        {
+            required_tools.push(tool);
+        }
+    }
+    let feature_flags = result.scan().ok().and_then(|scan| {
+        FeatureFlagDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+            &dependencies,
+        )
+    });
+    let mut required_env_vars = result.required_env_vars.clone();
+    if let Some(flags) = &feature_flags {
+        required_env_vars.extend(
+            FeatureFlagDetector::required_env_vars(flags)
+                .iter()
+                .map(|var| var.to_string()),
+        );
+        required_env_vars.sort();
+        required_env_vars.dedup();
+    }
+    let embedded_assets = match stack.language {
+        LanguageId::Go => result
+            .scan()
+            .map(|scan| {
+                EmbedDetector::detect(
+                    result.repo_path(),
+                    &result.service.path,
+                    &scan.file_tree,
+                    fs,
+                )
+            })
+            .unwrap_or_default(),
+        _ => vec![],
+    };
+    let has_embedded_frontend = embedded_assets
+        .iter()
+        .any(|asset| EmbedDetector::is_frontend_output(&asset.pattern));
+    let go_tests = match stack.language {
+        LanguageId::Go => result.scan().ok().and_then(|scan| {
+            GoTestDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+            )
+        }),
+        _ => None,
+    };
+    let race_suggestion = go_tests.as_ref().and_then(|tests| {
+        if tests.race_missing_from_command() {
+            Some(format!(
+                "{} run go test -race but the test command does not; use `go test -race ./...` to match",
+                tests.race_sources.join(", ")
+            ))
+        } else if tests.concurrent && tests.race_sources.is_empty() {
+            Some(
+                "Code starts goroutines and uses sync; add -race to the test command (`go test -race ./...`) to catch data races"
+                    .to_string(),
+            )
+        } else {
+            None
+        }
+    });
+    let lint_tools = result
+        .scan()
+        .map(|scan| {
+            LintDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+            )
+        })
+        .unwrap_or_default();
+    let license = result.scan().ok().and_then(|scan| {
+        LicenseDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+        )
+    });
+    let serverless = result.scan().ok().and_then(|scan| {
+        ServerlessDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+        )
+    });
+    let iac = result.scan().ok().and_then(|scan| {
+        IacDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+        )
+    });
+    let release_tools = match stack.language {
+        LanguageId::Go => result.scan().ok().and_then(|scan| {
+            ReleaseToolDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+                &dependencies,
+                &project_name,
+            )
+        }),
+        _ => None,
+    };
+    let goreleaser = release_tools
+        .as_ref()
+        .and_then(|tools| tools.goreleaser.clone());
+    let ko = release_tools.and_then(|tools| tools.ko);
+    let build_constraints = match stack.language {
+        LanguageId::Go => result.scan().ok().and_then(|scan| {
+            BuildTagDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+            )
+        }),
+        _ => None,
+    };
+    if build_constraints
+        .as_ref()
+        .is_some_and(BuildTagDetector::linux_only)
+    {
+        warnings.push(
+            "Go build constraints only target Linux; the service will not build for other operating systems"
+                .to_string(),
+        );
+    }
+    let wasm = match stack.language {
+        LanguageId::Go => result.scan().ok().and_then(|scan| {
+            WasmDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+                build_constraints.as_ref(),
+            )
+        }),
+        _ => None,
+    };
+    let dev_run_command = result.scan().ok().and_then(|scan| {
+        LiveReloadDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+            &stack.language,
+            &dependencies,
+        )
+    });
+    let kubernetes = result.scan().ok().and_then(|scan| {
+        KubernetesDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+        )
+    });
+    let nix = result.scan().ok().and_then(|scan| {
+        NixDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+        )
+    });
+    let local_replacements = match stack.build_system {
+        BuildSystemId::GoMod => manifest_content
+            .as_deref()
+            .map(ReplaceDirectiveAnalyzer::local_replacements)
+            .unwrap_or_default(),
+        _ => vec![],
+    };
+    warnings.extend(local_replacements.iter().map(|replacement| {
+        format!(
+            "go.mod replaces {} with local path {}; the build fails unless that directory is present in the build context",
+            replacement.module, replacement.path
+        )
+    }));
+    let toolchain = match stack.build_system {
+        BuildSystemId::GoMod => manifest_content
+            .as_deref()
+            .and_then(ToolchainDetector::detect),
+        _ => None,
+    };
+    if let Some(toolchain) = toolchain.as_ref().filter(|t| !t.consistent()) {
+        warnings.push(format!(
+            "go.mod toolchain go{} is older than its go {} directive; the go command rejects the module",
+            toolchain.toolchain_version.as_deref().unwrap_or_default(),
+            toolchain.module_version.as_deref().unwrap_or_default()
+        ));
+    }
+    let ci = result.scan().ok().and_then(|scan| {
+        GitHubActionsDetector::detect(
+            result.repo_path(),
+            &result.service.path,
+            &scan.file_tree,
+            fs,
+        )
+    });
+    if let (Some(ci), Some(module_version)) = (
+        ci.as_ref(),
+        toolchain.as_ref().and_then(|t| t.module_version.as_deref()),
+    ) {
+        warnings.extend(
+            ci.go_versions
+                .iter()
+                .filter(|ci_go| !versions_agree(&ci_go.version, module_version))
+                .map(|ci_go| {
+                    format!(
+                        "{} sets up Go {} but go.mod declares go {}",
+                        ci_go.workflow, ci_go.version, module_version
+                    )
+                }),
+        );
+    }
+    for package in sqlc.iter().flat_map(|sqlc| &sqlc.packages) {
+        if !package.generated {
+            warnings.push(format!(
+                "sqlc output directory {} has no generated code yet; `{}` must run before building",
+                package.out, SQLC_GENERATE
+            ));
+        }
+        if !package.imported {
+            warnings.push(format!(
+                "sqlc generates package {} into {} but no Go file imports it",
+                package.package, package.out
+            ));
+        }
+    }
+    let go_tools = match stack.build_system {
+        BuildSystemId::GoMod => manifest_content
+            .as_deref()
+            .map(GoToolDirectiveDetector::detect)
+            .unwrap_or_default(),
+        _ => vec![],
+    };
+    let deprecated_dependencies = match stack.build_system {
+        BuildSystemId::GoMod => manifest_content
+            .as_deref()
+            .map(DeprecationChecker::check)
+            .unwrap_or_default(),
+        _ => vec![],
+    };
+    let dependency_depth = match stack.build_system {
+        BuildSystemId::GoMod => result.scan().ok().and_then(|scan| {
+            DependencyDepthAnalyzer::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+            )
+        }),
+        _ => None,
+    };
+    if let Some(depth) = dependency_depth.filter(|depth| depth.excessive()) {
+        warnings.push(format!(
+            "The module graph holds {} dependencies up to {} levels deep, which makes upgrades fragile; run `go mod tidy` and prune requirements the service no longer needs",
+            depth.count, depth.depth
+        ));
+    }
+    let unused_go_tools: Vec<String> = GoToolDirectiveDetector::unused(&go_tools, &go_generate)
+        .into_iter()
+        .map(|tool| tool.module.clone())
+        .collect();
+    let go_sum = match stack.build_system {
+        BuildSystemId::GoMod => result.scan().ok().and_then(|scan| {
+            GoSumValidator::validate(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+            )
+        }),
+        _ => None,
+    };
+    // Without go.sum every requirement is missing, and `go build` refuses to run
+    let go_sum_absent = go_sum
+        .as_ref()
+        .is_some_and(|status| !status.present && !status.complete);
+    if let Some(status) = go_sum.as_ref().filter(|status| !status.complete) {
+        let relaxed = if status.env_hints.is_empty() {
+            String::new()
+        } else {
+            format!(
+                " (.env sets {}, which relaxes checksum verification)",
+                status.env_hints.join(", ")
+            )
+        };
+        warnings.push(if status.present {
+            format!(
+                "go.sum has no checksums for {}; run `go mod tidy` before building{}",
+                status.missing.join(", "),
+                relaxed
+            )
+        } else {
+            format!(
+                "go.mod requires {} modules but go.sum is missing; `go build` fails in module mode until `go mod tidy` records their checksums{}",
+                status.missing.len(),
+                relaxed
+            )
+        });
+    }
+
     let metadata = BuildMetadata {
         project_name: Some(project_name.clone()),
         language: stack.language.name().to_string(),
         build_system: stack.build_system.name().to_string(),
         framework: stack.framework.as_ref().map(|fw| fw.name().to_string()),
+        framework_version: framework_version(result, stack, registry),
         reasoning: format!(
             "Detected from {} in {}",
             result.service.manifest,
             result.service.path.display()
         ),
+        service_path: Some(if result.service.path.as_os_str().is_empty() {
+            ".".to_string()
+        } else {
+            result.service.path.display().to_string()
+        }),
+        workspace: None,
+        monorepo: None,
+        django: match stack.framework {
+            Some(FrameworkId::Django) => inspect_django_project(&service_path, fs),
+            _ => None,
+        },
+        node: package_json
+            .as_deref()
+            .and_then(parse_node_metadata)
+            .map(|mut node| {
+                if stack.build_system == BuildSystemId::Bun {
+                    node.package_manager = Some("bun".to_string());
+                }
+                node
+            }),
+        kotlin: match stack.language {
+            LanguageId::Kotlin => manifest_content.as_deref().map(parse_kotlin_metadata),
+            _ => None,
+        },
+        scala: match stack.language {
+            LanguageId::Scala => manifest_content
+                .as_deref()
+                .map(|content| parse_scala_metadata(&result.service.manifest, content)),
+            _ => None,
+        },
+        haskell: match stack.language {
+            LanguageId::Haskell => {
+                inspect_haskell_project(&service_path, &result.service.manifest, fs)
+            }
+            _ => None,
+        },
+        deno: match stack.build_system {
+            BuildSystemId::Deno => inspect_deno_project(&service_path, fs),
+            _ => None,
+        },
+        dart: match stack.language {
+            LanguageId::Dart => manifest_content
+                .as_deref()
+                .map(|content| inspect_dart_project(&service_path, content, fs)),
+            _ => None,
+        },
+        python: match (&stack.language, &stack.build_system) {
+            (LanguageId::Python, BuildSystemId::Conda) => manifest_content
+                .as_deref()
+                .map(|content| CondaDetector::inspect(&result.service.manifest, content)),
+            (LanguageId::Python, _) => manifest_content
+                .as_deref()
+                .and_then(PyprojectDetector::inspect),
+            _ => None,
+        },
+        bazel: match stack.build_system {
+            BuildSystemId::Bazel => inspect_bazel_workspace(&service_path, fs),
+            _ => None,
+        },
+        crate_type: match stack.build_system {
+            BuildSystemId::Cargo => manifest_content
+                .as_deref()
+                .and_then(|content| classify_crate(&service_path, content, fs))
+                .map(|kind| kind.as_str().to_string()),
+            _ => None,
+        },
+        runtime: match stack.runtime {
+            RuntimeId::Deno => Some("deno".to_string()),
+            RuntimeId::Bun => Some("bun".to_string()),
+            _ => None,
+        },
+        runtime_version: match stack.build_system {
+            BuildSystemId::Composer => manifest_content.as_deref().and_then(php_version_constraint),
+            BuildSystemId::DartPub => manifest_content
+                .as_deref()
+                .and_then(|content| Pubspec::parse(content).sdk),
+            BuildSystemId::Deno => fs
+                .read_to_string(&service_path.join("deno.lock"))
+                .ok()
+                .as_deref()
+                .and_then(lock_runtime_version),
+            _ => None,
+        },
+        sdk_version: match stack.build_system {
+            BuildSystemId::DotNet => global_json_sdk_version(&service_path, fs),
+            _ => None,
+        },
+        runtime_versions: version_pins
+            .iter()
+            .map(|(runtime, pin)| (runtime.clone(), pin.version.clone()))
+            .collect(),
+        makefile_targets: build_info.makefile_targets.clone(),
+        build_command_source: Some(build_info.source.as_str().to_string()),
+        dockerfile_from: result.dockerfile.as_ref().and_then(|df| df.from.clone()),
+        dockerfile_expose: result
+            .dockerfile
+            .as_ref()
+            .map(|df| df.expose.clone())
+            .unwrap_or_default(),
+        dockerfile_cmd: result.dockerfile.as_ref().and_then(|df| df.cmd.clone()),
+        compose: None,
+        external_services: vec![],
+        required_env_vars,
+        backing_services: vec![],
+        workflow_engine: temporal
+            .as_ref()
+            .map(|_| TemporalUsage::WORKFLOW_ENGINE.to_string()),
+        service_role: temporal
+            .as_ref()
+            .and_then(|usage| usage.role)
+            .map(|role| role.as_str().to_string()),
+        project_type: project_type
+            .as_ref()
+            .map(|classification| classification.primary.as_str().to_string()),
+        project_type_confidence: project_type
+            .as_ref()
+            .map(|classification| classification.confidence),
+        secondary_project_types: project_type
+            .as_ref()
+            .map(|classification| {
+                classification
+                    .secondary
+                    .iter()
+                    .map(|project_type| project_type.as_str().to_string())
+                    .collect()
+            })
+            .unwrap_or_default(),
+        cli_framework: cli.as_ref().map(|_| CliUsage::FRAMEWORK.to_string()),
+        config_library: match stack.language {
+            LanguageId::Go => CliDetector::config_library(&dependencies).map(String::from),
+            _ => None,
+        },
+        run_command: is_cli.then(|| CliUsage::run_command(&project_name)),
+        feature_flags,
+        logging_library: logging.as_ref().map(|usage| usage.library.to_string()),
+        log_format_hint: logging.as_ref().map(|usage| usage.format_hint.to_string()),
+        log_json_configured: logging.as_ref().is_some_and(|usage| usage.json_configured),
+        observability: match stack.language {
+            LanguageId::Go => result.scan().ok().and_then(|scan| {
+                ObservabilityDetector::detect(
+                    result.repo_path(),
+                    &result.service.path,
+                    &scan.file_tree,
+                    fs,
+                    &dependencies,
+                )
+            }),
+            _ => None,
+        },
+        observability_artifacts: result.scan().ok().and_then(|scan| {
+            ObservabilityArtifactDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+            )
+        }),
+        grpc,
+        buf,
+        proto_toolchain,
+        sqlc,
+        api_schema: result.scan().ok().and_then(|scan| {
+            OpenApiDetector::detect(
+                result.repo_path(),
+                &result.service.path,
+                &scan.file_tree,
+                fs,
+            )
+        }),
+        api_schema_generated: stack.language == LanguageId::Go
+            && OpenApiDetector::is_generated(&dependencies),
+        cgo: cgo_required,
+        cgo_disabled: cgo.as_ref().is_some_and(|usage| usage.disabled),
+        build_flags: if cgo_required {
+            vec![CGO_BUILD_FLAG.to_string()]
+        } else {
+            vec![]
+        },
+        pre_build_commands,
+        build_dependencies: go_generate.tools,
+        embedded_assets,
+        has_embedded_frontend,
+        local_replacements,
+        has_integration_tests: go_tests.as_ref().is_some_and(|t| t.has_integration_tests),
+        test_framework: go_tests.as_ref().map(|t| t.framework.clone()),
+        test_command: go_tests.map(|t| t.command),
+        ci_commands: ci
+            .as_ref()
+            .map(|ci| ci.commands.clone())
+            .unwrap_or_default(),
+        lint_tools,
+        license,
+        deployment_target: serverless.as_ref().map(|_| "serverless".to_string()),
+        serverless_platform: serverless.as_ref().map(|s| s.platform.clone()),
+        serverless_config: serverless.as_ref().map(|s| s.config.clone()),
+        deploy_command: serverless.as_ref().map(|s| s.deploy_command.clone()),
+        serverless_handler: serverless.and_then(|s| s.handler),
+        iac_tool: iac.as_ref().map(|p| p.tool.clone()),
+        iac_path: iac.as_ref().map(|p| p.path.clone()),
+        iac_providers: iac
+            .as_ref()
+            .map(|p| p.providers.clone())
+            .unwrap_or_default(),
+        cloud_providers: iac
+            .as_ref()
+            .map(|p| p.cloud_providers.clone())
+            .unwrap_or_default(),
+        iac_state_backend: iac.as_ref().and_then(|p| p.state_backend.clone()),
+        infra_subdirectory: iac.as_ref().and_then(|p| p.infra_subdirectory.clone()),
+        iac_language: iac.as_ref().and_then(|p| p.language.clone()),
+        iac_version_constraint: iac.as_ref().and_then(|p| p.version_constraint.clone()),
+        iac_deploy_command: iac.map(|p| p.deploy_command),
+        release_tool: goreleaser.as_ref().map(|_| GoReleaser::TOOL.to_string()),
+        release_config: goreleaser.as_ref().map(|g| g.config.clone()),
+        release_command: goreleaser
+            .as_ref()
+            .map(|_| GoReleaser::RELEASE_COMMAND.to_string()),
+        release_binaries: goreleaser
+            .as_ref()
+            .map(|g| g.binaries.clone())
+            .unwrap_or_default(),
+        cross_compile_targets: goreleaser
+            .as_ref()
+            .map(|g| g.targets.clone())
+            .unwrap_or_default(),
+        release_archive_formats: goreleaser.map(|g| g.archive_formats).unwrap_or_default(),
+        container_build_tool: ko.as_ref().map(|_| Ko::TOOL.to_string()),
+        container_build_command: ko.map(|ko| ko.build_command),
+        build_constraints,
+        required_tools,
+        migration_tool: migrations.as_ref().map(|m| m.tool.clone()),
+        migration_path: migrations.as_ref().and_then(|m| m.path.clone()),
+        migration_command: migrations.map(|m| m.command),
+        go_sum_present: go_sum.as_ref().map(|status| status.present),
+        go_sum_complete: go_sum.as_ref().map(|status| status.complete),
+        go_sum_missing: go_sum.map(|status| status.missing).unwrap_or_default(),
+        api_type: graphql.as_ref().map(|_| "graphql".to_string()),
+        graphql_subscriptions: graphql.as_ref().is_some_and(|g| g.subscriptions),
+        graphql_model_dir: graphql.as_ref().and_then(|g| g.model_dir.clone()),
+        graphql_resolver_dir: graphql.as_ref().and_then(|g| g.resolver_dir.clone()),
+        schema_files: graphql.map(|g| g.schema_files).unwrap_or_default(),
+        go_module_version: toolchain.as_ref().and_then(|t| t.module_version.clone()),
+        go_toolchain_version: toolchain.as_ref().and_then(|t| t.toolchain_version.clone()),
+        godebug_settings: toolchain.map(|t| t.godebug).unwrap_or_default(),
+        go_tools,
+        // Filled in by `detect --check-upgrades`, which needs the network
+        upgrades_available: vec![],
+        deprecated_dependencies,
+        dependency_depth: dependency_depth.map(|depth| depth.depth),
+        dependency_count: dependency_depth.map(|depth| depth.count),
+        wasm_target: wasm.map(|target| target.as_str().to_string()),
+        dev_run_command,
+        // Estimated once backing services are known
+        resource_hints: None,
+        build_environment: nix.as_ref().map(|_| "nix".to_string()),
+        build_environment_tools: nix.as_ref().map(|n| n.tools.clone()).unwrap_or_default(),
+        nix_develop_command: nix.map(|n| n.develop_command),
+        kubernetes,
+        devcontainer,
     };
 
     let mut cache_paths: Vec<String> = cache_info
@@ -138,15 +1339,33 @@ fn assemble_single_service(
             .map(|p| p.display().to_string()),
     );
 
+    let mut build_packages: Vec<String> = template
+        .as_ref()
+        .map(|t| t.build_packages.clone())
+        .unwrap_or_default();
+    // build-base already ships make
+    if build_info.source == BuildCommandSource::Makefile
+        && !build_packages
+            .iter()
+            .any(|p| p == "make" || p == "build-base")
+    {
+        build_packages.push("make".to_string());
+    }
+    let mut build_env = template
+        .as_ref()
+        .map(|t| t.build_env.clone())
+        .unwrap_or_default();
+    // The Go template builds static binaries with cgo off; importing "C" needs it back on
+    if cgo_required {
+        build_env.insert("CGO_ENABLED".to_string(), "1".to_string());
+        if !build_packages.iter().any(|p| p == "build-base") {
+            build_packages.push("build-base".to_string());
+        }
+    }
+
     let build = BuildStage {
-        packages: template
-            .as_ref()
-            .map(|t| t.build_packages.clone())
-            .unwrap_or_default(),
-        env: template
-            .as_ref()
-            .map(|t| t.build_env.clone())
-            .unwrap_or_default(),
+        packages: build_packages,
+        env: build_env,
         commands: build_info.build_cmd.clone(),
         cache: cache_paths,
     };
@@ -159,24 +1378,31 @@ fn assemble_single_service(
     }
 
     // Add framework-specific runtime environment variables
-    if let Some(framework_id) = &stack.framework {
-        if let Some(framework) = registry.get_framework(framework_id.clone()) {
-            env_map.extend(framework.runtime_env_vars());
-        }
+    let framework = stack
+        .framework
+        .as_ref()
+        .and_then(|framework_id| registry.get_framework(framework_id.clone()));
+    if let Some(framework) = framework {
+        env_map.extend(framework.runtime_env_vars());
     }
 
     let entrypoint_replaced = entrypoint_cmd.replace("{project_name}", &project_name);
-    let command_parts: Vec<String> = entrypoint_replaced
-        .split_whitespace()
-        .map(String::from)
-        .collect();
+    // A library build has nothing to start
+    let command_parts: Vec<String> = if runnable {
+        entrypoint_replaced
+            .split_whitespace()
+            .map(String::from)
+            .collect()
+    } else {
+        vec![]
+    };
 
     let runtime_packages = {
         let runtime = registry.get_runtime(stack.runtime.clone(), None);
-        runtime.runtime_packages(wolfi_index, &service_path, manifest_content.as_deref())
+        runtime.runtime_packages(wolfi_index, &service_path, manifest_content.as_deref(), fs)
     };
 
-    let runtime_copy = template
+    let mut runtime_copy: Vec<CopySpec> = template
         .as_ref()
         .map(|t| {
             t.runtime_copy
@@ -188,24 +1414,395 @@ fn assemble_single_service(
                 .collect()
         })
         .unwrap_or_default();
+    // The Makefile build writes its binary somewhere other than the template expects
+    if let (Some(artifact), Some(copy)) = (&build_info.artifact, runtime_copy.first_mut()) {
+        copy.from = artifact.clone();
+    }
+
+    let auxiliary_commands = template
+        .as_ref()
+        .map(|t| {
+            t.runtime_auxiliary_commands
+                .iter()
+                .map(|cmd| {
+                    cmd.replace("{project_name}", &project_name)
+                        .split_whitespace()
+                        .map(String::from)
+                        .collect()
+                })
+                .collect()
+        })
+        .unwrap_or_default();
+
+    let health = runtime_config.and_then(|rc| rc.health.clone());
+    let health_check_hint = framework.and_then(|fw| fw.health_check_hint());
+    let mut suggestions = Vec::new();
+    if health.is_none() && listens {
+        suggestions.push(health_check_hint.clone().unwrap_or_else(|| {
+            "No health endpoint found; expose /health so platforms can probe the service"
+                .to_string()
+        }));
+    }
+    if let Some(usage) = cgo.as_ref().filter(|usage| usage.required()) {
+        suggestions.push(format!(
+            "{} import \"C\"; cgo needs gcc (build-base) or musl-gcc in the build container",
+            usage.files.join(", ")
+        ));
+    }
+    if metadata.has_embedded_frontend {
+        suggestions.push(
+            "The binary embeds frontend build output; build the frontend before `go build`"
+                .to_string(),
+        );
+    }
+    suggestions.extend(race_suggestion);
+    suggestions.extend(unused_go_tools.iter().map(|module| {
+        format!(
+            "go.mod declares tool {} but no //go:generate directive runs it; remove it with `go get -tool {}@none`",
+            module, module
+        )
+    }));
+    suggestions.extend(metadata.deprecated_dependencies.iter().map(|deprecated| {
+        format!(
+            "{} is {}; move to {}",
+            deprecated.module, deprecated.reason, deprecated.replacement
+        )
+    }));
+    if let Some(logging) = &logging {
+        if !metadata
+            .required_env_vars
+            .iter()
+            .any(|var| var == LOG_LEVEL_ENV)
+        {
+            suggestions.push(format!(
+                "Logging with {}; read the level from {} so verbosity can change per environment without a rebuild",
+                logging.library, LOG_LEVEL_ENV
+            ));
+        }
+    }
+    if temporal.is_some() {
+        suggestions.push(
+            "Temporal SDK in use: the service needs a reachable Temporal server (frontend on port 7233, e.g. temporalio/auto-setup); set TEMPORAL_ADDRESS to point at it"
+                .to_string(),
+        );
+    }
+    if stack.build_system == BuildSystemId::Conda
+        && fs.is_file(&service_path.join("requirements.txt"))
+    {
+        suggestions.push(
+            "Using the Conda environment; requirements.txt is also present, so `pip install -r requirements.txt` remains a fallback where conda is unavailable"
+                .to_string(),
+        );
+    }
+    if let Some(grpc) = metadata.grpc.as_ref().filter(|grpc| !grpc.generated) {
+        suggestions.push(format!(
+            "No generated gRPC stubs found; run `{}` before building",
+            grpc.build_command_prefix
+        ));
+    }
+
+    match wasm {
+        Some(target @ WasmTarget::Browser) => suggestions.push(format!(
+            "Browser WebAssembly build: run `{}` and serve main.wasm with wasm_exec.js from $(go env GOROOT)/lib/wasm (misc/wasm before Go 1.24)",
+            target.build_command()
+        )),
+        Some(target @ WasmTarget::Wasi) => suggestions.push(format!(
+            "WASI build: run `{}` and start it with a WASI runtime such as `wasmtime main.wasm` or `wasmer run main.wasm`",
+            target.build_command()
+        )),
+        None => {}
+    }
 
     let runtime = RuntimeStage {
         packages: runtime_packages,
         env: env_map,
         copy: runtime_copy,
         command: command_parts,
-        ports: vec![port],
-        health: runtime_config.and_then(|rc| rc.health.clone()),
+        ports: if listens { vec![port] } else { vec![] },
+        health_check_path: health.as_ref().map(|h| h.endpoint.clone()),
+        health,
+        health_check_hint,
+        auxiliary_commands,
+        detected_port: result
+            .port_detection
+            .as_ref()
+            .filter(|_| listens)
+            .and_then(|pd| pd.port),
+        port_from_env: listens && result.port_detection.as_ref().is_some_and(|pd| pd.from_env),
     };
 
+    let mut confidence = ConfidenceTracker::new();
+    record_stack_evidence(
+        &mut confidence,
+        result,
+        stack,
+        registry,
+        manifest_content.as_deref(),
+        &service_path,
+    );
+    if runnable {
+        confidence.record("command", command_evidence);
+    }
+    if listens {
+        confidence.record("port", port_evidence);
+    }
+    if go_sum_absent {
+        confidence.record("build_command", Evidence::Extracted(0.5));
+    }
+    // Commands CI runs are how the project is actually built and tested
+    if let Some(ci) = ci.as_ref() {
+        if build
+            .commands
+            .iter()
+            .any(|command| ci.confirms("build", command))
+        {
+            confidence.record("build_command", Evidence::Declared);
+        }
+        if metadata
+            .test_command
+            .as_deref()
+            .is_some_and(|command| ci.confirms("test", command))
+        {
+            confidence.record("test_command", Evidence::Declared);
+        }
+    }
+
+    // The Dockerfile augments source detection: agreement backs the port, disagreement is reported
+    let mut conflicts = Vec::new();
+    let exposed = result
+        .dockerfile
+        .as_ref()
+        .map(|df| df.expose.as_slice())
+        .unwrap_or_default();
+    let source_port = result.port_detection.as_ref().and_then(|pd| pd.source_port);
+    if let Some(source_port) = source_port.filter(|_| !exposed.is_empty()) {
+        if exposed.contains(&source_port) {
+            confidence.record("port", Evidence::Declared);
+        } else {
+            conflicts.push(DetectionConflict {
+                field: "port".to_string(),
+                detected: source_port.to_string(),
+                declared: exposed
+                    .iter()
+                    .map(u16::to_string)
+                    .collect::<Vec<_>>()
+                    .join(", "),
+                source: "Dockerfile".to_string(),
+            });
+        }
+    }
+
+    if let Some(kubernetes) = metadata.kubernetes.as_ref() {
+        conflicts.extend(probe_conflict(runtime.health.as_ref(), kubernetes));
+    }
+    if let Some(devcontainer) = metadata.devcontainer.as_ref() {
+        conflicts.extend(devcontainer_conflict(&stack.build_system, devcontainer));
+    }
+
     Ok(UniversalBuild {
         version: "1.0".to_string(),
         metadata,
+        confidence: confidence.scores(),
+        conflicts,
+        warnings,
+        suggestions,
+        validation_errors: Vec::new(),
         build,
         runtime,
     })
 }
 
+/// Version pins for a service; asdf resolves upwards, so repository-root pins fill the gaps
+fn service_version_pins(
+    repo_path: &Path,
+    service_path: &Path,
+    fs: &dyn FileSystem,
+) -> BTreeMap<String, VersionPin> {
+    let mut pins = read_version_pins(service_path, fs);
+    if service_path != repo_path {
+        for (runtime, pin) in read_version_pins(repo_path, fs) {
+            pins.entry(runtime).or_insert(pin);
+        }
+    }
+    pins
+}
+
+/// Warns when the manifest's language version disagrees with the tooling pin
+fn version_pin_warnings(
+    pins: &BTreeMap<String, VersionPin>,
+    registry: &StackRegistry,
+    result: &ServiceContext,
+    manifest_content: Option<&str>,
+) -> Vec<String> {
+    let Some(language) = registry.get_language(result.service.language.clone()) else {
+        return vec![];
+    };
+    let Some(pin) = language
+        .runtime_name()
+        .and_then(|runtime| pins.get(&runtime))
+    else {
+        return vec![];
+    };
+    match language.detect_version(manifest_content) {
+        Some(declared) if !versions_agree(&pin.version, &declared) => vec![format!(
+            "{} {} declared in {} disagrees with {} pinned in {}",
+            language.id().name(),
+            declared,
+            result.service.manifest,
+            pin.version,
+            pin.source
+        )],
+        _ => vec![],
+    }
+}
+
+/// Reports a health endpoint detected from source that neither Kubernetes probe requests
+fn probe_conflict(
+    health: Option<&HealthCheck>,
+    kubernetes: &KubernetesMetadata,
+) -> Option<DetectionConflict> {
+    let health = health?;
+    let probes: Vec<&String> = [&kubernetes.liveness_path, &kubernetes.readiness_path]
+        .into_iter()
+        .flatten()
+        .collect();
+    if probes.is_empty() || probes.contains(&&health.endpoint) {
+        return None;
+    }
+    Some(DetectionConflict {
+        field: "health_check_path".to_string(),
+        detected: health.endpoint.clone(),
+        declared: probes
+            .iter()
+            .map(|path| path.as_str())
+            .collect::<Vec<_>>()
+            .join(", "),
+        source: kubernetes.manifest.clone(),
+    })
+}
+
+/// Warns when a Bun lockfile sits next to another package manager's lockfile, which the
+/// Bun build ignores
+fn lockfile_conflict_warnings(
+    build_system: &BuildSystemId,
+    service_path: &Path,
+    fs: &dyn FileSystem,
+) -> Vec<String> {
+    if *build_system != BuildSystemId::Bun {
+        return vec![];
+    }
+    let Some(bun_lock) = ["bun.lockb", "bun.lock"]
+        .into_iter()
+        .find(|lock| fs.is_file(&service_path.join(lock)))
+    else {
+        return vec![];
+    };
+    ["package-lock.json", "yarn.lock", "pnpm-lock.yaml"]
+        .into_iter()
+        .filter(|lock| fs.is_file(&service_path.join(lock)))
+        .map(|lock| {
+            format!(
+                "Both {} and {} are committed; the build installs with Bun and ignores {}",
+                bun_lock, lock, lock
+            )
+        })
+        .collect()
+}
+
+/// Records the evidence behind the detected language, build system and framework
+fn record_stack_evidence(
+    tracker: &mut ConfidenceTracker,
+    result: &ServiceContext,
+    stack: &Stack,
+    registry: &StackRegistry,
+    manifest_content: Option<&str>,
+    service_path: &Path,
+) {
+    let manifest = result.service.manifest.as_str();
+
+    if let Some(language) = registry.get_language(stack.language.clone()) {
+        if let Some(detection) = language.detect(manifest, manifest_content) {
+            tracker.record("language", Evidence::Manifest(detection.confidence));
+        }
+        let extensions = language.extensions();
+        let has_sources = result.scan().is_ok_and(|scan| {
+            scan.file_tree.iter().any(|file| {
+                in_service(file, &result.service.path)
+                    && file
+                        .extension()
+                        .and_then(|ext| ext.to_str())
+                        .is_some_and(|ext| extensions.iter().any(|e| e == ext))
+            })
+        });
+        if has_sources {
+            tracker.record("language", Evidence::SourceExtension);
+        }
+    }
+    if tracker.score("language").is_none() {
+        tracker.record("language", Evidence::Fallback);
+    }
+
+    let declares_manifest = registry
+        .get_build_system(stack.build_system.clone())
+        .is_some_and(|bs| {
+            bs.manifest_patterns()
+                .iter()
+                .any(|pattern| match pattern.filename.strip_prefix('*') {
+                    Some(suffix) => manifest.ends_with(suffix),
+                    None => pattern.filename == manifest,
+                })
+        });
+    tracker.record(
+        "build_system",
+        if declares_manifest {
+            Evidence::Manifest(1.0)
+        } else {
+            Evidence::Fallback
+        },
+    );
+
+    // Frameworks are only ever matched against the manifest's dependencies
+    if let Some(framework) = stack
+        .framework
+        .as_ref()
+        .and_then(|id| registry.get_framework(id.clone()))
+    {
+        tracker.record("framework", Evidence::Dependency);
+        if framework
+            .config_files()
+            .iter()
+            .any(|file| result.fs().is_file(&service_path.join(file)))
+        {
+            tracker.record("framework", Evidence::ConfigFile);
+        }
+    }
+}
+
+/// Reports a `postCreateCommand` that installs dependencies with other build systems only
+fn devcontainer_conflict(
+    build_system: &BuildSystemId,
+    devcontainer: &DevContainerMetadata,
+) -> Option<DetectionConflict> {
+    let installs = DevContainerDetector::installs(devcontainer);
+    if installs.is_empty() || installs.contains(build_system) {
+        return None;
+    }
+    Some(DetectionConflict {
+        field: "build_system".to_string(),
+        detected: build_system.name(),
+        declared: installs
+            .iter()
+            .map(BuildSystemId::name)
+            .collect::<Vec<_>>()
+            .join(", "),
+        source: devcontainer.config_file.clone(),
+    })
+}
+
+fn in_service(file: &Path, service: &Path) -> bool {
+    service.as_os_str().is_empty() || service == Path::new(".") || file.starts_with(service)
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -213,9 +1810,95 @@ mod tests {
     use crate::pipeline::phases::cache::CacheInfo;
     use crate::pipeline::phases::service_analysis::Service;
     use crate::pipeline::Confidence;
+    use peelbox_core::fs::RealFileSystem;
     use std::path::PathBuf;
     use std::sync::Arc;
 
+    #[test]
+    fn test_probe_conflict() {
+        let kubernetes = KubernetesMetadata {
+            manifest: "k8s/deployment.yaml".to_string(),
+            kind: "Deployment".to_string(),
+            liveness_path: Some("/healthz".to_string()),
+            readiness_path: Some("/readyz".to_string()),
+            ..Default::default()
+        };
+        let health = |endpoint: &str| HealthCheck {
+            endpoint: endpoint.to_string(),
+        };
+
+        assert_eq!(probe_conflict(Some(&health("/readyz")), &kubernetes), None);
+        assert_eq!(probe_conflict(None, &kubernetes), None);
+        assert_eq!(
+            probe_conflict(Some(&health("/health")), &kubernetes),
+            Some(DetectionConflict {
+                field: "health_check_path".to_string(),
+                detected: "/health".to_string(),
+                declared: "/healthz, /readyz".to_string(),
+                source: "k8s/deployment.yaml".to_string(),
+            })
+        );
+        assert_eq!(
+            probe_conflict(Some(&health("/health")), &KubernetesMetadata::default()),
+            None
+        );
+    }
+
+    #[test]
+    fn test_devcontainer_conflict() {
+        let devcontainer = |post_create: &str| DevContainerMetadata {
+            config_file: ".devcontainer/devcontainer.json".to_string(),
+            post_create: Some(post_create.to_string()),
+            ..Default::default()
+        };
+
+        assert_eq!(
+            devcontainer_conflict(&BuildSystemId::GoMod, &devcontainer("go mod download")),
+            None
+        );
+        assert_eq!(
+            devcontainer_conflict(
+                &BuildSystemId::GoMod,
+                &devcontainer("npm install -g @redocly/cli")
+            ),
+            None
+        );
+        assert_eq!(
+            devcontainer_conflict(
+                &BuildSystemId::Poetry,
+                &devcontainer("pip install -r requirements.txt")
+            ),
+            Some(DetectionConflict {
+                field: "build_system".to_string(),
+                detected: "Poetry".to_string(),
+                declared: "pip".to_string(),
+                source: ".devcontainer/devcontainer.json".to_string(),
+            })
+        );
+        assert_eq!(
+            devcontainer_conflict(&BuildSystemId::Pnpm, &DevContainerMetadata::default()),
+            None
+        );
+    }
+
+    #[test]
+    fn test_lockfile_conflict_warnings() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(dir.path().join("bun.lockb"), [0u8]).unwrap();
+        assert!(
+            lockfile_conflict_warnings(&BuildSystemId::Bun, dir.path(), &RealFileSystem).is_empty()
+        );
+
+        std::fs::write(dir.path().join("package-lock.json"), "{}").unwrap();
+        assert_eq!(
+            lockfile_conflict_warnings(&BuildSystemId::Bun, dir.path(), &RealFileSystem),
+            vec!["Both bun.lockb and package-lock.json are committed; the build installs with Bun and ignores package-lock.json"]
+        );
+        assert!(
+            lockfile_conflict_warnings(&BuildSystemId::Npm, dir.path(), &RealFileSystem).is_empty()
+        );
+    }
+
     #[test]
     fn test_confidence_calculation() {
         let service = Service {
@@ -246,10 +1929,16 @@ mod tests {
                 version: None,
             }),
             runtime_config: None,
+            port_detection: None,
+            dockerfile: None,
+            required_env_vars: vec![],
             build: Some(BuildInfo {
                 build_cmd: vec!["npm run build".to_string()],
                 output_dir: Some(PathBuf::from("dist")),
                 confidence: Confidence::High,
+                source: Default::default(),
+                makefile_targets: Default::default(),
+                artifact: None,
             }),
             cache: Some(CacheInfo {
                 cache_dirs: vec![],
@@ -1,8 +1,11 @@
 use super::context::AnalysisContext;
 use super::phases::{
-    build::BuildInfo, cache::CacheInfo, scan::ScanResult, service_analysis::Service,
+    build::BuildInfo, cache::CacheInfo, runtime_config::PortDetection, scan::ScanResult,
+    service_analysis::Service,
 };
+use crate::extractors::parsers::dockerfile::DockerfileInfo;
 use anyhow::Result;
+use peelbox_core::fs::FileSystem;
 use peelbox_stack::runtime::RuntimeConfig;
 use peelbox_stack::{BuildSystemId, FrameworkId, LanguageId, RuntimeId, StackRegistry};
 use std::path::Path;
@@ -26,6 +29,10 @@ pub struct ServiceContext {
     // Phase results
     pub stack: Option<Stack>,
     pub runtime_config: Option<RuntimeConfig>,
+    pub port_detection: Option<PortDetection>,
+    pub dockerfile: Option<DockerfileInfo>,
+    /// Environment variables the service's source code reads
+    pub required_env_vars: Vec<String>,
     pub build: Option<BuildInfo>,
     pub cache: Option<CacheInfo>,
 }
@@ -37,6 +44,9 @@ impl ServiceContext {
             analysis_context,
             stack: None,
             runtime_config: None,
+            port_detection: None,
+            dockerfile: None,
+            required_env_vars: vec![],
             build: None,
             cache: None,
         }
@@ -46,6 +56,10 @@ impl ServiceContext {
         &self.analysis_context.repo_path
     }
 
+    pub fn fs(&self) -> &dyn FileSystem {
+        self.analysis_context.fs.as_ref()
+    }
+
     pub fn scan(&self) -> Result<&ScanResult> {
         self.analysis_context
             .scan
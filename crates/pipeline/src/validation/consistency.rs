@@ -0,0 +1,364 @@
+//! Consistency checks - problems in an assembled result that don't stop it from being used
+//!
+//! Unlike the rules in `rules.rs`, which reject a build outright, these report fields that
+//! disagree with each other so they can be surfaced as `validation_errors` next to the result.
+
+use peelbox_core::output::schema::{UniversalBuild, ValidationError};
+use regex::Regex;
+use std::collections::HashSet;
+use std::path::Path;
+
+/// Languages whose runtime image needs the build stage's output copied in, by display name
+const COMPILED_LANGUAGES: [&str; 11] = [
+    "Go", "Rust", "C++", "Haskell", "Swift", "Zig", "Java", "Kotlin", "Scala", "C#", "F#",
+];
+
+/// Directories runtime packages install executables and libraries into
+const SYSTEM_PREFIXES: [&str; 6] = [
+    "/bin/",
+    "/sbin/",
+    "/usr/bin/",
+    "/usr/sbin/",
+    "/usr/lib/",
+    "/usr/libexec/",
+];
+
+/// Checks the result for fields that contradict each other, in field order
+pub fn validate_consistency(build: &UniversalBuild) -> Vec<ValidationError> {
+    let mut errors = Vec::new();
+    required_fields(build, &mut errors);
+    artifacts(build, &mut errors);
+    ports(build, &mut errors);
+    duplicates(build, &mut errors);
+    versions(build, &mut errors);
+    errors
+}
+
+fn error(field: impl Into<String>, code: &str, message: String) -> ValidationError {
+    ValidationError {
+        field: field.into(),
+        code: code.to_string(),
+        message,
+    }
+}
+
+fn required_fields(build: &UniversalBuild, errors: &mut Vec<ValidationError>) {
+    let language = &build.metadata.language;
+    if build.build.packages.is_empty() {
+        errors.push(error(
+            "build.packages",
+            "missing_field",
+            format!("No build packages for a {} project", language),
+        ));
+    }
+    let compiled = COMPILED_LANGUAGES
+        .iter()
+        .any(|name| name.eq_ignore_ascii_case(language));
+    if compiled && build.runtime.copy.is_empty() {
+        errors.push(error(
+            "runtime.copy",
+            "missing_field",
+            format!(
+                "{} builds an artifact, but nothing is copied into the runtime image",
+                language
+            ),
+        ));
+    }
+}
+
+/// The runtime command runs what the copy specs install, and the copy specs take what the
+/// build commands write with `-o`/`--output`
+fn artifacts(build: &UniversalBuild, errors: &mut Vec<ValidationError>) {
+    let installed: Vec<&Path> = build
+        .runtime
+        .copy
+        .iter()
+        .map(|copy| Path::new(copy.to.as_str()))
+        .collect();
+    for path in build
+        .runtime
+        .command
+        .iter()
+        .flat_map(|arg| arg.split(':'))
+        .map(|arg| arg.trim_end_matches("/*"))
+        .filter(|arg| arg.starts_with('/'))
+        .filter(|arg| !SYSTEM_PREFIXES.iter().any(|prefix| arg.starts_with(prefix)))
+    {
+        if !installed
+            .iter()
+            .any(|dest| Path::new(path).starts_with(dest))
+        {
+            errors.push(error(
+                "runtime.command",
+                "artifact_not_copied",
+                format!("{} is not installed by any runtime copy", path),
+            ));
+        }
+    }
+
+    let output =
+        Regex::new(r"(?:^|\s)(?:-o|--output)[\s=]+([^\s;&|]+)").expect("valid output regex");
+    let outputs: Vec<&str> = build
+        .build
+        .commands
+        .iter()
+        .flat_map(|command| output.captures_iter(command))
+        .filter_map(|caps| caps.get(1))
+        .map(|path| path.as_str().trim_start_matches("./"))
+        .collect();
+    if outputs.is_empty() {
+        return;
+    }
+    for (index, copy) in build.runtime.copy.iter().enumerate() {
+        let from = copy.from.trim_start_matches("./");
+        let built = outputs.iter().any(|output| {
+            Path::new(from).starts_with(output) || Path::new(output).starts_with(from)
+        });
+        if !built {
+            errors.push(error(
+                format!("runtime.copy[{}].from", index),
+                "artifact_not_built",
+                format!(
+                    "{} is not written by the build commands, which output {}",
+                    copy.from,
+                    outputs.join(", ")
+                ),
+            ));
+        }
+    }
+}
+
+fn ports(build: &UniversalBuild, errors: &mut Vec<ValidationError>) {
+    let ports = build
+        .runtime
+        .ports
+        .iter()
+        .map(|port| ("runtime.ports", *port))
+        .chain(
+            build
+                .runtime
+                .detected_port
+                .map(|port| ("runtime.detected_port", port)),
+        );
+    for (field, port) in ports {
+        // u16 caps ports at 65535, leaving 0 as the only invalid value
+        if port == 0 {
+            errors.push(error(
+                field,
+                "invalid_port",
+                "Port 0 is outside the valid range 1-65535".to_string(),
+            ));
+        }
+    }
+}
+
+fn duplicates(build: &UniversalBuild, errors: &mut Vec<ValidationError>) {
+    let ports: Vec<String> = build.runtime.ports.iter().map(u16::to_string).collect();
+    let destinations: Vec<String> = build.runtime.copy.iter().map(|c| c.to.clone()).collect();
+    let lists: [(&str, &[String]); 5] = [
+        ("build.packages", &build.build.packages),
+        ("build.cache", &build.build.cache),
+        ("runtime.packages", &build.runtime.packages),
+        ("runtime.ports", &ports),
+        ("runtime.copy", &destinations),
+    ];
+    for (field, values) in lists {
+        let mut seen = HashSet::new();
+        let mut reported = HashSet::new();
+        for value in values {
+            if !seen.insert(value) && reported.insert(value) {
+                errors.push(error(
+                    field,
+                    "duplicate_entry",
+                    format!("{} is listed more than once", value),
+                ));
+            }
+        }
+    }
+}
+
+/// Versions are `MAJOR[.MINOR[.PATCH]]` with optional pre-release and build suffixes;
+/// framework versions may come from a manifest requirement such as `^4.19.2`
+fn versions(build: &UniversalBuild, errors: &mut Vec<ValidationError>) {
+    let semver = Regex::new(r"^v?\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$")
+        .expect("valid semver regex");
+    let metadata = &build.metadata;
+    let versions = [
+        ("version", Some(build.version.as_str())),
+        (
+            "metadata.framework_version.version",
+            metadata.framework_version.as_ref().map(|fw| {
+                fw.version
+                    .trim_start_matches(['^', '~', '>', '<', '=', ' '])
+            }),
+        ),
+        (
+            "metadata.go_module_version",
+            metadata.go_module_version.as_deref(),
+        ),
+        (
+            "metadata.go_toolchain_version",
+            metadata
+                .go_toolchain_version
+                .as_deref()
+                .map(|version| version.trim_start_matches("go")),
+        ),
+    ];
+    for (field, version) in versions {
+        if let Some(version) = version.filter(|version| !semver.is_match(version)) {
+            errors.push(error(
+                field,
+                "invalid_version",
+                format!("{:?} is not a semantic version", version),
+            ));
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::output::schema::{
+        BuildMetadata, BuildStage, CopySpec, FrameworkVersion, RuntimeStage,
+    };
+
+    fn go_build() -> UniversalBuild {
+        UniversalBuild {
+            version: "1.0".to_string(),
+            metadata: BuildMetadata {
+                project_name: Some("blog".to_string()),
+                language: "Go".to_string(),
+                build_system: "go mod".to_string(),
+                go_module_version: Some("1.22".to_string()),
+                ..Default::default()
+            },
+            build: BuildStage {
+                packages: vec!["go-1.22".to_string()],
+                commands: vec![
+                    "go mod download".to_string(),
+                    "go build -o app .".to_string(),
+                ],
+                cache: vec![".cache/go-build".to_string()],
+                ..Default::default()
+            },
+            runtime: RuntimeStage {
+                packages: vec!["glibc".to_string(), "ca-certificates".to_string()],
+                copy: vec![CopySpec {
+                    from: "app".to_string(),
+                    to: "/usr/local/bin/blog".to_string(),
+                }],
+                command: vec!["/usr/local/bin/blog".to_string()],
+                ports: vec![8080],
+                ..Default::default()
+            },
+            confidence: Default::default(),
+            conflicts: vec![],
+            warnings: vec![],
+            suggestions: vec![],
+            validation_errors: vec![],
+        }
+    }
+
+    fn codes(build: &UniversalBuild) -> Vec<(String, String)> {
+        validate_consistency(build)
+            .into_iter()
+            .map(|e| (e.field, e.code))
+            .collect()
+    }
+
+    fn pair(field: &str, code: &str) -> (String, String) {
+        (field.to_string(), code.to_string())
+    }
+
+    #[test]
+    fn test_consistent_build() {
+        assert_eq!(validate_consistency(&go_build()), vec![]);
+    }
+
+    #[test]
+    fn test_missing_runtime_copy_for_compiled_language() {
+        let mut build = go_build();
+        build.runtime.copy.clear();
+        assert_eq!(
+            codes(&build),
+            vec![
+                pair("runtime.copy", "missing_field"),
+                pair("runtime.command", "artifact_not_copied"),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_copy_of_unbuilt_artifact() {
+        let mut build = go_build();
+        build.runtime.copy[0].from = "bin/blog".to_string();
+        let errors = validate_consistency(&build);
+        assert_eq!(errors.len(), 1);
+        assert_eq!(errors[0].field, "runtime.copy[0].from");
+        assert_eq!(errors[0].code, "artifact_not_built");
+        assert_eq!(
+            errors[0].message,
+            "bin/blog is not written by the build commands, which output app"
+        );
+    }
+
+    #[test]
+    fn test_system_binaries_and_classpaths() {
+        let mut build = go_build();
+        build.runtime.command = vec![
+            "/usr/lib/jvm/java-17-openjdk/bin/java".to_string(),
+            "-cp".to_string(),
+            "/app/classes:/app/lib/*".to_string(),
+        ];
+        build.runtime.copy = vec![
+            CopySpec {
+                from: "app".to_string(),
+                to: "/app/classes".to_string(),
+            },
+            CopySpec {
+                from: "app".to_string(),
+                to: "/app/lib".to_string(),
+            },
+        ];
+        assert_eq!(codes(&build), vec![]);
+    }
+
+    #[test]
+    fn test_invalid_port_and_duplicates() {
+        let mut build = go_build();
+        build.runtime.ports = vec![0, 8080, 8080];
+        build.runtime.packages.push("glibc".to_string());
+        build.runtime.packages.push("glibc".to_string());
+        assert_eq!(
+            codes(&build),
+            vec![
+                pair("runtime.ports", "invalid_port"),
+                pair("runtime.packages", "duplicate_entry"),
+                pair("runtime.ports", "duplicate_entry"),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_versions() {
+        let mut build = go_build();
+        build.metadata.framework_version = Some(FrameworkVersion {
+            framework: "Gin".to_string(),
+            version: "v1.10.0".to_string(),
+            module: "github.com/gin-gonic/gin".to_string(),
+        });
+        build.metadata.go_toolchain_version = Some("go1.22.3".to_string());
+        assert_eq!(codes(&build), vec![]);
+
+        build.metadata.framework_version.as_mut().unwrap().version = "^4.19".to_string();
+        build.metadata.go_module_version = Some("latest".to_string());
+        build.version = "one".to_string();
+        assert_eq!(
+            codes(&build),
+            vec![
+                pair("version", "invalid_version"),
+                pair("metadata.go_module_version", "invalid_version"),
+            ]
+        );
+    }
+}
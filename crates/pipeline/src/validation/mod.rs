@@ -1,5 +1,7 @@
+pub mod consistency;
 pub mod rules;
 pub mod validator;
 
+pub use consistency::validate_consistency;
 pub use peelbox_wolfi::WolfiPackageIndex;
 pub use validator::Validator;
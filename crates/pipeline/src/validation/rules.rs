@@ -19,7 +19,8 @@ pub fn validate_non_empty_commands(build: &UniversalBuild) -> Result<()> {
     if build.build.commands.is_empty() {
         anyhow::bail!("Build commands cannot be empty");
     }
-    if build.runtime.command.is_empty() {
+    // A library crate is only compiled, so it has nothing to start
+    if build.runtime.command.is_empty() && build.metadata.crate_type.as_deref() != Some("library") {
         anyhow::bail!("Runtime command cannot be empty");
     }
     Ok(())
@@ -169,7 +170,13 @@ mod tests {
                 build_system: "cargo".to_string(),
                 framework: None,
                 reasoning: "Detected Cargo.toml".to_string(),
+                ..Default::default()
             },
+            confidence: Default::default(),
+            conflicts: vec![],
+            warnings: vec![],
+            suggestions: vec![],
+            validation_errors: vec![],
             build: BuildStage {
                 packages: vec![
                     rust_package,
@@ -191,6 +198,7 @@ mod tests {
                 command: vec!["app".to_string()],
                 ports: vec![],
                 health: None,
+                ..Default::default()
             },
         }
     }
@@ -58,7 +58,13 @@ mod tests {
                 build_system: "cargo".to_string(),
                 framework: None,
                 reasoning: "Detected Cargo.toml".to_string(),
+                ..Default::default()
             },
+            confidence: Default::default(),
+            conflicts: vec![],
+            warnings: vec![],
+            suggestions: vec![],
+            validation_errors: vec![],
             build: BuildStage {
                 packages: vec!["rust".to_string(), "build-base".to_string()],
                 env: HashMap::new(),
@@ -75,6 +81,7 @@ mod tests {
                 command: vec!["app".to_string()],
                 ports: vec![],
                 health: None,
+                ..Default::default()
             },
         }
     }
@@ -105,4 +112,15 @@ mod tests {
         assert!(result.is_err());
         assert!(result.unwrap_err().to_string().contains("NonEmptyCommands"));
     }
+
+    #[test]
+    fn test_validator_library_crate_without_runtime_command() {
+        let mut build = create_minimal_valid_build();
+        build.runtime.command = vec![];
+        let validator = Validator::new();
+        assert!(validator.validate(&build).is_err());
+
+        build.metadata.crate_type = Some("library".to_string());
+        assert!(validator.validate(&build).is_ok());
+    }
 }
@@ -11,6 +11,9 @@ crate::define_id_enum! {
         Pip => "pip" : "pip",
         Poetry => "poetry" : "Poetry" | "poetry",
         Pipenv => "pipenv" : "Pipenv" | "pipenv",
+        Pdm => "pdm" : "PDM" | "pdm",
+        Hatch => "hatch" : "Hatch" | "hatch",
+        Conda => "conda" : "Conda" | "conda",
         GoMod => "go-mod" : "go mod" | "go-mod",
         DotNet => "dotnet" : ".NET" | "dotnet",
         Composer => "composer" : "Composer" | "composer",
@@ -19,6 +22,14 @@ crate::define_id_enum! {
         Make => "make" : "Make" | "make",
         Meson => "meson" : "Meson" | "meson",
         Mix => "mix" : "Mix" | "mix",
+        SwiftPm => "swiftpm" : "SwiftPM" | "swift",
+        Sbt => "sbt" : "sbt",
+        Mill => "mill" : "Mill" | "mill",
+        Stack => "stack" : "Stack" | "stack",
+        Cabal => "cabal" : "Cabal" | "cabal",
+        Deno => "deno" : "deno",
+        DartPub => "dart-pub" : "dart pub" | "dart-pub" | "pub",
+        Bazel => "bazel" : "bazel" | "Bazel",
     }
 }
 
@@ -46,15 +57,15 @@ mod tests {
 
     #[test]
     fn test_custom_build_system_serialization() {
-        let custom = BuildSystemId::Custom("Bazel".to_string());
-        assert_eq!(serde_json::to_string(&custom).unwrap(), "\"Bazel\"");
+        let custom = BuildSystemId::Custom("Buck2".to_string());
+        assert_eq!(serde_json::to_string(&custom).unwrap(), "\"Buck2\"");
     }
 
     #[test]
     fn test_custom_build_system_deserialization() {
-        let deserialized: BuildSystemId = serde_json::from_str("\"bazel\"").unwrap();
-        assert_eq!(deserialized, BuildSystemId::Custom("bazel".to_string()));
-        assert_eq!(deserialized.name(), "bazel");
+        let deserialized: BuildSystemId = serde_json::from_str("\"buck2\"").unwrap();
+        assert_eq!(deserialized, BuildSystemId::Custom("buck2".to_string()));
+        assert_eq!(deserialized.name(), "buck2");
     }
 
     #[test]
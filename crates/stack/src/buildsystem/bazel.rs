@@ -0,0 +1,439 @@
+//! Bazel build system (cc_binary, go_binary, java_binary and py_binary targets)
+
+use super::{BuildSystem, BuildTemplate, ManifestPattern};
+use crate::{BuildSystemId, DetectionStack, LanguageId};
+use anyhow::Result;
+use peelbox_core::fs::{FileSystem, FileType};
+use peelbox_core::output::schema::BazelMetadata;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+const WORKSPACE_FILES: [&str; 3] = ["MODULE.bazel", "WORKSPACE.bazel", "WORKSPACE"];
+
+/// Package files declaring the workspace's targets
+pub const BUILD_FILES: [&str; 2] = ["BUILD.bazel", "BUILD"];
+
+/// Language manifests a Bazel workspace keeps next to its WORKSPACE for IDEs and tooling
+const NATIVE_MANIFESTS: [&str; 4] = ["go.mod", "pom.xml", "pyproject.toml", "requirements.txt"];
+
+pub struct BazelBuildSystem;
+
+impl BuildSystem for BazelBuildSystem {
+    fn id(&self) -> BuildSystemId {
+        BuildSystemId::Bazel
+    }
+
+    fn manifest_patterns(&self) -> Vec<ManifestPattern> {
+        // Outranks go.mod, pom.xml and friends in the same directory: Bazel drives the build
+        WORKSPACE_FILES
+            .iter()
+            .map(|file| ManifestPattern {
+                filename: file.to_string(),
+                priority: 20,
+            })
+            .collect()
+    }
+
+    fn detect_all(
+        &self,
+        repo_root: &Path,
+        file_tree: &[PathBuf],
+        fs: &dyn FileSystem,
+    ) -> Result<Vec<DetectionStack>> {
+        let workspace_rank = |path: &Path| {
+            let name = path.file_name().and_then(|n| n.to_str())?;
+            WORKSPACE_FILES.iter().position(|file| *file == name)
+        };
+        let mut detections = Vec::new();
+
+        for rel_path in file_tree {
+            let Some(rank) = workspace_rank(rel_path.as_path()) else {
+                continue;
+            };
+            let workspace_dir = rel_path.parent().unwrap_or_else(|| Path::new(""));
+            // MODULE.bazel and WORKSPACE often sit side by side; the module names the project
+            let shadowed = file_tree.iter().any(|other| {
+                other.parent() == Some(workspace_dir)
+                    && workspace_rank(other.as_path()).is_some_and(|other_rank| other_rank < rank)
+            });
+            if shadowed {
+                continue;
+            }
+
+            let binaries = file_tree
+                .iter()
+                .filter(|path| is_build_file(path))
+                .filter_map(|build_file| {
+                    let package = build_file.parent()?.strip_prefix(workspace_dir).ok()?;
+                    let content = fs.read_to_string(&repo_root.join(build_file)).ok()?;
+                    Some(parse_binaries(&package_name(package), &content))
+                })
+                .flatten()
+                .collect();
+
+            // A workspace without binaries has nothing to run; leave it to the language's
+            // own build system
+            if let Some(binary) = primary_binary(binaries) {
+                detections.push(DetectionStack::new(
+                    BuildSystemId::Bazel,
+                    binary.language(),
+                    rel_path.clone(),
+                ));
+            }
+        }
+
+        Ok(detections)
+    }
+
+    fn build_template(
+        &self,
+        wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        service_path: &Path,
+        _manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> BuildTemplate {
+        let tool = bazel_tool(service_path, fs);
+        let mut build_packages = vec![match tool {
+            "bazelisk" => "bazelisk".to_string(),
+            _ => wolfi_index
+                .get_latest_version("bazel")
+                .unwrap_or_else(|| "bazel-8".to_string()),
+        }];
+        // The auto-configured C++ toolchain needs a host compiler for every language
+        build_packages.push("build-base".to_string());
+
+        let binary = primary_binary(workspace_binaries(service_path, fs));
+        let (build_commands, runtime_copy) = match &binary {
+            Some(binary) => {
+                match binary.language() {
+                    LanguageId::Python => build_packages.push(
+                        wolfi_index
+                            .get_latest_version("python")
+                            .unwrap_or_else(|| "python-3.12".to_string()),
+                    ),
+                    LanguageId::Java => build_packages.push(
+                        wolfi_index
+                            .get_latest_version("openjdk")
+                            .unwrap_or_else(|| "openjdk-21".to_string()),
+                    ),
+                    _ => {}
+                }
+                (
+                    vec![binary.build_command(tool)],
+                    vec![(binary.artifact(), binary.install_path())],
+                )
+            }
+            None => (vec![format!("{} build //...", tool)], vec![]),
+        };
+
+        BuildTemplate {
+            build_packages,
+            build_commands,
+            // bazel-bin links into the output base, so artifacts are copied while it is mounted
+            cache_paths: vec!["/root/.cache/bazel/".to_string()],
+            common_ports: vec![],
+            build_env: std::collections::HashMap::new(),
+            runtime_copy,
+            runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
+        }
+    }
+
+    fn cache_dirs(&self) -> Vec<String> {
+        vec!["/root/.cache/bazel".to_string()]
+    }
+
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        _manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let binary = primary_binary(workspace_binaries(service_path, fs))?;
+        Some(match binary.language() {
+            LanguageId::Java => format!("java -jar {}", binary.install_path()),
+            LanguageId::Python => format!("python3 {}", binary.install_path()),
+            _ => binary.install_path(),
+        })
+    }
+
+    fn parse_package_metadata(
+        &self,
+        manifest_content: &str,
+    ) -> Result<(String, bool), anyhow::Error> {
+        // `module(name = "greeter")` in MODULE.bazel, `workspace(name = "greeter")` in WORKSPACE
+        let re =
+            Regex::new(r#"\b(?:module|workspace)\s*\(\s*(?:[^)]*?,\s*)?name\s*=\s*"([^"]+)""#)?;
+        match re.captures(manifest_content) {
+            Some(caps) => Ok((caps[1].to_string(), true)),
+            None => Err(anyhow::anyhow!("Bazel workspace does not set a name")),
+        }
+    }
+}
+
+/// A `*_binary` rule declared in a BUILD file
+#[derive(Debug, Clone, PartialEq)]
+pub struct BazelBinary {
+    /// `cc_binary`, `go_binary`, `java_binary` or `py_binary`
+    pub rule: String,
+    /// Package path from the workspace root; empty for the root package
+    pub package: String,
+    pub name: String,
+}
+
+impl BazelBinary {
+    /// `//cmd/server:server`, or `//:hello` in the root package
+    pub fn label(&self) -> String {
+        format!("//{}:{}", self.package, self.name)
+    }
+
+    pub fn language(&self) -> LanguageId {
+        match self.rule.as_str() {
+            "go_binary" => LanguageId::Go,
+            "java_binary" => LanguageId::Java,
+            "py_binary" => LanguageId::Python,
+            _ => LanguageId::Cpp,
+        }
+    }
+
+    /// Builds what the container runs: java_binary's self-contained deploy jar and
+    /// py_binary as a zip that carries its runfiles
+    pub fn build_command(&self, tool: &str) -> String {
+        match self.rule.as_str() {
+            "java_binary" => format!("{} build {}_deploy.jar", tool, self.label()),
+            "py_binary" => format!("{} build --build_python_zip {}", tool, self.label()),
+            _ => format!("{} build {}", tool, self.label()),
+        }
+    }
+
+    /// Output under bazel-bin; rules_go nests binaries in a `<name>_` directory
+    fn artifact(&self) -> String {
+        let dir = if self.package.is_empty() {
+            "bazel-bin".to_string()
+        } else {
+            format!("bazel-bin/{}", self.package)
+        };
+        match self.rule.as_str() {
+            "go_binary" => format!("{}/{}_/{}", dir, self.name, self.name),
+            "java_binary" => format!("{}/{}_deploy.jar", dir, self.name),
+            "py_binary" => format!("{}/{}.zip", dir, self.name),
+            _ => format!("{}/{}", dir, self.name),
+        }
+    }
+
+    fn install_path(&self) -> String {
+        match self.rule.as_str() {
+            "java_binary" => format!("/app/{}_deploy.jar", self.name),
+            "py_binary" => format!("/app/{}.zip", self.name),
+            _ => format!("/usr/local/bin/{}", self.name),
+        }
+    }
+}
+
+/// Binary rules in a BUILD file, in declaration order
+pub fn parse_binaries(package: &str, content: &str) -> Vec<BazelBinary> {
+    let rule_re = Regex::new(r"(?m)^\s*(cc_binary|go_binary|java_binary|py_binary)\s*\(")
+        .expect("valid rule regex");
+    let name_re = Regex::new(r#"\bname\s*=\s*"([^"]+)""#).expect("valid name regex");
+
+    rule_re
+        .captures_iter(content)
+        .filter_map(|caps| {
+            let start = caps.get(0)?.end();
+            let body = rule_body(&content[start..]);
+            let name = name_re.captures(body)?[1].to_string();
+            Some(BazelBinary {
+                rule: caps[1].to_string(),
+                package: package.to_string(),
+                name,
+            })
+        })
+        .collect()
+}
+
+/// Arguments of a rule call, up to its closing parenthesis
+fn rule_body(rest: &str) -> &str {
+    let mut depth = 1;
+    let mut in_string = None;
+    for (i, c) in rest.char_indices() {
+        match (in_string, c) {
+            (Some(quote), _) if c == quote => in_string = None,
+            (Some(_), _) => {}
+            (None, '"' | '\'') => in_string = Some(c),
+            (None, '(') => depth += 1,
+            (None, ')') => {
+                depth -= 1;
+                if depth == 0 {
+                    return &rest[..i];
+                }
+            }
+            _ => {}
+        }
+    }
+    rest
+}
+
+/// The binary closest to the workspace root; the first declared wins within a package
+pub fn primary_binary(mut binaries: Vec<BazelBinary>) -> Option<BazelBinary> {
+    binaries.sort_by_key(|binary| {
+        let depth = match binary.package.as_str() {
+            "" => 0,
+            package => package.split('/').count(),
+        };
+        (depth, binary.package.clone())
+    });
+    binaries.into_iter().next()
+}
+
+/// Every binary rule under a workspace root, skipping Bazel's convenience symlinks
+pub fn workspace_binaries(workspace_root: &Path, fs: &dyn FileSystem) -> Vec<BazelBinary> {
+    let mut binaries = Vec::new();
+    let mut dirs = vec![workspace_root.to_path_buf()];
+    while let Some(dir) = dirs.pop() {
+        let Ok(entries) = fs.read_dir(&dir) else {
+            continue;
+        };
+        for entry in entries {
+            let name = entry.file_name();
+            if entry.file_type() == FileType::Directory {
+                if !name.starts_with("bazel-") && !name.starts_with('.') {
+                    dirs.push(entry.path);
+                }
+            } else if BUILD_FILES.contains(&name) {
+                let package = dir.strip_prefix(workspace_root).unwrap_or(Path::new(""));
+                if let Ok(content) = fs.read_to_string(entry.path()) {
+                    binaries.extend(parse_binaries(&package_name(package), &content));
+                }
+            }
+        }
+    }
+    binaries
+}
+
+/// `bazelisk` when the repository pins a Bazel release for it to fetch, else `bazel`
+pub fn bazel_tool(workspace_root: &Path, fs: &dyn FileSystem) -> &'static str {
+    if fs.is_file(&workspace_root.join(".bazelversion"))
+        || fs.is_file(&workspace_root.join(".bazeliskrc"))
+    {
+        "bazelisk"
+    } else {
+        "bazel"
+    }
+}
+
+/// Release pinned by .bazelversion, e.g. `7.4.1`
+pub fn bazel_version(workspace_root: &Path, fs: &dyn FileSystem) -> Option<String> {
+    fs.read_to_string(&workspace_root.join(".bazelversion"))
+        .ok()
+        .and_then(|content| content.lines().next().map(|line| line.trim().to_string()))
+        .filter(|version| !version.is_empty())
+}
+
+/// Primary binary of the workspace, how it is built and the pinned Bazel release
+pub fn inspect_bazel_workspace(
+    workspace_root: &Path,
+    fs: &dyn FileSystem,
+) -> Option<BazelMetadata> {
+    let binary = primary_binary(workspace_binaries(workspace_root, fs))?;
+    let tool = bazel_tool(workspace_root, fs);
+    Some(BazelMetadata {
+        target: binary.label(),
+        rule: binary.rule.clone(),
+        build_command: binary.build_command(tool),
+        tool: tool.to_string(),
+        version: bazel_version(workspace_root, fs),
+    })
+}
+
+/// go.mod, pom.xml or Python requirements kept next to the workspace, which list the
+/// dependencies frameworks are detected from
+pub fn native_manifest(workspace_root: &Path, fs: &dyn FileSystem) -> Option<String> {
+    NATIVE_MANIFESTS
+        .iter()
+        .find(|file| fs.is_file(&workspace_root.join(file)))
+        .map(|file| file.to_string())
+}
+
+fn is_build_file(path: &Path) -> bool {
+    path.file_name()
+        .and_then(|n| n.to_str())
+        .is_some_and(|name| BUILD_FILES.contains(&name))
+}
+
+fn package_name(package: &Path) -> String {
+    package.to_string_lossy().replace('\\', "/")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    #[test]
+    fn test_parse_binaries() {
+        let binaries = parse_binaries(
+            "cmd/server",
+            r#"load("@rules_go//go:def.bzl", "go_binary", "go_library")
+
+go_library(
+    name = "server_lib",
+    srcs = ["main.go"],
+)
+
+go_binary(
+    name = "server",
+    embed = [":server_lib"],
+    visibility = ["//visibility:public"],
+)
+"#,
+        );
+        assert_eq!(binaries.len(), 1);
+        assert_eq!(binaries[0].label(), "//cmd/server:server");
+        assert_eq!(binaries[0].language(), LanguageId::Go);
+        assert_eq!(
+            binaries[0].artifact(),
+            "bazel-bin/cmd/server/server_/server"
+        );
+    }
+
+    #[test]
+    fn test_primary_binary_is_closest_to_root() {
+        let mut binaries = parse_binaries("tools/gen", "cc_binary(name = \"gen\")\n");
+        binaries.extend(parse_binaries(
+            "",
+            "java_binary(\n    name = \"app\",\n    main_class = \"com.example.App\",\n)\n",
+        ));
+        let primary = primary_binary(binaries).unwrap();
+        assert_eq!(primary.label(), "//:app");
+        assert_eq!(
+            primary.build_command("bazel"),
+            "bazel build //:app_deploy.jar"
+        );
+    }
+
+    #[test]
+    fn test_bazelversion_selects_bazelisk() {
+        let dir = tempfile::tempdir().unwrap();
+        assert_eq!(bazel_tool(dir.path(), &RealFileSystem), "bazel");
+        std::fs::write(dir.path().join(".bazelversion"), "7.4.1\n").unwrap();
+        assert_eq!(bazel_tool(dir.path(), &RealFileSystem), "bazelisk");
+        assert_eq!(
+            bazel_version(dir.path(), &RealFileSystem).as_deref(),
+            Some("7.4.1")
+        );
+    }
+
+    #[test]
+    fn test_workspace_name() {
+        assert_eq!(
+            BazelBuildSystem
+                .parse_package_metadata(
+                    "module(\n    name = \"greeter\",\n    version = \"0.1.0\",\n)\n"
+                )
+                .unwrap()
+                .0,
+            "greeter"
+        );
+        assert!(BazelBuildSystem.parse_package_metadata("").is_err());
+    }
+}
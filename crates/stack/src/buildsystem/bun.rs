@@ -1,6 +1,6 @@
 //! Bun build system (JavaScript/TypeScript)
 
-use super::node_common::{parse_node_version, read_node_version_file};
+use super::node_common::{node_build_commands, parse_node_version, read_node_version_file};
 use super::{BuildSystem, BuildTemplate, ManifestPattern};
 use crate::{BuildSystemId, DetectionStack, LanguageId};
 use anyhow::Result;
@@ -20,6 +20,14 @@ impl BuildSystem for BunBuildSystem {
                 filename: "bun.lockb".to_string(),
                 priority: 15,
             },
+            ManifestPattern {
+                filename: "bun.lock".to_string(),
+                priority: 15,
+            },
+            ManifestPattern {
+                filename: "bunfig.toml".to_string(),
+                priority: 15,
+            },
             ManifestPattern {
                 filename: "package.json".to_string(),
                 priority: 10,
@@ -39,7 +47,7 @@ impl BuildSystem for BunBuildSystem {
             let filename = rel_path.file_name().and_then(|n| n.to_str());
 
             let is_match = match filename {
-                Some("bun.lockb") => true,
+                Some("bun.lockb" | "bun.lock" | "bunfig.toml") => true,
                 Some("package.json") => {
                     let abs_path = repo_root.join(rel_path);
                     let content = fs.read_to_string(&abs_path).ok();
@@ -69,11 +77,12 @@ impl BuildSystem for BunBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
         let build_packages = if wolfi_index.has_package("bun") {
             vec!["bun".to_string()]
         } else {
-            let node_version = read_node_version_file(service_path)
+            let node_version = read_node_version_file(service_path, fs)
                 .or_else(|| manifest_content.and_then(parse_node_version))
                 .or_else(|| wolfi_index.get_latest_version("nodejs"))
                 .expect("Failed to get nodejs version from Wolfi index");
@@ -82,15 +91,20 @@ impl BuildSystem for BunBuildSystem {
 
         BuildTemplate {
             build_packages,
-            build_commands: vec!["bun install".to_string(), "bun run build".to_string()],
+            build_commands: node_build_commands(
+                "bun install",
+                "bun",
+                service_path,
+                manifest_content,
+                fs,
+            ),
             cache_paths: vec!["node_modules/".to_string(), ".bun/".to_string()],
             common_ports: vec![3000, 8080],
             build_env: std::collections::HashMap::new(),
-            runtime_copy: vec![
-                ("dist/".to_string(), "/app/dist/".to_string()),
-                ("build/".to_string(), "/app/build/".to_string()),
-            ],
+            // Bun runs TypeScript sources directly, so the whole project ships
+            runtime_copy: vec![(".".to_string(), "/app".to_string())],
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
@@ -98,6 +112,24 @@ impl BuildSystem for BunBuildSystem {
         vec!["node_modules".to_string(), ".bun".to_string()]
     }
 
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        _manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        // The detected manifest may be a lockfile or bunfig.toml
+        let content = fs.read_to_string(&service_path.join("package.json")).ok()?;
+        let package: serde_json::Value = serde_json::from_str(&content).ok()?;
+        if package["scripts"]["start"].is_string() {
+            return Some("bun run start".to_string());
+        }
+        package["main"]
+            .as_str()
+            .or_else(|| package["module"].as_str())
+            .map(|main| format!("bun {}", main))
+    }
+
     fn is_workspace_root(&self, manifest_content: Option<&str>) -> bool {
         if let Some(content) = manifest_content {
             content.contains("\"workspaces\"")
@@ -106,3 +138,43 @@ impl BuildSystem for BunBuildSystem {
         }
     }
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::{MockFileSystem, RealFileSystem};
+
+    #[test]
+    fn test_runtime_command_prefers_start_script() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("package.json"),
+            r#"{"module": "src/index.ts", "scripts": {"start": "bun run src/index.ts"}}"#,
+        )
+        .unwrap();
+        assert_eq!(
+            BunBuildSystem.runtime_command(dir.path(), None, &RealFileSystem),
+            Some("bun run start".to_string())
+        );
+
+        std::fs::write(
+            dir.path().join("package.json"),
+            r#"{"module": "src/index.ts"}"#,
+        )
+        .unwrap();
+        assert_eq!(
+            BunBuildSystem.runtime_command(dir.path(), None, &RealFileSystem),
+            Some("bun src/index.ts".to_string())
+        );
+    }
+
+    #[test]
+    fn test_runtime_command_reads_through_file_system() {
+        let fs = MockFileSystem::new();
+        fs.add_file("package.json", r#"{"main": "server.ts"}"#);
+        assert_eq!(
+            BunBuildSystem.runtime_command(Path::new(""), None, &fs),
+            Some("bun server.ts".to_string())
+        );
+    }
+}
@@ -1,6 +1,6 @@
 //! Bundler build system (Ruby)
 
-use super::ruby_common::{parse_gemfile_version, read_ruby_version_file};
+use super::ruby_common::{gemfile_declares, parse_gemfile_version, read_ruby_version_file};
 use super::{BuildSystem, BuildTemplate, ManifestPattern};
 use crate::{BuildSystemId, DetectionStack, LanguageId};
 use anyhow::Result;
@@ -47,8 +47,9 @@ impl BuildSystem for BundlerBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
-        let ruby_version = read_ruby_version_file(service_path)
+        let ruby_version = read_ruby_version_file(service_path, fs)
             .or_else(|| manifest_content.and_then(parse_gemfile_version))
             .or_else(|| wolfi_index.get_latest_version("ruby"))
             .expect("Failed to get ruby version from Wolfi index");
@@ -73,23 +74,138 @@ impl BuildSystem for BundlerBuildSystem {
         build_env.insert("BUNDLE_PATH".to_string(), "vendor/bundle".to_string());
         build_env.insert("BUNDLE_DEPLOYMENT".to_string(), "false".to_string());
 
+        let mut build_commands = vec!["bundle install".to_string()];
+        if manifest_content.is_some_and(|c| uses_asset_pipeline(service_path, c, fs)) {
+            // Precompiling only needs a placeholder secret, not the production credentials
+            build_env.insert("RAILS_ENV".to_string(), "production".to_string());
+            build_env.insert("SECRET_KEY_BASE_DUMMY".to_string(), "1".to_string());
+            build_commands.push("bundle exec rails assets:precompile".to_string());
+        }
+
         let mut runtime_env = std::collections::HashMap::new();
         runtime_env.insert("BUNDLE_PATH".to_string(), "/app/vendor/bundle".to_string());
         runtime_env.insert("BUNDLE_GEMFILE".to_string(), "/app/Gemfile".to_string());
 
         BuildTemplate {
             build_packages,
-            build_commands: vec!["bundle install".to_string()],
+            build_commands,
             cache_paths: vec!["vendor/bundle/".to_string()],
 
             common_ports: vec![3000],
             build_env,
             runtime_copy: vec![(".".to_string(), "/app".to_string())],
             runtime_env,
+            runtime_auxiliary_commands: vec![],
         }
     }
 
     fn cache_dirs(&self) -> Vec<String> {
         vec!["vendor".to_string(), ".bundle".to_string()]
     }
+
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let gemfile = manifest_content?;
+
+        if gemfile_declares(gemfile, "rails") {
+            return Some("bundle exec rails server -b 0.0.0.0".to_string());
+        }
+        if gemfile_declares(gemfile, "sinatra") && fs.is_file(&service_path.join("app.rb")) {
+            return Some("bundle exec ruby app.rb".to_string());
+        }
+
+        None
+    }
+}
+
+/// Rails apps with Sprockets/Propshaft (or an app/assets tree) need assets precompiled;
+/// API-only apps have no assets:precompile task
+fn uses_asset_pipeline(service_path: &Path, gemfile: &str, fs: &dyn FileSystem) -> bool {
+    gemfile_declares(gemfile, "rails")
+        && (gemfile_declares(gemfile, "sprockets-rails")
+            || gemfile_declares(gemfile, "propshaft")
+            || fs.is_dir(&service_path.join("app/assets")))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+    use tempfile::TempDir;
+
+    const RAILS_GEMFILE: &str = "source \"https://rubygems.org\"\n\ngem \"rails\", \"~> 7.1.3\"\ngem \"propshaft\"\ngem \"puma\"\n";
+
+    #[test]
+    fn test_rails_build_precompiles_assets() {
+        let dir = TempDir::new().unwrap();
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = BundlerBuildSystem.build_template(
+            &wolfi_index,
+            dir.path(),
+            Some(RAILS_GEMFILE),
+            &RealFileSystem,
+        );
+
+        assert_eq!(
+            template.build_commands,
+            vec!["bundle install", "bundle exec rails assets:precompile"]
+        );
+        assert_eq!(
+            template.build_env.get("RAILS_ENV").map(String::as_str),
+            Some("production")
+        );
+        assert_eq!(
+            BundlerBuildSystem.runtime_command(dir.path(), Some(RAILS_GEMFILE), &RealFileSystem),
+            Some("bundle exec rails server -b 0.0.0.0".to_string())
+        );
+    }
+
+    #[test]
+    fn test_rails_api_skips_precompile() {
+        let dir = TempDir::new().unwrap();
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let gemfile = "gem 'rails', '~> 7.1'\ngem 'puma'\n";
+        let template = BundlerBuildSystem.build_template(
+            &wolfi_index,
+            dir.path(),
+            Some(gemfile),
+            &RealFileSystem,
+        );
+
+        assert_eq!(template.build_commands, vec!["bundle install"]);
+    }
+
+    #[test]
+    fn test_sinatra_runtime_command() {
+        let dir = TempDir::new().unwrap();
+        std::fs::write(dir.path().join("app.rb"), "require 'sinatra'\n").unwrap();
+        let gemfile = "source 'https://rubygems.org'\ngem 'sinatra'\n";
+
+        assert_eq!(
+            BundlerBuildSystem.runtime_command(dir.path(), Some(gemfile), &RealFileSystem),
+            Some("bundle exec ruby app.rb".to_string())
+        );
+    }
+
+    #[test]
+    fn test_ruby_version_file_takes_precedence() {
+        let dir = TempDir::new().unwrap();
+        std::fs::write(dir.path().join(".ruby-version"), "3.2.2\n").unwrap();
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = BundlerBuildSystem.build_template(
+            &wolfi_index,
+            dir.path(),
+            Some("ruby \"3.3.0\"\ngem 'sinatra'\n"),
+            &RealFileSystem,
+        );
+
+        assert_eq!(
+            template.build_packages.first().map(String::as_str),
+            Some("ruby-3.2")
+        );
+    }
 }
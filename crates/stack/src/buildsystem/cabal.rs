@@ -0,0 +1,476 @@
+//! Cabal build system (Haskell)
+
+use super::{BuildSystem, BuildTemplate, ManifestPattern};
+use crate::{BuildSystemId, DetectionStack, LanguageId};
+use anyhow::Result;
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::HaskellExecutable;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+pub struct CabalBuildSystem;
+
+impl BuildSystem for CabalBuildSystem {
+    fn id(&self) -> BuildSystemId {
+        BuildSystemId::Cabal
+    }
+
+    fn manifest_patterns(&self) -> Vec<ManifestPattern> {
+        vec![ManifestPattern {
+            filename: "*.cabal".to_string(),
+            priority: 8,
+        }]
+    }
+
+    fn detect_all(
+        &self,
+        _repo_root: &Path,
+        file_tree: &[PathBuf],
+        _fs: &dyn FileSystem,
+    ) -> Result<Vec<DetectionStack>> {
+        let stack_dirs: Vec<&Path> = file_tree
+            .iter()
+            .filter(|path| path.file_name().and_then(|n| n.to_str()) == Some("stack.yaml"))
+            .map(|path| path.parent().unwrap_or_else(|| Path::new("")))
+            .collect();
+
+        Ok(file_tree
+            .iter()
+            .filter(|path| path.extension().is_some_and(|ext| ext == "cabal"))
+            // Packages of a Stack project are built through its stack.yaml
+            .filter(|path| {
+                let dir = path.parent().unwrap_or_else(|| Path::new(""));
+                !stack_dirs
+                    .iter()
+                    .any(|stack_dir| dir.starts_with(stack_dir))
+            })
+            .map(|path| {
+                DetectionStack::new(BuildSystemId::Cabal, LanguageId::Haskell, path.clone())
+            })
+            .collect())
+    }
+
+    fn build_template(
+        &self,
+        _wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        _service_path: &Path,
+        manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
+    ) -> BuildTemplate {
+        let package = manifest_content
+            .map(HaskellPackage::parse_cabal)
+            .unwrap_or_default();
+
+        // Wolfi does not package GHC; ghcup installs the recommended GHC and cabal
+        let mut build_commands = vec![
+            "curl -sSf https://get-ghcup.haskell.org | BOOTSTRAP_HASKELL_NONINTERACTIVE=1 BOOTSTRAP_HASKELL_MINIMAL=1 sh".to_string(),
+            "ghcup install ghc --set recommended".to_string(),
+            "ghcup install cabal recommended".to_string(),
+            "cabal update".to_string(),
+        ];
+        let runtime_copy = match package.executables.first() {
+            Some(exe) => {
+                build_commands.push(format!(
+                    "cabal install exe:{} --install-method=copy --installdir=dist --overwrite-policy=always",
+                    exe.name
+                ));
+                vec![(
+                    format!("dist/{}", exe.name),
+                    format!("/usr/local/bin/{}", exe.name),
+                )]
+            }
+            None => {
+                build_commands.push("cabal build all".to_string());
+                vec![]
+            }
+        };
+
+        let mut build_env = std::collections::HashMap::new();
+        build_env.insert(
+            "PATH".to_string(),
+            "/root/.ghcup/bin:/root/.cabal/bin:/usr/local/bin:/usr/bin:/bin".to_string(),
+        );
+
+        BuildTemplate {
+            build_packages: haskell_build_packages(),
+            build_commands,
+            cache_paths: vec![
+                "/root/.ghcup/".to_string(),
+                "/root/.cabal/packages/".to_string(),
+                "dist-newstyle/".to_string(),
+            ],
+            common_ports: vec![3000, 8080],
+            build_env,
+            runtime_copy,
+            runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
+        }
+    }
+
+    fn cache_dirs(&self) -> Vec<String> {
+        vec!["dist-newstyle".to_string()]
+    }
+
+    fn runtime_command(
+        &self,
+        _service_path: &Path,
+        manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let package = HaskellPackage::parse_cabal(manifest_content?);
+        let exe = package.executables.first()?;
+        Some(format!("/usr/local/bin/{}", exe.name))
+    }
+
+    fn parse_package_metadata(
+        &self,
+        manifest_content: &str,
+    ) -> Result<(String, bool), anyhow::Error> {
+        let package = HaskellPackage::parse_cabal(manifest_content);
+        match package.name {
+            Some(name) => Ok((name, !package.executables.is_empty())),
+            None => Err(anyhow::anyhow!(".cabal file has no name field")),
+        }
+    }
+}
+
+/// Toolchain prerequisites for a GHC installed by ghcup or Stack, plus the C libraries
+/// common Hackage packages link against
+pub(crate) fn haskell_build_packages() -> Vec<String> {
+    vec![
+        "build-base".to_string(),
+        "curl".to_string(),
+        "xz".to_string(),
+        "perl".to_string(),
+        "gmp-dev".to_string(),
+        "libffi-dev".to_string(),
+        "zlib-dev".to_string(),
+    ]
+}
+
+/// Stanzas whose dependencies end up in the built program
+const RUNTIME_STANZAS: [&str; 3] = ["library", "executable", "common"];
+
+/// A Haskell package description: a .cabal file or an hpack package.yaml
+#[derive(Debug, Default, PartialEq)]
+pub struct HaskellPackage {
+    pub name: Option<String>,
+    pub executables: Vec<HaskellExecutable>,
+    /// Package names from `build-depends` / `dependencies`, without version bounds
+    pub dependencies: Vec<String>,
+}
+
+impl HaskellPackage {
+    /// Reads the package description in `dir`; package.yaml wins because hpack generates
+    /// the .cabal file from it
+    pub fn read(dir: &Path, fs: &dyn FileSystem) -> Option<Self> {
+        let file = package_description(dir, fs)?;
+        let content = fs.read_to_string(&dir.join(&file)).ok()?;
+        Some(Self::parse(&file, &content))
+    }
+
+    pub fn parse(file_name: &str, content: &str) -> Self {
+        if file_name == "package.yaml" {
+            Self::parse_hpack(content)
+        } else {
+            Self::parse_cabal(content)
+        }
+    }
+
+    pub fn parse_cabal(content: &str) -> Self {
+        let field_re =
+            Regex::new(r"^(\s*)([A-Za-z][A-Za-z0-9-]*)\s*:(.*)$").expect("valid field regex");
+        let mut package = Self::default();
+        let mut stanza = String::new();
+        // Field being read: (name, indent, value so far); values may continue on deeper lines
+        let mut field: Option<(String, usize, String)> = None;
+        // (stanza keyword, field name, value)
+        let mut fields: Vec<(String, String, String)> = Vec::new();
+
+        for line in content.lines() {
+            let trimmed = line.trim();
+            if trimmed.is_empty() || trimmed.starts_with("--") {
+                continue;
+            }
+            let indent = line.len() - line.trim_start().len();
+
+            if let Some((_, field_indent, value)) = field.as_mut() {
+                if indent > *field_indent && !field_re.is_match(line) {
+                    value.push(' ');
+                    value.push_str(trimmed);
+                    continue;
+                }
+            }
+            if let Some((name, _, value)) = field.take() {
+                fields.push((stanza.clone(), name, value));
+            }
+
+            if let Some(caps) = field_re.captures(line) {
+                field = Some((
+                    caps[2].to_lowercase(),
+                    caps[1].len(),
+                    caps[3].trim().to_string(),
+                ));
+            } else if indent == 0 {
+                // `executable greeter`, `library`, `test-suite spec`, ...
+                let mut words = trimmed.split_whitespace();
+                stanza = words.next().unwrap_or("").to_lowercase();
+                if stanza == "executable" {
+                    package.executables.push(HaskellExecutable {
+                        name: words.next().unwrap_or("").to_string(),
+                        main_is: None,
+                    });
+                }
+            }
+        }
+        if let Some((name, _, value)) = field.take() {
+            fields.push((stanza, name, value));
+        }
+
+        for (stanza, name, value) in fields {
+            match (stanza.as_str(), name.as_str()) {
+                ("", "name") => package.name = Some(value),
+                ("executable", "main-is") => {
+                    if let Some(exe) = package.executables.last_mut() {
+                        exe.main_is = Some(value);
+                    }
+                }
+                (stanza, "build-depends") if RUNTIME_STANZAS.contains(&stanza) => {
+                    for dep in value.split(',') {
+                        package.push_dependency(dep);
+                    }
+                }
+                _ => {}
+            }
+        }
+        package.drop_self_dependency();
+        package
+    }
+
+    /// hpack's YAML, read line by line: top-level `name`, `dependencies` lists at any depth
+    /// outside `tests`/`benchmarks`, and the entries of the `executables` map
+    pub fn parse_hpack(content: &str) -> Self {
+        let key_re = Regex::new(r"^(\s*)([A-Za-z][\w-]*):\s*(.*)$").expect("valid key regex");
+        let mut package = Self::default();
+        let mut section = String::new();
+        let mut executable_indent: Option<usize> = None;
+        let mut dependencies_indent: Option<usize> = None;
+
+        for line in content.lines() {
+            let trimmed = line.trim();
+            if trimmed.is_empty() || trimmed.starts_with('#') {
+                continue;
+            }
+            let indent = line.len() - line.trim_start().len();
+
+            if let Some(list_indent) = dependencies_indent {
+                if let Some(item) = trimmed.strip_prefix("- ") {
+                    if indent >= list_indent {
+                        if section != "tests" && section != "benchmarks" {
+                            package.push_dependency(item.trim_matches(|c| c == '"' || c == '\''));
+                        }
+                        continue;
+                    }
+                }
+                dependencies_indent = None;
+            }
+
+            let Some(caps) = key_re.captures(line) else {
+                continue;
+            };
+            let (key, value) = (&caps[2], caps[3].trim());
+            let value = value.trim_matches(|c| c == '"' || c == '\'');
+
+            if indent == 0 {
+                section = key.to_string();
+                executable_indent = None;
+                if key == "name" {
+                    package.name = Some(value.to_string());
+                }
+            } else if section == "executables" {
+                let entry_indent = *executable_indent.get_or_insert(indent);
+                if indent == entry_indent {
+                    package.executables.push(HaskellExecutable {
+                        name: key.to_string(),
+                        main_is: None,
+                    });
+                } else if key == "main" {
+                    if let Some(exe) = package.executables.last_mut() {
+                        exe.main_is = Some(value.to_string());
+                    }
+                }
+            }
+
+            if key == "dependencies" && value.is_empty() {
+                dependencies_indent = Some(indent);
+            }
+        }
+        package.drop_self_dependency();
+        package
+    }
+
+    fn push_dependency(&mut self, entry: &str) {
+        // "base >=4.7 && <5", "text ^>=2.0"
+        let name: String = entry
+            .trim()
+            .chars()
+            .take_while(|c| c.is_ascii_alphanumeric() || *c == '-')
+            .collect();
+        if !name.is_empty() && !self.dependencies.contains(&name) {
+            self.dependencies.push(name);
+        }
+    }
+
+    /// Executables depending on their package's own library are not external dependencies
+    fn drop_self_dependency(&mut self) {
+        if let Some(name) = &self.name {
+            self.dependencies.retain(|dep| dep != name);
+        }
+    }
+}
+
+/// File name of the package description in `dir`: package.yaml, else the first .cabal file
+pub fn package_description(dir: &Path, fs: &dyn FileSystem) -> Option<String> {
+    if fs.is_file(&dir.join("package.yaml")) {
+        return Some("package.yaml".to_string());
+    }
+    let mut cabal_files: Vec<String> = fs
+        .read_dir(dir)
+        .ok()?
+        .into_iter()
+        .map(|entry| entry.name)
+        .filter(|name| name.ends_with(".cabal"))
+        .collect();
+    cabal_files.sort();
+    cabal_files.into_iter().next()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    const CABAL: &str = r#"cabal-version:      3.0
+name:               greeter
+version:            0.1.0.0
+
+common shared
+    default-language: Haskell2010
+    build-depends:    base ^>=4.17
+
+library
+    exposed-modules:  Greeter
+    build-depends:
+        text ^>=2.0
+      , aeson >=2.1 && <2.3
+    hs-source-dirs:   src
+
+executable greeter
+    main-is:          Main.hs
+    build-depends:    base, greeter, scotty ^>=0.20
+    hs-source-dirs:   app
+
+test-suite spec
+    type:             exitcode-stdio-1.0
+    main-is:          Spec.hs
+    build-depends:    base, hspec
+"#;
+
+    const HPACK: &str = r#"name: catalog
+version: 0.1.0.0
+
+dependencies:
+- base >= 4.7 && < 5
+- servant-server
+- warp
+
+library:
+  source-dirs: src
+
+executables:
+  catalog-exe:
+    main: Main.hs
+    source-dirs: app
+    dependencies:
+      - catalog
+      - "optparse-applicative"
+
+tests:
+  catalog-test:
+    main: Spec.hs
+    dependencies:
+    - hspec
+"#;
+
+    #[test]
+    fn test_parse_cabal() {
+        let package = HaskellPackage::parse_cabal(CABAL);
+        assert_eq!(package.name.as_deref(), Some("greeter"));
+        assert_eq!(
+            package.executables,
+            vec![HaskellExecutable {
+                name: "greeter".to_string(),
+                main_is: Some("Main.hs".to_string()),
+            }]
+        );
+        assert_eq!(
+            package.dependencies,
+            vec!["base", "text", "aeson", "scotty"]
+        );
+    }
+
+    #[test]
+    fn test_parse_hpack() {
+        let package = HaskellPackage::parse_hpack(HPACK);
+        assert_eq!(package.name.as_deref(), Some("catalog"));
+        assert_eq!(
+            package.executables,
+            vec![HaskellExecutable {
+                name: "catalog-exe".to_string(),
+                main_is: Some("Main.hs".to_string()),
+            }]
+        );
+        assert_eq!(
+            package.dependencies,
+            vec!["base", "servant-server", "warp", "optparse-applicative"]
+        );
+    }
+
+    #[test]
+    fn test_installs_first_executable() {
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = CabalBuildSystem.build_template(
+            &wolfi_index,
+            Path::new("."),
+            Some(CABAL),
+            &RealFileSystem,
+        );
+        assert_eq!(
+            template.build_commands.last().unwrap(),
+            "cabal install exe:greeter --install-method=copy --installdir=dist --overwrite-policy=always"
+        );
+        assert_eq!(
+            CabalBuildSystem.runtime_command(Path::new("."), Some(CABAL), &RealFileSystem),
+            Some("/usr/local/bin/greeter".to_string())
+        );
+    }
+
+    #[test]
+    fn test_stack_packages_are_skipped() {
+        let tree = vec![
+            PathBuf::from("stack.yaml"),
+            PathBuf::from("api/api.cabal"),
+            PathBuf::from("tools/lint/lint.cabal"),
+        ];
+        let fs = peelbox_core::fs::MockFileSystem::new();
+        assert!(CabalBuildSystem
+            .detect_all(Path::new(""), &tree, &fs)
+            .unwrap()
+            .is_empty());
+
+        let detections = CabalBuildSystem
+            .detect_all(Path::new(""), &tree[1..], &fs)
+            .unwrap();
+        assert_eq!(detections.len(), 2);
+    }
+}
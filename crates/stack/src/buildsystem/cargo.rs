@@ -56,8 +56,9 @@ impl BuildSystem for CargoBuildSystem {
     fn build_template(
         &self,
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
-        _service_path: &Path,
-        _manifest_content: Option<&str>,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
         let mut build_packages = Vec::new();
 
@@ -73,17 +74,37 @@ impl BuildSystem for CargoBuildSystem {
         let mut build_env = std::collections::HashMap::new();
         build_env.insert("CARGO_HOME".to_string(), ".cargo".to_string());
 
+        let (build_commands, runtime_copy, common_ports) =
+            if !self.produces_runnable(service_path, manifest_content, fs) {
+                // Nothing to run; the build only verifies the library compiles
+                (
+                    vec!["cargo build --release --lib".to_string()],
+                    vec![],
+                    vec![],
+                )
+            } else {
+                let binary = manifest_content
+                    .and_then(|c| primary_binary(service_path, c, fs))
+                    .unwrap_or_else(|| "{project_name}".to_string());
+                (
+                    vec!["cargo build --release".to_string()],
+                    vec![(
+                        format!("target/release/{}", binary),
+                        "/usr/local/bin/{project_name}".to_string(),
+                    )],
+                    vec![8080],
+                )
+            };
+
         BuildTemplate {
             build_packages,
-            build_commands: vec!["cargo build --release".to_string()],
+            build_commands,
             cache_paths: vec!["target".to_string(), ".cargo".to_string()],
-            common_ports: vec![8080],
+            common_ports,
             build_env,
-            runtime_copy: vec![(
-                "target/release/{project_name}".to_string(),
-                "/usr/local/bin/{project_name}".to_string(),
-            )],
+            runtime_copy,
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
@@ -110,6 +131,16 @@ impl BuildSystem for CargoBuildSystem {
         Ok((name, is_application))
     }
 
+    fn produces_runnable(
+        &self,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> bool {
+        manifest_content.and_then(|c| classify_crate(service_path, c, fs))
+            != Some(CrateType::Library)
+    }
+
     fn is_workspace_root(&self, manifest_content: Option<&str>) -> bool {
         if let Some(content) = manifest_content {
             content.contains("[workspace]")
@@ -135,3 +166,201 @@ impl BuildSystem for CargoBuildSystem {
         }
     }
 }
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum CrateType {
+    Binary,
+    Library,
+}
+
+impl CrateType {
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            CrateType::Binary => "binary",
+            CrateType::Library => "library",
+        }
+    }
+}
+
+/// Classifies a `[package]` manifest as a binary or library crate.
+///
+/// `[[bin]]` targets, `src/main.rs` or `src/bin/` make it a binary; otherwise `src/lib.rs`
+/// (or a `[lib]` section) makes it a library. Virtual workspace manifests return `None`.
+pub fn classify_crate(
+    service_path: &Path,
+    manifest_content: &str,
+    fs: &dyn FileSystem,
+) -> Option<CrateType> {
+    let value: Value = toml::from_str(manifest_content).ok()?;
+    value.get("package")?;
+
+    let has_bin_targets = value
+        .get("bin")
+        .and_then(|b| b.as_array())
+        .is_some_and(|bins| !bins.is_empty());
+    if has_bin_targets
+        || fs.is_file(&service_path.join("src/main.rs"))
+        || fs.is_dir(&service_path.join("src/bin"))
+    {
+        return Some(CrateType::Binary);
+    }
+
+    if value.get("lib").is_some() || fs.is_file(&service_path.join("src/lib.rs")) {
+        return Some(CrateType::Library);
+    }
+
+    None
+}
+
+/// Name of the binary to ship: the `[[bin]]` matching the package name, else the first
+/// `[[bin]]`, else `None` (the default binary shares the package name).
+fn primary_binary(
+    service_path: &Path,
+    manifest_content: &str,
+    fs: &dyn FileSystem,
+) -> Option<String> {
+    let value: Value = toml::from_str(manifest_content).ok()?;
+    let package_name = value
+        .get("package")
+        .and_then(|p| p.get("name"))
+        .and_then(|n| n.as_str());
+
+    if fs.is_file(&service_path.join("src/main.rs")) {
+        return None;
+    }
+
+    let bins: Vec<&str> = value
+        .get("bin")
+        .and_then(|b| b.as_array())
+        .into_iter()
+        .flatten()
+        .filter_map(|bin| bin.get("name").and_then(|n| n.as_str()))
+        .collect();
+
+    if bins.iter().any(|name| Some(*name) == package_name) {
+        return None;
+    }
+    if let Some(name) = bins.first() {
+        return Some(name.to_string());
+    }
+
+    // Auto-discovered src/bin/<name>.rs targets
+    let mut auto_bins: Vec<String> = fs
+        .read_dir(&service_path.join("src/bin"))
+        .ok()?
+        .into_iter()
+        .filter_map(|entry| {
+            let path = entry.path();
+            (path.extension()? == "rs")
+                .then(|| path.file_stem()?.to_str().map(String::from))
+                .flatten()
+        })
+        .collect();
+    auto_bins.sort();
+    auto_bins.into_iter().next()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+    use tempfile::TempDir;
+
+    const PACKAGE: &str = "[package]\nname = \"demo\"\nversion = \"0.1.0\"\n";
+
+    fn crate_dir(files: &[&str]) -> TempDir {
+        let dir = TempDir::new().unwrap();
+        for file in files {
+            let path = dir.path().join(file);
+            std::fs::create_dir_all(path.parent().unwrap()).unwrap();
+            std::fs::write(path, "").unwrap();
+        }
+        dir
+    }
+
+    #[test]
+    fn test_classify_binary_with_main_rs() {
+        let dir = crate_dir(&["src/main.rs"]);
+        assert_eq!(
+            classify_crate(dir.path(), PACKAGE, &RealFileSystem),
+            Some(CrateType::Binary)
+        );
+    }
+
+    #[test]
+    fn test_classify_library() {
+        let dir = crate_dir(&["src/lib.rs"]);
+        assert_eq!(
+            classify_crate(dir.path(), PACKAGE, &RealFileSystem),
+            Some(CrateType::Library)
+        );
+    }
+
+    #[test]
+    fn test_classify_bin_section_overrides_lib() {
+        let dir = crate_dir(&["src/lib.rs", "src/cli.rs"]);
+        let manifest = format!(
+            "{}\n[[bin]]\nname = \"demo-cli\"\npath = \"src/cli.rs\"\n",
+            PACKAGE
+        );
+        assert_eq!(
+            classify_crate(dir.path(), &manifest, &RealFileSystem),
+            Some(CrateType::Binary)
+        );
+        assert_eq!(
+            primary_binary(dir.path(), &manifest, &RealFileSystem),
+            Some("demo-cli".to_string())
+        );
+    }
+
+    #[test]
+    fn test_classify_virtual_workspace() {
+        let dir = crate_dir(&[]);
+        assert_eq!(
+            classify_crate(
+                dir.path(),
+                "[workspace]\nmembers = [\"a\"]\n",
+                &RealFileSystem
+            ),
+            None
+        );
+    }
+
+    #[test]
+    fn test_library_build_template() {
+        let dir = crate_dir(&["src/lib.rs"]);
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = CargoBuildSystem.build_template(
+            &wolfi_index,
+            dir.path(),
+            Some(PACKAGE),
+            &RealFileSystem,
+        );
+
+        assert_eq!(template.build_commands, vec!["cargo build --release --lib"]);
+        assert!(template.runtime_copy.is_empty());
+        assert!(template.common_ports.is_empty());
+        assert!(!CargoBuildSystem.produces_runnable(dir.path(), Some(PACKAGE), &RealFileSystem));
+    }
+
+    #[test]
+    fn test_binary_build_template_copies_package_binary() {
+        let dir = crate_dir(&["src/main.rs"]);
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = CargoBuildSystem.build_template(
+            &wolfi_index,
+            dir.path(),
+            Some(PACKAGE),
+            &RealFileSystem,
+        );
+
+        assert_eq!(template.build_commands, vec!["cargo build --release"]);
+        assert_eq!(
+            template.runtime_copy,
+            vec![(
+                "target/release/{project_name}".to_string(),
+                "/usr/local/bin/{project_name}".to_string()
+            )]
+        );
+    }
+}
@@ -46,6 +46,7 @@ impl BuildSystem for CMakeBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         _service_path: &Path,
         _manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
     ) -> BuildTemplate {
         let mut build_packages = vec!["build-base".to_string()];
 
@@ -71,6 +72,7 @@ impl BuildSystem for CMakeBuildSystem {
                 "/usr/local/bin/{project_name}".to_string(),
             )],
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
@@ -27,6 +27,20 @@ fn parse_php_version(manifest_content: &str) -> Option<String> {
     }
 }
 
+/// Raw `require.php` constraint from composer.json (e.g., "^8.2")
+pub fn php_version_constraint(manifest_content: &str) -> Option<String> {
+    let composer: serde_json::Value = serde_json::from_str(manifest_content).ok()?;
+    composer["require"]["php"]
+        .as_str()
+        .map(|constraint| constraint.trim().to_string())
+        .filter(|constraint| !constraint.is_empty())
+}
+
+fn requires_package(manifest_content: &str, package: &str) -> bool {
+    serde_json::from_str::<serde_json::Value>(manifest_content)
+        .is_ok_and(|composer| composer["require"].get(package).is_some())
+}
+
 pub struct ComposerBuildSystem;
 
 impl BuildSystem for ComposerBuildSystem {
@@ -89,6 +103,7 @@ impl BuildSystem for ComposerBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         _service_path: &Path,
         manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
     ) -> BuildTemplate {
         let php_version = manifest_content
             .and_then(parse_php_version)
@@ -104,28 +119,113 @@ impl BuildSystem for ComposerBuildSystem {
         let mut build_packages = vec![php_version.clone(), "composer".to_string()];
         build_packages.extend(extension_packages);
 
+        let is_laravel = manifest_content.is_some_and(|c| requires_package(c, "laravel/framework"));
+        let install = "composer install --no-dev --optimize-autoloader --ignore-platform-reqs";
+        let (build_commands, runtime_copy) = if is_laravel {
+            // artisan, bootstrap/, routes/, storage/ etc. are all needed at runtime
+            (
+                vec![install.to_string()],
+                vec![(".".to_string(), "/app".to_string())],
+            )
+        } else {
+            (
+                vec![
+                    "composer config allow-plugins.symfony/runtime true".to_string(),
+                    install.to_string(),
+                ],
+                vec![
+                    ("vendor/".to_string(), "/app/vendor".to_string()),
+                    ("bin/".to_string(), "/app/bin".to_string()),
+                    ("public/".to_string(), "/app/public".to_string()),
+                    ("src/".to_string(), "/app/src".to_string()),
+                    ("config/".to_string(), "/app/config".to_string()),
+                ],
+            )
+        };
+
         BuildTemplate {
             build_packages,
-            build_commands: vec![
-                "composer config allow-plugins.symfony/runtime true".to_string(),
-                "composer install --no-dev --optimize-autoloader --ignore-platform-reqs"
-                    .to_string(),
-            ],
+            build_commands,
             cache_paths: vec!["/root/.composer/cache/".to_string()],
             common_ports: vec![9000, 80],
             build_env: std::collections::HashMap::new(),
-            runtime_copy: vec![
-                ("vendor/".to_string(), "/app/vendor".to_string()),
-                ("bin/".to_string(), "/app/bin".to_string()),
-                ("public/".to_string(), "/app/public".to_string()),
-                ("src/".to_string(), "/app/src".to_string()),
-                ("config/".to_string(), "/app/config".to_string()),
-            ],
+            runtime_copy,
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
     fn cache_dirs(&self) -> Vec<String> {
         vec![".composer/cache".to_string(), "vendor".to_string()]
     }
+
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let is_laravel = manifest_content.is_some_and(|c| requires_package(c, "laravel/framework"));
+        (is_laravel && fs.is_file(&service_path.join("artisan")))
+            .then(|| "php artisan serve --host=0.0.0.0 --port=8000".to_string())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+    use tempfile::TempDir;
+
+    const LARAVEL: &str = r#"{
+    "name": "laravel/laravel",
+    "require": {
+        "php": "^8.2",
+        "laravel/framework": "^11.0"
+    }
+}"#;
+
+    #[test]
+    fn test_php_version_constraint() {
+        assert_eq!(php_version_constraint(LARAVEL), Some("^8.2".to_string()));
+        assert_eq!(parse_php_version(LARAVEL), Some("php-8.2".to_string()));
+        assert_eq!(php_version_constraint(r#"{"require": {}}"#), None);
+    }
+
+    #[test]
+    fn test_laravel_runs_artisan() {
+        let dir = TempDir::new().unwrap();
+        std::fs::write(dir.path().join("artisan"), "#!/usr/bin/env php\n").unwrap();
+
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = ComposerBuildSystem.build_template(
+            &wolfi_index,
+            dir.path(),
+            Some(LARAVEL),
+            &RealFileSystem,
+        );
+        assert_eq!(
+            template.build_commands,
+            vec!["composer install --no-dev --optimize-autoloader --ignore-platform-reqs"]
+        );
+        assert_eq!(
+            template.runtime_copy,
+            vec![(".".to_string(), "/app".to_string())]
+        );
+        assert_eq!(
+            ComposerBuildSystem.runtime_command(dir.path(), Some(LARAVEL), &RealFileSystem),
+            Some("php artisan serve --host=0.0.0.0 --port=8000".to_string())
+        );
+    }
+
+    #[test]
+    fn test_symfony_has_no_runtime_command() {
+        let dir = TempDir::new().unwrap();
+        let symfony = r#"{"name": "acme/api", "require": {"php": ">=8.1", "symfony/framework-bundle": "6.4.*"}}"#;
+
+        assert_eq!(
+            ComposerBuildSystem.runtime_command(dir.path(), Some(symfony), &RealFileSystem),
+            None
+        );
+    }
 }
@@ -0,0 +1,329 @@
+//! Conda build system (Python) - environment.yml specifications
+
+use super::{BuildSystem, BuildTemplate, ManifestPattern};
+use crate::{BuildSystemId, DetectionStack, LanguageId};
+use anyhow::Result;
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::PythonMetadata;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+const MANIFESTS: [&str; 2] = ["environment.yml", "environment.yaml"];
+
+/// Environment created inside the build directory so the runtime stage can copy it
+const ENV_PREFIX: &str = "/build/.conda";
+
+/// Entry scripts of a data-science project, in order of preference
+const SCRIPTS: [&str; 4] = ["main.py", "app.py", "train.py", "serve.py"];
+
+/// Packages that make an environment a web service; checked before the data-science ones, since
+/// a model server pulls in both
+const WEB_PACKAGES: [&str; 7] = [
+    "fastapi",
+    "uvicorn",
+    "flask",
+    "django",
+    "gunicorn",
+    "starlette",
+    "tornado",
+];
+const DATA_SCIENCE_PACKAGES: [&str; 7] = [
+    "numpy",
+    "pandas",
+    "scikit-learn",
+    "scipy",
+    "jupyter",
+    "jupyterlab",
+    "matplotlib",
+];
+
+pub struct CondaBuildSystem;
+
+impl BuildSystem for CondaBuildSystem {
+    fn id(&self) -> BuildSystemId {
+        BuildSystemId::Conda
+    }
+
+    fn manifest_patterns(&self) -> Vec<ManifestPattern> {
+        // Above requirements.txt, which Conda projects often keep as a pip fallback
+        MANIFESTS
+            .iter()
+            .map(|filename| ManifestPattern {
+                filename: filename.to_string(),
+                priority: 13,
+            })
+            .collect()
+    }
+
+    fn detect_all(
+        &self,
+        _repo_root: &Path,
+        file_tree: &[PathBuf],
+        _fs: &dyn FileSystem,
+    ) -> Result<Vec<DetectionStack>> {
+        Ok(file_tree
+            .iter()
+            .filter(|path| {
+                path.file_name()
+                    .and_then(|n| n.to_str())
+                    .is_some_and(|name| MANIFESTS.contains(&name))
+            })
+            .map(|path| DetectionStack::new(BuildSystemId::Conda, LanguageId::Python, path.clone()))
+            .collect())
+    }
+
+    fn build_template(
+        &self,
+        _wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        service_path: &Path,
+        _manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> BuildTemplate {
+        let manifest = MANIFESTS
+            .iter()
+            .find(|name| fs.is_file(&service_path.join(name)))
+            .unwrap_or(&MANIFESTS[0]);
+
+        let mut build_env = std::collections::HashMap::new();
+        build_env.insert(
+            "CONDA_PKGS_DIRS".to_string(),
+            "/root/.cache/conda/pkgs".to_string(),
+        );
+
+        BuildTemplate {
+            // Conda resolves Python itself, pinned or not, from the environment's dependencies
+            build_packages: vec!["conda".to_string()],
+            build_commands: vec![format!(
+                "conda env create --prefix {} --file {}",
+                ENV_PREFIX, manifest
+            )],
+            cache_paths: vec![
+                "/root/.cache/conda/pkgs/".to_string(),
+                "/root/.cache/pip/".to_string(),
+            ],
+            common_ports: vec![8000, 5000],
+            build_env,
+            runtime_copy: vec![(".".to_string(), "/build".to_string())],
+            runtime_env: {
+                let mut env = std::collections::HashMap::new();
+                env.insert("CONDA_PREFIX".to_string(), ENV_PREFIX.to_string());
+                env.insert(
+                    "PATH".to_string(),
+                    format!("{}/bin:/usr/local/bin:/usr/bin:/bin", ENV_PREFIX),
+                );
+                env
+            },
+            runtime_auxiliary_commands: vec![],
+        }
+    }
+
+    fn cache_dirs(&self) -> Vec<String> {
+        vec![".cache/conda".to_string()]
+    }
+
+    /// Runs the project's script with the environment's interpreter; web stacks keep their
+    /// framework's server command
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        if CondaEnvironment::parse(manifest_content?).workload() == Some("web") {
+            return None;
+        }
+        SCRIPTS
+            .iter()
+            .find(|script| fs.is_file(&service_path.join(script)))
+            .map(|script| format!("python /build/{}", script))
+    }
+}
+
+/// Facts read from environment.yml
+#[derive(Debug, Default, PartialEq)]
+pub struct CondaEnvironment {
+    pub name: Option<String>,
+    /// Python pin from `dependencies`, e.g. `3.11` for `python=3.11.5`
+    pub python: Option<String>,
+    /// Conda packages, without channel prefixes or version constraints
+    pub dependencies: Vec<String>,
+    /// Packages of the nested `- pip:` list
+    pub pip: Vec<String>,
+}
+
+impl CondaEnvironment {
+    pub fn parse(content: &str) -> Self {
+        let version_re = Regex::new(r"\d+(?:\.\d+)?").expect("valid version regex");
+        let mut environment = Self::default();
+        let mut section = String::new();
+        let mut pip_indent: Option<usize> = None;
+
+        for line in content.lines() {
+            let line = line.split(" #").next().unwrap_or_default().trim_end();
+            let item = line.trim_start();
+            if item.is_empty() || item.starts_with('#') {
+                continue;
+            }
+            let indent = line.len() - item.len();
+
+            if indent == 0 && !item.starts_with('-') {
+                let (key, value) = item.split_once(':').unwrap_or((item, ""));
+                section = key.trim().to_string();
+                pip_indent = None;
+                if section == "name" && !unquote(value).is_empty() {
+                    environment.name = Some(unquote(value).to_string());
+                }
+                continue;
+            }
+            if section != "dependencies" {
+                continue;
+            }
+            let Some(spec) = item.strip_prefix('-').map(unquote) else {
+                continue;
+            };
+
+            if pip_indent.is_some_and(|pip| indent > pip) {
+                environment.pip.push(package_name(spec));
+                continue;
+            }
+            pip_indent = None;
+            if spec == "pip:" {
+                pip_indent = Some(indent);
+                continue;
+            }
+
+            let name = package_name(spec);
+            if name == "python" {
+                environment.python = version_re
+                    .find(&spec[spec.find("python").unwrap_or_default() + "python".len()..])
+                    .map(|version| version.as_str().to_string());
+            }
+            environment.dependencies.push(name);
+        }
+        environment
+    }
+
+    /// Conda and pip packages together
+    pub fn packages(&self) -> impl Iterator<Item = &str> {
+        self.dependencies
+            .iter()
+            .chain(&self.pip)
+            .map(String::as_str)
+    }
+
+    /// `web` for a service stack such as FastAPI and uvicorn, `data-science` for numpy, pandas
+    /// or scikit-learn, else `None`
+    pub fn workload(&self) -> Option<&'static str> {
+        let has_any = |names: &[&str]| self.packages().any(|package| names.contains(&package));
+        if has_any(&WEB_PACKAGES) {
+            Some("web")
+        } else if has_any(&DATA_SCIENCE_PACKAGES) {
+            Some("data-science")
+        } else {
+            None
+        }
+    }
+}
+
+pub struct CondaDetector;
+
+impl CondaDetector {
+    pub fn is_manifest(manifest_name: &str) -> bool {
+        MANIFESTS.contains(&manifest_name)
+    }
+
+    /// Package manager, the commands creating and activating the environment, and its workload
+    pub fn inspect(manifest_name: &str, content: &str) -> PythonMetadata {
+        let environment = CondaEnvironment::parse(content);
+        PythonMetadata {
+            package_manager: Some("conda".to_string()),
+            install_command: Some(format!("conda env create -f {}", manifest_name)),
+            activate_command: environment
+                .name
+                .as_ref()
+                .map(|name| format!("conda activate {}", name)),
+            workload: environment.workload().map(String::from),
+            ..PythonMetadata::default()
+        }
+    }
+}
+
+fn unquote(value: &str) -> &str {
+    value.trim().trim_matches(|c| c == '"' || c == '\'')
+}
+
+/// `conda-forge::scikit-learn>=1.4` → `scikit-learn`, normalised the way conda and PyPI compare
+/// names
+fn package_name(spec: &str) -> String {
+    let spec = spec.rsplit("::").next().unwrap_or(spec);
+    spec.split(|c: char| !(c.is_ascii_alphanumeric() || c == '-' || c == '_' || c == '.'))
+        .next()
+        .unwrap_or_default()
+        .to_lowercase()
+        .replace('_', "-")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const SKLEARN: &str = r#"name: churn-model
+channels:
+  - conda-forge
+  - defaults
+dependencies:
+  - python=3.11.5
+  - numpy>=1.26
+  - conda-forge::pandas
+  - scikit-learn=1.4.*  # pinned for the saved model
+  - pip
+  - pip:
+      - mlflow==2.11.0
+"#;
+
+    #[test]
+    fn test_parse_environment() {
+        let environment = CondaEnvironment::parse(SKLEARN);
+        assert_eq!(environment.name.as_deref(), Some("churn-model"));
+        assert_eq!(environment.python.as_deref(), Some("3.11"));
+        assert_eq!(
+            environment.dependencies,
+            vec!["python", "numpy", "pandas", "scikit-learn", "pip"]
+        );
+        assert_eq!(environment.pip, vec!["mlflow"]);
+        assert_eq!(environment.workload(), Some("data-science"));
+    }
+
+    #[test]
+    fn test_web_workload_wins_and_unindented_lists() {
+        let environment = CondaEnvironment::parse(
+            "name: api\ndependencies:\n- python\n- numpy\n- pip:\n  - fastapi\n  - uvicorn[standard]\n",
+        );
+        assert_eq!(environment.python, None);
+        assert_eq!(environment.pip, vec!["fastapi", "uvicorn"]);
+        assert_eq!(environment.workload(), Some("web"));
+        assert_eq!(
+            CondaEnvironment::parse("dependencies:\n  - python=3.12\n").workload(),
+            None
+        );
+    }
+
+    #[test]
+    fn test_inspect() {
+        let metadata = CondaDetector::inspect("environment.yml", SKLEARN);
+        assert_eq!(metadata.package_manager.as_deref(), Some("conda"));
+        assert_eq!(
+            metadata.install_command.as_deref(),
+            Some("conda env create -f environment.yml")
+        );
+        assert_eq!(
+            metadata.activate_command.as_deref(),
+            Some("conda activate churn-model")
+        );
+        assert_eq!(metadata.workload.as_deref(), Some("data-science"));
+
+        let unnamed = CondaDetector::inspect("environment.yaml", "dependencies:\n  - flask\n");
+        assert_eq!(unnamed.activate_command, None);
+        assert_eq!(unnamed.workload.as_deref(), Some("web"));
+    }
+}
@@ -0,0 +1,313 @@
+//! Dart pub build system (Dart and Flutter)
+
+use super::{BuildSystem, BuildTemplate, ManifestPattern};
+use crate::{BuildSystemId, DetectionStack, LanguageId};
+use anyhow::Result;
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+const FLUTTER_HOME: &str = "/opt/flutter";
+
+pub struct DartPubBuildSystem;
+
+impl BuildSystem for DartPubBuildSystem {
+    fn id(&self) -> BuildSystemId {
+        BuildSystemId::DartPub
+    }
+
+    fn manifest_patterns(&self) -> Vec<ManifestPattern> {
+        vec![ManifestPattern {
+            filename: "pubspec.yaml".to_string(),
+            priority: 10,
+        }]
+    }
+
+    fn detect_all(
+        &self,
+        _repo_root: &Path,
+        file_tree: &[PathBuf],
+        _fs: &dyn FileSystem,
+    ) -> Result<Vec<DetectionStack>> {
+        Ok(file_tree
+            .iter()
+            .filter(|path| path.file_name().and_then(|n| n.to_str()) == Some("pubspec.yaml"))
+            .map(|path| DetectionStack::new(BuildSystemId::DartPub, LanguageId::Dart, path.clone()))
+            .collect())
+    }
+
+    fn build_template(
+        &self,
+        _wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> BuildTemplate {
+        let pubspec = Pubspec::parse(manifest_content.unwrap_or_default());
+        if pubspec.flutter {
+            return flutter_template(&pubspec, service_path, fs);
+        }
+
+        let mut build_commands = vec!["dart pub get".to_string()];
+        let mut runtime_copy = vec![];
+        if let (Some(name), Some(entry)) = (&pubspec.name, pubspec.server_entry(service_path, fs)) {
+            if pubspec.depends_on("dart_frog") {
+                // dart_frog generates build/bin/server.dart from the routes/ tree
+                build_commands.push("dart pub global activate dart_frog_cli".to_string());
+                build_commands
+                    .push("dart pub global run dart_frog_cli:dart_frog build".to_string());
+            }
+            build_commands.push(format!("dart compile exe {} -o build/{}", entry, name));
+            runtime_copy.push((
+                format!("build/{}", name),
+                format!("/usr/local/bin/{}", name),
+            ));
+        }
+
+        BuildTemplate {
+            // Wolfi ships a single unversioned `dart` SDK package
+            build_packages: vec!["dart".to_string()],
+            build_commands,
+            cache_paths: vec![".dart_tool/".to_string(), "/root/.pub-cache/".to_string()],
+            common_ports: vec![8080],
+            build_env: std::collections::HashMap::new(),
+            runtime_copy,
+            runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
+        }
+    }
+
+    fn cache_dirs(&self) -> Vec<String> {
+        vec![".dart_tool".to_string()]
+    }
+
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let pubspec = Pubspec::parse(manifest_content?);
+        if pubspec.flutter {
+            return None;
+        }
+        pubspec.server_entry(service_path, fs)?;
+        Some(format!("/usr/local/bin/{}", pubspec.name?))
+    }
+
+    fn parse_package_metadata(
+        &self,
+        manifest_content: &str,
+    ) -> Result<(String, bool), anyhow::Error> {
+        match Pubspec::parse(manifest_content).name {
+            Some(name) => Ok((name, true)),
+            None => Err(anyhow::anyhow!("pubspec.yaml does not set a name")),
+        }
+    }
+}
+
+/// Wolfi does not package Flutter; the SDK is cloned from its stable channel. Only the web
+/// target produces something a container can serve.
+fn flutter_template(pubspec: &Pubspec, service_path: &Path, fs: &dyn FileSystem) -> BuildTemplate {
+    let web = fs.is_dir(&service_path.join("web"));
+    let mut build_commands = vec![
+        format!(
+            "git clone --depth 1 --branch stable https://github.com/flutter/flutter.git {}",
+            FLUTTER_HOME
+        ),
+        "flutter pub get".to_string(),
+    ];
+    let runtime_copy = if web {
+        build_commands.push("flutter build web --release".to_string());
+        vec![("build/web".to_string(), "/app/public".to_string())]
+    } else {
+        build_commands.push(pubspec.flutter_build_command(service_path, fs));
+        vec![]
+    };
+
+    let mut build_env = std::collections::HashMap::new();
+    build_env.insert(
+        "PATH".to_string(),
+        format!("{}/bin:/usr/local/bin:/usr/bin:/bin", FLUTTER_HOME),
+    );
+
+    BuildTemplate {
+        build_packages: vec![
+            "git".to_string(),
+            "curl".to_string(),
+            "unzip".to_string(),
+            "xz".to_string(),
+            "bash".to_string(),
+        ],
+        build_commands,
+        cache_paths: vec![".dart_tool/".to_string(), "/root/.pub-cache/".to_string()],
+        common_ports: vec![8080],
+        build_env,
+        runtime_copy,
+        runtime_env: std::collections::HashMap::new(),
+        runtime_auxiliary_commands: vec![],
+    }
+}
+
+/// Facts read from pubspec.yaml
+#[derive(Debug, Default, PartialEq)]
+pub struct Pubspec {
+    pub name: Option<String>,
+    /// `environment.sdk` constraint, e.g. `^3.3.0` or `>=3.0.0 <4.0.0`
+    pub sdk: Option<String>,
+    /// `dependencies.flutter.sdk: flutter`
+    pub flutter: bool,
+    /// Direct `dependencies`; `dev_dependencies` are left out
+    pub dependencies: Vec<String>,
+}
+
+impl Pubspec {
+    pub fn parse(content: &str) -> Self {
+        let key_re = Regex::new(r"^(\s*)([A-Za-z_][\w-]*):\s*(.*)$").expect("valid key regex");
+        let mut pubspec = Self::default();
+        let mut section = String::new();
+        let mut dependency_indent: Option<usize> = None;
+        let mut current_dependency = String::new();
+
+        for line in content.lines() {
+            let trimmed = line.trim();
+            if trimmed.is_empty() || trimmed.starts_with('#') {
+                continue;
+            }
+            let Some(caps) = key_re.captures(line) else {
+                continue;
+            };
+            let indent = caps[1].len();
+            let key = &caps[2];
+            let value = caps[3]
+                .split(" #")
+                .next()
+                .unwrap_or_default()
+                .trim()
+                .trim_matches(|c| c == '"' || c == '\'');
+
+            if indent == 0 {
+                section = key.to_string();
+                dependency_indent = None;
+                if key == "name" {
+                    pubspec.name = Some(value.to_string());
+                }
+                continue;
+            }
+
+            match section.as_str() {
+                "environment" if key == "sdk" => pubspec.sdk = Some(value.to_string()),
+                "dependencies" => {
+                    let entry_indent = *dependency_indent.get_or_insert(indent);
+                    if indent == entry_indent {
+                        current_dependency = key.to_string();
+                        pubspec.dependencies.push(key.to_string());
+                    } else if key == "sdk" && value == "flutter" && current_dependency == "flutter"
+                    {
+                        pubspec.flutter = true;
+                    }
+                }
+                _ => {}
+            }
+        }
+        pubspec
+    }
+
+    pub fn depends_on(&self, package: &str) -> bool {
+        self.dependencies.iter().any(|dep| dep == package)
+    }
+
+    /// Server entry point: dart_frog's generated server, else bin/server.dart or
+    /// bin/<name>.dart
+    pub fn server_entry(&self, service_path: &Path, fs: &dyn FileSystem) -> Option<String> {
+        if self.depends_on("dart_frog") {
+            return Some("build/bin/server.dart".to_string());
+        }
+        let mut candidates = vec!["bin/server.dart".to_string()];
+        if let Some(name) = &self.name {
+            candidates.push(format!("bin/{}.dart", name));
+        }
+        candidates
+            .into_iter()
+            .find(|entry| fs.is_file(&service_path.join(entry)))
+    }
+
+    /// Release builds signed with an upload key go to the Play Store as an app bundle
+    pub fn flutter_build_command(&self, service_path: &Path, fs: &dyn FileSystem) -> String {
+        if fs.is_file(&service_path.join("android/key.properties")) {
+            "flutter build appbundle".to_string()
+        } else {
+            "flutter build apk".to_string()
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    const FLUTTER_PUBSPEC: &str = r#"name: counter
+description: "A new Flutter project."
+publish_to: 'none'
+version: 1.0.0+1
+
+environment:
+  sdk: '>=3.3.0 <4.0.0'
+
+dependencies:
+  flutter:
+    sdk: flutter
+  cupertino_icons: ^1.0.6 # iOS style icons
+
+dev_dependencies:
+  flutter_test:
+    sdk: flutter
+  flutter_lints: ^3.0.0
+
+flutter:
+  uses-material-design: true
+"#;
+
+    #[test]
+    fn test_parse_flutter_pubspec() {
+        let pubspec = Pubspec::parse(FLUTTER_PUBSPEC);
+        assert_eq!(pubspec.name.as_deref(), Some("counter"));
+        assert_eq!(pubspec.sdk.as_deref(), Some(">=3.3.0 <4.0.0"));
+        assert!(pubspec.flutter);
+        assert_eq!(pubspec.dependencies, vec!["flutter", "cupertino_icons"]);
+    }
+
+    #[test]
+    fn test_parse_dart_server_pubspec() {
+        let pubspec = Pubspec::parse(
+            "name: notes_api\nenvironment:\n  sdk: ^3.3.0\ndependencies:\n  shelf: ^1.4.1\n  shelf_router: ^1.1.4\ndev_dependencies:\n  test: ^1.24.0\n",
+        );
+        assert_eq!(pubspec.sdk.as_deref(), Some("^3.3.0"));
+        assert!(!pubspec.flutter);
+        assert_eq!(pubspec.dependencies, vec!["shelf", "shelf_router"]);
+    }
+
+    #[test]
+    fn test_compiles_server_entry() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::create_dir(dir.path().join("bin")).unwrap();
+        std::fs::write(dir.path().join("bin/server.dart"), "void main() {}\n").unwrap();
+
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let manifest = Some("name: notes_api\ndependencies:\n  shelf: ^1.4.1\n");
+        let template =
+            DartPubBuildSystem.build_template(&wolfi_index, dir.path(), manifest, &RealFileSystem);
+        assert_eq!(
+            template.build_commands,
+            vec![
+                "dart pub get".to_string(),
+                "dart compile exe bin/server.dart -o build/notes_api".to_string()
+            ]
+        );
+        assert_eq!(
+            DartPubBuildSystem.runtime_command(dir.path(), manifest, &RealFileSystem),
+            Some("/usr/local/bin/notes_api".to_string())
+        );
+    }
+}
@@ -0,0 +1,378 @@
+//! Deno build system (TypeScript/JavaScript run by Deno)
+
+use super::{BuildSystem, BuildTemplate, ManifestPattern};
+use crate::{BuildSystemId, DetectionStack, LanguageId};
+use anyhow::Result;
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::collections::BTreeMap;
+use std::path::{Path, PathBuf};
+
+const CONFIG_FILES: [&str; 2] = ["deno.json", "deno.jsonc"];
+
+/// Modules Deno runs when the config has no usable `start` task
+const ENTRY_POINTS: [&str; 5] = ["main.ts", "mod.ts", "server.ts", "main.js", "index.ts"];
+
+pub struct DenoBuildSystem;
+
+impl BuildSystem for DenoBuildSystem {
+    fn id(&self) -> BuildSystemId {
+        BuildSystemId::Deno
+    }
+
+    fn manifest_patterns(&self) -> Vec<ManifestPattern> {
+        vec![
+            ManifestPattern {
+                filename: "deno.json".to_string(),
+                priority: 10,
+            },
+            ManifestPattern {
+                filename: "deno.jsonc".to_string(),
+                priority: 10,
+            },
+            ManifestPattern {
+                filename: "deno.lock".to_string(),
+                priority: 5,
+            },
+        ]
+    }
+
+    fn detect_all(
+        &self,
+        _repo_root: &Path,
+        file_tree: &[PathBuf],
+        _fs: &dyn FileSystem,
+    ) -> Result<Vec<DetectionStack>> {
+        Ok(file_tree
+            .iter()
+            .filter(|path| {
+                path.file_name()
+                    .and_then(|n| n.to_str())
+                    .is_some_and(|name| CONFIG_FILES.contains(&name) || name == "deno.lock")
+            })
+            .map(|path| {
+                DetectionStack::new(BuildSystemId::Deno, LanguageId::TypeScript, path.clone())
+            })
+            .collect())
+    }
+
+    fn build_template(
+        &self,
+        _wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        service_path: &Path,
+        _manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> BuildTemplate {
+        let config = DenoConfig::read(service_path, fs).unwrap_or_default();
+
+        // Caching the module graph downloads remote imports so the container starts offline
+        let mut build_commands = match config.entry_point(service_path, fs) {
+            Some(entry) => vec![format!("deno cache {}", entry)],
+            None => vec!["deno install".to_string()],
+        };
+        if config.tasks.contains_key("build") {
+            build_commands.push("deno task build".to_string());
+        }
+
+        let mut build_env = std::collections::HashMap::new();
+        build_env.insert("DENO_DIR".to_string(), ".deno".to_string());
+        let mut runtime_env = std::collections::HashMap::new();
+        runtime_env.insert("DENO_DIR".to_string(), "/app/.deno".to_string());
+
+        BuildTemplate {
+            // Wolfi ships a single unversioned `deno` package
+            build_packages: vec!["deno".to_string()],
+            build_commands,
+            cache_paths: vec![".deno/".to_string()],
+            common_ports: vec![8000],
+            build_env,
+            runtime_copy: vec![(".".to_string(), "/app".to_string())],
+            runtime_env,
+            runtime_auxiliary_commands: vec![],
+        }
+    }
+
+    fn cache_dirs(&self) -> Vec<String> {
+        vec![".deno".to_string()]
+    }
+
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        _manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let config = DenoConfig::read(service_path, fs)?;
+        match config.tasks.get("start") {
+            // Watch mode is for development; run the module itself instead
+            Some(start) if !start.contains("--watch") => Some("deno task start".to_string()),
+            _ => config
+                .entry_point(service_path, fs)
+                .map(|entry| format!("deno run --allow-net --allow-env --allow-read {}", entry)),
+        }
+    }
+
+    fn parse_package_metadata(
+        &self,
+        manifest_content: &str,
+    ) -> Result<(String, bool), anyhow::Error> {
+        let config = DenoConfig::parse(manifest_content);
+        // JSR names are scoped: "@example/inventory"
+        match config
+            .name
+            .as_deref()
+            .and_then(|name| name.rsplit('/').next())
+        {
+            Some(name) => Ok((name.to_string(), true)),
+            None => Err(anyhow::anyhow!("Deno config does not set a name")),
+        }
+    }
+}
+
+/// Facts read from deno.json or deno.jsonc
+#[derive(Debug, Default, PartialEq)]
+pub struct DenoConfig {
+    pub name: Option<String>,
+    /// Task name to command; Deno 2's `{ "command": ... }` form is flattened
+    pub tasks: BTreeMap<String, String>,
+    /// `importMap`, a path relative to the config
+    pub import_map: Option<String>,
+    /// Inline `imports`
+    pub imports: BTreeMap<String, String>,
+}
+
+impl DenoConfig {
+    pub fn parse(content: &str) -> Self {
+        let Ok(json) = serde_json::from_str::<serde_json::Value>(&strip_jsonc(content)) else {
+            return Self::default();
+        };
+
+        let tasks = json["tasks"]
+            .as_object()
+            .map(|tasks| {
+                tasks
+                    .iter()
+                    .filter_map(|(name, task)| {
+                        task.as_str()
+                            .or_else(|| task["command"].as_str())
+                            .map(|command| (name.clone(), command.to_string()))
+                    })
+                    .collect()
+            })
+            .unwrap_or_default();
+
+        Self {
+            name: json["name"].as_str().map(str::to_string),
+            tasks,
+            import_map: json["importMap"].as_str().map(str::to_string),
+            imports: imports_of(&json),
+        }
+    }
+
+    /// The service's deno.json, else its deno.jsonc
+    pub fn read(service_path: &Path, fs: &dyn FileSystem) -> Option<Self> {
+        CONFIG_FILES.iter().find_map(|file| {
+            fs.read_to_string(&service_path.join(file))
+                .ok()
+                .map(|content| Self::parse(&content))
+        })
+    }
+
+    /// Inline imports merged with those of the `importMap` file, which win on conflicts
+    pub fn dependencies(
+        &self,
+        service_path: &Path,
+        fs: &dyn FileSystem,
+    ) -> BTreeMap<String, String> {
+        let mut imports = self.imports.clone();
+        if let Some(file) = &self.import_map {
+            imports.extend(read_import_map(&service_path.join(file), fs));
+        }
+        imports
+    }
+
+    /// Module the `start` task runs, else the first conventional entry point present
+    pub fn entry_point(&self, service_path: &Path, fs: &dyn FileSystem) -> Option<String> {
+        let module_re = Regex::new(r"(\S+\.(?:ts|tsx|js|jsx|mjs))\b").expect("valid module regex");
+        self.tasks
+            .get("start")
+            .and_then(|start| module_re.captures(start).map(|caps| caps[1].to_string()))
+            .or_else(|| {
+                ENTRY_POINTS
+                    .iter()
+                    .find(|file| fs.is_file(&service_path.join(file)))
+                    .map(|file| file.to_string())
+            })
+    }
+}
+
+/// Top-level `imports` of a deno.json or an import map
+fn imports_of(json: &serde_json::Value) -> BTreeMap<String, String> {
+    json["imports"]
+        .as_object()
+        .map(|imports| {
+            imports
+                .iter()
+                .filter_map(|(specifier, target)| {
+                    target.as_str().map(|t| (specifier.clone(), t.to_string()))
+                })
+                .collect()
+        })
+        .unwrap_or_default()
+}
+
+fn read_import_map(path: &Path, fs: &dyn FileSystem) -> BTreeMap<String, String> {
+    fs.read_to_string(path)
+        .ok()
+        .and_then(|content| serde_json::from_str::<serde_json::Value>(&content).ok())
+        .map(|json| imports_of(&json))
+        .unwrap_or_default()
+}
+
+/// Deno major version implied by deno.lock's `version` header: lockfile formats 2 and 3
+/// are written by Deno 1, formats 4 and 5 by Deno 2
+pub fn lock_runtime_version(lock_content: &str) -> Option<String> {
+    let json: serde_json::Value = serde_json::from_str(lock_content).ok()?;
+    match json["version"].as_str()? {
+        "2" | "3" => Some("1".to_string()),
+        "4" | "5" => Some("2".to_string()),
+        _ => None,
+    }
+}
+
+/// Drops `//` and `/* */` comments and trailing commas so JSONC parses as JSON
+pub fn strip_jsonc(content: &str) -> String {
+    let mut out = String::with_capacity(content.len());
+    let mut chars = content.chars().peekable();
+    let mut in_string = false;
+
+    while let Some(c) = chars.next() {
+        if in_string {
+            out.push(c);
+            match c {
+                '\\' => {
+                    if let Some(escaped) = chars.next() {
+                        out.push(escaped);
+                    }
+                }
+                '"' => in_string = false,
+                _ => {}
+            }
+            continue;
+        }
+        match (c, chars.peek()) {
+            ('"', _) => {
+                in_string = true;
+                out.push(c);
+            }
+            ('/', Some('/')) => {
+                while chars.peek().is_some_and(|&next| next != '\n') {
+                    chars.next();
+                }
+            }
+            ('/', Some('*')) => {
+                chars.next();
+                let mut previous = '\0';
+                for next in chars.by_ref() {
+                    if previous == '*' && next == '/' {
+                        break;
+                    }
+                    previous = next;
+                }
+            }
+            _ => out.push(c),
+        }
+    }
+
+    Regex::new(r",(\s*[}\]])")
+        .expect("valid trailing comma regex")
+        .replace_all(&out, "$1")
+        .into_owned()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    #[test]
+    fn test_parse_jsonc_config() {
+        let config = DenoConfig::parse(
+            r#"{
+  // JSR package name
+  "name": "@example/inventory",
+  "tasks": {
+    "start": "deno run --allow-net main.ts", /* production */
+    "build": { "command": "deno check main.ts", "description": "type-check" },
+  },
+  "importMap": "./import_map.json",
+  "imports": { "std/": "https://deno.land/std@0.224.0/" }
+}"#,
+        );
+        assert_eq!(config.name.as_deref(), Some("@example/inventory"));
+        assert_eq!(
+            config.tasks.get("build").map(String::as_str),
+            Some("deno check main.ts")
+        );
+        assert_eq!(config.import_map.as_deref(), Some("./import_map.json"));
+        assert_eq!(config.imports.len(), 1);
+        assert_eq!(
+            config
+                .entry_point(Path::new("."), &RealFileSystem)
+                .as_deref(),
+            Some("main.ts")
+        );
+    }
+
+    #[test]
+    fn test_comment_markers_inside_strings_are_kept() {
+        assert_eq!(
+            strip_jsonc(r#"{"url": "https://deno.land/x/oak/"}"#),
+            r#"{"url": "https://deno.land/x/oak/"}"#
+        );
+    }
+
+    #[test]
+    fn test_import_map_file_is_merged() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("import_map.json"),
+            r#"{"imports": {"oak": "https://deno.land/x/oak@v12.6.1/mod.ts"}}"#,
+        )
+        .unwrap();
+        let config = DenoConfig::parse(r#"{"importMap": "./import_map.json"}"#);
+
+        let dependencies = config.dependencies(dir.path(), &RealFileSystem);
+        assert_eq!(
+            dependencies.get("oak").map(String::as_str),
+            Some("https://deno.land/x/oak@v12.6.1/mod.ts")
+        );
+    }
+
+    #[test]
+    fn test_lock_runtime_version() {
+        assert_eq!(
+            lock_runtime_version(r#"{"version": "4", "specifiers": {}}"#).as_deref(),
+            Some("2")
+        );
+        assert_eq!(
+            lock_runtime_version(r#"{"version": "3"}"#).as_deref(),
+            Some("1")
+        );
+        assert_eq!(lock_runtime_version("{}"), None);
+    }
+
+    #[test]
+    fn test_watch_mode_start_runs_module() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("deno.json"),
+            r#"{"tasks": {"start": "deno run -A --watch=static/ dev.ts"}}"#,
+        )
+        .unwrap();
+        assert_eq!(
+            DenoBuildSystem.runtime_command(dir.path(), None, &RealFileSystem),
+            Some("deno run --allow-net --allow-env --allow-read dev.ts".to_string())
+        );
+    }
+}
@@ -4,6 +4,7 @@ use super::{BuildSystem, BuildTemplate, ManifestPattern};
 use crate::{BuildSystemId, DetectionStack, LanguageId};
 use anyhow::Result;
 use peelbox_core::fs::FileSystem;
+use roxmltree::Document;
 use std::path::{Path, PathBuf};
 
 pub struct DotNetBuildSystem;
@@ -78,11 +79,18 @@ impl BuildSystem for DotNetBuildSystem {
     fn build_template(
         &self,
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
-        _service_path: &Path,
+        service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
-        let dotnet_version = manifest_content
-            .and_then(parse_dotnet_version)
+        // global.json pins the SDK; otherwise follow the project's target framework
+        let dotnet_version = global_json_sdk_version(service_path, fs)
+            .and_then(|sdk| {
+                sdk.split('.')
+                    .next()
+                    .map(|major| format!("dotnet-{}", major))
+            })
+            .or_else(|| manifest_content.and_then(parse_dotnet_version))
             .or_else(|| wolfi_index.get_latest_version("dotnet"))
             .expect("Failed to get dotnet version from Wolfi index");
 
@@ -97,18 +105,38 @@ impl BuildSystem for DotNetBuildSystem {
         build_env.insert("DOTNET_NOLOGO".to_string(), "1".to_string());
         build_env.insert("DOTNET_CLI_HOME".to_string(), "/root".to_string());
 
+        let is_library = manifest_content
+            .and_then(DotNetProject::parse)
+            .is_some_and(|project| !project.is_executable());
+        let (build_commands, runtime_copy) = if is_library {
+            // Class libraries have nothing to publish or run
+            (
+                vec![
+                    "dotnet restore".to_string(),
+                    "dotnet build -c Release --no-restore".to_string(),
+                ],
+                vec![],
+            )
+        } else {
+            (
+                vec![
+                    "dotnet restore".to_string(),
+                    "dotnet publish -c Release -o out".to_string(),
+                ],
+                vec![("out/".to_string(), "/app".to_string())],
+            )
+        };
+
         BuildTemplate {
             build_packages: vec![sdk_package],
-            build_commands: vec![
-                "dotnet restore".to_string(),
-                "dotnet publish -c Release -o out".to_string(),
-            ],
+            build_commands,
             cache_paths: vec!["/root/.nuget/packages/".to_string(), "obj/".to_string()],
 
             common_ports: vec![8080, 5000],
             build_env,
-            runtime_copy: vec![("out/".to_string(), "/app".to_string())],
+            runtime_copy,
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
@@ -120,6 +148,23 @@ impl BuildSystem for DotNetBuildSystem {
         ]
     }
 
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let project = DotNetProject::parse(manifest_content?)?;
+        if !project.is_executable() {
+            return None;
+        }
+
+        let assembly = project
+            .assembly_name
+            .or_else(|| project_file_stem(service_path, fs))?;
+        Some(format!("dotnet /app/{}.dll", assembly))
+    }
+
     fn is_workspace_root(&self, manifest_content: Option<&str>) -> bool {
         if let Some(content) = manifest_content {
             content.contains("Project(")
@@ -161,24 +206,202 @@ impl BuildSystem for DotNetBuildSystem {
     }
 }
 
-fn parse_dotnet_version(manifest_content: &str) -> Option<String> {
-    for line in manifest_content.lines() {
-        let trimmed = line.trim();
-
-        if trimmed.starts_with("<TargetFramework>") {
-            if let Some(framework) = trimmed
-                .strip_prefix("<TargetFramework>")?
-                .strip_suffix("</TargetFramework>")
-            {
-                if framework.starts_with("net") {
-                    let version = framework.trim_start_matches("net");
-                    if let Some(major) = version.chars().next() {
-                        return Some(format!("dotnet-{}", major));
-                    }
-                }
-            }
+/// Project properties that decide what gets published and how it starts.
+///
+/// Works for SDK-style projects (`<Project Sdk="...">`) and legacy MSBuild projects
+/// (namespaced `<Project ToolsVersion="...">` with `<TargetFrameworkVersion>`).
+#[derive(Debug, Default, PartialEq)]
+struct DotNetProject {
+    sdk: Option<String>,
+    target_framework: Option<String>,
+    output_type: Option<String>,
+    assembly_name: Option<String>,
+}
+
+impl DotNetProject {
+    fn parse(manifest_content: &str) -> Option<Self> {
+        let doc = Document::parse(manifest_content).ok()?;
+        let root = doc.root_element();
+        if !root.has_tag_name("Project") {
+            return None;
+        }
+
+        let property = |tag: &str| {
+            doc.descendants()
+                .find(|n| n.has_tag_name(tag))
+                .and_then(|n| n.text())
+                .map(|t| t.trim().to_string())
+                .filter(|t| !t.is_empty() && !t.contains("$("))
+        };
+
+        Some(Self {
+            sdk: root.attribute("Sdk").map(String::from),
+            // Multi-targeted projects list several frameworks; the first one is used
+            target_framework: property("TargetFramework")
+                .or_else(|| {
+                    property("TargetFrameworks")
+                        .and_then(|fws| fws.split(';').next().map(|f| f.trim().to_string()))
+                })
+                .or_else(|| property("TargetFrameworkVersion")),
+            output_type: property("OutputType"),
+            assembly_name: property("AssemblyName"),
+        })
+    }
+
+    /// Web and worker SDKs produce runnable apps; plain SDK projects default to Library
+    fn is_executable(&self) -> bool {
+        if let Some(output_type) = &self.output_type {
+            return matches!(output_type.as_str(), "Exe" | "WinExe");
         }
+        matches!(
+            self.sdk.as_deref(),
+            Some("Microsoft.NET.Sdk.Web") | Some("Microsoft.NET.Sdk.Worker")
+        )
     }
+}
+
+fn project_file_stem(service_path: &Path, fs: &dyn FileSystem) -> Option<String> {
+    let mut projects: Vec<PathBuf> = fs
+        .read_dir(service_path)
+        .ok()?
+        .into_iter()
+        .map(|entry| entry.path)
+        .filter(|path| {
+            path.extension()
+                .and_then(|ext| ext.to_str())
+                .is_some_and(|ext| matches!(ext, "csproj" | "fsproj" | "vbproj"))
+        })
+        .collect();
+    projects.sort();
+    projects
+        .first()
+        .and_then(|path| path.file_stem())
+        .and_then(|stem| stem.to_str())
+        .map(String::from)
+}
+
+/// `sdk.version` from a global.json next to the project (e.g., "8.0.100")
+pub fn global_json_sdk_version(service_path: &Path, fs: &dyn FileSystem) -> Option<String> {
+    let content = fs.read_to_string(&service_path.join("global.json")).ok()?;
+    let global: serde_json::Value = serde_json::from_str(&content).ok()?;
+    global["sdk"]["version"].as_str().map(String::from)
+}
+
+fn parse_dotnet_version(manifest_content: &str) -> Option<String> {
+    let framework = DotNetProject::parse(manifest_content)?.target_framework?;
 
-    None
+    // net8.0, net10.0, net8.0-windows; netcoreapp/netstandard and legacy v4.x have no
+    // matching Wolfi SDK, so the latest one is used instead
+    let version = framework.strip_prefix("net")?;
+    let major = version.split(['.', '-']).next()?;
+    if major.is_empty() || !major.chars().all(|c| c.is_ascii_digit()) {
+        return None;
+    }
+    Some(format!("dotnet-{}", major))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+    use tempfile::TempDir;
+
+    const WEB: &str = r#"<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+</Project>"#;
+
+    const LEGACY: &str = r#"<?xml version="1.0" encoding="utf-8"?>
+<Project ToolsVersion="15.0" xmlns="http://schemas.microsoft.com/developer/msbuild/2003">
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+    <AssemblyName>LegacyTool</AssemblyName>
+    <TargetFrameworkVersion>v4.7.2</TargetFrameworkVersion>
+  </PropertyGroup>
+</Project>"#;
+
+    #[test]
+    fn test_parse_sdk_style_project() {
+        let project = DotNetProject::parse(WEB).unwrap();
+        assert_eq!(project.sdk.as_deref(), Some("Microsoft.NET.Sdk.Web"));
+        assert_eq!(project.target_framework.as_deref(), Some("net8.0"));
+        assert!(project.is_executable());
+        assert_eq!(parse_dotnet_version(WEB), Some("dotnet-8".to_string()));
+    }
+
+    #[test]
+    fn test_parse_legacy_project() {
+        let project = DotNetProject::parse(LEGACY).unwrap();
+        assert_eq!(project.sdk, None);
+        assert_eq!(project.assembly_name.as_deref(), Some("LegacyTool"));
+        assert!(project.is_executable());
+        assert_eq!(parse_dotnet_version(LEGACY), None);
+    }
+
+    #[test]
+    fn test_parse_dotnet_version_multi_digit_and_multi_target() {
+        let net10 = WEB.replace("net8.0", "net10.0");
+        assert_eq!(parse_dotnet_version(&net10), Some("dotnet-10".to_string()));
+
+        let multi = r#"<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFrameworks>net9.0;net8.0</TargetFrameworks></PropertyGroup></Project>"#;
+        assert_eq!(parse_dotnet_version(multi), Some("dotnet-9".to_string()));
+    }
+
+    #[test]
+    fn test_runtime_command_uses_project_file_name() {
+        let dir = TempDir::new().unwrap();
+        std::fs::write(dir.path().join("Orders.Api.csproj"), WEB).unwrap();
+
+        assert_eq!(
+            DotNetBuildSystem.runtime_command(dir.path(), Some(WEB), &RealFileSystem),
+            Some("dotnet /app/Orders.Api.dll".to_string())
+        );
+    }
+
+    #[test]
+    fn test_library_is_built_not_published() {
+        let dir = TempDir::new().unwrap();
+        let library = r#"<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>"#;
+
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = DotNetBuildSystem.build_template(
+            &wolfi_index,
+            dir.path(),
+            Some(library),
+            &RealFileSystem,
+        );
+        assert_eq!(
+            template.build_commands,
+            vec!["dotnet restore", "dotnet build -c Release --no-restore"]
+        );
+        assert!(template.runtime_copy.is_empty());
+        assert_eq!(
+            DotNetBuildSystem.runtime_command(dir.path(), Some(library), &RealFileSystem),
+            None
+        );
+    }
+
+    #[test]
+    fn test_global_json_pins_sdk() {
+        let dir = TempDir::new().unwrap();
+        std::fs::write(
+            dir.path().join("global.json"),
+            r#"{"sdk": {"version": "8.0.100", "rollForward": "latestFeature"}}"#,
+        )
+        .unwrap();
+
+        assert_eq!(
+            global_json_sdk_version(dir.path(), &RealFileSystem),
+            Some("8.0.100".to_string())
+        );
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = DotNetBuildSystem.build_template(
+            &wolfi_index,
+            dir.path(),
+            Some(&WEB.replace("net8.0", "net9.0")),
+            &RealFileSystem,
+        );
+        assert_eq!(template.build_packages, vec!["dotnet-8-sdk"]);
+    }
 }
@@ -3,9 +3,13 @@
 use super::{BuildSystem, BuildTemplate, ManifestPattern};
 use crate::{BuildSystemId, DetectionStack, LanguageId};
 use anyhow::Result;
-use peelbox_core::fs::FileSystem;
+use peelbox_core::fs::{FileSystem, FileType};
+use regex::Regex;
 use std::path::{Path, PathBuf};
 
+const MAX_MAIN_PACKAGE_DEPTH: usize = 6;
+const PRIMARY_MAIN_PACKAGES: &[&str] = &["cmd/server", "cmd/api", "cmd/main", "."];
+
 fn parse_go_version(manifest_content: &str) -> Option<String> {
     for line in manifest_content.lines() {
         let trimmed = line.trim();
@@ -20,6 +24,121 @@ fn parse_go_version(manifest_content: &str) -> Option<String> {
     None
 }
 
+fn parse_go_work_uses(content: &str) -> Vec<String> {
+    let mut uses = Vec::new();
+    let mut in_use_block = false;
+
+    for line in content.lines() {
+        let trimmed = line.split("//").next().unwrap_or("").trim();
+
+        if in_use_block {
+            if trimmed == ")" {
+                in_use_block = false;
+            } else if !trimmed.is_empty() {
+                uses.push(trimmed.to_string());
+            }
+            continue;
+        }
+
+        if let Some(rest) = trimmed.strip_prefix("use") {
+            let rest = rest.trim();
+            if rest == "(" {
+                in_use_block = true;
+            } else if !rest.is_empty() && trimmed.starts_with("use ") {
+                uses.push(rest.to_string());
+            }
+        }
+    }
+
+    uses.into_iter()
+        .map(|path| path.trim_matches('"').to_string())
+        .filter(|path| !path.starts_with("../") && !path.starts_with('/'))
+        .map(|path| {
+            let normalized = path.trim_start_matches("./").trim_end_matches('/');
+            if normalized.is_empty() {
+                ".".to_string()
+            } else {
+                normalized.to_string()
+            }
+        })
+        .collect()
+}
+
+/// Directories (relative to the module root) containing a `package main`, sorted
+fn find_main_packages(service_path: &Path, fs: &dyn FileSystem) -> Vec<String> {
+    let package_main = Regex::new(r"(?m)^package\s+main\b").expect("package regex is valid");
+    let mut packages = Vec::new();
+    collect_main_packages(
+        service_path,
+        service_path,
+        0,
+        &package_main,
+        &mut packages,
+        fs,
+    );
+    packages.sort();
+    packages
+}
+
+fn collect_main_packages(
+    root: &Path,
+    dir: &Path,
+    depth: usize,
+    package_main: &Regex,
+    packages: &mut Vec<String>,
+    fs: &dyn FileSystem,
+) {
+    let Ok(entries) = fs.read_dir(dir) else {
+        return;
+    };
+
+    let mut is_main = false;
+    let mut subdirs = Vec::new();
+
+    for entry in entries {
+        let name = entry.file_name();
+        if entry.file_type() == FileType::Directory {
+            let skipped = name.starts_with('.')
+                || name.starts_with('_')
+                || matches!(name, "vendor" | "testdata" | "node_modules");
+            if !skipped && depth < MAX_MAIN_PACKAGE_DEPTH && !fs.exists(&entry.path.join("go.mod"))
+            {
+                subdirs.push(entry.path);
+            }
+        } else if !is_main && name.ends_with(".go") && !name.ends_with("_test.go") {
+            is_main = fs
+                .read_to_string(entry.path())
+                .map(|content| package_main.is_match(&content))
+                .unwrap_or(false);
+        }
+    }
+
+    if is_main {
+        let relative = dir.strip_prefix(root).unwrap_or(dir);
+        let relative = relative.to_string_lossy().replace('\\', "/");
+        packages.push(if relative.is_empty() {
+            ".".to_string()
+        } else {
+            relative
+        });
+    }
+
+    for subdir in subdirs {
+        collect_main_packages(root, &subdir, depth + 1, package_main, packages, fs);
+    }
+}
+
+fn primary_main_package(packages: &[String]) -> Option<&String> {
+    PRIMARY_MAIN_PACKAGES
+        .iter()
+        .find_map(|preferred| packages.iter().find(|p| p == preferred))
+        .or_else(|| packages.first())
+}
+
+fn binary_name(package: &str) -> &str {
+    package.rsplit('/').next().unwrap_or(package)
+}
+
 pub struct GoModBuildSystem;
 
 impl BuildSystem for GoModBuildSystem {
@@ -28,10 +147,16 @@ impl BuildSystem for GoModBuildSystem {
     }
 
     fn manifest_patterns(&self) -> Vec<ManifestPattern> {
-        vec![ManifestPattern {
-            filename: "go.mod".to_string(),
-            priority: 10,
-        }]
+        vec![
+            ManifestPattern {
+                filename: "go.mod".to_string(),
+                priority: 10,
+            },
+            ManifestPattern {
+                filename: "go.work".to_string(),
+                priority: 15,
+            },
+        ]
     }
 
     fn detect_all(
@@ -43,14 +168,15 @@ impl BuildSystem for GoModBuildSystem {
         let mut detections = Vec::new();
 
         for rel_path in file_tree {
-            if rel_path.file_name().and_then(|n| n.to_str()) == Some("go.mod") {
+            let file_name = rel_path.file_name().and_then(|n| n.to_str());
+            if file_name == Some("go.mod") || file_name == Some("go.work") {
                 let abs_path = repo_root.join(rel_path);
                 let content = fs.read_to_string(&abs_path).ok();
 
-                let is_valid = if let Some(c) = content.as_deref() {
-                    c.contains("module ")
-                } else {
-                    true
+                let is_valid = match (file_name, content.as_deref()) {
+                    (Some("go.work"), Some(c)) => self.is_workspace_root(Some(c)),
+                    (_, Some(c)) => c.contains("module "),
+                    (_, None) => true,
                 };
 
                 if is_valid {
@@ -69,8 +195,9 @@ impl BuildSystem for GoModBuildSystem {
     fn build_template(
         &self,
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
-        _service_path: &Path,
+        service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
         let go_package = manifest_content
             .and_then(parse_go_version)
@@ -90,18 +217,42 @@ impl BuildSystem for GoModBuildSystem {
         build_env.insert("GOSUMDB".to_string(), "off".to_string());
         build_env.insert("CGO_ENABLED".to_string(), "0".to_string());
 
+        let main_packages = find_main_packages(service_path, fs);
+        let primary = primary_main_package(&main_packages)
+            .cloned()
+            .unwrap_or_else(|| ".".to_string());
+
+        let mut build_commands = vec![
+            "go mod download".to_string(),
+            if primary == "." {
+                "go build -o app .".to_string()
+            } else {
+                format!("go build -o app ./{}", primary)
+            },
+        ];
+        let mut runtime_copy = vec![(
+            "app".to_string(),
+            "/usr/local/bin/{project_name}".to_string(),
+        )];
+        let mut runtime_auxiliary_commands = Vec::new();
+
+        for package in main_packages.iter().filter(|p| **p != primary && *p != ".") {
+            let name = binary_name(package);
+            build_commands.push(format!("go build -o bin/{} ./{}", name, package));
+            runtime_copy.push((format!("bin/{}", name), format!("/usr/local/bin/{}", name)));
+            runtime_auxiliary_commands.push(format!("/usr/local/bin/{}", name));
+        }
+
         BuildTemplate {
             build_packages: vec![go_package],
-            build_commands: vec![
-                "go mod download".to_string(),
-                "go build -o app .".to_string(),
-            ],
+            build_commands,
             cache_paths: vec![".cache/go-build".to_string(), ".cache/go-mod".to_string()],
 
             common_ports: vec![8080],
             build_env,
-            runtime_copy: vec![("app".to_string(), "/usr/local/bin/app".to_string())],
+            runtime_copy,
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands,
         }
     }
 
@@ -111,4 +262,185 @@ impl BuildSystem for GoModBuildSystem {
     fn workspace_configs(&self) -> Vec<String> {
         vec!["go.work".to_string()]
     }
+
+    fn parse_package_metadata(&self, manifest_content: &str) -> Result<(String, bool)> {
+        let module_path = manifest_content
+            .lines()
+            .find_map(|line| line.trim().strip_prefix("module "))
+            .map(|m| m.trim().trim_matches('"'));
+
+        let name = module_path
+            .and_then(|path| {
+                let mut segments = path.rsplit('/');
+                let last = segments.next()?;
+                let is_major_suffix = last.len() > 1
+                    && last.starts_with('v')
+                    && last[1..].chars().all(|c| c.is_ascii_digit());
+                if is_major_suffix {
+                    segments.next()
+                } else {
+                    Some(last)
+                }
+            })
+            .filter(|name| !name.is_empty())
+            .unwrap_or("app")
+            .to_string();
+
+        Ok((name, true))
+    }
+
+    fn is_workspace_root(&self, manifest_content: Option<&str>) -> bool {
+        manifest_content
+            .map(|content| !parse_go_work_uses(content).is_empty())
+            .unwrap_or(false)
+    }
+
+    fn parse_workspace_patterns(&self, manifest_content: &str) -> Result<Vec<String>> {
+        Ok(parse_go_work_uses(manifest_content))
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::{MockFileSystem, RealFileSystem};
+
+    const GO_WORK: &str = r#"go 1.22
+
+use (
+    ./services/api
+    ./services/worker // background jobs
+    ../outside
+)
+
+replace github.com/acme/shared => ../shared
+"#;
+
+    #[test]
+    fn test_parse_go_work_use_block() {
+        let uses = parse_go_work_uses(GO_WORK);
+        assert_eq!(uses, vec!["services/api", "services/worker"]);
+    }
+
+    #[test]
+    fn test_parse_go_work_single_use() {
+        let uses = parse_go_work_uses("go 1.22\n\nuse ./tools\nuse .\n");
+        assert_eq!(uses, vec!["tools", "."]);
+    }
+
+    #[test]
+    fn test_go_mod_is_not_workspace_root() {
+        let bs = GoModBuildSystem;
+        assert!(!bs.is_workspace_root(Some("module example.com/app\n\ngo 1.21\n")));
+        assert!(bs.is_workspace_root(Some(GO_WORK)));
+    }
+
+    #[test]
+    fn test_parse_package_metadata_module_name() {
+        let bs = GoModBuildSystem;
+        let (name, is_app) = bs
+            .parse_package_metadata("module github.com/acme/api/v2\n\ngo 1.22\n")
+            .unwrap();
+        assert_eq!(name, "api");
+        assert!(is_app);
+
+        let (name, _) = bs.parse_package_metadata("go 1.22\n\nuse ./api\n").unwrap();
+        assert_eq!(name, "app");
+    }
+
+    fn write_main(dir: &Path, rel: &str, package: &str) {
+        let path = dir.join(rel);
+        std::fs::create_dir_all(path.parent().unwrap()).unwrap();
+        std::fs::write(path, format!("package {}\n\nfunc main() {{}}\n", package)).unwrap();
+    }
+
+    #[test]
+    fn test_find_main_packages() {
+        let temp = tempfile::TempDir::new().unwrap();
+        write_main(temp.path(), "cmd/worker/main.go", "main");
+        write_main(temp.path(), "cmd/api/main.go", "main");
+        write_main(temp.path(), "internal/store/store.go", "store");
+        write_main(temp.path(), "vendor/example.com/tool/main.go", "main");
+
+        let packages = find_main_packages(temp.path(), &RealFileSystem);
+        assert_eq!(packages, vec!["cmd/api", "cmd/worker"]);
+        assert_eq!(
+            primary_main_package(&packages),
+            Some(&"cmd/api".to_string())
+        );
+    }
+
+    #[test]
+    fn test_primary_main_package_preference() {
+        let packages = vec![
+            "cmd/admin".to_string(),
+            "cmd/main".to_string(),
+            "cmd/server".to_string(),
+        ];
+        assert_eq!(
+            primary_main_package(&packages),
+            Some(&"cmd/server".to_string())
+        );
+
+        let packages = vec!["cmd/cli".to_string(), "cmd/migrate".to_string()];
+        assert_eq!(
+            primary_main_package(&packages),
+            Some(&"cmd/cli".to_string())
+        );
+    }
+
+    #[test]
+    fn test_build_template_multiple_binaries() {
+        let temp = tempfile::TempDir::new().unwrap();
+        write_main(temp.path(), "cmd/api/main.go", "main");
+        write_main(temp.path(), "cmd/worker/main.go", "main");
+
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = GoModBuildSystem.build_template(
+            &wolfi_index,
+            temp.path(),
+            Some("module example.com/app\n\ngo 1.22\n"),
+            &RealFileSystem,
+        );
+
+        assert_eq!(
+            template.build_commands,
+            vec![
+                "go mod download",
+                "go build -o app ./cmd/api",
+                "go build -o bin/worker ./cmd/worker",
+            ]
+        );
+        assert_eq!(
+            template.runtime_auxiliary_commands,
+            vec!["/usr/local/bin/worker"]
+        );
+        assert_eq!(template.runtime_copy.len(), 2);
+    }
+
+    #[test]
+    fn test_detect_all_includes_go_work() {
+        let fs = MockFileSystem::new();
+        fs.add_file("go.work", GO_WORK);
+        fs.add_file("services/api/go.mod", "module example.com/api\n\ngo 1.22\n");
+        fs.add_file(
+            "services/worker/go.mod",
+            "module example.com/worker\n\ngo 1.22\n",
+        );
+
+        let file_tree = vec![
+            PathBuf::from("go.work"),
+            PathBuf::from("services/api/go.mod"),
+            PathBuf::from("services/worker/go.mod"),
+        ];
+
+        let detections = GoModBuildSystem
+            .detect_all(Path::new(""), &file_tree, &fs)
+            .unwrap();
+
+        assert_eq!(detections.len(), 3);
+        assert!(detections
+            .iter()
+            .any(|d| d.manifest_path == PathBuf::from("go.work")));
+    }
 }
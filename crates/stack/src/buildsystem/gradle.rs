@@ -4,6 +4,7 @@ use super::{BuildSystem, BuildTemplate, ManifestPattern};
 use crate::{BuildSystemId, DetectionStack, LanguageId};
 use anyhow::Result;
 use peelbox_core::fs::FileSystem;
+use regex::Regex;
 use std::path::{Path, PathBuf};
 
 pub struct GradleBuildSystem;
@@ -61,7 +62,7 @@ impl BuildSystem for GradleBuildSystem {
                     }
                     detections.push(DetectionStack::new(
                         BuildSystemId::Gradle,
-                        LanguageId::Java,
+                        build_language(rel_path, file_tree),
                         rel_path.clone(),
                     ));
                 }
@@ -93,8 +94,9 @@ impl BuildSystem for GradleBuildSystem {
     fn build_template(
         &self,
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
-        _service_path: &Path,
+        service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
         let java_version = manifest_content
             .and_then(parse_java_version)
@@ -120,9 +122,35 @@ impl BuildSystem for GradleBuildSystem {
             "-Dorg.gradle.native=false".to_string(),
         );
 
+        // Prefer the project's wrapper so the pinned Gradle version is used
+        let gradle = if fs.is_file(&service_path.join("gradlew")) {
+            "./gradlew"
+        } else {
+            "gradle"
+        };
+        let script = manifest_content
+            .map(GradleScript::parse)
+            .unwrap_or_default();
+        let mut build_commands = vec![format!(
+            "{} build -x test --no-daemon --console=plain",
+            gradle
+        )];
+        let mut runtime_copy = vec![("build/libs/*.jar".to_string(), "/app/".to_string())];
+        if !script.spring_boot && script.main_class.is_some() {
+            let name = gradle_project_name(service_path, fs);
+            build_commands.push(format!(
+                "{} installDist --no-daemon --console=plain",
+                gradle
+            ));
+            runtime_copy.push((
+                format!("build/install/{}/lib/", name),
+                "/app/lib".to_string(),
+            ));
+        }
+
         BuildTemplate {
             build_packages: vec![java_version, gradle_version],
-            build_commands: vec!["gradle build -x test --no-daemon --console=plain".to_string()],
+            build_commands,
             cache_paths: vec![
                 "/root/.gradle/caches/".to_string(),
                 "/root/.gradle/wrapper/".to_string(),
@@ -130,14 +158,37 @@ impl BuildSystem for GradleBuildSystem {
             ],
             common_ports: vec![8080],
             build_env,
-            runtime_copy: vec![("build/libs/*.jar".to_string(), "/app/".to_string())],
+            runtime_copy,
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
     fn cache_dirs(&self) -> Vec<String> {
         vec![".gradle".to_string(), "build".to_string()]
     }
+
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let script = GradleScript::parse(manifest_content?);
+
+        if script.spring_boot {
+            let name = gradle_project_name(service_path, fs);
+            let jar = match script.version {
+                Some(version) => format!("{}-{}.jar", name, version),
+                None => format!("{}.jar", name),
+            };
+            return Some(format!("java -jar /app/{}", jar));
+        }
+
+        script
+            .main_class
+            .map(|main_class| format!("java -cp /app/lib/* {}", main_class))
+    }
     fn is_workspace_root(&self, manifest_content: Option<&str>) -> bool {
         if let Some(content) = manifest_content {
             content.contains("include(") || content.contains("include '")
@@ -168,23 +219,22 @@ impl BuildSystem for GradleBuildSystem {
     fn parse_workspace_patterns(&self, manifest_content: &str) -> Result<Vec<String>> {
         let mut patterns = Vec::new();
 
+        // include(":a", ":b") (Kotlin DSL) or include 'a', 'b' (Groovy DSL)
+        let project_re = Regex::new(r#"["']([^"']+)["']"#)?;
         for line in manifest_content.lines() {
             let trimmed = line.trim();
+            let Some(rest) = trimmed.strip_prefix("include") else {
+                continue;
+            };
+            if !rest.starts_with(['(', ' ', '\t']) {
+                continue;
+            }
 
-            if trimmed.starts_with("include") {
-                let include_str = if trimmed.contains('(') {
-                    trimmed.split('(').nth(1).and_then(|s| s.split(')').next())
-                } else {
-                    None
-                };
-
-                if let Some(projects_str) = include_str {
-                    for project in projects_str.split(',') {
-                        let project = project.trim().trim_matches(|c| c == '\'' || c == '"');
-                        if !project.is_empty() {
-                            patterns.push(project.trim_start_matches(':').to_string());
-                        }
-                    }
+            for caps in project_re.captures_iter(rest) {
+                // Nested projects use ':' as the path separator
+                let project = caps[1].trim_start_matches(':').replace(':', "/");
+                if !project.is_empty() {
+                    patterns.push(project);
                 }
             }
         }
@@ -193,7 +243,81 @@ impl BuildSystem for GradleBuildSystem {
     }
 }
 
+/// Kotlin when a Kotlin DSL build script sits alongside `.kt` or `.kts` sources, Java otherwise
+fn build_language(build_file: &Path, file_tree: &[PathBuf]) -> LanguageId {
+    let dir = build_file.parent().unwrap_or(Path::new(""));
+    let kotlin_dsl = build_file
+        .file_name()
+        .is_some_and(|n| n == "build.gradle.kts");
+    let has_kotlin_sources = file_tree.iter().any(|path| {
+        let Some(name) = path.file_name().and_then(|n| n.to_str()) else {
+            return false;
+        };
+        path.starts_with(dir)
+            && (name.ends_with(".kt") || (name.ends_with(".kts") && !name.ends_with(".gradle.kts")))
+    });
+
+    if kotlin_dsl && has_kotlin_sources {
+        LanguageId::Kotlin
+    } else {
+        LanguageId::Java
+    }
+}
+
+/// Facts read from build.gradle / build.gradle.kts that decide how the app starts
+#[derive(Debug, Default, PartialEq)]
+struct GradleScript {
+    spring_boot: bool,
+    main_class: Option<String>,
+    version: Option<String>,
+}
+
+impl GradleScript {
+    fn parse(content: &str) -> Self {
+        let spring_boot_re =
+            Regex::new(r#"(?:id\s*\(?\s*|apply\s+plugin:\s*)["']org\.springframework\.boot["']"#)
+                .expect("valid regex");
+        let main_class_re = Regex::new(
+            r#"(?m)^\s*(?:mainClass(?:\.set\(|\s*=\s*|\s+)|mainClassName\s*=\s*)["']([\w.$]+)["']"#,
+        )
+        .expect("valid regex");
+        let version_re = Regex::new(r#"(?m)^version\s*=\s*["']([^"']+)["']"#).expect("valid regex");
+
+        Self {
+            spring_boot: spring_boot_re.is_match(content),
+            main_class: main_class_re.captures(content).map(|c| c[1].to_string()),
+            version: version_re
+                .captures(content)
+                .map(|c| c[1].to_string())
+                .filter(|v| v != "unspecified"),
+        }
+    }
+}
+
+/// `rootProject.name` from settings.gradle(.kts), falling back to the directory name
+fn gradle_project_name(service_path: &Path, fs: &dyn FileSystem) -> String {
+    let name_re = Regex::new(r#"rootProject\.name\s*=\s*["']([^"']+)["']"#).expect("valid regex");
+
+    ["settings.gradle.kts", "settings.gradle"]
+        .iter()
+        .filter_map(|file| fs.read_to_string(&service_path.join(file)).ok())
+        .find_map(|content| name_re.captures(&content).map(|c| c[1].to_string()))
+        .or_else(|| {
+            service_path
+                .file_name()
+                .and_then(|n| n.to_str())
+                .map(String::from)
+        })
+        .unwrap_or_else(|| "app".to_string())
+}
+
 fn parse_java_version(manifest_content: &str) -> Option<String> {
+    // java { toolchain { languageVersion = JavaLanguageVersion.of(21) } }
+    let toolchain_re = Regex::new(r"JavaLanguageVersion\.of\(\s*(\d+)\s*\)").ok()?;
+    if let Some(caps) = toolchain_re.captures(manifest_content) {
+        return Some(format!("openjdk-{}", &caps[1]));
+    }
+
     for line in manifest_content.lines() {
         let trimmed = line.trim();
 
@@ -212,3 +336,140 @@ fn parse_java_version(manifest_content: &str) -> Option<String> {
 
     None
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    #[test]
+    fn test_parse_groovy_spring_boot() {
+        let script = GradleScript::parse(
+            "plugins {\n    id 'java'\n    id 'org.springframework.boot' version '3.2.5'\n}\n\nversion = '1.0.0'\n",
+        );
+        assert!(script.spring_boot);
+        assert_eq!(script.version.as_deref(), Some("1.0.0"));
+    }
+
+    #[test]
+    fn test_parse_kotlin_dsl_application() {
+        let script = GradleScript::parse(
+            r#"plugins {
+    kotlin("jvm") version "1.9.23"
+    application
+}
+
+application {
+    mainClass.set("com.example.MainKt")
+}
+"#,
+        );
+        assert!(!script.spring_boot);
+        assert_eq!(script.main_class.as_deref(), Some("com.example.MainKt"));
+
+        let assigned =
+            GradleScript::parse("application {\n    mainClass = \"com.example.App\"\n}\n");
+        assert_eq!(assigned.main_class.as_deref(), Some("com.example.App"));
+    }
+
+    #[test]
+    fn test_runtime_command_spring_boot_uses_settings_name() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("settings.gradle.kts"),
+            "rootProject.name = \"billing\"\n",
+        )
+        .unwrap();
+        let script = "plugins {\n    id(\"org.springframework.boot\") version \"3.2.5\"\n}\nversion = \"0.3.0\"\n";
+
+        assert_eq!(
+            GradleBuildSystem.runtime_command(dir.path(), Some(script), &RealFileSystem),
+            Some("java -jar /app/billing-0.3.0.jar".to_string())
+        );
+    }
+
+    #[test]
+    fn test_application_plugin_installs_distribution() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("settings.gradle"),
+            "rootProject.name = 'tool'\n",
+        )
+        .unwrap();
+        std::fs::write(dir.path().join("gradlew"), "#!/bin/sh\n").unwrap();
+        let script = "plugins {\n    id 'application'\n}\n\napplication {\n    mainClass = 'com.example.Tool'\n}\n";
+
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = GradleBuildSystem.build_template(
+            &wolfi_index,
+            dir.path(),
+            Some(script),
+            &RealFileSystem,
+        );
+        assert_eq!(
+            template.build_commands,
+            vec![
+                "./gradlew build -x test --no-daemon --console=plain".to_string(),
+                "./gradlew installDist --no-daemon --console=plain".to_string(),
+            ]
+        );
+        assert!(template.runtime_copy.contains(&(
+            "build/install/tool/lib/".to_string(),
+            "/app/lib".to_string()
+        )));
+        assert_eq!(
+            GradleBuildSystem.runtime_command(dir.path(), Some(script), &RealFileSystem),
+            Some("java -cp /app/lib/* com.example.Tool".to_string())
+        );
+    }
+
+    #[test]
+    fn test_parse_workspace_patterns_groovy_and_kotlin() {
+        let groovy =
+            "rootProject.name = 'multi'\ninclude 'lib', 'api-service'\ninclude ':tools:cli'\n";
+        assert_eq!(
+            GradleBuildSystem.parse_workspace_patterns(groovy).unwrap(),
+            vec!["lib", "api-service", "tools/cli"]
+        );
+
+        let kotlin = "include(\":app\", \":core\")\nincludeBuild(\"build-logic\")\n";
+        assert_eq!(
+            GradleBuildSystem.parse_workspace_patterns(kotlin).unwrap(),
+            vec!["app", "core"]
+        );
+    }
+
+    #[test]
+    fn test_build_language() {
+        let tree: Vec<PathBuf> = [
+            "build.gradle.kts",
+            "src/main/kotlin/com/example/Application.kt",
+            "legacy/build.gradle.kts",
+            "legacy/src/main/java/com/example/Legacy.java",
+            "groovy/build.gradle",
+            "groovy/src/main/kotlin/Main.kt",
+        ]
+        .iter()
+        .map(PathBuf::from)
+        .collect();
+
+        assert_eq!(
+            build_language(Path::new("build.gradle.kts"), &tree),
+            LanguageId::Kotlin
+        );
+        assert_eq!(
+            build_language(Path::new("legacy/build.gradle.kts"), &tree),
+            LanguageId::Java
+        );
+        assert_eq!(
+            build_language(Path::new("groovy/build.gradle"), &tree),
+            LanguageId::Java
+        );
+    }
+
+    #[test]
+    fn test_parse_java_version_toolchain() {
+        let script = "java {\n    toolchain {\n        languageVersion = JavaLanguageVersion.of(21)\n    }\n}\n";
+        assert_eq!(parse_java_version(script), Some("openjdk-21".to_string()));
+    }
+}
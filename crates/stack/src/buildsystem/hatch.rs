@@ -0,0 +1,164 @@
+//! Hatch build system (Python)
+
+use super::pyproject::PyprojectDetector;
+use super::python_common::{parse_pyproject_toml_version, read_python_version_file};
+use super::{BuildSystem, BuildTemplate, ManifestPattern};
+use crate::{BuildSystemId, DetectionStack, LanguageId};
+use anyhow::Result;
+use peelbox_core::fs::FileSystem;
+use std::path::{Path, PathBuf};
+
+pub struct HatchBuildSystem;
+
+impl BuildSystem for HatchBuildSystem {
+    fn id(&self) -> BuildSystemId {
+        BuildSystemId::Hatch
+    }
+
+    fn manifest_patterns(&self) -> Vec<ManifestPattern> {
+        vec![ManifestPattern {
+            filename: "pyproject.toml".to_string(),
+            priority: 12,
+        }]
+    }
+
+    fn detect_all(
+        &self,
+        repo_root: &Path,
+        file_tree: &[PathBuf],
+        fs: &dyn FileSystem,
+    ) -> Result<Vec<DetectionStack>> {
+        let mut detections = Vec::new();
+
+        for rel_path in file_tree {
+            if rel_path.file_name().and_then(|n| n.to_str()) == Some("pyproject.toml") {
+                let abs_path = repo_root.join(rel_path);
+                let content = fs.read_to_string(&abs_path).ok();
+
+                let is_valid = content.as_deref().map_or(true, |c| {
+                    PyprojectDetector::tool(c).map(|tool| tool.build_system())
+                        == Some(BuildSystemId::Hatch)
+                });
+
+                if is_valid {
+                    detections.push(DetectionStack::new(
+                        BuildSystemId::Hatch,
+                        LanguageId::Python,
+                        rel_path.clone(),
+                    ));
+                }
+            }
+        }
+
+        Ok(detections)
+    }
+
+    fn build_template(
+        &self,
+        wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> BuildTemplate {
+        let python_version = read_python_version_file(service_path, fs)
+            .or_else(|| manifest_content.and_then(parse_pyproject_toml_version))
+            .or_else(|| wolfi_index.get_latest_version("python"))
+            .expect("Failed to get python version from Wolfi index");
+
+        // Derive version-specific pip package from Python version
+        // python-3.14 -> py3.14-pip
+        let pip_package = python_version
+            .strip_prefix("python-")
+            .map(|v| format!("py{}-pip", v))
+            .unwrap_or_else(|| "py3-pip".to_string());
+
+        let mut build_env = std::collections::HashMap::new();
+        build_env.insert(
+            "HATCH_CACHE_DIR".to_string(),
+            "/root/.cache/hatch".to_string(),
+        );
+        // Puts the default environment in the project rather than Hatch's data directory
+        build_env.insert(
+            "HATCH_ENV_TYPE_VIRTUAL_PATH".to_string(),
+            ".venv".to_string(),
+        );
+
+        BuildTemplate {
+            build_packages: vec![
+                python_version.clone(),
+                pip_package,
+                "build-base".to_string(),
+            ],
+            // Install hatch and create the default environment in .venv
+            build_commands: vec![
+                "pip install --user hatch".to_string(),
+                "/root/.local/bin/hatch env create".to_string(),
+            ],
+            cache_paths: vec![
+                "/root/.cache/hatch/".to_string(),
+                "/root/.cache/pip/".to_string(),
+            ],
+            common_ports: vec![8000, 5000],
+            build_env,
+            runtime_copy: vec![(".".to_string(), "/build".to_string())],
+            runtime_env: {
+                let mut env = std::collections::HashMap::new();
+                env.insert("VIRTUAL_ENV".to_string(), "/build/.venv".to_string());
+                env.insert(
+                    "PATH".to_string(),
+                    "/build/.venv/bin:/usr/local/bin:/usr/bin:/bin".to_string(),
+                );
+                env
+            },
+            runtime_auxiliary_commands: vec![],
+        }
+    }
+
+    fn cache_dirs(&self) -> Vec<String> {
+        vec![".cache/hatch".to_string()]
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    #[test]
+    fn test_detects_hatchling_backend_or_tool_section() {
+        let fs = MockFileSystem::new();
+        fs.add_file(
+            "api/pyproject.toml",
+            "[project]\nname = \"api\"\n\n[build-system]\nrequires = [\"hatchling\"]\nbuild-backend = \"hatchling.build\"\n",
+        );
+        fs.add_file(
+            "worker/pyproject.toml",
+            "[project]\nname = \"worker\"\n\n[tool.hatch.envs.default]\ndependencies = [\"pytest\"]\n",
+        );
+        fs.add_file(
+            "web/pyproject.toml",
+            "[project]\nname = \"web\"\n\n[build-system]\nrequires = [\"pdm-backend\"]\nbuild-backend = \"pdm.backend\"\n",
+        );
+
+        let files = vec![
+            PathBuf::from("api/pyproject.toml"),
+            PathBuf::from("web/pyproject.toml"),
+            PathBuf::from("worker/pyproject.toml"),
+        ];
+        let detections = HatchBuildSystem
+            .detect_all(Path::new(""), &files, &fs)
+            .unwrap();
+
+        let manifests: Vec<&Path> = detections
+            .iter()
+            .map(|d| d.manifest_path.as_path())
+            .collect();
+        assert_eq!(
+            manifests,
+            vec![
+                Path::new("api/pyproject.toml"),
+                Path::new("worker/pyproject.toml")
+            ]
+        );
+    }
+}
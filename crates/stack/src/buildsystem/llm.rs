@@ -182,6 +182,7 @@ Identify manifest files and their build systems. Return JSON array ONLY:
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         _service_path: &Path,
         _manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
     ) -> BuildTemplate {
         self.detected_info
             .lock()
@@ -199,6 +200,7 @@ Identify manifest files and their build systems. Return JSON array ONLY:
                     build_env: std::collections::HashMap::new(),
                     runtime_copy: vec![],
                     runtime_env: std::collections::HashMap::new(),
+                    runtime_auxiliary_commands: vec![],
                 }
             })
             .unwrap_or_else(|| BuildTemplate {
@@ -209,6 +211,7 @@ Identify manifest files and their build systems. Return JSON array ONLY:
                 build_env: std::collections::HashMap::new(),
                 runtime_copy: vec![],
                 runtime_env: std::collections::HashMap::new(),
+                runtime_auxiliary_commands: vec![],
             })
     }
 
@@ -47,6 +47,7 @@ impl BuildSystem for MakeBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         _service_path: &Path,
         _manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
     ) -> BuildTemplate {
         let mut build_packages = vec!["build-base".to_string()];
 
@@ -66,6 +67,7 @@ impl BuildSystem for MakeBuildSystem {
             build_env: std::collections::HashMap::new(),
             runtime_copy: vec![("app".to_string(), "/usr/local/bin/app".to_string())],
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
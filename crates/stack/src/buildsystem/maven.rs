@@ -58,6 +58,7 @@ impl BuildSystem for MavenBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         _service_path: &Path,
         manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
     ) -> BuildTemplate {
         let java_version = manifest_content
             .and_then(parse_java_version)
@@ -85,6 +86,18 @@ impl BuildSystem for MavenBuildSystem {
         let mut runtime_env = std::collections::HashMap::new();
         runtime_env.insert("CLASSPATH".to_string(), "/app/*:/app/lib/*".to_string());
 
+        let mut runtime_copy = vec![
+            ("target/*.jar".to_string(), "/app/".to_string()),
+            ("target/lib/".to_string(), "/app/lib".to_string()),
+        ];
+        let pom = manifest_content.and_then(PomInfo::parse);
+        if pom
+            .as_ref()
+            .is_some_and(|p| !p.is_spring_boot && p.main_class.is_some())
+        {
+            runtime_copy.push(("target/classes/".to_string(), "/app/classes".to_string()));
+        }
+
         BuildTemplate {
             build_packages: vec![java_version, maven_version],
             build_commands: vec![
@@ -94,17 +107,32 @@ impl BuildSystem for MavenBuildSystem {
             cache_paths: vec!["/root/.m2/repository/".to_string()],
             common_ports: vec![8080],
             build_env,
-            runtime_copy: vec![
-                ("target/*.jar".to_string(), "/app/".to_string()),
-                ("target/lib/".to_string(), "/app/lib".to_string()),
-            ],
+            runtime_copy,
             runtime_env,
+            runtime_auxiliary_commands: vec![],
         }
     }
 
     fn cache_dirs(&self) -> Vec<String> {
         vec![".m2/repository".to_string(), "target".to_string()]
     }
+
+    fn runtime_command(
+        &self,
+        _service_path: &Path,
+        manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let pom = PomInfo::parse(manifest_content?)?;
+
+        if pom.is_spring_boot {
+            return Some(format!("java -jar /app/{}.jar", pom.jar_name()?));
+        }
+
+        pom.main_class
+            .map(|main_class| format!("java -cp /app/classes:/app/lib/* {}", main_class))
+    }
+
     fn is_workspace_root(&self, manifest_content: Option<&str>) -> bool {
         if let Some(content) = manifest_content {
             content.contains("<modules>")
@@ -161,6 +189,73 @@ impl BuildSystem for MavenBuildSystem {
     }
 }
 
+/// Fields of a pom.xml that decide how the packaged application is started
+#[derive(Debug, Default, PartialEq)]
+struct PomInfo {
+    artifact_id: Option<String>,
+    version: Option<String>,
+    final_name: Option<String>,
+    main_class: Option<String>,
+    is_spring_boot: bool,
+}
+
+impl PomInfo {
+    fn parse(manifest_content: &str) -> Option<Self> {
+        let doc = Document::parse(manifest_content).ok()?;
+        let root = doc.root_element();
+        let child_text = |node: roxmltree::Node, tag: &str| {
+            node.children()
+                .find(|c| c.has_tag_name(tag))
+                .and_then(|c| c.text())
+                .map(|t| t.trim().to_string())
+        };
+
+        let parent = root.children().find(|c| c.has_tag_name("parent"));
+        let parent_artifact = parent.and_then(|p| child_text(p, "artifactId"));
+        let build = root.children().find(|c| c.has_tag_name("build"));
+
+        let uses_boot_plugin = doc.descendants().any(|n| {
+            n.has_tag_name("artifactId")
+                && n.text().map(str::trim) == Some("spring-boot-maven-plugin")
+        });
+
+        let main_class = doc
+            .descendants()
+            .find(|n| {
+                n.has_tag_name("mainClass")
+                    || n.has_tag_name("exec.mainClass")
+                    || n.has_tag_name("start-class")
+            })
+            .and_then(|n| n.text())
+            .map(|t| t.trim().to_string())
+            .filter(|t| !t.is_empty() && !t.contains("${"));
+
+        Some(Self {
+            artifact_id: child_text(root, "artifactId"),
+            version: child_text(root, "version")
+                .or_else(|| parent.and_then(|p| child_text(p, "version"))),
+            final_name: build.and_then(|b| child_text(b, "finalName")),
+            main_class,
+            is_spring_boot: parent_artifact.as_deref() == Some("spring-boot-starter-parent")
+                || uses_boot_plugin,
+        })
+    }
+
+    /// `<finalName>` or Maven's default `<artifactId>-<version>`
+    fn jar_name(&self) -> Option<String> {
+        if let Some(final_name) = &self.final_name {
+            if !final_name.contains("${") {
+                return Some(final_name.clone());
+            }
+        }
+        match (&self.artifact_id, &self.version) {
+            (Some(artifact), Some(version)) => Some(format!("{}-{}", artifact, version)),
+            (Some(artifact), None) => Some(artifact.clone()),
+            _ => None,
+        }
+    }
+}
+
 fn parse_java_version(manifest_content: &str) -> Option<String> {
     let doc = Document::parse(manifest_content).ok()?;
 
@@ -175,3 +270,109 @@ fn parse_java_version(manifest_content: &str) -> Option<String> {
 
     None
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    const SPRING_BOOT_POM: &str = r#"<?xml version="1.0"?>
+<project>
+    <parent>
+        <groupId>org.springframework.boot</groupId>
+        <artifactId>spring-boot-starter-parent</artifactId>
+        <version>3.2.5</version>
+    </parent>
+    <artifactId>orders</artifactId>
+    <version>0.0.1-SNAPSHOT</version>
+    <properties>
+        <java.version>21</java.version>
+    </properties>
+</project>"#;
+
+    const PLAIN_POM: &str = r#"<?xml version="1.0"?>
+<project>
+    <groupId>com.example</groupId>
+    <artifactId>cli</artifactId>
+    <version>1.0</version>
+    <properties>
+        <maven.compiler.source>17</maven.compiler.source>
+    </properties>
+    <build>
+        <plugins>
+            <plugin>
+                <artifactId>maven-jar-plugin</artifactId>
+                <configuration>
+                    <archive>
+                        <manifest>
+                            <mainClass>com.example.App</mainClass>
+                        </manifest>
+                    </archive>
+                </configuration>
+            </plugin>
+        </plugins>
+    </build>
+</project>"#;
+
+    #[test]
+    fn test_spring_boot_parent_runs_fat_jar() {
+        assert_eq!(
+            MavenBuildSystem.runtime_command(
+                Path::new("."),
+                Some(SPRING_BOOT_POM),
+                &RealFileSystem
+            ),
+            Some("java -jar /app/orders-0.0.1-SNAPSHOT.jar".to_string())
+        );
+    }
+
+    #[test]
+    fn test_plain_maven_runs_main_class() {
+        assert_eq!(
+            MavenBuildSystem.runtime_command(Path::new("."), Some(PLAIN_POM), &RealFileSystem),
+            Some("java -cp /app/classes:/app/lib/* com.example.App".to_string())
+        );
+
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = MavenBuildSystem.build_template(
+            &wolfi_index,
+            Path::new("."),
+            Some(PLAIN_POM),
+            &RealFileSystem,
+        );
+        assert!(template
+            .runtime_copy
+            .iter()
+            .any(|(from, _)| from == "target/classes/"));
+    }
+
+    #[test]
+    fn test_plain_maven_without_main_class() {
+        let pom = "<project><artifactId>lib</artifactId><version>1.0</version></project>";
+        assert_eq!(
+            MavenBuildSystem.runtime_command(Path::new("."), Some(pom), &RealFileSystem),
+            None
+        );
+    }
+
+    #[test]
+    fn test_final_name_overrides_jar_name() {
+        let pom = PomInfo::parse(
+            "<project><artifactId>a</artifactId><version>1</version><build><finalName>app</finalName></build></project>",
+        )
+        .unwrap();
+        assert_eq!(pom.jar_name(), Some("app".to_string()));
+    }
+
+    #[test]
+    fn test_parse_java_version() {
+        assert_eq!(
+            parse_java_version(SPRING_BOOT_POM),
+            Some("openjdk-21".to_string())
+        );
+        assert_eq!(
+            parse_java_version(PLAIN_POM),
+            Some("openjdk-17".to_string())
+        );
+    }
+}
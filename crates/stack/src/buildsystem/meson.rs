@@ -57,6 +57,7 @@ impl BuildSystem for MesonBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         _service_path: &Path,
         _manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
     ) -> BuildTemplate {
         let mut build_packages = vec!["build-base".to_string()];
 
@@ -79,6 +80,7 @@ impl BuildSystem for MesonBuildSystem {
             build_env: std::collections::HashMap::new(),
             runtime_copy: vec![("builddir/app".to_string(), "/usr/local/bin/app".to_string())],
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
@@ -0,0 +1,197 @@
+//! Mill build system (Scala)
+
+use super::{BuildSystem, BuildTemplate, ManifestPattern};
+use crate::{BuildSystemId, DetectionStack, LanguageId};
+use anyhow::Result;
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+pub struct MillBuildSystem;
+
+impl BuildSystem for MillBuildSystem {
+    fn id(&self) -> BuildSystemId {
+        BuildSystemId::Mill
+    }
+
+    fn manifest_patterns(&self) -> Vec<ManifestPattern> {
+        vec![ManifestPattern {
+            filename: "build.sc".to_string(),
+            priority: 10,
+        }]
+    }
+
+    fn detect_all(
+        &self,
+        repo_root: &Path,
+        file_tree: &[PathBuf],
+        fs: &dyn FileSystem,
+    ) -> Result<Vec<DetectionStack>> {
+        let mut detections = Vec::new();
+
+        for rel_path in file_tree {
+            if rel_path.file_name().and_then(|n| n.to_str()) == Some("build.sc") {
+                let content = fs.read_to_string(&repo_root.join(rel_path)).ok();
+                // Ammonite scripts share the extension; Mill builds declare modules
+                let is_valid = if let Some(c) = content.as_deref() {
+                    c.contains("extends") && c.contains("Module")
+                } else {
+                    true
+                };
+
+                if is_valid {
+                    detections.push(DetectionStack::new(
+                        BuildSystemId::Mill,
+                        LanguageId::Scala,
+                        rel_path.clone(),
+                    ));
+                }
+            }
+        }
+
+        Ok(detections)
+    }
+
+    fn build_template(
+        &self,
+        wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> BuildTemplate {
+        let java_version = wolfi_index
+            .get_latest_version("openjdk")
+            .expect("Failed to get openjdk version from Wolfi index");
+        let java_home = format!(
+            "/usr/lib/jvm/java-{}-openjdk",
+            java_version.trim_start_matches("openjdk-")
+        );
+
+        let mut build_env = std::collections::HashMap::new();
+        build_env.insert("JAVA_HOME".to_string(), java_home);
+
+        // Wolfi does not package Mill; the checked-in launcher downloads the pinned version
+        let mill = mill_launcher(service_path, fs);
+        let (build_commands, runtime_copy) = match manifest_content.and_then(main_module) {
+            Some(module) => (
+                vec![format!("{} --no-server {}.assembly", mill, module)],
+                vec![(
+                    format!("out/{}/assembly.dest/out.jar", module),
+                    format!("/app/{}.jar", module),
+                )],
+            ),
+            None => (vec![format!("{} --no-server __.compile", mill)], vec![]),
+        };
+
+        BuildTemplate {
+            build_packages: vec![java_version, "curl".to_string()],
+            build_commands,
+            cache_paths: vec![
+                "/root/.cache/mill/".to_string(),
+                "/root/.cache/coursier/".to_string(),
+            ],
+            common_ports: vec![8080],
+            build_env,
+            runtime_copy,
+            runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
+        }
+    }
+
+    fn cache_dirs(&self) -> Vec<String> {
+        vec!["out".to_string()]
+    }
+
+    fn runtime_command(
+        &self,
+        _service_path: &Path,
+        manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let module = main_module(manifest_content?)?;
+        Some(format!("java -jar /app/{}.jar", module))
+    }
+
+    fn parse_package_metadata(
+        &self,
+        manifest_content: &str,
+    ) -> Result<(String, bool), anyhow::Error> {
+        match main_module(manifest_content) {
+            Some(module) => Ok((module, true)),
+            None => Err(anyhow::anyhow!(
+                "build.sc declares no module with a mainClass"
+            )),
+        }
+    }
+}
+
+/// Mill module declaring `def mainClass`, i.e. the nearest `object` opened before it
+pub fn main_module(build_sc: &str) -> Option<String> {
+    let main_class = build_sc.find("def mainClass")?;
+    let object_re = Regex::new(r"(?m)^\s*object\s+(\w+)\s+extends").ok()?;
+    object_re
+        .captures_iter(&build_sc[..main_class])
+        .last()
+        .map(|caps| caps[1].to_string())
+}
+
+/// The repository's `./mill` launcher script, or a `mill` on PATH
+fn mill_launcher(service_path: &Path, fs: &dyn FileSystem) -> &'static str {
+    if fs.is_file(&service_path.join("mill")) {
+        "./mill"
+    } else {
+        "mill"
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    const BUILD: &str = r#"import mill._, scalalib._
+
+object core extends ScalaModule {
+  def scalaVersion = "3.3.3"
+}
+
+object server extends ScalaModule {
+  def scalaVersion = "3.3.3"
+  def moduleDeps = Seq(core)
+  def mainClass = Some("server.Main")
+
+  object test extends ScalaTests with TestModule.Munit
+}
+"#;
+
+    #[test]
+    fn test_main_module() {
+        assert_eq!(main_module(BUILD).as_deref(), Some("server"));
+        assert_eq!(main_module("object core extends ScalaModule {}\n"), None);
+    }
+
+    #[test]
+    fn test_assembly_of_main_module() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(dir.path().join("mill"), "#!/usr/bin/env sh\n").unwrap();
+
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template =
+            MillBuildSystem.build_template(&wolfi_index, dir.path(), Some(BUILD), &RealFileSystem);
+        assert_eq!(
+            template.build_commands,
+            vec!["./mill --no-server server.assembly"]
+        );
+        assert_eq!(
+            template.runtime_copy,
+            vec![(
+                "out/server/assembly.dest/out.jar".to_string(),
+                "/app/server.jar".to_string()
+            )]
+        );
+        assert_eq!(
+            MillBuildSystem.runtime_command(dir.path(), Some(BUILD), &RealFileSystem),
+            Some("java -jar /app/server.jar".to_string())
+        );
+    }
+}
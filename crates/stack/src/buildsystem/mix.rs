@@ -4,6 +4,7 @@ use super::{BuildSystem, BuildTemplate, ManifestPattern};
 use crate::{BuildSystemId, DetectionStack, LanguageId};
 use anyhow::Result;
 use peelbox_core::fs::FileSystem;
+use regex::Regex;
 use std::path::{Path, PathBuf};
 
 pub struct MixBuildSystem;
@@ -44,31 +45,202 @@ impl BuildSystem for MixBuildSystem {
     fn build_template(
         &self,
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
-        _service_path: &Path,
-        _manifest_content: Option<&str>,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
-        let elixir_version = wolfi_index
-            .get_latest_version("elixir")
+        let elixir_version = read_tool_versions(service_path, fs)
+            .or_else(|| manifest_content.and_then(parse_mix_elixir_version))
+            .filter(|version| wolfi_index.has_package(version))
+            .or_else(|| wolfi_index.get_latest_version("elixir"))
             .expect("Failed to get elixir version from Wolfi index");
 
+        let mut build_packages = vec![elixir_version.clone()];
+        let mut build_commands = vec![
+            "mix local.hex --force".to_string(),
+            "mix local.rebar --force".to_string(),
+            "mix deps.get --only prod".to_string(),
+            "mix compile".to_string(),
+        ];
+
+        let is_phoenix = manifest_content.is_some_and(|c| mix_depends_on(c, "phoenix"));
+        if is_phoenix && fs.is_file(&service_path.join("assets/package.json")) {
+            // Webpack-era Phoenix apps bundle and digest frontend assets via npm
+            if let Some(node_version) = wolfi_index.get_latest_version("nodejs") {
+                build_packages.push(node_version);
+                build_packages.push("npm".to_string());
+            }
+            build_commands.push("npm install --prefix assets".to_string());
+            build_commands.push("npm run deploy --prefix assets".to_string());
+            build_commands.push("mix phx.digest".to_string());
+        }
+
+        // Hex/rebar archives live under MIX_HOME and must travel with the app,
+        // since mix itself starts the release at runtime
+        let mut build_env = std::collections::HashMap::new();
+        build_env.insert("MIX_ENV".to_string(), "prod".to_string());
+        build_env.insert("MIX_HOME".to_string(), "/app/.mix".to_string());
+        build_env.insert("HEX_HOME".to_string(), "/app/.hex".to_string());
+
+        let runtime_env = build_env.clone();
+
         BuildTemplate {
-            build_packages: vec![elixir_version.clone()],
-            build_commands: vec![
-                "mix local.hex --force".to_string(),
-                "mix local.rebar --force".to_string(),
-                "mix deps.get".to_string(),
-                "mix compile".to_string(),
-            ],
+            build_packages,
+            build_commands,
             cache_paths: vec!["_build/".to_string(), "deps/".to_string()],
 
             common_ports: vec![4000],
-            build_env: std::collections::HashMap::new(),
-            runtime_copy: vec![],
-            runtime_env: std::collections::HashMap::new(),
+            build_env,
+            runtime_copy: vec![(".".to_string(), "/app".to_string())],
+            runtime_env,
+            runtime_auxiliary_commands: vec![],
         }
     }
 
     fn cache_dirs(&self) -> Vec<String> {
         vec!["_build".to_string(), "deps".to_string()]
     }
+
+    fn runtime_command(
+        &self,
+        _service_path: &Path,
+        manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
+    ) -> Option<String> {
+        if manifest_content.is_some_and(|c| mix_depends_on(c, "phoenix")) {
+            Some("mix phx.server".to_string())
+        } else {
+            Some("mix run --no-halt".to_string())
+        }
+    }
+}
+
+/// Whether `deps` in mix.exs contains `{:<name>, ...}`
+fn mix_depends_on(manifest_content: &str, dep: &str) -> bool {
+    let pattern = format!(r"\{{\s*:{}\s*,", regex::escape(dep));
+    Regex::new(&pattern).is_ok_and(|re| re.is_match(manifest_content))
+}
+
+/// asdf `.tool-versions` entry, e.g. `elixir 1.16.2-otp-26`
+fn read_tool_versions(service_path: &Path, fs: &dyn FileSystem) -> Option<String> {
+    let content = fs
+        .read_to_string(&service_path.join(".tool-versions"))
+        .ok()?;
+    content
+        .lines()
+        .filter_map(|line| line.trim().strip_prefix("elixir "))
+        .find_map(|version| elixir_package(version.trim()))
+}
+
+/// `elixir: "~> 1.15"` requirement from the mix.exs project config
+fn parse_mix_elixir_version(manifest_content: &str) -> Option<String> {
+    let re = Regex::new(r#"elixir:\s*"([^"]+)""#).ok()?;
+    let requirement = re.captures(manifest_content)?;
+    elixir_package(requirement[1].trim_start_matches(['~', '>', '=', ' ']))
+}
+
+fn elixir_package(version: &str) -> Option<String> {
+    let mut parts = version.split(['.', '-']);
+    let major = parts
+        .next()
+        .filter(|p| p.chars().all(|c| c.is_ascii_digit()))?;
+    let minor = parts
+        .next()
+        .filter(|p| p.chars().all(|c| c.is_ascii_digit()))?;
+    Some(format!("elixir-{}.{}", major, minor))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+    use tempfile::TempDir;
+
+    const PHOENIX: &str = r#"defmodule Shop.MixProject do
+  use Mix.Project
+
+  def project do
+    [app: :shop, version: "0.1.0", elixir: "~> 1.15", deps: deps()]
+  end
+
+  defp deps do
+    [
+      {:phoenix, "~> 1.7.14"},
+      {:phoenix_live_view, "~> 0.20.17"},
+      {:plug_cowboy, "~> 2.7"}
+    ]
+  end
+end
+"#;
+
+    #[test]
+    fn test_mix_depends_on() {
+        assert!(mix_depends_on(PHOENIX, "phoenix"));
+        assert!(mix_depends_on(PHOENIX, "plug_cowboy"));
+        assert!(!mix_depends_on(PHOENIX, "plug"));
+        assert!(!mix_depends_on(PHOENIX, "ecto"));
+    }
+
+    #[test]
+    fn test_elixir_version_sources() {
+        let dir = TempDir::new().unwrap();
+        assert_eq!(read_tool_versions(dir.path(), &RealFileSystem), None);
+
+        std::fs::write(
+            dir.path().join(".tool-versions"),
+            "erlang 26.2.5\nelixir 1.16.2-otp-26\n",
+        )
+        .unwrap();
+        assert_eq!(
+            read_tool_versions(dir.path(), &RealFileSystem),
+            Some("elixir-1.16".to_string())
+        );
+        assert_eq!(
+            parse_mix_elixir_version(PHOENIX),
+            Some("elixir-1.15".to_string())
+        );
+    }
+
+    #[test]
+    fn test_phoenix_with_assets_runs_npm_deploy() {
+        let dir = TempDir::new().unwrap();
+        std::fs::create_dir(dir.path().join("assets")).unwrap();
+        std::fs::write(dir.path().join("assets/package.json"), "{}").unwrap();
+
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template =
+            MixBuildSystem.build_template(&wolfi_index, dir.path(), Some(PHOENIX), &RealFileSystem);
+        assert!(template.build_packages.contains(&"npm".to_string()));
+        assert_eq!(
+            &template.build_commands[4..],
+            &[
+                "npm install --prefix assets".to_string(),
+                "npm run deploy --prefix assets".to_string(),
+                "mix phx.digest".to_string(),
+            ]
+        );
+        assert_eq!(
+            MixBuildSystem.runtime_command(dir.path(), Some(PHOENIX), &RealFileSystem),
+            Some("mix phx.server".to_string())
+        );
+    }
+
+    #[test]
+    fn test_plain_mix_project() {
+        let dir = TempDir::new().unwrap();
+        let manifest = "defmodule Worker.MixProject do\n  defp deps do\n    [{:jason, \"~> 1.4\"}]\n  end\nend\n";
+
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = MixBuildSystem.build_template(
+            &wolfi_index,
+            dir.path(),
+            Some(manifest),
+            &RealFileSystem,
+        );
+        assert_eq!(template.build_commands.len(), 4);
+        assert_eq!(
+            MixBuildSystem.runtime_command(dir.path(), Some(manifest), &RealFileSystem),
+            Some("mix run --no-halt".to_string())
+        );
+    }
 }
@@ -6,7 +6,7 @@
 
 use crate::DetectionStack;
 use anyhow::Result;
-use peelbox_core::fs::FileSystem;
+use peelbox_core::fs::{FileSystem, FileType};
 use serde::{Deserialize, Serialize};
 use std::path::{Path, PathBuf};
 
@@ -25,6 +25,9 @@ pub struct BuildTemplate {
     pub runtime_copy: Vec<(String, String)>,
     #[serde(default)]
     pub runtime_env: std::collections::HashMap<String, String>,
+    /// Commands for additional binaries shipped next to the primary entrypoint
+    #[serde(default)]
+    pub runtime_auxiliary_commands: Vec<String>,
 }
 
 /// Manifest pattern for build system detection
@@ -52,16 +55,42 @@ pub trait BuildSystem: Send + Sync {
     /// Get build template for this build system
     /// Uses WolfiPackageIndex for dynamic version discovery
     /// service_path allows build systems to read version hint files (.nvmrc, .python-version, etc.)
+    /// through `fs`
     fn build_template(
         &self,
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate;
 
     /// Cache directories for this build system
     fn cache_dirs(&self) -> Vec<String>;
 
+    /// Start command derived from the manifest (e.g., the jar Maven produces)
+    /// Takes precedence over runtime-detected entrypoints. Default returns None.
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let _ = (service_path, manifest_content, fs);
+        None
+    }
+
+    /// Whether the build produces something to run; a library only gets compiled, so it has
+    /// no start command or port. Default returns true.
+    fn produces_runnable(
+        &self,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> bool {
+        let _ = (service_path, manifest_content, fs);
+        true
+    }
+
     /// Check if manifest indicates workspace/monorepo root
     fn is_workspace_root(&self, manifest_content: Option<&str>) -> bool {
         let _ = manifest_content;
@@ -100,9 +129,10 @@ pub trait BuildSystem: Send + Sync {
         &self,
         repo_path: &std::path::Path,
         pattern: &str,
+        fs: &dyn FileSystem,
     ) -> Result<Vec<std::path::PathBuf>, anyhow::Error> {
         let project_path = repo_path.join(pattern);
-        if project_path.exists() && project_path.is_dir() {
+        if fs.is_dir(&project_path) {
             Ok(vec![project_path])
         } else {
             Ok(vec![])
@@ -130,15 +160,17 @@ pub(crate) fn parse_package_json_workspaces(
 pub(crate) fn glob_package_json_workspace_pattern(
     repo_path: &std::path::Path,
     pattern: &str,
+    fs: &dyn FileSystem,
 ) -> Result<Vec<std::path::PathBuf>, anyhow::Error> {
     let mut results = Vec::new();
 
     if pattern.ends_with("/*") {
         let base_dir = repo_path.join(pattern.trim_end_matches("/*"));
-        if let Ok(entries) = std::fs::read_dir(&base_dir) {
-            for entry in entries.flatten() {
-                if entry.path().is_dir() {
-                    results.push(entry.path());
+        if let Ok(entries) = fs.read_dir(&base_dir) {
+            for entry in entries {
+                // Installed dependencies are never workspace members
+                if entry.file_type() == FileType::Directory && entry.file_name() != "node_modules" {
+                    results.push(entry.path);
                 }
             }
         }
@@ -151,42 +183,66 @@ mod node_common;
 mod python_common;
 mod ruby_common;
 
+pub mod bazel;
 pub mod bun;
 pub mod bundler;
+pub mod cabal;
 pub mod cargo;
 pub mod cmake;
 pub mod composer;
+pub mod conda;
+pub mod dart_pub;
+pub mod deno;
 pub mod dotnet;
 pub mod go_mod;
 pub mod gradle;
+pub mod hatch;
 pub mod llm;
 pub mod make;
 pub mod maven;
 pub mod meson;
+pub mod mill;
 pub mod mix;
 pub mod npm;
+pub mod pdm;
 pub mod pip;
 pub mod pipenv;
 pub mod pnpm;
 pub mod poetry;
+pub mod pyproject;
+pub mod sbt;
+pub mod stack;
+pub mod swiftpm;
 pub mod yarn;
 
+pub use bazel::BazelBuildSystem;
 pub use bun::BunBuildSystem;
 pub use bundler::BundlerBuildSystem;
+pub use cabal::CabalBuildSystem;
 pub use cargo::CargoBuildSystem;
 pub use cmake::CMakeBuildSystem;
 pub use composer::ComposerBuildSystem;
+pub use conda::{CondaBuildSystem, CondaDetector, CondaEnvironment};
+pub use dart_pub::DartPubBuildSystem;
+pub use deno::DenoBuildSystem;
 pub use dotnet::DotNetBuildSystem;
 pub use go_mod::GoModBuildSystem;
 pub use gradle::GradleBuildSystem;
+pub use hatch::HatchBuildSystem;
 pub use llm::LLMBuildSystem;
 pub use make::MakeBuildSystem;
 pub use maven::MavenBuildSystem;
 pub use meson::MesonBuildSystem;
+pub use mill::MillBuildSystem;
 pub use mix::MixBuildSystem;
 pub use npm::NpmBuildSystem;
+pub use pdm::PdmBuildSystem;
 pub use pip::PipBuildSystem;
 pub use pipenv::PipenvBuildSystem;
 pub use pnpm::PnpmBuildSystem;
 pub use poetry::PoetryBuildSystem;
+pub use pyproject::{PyprojectDetector, PyprojectTool};
+pub use sbt::SbtBuildSystem;
+pub use stack::StackBuildSystem;
+pub use swiftpm::SwiftPmBuildSystem;
 pub use yarn::YarnBuildSystem;
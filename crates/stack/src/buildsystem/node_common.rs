@@ -1,4 +1,5 @@
-use std::path::Path;
+use peelbox_core::fs::FileSystem;
+use std::path::{Path, PathBuf};
 
 pub(super) fn normalize_node_version(version_str: &str) -> Option<String> {
     let ver_num = version_str
@@ -12,10 +13,10 @@ pub(super) fn normalize_node_version(version_str: &str) -> Option<String> {
     Some(format!("nodejs-{}", ver_num))
 }
 
-pub(super) fn read_node_version_file(service_path: &Path) -> Option<String> {
+pub(super) fn read_node_version_file(service_path: &Path, fs: &dyn FileSystem) -> Option<String> {
     for file_name in [".nvmrc", ".node-version"] {
         let path = service_path.join(file_name);
-        if let Ok(content) = std::fs::read_to_string(&path) {
+        if let Ok(content) = fs.read_to_string(&path) {
             if !content.trim().is_empty() {
                 return normalize_node_version(&content);
             }
@@ -24,8 +25,105 @@ pub(super) fn read_node_version_file(service_path: &Path) -> Option<String> {
     None
 }
 
+/// A root pnpm-workspace.yaml puts every package.json of the repository under pnpm
+pub(super) fn in_pnpm_workspace(file_tree: &[PathBuf]) -> bool {
+    file_tree
+        .iter()
+        .any(|path| path == Path::new("pnpm-workspace.yaml"))
+}
+
 pub(super) fn parse_node_version(manifest_content: &str) -> Option<String> {
     let package: serde_json::Value = serde_json::from_str(manifest_content).ok()?;
     let node_version = package["engines"]["node"].as_str()?;
     normalize_node_version(node_version)
 }
+
+/// Appends `<runner> run build` when package.json declares a `build` script.
+///
+/// The detected manifest may be a lockfile, so package.json is read from the service
+/// directory first.
+pub(super) fn node_build_commands(
+    install: &str,
+    runner: &str,
+    service_path: &Path,
+    manifest_content: Option<&str>,
+    fs: &dyn FileSystem,
+) -> Vec<String> {
+    let mut commands = vec![install.to_string()];
+    let package_json = fs.read_to_string(&service_path.join("package.json")).ok();
+    let has_build_script = package_json
+        .as_deref()
+        .or(manifest_content)
+        .and_then(|c| serde_json::from_str::<serde_json::Value>(c).ok())
+        .is_some_and(|package| package["scripts"]["build"].is_string());
+    if has_build_script {
+        commands.push(format!("{} run build", runner));
+    }
+    commands
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    #[test]
+    fn test_node_build_commands_with_build_script() {
+        let manifest = r#"{"scripts": {"build": "tsc", "start": "node dist/index.js"}}"#;
+        assert_eq!(
+            node_build_commands(
+                "npm ci",
+                "npm",
+                Path::new("/nonexistent"),
+                Some(manifest),
+                &RealFileSystem
+            ),
+            vec!["npm ci".to_string(), "npm run build".to_string()]
+        );
+    }
+
+    #[test]
+    fn test_node_build_commands_without_build_script() {
+        let manifest = r#"{"scripts": {"start": "node index.js"}}"#;
+        assert_eq!(
+            node_build_commands(
+                "yarn install --frozen-lockfile",
+                "yarn",
+                Path::new("/nonexistent"),
+                Some(manifest),
+                &RealFileSystem
+            ),
+            vec!["yarn install --frozen-lockfile".to_string()]
+        );
+        assert_eq!(
+            node_build_commands(
+                "npm ci",
+                "npm",
+                Path::new("/nonexistent"),
+                None,
+                &RealFileSystem
+            )
+            .len(),
+            1
+        );
+    }
+
+    #[test]
+    fn test_node_build_commands_reads_package_json_next_to_lockfile() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("package.json"),
+            r#"{"scripts": {"build": "next build"}}"#,
+        )
+        .unwrap();
+
+        let commands = node_build_commands(
+            "yarn install --frozen-lockfile",
+            "yarn",
+            dir.path(),
+            Some("# yarn lockfile v1\n"),
+            &RealFileSystem,
+        );
+        assert_eq!(commands.last().unwrap(), "yarn run build");
+    }
+}
@@ -1,6 +1,8 @@
 //! npm build system (JavaScript/TypeScript)
 
-use super::node_common::{parse_node_version, read_node_version_file};
+use super::node_common::{
+    in_pnpm_workspace, node_build_commands, parse_node_version, read_node_version_file,
+};
 use super::{BuildSystem, BuildTemplate, ManifestPattern};
 use crate::{BuildSystemId, DetectionStack, LanguageId};
 use anyhow::Result;
@@ -28,11 +30,13 @@ impl BuildSystem for NpmBuildSystem {
         fs: &dyn FileSystem,
     ) -> Result<Vec<DetectionStack>> {
         let mut detections = Vec::new();
+        let pnpm_workspace = in_pnpm_workspace(file_tree);
 
         for rel_path in file_tree {
             let filename = rel_path.file_name().and_then(|n| n.to_str());
 
             let is_match = match filename {
+                Some("package.json") if pnpm_workspace => false,
                 Some("package.json") => {
                     let abs_path = repo_root.join(rel_path);
                     let content = fs.read_to_string(&abs_path).ok();
@@ -64,8 +68,9 @@ impl BuildSystem for NpmBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
-        let node_version = read_node_version_file(service_path)
+        let node_version = read_node_version_file(service_path, fs)
             .or_else(|| manifest_content.and_then(parse_node_version))
             .or_else(|| wolfi_index.get_latest_version("nodejs"))
             .expect("Failed to get nodejs version from Wolfi index");
@@ -74,7 +79,13 @@ impl BuildSystem for NpmBuildSystem {
 
         BuildTemplate {
             build_packages: vec![node_version, "npm".to_string()],
-            build_commands: vec!["npm ci".to_string()],
+            build_commands: node_build_commands(
+                "npm ci",
+                "npm",
+                service_path,
+                manifest_content,
+                fs,
+            ),
             cache_paths: vec!["node_modules/".to_string(), "/root/.npm/".to_string()],
             common_ports: vec![3000, 8080],
             build_env,
@@ -83,6 +94,7 @@ impl BuildSystem for NpmBuildSystem {
                 ("build/".to_string(), "/app/build/".to_string()),
             ],
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
@@ -130,7 +142,8 @@ impl BuildSystem for NpmBuildSystem {
         &self,
         repo_path: &std::path::Path,
         pattern: &str,
+        fs: &dyn FileSystem,
     ) -> Result<Vec<std::path::PathBuf>, anyhow::Error> {
-        super::glob_package_json_workspace_pattern(repo_path, pattern)
+        super::glob_package_json_workspace_pattern(repo_path, pattern, fs)
     }
 }
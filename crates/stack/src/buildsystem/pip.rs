@@ -1,5 +1,6 @@
 //! pip build system (Python)
 
+use super::pyproject::{PyprojectDetector, PyprojectTool};
 use super::python_common::{parse_pyproject_toml_version, read_python_version_file};
 use super::{BuildSystem, BuildTemplate, ManifestPattern};
 use crate::{BuildSystemId, DetectionStack, LanguageId};
@@ -28,6 +29,10 @@ impl BuildSystem for PipBuildSystem {
                 filename: "setup.cfg".to_string(),
                 priority: 5,
             },
+            ManifestPattern {
+                filename: "pyproject.toml".to_string(),
+                priority: 10,
+            },
         ]
     }
 
@@ -54,6 +59,11 @@ impl BuildSystem for PipBuildSystem {
                     }
                 }
                 Some("setup.py") | Some("setup.cfg") => true,
+                // Only setuptools projects; pyproject.toml also configures linters next to a
+                // requirements.txt
+                Some("pyproject.toml") => fs
+                    .read_to_string(&repo_root.join(rel_path))
+                    .is_ok_and(|c| PyprojectDetector::tool(&c) == Some(PyprojectTool::Setuptools)),
                 _ => false,
             };
 
@@ -74,8 +84,9 @@ impl BuildSystem for PipBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
-        let python_version = read_python_version_file(service_path)
+        let python_version = read_python_version_file(service_path, fs)
             .or_else(|| manifest_content.and_then(parse_pyproject_toml_version))
             .or_else(|| wolfi_index.get_latest_version("python"))
             .expect("Failed to get python version from Wolfi index");
@@ -87,15 +98,18 @@ impl BuildSystem for PipBuildSystem {
             .map(|v| format!("py{}-pip", v))
             .unwrap_or_else(|| "py3-pip".to_string());
 
+        let install_command = match manifest_content.and_then(PyprojectDetector::tool) {
+            Some(_) => "pip install --user --no-cache-dir .",
+            None => "pip install --user --no-cache-dir -r requirements.txt",
+        };
+
         BuildTemplate {
             build_packages: vec![
                 python_version.clone(),
                 pip_package,
                 "build-base".to_string(),
             ],
-            build_commands: vec![
-                "pip install --user --no-cache-dir -r requirements.txt".to_string()
-            ],
+            build_commands: vec![install_command.to_string()],
             cache_paths: vec!["/root/.cache/pip/".to_string()],
             common_ports: vec![8000, 5000],
             build_env: std::collections::HashMap::new(),
@@ -121,6 +135,7 @@ impl BuildSystem for PipBuildSystem {
                 );
                 env
             },
+            runtime_auxiliary_commands: vec![],
         }
     }
 
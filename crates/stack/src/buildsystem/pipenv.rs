@@ -47,8 +47,9 @@ impl BuildSystem for PipenvBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
-        let python_version = read_python_version_file(service_path)
+        let python_version = read_python_version_file(service_path, fs)
             .or_else(|| manifest_content.and_then(parse_pyproject_toml_version))
             .or_else(|| wolfi_index.get_latest_version("python"))
             .expect("Failed to get python version from Wolfi index");
@@ -78,6 +79,7 @@ impl BuildSystem for PipenvBuildSystem {
             build_env,
             runtime_copy: vec![],
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
@@ -1,7 +1,9 @@
 //! pnpm build system (JavaScript/TypeScript)
 
-use super::node_common::{parse_node_version, read_node_version_file};
-use super::{BuildSystem, BuildTemplate, ManifestPattern};
+use super::node_common::{
+    in_pnpm_workspace, node_build_commands, parse_node_version, read_node_version_file,
+};
+use super::{BuildSystem, BuildTemplate, ManifestPattern, NpmBuildSystem};
 use crate::{BuildSystemId, DetectionStack, LanguageId};
 use anyhow::Result;
 use peelbox_core::fs::FileSystem;
@@ -34,12 +36,14 @@ impl BuildSystem for PnpmBuildSystem {
         fs: &dyn FileSystem,
     ) -> Result<Vec<DetectionStack>> {
         let mut detections = Vec::new();
+        let pnpm_workspace = in_pnpm_workspace(file_tree);
 
         for rel_path in file_tree {
             let filename = rel_path.file_name().and_then(|n| n.to_str());
 
             let is_match = match filename {
                 Some("pnpm-lock.yaml") => true,
+                Some("package.json") if pnpm_workspace => true,
                 Some("package.json") => {
                     let abs_path = repo_root.join(rel_path);
                     let content = fs.read_to_string(&abs_path).ok();
@@ -69,8 +73,9 @@ impl BuildSystem for PnpmBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
-        let node_version = read_node_version_file(service_path)
+        let node_version = read_node_version_file(service_path, fs)
             .or_else(|| manifest_content.and_then(parse_node_version))
             .or_else(|| wolfi_index.get_latest_version("nodejs"))
             .expect("Failed to get nodejs version from Wolfi index");
@@ -79,7 +84,13 @@ impl BuildSystem for PnpmBuildSystem {
 
         BuildTemplate {
             build_packages: vec![node_version.clone(), "pnpm".to_string()],
-            build_commands: vec!["pnpm install --frozen-lockfile".to_string()],
+            build_commands: node_build_commands(
+                "pnpm install --frozen-lockfile",
+                "pnpm",
+                service_path,
+                manifest_content,
+                fs,
+            ),
             cache_paths: vec!["node_modules/".to_string(), ".pnpm-store/".to_string()],
             common_ports: vec![3000, 8080],
             build_env,
@@ -88,12 +99,18 @@ impl BuildSystem for PnpmBuildSystem {
                 ("build/".to_string(), "/app/build/".to_string()),
             ],
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
     fn cache_dirs(&self) -> Vec<String> {
         vec!["node_modules".to_string(), ".pnpm-store".to_string()]
     }
+
+    fn parse_package_metadata(&self, manifest_content: &str) -> Result<(String, bool)> {
+        NpmBuildSystem.parse_package_metadata(manifest_content)
+    }
+
     fn is_workspace_root(&self, manifest_content: Option<&str>) -> bool {
         if let Some(content) = manifest_content {
             content.contains("\"workspaces\"")
@@ -114,7 +131,49 @@ impl BuildSystem for PnpmBuildSystem {
         &self,
         repo_path: &std::path::Path,
         pattern: &str,
+        fs: &dyn FileSystem,
     ) -> Result<Vec<std::path::PathBuf>> {
-        super::glob_package_json_workspace_pattern(repo_path, pattern)
+        super::glob_package_json_workspace_pattern(repo_path, pattern, fs)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::MockFileSystem;
+
+    #[test]
+    fn test_workspace_packages_belong_to_pnpm() {
+        let fs = MockFileSystem::new();
+        fs.add_file("package.json", r#"{"private": true}"#);
+        fs.add_file("apps/api/package.json", r#"{"name": "@shop/api"}"#);
+        let tree: Vec<PathBuf> = [
+            "package.json",
+            "pnpm-workspace.yaml",
+            "apps/api/package.json",
+        ]
+        .iter()
+        .map(PathBuf::from)
+        .collect();
+
+        let pnpm = PnpmBuildSystem
+            .detect_all(Path::new(""), &tree, &fs)
+            .unwrap();
+        assert_eq!(pnpm.len(), 2);
+        let npm = NpmBuildSystem
+            .detect_all(Path::new(""), &tree, &fs)
+            .unwrap();
+        assert!(npm.is_empty());
+    }
+
+    #[test]
+    fn test_package_metadata() {
+        let (name, is_application) = PnpmBuildSystem
+            .parse_package_metadata(
+                r#"{"name": "@shop/api", "scripts": {"start": "node dist/index.js"}}"#,
+            )
+            .unwrap();
+        assert_eq!(name, "@shop/api");
+        assert!(is_application);
     }
 }
@@ -1,5 +1,6 @@
 //! Poetry build system (Python)
 
+use super::pyproject::PyprojectDetector;
 use super::python_common::{parse_pyproject_toml_version, read_python_version_file};
 use super::{BuildSystem, BuildTemplate, ManifestPattern};
 use crate::{BuildSystemId, DetectionStack, LanguageId};
@@ -34,11 +35,10 @@ impl BuildSystem for PoetryBuildSystem {
                 let abs_path = repo_root.join(rel_path);
                 let content = fs.read_to_string(&abs_path).ok();
 
-                let is_valid = if let Some(c) = content.as_deref() {
-                    c.contains("[tool.poetry]")
-                } else {
-                    true
-                };
+                let is_valid = content.as_deref().map_or(true, |c| {
+                    PyprojectDetector::tool(c).map(|tool| tool.build_system())
+                        == Some(BuildSystemId::Poetry)
+                });
 
                 if is_valid {
                     detections.push(DetectionStack::new(
@@ -58,8 +58,9 @@ impl BuildSystem for PoetryBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
-        let python_version = read_python_version_file(service_path)
+        let python_version = read_python_version_file(service_path, fs)
             .or_else(|| manifest_content.and_then(parse_pyproject_toml_version))
             .or_else(|| wolfi_index.get_latest_version("python"))
             .expect("Failed to get python version from Wolfi index");
@@ -108,6 +109,7 @@ impl BuildSystem for PoetryBuildSystem {
                 );
                 env
             },
+            runtime_auxiliary_commands: vec![],
         }
     }
 
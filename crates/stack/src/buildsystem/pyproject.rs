@@ -0,0 +1,260 @@
+//! pyproject.toml reader - the tool managing a Python project and the scripts it declares
+
+use crate::BuildSystemId;
+use peelbox_core::output::schema::PythonMetadata;
+use std::collections::BTreeMap;
+
+/// A tool that builds or manages a pyproject.toml project
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum PyprojectTool {
+    Poetry,
+    Pdm,
+    Hatch,
+    Setuptools,
+}
+
+/// Each tool with the top-level package of its `build-backend` and the `requires` packages
+/// providing it
+const BACKENDS: [(PyprojectTool, &str, &[&str]); 4] = [
+    (PyprojectTool::Poetry, "poetry", &["poetry-core", "poetry"]),
+    (PyprojectTool::Pdm, "pdm", &["pdm-backend", "pdm-pep517"]),
+    (PyprojectTool::Hatch, "hatchling", &["hatchling"]),
+    (PyprojectTool::Setuptools, "setuptools", &["setuptools"]),
+];
+
+/// `[tool.<name>]` sections that claim a project whatever its build backend
+const TOOL_SECTIONS: [(PyprojectTool, &str); 3] = [
+    (PyprojectTool::Poetry, "poetry"),
+    (PyprojectTool::Pdm, "pdm"),
+    (PyprojectTool::Hatch, "hatch"),
+];
+
+impl PyprojectTool {
+    pub fn name(self) -> &'static str {
+        match self {
+            PyprojectTool::Poetry => "Poetry",
+            PyprojectTool::Pdm => "PDM",
+            PyprojectTool::Hatch => "Hatch",
+            PyprojectTool::Setuptools => "setuptools",
+        }
+    }
+
+    /// setuptools projects install with pip
+    pub fn build_system(self) -> BuildSystemId {
+        match self {
+            PyprojectTool::Poetry => BuildSystemId::Poetry,
+            PyprojectTool::Pdm => BuildSystemId::Pdm,
+            PyprojectTool::Hatch => BuildSystemId::Hatch,
+            PyprojectTool::Setuptools => BuildSystemId::Pip,
+        }
+    }
+
+    pub fn install_command(self) -> &'static str {
+        match self {
+            PyprojectTool::Poetry => "poetry install",
+            PyprojectTool::Pdm => "pdm install",
+            PyprojectTool::Hatch => "hatch env create",
+            PyprojectTool::Setuptools => "pip install .",
+        }
+    }
+
+    /// Runs a console script inside the tool's environment; pip installs scripts onto PATH
+    pub fn run_command(self, script: &str) -> String {
+        match self {
+            PyprojectTool::Poetry => format!("poetry run {}", script),
+            PyprojectTool::Pdm => format!("pdm run {}", script),
+            PyprojectTool::Hatch => format!("hatch run {}", script),
+            PyprojectTool::Setuptools => script.to_string(),
+        }
+    }
+}
+
+pub struct PyprojectDetector;
+
+impl PyprojectDetector {
+    /// Tool behind `[build-system].build-backend`, else the first known `requires` package
+    pub fn backend(content: &str) -> Option<PyprojectTool> {
+        let pyproject: toml::Value = toml::from_str(content).ok()?;
+        let build_system = pyproject.get("build-system")?;
+
+        if let Some(backend) = build_system.get("build-backend").and_then(|b| b.as_str()) {
+            // poetry.core.masonry.api, pdm.backend, hatchling.build, setuptools.build_meta
+            let package = backend.split('.').next().unwrap_or_default();
+            let tool = BACKENDS.iter().find(|(_, root, _)| *root == package);
+            if let Some((tool, _, _)) = tool {
+                return Some(*tool);
+            }
+        }
+
+        let requires: Vec<String> = build_system
+            .get("requires")
+            .and_then(|r| r.as_array())
+            .into_iter()
+            .flatten()
+            .filter_map(|r| r.as_str())
+            .map(requirement_name)
+            .collect();
+        BACKENDS
+            .iter()
+            .find(|(_, _, packages)| packages.iter().any(|p| requires.iter().any(|r| r == p)))
+            .map(|(tool, _, _)| *tool)
+    }
+
+    /// Tool managing the project: a `[tool.poetry]`, `[tool.pdm]` or `[tool.hatch]` section,
+    /// else the build backend
+    pub fn tool(content: &str) -> Option<PyprojectTool> {
+        let Ok(pyproject) = toml::from_str::<toml::Value>(content) else {
+            // Still recognise the sections of a file toml rejects
+            return TOOL_SECTIONS
+                .iter()
+                .find(|(_, section)| content.contains(&format!("[tool.{}", section)))
+                .map(|(tool, _)| *tool);
+        };
+        let sections = pyproject.get("tool");
+        TOOL_SECTIONS
+            .iter()
+            .find(|(_, section)| sections.and_then(|tools| tools.get(section)).is_some())
+            .map(|(tool, _)| *tool)
+            .or_else(|| Self::backend(content))
+    }
+
+    /// Build backend, install command and console scripts; `None` for a pyproject.toml that
+    /// declares none of them, or for another manifest
+    pub fn inspect(content: &str) -> Option<PythonMetadata> {
+        let pyproject: toml::Value = toml::from_str(content).ok()?;
+        let tool = Self::tool(content);
+
+        let scripts: BTreeMap<String, String> = pyproject
+            .get("project")
+            .and_then(|project| project.get("scripts"))
+            .or_else(|| pyproject.get("tool")?.get("poetry")?.get("scripts"))
+            .and_then(|scripts| scripts.as_table())
+            .into_iter()
+            .flatten()
+            .filter_map(|(name, target)| Some((name.clone(), target.as_str()?.to_string())))
+            .collect();
+
+        let metadata = PythonMetadata {
+            build_backend: Self::backend(content).map(|backend| backend.name().to_string()),
+            install_command: tool.map(|tool| tool.install_command().to_string()),
+            run_commands: scripts
+                .keys()
+                .map(|script| match tool {
+                    Some(tool) => tool.run_command(script),
+                    None => script.clone(),
+                })
+                .collect(),
+            scripts,
+            ..PythonMetadata::default()
+        };
+        (metadata != PythonMetadata::default()).then_some(metadata)
+    }
+}
+
+/// `hatchling>=1.18` → `hatchling`, normalised the way PyPI compares names
+fn requirement_name(requirement: &str) -> String {
+    requirement
+        .split(|c: char| !(c.is_ascii_alphanumeric() || c == '-' || c == '_' || c == '.'))
+        .next()
+        .unwrap_or_default()
+        .to_lowercase()
+        .replace(['_', '.'], "-")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const PDM: &str = r#"[project]
+name = "ledger"
+version = "0.1.0"
+requires-python = ">=3.12"
+dependencies = ["fastapi>=0.110", "uvicorn[standard]>=0.29"]
+
+[project.scripts]
+ledger = "ledger.main:run"
+
+[build-system]
+requires = ["pdm-backend"]
+build-backend = "pdm.backend"
+"#;
+
+    #[test]
+    fn test_backends() {
+        let backend = |requires: &str, module: &str| {
+            PyprojectDetector::backend(&format!(
+                "[build-system]\nrequires = [{}]\nbuild-backend = \"{}\"\n",
+                requires, module
+            ))
+        };
+        assert_eq!(
+            backend("\"poetry-core\"", "poetry.core.masonry.api"),
+            Some(PyprojectTool::Poetry)
+        );
+        assert_eq!(
+            backend("\"pdm-backend\"", "pdm.backend"),
+            Some(PyprojectTool::Pdm)
+        );
+        assert_eq!(
+            backend("\"hatchling>=1.18\", \"hatch-vcs\"", "hatchling.build"),
+            Some(PyprojectTool::Hatch)
+        );
+        assert_eq!(
+            backend("\"setuptools>=61\", \"wheel\"", "setuptools.build_meta"),
+            Some(PyprojectTool::Setuptools)
+        );
+        assert_eq!(
+            backend("\"Hatchling\"", "custom_backend"),
+            Some(PyprojectTool::Hatch)
+        );
+        assert_eq!(backend("\"flit_core\"", "flit_core.buildapi"), None);
+        assert_eq!(
+            PyprojectDetector::backend("[project]\nname = \"app\"\n"),
+            None
+        );
+    }
+
+    #[test]
+    fn test_tool_section_wins_over_backend() {
+        let content = "[tool.pdm]\ndistribution = true\n\n[build-system]\nrequires = [\"setuptools\"]\nbuild-backend = \"setuptools.build_meta\"\n";
+        assert_eq!(PyprojectDetector::tool(content), Some(PyprojectTool::Pdm));
+        assert_eq!(
+            PyprojectDetector::tool("[tool.hatch.envs.default]\n"),
+            Some(PyprojectTool::Hatch)
+        );
+        assert_eq!(
+            PyprojectDetector::tool("[tool.poetry\nname = "),
+            Some(PyprojectTool::Poetry)
+        );
+        assert_eq!(PyprojectDetector::tool(PDM), Some(PyprojectTool::Pdm));
+    }
+
+    #[test]
+    fn test_inspect_pdm_project() {
+        let metadata = PyprojectDetector::inspect(PDM).unwrap();
+        assert_eq!(metadata.build_backend.as_deref(), Some("PDM"));
+        assert_eq!(metadata.install_command.as_deref(), Some("pdm install"));
+        assert_eq!(
+            metadata.scripts,
+            BTreeMap::from([("ledger".to_string(), "ledger.main:run".to_string())])
+        );
+        assert_eq!(metadata.run_commands, vec!["pdm run ledger"]);
+    }
+
+    #[test]
+    fn test_inspect_poetry_scripts_and_plain_manifests() {
+        let metadata = PyprojectDetector::inspect(
+            "[tool.poetry]\nname = \"app\"\n\n[tool.poetry.scripts]\nserve = \"app.cli:serve\"\n",
+        )
+        .unwrap();
+        assert_eq!(metadata.build_backend, None);
+        assert_eq!(metadata.install_command.as_deref(), Some("poetry install"));
+        assert_eq!(metadata.run_commands, vec!["poetry run serve"]);
+
+        assert_eq!(
+            PyprojectDetector::inspect("[project]\nname = \"app\"\n"),
+            None
+        );
+        assert_eq!(PyprojectDetector::inspect("flask==3.0.0\n"), None);
+    }
+}
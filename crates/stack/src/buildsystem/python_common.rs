@@ -1,3 +1,4 @@
+use peelbox_core::fs::FileSystem;
 use std::path::Path;
 
 fn normalize_python_version(version_str: &str) -> Option<String> {
@@ -20,16 +21,16 @@ fn normalize_python_version(version_str: &str) -> Option<String> {
     }
 }
 
-pub(super) fn read_python_version_file(service_path: &Path) -> Option<String> {
+pub(super) fn read_python_version_file(service_path: &Path, fs: &dyn FileSystem) -> Option<String> {
     let runtime_txt = service_path.join("runtime.txt");
-    if let Ok(content) = std::fs::read_to_string(&runtime_txt) {
+    if let Ok(content) = fs.read_to_string(&runtime_txt) {
         if !content.trim().is_empty() {
             return normalize_python_version(&content);
         }
     }
 
     let python_version = service_path.join(".python-version");
-    if let Ok(content) = std::fs::read_to_string(&python_version) {
+    if let Ok(content) = fs.read_to_string(&python_version) {
         if !content.trim().is_empty() {
             return normalize_python_version(&content);
         }
@@ -1,3 +1,5 @@
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
 use std::path::Path;
 
 fn normalize_ruby_version(version_str: &str) -> Option<String> {
@@ -17,9 +19,9 @@ fn normalize_ruby_version(version_str: &str) -> Option<String> {
     }
 }
 
-pub(super) fn read_ruby_version_file(service_path: &Path) -> Option<String> {
+pub(super) fn read_ruby_version_file(service_path: &Path, fs: &dyn FileSystem) -> Option<String> {
     let ruby_version_file = service_path.join(".ruby-version");
-    if let Ok(content) = std::fs::read_to_string(&ruby_version_file) {
+    if let Ok(content) = fs.read_to_string(&ruby_version_file) {
         if !content.trim().is_empty() {
             return normalize_ruby_version(&content);
         }
@@ -28,14 +30,41 @@ pub(super) fn read_ruby_version_file(service_path: &Path) -> Option<String> {
 }
 
 pub(super) fn parse_gemfile_version(manifest_content: &str) -> Option<String> {
-    for line in manifest_content.lines() {
-        let trimmed = line.trim();
-        if trimmed.starts_with("ruby") && trimmed.contains('"') {
-            let parts: Vec<&str> = trimmed.split('"').collect();
-            if parts.len() >= 2 {
-                return normalize_ruby_version(parts[1]);
-            }
-        }
+    // ruby "3.3.0" or ruby '3.3.0' (ruby file: ".ruby-version" is covered by the file lookup)
+    let ruby_re = Regex::new(r#"(?m)^\s*ruby\s+["']([^"']+)["']"#).ok()?;
+    ruby_re
+        .captures(manifest_content)
+        .and_then(|caps| normalize_ruby_version(&caps[1]))
+}
+
+/// Whether the Gemfile declares `gem "<name>"` (ignoring commented-out lines)
+pub(super) fn gemfile_declares(manifest_content: &str, gem: &str) -> bool {
+    let pattern = format!(r#"(?m)^\s*gem\s+["']{}["']"#, regex::escape(gem));
+    Regex::new(&pattern).is_ok_and(|re| re.is_match(manifest_content))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_parse_gemfile_version_quotes() {
+        assert_eq!(
+            parse_gemfile_version("source \"https://rubygems.org\"\nruby \"3.3.4\"\n"),
+            Some("ruby-3.3".to_string())
+        );
+        assert_eq!(
+            parse_gemfile_version("ruby '3.2.2'\ngem 'sinatra'\n"),
+            Some("ruby-3.2".to_string())
+        );
+        assert_eq!(parse_gemfile_version("gem 'ruby-progressbar'\n"), None);
+    }
+
+    #[test]
+    fn test_gemfile_declares() {
+        let gemfile = "gem \"rails\", \"~> 7.1.3\"\n# gem 'sinatra'\ngem 'rails-html-sanitizer'\n";
+        assert!(gemfile_declares(gemfile, "rails"));
+        assert!(!gemfile_declares(gemfile, "sinatra"));
+        assert!(!gemfile_declares(gemfile, "rack"));
     }
-    None
 }
@@ -0,0 +1,274 @@
+//! sbt build system (Scala)
+
+use super::{BuildSystem, BuildTemplate, ManifestPattern};
+use crate::{BuildSystemId, DetectionStack, LanguageId};
+use anyhow::Result;
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+pub struct SbtBuildSystem;
+
+impl BuildSystem for SbtBuildSystem {
+    fn id(&self) -> BuildSystemId {
+        BuildSystemId::Sbt
+    }
+
+    fn manifest_patterns(&self) -> Vec<ManifestPattern> {
+        vec![ManifestPattern {
+            filename: "build.sbt".to_string(),
+            priority: 10,
+        }]
+    }
+
+    fn detect_all(
+        &self,
+        _repo_root: &Path,
+        file_tree: &[PathBuf],
+        _fs: &dyn FileSystem,
+    ) -> Result<Vec<DetectionStack>> {
+        Ok(file_tree
+            .iter()
+            .filter(|path| path.file_name().and_then(|n| n.to_str()) == Some("build.sbt"))
+            // project/ holds the meta-build, not a service
+            .filter(|path| {
+                !path
+                    .parent()
+                    .and_then(|p| p.file_name())
+                    .is_some_and(|dir| dir == "project")
+            })
+            .map(|path| DetectionStack::new(BuildSystemId::Sbt, LanguageId::Scala, path.clone()))
+            .collect())
+    }
+
+    fn build_template(
+        &self,
+        wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> BuildTemplate {
+        let java_version = wolfi_index
+            .get_latest_version("openjdk")
+            .expect("Failed to get openjdk version from Wolfi index");
+        let java_home = format!(
+            "/usr/lib/jvm/java-{}-openjdk",
+            java_version.trim_start_matches("openjdk-")
+        );
+
+        let mut build_env = std::collections::HashMap::new();
+        build_env.insert("JAVA_HOME".to_string(), java_home);
+        build_env.insert(
+            "SBT_OPTS".to_string(),
+            "-Dsbt.global.base=/root/.sbt -Dsbt.ivy.home=/root/.ivy2".to_string(),
+        );
+
+        let build = manifest_content
+            .map(|content| SbtBuild::parse(content).with_plugins(service_path, fs))
+            .unwrap_or_default();
+        let (build_commands, runtime_copy) = match build.packaging() {
+            Packaging::Stage => (
+                vec!["sbt -batch clean stage".to_string()],
+                vec![("target/universal/stage/".to_string(), "/app/".to_string())],
+            ),
+            Packaging::Assembly => (
+                vec!["sbt -batch clean assembly".to_string()],
+                vec![(
+                    "target/scala-*/*-assembly-*.jar".to_string(),
+                    "/app/".to_string(),
+                )],
+            ),
+            Packaging::Jar => (
+                vec!["sbt -batch clean package".to_string()],
+                vec![("target/scala-*/*.jar".to_string(), "/app/".to_string())],
+            ),
+        };
+
+        BuildTemplate {
+            build_packages: vec![java_version, "sbt".to_string()],
+            build_commands,
+            cache_paths: vec![
+                "/root/.sbt/".to_string(),
+                "/root/.ivy2/cache/".to_string(),
+                "/root/.cache/coursier/".to_string(),
+            ],
+            common_ports: vec![8080, 9000],
+            build_env,
+            runtime_copy,
+            runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
+        }
+    }
+
+    fn cache_dirs(&self) -> Vec<String> {
+        vec!["target".to_string(), "project/target".to_string()]
+    }
+
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let build = SbtBuild::parse(manifest_content?).with_plugins(service_path, fs);
+        let name = build.normalized_name();
+
+        match build.packaging() {
+            // Play writes a RUNNING_PID file that blocks restarts of the same container
+            Packaging::Stage if build.play => {
+                Some(format!("/app/bin/{} -Dpidfile.path=/dev/null", name))
+            }
+            Packaging::Stage => Some(format!("/app/bin/{}", name)),
+            Packaging::Assembly => Some(format!(
+                "java -jar /app/{}-assembly-{}.jar",
+                name,
+                build.version.as_deref().unwrap_or("0.1.0-SNAPSHOT")
+            )),
+            Packaging::Jar => None,
+        }
+    }
+
+    fn is_workspace_root(&self, manifest_content: Option<&str>) -> bool {
+        manifest_content.is_some_and(|content| content.contains(".aggregate("))
+    }
+
+    fn parse_package_metadata(
+        &self,
+        manifest_content: &str,
+    ) -> Result<(String, bool), anyhow::Error> {
+        let build = SbtBuild::parse(manifest_content);
+        match build.name {
+            Some(_) => Ok((build.normalized_name(), true)),
+            None => Err(anyhow::anyhow!("build.sbt does not set a project name")),
+        }
+    }
+}
+
+#[derive(Debug, PartialEq)]
+enum Packaging {
+    /// sbt-native-packager's `stage` (Play and `JavaAppPackaging`)
+    Stage,
+    /// sbt-assembly fat jar
+    Assembly,
+    /// Plain `package` jar without its dependencies
+    Jar,
+}
+
+/// Facts read from build.sbt and project/plugins.sbt that decide how the app is packaged
+#[derive(Debug, Default)]
+struct SbtBuild {
+    name: Option<String>,
+    version: Option<String>,
+    play: bool,
+    native_packager: bool,
+    assembly: bool,
+}
+
+impl SbtBuild {
+    fn parse(content: &str) -> Self {
+        // name := "orders" or name := """orders"""
+        let name_re = Regex::new(r#"(?m)^\s*name\s*:=\s*"+([^"]+)"+"#).expect("valid regex");
+        let version_re = Regex::new(r#"(?m)^\s*(?:ThisBuild\s*/\s*)?version\s*:=\s*"([^"]+)""#)
+            .expect("valid regex");
+        let packager_re =
+            Regex::new(r"enablePlugins\([^)]*\b(JavaAppPackaging|JavaServerAppPackaging)\b")
+                .expect("valid regex");
+
+        Self {
+            name: name_re.captures(content).map(|c| c[1].to_string()),
+            version: version_re.captures(content).map(|c| c[1].to_string()),
+            play: Regex::new(r"enablePlugins\([^)]*\bPlay(Scala|Java)\b")
+                .expect("valid regex")
+                .is_match(content),
+            native_packager: packager_re.is_match(content),
+            assembly: false,
+        }
+    }
+
+    /// Plugins live in the meta-build's project/plugins.sbt, next to build.sbt
+    fn with_plugins(mut self, service_path: &Path, fs: &dyn FileSystem) -> Self {
+        self.assembly = fs
+            .read_to_string(&service_path.join("project/plugins.sbt"))
+            .is_ok_and(|plugins| plugins.contains("sbt-assembly"));
+        self
+    }
+
+    fn packaging(&self) -> Packaging {
+        if self.play || self.native_packager {
+            Packaging::Stage
+        } else if self.assembly {
+            Packaging::Assembly
+        } else {
+            Packaging::Jar
+        }
+    }
+
+    /// sbt's normalizedName, which names the jar and the staged start script
+    fn normalized_name(&self) -> String {
+        self.name
+            .as_deref()
+            .unwrap_or("root")
+            .to_lowercase()
+            .replace(' ', "-")
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    #[test]
+    fn test_play_stages_start_script() {
+        let build = "name := \"\"\"Store Front\"\"\"\nlazy val root = (project in file(\".\")).enablePlugins(PlayScala)\n";
+        assert_eq!(
+            SbtBuildSystem.runtime_command(Path::new("."), Some(build), &RealFileSystem),
+            Some("/app/bin/store-front -Dpidfile.path=/dev/null".to_string())
+        );
+
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = SbtBuildSystem.build_template(
+            &wolfi_index,
+            Path::new("."),
+            Some(build),
+            &RealFileSystem,
+        );
+        assert_eq!(template.build_commands, vec!["sbt -batch clean stage"]);
+        assert_eq!(template.build_packages[1], "sbt");
+    }
+
+    #[test]
+    fn test_assembly_plugin_builds_fat_jar() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::create_dir(dir.path().join("project")).unwrap();
+        std::fs::write(
+            dir.path().join("project/plugins.sbt"),
+            "addSbtPlugin(\"com.eed3si9n\" % \"sbt-assembly\" % \"2.2.0\")\n",
+        )
+        .unwrap();
+        let build = "name := \"greeter\"\nversion := \"0.2.0\"\n";
+
+        assert_eq!(
+            SbtBuildSystem.runtime_command(dir.path(), Some(build), &RealFileSystem),
+            Some("java -jar /app/greeter-assembly-0.2.0.jar".to_string())
+        );
+        assert_eq!(
+            SbtBuildSystem.runtime_command(Path::new("."), Some(build), &RealFileSystem),
+            None
+        );
+    }
+
+    #[test]
+    fn test_meta_build_is_not_a_service() {
+        let tree = vec![
+            PathBuf::from("build.sbt"),
+            PathBuf::from("project/build.sbt"),
+        ];
+        let fs = peelbox_core::fs::MockFileSystem::new();
+        let detections = SbtBuildSystem
+            .detect_all(Path::new(""), &tree, &fs)
+            .unwrap();
+        assert_eq!(detections.len(), 1);
+        assert_eq!(detections[0].manifest_path, PathBuf::from("build.sbt"));
+    }
+}
@@ -0,0 +1,205 @@
+//! Stack build system (Haskell)
+
+use super::cabal::{haskell_build_packages, HaskellPackage};
+use super::{BuildSystem, BuildTemplate, ManifestPattern};
+use crate::{BuildSystemId, DetectionStack, LanguageId};
+use anyhow::Result;
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::HaskellExecutable;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+pub struct StackBuildSystem;
+
+impl BuildSystem for StackBuildSystem {
+    fn id(&self) -> BuildSystemId {
+        BuildSystemId::Stack
+    }
+
+    fn manifest_patterns(&self) -> Vec<ManifestPattern> {
+        vec![ManifestPattern {
+            filename: "stack.yaml".to_string(),
+            priority: 10,
+        }]
+    }
+
+    fn detect_all(
+        &self,
+        _repo_root: &Path,
+        file_tree: &[PathBuf],
+        _fs: &dyn FileSystem,
+    ) -> Result<Vec<DetectionStack>> {
+        Ok(file_tree
+            .iter()
+            .filter(|path| path.file_name().and_then(|n| n.to_str()) == Some("stack.yaml"))
+            .map(|path| {
+                DetectionStack::new(BuildSystemId::Stack, LanguageId::Haskell, path.clone())
+            })
+            .collect())
+    }
+
+    fn build_template(
+        &self,
+        _wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> BuildTemplate {
+        let project = StackProject::parse(manifest_content.unwrap_or_default());
+
+        // Wolfi does not package Stack or GHC; the installer fetches Stack, which then
+        // installs the GHC the resolver pins
+        let mut build_commands = vec!["curl -sSL https://get.haskellstack.org/ | sh".to_string()];
+        let runtime_copy = match project.executables(service_path, fs).first() {
+            Some(exe) => {
+                build_commands.push(
+                    "stack build --install-ghc --copy-bins --local-bin-path dist".to_string(),
+                );
+                vec![(
+                    format!("dist/{}", exe.name),
+                    format!("/usr/local/bin/{}", exe.name),
+                )]
+            }
+            None => {
+                build_commands.push("stack build --install-ghc".to_string());
+                vec![]
+            }
+        };
+
+        BuildTemplate {
+            build_packages: haskell_build_packages(),
+            build_commands,
+            cache_paths: vec!["/root/.stack/".to_string(), ".stack-work/".to_string()],
+            common_ports: vec![3000, 8080],
+            build_env: std::collections::HashMap::new(),
+            runtime_copy,
+            runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
+        }
+    }
+
+    fn cache_dirs(&self) -> Vec<String> {
+        vec![".stack-work".to_string()]
+    }
+
+    fn runtime_command(
+        &self,
+        service_path: &Path,
+        manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let project = StackProject::parse(manifest_content?);
+        let exe = project.executables(service_path, fs).into_iter().next()?;
+        Some(format!("/usr/local/bin/{}", exe.name))
+    }
+
+    fn parse_package_metadata(
+        &self,
+        _manifest_content: &str,
+    ) -> Result<(String, bool), anyhow::Error> {
+        // The name lives in the package description next to stack.yaml
+        Err(anyhow::anyhow!("stack.yaml does not name the project"))
+    }
+}
+
+/// Facts read from stack.yaml
+#[derive(Debug, Default, PartialEq)]
+pub struct StackProject {
+    /// `resolver` (or its newer spelling `snapshot`), e.g. `lts-22.7`
+    pub resolver: Option<String>,
+    /// Package directories; Stack builds the project root when the list is absent
+    pub packages: Vec<String>,
+}
+
+impl StackProject {
+    pub fn parse(content: &str) -> Self {
+        let resolver_re =
+            Regex::new(r#"(?m)^(?:resolver|snapshot):\s*['"]?([^'"\s#]+)"#).expect("valid regex");
+
+        let mut packages = Vec::new();
+        let mut in_packages = false;
+        for line in content.lines() {
+            let trimmed = line.trim();
+            if trimmed.is_empty() || trimmed.starts_with('#') {
+                continue;
+            }
+            if !line.starts_with(' ') && !trimmed.starts_with('-') {
+                in_packages = trimmed == "packages:";
+                continue;
+            }
+            if in_packages {
+                if let Some(item) = trimmed.strip_prefix('-') {
+                    packages.push(
+                        item.trim()
+                            .trim_matches(|c| c == '"' || c == '\'')
+                            .to_string(),
+                    );
+                }
+            }
+        }
+        if packages.is_empty() {
+            packages.push(".".to_string());
+        }
+
+        Self {
+            resolver: resolver_re
+                .captures(content)
+                .map(|caps| caps[1].to_string()),
+            packages,
+        }
+    }
+
+    /// Executables of every listed package, in stack.yaml order
+    pub fn executables(&self, service_path: &Path, fs: &dyn FileSystem) -> Vec<HaskellExecutable> {
+        self.packages
+            .iter()
+            .filter_map(|dir| HaskellPackage::read(&service_path.join(dir), fs))
+            .flat_map(|package| package.executables)
+            .collect()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    #[test]
+    fn test_parse_stack_yaml() {
+        let project = StackProject::parse(
+            "resolver: lts-22.7\n\npackages:\n- api\n- 'worker'\n\nextra-deps:\n- servant-0.20.1\n",
+        );
+        assert_eq!(project.resolver.as_deref(), Some("lts-22.7"));
+        assert_eq!(project.packages, vec!["api", "worker"]);
+
+        let project = StackProject::parse("snapshot: lts-22.7 # GHC 9.6.4\n");
+        assert_eq!(project.resolver.as_deref(), Some("lts-22.7"));
+        assert_eq!(project.packages, vec!["."]);
+    }
+
+    #[test]
+    fn test_copies_executable_from_package_yaml() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("package.yaml"),
+            "name: catalog\nexecutables:\n  catalog-exe:\n    main: Main.hs\n",
+        )
+        .unwrap();
+
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let manifest = Some("resolver: lts-22.7\n");
+        let template =
+            StackBuildSystem.build_template(&wolfi_index, dir.path(), manifest, &RealFileSystem);
+        assert_eq!(
+            template.runtime_copy,
+            vec![(
+                "dist/catalog-exe".to_string(),
+                "/usr/local/bin/catalog-exe".to_string()
+            )]
+        );
+        assert_eq!(
+            StackBuildSystem.runtime_command(dir.path(), manifest, &RealFileSystem),
+            Some("/usr/local/bin/catalog-exe".to_string())
+        );
+    }
+}
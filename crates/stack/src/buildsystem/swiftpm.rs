@@ -0,0 +1,285 @@
+//! Swift Package Manager build system (Swift)
+
+use super::{BuildSystem, BuildTemplate, ManifestPattern};
+use crate::{BuildSystemId, DetectionStack, LanguageId};
+use anyhow::Result;
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::path::{Path, PathBuf};
+
+pub struct SwiftPmBuildSystem;
+
+impl BuildSystem for SwiftPmBuildSystem {
+    fn id(&self) -> BuildSystemId {
+        BuildSystemId::SwiftPm
+    }
+
+    fn manifest_patterns(&self) -> Vec<ManifestPattern> {
+        vec![ManifestPattern {
+            filename: "Package.swift".to_string(),
+            priority: 10,
+        }]
+    }
+
+    fn detect_all(
+        &self,
+        repo_root: &Path,
+        file_tree: &[PathBuf],
+        fs: &dyn FileSystem,
+    ) -> Result<Vec<DetectionStack>> {
+        let mut detections = Vec::new();
+
+        for rel_path in file_tree {
+            if rel_path.file_name().and_then(|n| n.to_str()) == Some("Package.swift") {
+                let abs_path = repo_root.join(rel_path);
+                let content = fs.read_to_string(&abs_path).ok();
+
+                let is_valid = if let Some(c) = content.as_deref() {
+                    c.contains("Package(")
+                } else {
+                    true
+                };
+
+                if is_valid {
+                    detections.push(DetectionStack::new(
+                        BuildSystemId::SwiftPm,
+                        LanguageId::Swift,
+                        rel_path.clone(),
+                    ));
+                }
+            }
+        }
+
+        Ok(detections)
+    }
+
+    fn build_template(
+        &self,
+        wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        _service_path: &Path,
+        manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
+    ) -> BuildTemplate {
+        let package = manifest_content
+            .map(SwiftPackage::parse)
+            .unwrap_or_default();
+
+        // Prefer the toolchain matching swift-tools-version when Wolfi carries it
+        let swift_package = package
+            .tools_version
+            .as_ref()
+            .map(|version| format!("swift-{}", version))
+            .filter(|name| wolfi_index.has_package(name))
+            .or_else(|| wolfi_index.get_latest_version("swift"))
+            .unwrap_or_else(|| "swift".to_string());
+
+        let build_packages = vec![
+            swift_package,
+            "build-base".to_string(),
+            "libstdc++".to_string(),
+        ];
+
+        let (build_commands, runtime_copy) = match package.executable() {
+            // Static stdlib keeps the binary runnable without a Swift toolchain at runtime
+            Some(binary) => (
+                vec!["swift build -c release --static-swift-stdlib".to_string()],
+                vec![(
+                    format!(".build/release/{}", binary),
+                    format!("/usr/local/bin/{}", binary),
+                )],
+            ),
+            // Library packages only need to compile
+            None => (vec!["swift build -c release".to_string()], vec![]),
+        };
+
+        BuildTemplate {
+            build_packages,
+            build_commands,
+            cache_paths: vec![".build/".to_string()],
+            common_ports: vec![8080],
+            build_env: std::collections::HashMap::new(),
+            runtime_copy,
+            runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
+        }
+    }
+
+    fn cache_dirs(&self) -> Vec<String> {
+        vec![".build".to_string()]
+    }
+
+    fn runtime_command(
+        &self,
+        _service_path: &Path,
+        manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
+    ) -> Option<String> {
+        let package = SwiftPackage::parse(manifest_content?);
+        let binary = format!("/usr/local/bin/{}", package.executable()?);
+
+        // Vapor's and Hummingbird's templates bind to localhost unless told otherwise
+        let command = if package.depends_on("vapor/vapor") {
+            format!("{} serve --hostname 0.0.0.0 --port 8080", binary)
+        } else if package.depends_on("hummingbird-project/hummingbird") {
+            format!("{} --hostname 0.0.0.0 --port 8080", binary)
+        } else {
+            binary
+        };
+        Some(command)
+    }
+
+    fn parse_package_metadata(
+        &self,
+        manifest_content: &str,
+    ) -> Result<(String, bool), anyhow::Error> {
+        let package = SwiftPackage::parse(manifest_content);
+        let name = package.name.clone().unwrap_or_else(|| "app".to_string());
+        Ok((name, package.executable().is_some()))
+    }
+}
+
+/// Facts read from Package.swift (a Swift source file, so matched textually)
+#[derive(Debug, Default, PartialEq)]
+struct SwiftPackage {
+    name: Option<String>,
+    tools_version: Option<String>,
+    executable_products: Vec<String>,
+    executable_targets: Vec<String>,
+    dependency_urls: Vec<String>,
+}
+
+impl SwiftPackage {
+    fn parse(content: &str) -> Self {
+        let capture_all = |pattern: &str| -> Vec<String> {
+            Regex::new(pattern)
+                .map(|re| {
+                    re.captures_iter(content)
+                        .map(|caps| caps[1].to_string())
+                        .collect()
+                })
+                .unwrap_or_default()
+        };
+
+        Self {
+            name: capture_all(r#"Package\(\s*name:\s*"([^"]+)""#)
+                .into_iter()
+                .next(),
+            tools_version: capture_all(r"//\s*swift-tools-version\s*:\s*(\d+\.\d+)")
+                .into_iter()
+                .next(),
+            executable_products: capture_all(r#"\.executable\(\s*name:\s*"([^"]+)""#),
+            executable_targets: capture_all(r#"\.executableTarget\(\s*name:\s*"([^"]+)""#),
+            dependency_urls: capture_all(r#"\.package\(\s*url:\s*"([^"]+)""#),
+        }
+    }
+
+    /// Binary to ship: an explicit executable product wins over executable targets
+    fn executable(&self) -> Option<&str> {
+        self.executable_products
+            .first()
+            .or_else(|| self.executable_targets.first())
+            .map(String::as_str)
+    }
+
+    fn depends_on(&self, repo: &str) -> bool {
+        self.dependency_urls.iter().any(|url| {
+            url.trim_end_matches('/')
+                .trim_end_matches(".git")
+                .ends_with(&format!("/{}", repo))
+        })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    const VAPOR: &str = r#"// swift-tools-version:5.9
+import PackageDescription
+
+let package = Package(
+    name: "todos",
+    platforms: [.macOS(.v13)],
+    dependencies: [
+        .package(url: "https://github.com/vapor/vapor.git", from: "4.89.0"),
+    ],
+    targets: [
+        .executableTarget(
+            name: "App",
+            dependencies: [.product(name: "Vapor", package: "vapor")]
+        ),
+        .testTarget(name: "AppTests", dependencies: [.target(name: "App")]),
+    ]
+)
+"#;
+
+    const LIBRARY: &str = r#"// swift-tools-version:5.10
+import PackageDescription
+
+let package = Package(
+    name: "Slugify",
+    products: [
+        .library(name: "Slugify", targets: ["Slugify"]),
+    ],
+    targets: [
+        .target(name: "Slugify"),
+        .testTarget(name: "SlugifyTests", dependencies: ["Slugify"]),
+    ]
+)
+"#;
+
+    #[test]
+    fn test_parse_vapor_package() {
+        let package = SwiftPackage::parse(VAPOR);
+        assert_eq!(package.name.as_deref(), Some("todos"));
+        assert_eq!(package.tools_version.as_deref(), Some("5.9"));
+        assert_eq!(package.executable(), Some("App"));
+        assert!(package.depends_on("vapor/vapor"));
+        assert!(!package.depends_on("hummingbird-project/hummingbird"));
+    }
+
+    #[test]
+    fn test_vapor_runtime_command() {
+        assert_eq!(
+            SwiftPmBuildSystem.runtime_command(Path::new("."), Some(VAPOR), &RealFileSystem),
+            Some("/usr/local/bin/App serve --hostname 0.0.0.0 --port 8080".to_string())
+        );
+    }
+
+    #[test]
+    fn test_executable_product_names_binary() {
+        let manifest = r#"let package = Package(
+    name: "api",
+    products: [.executable(name: "api-server", targets: ["Server"])],
+    dependencies: [.package(url: "https://github.com/hummingbird-project/hummingbird.git", from: "2.0.0")],
+    targets: [.executableTarget(name: "Server")]
+)"#;
+        assert_eq!(
+            SwiftPmBuildSystem.runtime_command(Path::new("."), Some(manifest), &RealFileSystem),
+            Some("/usr/local/bin/api-server --hostname 0.0.0.0 --port 8080".to_string())
+        );
+    }
+
+    #[test]
+    fn test_library_package() {
+        let wolfi_index = peelbox_wolfi::WolfiPackageIndex::for_tests();
+        let template = SwiftPmBuildSystem.build_template(
+            &wolfi_index,
+            Path::new("."),
+            Some(LIBRARY),
+            &RealFileSystem,
+        );
+
+        assert_eq!(template.build_commands, vec!["swift build -c release"]);
+        assert!(template.runtime_copy.is_empty());
+        assert_eq!(
+            SwiftPmBuildSystem.runtime_command(Path::new("."), Some(LIBRARY), &RealFileSystem),
+            None
+        );
+        assert_eq!(
+            SwiftPmBuildSystem.parse_package_metadata(LIBRARY).unwrap(),
+            ("Slugify".to_string(), false)
+        );
+    }
+}
@@ -1,6 +1,6 @@
 //! Yarn build system (JavaScript/TypeScript)
 
-use super::node_common::{parse_node_version, read_node_version_file};
+use super::node_common::{node_build_commands, parse_node_version, read_node_version_file};
 use super::{BuildSystem, BuildTemplate, ManifestPattern};
 use crate::{BuildSystemId, DetectionStack, LanguageId};
 use anyhow::Result;
@@ -69,8 +69,9 @@ impl BuildSystem for YarnBuildSystem {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> BuildTemplate {
-        let node_version = read_node_version_file(service_path)
+        let node_version = read_node_version_file(service_path, fs)
             .or_else(|| manifest_content.and_then(parse_node_version))
             .or_else(|| wolfi_index.get_latest_version("nodejs"))
             .expect("Failed to get nodejs version from Wolfi index");
@@ -79,7 +80,13 @@ impl BuildSystem for YarnBuildSystem {
 
         BuildTemplate {
             build_packages: vec![node_version.clone(), "yarn".to_string()],
-            build_commands: vec!["yarn install --frozen-lockfile".to_string()],
+            build_commands: node_build_commands(
+                "yarn install --frozen-lockfile",
+                "yarn",
+                service_path,
+                manifest_content,
+                fs,
+            ),
             cache_paths: vec!["node_modules/".to_string(), ".yarn/cache/".to_string()],
             common_ports: vec![3000, 8080],
             build_env,
@@ -88,6 +95,7 @@ impl BuildSystem for YarnBuildSystem {
                 ("build/".to_string(), "/app/build/".to_string()),
             ],
             runtime_env: std::collections::HashMap::new(),
+            runtime_auxiliary_commands: vec![],
         }
     }
 
@@ -118,7 +126,8 @@ impl BuildSystem for YarnBuildSystem {
         &self,
         repo_path: &std::path::Path,
         pattern: &str,
+        fs: &dyn FileSystem,
     ) -> Result<Vec<std::path::PathBuf>> {
-        super::glob_package_json_workspace_pattern(repo_path, pattern)
+        super::glob_package_json_workspace_pattern(repo_path, pattern, fs)
     }
 }
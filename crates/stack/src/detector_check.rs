@@ -0,0 +1,247 @@
+//! Invariants every registered build system should hold, checked in tests against the default
+//! registry and against a registry with plugins added, so the same checks cover both
+//!
+//! Detection is synchronous and never cancelled midway, so there is no cancellation for
+//! detectors to honour; the checks cover what can go wrong instead:
+//!
+//! 1. `detect_all` succeeds on an empty tree and on manifests it cannot read. A single error
+//!    aborts `StackRegistry::detect_all_stacks` for every build system.
+//! 2. A manifest file claimed by several build systems has one winner: the build systems
+//!    claiming the same (empty) manifest do not share its highest priority.
+//! 3. Detections name the build system that made them and a manifest from the given tree.
+
+use crate::{BuildSystem, BuildSystemId, StackRegistry};
+use peelbox_core::fs::MockFileSystem;
+use std::collections::BTreeMap;
+use std::path::{Path, PathBuf};
+use std::sync::Arc;
+
+/// A broken invariant and the build system breaking it
+#[derive(Debug, Clone, PartialEq)]
+pub struct Violation {
+    pub build_system: BuildSystemId,
+    pub message: String,
+}
+
+/// Checks every build system of `registry`, returning the violations found
+pub fn check_registry(registry: &StackRegistry) -> Vec<Violation> {
+    check_build_systems(&registry.all_build_systems())
+}
+
+pub fn check_build_systems(build_systems: &[Arc<dyn BuildSystem>]) -> Vec<Violation> {
+    let mut violations = Vec::new();
+    for build_system in build_systems {
+        check_detect_all(build_system.as_ref(), &mut violations);
+    }
+    check_priorities(build_systems, &mut violations);
+    violations
+}
+
+fn check_detect_all(build_system: &dyn BuildSystem, violations: &mut Vec<Violation>) {
+    let id = build_system.id();
+    let mut violation = |message: String| {
+        violations.push(Violation {
+            build_system: id.clone(),
+            message,
+        })
+    };
+
+    match build_system.detect_all(Path::new(""), &[], &MockFileSystem::new()) {
+        Ok(detections) if detections.is_empty() => {}
+        Ok(detections) => violation(format!(
+            "detects {} stack(s) in an empty tree",
+            detections.len()
+        )),
+        Err(e) => violation(format!("fails on an empty tree: {}", e)),
+    }
+
+    let mut filenames: Vec<String> = build_system
+        .manifest_patterns()
+        .into_iter()
+        .map(|pattern| pattern.filename)
+        .collect();
+    filenames.sort();
+    for pair in filenames.windows(2).filter(|pair| pair[0] == pair[1]) {
+        violation(format!("declares manifest {} twice", pair[0]));
+    }
+    filenames.dedup();
+
+    // Files in the tree but not in the filesystem: every read fails
+    let tree: Vec<PathBuf> = filenames.iter().map(|name| manifest_path(name)).collect();
+    match build_system.detect_all(Path::new(""), &tree, &MockFileSystem::new()) {
+        Ok(detections) => {
+            for detection in detections {
+                if detection.build_system != id {
+                    violation(format!(
+                        "reports {} as {}",
+                        detection.manifest_path.display(),
+                        detection.build_system.name()
+                    ));
+                }
+                if !tree.contains(&detection.manifest_path) {
+                    violation(format!(
+                        "reports {}, which is not in the file tree",
+                        detection.manifest_path.display()
+                    ));
+                }
+            }
+        }
+        Err(e) => violation(format!("fails on unreadable manifests: {}", e)),
+    }
+}
+
+fn check_priorities(build_systems: &[Arc<dyn BuildSystem>], violations: &mut Vec<Violation>) {
+    let mut claims: BTreeMap<String, Vec<(u8, BuildSystemId)>> = BTreeMap::new();
+    for build_system in build_systems {
+        for pattern in build_system.manifest_patterns() {
+            claims
+                .entry(pattern.filename)
+                .or_default()
+                .push((pattern.priority, build_system.id()));
+        }
+    }
+
+    for (filename, candidates) in claims.into_iter().filter(|(_, c)| c.len() > 1) {
+        let path = manifest_path(&filename);
+        let fs = MockFileSystem::new();
+        fs.add_file(&path, "");
+
+        let mut detected: Vec<(u8, BuildSystemId)> = candidates
+            .into_iter()
+            .filter(|(_, id)| {
+                build_systems
+                    .iter()
+                    .find(|build_system| build_system.id() == *id)
+                    .and_then(|build_system| {
+                        build_system
+                            .detect_all(Path::new(""), std::slice::from_ref(&path), &fs)
+                            .ok()
+                    })
+                    .is_some_and(|detections| !detections.is_empty())
+            })
+            .collect();
+        detected.sort_by(|a, b| b.0.cmp(&a.0));
+
+        let Some(&(top, _)) = detected.first() else {
+            continue;
+        };
+        let tied: Vec<&BuildSystemId> = detected
+            .iter()
+            .filter(|(priority, _)| *priority == top)
+            .map(|(_, id)| id)
+            .collect();
+        if tied.len() > 1 {
+            let names: Vec<String> = tied.iter().map(|id| id.name()).collect();
+            for id in tied {
+                violations.push(Violation {
+                    build_system: id.clone(),
+                    message: format!(
+                        "claims {} at priority {} together with {}",
+                        filename,
+                        top,
+                        names.join(", ")
+                    ),
+                });
+            }
+        }
+    }
+}
+
+/// A manifest in a service directory; glob patterns (`*.csproj`) get a file name
+fn manifest_path(filename: &str) -> PathBuf {
+    PathBuf::from("service").join(filename.replace('*', "app"))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::buildsystem::{BuildTemplate, ManifestPattern};
+    use crate::{DetectionStack, LanguageId};
+    use anyhow::Result;
+    use peelbox_core::fs::FileSystem;
+
+    #[test]
+    fn test_detector_invariants() {
+        let registry = StackRegistry::with_defaults(None);
+        assert_eq!(
+            registry.all_build_systems().len(),
+            BuildSystemId::all_variants().len(),
+            "every built-in build system is registered"
+        );
+        assert_eq!(check_registry(&registry), vec![]);
+    }
+
+    /// Claims every package.json, like npm does when no packageManager is set
+    struct Greedy {
+        id: &'static str,
+        fail: bool,
+    }
+
+    impl BuildSystem for Greedy {
+        fn id(&self) -> BuildSystemId {
+            BuildSystemId::Custom(self.id.to_string())
+        }
+
+        fn manifest_patterns(&self) -> Vec<ManifestPattern> {
+            vec![ManifestPattern {
+                filename: "package.json".to_string(),
+                priority: 10,
+            }]
+        }
+
+        fn detect_all(
+            &self,
+            _repo_root: &Path,
+            file_tree: &[PathBuf],
+            _fs: &dyn FileSystem,
+        ) -> Result<Vec<DetectionStack>> {
+            if self.fail && !file_tree.is_empty() {
+                anyhow::bail!("cannot read package.json");
+            }
+            Ok(file_tree
+                .iter()
+                .map(|path| DetectionStack::new(self.id(), LanguageId::JavaScript, path.clone()))
+                .collect())
+        }
+
+        fn build_template(
+            &self,
+            _wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+            _service_path: &Path,
+            _manifest_content: Option<&str>,
+            _fs: &dyn FileSystem,
+        ) -> BuildTemplate {
+            unimplemented!("not used by the checks")
+        }
+
+        fn cache_dirs(&self) -> Vec<String> {
+            vec![]
+        }
+    }
+
+    #[test]
+    fn test_reports_broken_build_systems() {
+        let registry = StackRegistry::with_defaults(None);
+        registry.add_build_system(Arc::new(Greedy {
+            id: "greedy",
+            fail: false,
+        }));
+        registry.add_build_system(Arc::new(Greedy {
+            id: "failing",
+            fail: true,
+        }));
+
+        let messages: Vec<(String, String)> = check_registry(&registry)
+            .into_iter()
+            .map(|v| (v.build_system.name(), v.message))
+            .collect();
+        assert!(messages.contains(&(
+            "failing".to_string(),
+            "fails on unreadable manifests: cannot read package.json".to_string()
+        )));
+        // The failing build system detects nothing, so only npm and greedy tie
+        assert!(messages.iter().any(|(name, message)| name == "greedy"
+            && message.starts_with("claims package.json at priority 10 together with")
+            && message.contains("npm")));
+    }
+}
@@ -0,0 +1,79 @@
+//! Akka HTTP framework for Scala
+
+use super::*;
+
+pub struct AkkaHttpFramework;
+
+impl Framework for AkkaHttpFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::AkkaHttp
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Scala".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["sbt".to_string(), "mill".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"^com\.typesafe\.akka:akka-http$".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8080]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec!["/health".to_string()]
+    }
+
+    fn config_files(&self) -> Vec<&str> {
+        vec!["src/main/resources/application.conf"]
+    }
+
+    fn parse_config(&self, _file_path: &Path, content: &str) -> Option<FrameworkConfig> {
+        let port_re = Regex::new(r"(?m)^\s*port\s*[=:]\s*(\d+)").ok()?;
+        let port = port_re
+            .captures(content)
+            .and_then(|caps| caps[1].parse::<u16>().ok())?;
+
+        Some(FrameworkConfig {
+            port: Some(port),
+            ..Default::default()
+        })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_akka_http_dependency_detection() {
+        let dep = Dependency {
+            name: "com.typesafe.akka:akka-http".to_string(),
+            version: Some("10.5.3".to_string()),
+            is_internal: false,
+        };
+        assert!(AkkaHttpFramework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&dep)));
+
+        let testkit = Dependency {
+            name: "com.typesafe.akka:akka-http-testkit".to_string(),
+            version: None,
+            is_internal: false,
+        };
+        assert!(!AkkaHttpFramework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&testkit)));
+    }
+}
@@ -0,0 +1,78 @@
+//! Chi framework for Go
+
+use super::*;
+
+pub struct ChiFramework;
+
+impl Framework for ChiFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Chi
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Go".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["go".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"github\.com/go-chi/chi".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8080]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some("Chi has no built-in health endpoint; mount middleware.Heartbeat(\"/health\") or a /health route".to_string())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Dependency;
+
+    #[test]
+    fn test_chi_compatibility() {
+        let framework = ChiFramework;
+        assert!(framework.compatible_languages().iter().any(|s| s == "Go"));
+        assert!(framework
+            .compatible_build_systems()
+            .iter()
+            .any(|s| s == "go"));
+    }
+
+    #[test]
+    fn test_chi_dependency_detection() {
+        let framework = ChiFramework;
+        let patterns = framework.dependency_patterns();
+
+        let dep = Dependency {
+            name: "github.com/go-chi/chi/v5".to_string(),
+            version: Some("v5.0.12".to_string()),
+            is_internal: false,
+        };
+
+        let matches: Vec<_> = patterns.iter().filter(|p| p.matches(&dep)).collect();
+        assert!(!matches.is_empty());
+        assert!(matches[0].confidence >= 0.9);
+    }
+
+    #[test]
+    fn test_chi_has_no_builtin_health_endpoint() {
+        let framework = ChiFramework;
+        assert!(framework.health_endpoints(&[]).is_empty());
+        assert!(framework.health_check_hint().is_some());
+    }
+}
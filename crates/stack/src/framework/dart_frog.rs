@@ -0,0 +1,42 @@
+//! Dart Frog framework for Dart
+
+use super::*;
+
+pub struct DartFrogFramework;
+
+impl Framework for DartFrogFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::DartFrog
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Dart".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["dart-pub".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"^dart_frog$".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8080]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "Dart Frog has no built-in health endpoint; add a routes/health.dart handler"
+                .to_string(),
+        )
+    }
+}
@@ -1,6 +1,8 @@
 //! Django framework for Python
 
 use super::*;
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::DjangoMetadata;
 
 pub struct DjangoFramework;
 
@@ -18,6 +20,9 @@ impl Framework for DjangoFramework {
             "pip".to_string(),
             "poetry".to_string(),
             "pipenv".to_string(),
+            "pdm".to_string(),
+            "hatch".to_string(),
+            "conda".to_string(),
         ]
     }
 
@@ -53,7 +58,7 @@ impl Framework for DjangoFramework {
             "python".to_string(),
             "manage.py".to_string(),
             "runserver".to_string(),
-            "0.0.0.0:8080".to_string(),
+            "0.0.0.0:8000".to_string(),
         ])
     }
 
@@ -111,6 +116,48 @@ impl Framework for DjangoFramework {
     }
 }
 
+/// Inspects a Django project rooted at `service_path`.
+///
+/// Returns `None` when there is no `manage.py`. The settings module is read from the
+/// `DJANGO_SETTINGS_MODULE` default in `manage.py`; `wsgi.py`/`asgi.py` are looked up in
+/// the settings package.
+pub fn inspect_django_project(service_path: &Path, fs: &dyn FileSystem) -> Option<DjangoMetadata> {
+    let manage_py = fs.read_to_string(&service_path.join("manage.py")).ok()?;
+    let settings_module = parse_settings_module(&manage_py);
+
+    let package = settings_module
+        .as_deref()
+        .and_then(|module| module.rsplit_once('.'))
+        .map(|(package, _)| package.to_string());
+
+    let (wsgi_application, asgi_application) = match package.as_deref() {
+        Some(package) => {
+            let dir = service_path.join(package.replace('.', "/"));
+            (
+                fs.is_file(&dir.join("wsgi.py"))
+                    .then(|| format!("{}.wsgi.application", package)),
+                fs.is_file(&dir.join("asgi.py"))
+                    .then(|| format!("{}.asgi.application", package)),
+            )
+        }
+        None => (None, None),
+    };
+    let asgi_capable = wsgi_application.is_some() && asgi_application.is_some();
+
+    Some(DjangoMetadata {
+        settings_module,
+        wsgi_application,
+        asgi_application,
+        asgi_capable,
+    })
+}
+
+fn parse_settings_module(manage_py: &str) -> Option<String> {
+    let re =
+        Regex::new(r#"DJANGO_SETTINGS_MODULE['"]\s*,\s*['"]([A-Za-z_][A-Za-z0-9_.]*)['"]"#).ok()?;
+    re.captures(manage_py).map(|caps| caps[1].to_string())
+}
+
 fn extract_number(s: &str) -> Option<u16> {
     let s = s.trim_matches(|c: char| !c.is_numeric());
     s.parse::<u16>().ok()
@@ -152,6 +199,7 @@ fn extract_django_env_vars(line: &str, env_vars: &mut Vec<String>) {
 mod tests {
     use super::*;
     use crate::language::Dependency;
+    use peelbox_core::fs::RealFileSystem;
 
     #[test]
     fn test_django_compatibility() {
@@ -234,4 +282,58 @@ DATABASE_URL = os.environ['DATABASE_URL']
         assert!(files.contains(&"settings.py"));
         assert!(files.contains(&"*/settings.py"));
     }
+
+    #[test]
+    fn test_django_runserver_binds_default_port() {
+        let cmd = DjangoFramework.entrypoint_command().unwrap();
+        assert_eq!(cmd.last().map(String::as_str), Some("0.0.0.0:8000"));
+    }
+
+    #[test]
+    fn test_parse_settings_module() {
+        let manage_py = r#"
+def main():
+    os.environ.setdefault('DJANGO_SETTINGS_MODULE', 'mysite.settings')
+"#;
+        assert_eq!(
+            parse_settings_module(manage_py),
+            Some("mysite.settings".to_string())
+        );
+        assert_eq!(parse_settings_module("import sys\n"), None);
+    }
+
+    #[test]
+    fn test_inspect_django_project() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("manage.py"),
+            "os.environ.setdefault(\"DJANGO_SETTINGS_MODULE\", \"mysite.settings\")\n",
+        )
+        .unwrap();
+        std::fs::create_dir(dir.path().join("mysite")).unwrap();
+        std::fs::write(dir.path().join("mysite/settings.py"), "").unwrap();
+        std::fs::write(dir.path().join("mysite/wsgi.py"), "").unwrap();
+
+        let project = inspect_django_project(dir.path(), &RealFileSystem).unwrap();
+        assert_eq!(project.settings_module.as_deref(), Some("mysite.settings"));
+        assert_eq!(
+            project.wsgi_application.as_deref(),
+            Some("mysite.wsgi.application")
+        );
+        assert!(!project.asgi_capable);
+
+        std::fs::write(dir.path().join("mysite/asgi.py"), "").unwrap();
+        let project = inspect_django_project(dir.path(), &RealFileSystem).unwrap();
+        assert!(project.asgi_capable);
+        assert_eq!(
+            project.asgi_application.as_deref(),
+            Some("mysite.asgi.application")
+        );
+    }
+
+    #[test]
+    fn test_inspect_requires_manage_py() {
+        let dir = tempfile::tempdir().unwrap();
+        assert!(inspect_django_project(dir.path(), &RealFileSystem).is_none());
+    }
 }
@@ -33,6 +33,10 @@ impl Framework for EchoFramework {
         vec!["/health".to_string(), "/healthz".to_string()]
     }
 
+    fn health_check_hint(&self) -> Option<String> {
+        Some("Echo has no built-in health route; register /health or probe /".to_string())
+    }
+
     fn env_var_patterns(&self) -> Vec<(String, String)> {
         vec![(r"PORT\s*=\s*(\d+)".to_string(), "Server port".to_string())]
     }
@@ -0,0 +1,42 @@
+//! Elysia framework for Bun
+
+use super::*;
+
+pub struct ElysiaFramework;
+
+impl Framework for ElysiaFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Elysia
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["TypeScript".to_string(), "JavaScript".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["bun".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::NpmPackage,
+            pattern: "elysia".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![3000]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "Elysia has no built-in health endpoint; add `.get(\"/health\", ...)` to the application"
+                .to_string(),
+        )
+    }
+}
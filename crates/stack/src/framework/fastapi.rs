@@ -18,6 +18,9 @@ impl Framework for FastApiFramework {
             "pip".to_string(),
             "poetry".to_string(),
             "pipenv".to_string(),
+            "pdm".to_string(),
+            "hatch".to_string(),
+            "conda".to_string(),
         ]
     }
 
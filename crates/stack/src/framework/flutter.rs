@@ -0,0 +1,43 @@
+//! Flutter framework for Dart
+
+use super::*;
+
+pub struct FlutterFramework;
+
+impl Framework for FlutterFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Flutter
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Dart".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["dart-pub".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        // pubspec.yaml lists the SDK as `flutter: { sdk: flutter }`
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"^flutter$".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8080]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "Flutter apps are clients; only the web build can be served from a container"
+                .to_string(),
+        )
+    }
+}
@@ -29,12 +29,16 @@ impl Framework for GinFramework {
         vec![8080]
     }
 
+    /// Gin registers no health route itself; the service's own route is found by scanning
     fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
-        vec![
-            "/health".to_string(),
-            "/healthz".to_string(),
-            "/ping".to_string(),
-        ]
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "Gin has no built-in health endpoint; register r.GET(\"/health\", ...) on the engine"
+                .to_string(),
+        )
     }
 
     fn env_var_patterns(&self) -> Vec<(String, String)> {
@@ -81,4 +85,11 @@ mod tests {
         let framework = GinFramework;
         assert_eq!(framework.default_ports(), vec![8080]);
     }
+
+    #[test]
+    fn test_gin_has_no_builtin_health_endpoint() {
+        let framework = GinFramework;
+        assert!(framework.health_endpoints(&[]).is_empty());
+        assert!(framework.health_check_hint().is_some());
+    }
 }
@@ -0,0 +1,81 @@
+//! Gorilla Mux framework for Go
+
+use super::*;
+
+pub struct GorillaMuxFramework;
+
+impl Framework for GorillaMuxFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::GorillaMux
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Go".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["go".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"github\.com/gorilla/mux".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8080]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "Gorilla Mux has no built-in health endpoint; register a /health handler on the router"
+                .to_string(),
+        )
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Dependency;
+
+    #[test]
+    fn test_gorilla_mux_compatibility() {
+        let framework = GorillaMuxFramework;
+        assert!(framework.compatible_languages().iter().any(|s| s == "Go"));
+        assert!(framework
+            .compatible_build_systems()
+            .iter()
+            .any(|s| s == "go"));
+    }
+
+    #[test]
+    fn test_gorilla_mux_dependency_detection() {
+        let framework = GorillaMuxFramework;
+        let patterns = framework.dependency_patterns();
+
+        let dep = Dependency {
+            name: "github.com/gorilla/mux".to_string(),
+            version: Some("v1.8.1".to_string()),
+            is_internal: false,
+        };
+
+        let matches: Vec<_> = patterns.iter().filter(|p| p.matches(&dep)).collect();
+        assert!(!matches.is_empty());
+        assert!(matches[0].confidence >= 0.9);
+    }
+
+    #[test]
+    fn test_gorilla_mux_has_no_builtin_health_endpoint() {
+        let framework = GorillaMuxFramework;
+        assert!(framework.health_endpoints(&[]).is_empty());
+        assert!(framework.health_check_hint().is_some());
+    }
+}
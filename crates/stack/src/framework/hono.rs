@@ -0,0 +1,56 @@
+//! Hono framework for Deno and Node
+
+use super::*;
+
+pub struct HonoFramework;
+
+impl Framework for HonoFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Hono
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["TypeScript".to_string(), "JavaScript".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec![
+            "deno".to_string(),
+            "npm".to_string(),
+            "yarn".to_string(),
+            "pnpm".to_string(),
+            "bun".to_string(),
+        ]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![
+            DependencyPattern {
+                pattern_type: DependencyPatternType::Regex,
+                pattern: r"^(deno\.land/x/hono|jsr:@hono/hono|npm:hono)$".to_string(),
+                confidence: 0.95,
+            },
+            DependencyPattern {
+                pattern_type: DependencyPatternType::NpmPackage,
+                pattern: "hono".to_string(),
+                confidence: 0.95,
+            },
+        ]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        // Deno.serve listens on 8000, @hono/node-server on 3000
+        vec![8000, 3000]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "Hono has no built-in health endpoint; add `app.get(\"/health\", ...)` to the application"
+                .to_string(),
+        )
+    }
+}
@@ -0,0 +1,77 @@
+//! http4s framework for Scala
+
+use super::*;
+
+pub struct Http4sFramework;
+
+impl Framework for Http4sFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Http4s
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Scala".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["sbt".to_string(), "mill".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![
+            DependencyPattern {
+                pattern_type: DependencyPatternType::Regex,
+                pattern: r"^org\.http4s:http4s-(ember|blaze|netty|jetty)-server$".to_string(),
+                confidence: 0.95,
+            },
+            DependencyPattern {
+                pattern_type: DependencyPatternType::Regex,
+                pattern: r"^org\.http4s:http4s-dsl$".to_string(),
+                confidence: 0.85,
+            },
+        ]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8080]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "http4s has no built-in health endpoint; route `GET -> Root / \"health\"` in the service"
+                .to_string(),
+        )
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_http4s_dependency_detection() {
+        let server = Dependency {
+            name: "org.http4s:http4s-ember-server".to_string(),
+            version: Some("0.23.27".to_string()),
+            is_internal: false,
+        };
+        assert!(Http4sFramework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&server)));
+
+        let client = Dependency {
+            name: "org.http4s:http4s-ember-client".to_string(),
+            version: None,
+            is_internal: false,
+        };
+        assert!(!Http4sFramework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&client)));
+    }
+}
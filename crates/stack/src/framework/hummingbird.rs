@@ -0,0 +1,65 @@
+//! Hummingbird framework for Swift
+
+use super::*;
+
+pub struct HummingbirdFramework;
+
+impl Framework for HummingbirdFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Hummingbird
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Swift".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["swiftpm".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"^hummingbird-project/hummingbird$".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8080]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec!["/health".to_string(), "/healthz".to_string()]
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Dependency;
+
+    #[test]
+    fn test_hummingbird_dependency_detection() {
+        let framework = HummingbirdFramework;
+        let dep = Dependency {
+            name: "hummingbird-project/hummingbird".to_string(),
+            version: Some("2.0.0".to_string()),
+            is_internal: false,
+        };
+
+        assert!(framework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&dep)));
+        assert!(framework
+            .compatible_build_systems()
+            .iter()
+            .any(|s| s == "swiftpm"));
+    }
+
+    #[test]
+    fn test_hummingbird_default_ports() {
+        assert_eq!(HummingbirdFramework.default_ports(), vec![8080]);
+    }
+}
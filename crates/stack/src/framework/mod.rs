@@ -83,6 +83,11 @@ pub trait Framework: Send + Sync {
     /// Takes file list for detecting framework-specific files (e.g., actuator presence)
     fn health_endpoints(&self, files: &[PathBuf]) -> Vec<String>;
 
+    /// Guidance on health checks when the framework has no conventional endpoint
+    fn health_check_hint(&self) -> Option<String> {
+        None
+    }
+
     /// Runtime environment variables specific to this framework
     fn runtime_env_vars(&self) -> HashMap<String, String> {
         HashMap::new()
@@ -115,47 +120,91 @@ pub trait Framework: Send + Sync {
 }
 
 pub mod actix;
+pub mod akka_http;
 pub mod aspnet;
 pub mod axum;
+pub mod chi;
+pub mod dart_frog;
 pub mod django;
 pub mod echo;
+pub mod elysia;
 pub mod express;
 pub mod fastapi;
 pub mod fastify;
 pub mod flask;
+pub mod flutter;
+pub mod fresh;
 pub mod gin;
+pub mod gorilla_mux;
+pub mod hono;
+pub mod http4s;
+pub mod hummingbird;
 pub mod ktor;
 pub mod laravel;
 pub mod llm;
 pub mod micronaut;
 pub mod nestjs;
 pub mod nextjs;
+pub mod nuxt;
+pub mod oak;
 pub mod phoenix;
+pub mod play;
 pub mod quarkus;
 pub mod rails;
+pub mod remix;
+pub mod scotty;
+pub mod servant;
+pub mod shelf;
 pub mod sinatra;
 pub mod spring_boot;
+pub mod starlette;
+pub mod sveltekit;
 pub mod symfony;
+pub mod tornado;
+pub mod vapor;
+pub mod yesod;
 
 pub use actix::ActixFramework;
+pub use akka_http::AkkaHttpFramework;
 pub use aspnet::AspNetFramework;
 pub use axum::AxumFramework;
+pub use chi::ChiFramework;
+pub use dart_frog::DartFrogFramework;
 pub use django::DjangoFramework;
 pub use echo::EchoFramework;
+pub use elysia::ElysiaFramework;
 pub use express::ExpressFramework;
 pub use fastapi::FastApiFramework;
 pub use fastify::FastifyFramework;
 pub use flask::FlaskFramework;
+pub use flutter::FlutterFramework;
+pub use fresh::FreshFramework;
 pub use gin::GinFramework;
+pub use gorilla_mux::GorillaMuxFramework;
+pub use hono::HonoFramework;
+pub use http4s::Http4sFramework;
+pub use hummingbird::HummingbirdFramework;
 pub use ktor::KtorFramework;
 pub use laravel::LaravelFramework;
 pub use llm::LLMFramework;
 pub use micronaut::MicronautFramework;
 pub use nestjs::NestJsFramework;
 pub use nextjs::NextJsFramework;
+pub use nuxt::NuxtFramework;
+pub use oak::OakFramework;
 pub use phoenix::PhoenixFramework;
+pub use play::PlayFramework;
 pub use quarkus::QuarkusFramework;
 pub use rails::RailsFramework;
+pub use remix::RemixFramework;
+pub use scotty::ScottyFramework;
+pub use servant::ServantFramework;
+pub use shelf::ShelfFramework;
 pub use sinatra::SinatraFramework;
 pub use spring_boot::SpringBootFramework;
+pub use starlette::StarletteFramework;
+pub use sveltekit::SvelteKitFramework;
 pub use symfony::SymfonyFramework;
+pub use tornado::TornadoFramework;
+pub use vapor::VaporFramework;
+pub use yesod::YesodFramework;
@@ -0,0 +1,89 @@
+//! Nuxt framework for JavaScript/TypeScript
+
+use super::*;
+
+pub struct NuxtFramework;
+
+impl Framework for NuxtFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Nuxt
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["JavaScript".to_string(), "TypeScript".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec![
+            "npm".to_string(),
+            "yarn".to_string(),
+            "pnpm".to_string(),
+            "bun".to_string(),
+        ]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::NpmPackage,
+            pattern: "nuxt".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![3000]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec!["/health".to_string(), "/api/health".to_string()]
+    }
+
+    fn entrypoint_command(&self) -> Option<Vec<String>> {
+        Some(vec![
+            "node".to_string(),
+            ".output/server/index.mjs".to_string(),
+        ])
+    }
+
+    fn config_files(&self) -> Vec<&str> {
+        vec!["nuxt.config.ts"]
+    }
+
+    fn customize_build_template(&self, mut template: BuildTemplate) -> BuildTemplate {
+        // Nuxt builds a self-contained Nitro server into .output/
+        for (from, to) in [(".output/", "/app/.output")] {
+            if !template.runtime_copy.iter().any(|(f, _)| f == from) {
+                template
+                    .runtime_copy
+                    .push((from.to_string(), to.to_string()));
+            }
+        }
+        template
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Dependency;
+
+    #[test]
+    fn test_nuxt_dependency_detection() {
+        let framework = NuxtFramework;
+        let dep = Dependency {
+            name: "nuxt".to_string(),
+            version: None,
+            is_internal: false,
+        };
+
+        assert!(framework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&dep)));
+    }
+
+    #[test]
+    fn test_nuxt_default_ports() {
+        assert_eq!(NuxtFramework.default_ports(), vec![3000]);
+    }
+}
@@ -0,0 +1,42 @@
+//! Oak framework for Deno
+
+use super::*;
+
+pub struct OakFramework;
+
+impl Framework for OakFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Oak
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["TypeScript".to_string(), "JavaScript".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["deno".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"^(deno\.land/x/oak|jsr:@oak/oak|npm:@oakserver/oak)$".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8000]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "Oak has no built-in health endpoint; add `router.get(\"/health\", ...)` to the application"
+                .to_string(),
+        )
+    }
+}
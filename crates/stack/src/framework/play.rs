@@ -0,0 +1,123 @@
+//! Play Framework for Scala
+
+use super::*;
+
+pub struct PlayFramework;
+
+impl Framework for PlayFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Play
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Scala".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["sbt".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![
+            // sbt auto-plugins enabled by the build (`enablePlugins(PlayScala)`)
+            DependencyPattern {
+                pattern_type: DependencyPatternType::Regex,
+                pattern: r"^Play(Scala|Java)$".to_string(),
+                confidence: 0.95,
+            },
+            DependencyPattern {
+                pattern_type: DependencyPatternType::Regex,
+                pattern: r"^(com\.typesafe\.play|org\.playframework):play(-server|-guice)?$"
+                    .to_string(),
+                confidence: 0.9,
+            },
+        ]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![9000]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some("Play has no built-in health endpoint; add `GET /health` to conf/routes".to_string())
+    }
+
+    fn env_var_patterns(&self) -> Vec<(String, String)> {
+        vec![(
+            r"\$\{\?([A-Z_][A-Z0-9_]*)\}".to_string(),
+            "HOCON substitution".to_string(),
+        )]
+    }
+
+    fn config_files(&self) -> Vec<&str> {
+        vec!["conf/application.conf"]
+    }
+
+    fn parse_config(&self, _file_path: &Path, content: &str) -> Option<FrameworkConfig> {
+        // play.server.http.port = 9001 or http.port = 9001
+        let port_re = Regex::new(r"(?m)^\s*(?:play\.server\.)?http\.port\s*[=:]\s*(\d+)").ok()?;
+        let port = port_re
+            .captures(content)
+            .and_then(|caps| caps[1].parse::<u16>().ok());
+
+        let env_re = Regex::new(r"\$\{\?([A-Z_][A-Z0-9_]*)\}").ok()?;
+        let env_vars: Vec<String> = env_re
+            .captures_iter(content)
+            .map(|caps| caps[1].to_string())
+            .collect();
+
+        if port.is_none() && env_vars.is_empty() {
+            return None;
+        }
+
+        Some(FrameworkConfig {
+            port,
+            env_vars,
+            health_endpoint: None,
+        })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_play_plugin_detection() {
+        let dep = Dependency {
+            name: "PlayScala".to_string(),
+            version: None,
+            is_internal: false,
+        };
+        assert!(PlayFramework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&dep)));
+
+        let json = Dependency {
+            name: "com.typesafe.play:play-json".to_string(),
+            version: Some("2.10.4".to_string()),
+            is_internal: false,
+        };
+        assert!(!PlayFramework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&json)));
+    }
+
+    #[test]
+    fn test_play_parse_config() {
+        let config = PlayFramework
+            .parse_config(
+                Path::new("conf/application.conf"),
+                "play.http.secret.key = ${?APPLICATION_SECRET}\nplay.server.http.port = 9001\n",
+            )
+            .unwrap();
+        assert_eq!(config.port, Some(9001));
+        assert_eq!(config.env_vars, vec!["APPLICATION_SECRET"]);
+    }
+}
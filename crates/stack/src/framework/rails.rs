@@ -37,6 +37,13 @@ impl Framework for RailsFramework {
         ]
     }
 
+    fn runtime_env_vars(&self) -> HashMap<String, String> {
+        let mut env = HashMap::new();
+        env.insert("RAILS_ENV".to_string(), "production".to_string());
+        env.insert("RAILS_LOG_TO_STDOUT".to_string(), "1".to_string());
+        env
+    }
+
     fn env_var_patterns(&self) -> Vec<(String, String)> {
         vec![
             (
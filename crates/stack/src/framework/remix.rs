@@ -0,0 +1,96 @@
+//! Remix framework for JavaScript/TypeScript
+
+use super::*;
+
+pub struct RemixFramework;
+
+impl Framework for RemixFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Remix
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["JavaScript".to_string(), "TypeScript".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec![
+            "npm".to_string(),
+            "yarn".to_string(),
+            "pnpm".to_string(),
+            "bun".to_string(),
+        ]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![
+            DependencyPattern {
+                pattern_type: DependencyPatternType::NpmPackage,
+                pattern: "@remix-run/node".to_string(),
+                confidence: 0.95,
+            },
+            DependencyPattern {
+                pattern_type: DependencyPatternType::NpmPackage,
+                pattern: "@remix-run/serve".to_string(),
+                confidence: 0.95,
+            },
+        ]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![3000]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec!["/healthcheck".to_string(), "/health".to_string()]
+    }
+
+    fn entrypoint_command(&self) -> Option<Vec<String>> {
+        Some(vec![
+            "npx".to_string(),
+            "remix-serve".to_string(),
+            "build/server/index.js".to_string(),
+        ])
+    }
+
+    fn config_files(&self) -> Vec<&str> {
+        vec!["remix.config.js"]
+    }
+
+    fn customize_build_template(&self, mut template: BuildTemplate) -> BuildTemplate {
+        for (from, to) in [("build/", "/app/build"), ("public/", "/app/public")] {
+            if !template.runtime_copy.iter().any(|(f, _)| f == from) {
+                template
+                    .runtime_copy
+                    .push((from.to_string(), to.to_string()));
+            }
+        }
+        template
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Dependency;
+
+    #[test]
+    fn test_remix_dependency_detection() {
+        let framework = RemixFramework;
+        let dep = Dependency {
+            name: "@remix-run/node".to_string(),
+            version: None,
+            is_internal: false,
+        };
+
+        assert!(framework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&dep)));
+    }
+
+    #[test]
+    fn test_remix_default_ports() {
+        assert_eq!(RemixFramework.default_ports(), vec![3000]);
+    }
+}
@@ -0,0 +1,42 @@
+//! Scotty framework for Haskell
+
+use super::*;
+
+pub struct ScottyFramework;
+
+impl Framework for ScottyFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Scotty
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Haskell".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["stack".to_string(), "cabal".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"^scotty$".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![3000]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "Scotty has no built-in health endpoint; add `get \"/health\"` to the application"
+                .to_string(),
+        )
+    }
+}
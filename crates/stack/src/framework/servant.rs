@@ -0,0 +1,61 @@
+//! Servant framework for Haskell
+
+use super::*;
+
+pub struct ServantFramework;
+
+impl Framework for ServantFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Servant
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Haskell".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["stack".to_string(), "cabal".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        // The `servant` package alone only describes the API type (clients use it too)
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"^servant-server$".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8080]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "Servant has no built-in health endpoint; add `\"health\" :> Get '[JSON] NoContent` to the API type"
+                .to_string(),
+        )
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_servant_server_only() {
+        let dep = |name: &str| Dependency {
+            name: name.to_string(),
+            version: None,
+            is_internal: false,
+        };
+        let patterns = ServantFramework.dependency_patterns();
+        assert!(patterns.iter().any(|p| p.matches(&dep("servant-server"))));
+        assert!(!patterns.iter().any(|p| p.matches(&dep("servant-client"))));
+        assert!(!patterns.iter().any(|p| p.matches(&dep("servant"))));
+    }
+}
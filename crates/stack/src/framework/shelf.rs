@@ -0,0 +1,42 @@
+//! Shelf framework for Dart
+
+use super::*;
+
+pub struct ShelfFramework;
+
+impl Framework for ShelfFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Shelf
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Dart".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["dart-pub".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"^shelf$".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8080]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "Shelf has no built-in health endpoint; add `..get('/health', ...)` to the shelf_router Router"
+                .to_string(),
+        )
+    }
+}
@@ -0,0 +1,85 @@
+//! Starlette framework for Python
+
+use super::*;
+
+pub struct StarletteFramework;
+
+impl Framework for StarletteFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Starlette
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Python".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec![
+            "pip".to_string(),
+            "poetry".to_string(),
+            "pipenv".to_string(),
+            "pdm".to_string(),
+            "hatch".to_string(),
+            "conda".to_string(),
+        ]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        // FastAPI depends on starlette; FastApi is declared first so it wins
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::PypiPackage,
+            pattern: "starlette".to_string(),
+            confidence: 0.9,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8000]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec!["/health".to_string(), "/healthz".to_string()]
+    }
+
+    fn entrypoint_command(&self) -> Option<Vec<String>> {
+        Some(vec![
+            "uvicorn".to_string(),
+            "main:app".to_string(),
+            "--host".to_string(),
+            "0.0.0.0".to_string(),
+            "--port".to_string(),
+            "8000".to_string(),
+        ])
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Dependency;
+
+    #[test]
+    fn test_starlette_dependency_detection() {
+        let framework = StarletteFramework;
+        let dep = Dependency {
+            name: "starlette".to_string(),
+            version: None,
+            is_internal: false,
+        };
+
+        let matches: Vec<_> = framework
+            .dependency_patterns()
+            .iter()
+            .filter(|p| p.matches(&dep))
+            .cloned()
+            .collect();
+        assert_eq!(matches.len(), 1);
+        assert!(matches[0].confidence < 0.95);
+    }
+
+    #[test]
+    fn test_starlette_entrypoint_uses_uvicorn() {
+        let cmd = StarletteFramework.entrypoint_command().unwrap();
+        assert_eq!(cmd[0], "uvicorn");
+    }
+}
@@ -0,0 +1,89 @@
+//! SvelteKit framework for JavaScript/TypeScript
+
+use super::*;
+
+pub struct SvelteKitFramework;
+
+impl Framework for SvelteKitFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::SvelteKit
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["JavaScript".to_string(), "TypeScript".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec![
+            "npm".to_string(),
+            "yarn".to_string(),
+            "pnpm".to_string(),
+            "bun".to_string(),
+        ]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::NpmPackage,
+            pattern: "@sveltejs/kit".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![3000]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec!["/health".to_string()]
+    }
+
+    fn entrypoint_command(&self) -> Option<Vec<String>> {
+        Some(vec!["node".to_string(), "build".to_string()])
+    }
+
+    fn config_files(&self) -> Vec<&str> {
+        vec!["svelte.config.js"]
+    }
+
+    fn customize_build_template(&self, mut template: BuildTemplate) -> BuildTemplate {
+        // adapter-node emits a runnable server into build/
+        for (from, to) in [
+            ("build/", "/app/build"),
+            ("package.json", "/app/package.json"),
+        ] {
+            if !template.runtime_copy.iter().any(|(f, _)| f == from) {
+                template
+                    .runtime_copy
+                    .push((from.to_string(), to.to_string()));
+            }
+        }
+        template
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Dependency;
+
+    #[test]
+    fn test_sveltekit_dependency_detection() {
+        let framework = SvelteKitFramework;
+        let dep = Dependency {
+            name: "@sveltejs/kit".to_string(),
+            version: None,
+            is_internal: false,
+        };
+
+        assert!(framework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&dep)));
+    }
+
+    #[test]
+    fn test_sveltekit_default_ports() {
+        assert_eq!(SvelteKitFramework.default_ports(), vec![3000]);
+    }
+}
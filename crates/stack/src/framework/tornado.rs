@@ -0,0 +1,68 @@
+//! Tornado framework for Python
+
+use super::*;
+
+pub struct TornadoFramework;
+
+impl Framework for TornadoFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Tornado
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Python".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec![
+            "pip".to_string(),
+            "poetry".to_string(),
+            "pipenv".to_string(),
+            "pdm".to_string(),
+            "hatch".to_string(),
+            "conda".to_string(),
+        ]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::PypiPackage,
+            pattern: "tornado".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8888]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec!["/health".to_string()]
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Dependency;
+
+    #[test]
+    fn test_tornado_dependency_detection() {
+        let framework = TornadoFramework;
+        let dep = Dependency {
+            name: "tornado".to_string(),
+            version: Some("6.4".to_string()),
+            is_internal: false,
+        };
+
+        assert!(framework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&dep)));
+    }
+
+    #[test]
+    fn test_tornado_default_ports() {
+        assert_eq!(TornadoFramework.default_ports(), vec![8888]);
+    }
+}
@@ -0,0 +1,95 @@
+//! Vapor framework for Swift
+
+use super::*;
+
+pub struct VaporFramework;
+
+impl Framework for VaporFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Vapor
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Swift".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["swiftpm".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"^vapor/vapor$".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![8080]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec!["/health".to_string(), "/healthz".to_string()]
+    }
+
+    fn env_var_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r"LOG_LEVEL\s*=\s*(\w+)".to_string(),
+                "Vapor log level".to_string(),
+            ),
+            (
+                r"DATABASE_URL\s*=\s*(\S+)".to_string(),
+                "Vapor database URL".to_string(),
+            ),
+        ]
+    }
+
+    fn config_files(&self) -> Vec<&str> {
+        vec!["Sources/App/configure.swift"]
+    }
+
+    fn parse_config(&self, _file_path: &Path, content: &str) -> Option<FrameworkConfig> {
+        // app.http.server.configuration.port = 8081
+        let port_re = Regex::new(r"configuration\.port\s*=\s*(\d+)").ok()?;
+        let port = port_re
+            .captures(content)
+            .and_then(|caps| caps[1].parse::<u16>().ok())?;
+
+        Some(FrameworkConfig {
+            port: Some(port),
+            ..Default::default()
+        })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Dependency;
+
+    #[test]
+    fn test_vapor_dependency_detection() {
+        let framework = VaporFramework;
+        let dep = Dependency {
+            name: "vapor/vapor".to_string(),
+            version: Some("4.89.0".to_string()),
+            is_internal: false,
+        };
+
+        assert!(framework
+            .dependency_patterns()
+            .iter()
+            .any(|p| p.matches(&dep)));
+        assert!(framework
+            .compatible_build_systems()
+            .iter()
+            .any(|s| s == "swiftpm"));
+    }
+
+    #[test]
+    fn test_vapor_default_ports() {
+        assert_eq!(VaporFramework.default_ports(), vec![8080]);
+    }
+}
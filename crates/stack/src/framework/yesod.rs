@@ -0,0 +1,83 @@
+//! Yesod framework for Haskell
+
+use super::*;
+
+pub struct YesodFramework;
+
+impl Framework for YesodFramework {
+    fn id(&self) -> crate::FrameworkId {
+        crate::FrameworkId::Yesod
+    }
+
+    fn compatible_languages(&self) -> Vec<String> {
+        vec!["Haskell".to_string()]
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["stack".to_string(), "cabal".to_string()]
+    }
+
+    fn dependency_patterns(&self) -> Vec<DependencyPattern> {
+        vec![DependencyPattern {
+            pattern_type: DependencyPatternType::Regex,
+            pattern: r"^yesod(-core)?$".to_string(),
+            confidence: 0.95,
+        }]
+    }
+
+    fn default_ports(&self) -> Vec<u16> {
+        vec![3000]
+    }
+
+    fn health_endpoints(&self, _files: &[std::path::PathBuf]) -> Vec<String> {
+        vec![]
+    }
+
+    fn health_check_hint(&self) -> Option<String> {
+        Some(
+            "Yesod has no built-in health endpoint; add `/health HealthR GET` to config/routes"
+                .to_string(),
+        )
+    }
+
+    fn env_var_patterns(&self) -> Vec<(String, String)> {
+        // settings.yml reads overrides as "_env:YESOD_PORT:3000"
+        vec![(
+            r"_env:([A-Z_][A-Z0-9_]*):".to_string(),
+            "Yesod settings".to_string(),
+        )]
+    }
+
+    fn config_files(&self) -> Vec<&str> {
+        vec!["config/settings.yml"]
+    }
+
+    fn parse_config(&self, _file_path: &Path, content: &str) -> Option<FrameworkConfig> {
+        // port: "_env:YESOD_PORT:3000" or port: 3000
+        let port_re = Regex::new(r#"(?m)^port:\s*"?(?:_env:[A-Z_]+:)?(\d+)"?"#).ok()?;
+        let port = port_re
+            .captures(content)
+            .and_then(|caps| caps[1].parse::<u16>().ok())?;
+
+        Some(FrameworkConfig {
+            port: Some(port),
+            ..Default::default()
+        })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_settings_port() {
+        let config = YesodFramework
+            .parse_config(
+                Path::new("config/settings.yml"),
+                "host: \"_env:YESOD_HOST:*4\"\nport: \"_env:YESOD_PORT:3001\"\n",
+            )
+            .unwrap();
+        assert_eq!(config.port, Some(3001));
+    }
+}
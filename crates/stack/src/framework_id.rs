@@ -5,6 +5,9 @@ crate::define_id_enum! {
         Quarkus => "quarkus" : "Quarkus",
         Micronaut => "micronaut" : "Micronaut",
         Ktor => "ktor" : "Ktor",
+        Nuxt => "nuxt" : "Nuxt",
+        SvelteKit => "sveltekit" : "SvelteKit",
+        Remix => "remix" : "Remix",
         Express => "express" : "Express",
         NextJs => "nextjs" : "Next.js",
         NestJs => "nestjs" : "NestJS",
@@ -12,16 +15,35 @@ crate::define_id_enum! {
         Django => "django" : "Django",
         Flask => "flask" : "Flask",
         FastApi => "fastapi" : "FastAPI",
+        Tornado => "tornado" : "Tornado",
+        Starlette => "starlette" : "Starlette",
         Rails => "rails" : "Rails",
         Sinatra => "sinatra" : "Sinatra",
         ActixWeb => "actix-web" : "Actix Web",
         Axum => "axum" : "Axum",
         Gin => "gin" : "Gin",
         Echo => "echo" : "Echo",
+        GorillaMux => "gorilla-mux" : "Gorilla Mux",
+        Chi => "chi" : "Chi",
         AspNetCore => "aspnet-core" : "ASP.NET Core",
         Laravel => "laravel" : "Laravel",
         Symfony => "symfony" : "Symfony",
         Phoenix => "phoenix" : "Phoenix",
+        Vapor => "vapor" : "Vapor",
+        Hummingbird => "hummingbird" : "Hummingbird",
+        Play => "play" : "Play",
+        AkkaHttp => "akka-http" : "Akka HTTP",
+        Http4s => "http4s" : "http4s",
+        Yesod => "yesod" : "Yesod",
+        Servant => "servant" : "Servant",
+        Scotty => "scotty" : "Scotty",
+        Oak => "oak" : "Oak",
+        Fresh => "fresh" : "Fresh",
+        Hono => "hono" : "Hono",
+        Elysia => "elysia" : "Elysia",
+        Shelf => "shelf" : "Shelf",
+        DartFrog => "dart-frog" : "Dart Frog",
+        Flutter => "flutter" : "Flutter",
     }
 }
 
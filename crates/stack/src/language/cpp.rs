@@ -61,7 +61,12 @@ impl LanguageDefinition for CppLanguage {
     }
 
     fn compatible_build_systems(&self) -> Vec<String> {
-        vec!["cmake".to_string(), "make".to_string(), "meson".to_string()]
+        vec![
+            "cmake".to_string(),
+            "make".to_string(),
+            "meson".to_string(),
+            "bazel".to_string(),
+        ]
     }
 
     fn excluded_dirs(&self) -> Vec<String> {
@@ -256,7 +261,10 @@ mod tests {
     #[test]
     fn test_compatible_build_systems() {
         let lang = CppLanguage;
-        assert_eq!(lang.compatible_build_systems(), &["cmake", "make", "meson"]);
+        assert_eq!(
+            lang.compatible_build_systems(),
+            &["cmake", "make", "meson", "bazel"]
+        );
     }
 
     #[test]
@@ -0,0 +1,275 @@
+//! Dart language definition (Dart servers and Flutter apps)
+
+use super::{Dependency, DependencyInfo, DetectionMethod, DetectionResult, LanguageDefinition};
+use crate::buildsystem::dart_pub::Pubspec;
+use peelbox_core::fs::{FileSystem, FileType};
+use peelbox_core::output::schema::DartMetadata;
+use regex::Regex;
+use std::path::Path;
+
+pub struct DartLanguage;
+
+impl LanguageDefinition for DartLanguage {
+    fn id(&self) -> crate::LanguageId {
+        crate::LanguageId::Dart
+    }
+
+    fn extensions(&self) -> Vec<String> {
+        vec!["dart".to_string()]
+    }
+
+    fn detect(
+        &self,
+        manifest_name: &str,
+        manifest_content: Option<&str>,
+    ) -> Option<DetectionResult> {
+        if manifest_name != "pubspec.yaml" {
+            return None;
+        }
+
+        let mut confidence = 0.9;
+        if manifest_content.is_some_and(|c| c.contains("name:")) {
+            confidence = 1.0;
+        }
+
+        Some(DetectionResult {
+            build_system: crate::BuildSystemId::DartPub,
+            confidence,
+        })
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["dart-pub".to_string()]
+    }
+
+    fn excluded_dirs(&self) -> Vec<String> {
+        vec![
+            ".dart_tool".to_string(),
+            "build".to_string(),
+            ".pub-cache".to_string(),
+        ]
+    }
+
+    fn detect_version(&self, manifest_content: Option<&str>) -> Option<String> {
+        // Lower bound of `environment.sdk`: "^3.3.0" or ">=3.0.0 <4.0.0"
+        let sdk = Pubspec::parse(manifest_content?).sdk?;
+        let re = Regex::new(r"(\d+\.\d+(?:\.\d+)?)").ok()?;
+        re.captures(&sdk).map(|caps| caps[1].to_string())
+    }
+
+    fn parse_dependencies(
+        &self,
+        manifest_content: &str,
+        _all_internal_paths: &[std::path::PathBuf],
+    ) -> DependencyInfo {
+        DependencyInfo {
+            internal_deps: vec![],
+            external_deps: Pubspec::parse(manifest_content)
+                .dependencies
+                .into_iter()
+                .map(|name| Dependency {
+                    name,
+                    version: None,
+                    is_internal: false,
+                })
+                .collect(),
+            detected_by: DetectionMethod::Deterministic,
+        }
+    }
+
+    fn env_var_patterns(&self) -> Vec<(String, String)> {
+        vec![(
+            r#"Platform\.environment\[\s*['"]([A-Z_][A-Z0-9_]*)['"]\s*\]"#.to_string(),
+            "Platform.environment".to_string(),
+        )]
+    }
+
+    fn port_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r#"Platform\.environment\[\s*['"]PORT['"]\s*\]\s*\?\?\s*['"](\d{4,5})['"]"#
+                    .to_string(),
+                "PORT fallback".to_string(),
+            ),
+            (
+                r"serve\([^;]*?,\s*(\d{4,5})\s*\)".to_string(),
+                "shelf_io.serve".to_string(),
+            ),
+        ]
+    }
+
+    fn health_check_patterns(&self) -> Vec<(String, String)> {
+        vec![(
+            r#"\.get\(\s*['"](/[\w\-/]*health[\w\-]*)['"]"#.to_string(),
+            "shelf_router".to_string(),
+        )]
+    }
+
+    fn is_main_file(
+        &self,
+        fs: &dyn peelbox_core::fs::FileSystem,
+        file_path: &std::path::Path,
+    ) -> bool {
+        if !file_path.extension().is_some_and(|ext| ext == "dart") {
+            return false;
+        }
+
+        fs.read_to_string(file_path)
+            .is_ok_and(|content| content.contains("void main(") || content.contains("main() "))
+    }
+
+    fn runtime_name(&self) -> Option<String> {
+        Some("dart".to_string())
+    }
+
+    fn default_port(&self) -> Option<u16> {
+        Some(8080)
+    }
+}
+
+/// Whether the project is a Flutter app, its SDK constraint, the commands for working on
+/// it locally and the tests under test/
+pub fn inspect_dart_project(
+    service_path: &Path,
+    manifest_content: &str,
+    fs: &dyn FileSystem,
+) -> DartMetadata {
+    let pubspec = Pubspec::parse(manifest_content);
+    let test_files = test_files(service_path, fs);
+
+    let (run_command, build_command) = if pubspec.flutter {
+        (
+            Some("flutter run".to_string()),
+            Some(pubspec.flutter_build_command(service_path, fs)),
+        )
+    } else if pubspec.depends_on("dart_frog") {
+        (
+            Some("dart_frog dev".to_string()),
+            Some("dart_frog build".to_string()),
+        )
+    } else {
+        match pubspec.server_entry(service_path, fs) {
+            Some(entry) => (
+                Some(format!("dart run {}", entry)),
+                Some(format!("dart compile exe {}", entry)),
+            ),
+            None => (None, None),
+        }
+    };
+    let test_command = (!test_files.is_empty()).then(|| {
+        if pubspec.flutter {
+            "flutter test".to_string()
+        } else {
+            "dart test".to_string()
+        }
+    });
+
+    DartMetadata {
+        flutter: pubspec.flutter,
+        sdk: pubspec.sdk,
+        run_command,
+        build_command,
+        test_command,
+        test_files,
+    }
+}
+
+/// `*_test.dart` files under test/, relative to the service, in path order
+fn test_files(service_path: &Path, fs: &dyn FileSystem) -> Vec<String> {
+    let mut files = Vec::new();
+    let mut dirs = vec![service_path.join("test")];
+    while let Some(dir) = dirs.pop() {
+        let Ok(entries) = fs.read_dir(&dir) else {
+            continue;
+        };
+        for entry in entries {
+            if entry.file_type() == FileType::Directory {
+                dirs.push(entry.path);
+            } else if entry.file_name().ends_with("_test.dart") {
+                if let Ok(relative) = entry.path().strip_prefix(service_path) {
+                    files.push(relative.to_string_lossy().replace('\\', "/"));
+                }
+            }
+        }
+    }
+    files.sort();
+    files
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    #[test]
+    fn test_detect_pubspec() {
+        assert_eq!(
+            DartLanguage
+                .detect("pubspec.yaml", Some("name: app\n"))
+                .unwrap()
+                .build_system,
+            crate::BuildSystemId::DartPub
+        );
+        assert!(DartLanguage.detect("pubspec.lock", None).is_none());
+    }
+
+    #[test]
+    fn test_sdk_version() {
+        assert_eq!(
+            DartLanguage.detect_version(Some("environment:\n  sdk: '>=3.3.0 <4.0.0'\n")),
+            Some("3.3.0".to_string())
+        );
+    }
+
+    #[test]
+    fn test_inspect_shelf_server() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::create_dir_all(dir.path().join("bin")).unwrap();
+        std::fs::create_dir_all(dir.path().join("test/routes")).unwrap();
+        std::fs::write(dir.path().join("bin/server.dart"), "void main() {}\n").unwrap();
+        std::fs::write(dir.path().join("test/server_test.dart"), "").unwrap();
+        std::fs::write(dir.path().join("test/routes/notes_test.dart"), "").unwrap();
+        std::fs::write(dir.path().join("test/helpers.dart"), "").unwrap();
+
+        let metadata = inspect_dart_project(
+            dir.path(),
+            "name: notes_api\nenvironment:\n  sdk: ^3.3.0\ndependencies:\n  shelf: ^1.4.1\n",
+            &RealFileSystem,
+        );
+        assert!(!metadata.flutter);
+        assert_eq!(metadata.sdk.as_deref(), Some("^3.3.0"));
+        assert_eq!(
+            metadata.run_command.as_deref(),
+            Some("dart run bin/server.dart")
+        );
+        assert_eq!(metadata.test_command.as_deref(), Some("dart test"));
+        assert_eq!(
+            metadata.test_files,
+            vec!["test/routes/notes_test.dart", "test/server_test.dart"]
+        );
+    }
+
+    #[test]
+    fn test_inspect_flutter_app() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::create_dir_all(dir.path().join("android")).unwrap();
+        std::fs::write(
+            dir.path().join("android/key.properties"),
+            "storePassword=x\n",
+        )
+        .unwrap();
+
+        let metadata = inspect_dart_project(
+            dir.path(),
+            "name: counter\ndependencies:\n  flutter:\n    sdk: flutter\n",
+            &RealFileSystem,
+        );
+        assert!(metadata.flutter);
+        assert_eq!(metadata.run_command.as_deref(), Some("flutter run"));
+        assert_eq!(
+            metadata.build_command.as_deref(),
+            Some("flutter build appbundle")
+        );
+        assert_eq!(metadata.test_command, None);
+    }
+}
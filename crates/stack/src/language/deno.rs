@@ -0,0 +1,282 @@
+//! TypeScript/JavaScript run by Deno (deno.json, deno.jsonc, deno.lock)
+
+use super::{Dependency, DependencyInfo, DetectionMethod, DetectionResult, LanguageDefinition};
+use crate::buildsystem::deno::{strip_jsonc, DenoConfig};
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::DenoMetadata;
+use std::path::Path;
+
+pub struct DenoLanguage;
+
+impl LanguageDefinition for DenoLanguage {
+    fn id(&self) -> crate::LanguageId {
+        crate::LanguageId::TypeScript
+    }
+
+    fn extensions(&self) -> Vec<String> {
+        vec![
+            "ts".to_string(),
+            "tsx".to_string(),
+            "js".to_string(),
+            "jsx".to_string(),
+            "mts".to_string(),
+        ]
+    }
+
+    fn detect(
+        &self,
+        manifest_name: &str,
+        manifest_content: Option<&str>,
+    ) -> Option<DetectionResult> {
+        let confidence = match manifest_name {
+            "deno.json" | "deno.jsonc" => 1.0,
+            "deno.lock" => 0.8,
+            // The file a deno.json `importMap` points at carries the dependencies
+            name if name.ends_with(".json") && manifest_content.is_some_and(is_import_map) => 0.9,
+            _ => return None,
+        };
+
+        Some(DetectionResult {
+            build_system: crate::BuildSystemId::Deno,
+            confidence,
+        })
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["deno".to_string()]
+    }
+
+    fn excluded_dirs(&self) -> Vec<String> {
+        vec![
+            ".deno".to_string(),
+            "node_modules".to_string(),
+            "_fresh".to_string(),
+        ]
+    }
+
+    fn parse_dependencies(
+        &self,
+        manifest_content: &str,
+        _all_internal_paths: &[std::path::PathBuf],
+    ) -> DependencyInfo {
+        // deno.json and import maps both keep their modules under `imports`
+        let imports = DenoConfig::parse(manifest_content).imports;
+
+        let mut external_deps: Vec<Dependency> = Vec::new();
+        for target in imports.values() {
+            let (name, version) = module_name(target);
+            if !external_deps.iter().any(|dep| dep.name == name) {
+                external_deps.push(Dependency {
+                    name,
+                    version,
+                    is_internal: false,
+                });
+            }
+        }
+
+        DependencyInfo {
+            internal_deps: vec![],
+            external_deps,
+            detected_by: DetectionMethod::Deterministic,
+        }
+    }
+
+    fn env_var_patterns(&self) -> Vec<(String, String)> {
+        vec![(
+            r#"Deno\.env\.get\(\s*["']([A-Z_][A-Z0-9_]*)["']"#.to_string(),
+            "Deno.env.get".to_string(),
+        )]
+    }
+
+    fn health_check_patterns(&self) -> Vec<(String, String)> {
+        vec![(
+            r#"\.get\(\s*["'](/[\w\-/]*health[\w\-]*)["']"#.to_string(),
+            "Oak/Hono router".to_string(),
+        )]
+    }
+
+    fn is_main_file(
+        &self,
+        _fs: &dyn peelbox_core::fs::FileSystem,
+        file_path: &std::path::Path,
+    ) -> bool {
+        file_path
+            .file_name()
+            .and_then(|f| f.to_str())
+            .is_some_and(|name| {
+                ["main.ts", "mod.ts", "server.ts", "main.js", "index.ts"].contains(&name)
+            })
+    }
+
+    fn port_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r"\bport\s*:\s*(\d{4,5})".to_string(),
+                "listen()/Deno.serve() port".to_string(),
+            ),
+            (
+                r#"Deno\.env\.get\(\s*["']PORT["']\s*\)\s*\?\?\s*["']?(\d{4,5})"#.to_string(),
+                "PORT fallback".to_string(),
+            ),
+        ]
+    }
+
+    fn runtime_name(&self) -> Option<String> {
+        Some("deno".to_string())
+    }
+
+    fn default_port(&self) -> Option<u16> {
+        Some(8000)
+    }
+}
+
+/// An import map holds nothing but `imports` and `scopes`
+fn is_import_map(content: &str) -> bool {
+    serde_json::from_str::<serde_json::Value>(&strip_jsonc(content))
+        .ok()
+        .and_then(|json| json.as_object().cloned())
+        .is_some_and(|map| {
+            map.contains_key("imports") && map.keys().all(|k| k == "imports" || k == "scopes")
+        })
+}
+
+/// Module identity and version of an import target:
+/// `jsr:@oak/oak@^17` is `jsr:@oak/oak`, `npm:hono@4` is `npm:hono` and
+/// `https://deno.land/x/oak@v12.6.1/mod.ts` is `deno.land/x/oak`
+fn module_name(target: &str) -> (String, Option<String>) {
+    for scheme in ["jsr:", "npm:"] {
+        if let Some(spec) = target.strip_prefix(scheme) {
+            let spec = spec.trim_start_matches('/');
+            // Skip the `@` that opens a scope
+            let split = spec
+                .char_indices()
+                .skip(1)
+                .find(|&(_, c)| c == '@')
+                .map(|(i, _)| i);
+            let (name, version) = match split {
+                Some(i) => {
+                    let version = spec[i + 1..].split('/').next().unwrap_or_default();
+                    (&spec[..i], Some(version.to_string()))
+                }
+                None => (spec, None),
+            };
+            // Drop any subpath after the package name
+            let depth = if name.starts_with('@') { 2 } else { 1 };
+            let name: Vec<&str> = name.split('/').take(depth).collect();
+            return (format!("{}{}", scheme, name.join("/")), version);
+        }
+    }
+
+    let url = target
+        .trim_start_matches("https://")
+        .trim_start_matches("http://");
+    let segments: Vec<&str> = url.split('/').filter(|s| !s.is_empty()).collect();
+    if let Some(pos) = segments.iter().position(|s| s.contains('@')) {
+        let (name, version) = segments[pos].split_once('@').unwrap_or((segments[pos], ""));
+        let mut parts: Vec<&str> = segments[..pos].to_vec();
+        parts.push(name);
+        let version = (!version.is_empty()).then(|| version.to_string());
+        return (parts.join("/"), version);
+    }
+
+    // Unversioned: deno.land/x/<module> or <host>/<package>
+    let depth = if segments.get(1) == Some(&"x") { 3 } else { 2 };
+    (
+        segments
+            .into_iter()
+            .take(depth)
+            .collect::<Vec<_>>()
+            .join("/"),
+        None,
+    )
+}
+
+/// Tasks, import map and imported modules of a Deno project
+pub fn inspect_deno_project(service_path: &Path, fs: &dyn FileSystem) -> Option<DenoMetadata> {
+    let config = DenoConfig::read(service_path, fs)?;
+    let dependencies = config.dependencies(service_path, fs);
+    Some(DenoMetadata {
+        run_command: config.tasks.get("start").cloned(),
+        build_command: config.tasks.get("build").cloned(),
+        import_map: config.import_map,
+        dependencies,
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+
+    #[test]
+    fn test_detect_manifests() {
+        assert_eq!(
+            DenoLanguage
+                .detect("deno.jsonc", None)
+                .unwrap()
+                .build_system,
+            crate::BuildSystemId::Deno
+        );
+        assert!(DenoLanguage
+            .detect("import_map.json", Some(r#"{"imports": {}}"#))
+            .is_some());
+        assert!(DenoLanguage
+            .detect("package.json", Some(r#"{"name": "x", "imports": {}}"#))
+            .is_none());
+    }
+
+    #[test]
+    fn test_module_names() {
+        assert_eq!(
+            module_name("https://deno.land/x/oak@v12.6.1/mod.ts"),
+            ("deno.land/x/oak".to_string(), Some("v12.6.1".to_string()))
+        );
+        assert_eq!(
+            module_name("https://deno.land/x/oak/"),
+            ("deno.land/x/oak".to_string(), None)
+        );
+        assert_eq!(
+            module_name("https://deno.land/std@0.208.0/"),
+            ("deno.land/std".to_string(), Some("0.208.0".to_string()))
+        );
+        assert_eq!(
+            module_name("jsr:@oak/oak@^17.1.0"),
+            ("jsr:@oak/oak".to_string(), Some("^17.1.0".to_string()))
+        );
+        assert_eq!(
+            module_name("npm:hono@4/jsx"),
+            ("npm:hono".to_string(), Some("4".to_string()))
+        );
+    }
+
+    #[test]
+    fn test_framework_dependencies_from_import_map() {
+        let deps = DenoLanguage.parse_dependencies(
+            r#"{"imports": {"$fresh/": "https://deno.land/x/fresh@1.6.8/", "preact": "https://esm.sh/preact@10.19.6"}}"#,
+            &[],
+        );
+        let names: Vec<&str> = deps.external_deps.iter().map(|d| d.name.as_str()).collect();
+        assert_eq!(names, vec!["deno.land/x/fresh", "esm.sh/preact"]);
+    }
+
+    #[test]
+    fn test_inspect_deno_project() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("deno.json"),
+            r#"{"tasks": {"start": "deno run -A main.ts"}, "importMap": "./import_map.json"}"#,
+        )
+        .unwrap();
+        std::fs::write(
+            dir.path().join("import_map.json"),
+            r#"{"imports": {"oak": "https://deno.land/x/oak@v12.6.1/mod.ts"}}"#,
+        )
+        .unwrap();
+
+        let metadata = inspect_deno_project(dir.path(), &RealFileSystem).unwrap();
+        assert_eq!(metadata.run_command.as_deref(), Some("deno run -A main.ts"));
+        assert_eq!(metadata.build_command, None);
+        assert_eq!(metadata.import_map.as_deref(), Some("./import_map.json"));
+        assert_eq!(metadata.dependencies.len(), 1);
+    }
+}
@@ -12,7 +12,7 @@ impl LanguageDefinition for GoLanguage {
     }
 
     fn extensions(&self) -> Vec<String> {
-        vec!["go".to_string()]
+        vec!["go".to_string(), "bazel".to_string()]
     }
 
     fn detect(
@@ -49,6 +49,20 @@ impl LanguageDefinition for GoLanguage {
         vec!["go.work".to_string()]
     }
 
+    fn is_workspace_root(&self, manifest_name: &str, manifest_content: Option<&str>) -> bool {
+        if manifest_name != "go.work" {
+            return false;
+        }
+
+        manifest_content
+            .map(|content| {
+                content
+                    .lines()
+                    .any(|line| line.trim_start().starts_with("use"))
+            })
+            .unwrap_or(false)
+    }
+
     fn detect_version(&self, manifest_content: Option<&str>) -> Option<String> {
         let content = manifest_content?;
 
@@ -150,6 +164,10 @@ impl LanguageDefinition for GoLanguage {
                 r#"os\.Getenv\(["']([A-Z_][A-Z0-9_]*)["']"#.to_string(),
                 "os.Getenv".to_string(),
             ),
+            (
+                r#"os\.LookupEnv\(["']([A-Z_][A-Z0-9_]*)["']"#.to_string(),
+                "os.LookupEnv".to_string(),
+            ),
             (
                 r#"viper\.GetString\(["']([A-Z_][A-Z0-9_]*)["']"#.to_string(),
                 "viper".to_string(),
@@ -167,14 +185,20 @@ impl LanguageDefinition for GoLanguage {
                 r#"http\.ListenAndServe\([^:)]*:(\d{4,5})"#.to_string(),
                 "http.ListenAndServe".to_string(),
             ),
+            (
+                r#"net\.Listen\("tcp",\s*"[^:"]*:(\d{4,5})""#.to_string(),
+                "net.Listen".to_string(),
+            ),
         ]
     }
 
     fn health_check_patterns(&self) -> Vec<(String, String)> {
-        vec![
-            ("/health".to_string(), "Gin".to_string()),
-            ("/healthz".to_string(), "Kubernetes".to_string()),
-        ]
+        // Route registrations of net/http, Gin, Echo, Chi, Gorilla Mux and Fiber
+        vec![(
+            r#"\.(?:HandleFunc|Handle|GET|Get|Any)\(\s*"(/(?:healthz?|ping|readyz|livez))""#
+                .to_string(),
+            "route".to_string(),
+        )]
     }
 
     fn default_env_vars(&self) -> Vec<String> {
@@ -296,7 +320,7 @@ mod tests {
     #[test]
     fn test_compatible_build_systems() {
         let lang = GoLanguage;
-        assert_eq!(lang.compatible_build_systems(), vec!["go".to_string()]);
+        assert_eq!(lang.compatible_build_systems(), vec!["go", "bazel"]);
     }
 
     #[test]
@@ -305,6 +329,14 @@ mod tests {
         assert!(lang.workspace_configs().iter().any(|s| s == "go.work"));
     }
 
+    #[test]
+    fn test_is_workspace_root() {
+        let lang = GoLanguage;
+        assert!(lang.is_workspace_root("go.work", Some("go 1.22\n\nuse ./api\n")));
+        assert!(!lang.is_workspace_root("go.mod", Some("module example.com/app\n")));
+        assert!(!lang.is_workspace_root("go.work", None));
+    }
+
     #[test]
     fn test_detect_version() {
         let lang = GoLanguage;
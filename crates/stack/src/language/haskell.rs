@@ -0,0 +1,272 @@
+//! Haskell language definition (Stack and Cabal)
+
+use super::{Dependency, DependencyInfo, DetectionMethod, DetectionResult, LanguageDefinition};
+use crate::buildsystem::cabal::HaskellPackage;
+use crate::buildsystem::stack::StackProject;
+use peelbox_core::fs::FileSystem;
+use peelbox_core::output::schema::HaskellMetadata;
+use regex::Regex;
+use std::path::Path;
+
+pub struct HaskellLanguage;
+
+impl LanguageDefinition for HaskellLanguage {
+    fn id(&self) -> crate::LanguageId {
+        crate::LanguageId::Haskell
+    }
+
+    fn extensions(&self) -> Vec<String> {
+        vec!["hs".to_string(), "lhs".to_string()]
+    }
+
+    fn detect(
+        &self,
+        manifest_name: &str,
+        manifest_content: Option<&str>,
+    ) -> Option<DetectionResult> {
+        // package.yaml (hpack) is where a Stack project lists its dependencies
+        let (build_system, marker) = match manifest_name {
+            "stack.yaml" => (crate::BuildSystemId::Stack, "resolver:"),
+            "package.yaml" => (crate::BuildSystemId::Stack, "dependencies:"),
+            name if name.ends_with(".cabal") => (crate::BuildSystemId::Cabal, "cabal-version:"),
+            _ => return None,
+        };
+
+        let mut confidence = 0.9;
+        if manifest_content.is_some_and(|c| c.contains(marker)) {
+            confidence = 1.0;
+        }
+
+        Some(DetectionResult {
+            build_system,
+            confidence,
+        })
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["stack".to_string(), "cabal".to_string()]
+    }
+
+    fn excluded_dirs(&self) -> Vec<String> {
+        vec![
+            ".stack-work".to_string(),
+            "dist-newstyle".to_string(),
+            "dist".to_string(),
+        ]
+    }
+
+    fn detect_version(&self, manifest_content: Option<&str>) -> Option<String> {
+        // stack.yaml `compiler: ghc-9.6.4` or .cabal `tested-with: GHC == 9.6.4`
+        let re =
+            Regex::new(r"(?mi)^(?:compiler:\s*ghc-|tested-with:\s*GHC\s*==\s*)([\d.]+)").ok()?;
+        re.captures(manifest_content?)
+            .map(|caps| caps[1].to_string())
+    }
+
+    fn parse_dependencies(
+        &self,
+        manifest_content: &str,
+        _all_internal_paths: &[std::path::PathBuf],
+    ) -> DependencyInfo {
+        // .cabal files are the only format with `build-depends`; anything else is hpack YAML
+        let package = if manifest_content.contains("build-depends") {
+            HaskellPackage::parse_cabal(manifest_content)
+        } else {
+            HaskellPackage::parse_hpack(manifest_content)
+        };
+
+        DependencyInfo {
+            internal_deps: vec![],
+            external_deps: package
+                .dependencies
+                .into_iter()
+                .map(|name| Dependency {
+                    name,
+                    version: None,
+                    is_internal: false,
+                })
+                .collect(),
+            detected_by: DetectionMethod::Deterministic,
+        }
+    }
+
+    fn env_var_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r#"getEnv\s+"([A-Z_][A-Z0-9_]*)""#.to_string(),
+                "getEnv".to_string(),
+            ),
+            (
+                r#"lookupEnv\s+"([A-Z_][A-Z0-9_]*)""#.to_string(),
+                "lookupEnv".to_string(),
+            ),
+        ]
+    }
+
+    fn port_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r"\b(?:run|scotty|warp)\s+(\d{4,5})\b".to_string(),
+                "Warp/Scotty port literal".to_string(),
+            ),
+            (
+                r"setPort\s+(\d{4,5})\b".to_string(),
+                "Warp setPort".to_string(),
+            ),
+        ]
+    }
+
+    fn health_check_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r#"\bget\s+"(/[\w\-]*health[\w\-]*)""#.to_string(),
+                "Scotty".to_string(),
+            ),
+            (
+                r"(?m)^\s*(/[\w\-]*health[\w\-]*)\s+[A-Z]\w*R\b".to_string(),
+                "Yesod parseRoutes".to_string(),
+            ),
+        ]
+    }
+
+    fn is_main_file(
+        &self,
+        fs: &dyn peelbox_core::fs::FileSystem,
+        file_path: &std::path::Path,
+    ) -> bool {
+        if !file_path.extension().is_some_and(|ext| ext == "hs") {
+            return false;
+        }
+
+        fs.read_to_string(file_path).is_ok_and(|content| {
+            content.contains("main :: IO ()") || content.contains("module Main ")
+        })
+    }
+
+    fn runtime_name(&self) -> Option<String> {
+        Some("haskell".to_string())
+    }
+
+    fn default_port(&self) -> Option<u16> {
+        Some(8080)
+    }
+}
+
+/// Stack resolver and packages, the executables the project defines and the commands to
+/// build and run the first one locally
+pub fn inspect_haskell_project(
+    service_path: &Path,
+    manifest_name: &str,
+    fs: &dyn FileSystem,
+) -> Option<HaskellMetadata> {
+    if manifest_name == "stack.yaml" {
+        let content = fs.read_to_string(&service_path.join(manifest_name)).ok()?;
+        let project = StackProject::parse(&content);
+        let executables = project.executables(service_path, fs);
+        return Some(HaskellMetadata {
+            resolver: project.resolver,
+            run_command: executables
+                .first()
+                .map(|exe| format!("stack exec -- {}", exe.name)),
+            packages: project.packages,
+            executables,
+            build_command: "stack build".to_string(),
+        });
+    }
+
+    let content = fs.read_to_string(&service_path.join(manifest_name)).ok()?;
+    let package = HaskellPackage::parse_cabal(&content);
+    Some(HaskellMetadata {
+        resolver: None,
+        packages: vec![],
+        run_command: package
+            .executables
+            .first()
+            .map(|exe| format!("cabal run {}", exe.name)),
+        executables: package.executables,
+        build_command: "cabal build".to_string(),
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+    use peelbox_core::output::schema::HaskellExecutable;
+
+    #[test]
+    fn test_detect_manifests() {
+        assert_eq!(
+            HaskellLanguage
+                .detect("stack.yaml", Some("resolver: lts-22.7\n"))
+                .unwrap()
+                .build_system,
+            crate::BuildSystemId::Stack
+        );
+        assert_eq!(
+            HaskellLanguage
+                .detect("greeter.cabal", None)
+                .unwrap()
+                .build_system,
+            crate::BuildSystemId::Cabal
+        );
+        assert!(HaskellLanguage.detect("cabal.project", None).is_none());
+    }
+
+    #[test]
+    fn test_framework_dependencies_from_hpack() {
+        let deps = HaskellLanguage.parse_dependencies(
+            "name: catalog\ndependencies:\n- base >= 4.7 && < 5\n- servant-server\n",
+            &[],
+        );
+        let names: Vec<&str> = deps.external_deps.iter().map(|d| d.name.as_str()).collect();
+        assert_eq!(names, vec!["base", "servant-server"]);
+    }
+
+    #[test]
+    fn test_inspect_stack_project() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("stack.yaml"),
+            "resolver: lts-22.7\npackages:\n- .\n",
+        )
+        .unwrap();
+        std::fs::write(
+            dir.path().join("package.yaml"),
+            "name: catalog\nexecutables:\n  catalog-exe:\n    main: Main.hs\n",
+        )
+        .unwrap();
+
+        let metadata = inspect_haskell_project(dir.path(), "stack.yaml", &RealFileSystem).unwrap();
+        assert_eq!(metadata.resolver.as_deref(), Some("lts-22.7"));
+        assert_eq!(metadata.packages, vec!["."]);
+        assert_eq!(
+            metadata.executables,
+            vec![HaskellExecutable {
+                name: "catalog-exe".to_string(),
+                main_is: Some("Main.hs".to_string()),
+            }]
+        );
+        assert_eq!(metadata.build_command, "stack build");
+        assert_eq!(
+            metadata.run_command.as_deref(),
+            Some("stack exec -- catalog-exe")
+        );
+    }
+
+    #[test]
+    fn test_inspect_cabal_project() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("greeter.cabal"),
+            "cabal-version: 3.0\nname: greeter\n\nexecutable greeter\n    main-is: Main.hs\n    build-depends: base, scotty\n",
+        )
+        .unwrap();
+
+        let metadata =
+            inspect_haskell_project(dir.path(), "greeter.cabal", &RealFileSystem).unwrap();
+        assert_eq!(metadata.resolver, None);
+        assert_eq!(metadata.build_command, "cabal build");
+        assert_eq!(metadata.run_command.as_deref(), Some("cabal run greeter"));
+    }
+}
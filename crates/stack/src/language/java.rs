@@ -58,7 +58,11 @@ impl LanguageDefinition for JavaLanguage {
     }
 
     fn compatible_build_systems(&self) -> Vec<String> {
-        vec!["maven".to_string(), "gradle".to_string()]
+        vec![
+            "maven".to_string(),
+            "gradle".to_string(),
+            "bazel".to_string(),
+        ]
     }
 
     fn excluded_dirs(&self) -> Vec<String> {
@@ -272,6 +276,19 @@ impl JavaLanguage {
             }
         }
 
+        // Parent POM (e.g., spring-boot-starter-parent) identifies the framework as well
+        let parent_re = Regex::new(r"<parent>\s*<groupId>([^<]+)</groupId>\s*<artifactId>([^<]+)</artifactId>(?:\s*<version>([^<]+)</version>)?").ok();
+        if let Some(caps) = parent_re.as_ref().and_then(|re| re.captures(content)) {
+            let name = format!("{}:{}", &caps[1], &caps[2]);
+            if seen.insert(name.clone()) {
+                external_deps.push(Dependency {
+                    name,
+                    version: caps.get(3).map(|v| v.as_str().to_string()),
+                    is_internal: false,
+                });
+            }
+        }
+
         let module_re = Regex::new(r"<module>([^<]+)</module>").ok();
         if let Some(ref re) = module_re {
             for caps in re.captures_iter(content) {
@@ -536,6 +553,27 @@ rootProject.name = "single-project"
         assert!(deps.external_deps.iter().any(|d| d.name.contains("h2")));
     }
 
+    #[test]
+    fn test_parse_dependencies_maven_parent() {
+        let lang = JavaLanguage;
+        let content = r#"
+<project>
+    <parent>
+        <groupId>org.springframework.boot</groupId>
+        <artifactId>spring-boot-starter-parent</artifactId>
+        <version>3.2.5</version>
+    </parent>
+</project>
+"#;
+        let deps = lang.parse_dependencies(content, &[]);
+
+        assert_eq!(deps.external_deps.len(), 1);
+        assert_eq!(
+            deps.external_deps[0].name,
+            "org.springframework.boot:spring-boot-starter-parent"
+        );
+    }
+
     #[test]
     fn test_parse_dependencies_maven_modules() {
         let lang = JavaLanguage;
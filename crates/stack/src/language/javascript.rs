@@ -6,6 +6,7 @@ use super::{
     parsers::{DependencyParser, JsonDependencyParser},
     DependencyInfo, DetectionResult, LanguageDefinition,
 };
+use peelbox_core::output::schema::NodeMetadata;
 use regex::Regex;
 
 pub struct JavaScriptLanguage;
@@ -34,7 +35,7 @@ impl LanguageDefinition for JavaScriptLanguage {
         manifest_content: Option<&str>,
     ) -> Option<DetectionResult> {
         match manifest_name {
-            "bun.lockb" => Some(DetectionResult {
+            "bun.lockb" | "bun.lock" | "bunfig.toml" => Some(DetectionResult {
                 build_system: crate::BuildSystemId::Bun,
                 confidence: 1.0,
             }),
@@ -190,10 +191,16 @@ impl LanguageDefinition for JavaScriptLanguage {
     }
 
     fn health_check_patterns(&self) -> Vec<(String, String)> {
-        vec![(
-            r#"app\.get\(['"]([/\w\-]*health[/\w\-]*)['"]"#.to_string(),
-            "Express".to_string(),
-        )]
+        vec![
+            (
+                r#"app\.get\(['"]([/\w\-]*health[/\w\-]*)['"]"#.to_string(),
+                "Express".to_string(),
+            ),
+            (
+                r#"\)\s*\.get\(\s*['"](/[\w\-]*health[\w\-]*)['"]"#.to_string(),
+                "Elysia chained route".to_string(),
+            ),
+        ]
     }
 
     fn is_main_file(
@@ -250,21 +257,36 @@ impl LanguageDefinition for JavaScriptLanguage {
     }
 
     fn parse_entrypoint_from_manifest(&self, manifest_content: &str) -> Option<String> {
-        let parsed: serde_json::Value = serde_json::from_str(manifest_content).ok()?;
+        let metadata = parse_node_metadata(manifest_content)?;
 
-        if let Some(main) = parsed.get("main").and_then(|v| v.as_str()) {
-            return Some(format!("node {}", main));
-        }
-
-        if let Some(scripts) = parsed.get("scripts") {
-            if let Some(start) = scripts.get("start").and_then(|v| v.as_str()) {
-                return Some(start.to_string());
-            }
-        }
-
-        None
+        metadata
+            .start_script
+            .or_else(|| metadata.entry_point.map(|main| format!("node {}", main)))
     }
 }
+
+/// Reads `scripts.start`/`build`/`dev`, the `main` (or `module`) entry point and the
+/// `packageManager` from package.json
+pub fn parse_node_metadata(manifest_content: &str) -> Option<NodeMetadata> {
+    let parsed: serde_json::Value = serde_json::from_str(manifest_content).ok()?;
+    let script = |name: &str| parsed["scripts"][name].as_str().map(String::from);
+
+    Some(NodeMetadata {
+        entry_point: parsed["main"]
+            .as_str()
+            .or_else(|| parsed["module"].as_str())
+            .map(String::from),
+        start_script: script("start"),
+        build_script: script("build"),
+        dev_script: script("dev"),
+        // "pnpm@9.1.0+sha512..." names the tool before the `@`
+        package_manager: parsed["packageManager"]
+            .as_str()
+            .and_then(|pm| pm.split('@').next())
+            .filter(|pm| !pm.is_empty())
+            .map(String::from),
+    })
+}
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -595,4 +617,37 @@ mod tests {
         assert_eq!(deps.internal_deps.len(), 0);
         assert_eq!(deps.detected_by, DetectionMethod::Deterministic);
     }
+
+    #[test]
+    fn test_parse_node_metadata() {
+        let content = r#"{
+            "name": "web",
+            "module": "dist/index.mjs",
+            "scripts": {"start": "node dist/index.mjs", "build": "tsc", "dev": "tsx watch src"}
+        }"#;
+        let metadata = parse_node_metadata(content).unwrap();
+
+        assert_eq!(metadata.entry_point.as_deref(), Some("dist/index.mjs"));
+        assert_eq!(
+            metadata.start_script.as_deref(),
+            Some("node dist/index.mjs")
+        );
+        assert_eq!(metadata.build_script.as_deref(), Some("tsc"));
+        assert_eq!(metadata.dev_script.as_deref(), Some("tsx watch src"));
+    }
+
+    #[test]
+    fn test_entrypoint_prefers_start_script() {
+        let lang = JavaScriptLanguage;
+        assert_eq!(
+            lang.parse_entrypoint_from_manifest(
+                r#"{"main": "index.js", "scripts": {"start": "node server.js"}}"#
+            ),
+            Some("node server.js".to_string())
+        );
+        assert_eq!(
+            lang.parse_entrypoint_from_manifest(r#"{"main": "index.js"}"#),
+            Some("node index.js".to_string())
+        );
+    }
 }
@@ -0,0 +1,280 @@
+//! Kotlin language definition (Gradle Kotlin DSL)
+//!
+//! Manifests are the same Gradle scripts Java reads, so dependency parsing and version
+//! detection delegate to [`JavaLanguage`].
+
+use super::{DependencyInfo, DetectionResult, JavaLanguage, LanguageDefinition};
+use peelbox_core::output::schema::KotlinMetadata;
+use regex::Regex;
+
+pub struct KotlinLanguage;
+
+impl LanguageDefinition for KotlinLanguage {
+    fn id(&self) -> crate::LanguageId {
+        crate::LanguageId::Kotlin
+    }
+
+    fn extensions(&self) -> Vec<String> {
+        vec!["kt".to_string(), "kts".to_string()]
+    }
+
+    fn detect(
+        &self,
+        manifest_name: &str,
+        manifest_content: Option<&str>,
+    ) -> Option<DetectionResult> {
+        match manifest_name {
+            "build.gradle.kts" | "settings.gradle.kts" => {
+                JavaLanguage.detect(manifest_name, manifest_content)
+            }
+            _ => None,
+        }
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["gradle".to_string(), "maven".to_string()]
+    }
+
+    fn excluded_dirs(&self) -> Vec<String> {
+        vec![
+            "build".to_string(),
+            ".gradle".to_string(),
+            ".kotlin".to_string(),
+        ]
+    }
+
+    fn workspace_configs(&self) -> Vec<String> {
+        vec!["settings.gradle.kts".to_string()]
+    }
+
+    fn detect_version(&self, manifest_content: Option<&str>) -> Option<String> {
+        JavaLanguage.detect_version(manifest_content)
+    }
+
+    fn is_workspace_root(&self, manifest_name: &str, manifest_content: Option<&str>) -> bool {
+        JavaLanguage.is_workspace_root(manifest_name, manifest_content)
+    }
+
+    fn parse_dependencies(
+        &self,
+        manifest_content: &str,
+        all_internal_paths: &[std::path::PathBuf],
+    ) -> DependencyInfo {
+        JavaLanguage.parse_dependencies(manifest_content, all_internal_paths)
+    }
+
+    fn env_var_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r#"System\.getenv\("([A-Z_][A-Z0-9_]*)"\)"#.to_string(),
+                "System.getenv".to_string(),
+            ),
+            (
+                r#"System\.getenv\(\)\["([A-Z_][A-Z0-9_]*)"\]"#.to_string(),
+                "System.getenv map".to_string(),
+            ),
+        ]
+    }
+
+    fn port_patterns(&self) -> Vec<(String, String)> {
+        let mut patterns = JavaLanguage.port_patterns();
+        patterns.push((
+            r#"embeddedServer\([^)]*port\s*=\s*(\d{4,5})"#.to_string(),
+            "Ktor embeddedServer".to_string(),
+        ));
+        patterns
+    }
+
+    fn health_check_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r#"@GetMapping\(['"]([/\w\-]*health[/\w\-]*)['"]"#.to_string(),
+                "Spring".to_string(),
+            ),
+            (
+                r#"\bget\("(/(?:healthz?|ping|readyz|livez))"\)"#.to_string(),
+                "Ktor".to_string(),
+            ),
+        ]
+    }
+
+    fn default_health_endpoints(&self) -> Vec<(String, String)> {
+        JavaLanguage.default_health_endpoints()
+    }
+
+    fn is_main_file(
+        &self,
+        fs: &dyn peelbox_core::fs::FileSystem,
+        file_path: &std::path::Path,
+    ) -> bool {
+        if file_path.extension().is_some_and(|ext| ext == "kt") {
+            if let Ok(content) = fs.read_to_string(file_path) {
+                return content.contains("fun main(");
+            }
+        }
+        false
+    }
+
+    fn runtime_name(&self) -> Option<String> {
+        // Runs on the JVM, so Java version pins apply
+        Some("java".to_string())
+    }
+
+    fn default_port(&self) -> Option<u16> {
+        Some(8080)
+    }
+
+    fn default_entrypoint(&self, build_system: &str) -> Option<String> {
+        JavaLanguage.default_entrypoint(build_system)
+    }
+}
+
+/// Reads the Kotlin plugin version, the local run task and Multiplatform targets from
+/// build.gradle.kts
+pub fn parse_kotlin_metadata(manifest_content: &str) -> KotlinMetadata {
+    // kotlin("jvm") version "1.9.23" or id("org.jetbrains.kotlin.jvm") version "1.9.23"
+    let plugin_re = Regex::new(
+        r#"(?:kotlin\(\s*"(jvm|multiplatform)"\s*\)|id\(\s*"org\.jetbrains\.kotlin\.(jvm|multiplatform)"\s*\))\s*version\s*"([^"]+)""#,
+    )
+    .expect("valid kotlin plugin regex");
+    let plugins: Vec<(String, String)> = plugin_re
+        .captures_iter(manifest_content)
+        .map(|caps| {
+            let kind = caps
+                .get(1)
+                .or_else(|| caps.get(2))
+                .map_or("", |m| m.as_str());
+            (kind.to_string(), caps[3].to_string())
+        })
+        .collect();
+
+    let multiplatform = plugins.iter().any(|(kind, _)| kind == "multiplatform")
+        || Regex::new(r#"kotlin\(\s*"multiplatform"\s*\)|org\.jetbrains\.kotlin\.multiplatform"#)
+            .expect("valid multiplatform regex")
+            .is_match(manifest_content);
+
+    // Ktor's `application` plugin runs the server; Spring Boot ships its own task
+    let run_command = if manifest_content.contains("io.ktor:ktor-server-core") {
+        Some("./gradlew run".to_string())
+    } else if manifest_content.contains("org.springframework.boot:spring-boot-starter-web") {
+        Some("./gradlew bootRun".to_string())
+    } else {
+        None
+    };
+
+    KotlinMetadata {
+        version: plugins.into_iter().next().map(|(_, version)| version),
+        multiplatform,
+        targets: if multiplatform {
+            multiplatform_targets(manifest_content)
+        } else {
+            vec![]
+        },
+        run_command,
+    }
+}
+
+/// Target declarations inside `kotlin { ... }`, e.g. `jvm()`, `js(IR) { ... }`, `iosArm64()`
+fn multiplatform_targets(manifest_content: &str) -> Vec<String> {
+    let target_re = Regex::new(
+        r"(?m)^\s*(jvm|js|wasmJs|wasmWasi|androidTarget|android|(?:ios|macos|tvos|watchos|linux|mingw|androidNative)[A-Z]\w*)\s*[({]",
+    )
+    .expect("valid target regex");
+
+    let mut targets: Vec<String> = Vec::new();
+    for caps in target_re.captures_iter(manifest_content) {
+        let name = &caps[1];
+        // Source sets (`linuxX64Main { ... }`) look like targets
+        if name.ends_with("Main") || name.ends_with("Test") {
+            continue;
+        }
+        if !targets.iter().any(|t| t == name) {
+            targets.push(name.to_string());
+        }
+    }
+    targets
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_detect_gradle_kts_only() {
+        assert_eq!(
+            KotlinLanguage
+                .detect("build.gradle.kts", Some("plugins {}"))
+                .unwrap()
+                .build_system,
+            crate::BuildSystemId::Gradle
+        );
+        assert!(KotlinLanguage.detect("build.gradle", None).is_none());
+        assert!(KotlinLanguage.detect("pom.xml", None).is_none());
+    }
+
+    #[test]
+    fn test_ktor_metadata() {
+        let metadata = parse_kotlin_metadata(
+            r#"plugins {
+    kotlin("jvm") version "1.9.23"
+    id("io.ktor.plugin") version "2.3.10"
+}
+
+dependencies {
+    implementation("io.ktor:ktor-server-core-jvm")
+    implementation("io.ktor:ktor-server-netty-jvm")
+}
+"#,
+        );
+        assert_eq!(metadata.version.as_deref(), Some("1.9.23"));
+        assert_eq!(metadata.run_command.as_deref(), Some("./gradlew run"));
+        assert!(!metadata.multiplatform);
+        assert!(metadata.targets.is_empty());
+    }
+
+    #[test]
+    fn test_spring_boot_metadata() {
+        let metadata = parse_kotlin_metadata(
+            r#"plugins {
+    id("org.jetbrains.kotlin.jvm") version "2.0.0"
+    id("org.springframework.boot") version "3.3.0"
+}
+
+dependencies {
+    implementation("org.springframework.boot:spring-boot-starter-web")
+}
+"#,
+        );
+        assert_eq!(metadata.version.as_deref(), Some("2.0.0"));
+        assert_eq!(metadata.run_command.as_deref(), Some("./gradlew bootRun"));
+    }
+
+    #[test]
+    fn test_multiplatform_targets() {
+        let metadata = parse_kotlin_metadata(
+            r#"plugins {
+    kotlin("multiplatform") version "1.9.23"
+}
+
+kotlin {
+    jvm()
+    js(IR) {
+        browser()
+    }
+    linuxX64()
+    iosArm64()
+
+    sourceSets {
+        val jvmMain by getting
+        linuxX64Main {
+        }
+    }
+}
+"#,
+        );
+        assert!(metadata.multiplatform);
+        assert_eq!(metadata.version.as_deref(), Some("1.9.23"));
+        assert_eq!(metadata.targets, vec!["jvm", "js", "linuxX64", "iosArm64"]);
+        assert_eq!(metadata.run_command, None);
+    }
+}
@@ -1,27 +1,39 @@
 mod cpp;
+mod dart;
+mod deno;
 mod dotnet;
 mod elixir;
 mod go;
+mod haskell;
 mod java;
 mod javascript;
+mod kotlin;
 pub mod llm;
 pub mod parsers;
 mod php;
 mod python;
 mod ruby;
 mod rust;
+mod scala;
+mod swift;
 
 pub use cpp::CppLanguage;
+pub use dart::{inspect_dart_project, DartLanguage};
+pub use deno::{inspect_deno_project, DenoLanguage};
 pub use dotnet::DotNetLanguage;
 pub use elixir::ElixirLanguage;
 pub use go::GoLanguage;
+pub use haskell::{inspect_haskell_project, HaskellLanguage};
 pub use java::JavaLanguage;
-pub use javascript::JavaScriptLanguage;
+pub use javascript::{parse_node_metadata, JavaScriptLanguage};
+pub use kotlin::{parse_kotlin_metadata, KotlinLanguage};
 pub use llm::LLMLanguage;
 pub use php::PhpLanguage;
 pub use python::PythonLanguage;
 pub use ruby::RubyLanguage;
 pub use rust::RustLanguage;
+pub use scala::{parse_scala_metadata, ScalaLanguage};
+pub use swift::SwiftLanguage;
 
 pub trait LanguageDefinition: Send + Sync {
     fn id(&self) -> crate::LanguageId;
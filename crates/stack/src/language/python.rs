@@ -1,9 +1,10 @@
-//! Python language definition (pip, poetry, pipenv)
+//! Python language definition (pip, poetry, pipenv, pdm, hatch, conda)
 
 use super::{
     parsers::{DependencyParser, RegexDependencyParser},
     Dependency, DependencyInfo, DetectionMethod, DetectionResult, LanguageDefinition,
 };
+use crate::buildsystem::{CondaEnvironment, PyprojectDetector, PyprojectTool};
 use regex::Regex;
 use std::collections::HashSet;
 
@@ -29,11 +30,14 @@ impl LanguageDefinition for PythonLanguage {
                 let mut confidence = 0.85;
 
                 if let Some(content) = manifest_content {
-                    if content.contains("[tool.poetry]") {
-                        build_system = crate::BuildSystemId::Poetry;
-                        confidence = 1.0;
-                    } else if content.contains("[project]") {
-                        confidence = 0.9;
+                    match PyprojectDetector::tool(content) {
+                        Some(PyprojectTool::Setuptools) => confidence = 0.9,
+                        Some(tool) => {
+                            build_system = tool.build_system();
+                            confidence = 1.0;
+                        }
+                        None if content.contains("[project]") => confidence = 0.9,
+                        None => {}
                     }
                 }
 
@@ -42,6 +46,10 @@ impl LanguageDefinition for PythonLanguage {
                     confidence,
                 })
             }
+            "environment.yml" | "environment.yaml" => Some(DetectionResult {
+                build_system: crate::BuildSystemId::Conda,
+                confidence: 1.0,
+            }),
             "Pipfile" => Some(DetectionResult {
                 build_system: crate::BuildSystemId::Pipenv,
                 confidence: 1.0,
@@ -74,6 +82,10 @@ impl LanguageDefinition for PythonLanguage {
             "pip".to_string(),
             "poetry".to_string(),
             "pipenv".to_string(),
+            "pdm".to_string(),
+            "hatch".to_string(),
+            "conda".to_string(),
+            "bazel".to_string(),
         ]
     }
 
@@ -106,6 +118,14 @@ impl LanguageDefinition for PythonLanguage {
             return Some(caps.get(1)?.as_str().to_string());
         }
 
+        // environment.yml: - python=3.11
+        if let Some(caps) = Regex::new(r"(?m)^\s*-\s*python\s*[=<>~]+\s*(\d+\.\d+)")
+            .ok()
+            .and_then(|re| re.captures(content))
+        {
+            return Some(caps.get(1)?.as_str().to_string());
+        }
+
         // Pipfile: python_version = "3.11"
         if let Some(caps) = Regex::new(r#"python_version\s*=\s*"(\d+\.\d+)""#)
             .ok()
@@ -132,8 +152,15 @@ impl LanguageDefinition for PythonLanguage {
         manifest_content: &str,
         _all_internal_paths: &[std::path::PathBuf],
     ) -> DependencyInfo {
-        if manifest_content.contains("[tool.poetry") {
+        if Regex::new(r"(?m)^dependencies:")
+            .expect("valid regex")
+            .is_match(manifest_content)
+        {
+            self.parse_conda_dependencies(manifest_content)
+        } else if manifest_content.contains("[tool.poetry") {
             self.parse_poetry_dependencies(manifest_content)
+        } else if manifest_content.contains("[project]") {
+            self.parse_pep621_dependencies(manifest_content)
         } else if !manifest_content.contains('[') {
             self.parse_requirements_txt(manifest_content)
         } else {
@@ -249,6 +276,75 @@ impl PythonLanguage {
         }
     }
 
+    /// Parses PEP 621 `[project] dependencies` (used by pdm, hatch, setuptools)
+    fn parse_pep621_dependencies(&self, content: &str) -> DependencyInfo {
+        let parsed: toml::Value = match toml::from_str(content) {
+            Ok(v) => v,
+            Err(_) => return DependencyInfo::empty(),
+        };
+
+        let spec_re =
+            Regex::new(r"^([A-Za-z0-9_.-]+)(?:\[[^\]]*\])?\s*(.*)$").expect("valid regex");
+        let mut external_deps = Vec::new();
+        let mut seen = HashSet::new();
+
+        let requirements = parsed
+            .get("project")
+            .and_then(|p| p.get("dependencies"))
+            .and_then(|d| d.as_array())
+            .into_iter()
+            .flatten()
+            .filter_map(|v| v.as_str());
+
+        for requirement in requirements {
+            let spec = requirement.split(';').next().unwrap_or("").trim();
+            if let Some(caps) = spec_re.captures(spec) {
+                let name = caps[1].to_string();
+                if !seen.insert(name.clone()) {
+                    continue;
+                }
+                let version = caps
+                    .get(2)
+                    .map(|m| m.as_str().trim())
+                    .filter(|v| !v.is_empty())
+                    .map(String::from);
+
+                external_deps.push(Dependency {
+                    name,
+                    version,
+                    is_internal: false,
+                });
+            }
+        }
+
+        DependencyInfo {
+            internal_deps: vec![],
+            external_deps,
+            detected_by: DetectionMethod::Deterministic,
+        }
+    }
+
+    /// Parses environment.yml `dependencies`, including its nested pip list
+    fn parse_conda_dependencies(&self, content: &str) -> DependencyInfo {
+        let environment = CondaEnvironment::parse(content);
+        let mut seen = HashSet::new();
+        let external_deps = environment
+            .packages()
+            .filter(|name| !matches!(*name, "python" | "pip") && seen.insert(name.to_string()))
+            .map(|name| Dependency {
+                name: name.to_string(),
+                version: None,
+                is_internal: false,
+            })
+            .collect();
+
+        DependencyInfo {
+            internal_deps: vec![],
+            external_deps,
+            detected_by: DetectionMethod::Deterministic,
+        }
+    }
+
     fn parse_requirements_txt(&self, content: &str) -> DependencyInfo {
         let dep_re = Regex::new(r"^([a-zA-Z0-9_-]+)(?:==|>=|<=|~=|!=)?([^\s#]*)").unwrap();
         RegexDependencyParser {
@@ -317,6 +413,37 @@ version = "0.1.0"
         assert_eq!(r.build_system, crate::BuildSystemId::Pip);
     }
 
+    #[test]
+    fn test_detect_pyproject_pdm() {
+        let lang = PythonLanguage;
+        let content = r#"
+[project]
+name = "myapp"
+
+[tool.pdm]
+distribution = false
+"#;
+        let r = lang.detect("pyproject.toml", Some(content)).unwrap();
+        assert_eq!(r.build_system, crate::BuildSystemId::Pdm);
+        assert_eq!(r.confidence, 1.0);
+    }
+
+    #[test]
+    fn test_detect_pyproject_hatch() {
+        let lang = PythonLanguage;
+        let content = r#"
+[project]
+name = "myapp"
+
+[build-system]
+requires = ["hatchling"]
+build-backend = "hatchling.build"
+"#;
+        let r = lang.detect("pyproject.toml", Some(content)).unwrap();
+        assert_eq!(r.build_system, crate::BuildSystemId::Hatch);
+        assert_eq!(r.confidence, 1.0);
+    }
+
     #[test]
     fn test_compatible_build_systems() {
         let lang = PythonLanguage;
@@ -324,6 +451,22 @@ version = "0.1.0"
         assert!(systems.iter().any(|s| s == "pip"));
         assert!(systems.iter().any(|s| s == "poetry"));
         assert!(systems.iter().any(|s| s == "pipenv"));
+        assert!(systems.iter().any(|s| s == "pdm"));
+        assert!(systems.iter().any(|s| s == "hatch"));
+        assert!(systems.iter().any(|s| s == "conda"));
+    }
+
+    #[test]
+    fn test_conda_environment() {
+        let lang = PythonLanguage;
+        let content = "name: api\ndependencies:\n  - python=3.11\n  - pip:\n      - fastapi\n";
+        let r = lang.detect("environment.yml", Some(content)).unwrap();
+        assert_eq!(r.build_system, crate::BuildSystemId::Conda);
+        assert_eq!(lang.detect_version(Some(content)), Some("3.11".to_string()));
+
+        let deps = lang.parse_dependencies(content, &[]);
+        let names: Vec<&str> = deps.external_deps.iter().map(|d| d.name.as_str()).collect();
+        assert_eq!(names, vec!["fastapi"]);
     }
 
     #[test]
@@ -416,4 +559,32 @@ flask = "^2.3.0"
         assert_eq!(deps.external_deps.len(), 1);
         assert!(deps.external_deps.iter().all(|d| d.name != "python"));
     }
+
+    #[test]
+    fn test_parse_dependencies_pep621() {
+        let lang = PythonLanguage;
+        let content = r#"
+[project]
+name = "myapp"
+dependencies = [
+    "starlette>=0.37",
+    "uvicorn[standard]==0.29.0",
+    "tomli; python_version < '3.11'",
+]
+"#;
+        let deps = lang.parse_dependencies(content, &[]);
+
+        assert_eq!(deps.external_deps.len(), 3);
+        let starlette = deps
+            .external_deps
+            .iter()
+            .find(|d| d.name == "starlette")
+            .unwrap();
+        assert_eq!(starlette.version.as_deref(), Some(">=0.37"));
+        assert!(deps.external_deps.iter().any(|d| d.name == "uvicorn"));
+        assert!(deps
+            .external_deps
+            .iter()
+            .any(|d| d.name == "tomli" && d.version.is_none()));
+    }
 }
@@ -0,0 +1,313 @@
+//! Scala language definition (sbt and Mill)
+
+use super::{Dependency, DependencyInfo, DetectionMethod, DetectionResult, LanguageDefinition};
+use crate::buildsystem::mill::main_module;
+use peelbox_core::output::schema::ScalaMetadata;
+use regex::Regex;
+use std::collections::HashSet;
+
+pub struct ScalaLanguage;
+
+impl LanguageDefinition for ScalaLanguage {
+    fn id(&self) -> crate::LanguageId {
+        crate::LanguageId::Scala
+    }
+
+    fn extensions(&self) -> Vec<String> {
+        vec!["scala".to_string(), "sc".to_string()]
+    }
+
+    fn detect(
+        &self,
+        manifest_name: &str,
+        manifest_content: Option<&str>,
+    ) -> Option<DetectionResult> {
+        let (build_system, marker) = match manifest_name {
+            "build.sbt" => (crate::BuildSystemId::Sbt, ":="),
+            "build.sc" => (crate::BuildSystemId::Mill, "extends"),
+            _ => return None,
+        };
+
+        let mut confidence = 0.9;
+        if manifest_content.is_some_and(|c| c.contains(marker)) {
+            confidence = 1.0;
+        }
+
+        Some(DetectionResult {
+            build_system,
+            confidence,
+        })
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["sbt".to_string(), "mill".to_string()]
+    }
+
+    fn excluded_dirs(&self) -> Vec<String> {
+        vec![
+            "target".to_string(),
+            "project/target".to_string(),
+            "out".to_string(),
+            ".bsp".to_string(),
+            ".bloop".to_string(),
+            ".metals".to_string(),
+        ]
+    }
+
+    fn detect_version(&self, manifest_content: Option<&str>) -> Option<String> {
+        scala_version(manifest_content?)
+    }
+
+    fn parse_dependencies(
+        &self,
+        manifest_content: &str,
+        _all_internal_paths: &[std::path::PathBuf],
+    ) -> DependencyInfo {
+        let mut external_deps = Vec::new();
+        let mut seen = HashSet::new();
+        let mut push = |name: String, version: Option<String>| {
+            if seen.insert(name.clone()) {
+                external_deps.push(Dependency {
+                    name,
+                    version,
+                    is_internal: false,
+                });
+            }
+        };
+
+        // sbt: "org.http4s" %% "http4s-dsl" % "0.23.27" (versions held in vals stay unknown)
+        let sbt_re = Regex::new(r#""([^"\s]+)"\s*%%?%?\s*"([^"\s]+)"(?:\s*%\s*"([^"]+)")?"#)
+            .expect("valid sbt dependency regex");
+        for caps in sbt_re.captures_iter(manifest_content) {
+            push(
+                format!("{}:{}", &caps[1], &caps[2]),
+                caps.get(3).map(|v| v.as_str().to_string()),
+            );
+        }
+
+        // Mill: ivy"com.lihaoyi::cask:0.9.2"
+        let ivy_re = Regex::new(r#"ivy"([^":]+)::?:?([^":]+):([^"]+)""#).expect("valid ivy regex");
+        for caps in ivy_re.captures_iter(manifest_content) {
+            push(
+                format!("{}:{}", &caps[1], &caps[2]),
+                Some(caps[3].to_string()),
+            );
+        }
+
+        // Auto-plugins the build enables identify frameworks that ship as sbt plugins (Play)
+        let plugins_re = Regex::new(r"enablePlugins\(([^)]*)\)").expect("valid plugins regex");
+        for caps in plugins_re.captures_iter(manifest_content) {
+            for plugin in caps[1].split(',').map(str::trim).filter(|p| !p.is_empty()) {
+                push(plugin.to_string(), None);
+            }
+        }
+
+        DependencyInfo {
+            internal_deps: vec![],
+            external_deps,
+            detected_by: DetectionMethod::Deterministic,
+        }
+    }
+
+    fn env_var_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r#"sys\.env\.get\("([A-Z_][A-Z0-9_]*)"\)"#.to_string(),
+                "sys.env.get".to_string(),
+            ),
+            (
+                r#"sys\.env\("([A-Z_][A-Z0-9_]*)"\)"#.to_string(),
+                "sys.env".to_string(),
+            ),
+            (
+                r#"System\.getenv\("([A-Z_][A-Z0-9_]*)"\)"#.to_string(),
+                "System.getenv".to_string(),
+            ),
+        ]
+    }
+
+    fn port_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r#"port"(\d{4,5})""#.to_string(),
+                "ip4s port literal".to_string(),
+            ),
+            (
+                r#"(?:newServerAt|bind(?:AndHandle)?)\([^)]*?(\d{4,5})\)"#.to_string(),
+                "Akka HTTP bind".to_string(),
+            ),
+        ]
+    }
+
+    fn health_check_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r#"(?m)^GET\s+(/[\w\-]*health[\w\-]*)\s"#.to_string(),
+                "Play routes".to_string(),
+            ),
+            (
+                r#"Root\s*/\s*"(healthz?)""#.to_string(),
+                "http4s".to_string(),
+            ),
+            (
+                r#"path\("(healthz?)"\)"#.to_string(),
+                "Akka HTTP".to_string(),
+            ),
+        ]
+    }
+
+    fn is_main_file(
+        &self,
+        fs: &dyn peelbox_core::fs::FileSystem,
+        file_path: &std::path::Path,
+    ) -> bool {
+        if !file_path.extension().is_some_and(|ext| ext == "scala") {
+            return false;
+        }
+
+        fs.read_to_string(file_path).is_ok_and(|content| {
+            content.contains("def main(")
+                || content.contains("extends App")
+                || content.contains("extends IOApp")
+                || content.contains("@main")
+        })
+    }
+
+    fn runtime_name(&self) -> Option<String> {
+        Some("java".to_string())
+    }
+
+    fn default_port(&self) -> Option<u16> {
+        Some(8080)
+    }
+
+    fn default_entrypoint(&self, _build_system: &str) -> Option<String> {
+        Some("java -jar app.jar".to_string())
+    }
+}
+
+/// `scalaVersion := "3.3.3"` (sbt, optionally scoped to `ThisBuild`) or
+/// `def scalaVersion = "3.3.3"` (Mill)
+fn scala_version(content: &str) -> Option<String> {
+    let re = Regex::new(r#"scalaVersion\s*(?::=|=)\s*"([^"]+)""#).ok()?;
+    re.captures(content).map(|caps| caps[1].to_string())
+}
+
+/// Commands for working on the project locally, read from build.sbt or build.sc
+pub fn parse_scala_metadata(manifest_name: &str, manifest_content: &str) -> ScalaMetadata {
+    let (build_command, run_command) = match manifest_name {
+        "build.sc" => (
+            None,
+            main_module(manifest_content).map(|module| format!("mill {}.run", module)),
+        ),
+        _ => {
+            let deps = ScalaLanguage.parse_dependencies(manifest_content, &[]);
+            let has = |prefix: &str| {
+                deps.external_deps
+                    .iter()
+                    .any(|dep| dep.name.starts_with(prefix))
+            };
+            if has("PlayScala") || has("PlayJava") {
+                // `dist` packages the production zip; `run` starts the dev server
+                (Some("sbt dist".to_string()), Some("sbt run".to_string()))
+            } else if has("com.typesafe.akka:akka-http") || has("org.http4s:http4s-") {
+                (None, Some("sbt run".to_string()))
+            } else {
+                (None, None)
+            }
+        }
+    };
+
+    ScalaMetadata {
+        version: scala_version(manifest_content),
+        build_command,
+        run_command,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const PLAY_SBT: &str = r#"name := """storefront"""
+version := "1.0-SNAPSHOT"
+
+lazy val root = (project in file(".")).enablePlugins(PlayScala)
+
+scalaVersion := "2.13.14"
+
+libraryDependencies += guice
+libraryDependencies += "org.scalatestplus.play" %% "scalatestplus-play" % "7.0.1" % Test
+"#;
+
+    const MILL: &str = r#"import mill._, scalalib._
+
+object api extends ScalaModule {
+  def scalaVersion = "3.3.3"
+  def ivyDeps = Agg(ivy"com.lihaoyi::cask:0.9.2")
+  def mainClass = Some("api.Main")
+
+  object test extends ScalaTests with TestModule.Munit
+}
+"#;
+
+    #[test]
+    fn test_detect_build_files() {
+        assert_eq!(
+            ScalaLanguage
+                .detect("build.sbt", None)
+                .unwrap()
+                .build_system,
+            crate::BuildSystemId::Sbt
+        );
+        assert_eq!(
+            ScalaLanguage
+                .detect("build.sc", Some(MILL))
+                .unwrap()
+                .confidence,
+            1.0
+        );
+        assert!(ScalaLanguage.detect("build.gradle", None).is_none());
+    }
+
+    #[test]
+    fn test_parse_dependencies() {
+        let deps = ScalaLanguage.parse_dependencies(PLAY_SBT, &[]);
+        let names: Vec<&str> = deps.external_deps.iter().map(|d| d.name.as_str()).collect();
+        assert_eq!(
+            names,
+            vec!["org.scalatestplus.play:scalatestplus-play", "PlayScala"]
+        );
+        assert_eq!(deps.external_deps[0].version.as_deref(), Some("7.0.1"));
+
+        let mill = ScalaLanguage.parse_dependencies(MILL, &[]);
+        assert_eq!(mill.external_deps[0].name, "com.lihaoyi:cask");
+        assert_eq!(mill.external_deps[0].version.as_deref(), Some("0.9.2"));
+    }
+
+    #[test]
+    fn test_play_metadata() {
+        let metadata = parse_scala_metadata("build.sbt", PLAY_SBT);
+        assert_eq!(metadata.version.as_deref(), Some("2.13.14"));
+        assert_eq!(metadata.build_command.as_deref(), Some("sbt dist"));
+        assert_eq!(metadata.run_command.as_deref(), Some("sbt run"));
+    }
+
+    #[test]
+    fn test_http4s_metadata() {
+        let metadata = parse_scala_metadata(
+            "build.sbt",
+            "ThisBuild / scalaVersion := \"3.3.3\"\nlibraryDependencies += \"org.http4s\" %% \"http4s-ember-server\" % http4sVersion\n",
+        );
+        assert_eq!(metadata.version.as_deref(), Some("3.3.3"));
+        assert_eq!(metadata.build_command, None);
+        assert_eq!(metadata.run_command.as_deref(), Some("sbt run"));
+    }
+
+    #[test]
+    fn test_mill_metadata() {
+        let metadata = parse_scala_metadata("build.sc", MILL);
+        assert_eq!(metadata.version.as_deref(), Some("3.3.3"));
+        assert_eq!(metadata.run_command.as_deref(), Some("mill api.run"));
+    }
+}
@@ -0,0 +1,234 @@
+//! Swift language definition
+
+use super::{Dependency, DependencyInfo, DetectionMethod, DetectionResult, LanguageDefinition};
+use regex::Regex;
+
+pub struct SwiftLanguage;
+
+impl LanguageDefinition for SwiftLanguage {
+    fn id(&self) -> crate::LanguageId {
+        crate::LanguageId::Swift
+    }
+
+    fn extensions(&self) -> Vec<String> {
+        vec!["swift".to_string()]
+    }
+
+    fn detect(
+        &self,
+        manifest_name: &str,
+        manifest_content: Option<&str>,
+    ) -> Option<DetectionResult> {
+        if manifest_name != "Package.swift" {
+            return None;
+        }
+
+        let mut confidence = 0.9;
+        if let Some(content) = manifest_content {
+            if content.contains("PackageDescription") {
+                confidence = 1.0;
+            }
+        }
+
+        Some(DetectionResult {
+            build_system: crate::BuildSystemId::SwiftPm,
+            confidence,
+        })
+    }
+
+    fn compatible_build_systems(&self) -> Vec<String> {
+        vec!["swiftpm".to_string()]
+    }
+
+    fn excluded_dirs(&self) -> Vec<String> {
+        vec![".build".to_string(), ".swiftpm".to_string()]
+    }
+
+    fn detect_version(&self, manifest_content: Option<&str>) -> Option<String> {
+        // First line of Package.swift: // swift-tools-version:5.9 (or "version: 5.9")
+        let re = Regex::new(r"//\s*swift-tools-version\s*:\s*(\d+\.\d+)").ok()?;
+        re.captures(manifest_content?)
+            .and_then(|caps| caps.get(1))
+            .map(|m| m.as_str().to_string())
+    }
+
+    fn parse_dependencies(
+        &self,
+        manifest_content: &str,
+        all_internal_paths: &[std::path::PathBuf],
+    ) -> DependencyInfo {
+        let mut internal_deps = Vec::new();
+        let mut external_deps = Vec::new();
+
+        // .package(url: "https://github.com/vapor/vapor.git", from: "4.89.0")
+        if let Ok(re) = Regex::new(
+            r#"\.package\(\s*url:\s*"([^"]+)"\s*(?:,\s*(?:from|exact|branch|revision):\s*"([^"]+)")?"#,
+        ) {
+            for cap in re.captures_iter(manifest_content) {
+                let url = cap[1].trim_end_matches('/').trim_end_matches(".git");
+                // owner/repo identifies the package, e.g. "vapor/vapor"
+                let mut segments = url.rsplit('/');
+                let repo = segments.next().unwrap_or(url);
+                let name = match segments.next() {
+                    Some(owner) => format!("{}/{}", owner, repo),
+                    None => repo.to_string(),
+                };
+
+                external_deps.push(Dependency {
+                    name,
+                    version: cap.get(2).map(|v| v.as_str().to_string()),
+                    is_internal: false,
+                });
+            }
+        }
+
+        // .package(path: "../Shared")
+        if let Ok(re) = Regex::new(r#"\.package\(\s*(?:name:\s*"[^"]+"\s*,\s*)?path:\s*"([^"]+)""#)
+        {
+            for cap in re.captures_iter(manifest_content) {
+                let path_str = &cap[1];
+                let name = path_str
+                    .trim_end_matches('/')
+                    .rsplit('/')
+                    .next()
+                    .unwrap_or(path_str)
+                    .to_string();
+                let is_internal = all_internal_paths
+                    .iter()
+                    .any(|p| p.to_str().is_some_and(|s| s.contains(path_str)));
+
+                let dep = Dependency {
+                    name,
+                    version: None,
+                    is_internal,
+                };
+                if is_internal {
+                    internal_deps.push(dep);
+                } else {
+                    external_deps.push(dep);
+                }
+            }
+        }
+
+        DependencyInfo {
+            internal_deps,
+            external_deps,
+            detected_by: DetectionMethod::Deterministic,
+        }
+    }
+
+    fn env_var_patterns(&self) -> Vec<(String, String)> {
+        vec![
+            (
+                r#"Environment\.get\("([A-Z_][A-Z0-9_]*)"\)"#.to_string(),
+                "Vapor Environment".to_string(),
+            ),
+            (
+                r#"ProcessInfo\.processInfo\.environment\["([A-Z_][A-Z0-9_]*)"\]"#.to_string(),
+                "ProcessInfo".to_string(),
+            ),
+        ]
+    }
+
+    fn port_patterns(&self) -> Vec<(String, String)> {
+        vec![(
+            r#"port:\s*(\d{4,5})"#.to_string(),
+            "configuration".to_string(),
+        )]
+    }
+
+    fn health_check_patterns(&self) -> Vec<(String, String)> {
+        vec![(
+            r#"\.get\("([\w\-]*health[\w\-]*)"\)"#.to_string(),
+            "Vapor/Hummingbird".to_string(),
+        )]
+    }
+
+    fn is_main_file(
+        &self,
+        fs: &dyn peelbox_core::fs::FileSystem,
+        file_path: &std::path::Path,
+    ) -> bool {
+        if let Some(file_name) = file_path.file_name().and_then(|n| n.to_str()) {
+            if file_name == "main.swift" || file_name == "entrypoint.swift" {
+                return true;
+            }
+        }
+
+        if let Ok(content) = fs.read_to_string(file_path) {
+            if content.contains("@main") {
+                return true;
+            }
+        }
+
+        false
+    }
+
+    fn runtime_name(&self) -> Option<String> {
+        Some("swift".to_string())
+    }
+
+    fn default_port(&self) -> Option<u16> {
+        Some(8080)
+    }
+
+    fn default_entrypoint(&self, _build_system: &str) -> Option<String> {
+        Some("./.build/release/app".to_string())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const VAPOR_PACKAGE: &str = r#"// swift-tools-version:5.9
+import PackageDescription
+
+let package = Package(
+    name: "todos",
+    dependencies: [
+        .package(url: "https://github.com/vapor/vapor.git", from: "4.89.0"),
+        .package(path: "../Shared"),
+    ],
+    targets: [
+        .executableTarget(name: "App", dependencies: [.product(name: "Vapor", package: "vapor")]),
+    ]
+)
+"#;
+
+    #[test]
+    fn test_detect_package_swift() {
+        let lang = SwiftLanguage;
+        let result = lang.detect("Package.swift", Some(VAPOR_PACKAGE)).unwrap();
+        assert_eq!(result.build_system, crate::BuildSystemId::SwiftPm);
+        assert_eq!(result.confidence, 1.0);
+        assert!(lang.detect("Package.resolved", None).is_none());
+    }
+
+    #[test]
+    fn test_detect_tools_version() {
+        let lang = SwiftLanguage;
+        assert_eq!(
+            lang.detect_version(Some(VAPOR_PACKAGE)),
+            Some("5.9".to_string())
+        );
+        assert_eq!(
+            lang.detect_version(Some("// swift-tools-version: 6.0\n")),
+            Some("6.0".to_string())
+        );
+    }
+
+    #[test]
+    fn test_parse_dependencies() {
+        let lang = SwiftLanguage;
+        let internal_paths = vec![std::path::PathBuf::from("../Shared")];
+        let deps = lang.parse_dependencies(VAPOR_PACKAGE, &internal_paths);
+
+        assert_eq!(deps.detected_by, DetectionMethod::Deterministic);
+        assert_eq!(deps.external_deps.len(), 1);
+        assert_eq!(deps.external_deps[0].name, "vapor/vapor");
+        assert_eq!(deps.external_deps[0].version.as_deref(), Some("4.89.0"));
+        assert_eq!(deps.internal_deps.len(), 1);
+        assert_eq!(deps.internal_deps[0].name, "Shared");
+    }
+}
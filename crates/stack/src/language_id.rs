@@ -14,6 +14,10 @@ crate::define_id_enum! {
         PHP => "php" : "PHP",
         Cpp => "c++" : "C++",
         Elixir => "elixir" : "Elixir",
+        Swift => "swift" : "Swift",
+        Scala => "scala" : "Scala",
+        Haskell => "haskell" : "Haskell",
+        Dart => "dart" : "Dart",
     }
 }
 
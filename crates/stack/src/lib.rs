@@ -10,6 +10,8 @@ pub mod id_enum_macro;
 pub mod build_system_id;
 pub mod buildsystem;
 pub mod detection;
+#[cfg(test)]
+mod detector_check;
 pub mod framework;
 pub mod framework_id;
 pub mod language;
@@ -29,5 +31,5 @@ pub use language::{
 };
 pub use language_id::LanguageId;
 pub use orchestrator::{MonorepoOrchestrator, OrchestratorId};
-pub use registry::StackRegistry;
+pub use registry::{register_build_system, register_language, StackRegistry};
 pub use runtime_id::RuntimeId;
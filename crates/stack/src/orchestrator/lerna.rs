@@ -3,6 +3,7 @@
 use super::{MonorepoOrchestrator, OrchestratorId, Package, WorkspaceStructure};
 use crate::buildsystem::{BuildSystem, NpmBuildSystem};
 use anyhow::{Context, Result};
+use peelbox_core::fs::FileSystem;
 use serde_json::Value;
 use std::path::Path;
 
@@ -29,8 +30,12 @@ impl MonorepoOrchestrator for LernaOrchestrator {
         "Lerna"
     }
 
-    fn workspace_structure(&self, repo_path: &Path) -> Result<WorkspaceStructure> {
-        parse_workspace_structure(repo_path)
+    fn workspace_structure(
+        &self,
+        repo_path: &Path,
+        fs: &dyn FileSystem,
+    ) -> Result<WorkspaceStructure> {
+        parse_workspace_structure(repo_path, fs)
     }
 
     fn build_command(&self, package: &Package) -> String {
@@ -38,9 +43,10 @@ impl MonorepoOrchestrator for LernaOrchestrator {
     }
 }
 
-fn parse_workspace_structure(repo_path: &Path) -> Result<WorkspaceStructure> {
+fn parse_workspace_structure(repo_path: &Path, fs: &dyn FileSystem) -> Result<WorkspaceStructure> {
     let lerna_json_path = repo_path.join("lerna.json");
-    let lerna_content = std::fs::read_to_string(&lerna_json_path)
+    let lerna_content = fs
+        .read_to_string(&lerna_json_path)
         .with_context(|| format!("Failed to read {}", lerna_json_path.display()))?;
 
     let lerna_config: Value =
@@ -60,10 +66,10 @@ fn parse_workspace_structure(repo_path: &Path) -> Result<WorkspaceStructure> {
     };
 
     for pattern in patterns {
-        let workspace_paths = npm.glob_workspace_pattern(repo_path, &pattern)?;
+        let workspace_paths = npm.glob_workspace_pattern(repo_path, &pattern, fs)?;
         for workspace_path in workspace_paths {
             let pkg_json = workspace_path.join("package.json");
-            if let Ok(pkg_content) = std::fs::read_to_string(&pkg_json) {
+            if let Ok(pkg_content) = fs.read_to_string(&pkg_json) {
                 if let Ok((name, is_application)) = npm.parse_package_metadata(&pkg_content) {
                     packages.push(Package {
                         path: workspace_path,
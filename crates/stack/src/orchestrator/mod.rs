@@ -5,6 +5,7 @@
 //! (e.g., Turborepo works with npm/yarn/pnpm).
 
 use anyhow::Result;
+use peelbox_core::fs::FileSystem;
 use std::path::PathBuf;
 
 /// Package within a workspace
@@ -38,8 +39,12 @@ pub trait MonorepoOrchestrator: Send + Sync {
     /// Human-readable name
     fn name(&self) -> &'static str;
 
-    /// Parse workspace structure (PR9+)
-    fn workspace_structure(&self, _repo_path: &std::path::Path) -> Result<WorkspaceStructure> {
+    /// Parse workspace structure (PR9+), reading the workspace's files through `fs`
+    fn workspace_structure(
+        &self,
+        _repo_path: &std::path::Path,
+        _fs: &dyn FileSystem,
+    ) -> Result<WorkspaceStructure> {
         unimplemented!(
             "workspace_structure not yet implemented for {}",
             self.name()
@@ -3,8 +3,9 @@
 use super::{MonorepoOrchestrator, OrchestratorId, Package, WorkspaceStructure};
 use crate::buildsystem::{BuildSystem, NpmBuildSystem};
 use anyhow::{Context, Result};
+use peelbox_core::fs::{FileSystem, FileType};
 use serde_json::Value;
-use std::path::Path;
+use std::path::{Path, PathBuf};
 
 pub struct NxOrchestrator;
 
@@ -29,8 +30,12 @@ impl MonorepoOrchestrator for NxOrchestrator {
         "Nx"
     }
 
-    fn workspace_structure(&self, repo_path: &Path) -> Result<WorkspaceStructure> {
-        parse_workspace_structure(repo_path)
+    fn workspace_structure(
+        &self,
+        repo_path: &Path,
+        fs: &dyn FileSystem,
+    ) -> Result<WorkspaceStructure> {
+        parse_workspace_structure(repo_path, fs)
     }
 
     fn build_command(&self, package: &Package) -> String {
@@ -38,9 +43,10 @@ impl MonorepoOrchestrator for NxOrchestrator {
     }
 }
 
-fn parse_workspace_structure(repo_path: &Path) -> Result<WorkspaceStructure> {
+fn parse_workspace_structure(repo_path: &Path, fs: &dyn FileSystem) -> Result<WorkspaceStructure> {
     let nx_json_path = repo_path.join("nx.json");
-    let _nx_content = std::fs::read_to_string(&nx_json_path)
+    let _nx_content = fs
+        .read_to_string(&nx_json_path)
         .with_context(|| format!("Failed to read {}", nx_json_path.display()))?;
 
     let npm = NpmBuildSystem;
@@ -48,14 +54,14 @@ fn parse_workspace_structure(repo_path: &Path) -> Result<WorkspaceStructure> {
 
     // Try workspace.json first (Nx >= 13)
     let workspace_json_path = repo_path.join("workspace.json");
-    if workspace_json_path.exists() {
-        if let Ok(content) = std::fs::read_to_string(&workspace_json_path) {
+    if fs.exists(&workspace_json_path) {
+        if let Ok(content) = fs.read_to_string(&workspace_json_path) {
             if let Ok(workspace) = serde_json::from_str::<Value>(&content) {
                 if let Some(projects) = workspace["projects"].as_object() {
                     for (name, project_config) in projects {
                         if let Some(root) = project_config.as_str() {
                             let project_path = repo_path.join(root);
-                            if let Ok(pkg) = parse_project(&project_path, name, &npm) {
+                            if let Ok(pkg) = parse_project(&project_path, name, &npm, fs) {
                                 packages.push(pkg);
                             }
                         }
@@ -65,17 +71,40 @@ fn parse_workspace_structure(repo_path: &Path) -> Result<WorkspaceStructure> {
         }
     }
 
+    // Without workspace.json, every project.json is a project (any language)
+    if packages.is_empty() {
+        for project_json in find_project_files(repo_path, fs) {
+            let project_path = project_json.parent().unwrap_or(repo_path);
+            let name = fs
+                .read_to_string(&project_json)
+                .ok()
+                .and_then(|content| serde_json::from_str::<Value>(&content).ok())
+                .and_then(|project| project["name"].as_str().map(String::from))
+                .or_else(|| {
+                    project_path
+                        .file_name()
+                        .and_then(|n| n.to_str())
+                        .map(String::from)
+                })
+                .unwrap_or_else(|| "app".to_string());
+            if let Ok(pkg) = parse_project(project_path, &name, &npm, fs) {
+                packages.push(pkg);
+            }
+        }
+    }
+
     // Try package.json workspaces (Nx < 13 or npm workspaces integration)
     if packages.is_empty() {
         let package_json_path = repo_path.join("package.json");
-        if package_json_path.exists() {
-            if let Ok(content) = std::fs::read_to_string(&package_json_path) {
+        if fs.exists(&package_json_path) {
+            if let Ok(content) = fs.read_to_string(&package_json_path) {
                 let patterns = npm.parse_workspace_patterns(&content).unwrap_or_default();
                 for pattern in patterns {
-                    if let Ok(workspace_paths) = npm.glob_workspace_pattern(repo_path, &pattern) {
+                    if let Ok(workspace_paths) = npm.glob_workspace_pattern(repo_path, &pattern, fs)
+                    {
                         for workspace_path in workspace_paths {
                             let pkg_json = workspace_path.join("package.json");
-                            if let Ok(pkg_content) = std::fs::read_to_string(&pkg_json) {
+                            if let Ok(pkg_content) = fs.read_to_string(&pkg_json) {
                                 if let Ok((name, is_application)) =
                                     npm.parse_package_metadata(&pkg_content)
                                 {
@@ -99,19 +128,52 @@ fn parse_workspace_structure(repo_path: &Path) -> Result<WorkspaceStructure> {
     })
 }
 
-fn parse_project(project_path: &Path, name: &str, npm: &NpmBuildSystem) -> Result<Package> {
+/// project.json files below the root, skipping installed dependencies and hidden dirs
+fn find_project_files(repo_path: &Path, fs: &dyn FileSystem) -> Vec<PathBuf> {
+    let mut found = Vec::new();
+    let mut pending = vec![repo_path.to_path_buf()];
+
+    while let Some(dir) = pending.pop() {
+        let Ok(entries) = fs.read_dir(&dir) else {
+            continue;
+        };
+        for entry in entries {
+            let file_name = entry.file_name();
+            let path = entry.path().to_path_buf();
+            if entry.file_type() == FileType::Directory {
+                if file_name != "node_modules" && !file_name.starts_with('.') {
+                    pending.push(path);
+                }
+            } else if file_name == "project.json" && dir != repo_path {
+                found.push(path);
+            }
+        }
+    }
+
+    found.sort();
+    found
+}
+
+fn parse_project(
+    project_path: &Path,
+    name: &str,
+    npm: &NpmBuildSystem,
+    fs: &dyn FileSystem,
+) -> Result<Package> {
     // Check project.json first (Nx >= 13 project configuration)
     let project_json_path = project_path.join("project.json");
-    let is_application = if project_json_path.exists() {
-        let content = std::fs::read_to_string(&project_json_path)?;
+    let is_application = if fs.exists(&project_json_path) {
+        let content = fs.read_to_string(&project_json_path)?;
         let project: Value = serde_json::from_str(&content)?;
-        // Nx applications have "serve" or "start" targets
-        project["targets"]["serve"].is_object() || project["targets"]["start"].is_object()
+        // Nx applications declare projectType or have "serve"/"start" targets
+        project["projectType"] == "application"
+            || project["targets"]["serve"].is_object()
+            || project["targets"]["start"].is_object()
     } else {
         // Fallback to package.json analysis via build system
         let package_json_path = project_path.join("package.json");
-        if package_json_path.exists() {
-            let content = std::fs::read_to_string(&package_json_path)?;
+        if fs.exists(&package_json_path) {
+            let content = fs.read_to_string(&package_json_path)?;
             // Use npm build system to detect application (checks for "start" script)
             npm.parse_package_metadata(&content)?.1
         } else {
@@ -125,3 +187,47 @@ fn parse_project(project_path: &Path, name: &str, npm: &NpmBuildSystem) -> Resul
         is_application,
     })
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+    use tempfile::TempDir;
+
+    fn write(dir: &Path, path: &str, content: &str) {
+        let path = dir.join(path);
+        std::fs::create_dir_all(path.parent().unwrap()).unwrap();
+        std::fs::write(path, content).unwrap();
+    }
+
+    #[test]
+    fn test_project_json_discovery_skips_node_modules() {
+        let dir = TempDir::new().unwrap();
+        write(dir.path(), "nx.json", "{}");
+        write(
+            dir.path(),
+            "apps/api/project.json",
+            r#"{"name": "api", "projectType": "application"}"#,
+        );
+        write(
+            dir.path(),
+            "libs/shared/project.json",
+            r#"{"name": "shared", "projectType": "library"}"#,
+        );
+        write(
+            dir.path(),
+            "node_modules/some-dep/project.json",
+            r#"{"name": "some-dep"}"#,
+        );
+
+        let structure = parse_workspace_structure(dir.path(), &RealFileSystem).unwrap();
+        let packages: Vec<(&str, bool)> = structure
+            .packages
+            .iter()
+            .map(|p| (p.name.as_str(), p.is_application))
+            .collect();
+
+        assert_eq!(structure.orchestrator, Some(OrchestratorId::Nx));
+        assert_eq!(packages, vec![("api", true), ("shared", false)]);
+    }
+}
@@ -3,6 +3,7 @@
 use super::{MonorepoOrchestrator, OrchestratorId, Package, WorkspaceStructure};
 use crate::buildsystem::{BuildSystem, NpmBuildSystem};
 use anyhow::{Context, Result};
+use peelbox_core::fs::FileSystem;
 use std::path::Path;
 
 pub struct TurborepoOrchestrator;
@@ -28,8 +29,12 @@ impl MonorepoOrchestrator for TurborepoOrchestrator {
         "Turborepo"
     }
 
-    fn workspace_structure(&self, repo_path: &Path) -> Result<WorkspaceStructure> {
-        parse_workspace_structure(repo_path)
+    fn workspace_structure(
+        &self,
+        repo_path: &Path,
+        fs: &dyn FileSystem,
+    ) -> Result<WorkspaceStructure> {
+        parse_workspace_structure(repo_path, fs)
     }
 
     fn build_command(&self, package: &Package) -> String {
@@ -37,9 +42,10 @@ impl MonorepoOrchestrator for TurborepoOrchestrator {
     }
 }
 
-fn parse_workspace_structure(repo_path: &Path) -> Result<WorkspaceStructure> {
+fn parse_workspace_structure(repo_path: &Path, fs: &dyn FileSystem) -> Result<WorkspaceStructure> {
     let package_json_path = repo_path.join("package.json");
-    let content = std::fs::read_to_string(&package_json_path)
+    let content = fs
+        .read_to_string(&package_json_path)
         .with_context(|| format!("Failed to read {}", package_json_path.display()))?;
 
     let npm = NpmBuildSystem;
@@ -50,11 +56,11 @@ fn parse_workspace_structure(repo_path: &Path) -> Result<WorkspaceStructure> {
     let mut packages = Vec::new();
 
     for pattern in workspace_patterns {
-        let workspace_paths = npm.glob_workspace_pattern(repo_path, &pattern)?;
+        let workspace_paths = npm.glob_workspace_pattern(repo_path, &pattern, fs)?;
 
         for workspace_path in workspace_paths {
             let pkg_json = workspace_path.join("package.json");
-            if let Ok(pkg_content) = std::fs::read_to_string(&pkg_json) {
+            if let Ok(pkg_content) = fs.read_to_string(&pkg_json) {
                 if let Ok((name, is_application)) = npm.parse_package_metadata(&pkg_content) {
                     packages.push(Package {
                         path: workspace_path,
@@ -8,6 +8,37 @@ use std::collections::HashMap;
 use std::path::Path;
 use std::sync::{Arc, RwLock};
 
+/// Languages and build systems registered process-wide, added to every registry
+/// `StackRegistry::with_defaults` builds afterwards
+static PLUGINS: RwLock<Vec<Plugin>> = RwLock::new(Vec::new());
+
+enum Plugin {
+    Language(Arc<dyn LanguageDefinition>),
+    BuildSystem(Arc<dyn BuildSystem>),
+}
+
+/// Registers a language for every registry built by `StackRegistry::with_defaults` from now
+/// on, replacing a built-in language with the same id
+///
+/// Call it before starting detection, e.g. at the top of `main`.
+pub fn register_language(language: Arc<dyn LanguageDefinition>) {
+    PLUGINS.write().unwrap().push(Plugin::Language(language));
+}
+
+/// Registers a build system - the detector finding a stack's manifests - for every registry
+/// built by `StackRegistry::with_defaults` from now on, replacing a built-in build system
+/// with the same id
+///
+/// When several build systems detect manifests in one directory, the manifest pattern with
+/// the highest `priority` wins. On a tie a `Custom` build system wins over a built-in one,
+/// and otherwise the build system whose name sorts first.
+pub fn register_build_system(build_system: Arc<dyn BuildSystem>) {
+    PLUGINS
+        .write()
+        .unwrap()
+        .push(Plugin::BuildSystem(build_system));
+}
+
 /// Registry for all technology stack components.
 ///
 /// Provides unified detection and lookup for languages, build systems, frameworks,
@@ -54,53 +85,71 @@ impl StackRegistry {
     /// 2. All known build systems (Cargo, Maven, Gradle, npm, etc.)
     /// 3. All known frameworks (Spring Boot, Next.js, Django, etc.)
     /// 4. All known orchestrators (Turborepo, Nx, Lerna)
-    /// 5. LLM fallback implementations (if llm_client provided)
+    /// 5. Languages and build systems from `register_language` and `register_build_system`,
+    ///    in registration order
+    /// 6. LLM fallback implementations (if llm_client provided)
     ///
     /// This order ensures deterministic detection tries first, with LLM as last resort.
     pub fn with_defaults(llm_client: Option<Arc<dyn LLMClient>>) -> Self {
         let mut registry = Self::new();
         registry.llm_client = llm_client.clone();
 
-        {
-            let mut languages = registry.languages.write().unwrap();
-            languages.insert(LanguageId::Rust, Arc::new(RustLanguage));
-            languages.insert(LanguageId::Java, Arc::new(JavaLanguage));
-            languages.insert(LanguageId::JavaScript, Arc::new(JavaScriptLanguage));
-            languages.insert(LanguageId::Python, Arc::new(PythonLanguage));
-            languages.insert(LanguageId::Go, Arc::new(GoLanguage));
-            languages.insert(LanguageId::CSharp, Arc::new(DotNetLanguage));
-            languages.insert(LanguageId::Ruby, Arc::new(RubyLanguage));
-            languages.insert(LanguageId::PHP, Arc::new(PhpLanguage));
-            languages.insert(LanguageId::Cpp, Arc::new(CppLanguage));
-            languages.insert(LanguageId::Elixir, Arc::new(ElixirLanguage));
+        let languages: [Arc<dyn LanguageDefinition>; 16] = [
+            Arc::new(RustLanguage),
+            Arc::new(JavaLanguage),
+            Arc::new(KotlinLanguage),
+            Arc::new(JavaScriptLanguage),
+            Arc::new(PythonLanguage),
+            Arc::new(GoLanguage),
+            Arc::new(DotNetLanguage),
+            Arc::new(RubyLanguage),
+            Arc::new(PhpLanguage),
+            Arc::new(CppLanguage),
+            Arc::new(ElixirLanguage),
+            Arc::new(SwiftLanguage),
+            Arc::new(ScalaLanguage),
+            Arc::new(HaskellLanguage),
+            Arc::new(DenoLanguage),
+            Arc::new(DartLanguage),
+        ];
+        for language in languages {
+            registry.add_language(language);
         }
 
-        {
-            let mut build_systems = registry.build_systems.write().unwrap();
-            for id in BuildSystemId::all_variants() {
-                let bs: Arc<dyn BuildSystem> = match id {
-                    BuildSystemId::Cargo => Arc::new(CargoBuildSystem),
-                    BuildSystemId::Maven => Arc::new(MavenBuildSystem),
-                    BuildSystemId::Gradle => Arc::new(GradleBuildSystem),
-                    BuildSystemId::Npm => Arc::new(NpmBuildSystem),
-                    BuildSystemId::Yarn => Arc::new(YarnBuildSystem),
-                    BuildSystemId::Pnpm => Arc::new(PnpmBuildSystem),
-                    BuildSystemId::Bun => Arc::new(BunBuildSystem),
-                    BuildSystemId::Pip => Arc::new(PipBuildSystem),
-                    BuildSystemId::Poetry => Arc::new(PoetryBuildSystem),
-                    BuildSystemId::Pipenv => Arc::new(PipenvBuildSystem),
-                    BuildSystemId::GoMod => Arc::new(GoModBuildSystem),
-                    BuildSystemId::DotNet => Arc::new(DotNetBuildSystem),
-                    BuildSystemId::Composer => Arc::new(ComposerBuildSystem),
-                    BuildSystemId::Bundler => Arc::new(BundlerBuildSystem),
-                    BuildSystemId::CMake => Arc::new(CMakeBuildSystem),
-                    BuildSystemId::Make => Arc::new(MakeBuildSystem),
-                    BuildSystemId::Meson => Arc::new(MesonBuildSystem),
-                    BuildSystemId::Mix => Arc::new(MixBuildSystem),
-                    BuildSystemId::Custom(_) => continue,
-                };
-                build_systems.insert(id.clone(), bs);
-            }
+        for id in BuildSystemId::all_variants() {
+            let bs: Arc<dyn BuildSystem> = match id {
+                BuildSystemId::Cargo => Arc::new(CargoBuildSystem),
+                BuildSystemId::Maven => Arc::new(MavenBuildSystem),
+                BuildSystemId::Gradle => Arc::new(GradleBuildSystem),
+                BuildSystemId::Npm => Arc::new(NpmBuildSystem),
+                BuildSystemId::Yarn => Arc::new(YarnBuildSystem),
+                BuildSystemId::Pnpm => Arc::new(PnpmBuildSystem),
+                BuildSystemId::Bun => Arc::new(BunBuildSystem),
+                BuildSystemId::Pip => Arc::new(PipBuildSystem),
+                BuildSystemId::Poetry => Arc::new(PoetryBuildSystem),
+                BuildSystemId::Pipenv => Arc::new(PipenvBuildSystem),
+                BuildSystemId::Pdm => Arc::new(PdmBuildSystem),
+                BuildSystemId::Hatch => Arc::new(HatchBuildSystem),
+                BuildSystemId::Conda => Arc::new(CondaBuildSystem),
+                BuildSystemId::GoMod => Arc::new(GoModBuildSystem),
+                BuildSystemId::DotNet => Arc::new(DotNetBuildSystem),
+                BuildSystemId::Composer => Arc::new(ComposerBuildSystem),
+                BuildSystemId::Bundler => Arc::new(BundlerBuildSystem),
+                BuildSystemId::CMake => Arc::new(CMakeBuildSystem),
+                BuildSystemId::Make => Arc::new(MakeBuildSystem),
+                BuildSystemId::Meson => Arc::new(MesonBuildSystem),
+                BuildSystemId::Mix => Arc::new(MixBuildSystem),
+                BuildSystemId::SwiftPm => Arc::new(SwiftPmBuildSystem),
+                BuildSystemId::Sbt => Arc::new(SbtBuildSystem),
+                BuildSystemId::Mill => Arc::new(MillBuildSystem),
+                BuildSystemId::Stack => Arc::new(StackBuildSystem),
+                BuildSystemId::Cabal => Arc::new(CabalBuildSystem),
+                BuildSystemId::Deno => Arc::new(DenoBuildSystem),
+                BuildSystemId::DartPub => Arc::new(DartPubBuildSystem),
+                BuildSystemId::Bazel => Arc::new(BazelBuildSystem),
+                BuildSystemId::Custom(_) => continue,
+            };
+            registry.add_build_system(bs);
         }
 
         for id in FrameworkId::all_variants() {
@@ -109,6 +158,9 @@ impl StackRegistry {
                 FrameworkId::Quarkus => Box::new(QuarkusFramework),
                 FrameworkId::Micronaut => Box::new(MicronautFramework),
                 FrameworkId::Ktor => Box::new(KtorFramework),
+                FrameworkId::Nuxt => Box::new(NuxtFramework),
+                FrameworkId::SvelteKit => Box::new(SvelteKitFramework),
+                FrameworkId::Remix => Box::new(RemixFramework),
                 FrameworkId::Express => Box::new(ExpressFramework),
                 FrameworkId::NextJs => Box::new(NextJsFramework),
                 FrameworkId::NestJs => Box::new(NestJsFramework),
@@ -116,16 +168,35 @@ impl StackRegistry {
                 FrameworkId::Django => Box::new(DjangoFramework),
                 FrameworkId::Flask => Box::new(FlaskFramework),
                 FrameworkId::FastApi => Box::new(FastApiFramework),
+                FrameworkId::Tornado => Box::new(TornadoFramework),
+                FrameworkId::Starlette => Box::new(StarletteFramework),
                 FrameworkId::Rails => Box::new(RailsFramework),
                 FrameworkId::Sinatra => Box::new(SinatraFramework),
                 FrameworkId::ActixWeb => Box::new(ActixFramework),
                 FrameworkId::Axum => Box::new(AxumFramework),
                 FrameworkId::Gin => Box::new(GinFramework),
                 FrameworkId::Echo => Box::new(EchoFramework),
+                FrameworkId::GorillaMux => Box::new(GorillaMuxFramework),
+                FrameworkId::Chi => Box::new(ChiFramework),
                 FrameworkId::AspNetCore => Box::new(AspNetFramework),
                 FrameworkId::Laravel => Box::new(LaravelFramework),
                 FrameworkId::Symfony => Box::new(SymfonyFramework),
                 FrameworkId::Phoenix => Box::new(PhoenixFramework),
+                FrameworkId::Vapor => Box::new(VaporFramework),
+                FrameworkId::Hummingbird => Box::new(HummingbirdFramework),
+                FrameworkId::Play => Box::new(PlayFramework),
+                FrameworkId::AkkaHttp => Box::new(AkkaHttpFramework),
+                FrameworkId::Http4s => Box::new(Http4sFramework),
+                FrameworkId::Yesod => Box::new(YesodFramework),
+                FrameworkId::Servant => Box::new(ServantFramework),
+                FrameworkId::Scotty => Box::new(ScottyFramework),
+                FrameworkId::Oak => Box::new(OakFramework),
+                FrameworkId::Fresh => Box::new(FreshFramework),
+                FrameworkId::Hono => Box::new(HonoFramework),
+                FrameworkId::Elysia => Box::new(ElysiaFramework),
+                FrameworkId::Shelf => Box::new(ShelfFramework),
+                FrameworkId::DartFrog => Box::new(DartFrogFramework),
+                FrameworkId::Flutter => Box::new(FlutterFramework),
                 FrameworkId::Custom(_) => continue,
             };
             registry.frameworks.insert(id.clone(), fw);
@@ -141,6 +212,15 @@ impl StackRegistry {
             .orchestrators
             .insert(OrchestratorId::Lerna, Arc::new(LernaOrchestrator));
 
+        for plugin in PLUGINS.read().unwrap().iter() {
+            match plugin {
+                Plugin::Language(language) => registry.add_language(language.clone()),
+                Plugin::BuildSystem(build_system) => {
+                    registry.add_build_system(build_system.clone())
+                }
+            }
+        }
+
         if let Some(llm) = llm_client {
             registry.languages.write().unwrap().insert(
                 LanguageId::Custom("__llm_fallback__".to_string()),
@@ -166,6 +246,22 @@ impl StackRegistry {
         registry
     }
 
+    /// Adds a language under its id, replacing any language already registered there
+    pub fn add_language(&self, language: Arc<dyn LanguageDefinition>) {
+        self.languages
+            .write()
+            .unwrap()
+            .insert(language.id(), language);
+    }
+
+    /// Adds a build system under its id, replacing any build system already registered there
+    pub fn add_build_system(&self, build_system: Arc<dyn BuildSystem>) {
+        self.build_systems
+            .write()
+            .unwrap()
+            .insert(build_system.id(), build_system);
+    }
+
     pub fn register_llm_language(&self, language_id: LanguageId) {
         if let Some(llm) = &self.llm_client {
             let mut languages = self.languages.write().unwrap();
@@ -198,6 +294,16 @@ impl StackRegistry {
         self.build_systems.read().unwrap().get(&id).cloned()
     }
 
+    /// Registered build systems, in no particular order
+    pub fn all_build_systems(&self) -> Vec<Arc<dyn BuildSystem>> {
+        self.build_systems
+            .read()
+            .unwrap()
+            .values()
+            .cloned()
+            .collect()
+    }
+
     pub fn get_language(&self, id: LanguageId) -> Option<Arc<dyn LanguageDefinition>> {
         self.languages.read().unwrap().get(&id).cloned()
     }
@@ -349,6 +455,8 @@ impl StackRegistry {
             RuntimeId::PHP => Box::new(crate::runtime::PhpRuntime),
             RuntimeId::DotNet => Box::new(crate::runtime::DotNetRuntime),
             RuntimeId::BEAM => Box::new(crate::runtime::BeamRuntime),
+            RuntimeId::Deno => Box::new(crate::runtime::DenoRuntime),
+            RuntimeId::Bun => Box::new(crate::runtime::BunRuntime),
             RuntimeId::Native => Box::new(crate::runtime::NativeRuntime),
             RuntimeId::Custom(_) => match llm_client {
                 Some(llm) => Box::new(crate::runtime::LLMRuntime::new(llm)),
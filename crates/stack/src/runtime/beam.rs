@@ -1,5 +1,6 @@
 use super::{HealthCheck, Runtime, RuntimeConfig};
 use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
 use regex::Regex;
 use std::collections::HashSet;
 use std::path::{Path, PathBuf};
@@ -7,14 +8,14 @@ use std::path::{Path, PathBuf};
 pub struct BeamRuntime;
 
 impl BeamRuntime {
-    fn extract_env_vars(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_env_vars(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut env_vars = HashSet::new();
         let env_pattern = Regex::new(r#"System\.get_env\("([A-Z_][A-Z0-9_]*)"\)"#).unwrap();
 
         for file in files {
             if let Some(ext) = file.extension() {
                 if ext == "ex" || ext == "exs" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         for cap in env_pattern.captures_iter(&content) {
                             if let Some(var) = cap.get(1) {
                                 env_vars.insert(var.as_str().to_string());
@@ -30,14 +31,14 @@ impl BeamRuntime {
         vars
     }
 
-    fn extract_ports(&self, files: &[PathBuf]) -> Option<u16> {
+    fn extract_ports(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Option<u16> {
         let cowboy_pattern = Regex::new(r":cowboy.*port:\s*(\d+)").unwrap();
         let ranch_pattern = Regex::new(r":ranch.*port:\s*(\d+)").unwrap();
 
         for file in files {
             if let Some(ext) = file.extension() {
                 if ext == "ex" || ext == "exs" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         if let Some(cap) = cowboy_pattern.captures(&content) {
                             if let Some(port_str) = cap.get(1) {
                                 if let Ok(port) = port_str.as_str().parse::<u16>() {
@@ -59,12 +60,12 @@ impl BeamRuntime {
         None
     }
 
-    fn extract_native_deps(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_native_deps(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut deps = HashSet::new();
 
         for file in files {
             if file.file_name().is_some_and(|n| n == "mix.exs") {
-                if let Ok(content) = std::fs::read_to_string(file) {
+                if let Ok(content) = fs.read_to_string(file) {
                     if content.contains(":nif") || content.contains("rustler") {
                         deps.insert("build-base".to_string());
                     }
@@ -87,10 +88,11 @@ impl Runtime for BeamRuntime {
         &self,
         files: &[PathBuf],
         framework: Option<&dyn Framework>,
+        fs: &dyn FileSystem,
     ) -> Option<RuntimeConfig> {
-        let env_vars = self.extract_env_vars(files);
-        let native_deps = self.extract_native_deps(files);
-        let detected_port = self.extract_ports(files);
+        let env_vars = self.extract_env_vars(files, fs);
+        let native_deps = self.extract_native_deps(files, fs);
+        let detected_port = self.extract_ports(files, fs);
 
         let port =
             detected_port.or_else(|| framework.and_then(|f| f.default_ports().first().copied()));
@@ -127,6 +129,7 @@ impl Runtime for BeamRuntime {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         _service_path: &Path,
         _manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
     ) -> Vec<String> {
         let available = wolfi_index.get_versions("elixir");
 
@@ -142,6 +145,7 @@ impl Runtime for BeamRuntime {
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::RealFileSystem;
     use std::fs;
     use tempfile::TempDir;
 
@@ -198,7 +202,7 @@ api_key = System.get_env("API_KEY")
 
         let runtime = BeamRuntime;
         let files = vec![ex_file];
-        let env_vars = runtime.extract_env_vars(&files);
+        let env_vars = runtime.extract_env_vars(&files, &RealFileSystem);
 
         assert_eq!(env_vars, vec!["API_KEY", "DATABASE_URL"]);
     }
@@ -217,7 +221,7 @@ api_key = System.get_env("API_KEY")
 
         let runtime = BeamRuntime;
         let files = vec![ex_file];
-        let port = runtime.extract_ports(&files);
+        let port = runtime.extract_ports(&files, &RealFileSystem);
 
         assert_eq!(port, Some(4000));
     }
@@ -240,7 +244,7 @@ end
 
         let runtime = BeamRuntime;
         let files = vec![mix_file];
-        let deps = runtime.extract_native_deps(&files);
+        let deps = runtime.extract_native_deps(&files, &RealFileSystem);
 
         assert_eq!(deps, vec!["build-base".to_string()]);
     }
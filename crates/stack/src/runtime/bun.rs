@@ -0,0 +1,147 @@
+use super::{HealthCheck, Runtime, RuntimeConfig};
+use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::collections::HashSet;
+use std::path::{Path, PathBuf};
+
+pub struct BunRuntime;
+
+impl BunRuntime {
+    fn is_source(file: &Path) -> bool {
+        file.extension().is_some_and(|ext| {
+            ext == "ts" || ext == "tsx" || ext == "js" || ext == "mjs" || ext == "cjs"
+        })
+    }
+
+    fn extract_env_vars(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
+        let mut env_vars = HashSet::new();
+        // Bun exposes the environment as both process.env and Bun.env
+        let env_pattern =
+            Regex::new(r"(?:process|Bun)\.env\.([A-Z_][A-Z0-9_]*)").expect("valid regex");
+
+        for file in files.iter().filter(|f| Self::is_source(f)) {
+            if let Ok(content) = fs.read_to_string(file) {
+                for cap in env_pattern.captures_iter(&content) {
+                    if let Some(var) = cap.get(1) {
+                        env_vars.insert(var.as_str().to_string());
+                    }
+                }
+            }
+        }
+
+        let mut vars: Vec<String> = env_vars.into_iter().collect();
+        vars.sort();
+        vars
+    }
+
+    fn extract_ports(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Option<u16> {
+        // Elysia `.listen(3000)` and `Bun.serve({ port: 3000, ... })`
+        let listen_pattern = Regex::new(r"\.listen\s*\(\s*(\d+)\s*\)").expect("valid regex");
+        let serve_pattern =
+            Regex::new(r"Bun\.serve\s*\(\s*\{[^}]*\bport\s*:\s*(\d+)").expect("valid regex");
+
+        for file in files.iter().filter(|f| Self::is_source(f)) {
+            if let Ok(content) = fs.read_to_string(file) {
+                if let Some(port) = listen_pattern
+                    .captures(&content)
+                    .or_else(|| serve_pattern.captures(&content))
+                    .and_then(|cap| cap[1].parse::<u16>().ok())
+                {
+                    return Some(port);
+                }
+            }
+        }
+        None
+    }
+}
+
+impl Runtime for BunRuntime {
+    fn name(&self) -> &str {
+        "Bun"
+    }
+
+    fn try_extract(
+        &self,
+        files: &[PathBuf],
+        framework: Option<&dyn Framework>,
+        fs: &dyn FileSystem,
+    ) -> Option<RuntimeConfig> {
+        let env_vars = self.extract_env_vars(files, fs);
+        let detected_port = self.extract_ports(files, fs);
+
+        let port =
+            detected_port.or_else(|| framework.and_then(|f| f.default_ports().first().copied()));
+        let health = framework.and_then(|f| {
+            f.health_endpoints(&[]).first().map(|endpoint| HealthCheck {
+                endpoint: endpoint.to_string(),
+            })
+        });
+
+        // The entrypoint comes from package.json scripts during assembly
+        Some(RuntimeConfig {
+            entrypoint: None,
+            port,
+            env_vars,
+            health,
+            native_deps: vec![],
+        })
+    }
+
+    fn runtime_base_image(&self, version: Option<&str>) -> String {
+        let version = version.unwrap_or("1");
+        format!("oven/bun:{}-alpine", version)
+    }
+
+    fn required_packages(&self) -> Vec<String> {
+        vec![]
+    }
+
+    fn start_command(&self, entrypoint: &Path) -> String {
+        format!("bun {}", entrypoint.display())
+    }
+
+    fn runtime_packages(
+        &self,
+        _wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        _service_path: &Path,
+        _manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
+    ) -> Vec<String> {
+        // Wolfi ships a single unversioned `bun` package
+        vec!["bun".to_string()]
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+    use std::fs;
+    use tempfile::TempDir;
+
+    #[test]
+    fn test_bun_runtime_name() {
+        assert_eq!(BunRuntime.name(), "Bun");
+    }
+
+    #[test]
+    fn test_extract_env_vars_and_port() {
+        let temp_dir = TempDir::new().unwrap();
+        let index = temp_dir.path().join("index.ts");
+        fs::write(
+            &index,
+            r#"const secret = Bun.env.JWT_SECRET;
+const db = process.env.DATABASE_URL;
+Bun.serve({ port: 4000, fetch: handler });
+"#,
+        )
+        .unwrap();
+
+        let config = BunRuntime
+            .try_extract(&[index], None, &RealFileSystem)
+            .unwrap();
+        assert_eq!(config.env_vars, vec!["DATABASE_URL", "JWT_SECRET"]);
+        assert_eq!(config.port, Some(4000));
+    }
+}
@@ -0,0 +1,147 @@
+use super::{HealthCheck, Runtime, RuntimeConfig};
+use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
+use regex::Regex;
+use std::collections::HashSet;
+use std::path::{Path, PathBuf};
+
+pub struct DenoRuntime;
+
+impl DenoRuntime {
+    fn is_source(file: &Path) -> bool {
+        file.extension()
+            .is_some_and(|ext| ext == "ts" || ext == "tsx" || ext == "js" || ext == "mjs")
+    }
+
+    fn extract_env_vars(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
+        let mut env_vars = HashSet::new();
+        let env_pattern =
+            Regex::new(r#"Deno\.env\.get\(\s*["']([A-Z_][A-Z0-9_]*)["']"#).expect("valid regex");
+
+        for file in files.iter().filter(|f| Self::is_source(f)) {
+            if let Ok(content) = fs.read_to_string(file) {
+                for cap in env_pattern.captures_iter(&content) {
+                    if let Some(var) = cap.get(1) {
+                        env_vars.insert(var.as_str().to_string());
+                    }
+                }
+            }
+        }
+
+        let mut vars: Vec<String> = env_vars.into_iter().collect();
+        vars.sort();
+        vars
+    }
+
+    fn extract_ports(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Option<u16> {
+        // Oak `app.listen({ port: 8000 })` and `Deno.serve({ port: 8000 }, handler)`
+        let port_pattern =
+            Regex::new(r"(?:listen|Deno\.serve)\s*\(\s*\{[^}]*\bport\s*:\s*(\d{2,5})")
+                .expect("valid regex");
+
+        for file in files.iter().filter(|f| Self::is_source(f)) {
+            if let Ok(content) = fs.read_to_string(file) {
+                if let Some(port) = port_pattern
+                    .captures(&content)
+                    .and_then(|cap| cap[1].parse::<u16>().ok())
+                {
+                    return Some(port);
+                }
+            }
+        }
+        None
+    }
+}
+
+impl Runtime for DenoRuntime {
+    fn name(&self) -> &str {
+        "Deno"
+    }
+
+    fn try_extract(
+        &self,
+        files: &[PathBuf],
+        framework: Option<&dyn Framework>,
+        fs: &dyn FileSystem,
+    ) -> Option<RuntimeConfig> {
+        let env_vars = self.extract_env_vars(files, fs);
+        let detected_port = self.extract_ports(files, fs);
+
+        let port =
+            detected_port.or_else(|| framework.and_then(|f| f.default_ports().first().copied()));
+        let health = framework.and_then(|f| {
+            f.health_endpoints(&[]).first().map(|endpoint| HealthCheck {
+                endpoint: endpoint.to_string(),
+            })
+        });
+
+        // The entrypoint comes from deno.json tasks during assembly
+        Some(RuntimeConfig {
+            entrypoint: None,
+            port,
+            env_vars,
+            health,
+            native_deps: vec![],
+        })
+    }
+
+    fn runtime_base_image(&self, version: Option<&str>) -> String {
+        match version {
+            Some(version) => format!("denoland/deno:alpine-{}", version),
+            None => "denoland/deno:alpine".to_string(),
+        }
+    }
+
+    fn required_packages(&self) -> Vec<String> {
+        vec![]
+    }
+
+    fn start_command(&self, entrypoint: &Path) -> String {
+        format!(
+            "deno run --allow-net --allow-env --allow-read {}",
+            entrypoint.display()
+        )
+    }
+
+    fn runtime_packages(
+        &self,
+        _wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
+        _service_path: &Path,
+        _manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
+    ) -> Vec<String> {
+        vec!["deno".to_string()]
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use peelbox_core::fs::RealFileSystem;
+    use std::fs;
+    use tempfile::TempDir;
+
+    #[test]
+    fn test_deno_runtime_name() {
+        assert_eq!(DenoRuntime.name(), "Deno");
+    }
+
+    #[test]
+    fn test_extract_env_vars_and_port() {
+        let temp_dir = TempDir::new().unwrap();
+        let main = temp_dir.path().join("main.ts");
+        fs::write(
+            &main,
+            r#"const db = Deno.env.get("DATABASE_URL");
+Deno.serve({ port: 8080, hostname: "0.0.0.0" }, handler);
+"#,
+        )
+        .unwrap();
+
+        let config = DenoRuntime
+            .try_extract(&[main], None, &RealFileSystem)
+            .unwrap();
+        assert_eq!(config.env_vars, vec!["DATABASE_URL"]);
+        assert_eq!(config.port, Some(8080));
+    }
+}
@@ -1,5 +1,6 @@
 use super::{HealthCheck, Runtime, RuntimeConfig};
 use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
 use regex::Regex;
 use serde_json::Value;
 use std::collections::HashSet;
@@ -8,7 +9,7 @@ use std::path::{Path, PathBuf};
 pub struct DotNetRuntime;
 
 impl DotNetRuntime {
-    fn extract_env_vars(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_env_vars(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut env_vars = HashSet::new();
         let env_pattern =
             Regex::new(r#"Environment\.GetEnvironmentVariable\("([A-Z_][A-Z0-9_]*)"\)"#).unwrap();
@@ -16,7 +17,7 @@ impl DotNetRuntime {
         for file in files {
             if let Some(ext) = file.extension() {
                 if ext == "cs" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         for cap in env_pattern.captures_iter(&content) {
                             if let Some(var) = cap.get(1) {
                                 env_vars.insert(var.as_str().to_string());
@@ -32,12 +33,12 @@ impl DotNetRuntime {
         vars
     }
 
-    fn extract_ports(&self, files: &[PathBuf]) -> Option<u16> {
+    fn extract_ports(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Option<u16> {
         let port_re = Regex::new(r":(\d+)").unwrap();
 
         for file in files {
             if file.file_name().is_some_and(|n| n == "launchSettings.json") {
-                if let Ok(content) = std::fs::read_to_string(file) {
+                if let Ok(content) = fs.read_to_string(file) {
                     if let Ok(json) = serde_json::from_str::<Value>(&content) {
                         if let Some(profiles) = json["profiles"].as_object() {
                             for profile in profiles.values() {
@@ -59,13 +60,13 @@ impl DotNetRuntime {
         None
     }
 
-    fn extract_native_deps(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_native_deps(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut deps = HashSet::new();
 
         for file in files {
             if let Some(ext) = file.extension() {
                 if ext == "csproj" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         if content.contains("<NativeLibrary")
                             || content.contains("Interop")
                             || content.contains("PInvoke")
@@ -104,10 +105,11 @@ impl Runtime for DotNetRuntime {
         &self,
         files: &[PathBuf],
         framework: Option<&dyn Framework>,
+        fs: &dyn FileSystem,
     ) -> Option<RuntimeConfig> {
-        let env_vars = self.extract_env_vars(files);
-        let native_deps = self.extract_native_deps(files);
-        let detected_port = self.extract_ports(files);
+        let env_vars = self.extract_env_vars(files, fs);
+        let native_deps = self.extract_native_deps(files, fs);
+        let detected_port = self.extract_ports(files, fs);
         let entrypoint = self.extract_entrypoint(files);
 
         let port =
@@ -145,8 +147,9 @@ impl Runtime for DotNetRuntime {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Vec<String> {
-        let requested = self.detect_version(service_path, manifest_content);
+        let requested = self.detect_version(service_path, manifest_content, fs);
         let available = wolfi_index.get_versions("dotnet");
 
         let base_version = requested
@@ -178,11 +181,12 @@ impl DotNetRuntime {
         &self,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Option<String> {
         if let Some(content) = manifest_content {
             for csproj in ["*.csproj", "*.fsproj", "*.vbproj"] {
                 let pattern_path = service_path.join(csproj);
-                if pattern_path.parent().map(|p| p.exists()).unwrap_or(false) {
+                if pattern_path.parent().map(|p| fs.exists(p)).unwrap_or(false) {
                     return self.parse_target_framework(content);
                 }
             }
@@ -200,7 +204,7 @@ impl DotNetRuntime {
                 {
                     if framework.starts_with("net") {
                         let version = framework.trim_start_matches("net");
-                        if let Some(major) = version.chars().next() {
+                        if let Some(major) = version.split(['.', '-']).next() {
                             return Some(major.to_string());
                         }
                     }
@@ -214,6 +218,7 @@ impl DotNetRuntime {
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::RealFileSystem;
     use std::fs;
     use tempfile::TempDir;
 
@@ -271,7 +276,7 @@ var apiKey = Environment.GetEnvironmentVariable("API_KEY");
 
         let runtime = DotNetRuntime;
         let files = vec![cs_file];
-        let env_vars = runtime.extract_env_vars(&files);
+        let env_vars = runtime.extract_env_vars(&files, &RealFileSystem);
 
         assert_eq!(env_vars, vec!["API_KEY", "DATABASE_URL"]);
     }
@@ -296,7 +301,7 @@ var apiKey = Environment.GetEnvironmentVariable("API_KEY");
 
         let runtime = DotNetRuntime;
         let files = vec![settings_file];
-        let port = runtime.extract_ports(&files);
+        let port = runtime.extract_ports(&files, &RealFileSystem);
 
         assert_eq!(port, Some(5000));
     }
@@ -319,7 +324,7 @@ var apiKey = Environment.GetEnvironmentVariable("API_KEY");
 
         let runtime = DotNetRuntime;
         let files = vec![csproj_file];
-        let deps = runtime.extract_native_deps(&files);
+        let deps = runtime.extract_native_deps(&files, &RealFileSystem);
 
         assert_eq!(deps, vec!["build-base".to_string()]);
     }
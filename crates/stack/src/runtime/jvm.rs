@@ -1,5 +1,6 @@
 use super::{HealthCheck, Runtime, RuntimeConfig};
 use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
 use regex::Regex;
 use std::collections::HashSet;
 use std::path::{Path, PathBuf};
@@ -7,14 +8,14 @@ use std::path::{Path, PathBuf};
 pub struct JvmRuntime;
 
 impl JvmRuntime {
-    fn extract_env_vars(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_env_vars(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut env_vars = HashSet::new();
         let env_pattern = Regex::new(r#"System\.getenv\("([A-Z_][A-Z0-9_]*)"\)"#).unwrap();
 
         for file in files {
             if let Some(ext) = file.extension() {
-                if ext == "java" || ext == "kt" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                if ext == "java" || ext == "kt" || ext == "scala" {
+                    if let Ok(content) = fs.read_to_string(file) {
                         for cap in env_pattern.captures_iter(&content) {
                             if let Some(var) = cap.get(1) {
                                 env_vars.insert(var.as_str().to_string());
@@ -30,14 +31,14 @@ impl JvmRuntime {
         vars
     }
 
-    fn extract_ports(&self, files: &[PathBuf]) -> Option<u16> {
+    fn extract_ports(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Option<u16> {
         let server_socket_pattern = Regex::new(r"ServerSocket\s*\(\s*(\d+)\s*\)").unwrap();
         let jetty_pattern = Regex::new(r"\.setPort\s*\(\s*(\d+)\s*\)").unwrap();
 
         for file in files {
             if let Some(ext) = file.extension() {
-                if ext == "java" || ext == "kt" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                if ext == "java" || ext == "kt" || ext == "scala" {
+                    if let Ok(content) = fs.read_to_string(file) {
                         if let Some(cap) = server_socket_pattern.captures(&content) {
                             if let Some(port_str) = cap.get(1) {
                                 if let Ok(port) = port_str.as_str().parse::<u16>() {
@@ -59,12 +60,12 @@ impl JvmRuntime {
         None
     }
 
-    fn extract_native_deps(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_native_deps(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut deps = HashSet::new();
 
         for file in files {
             if file.file_name().is_some_and(|n| n == "pom.xml") {
-                if let Ok(content) = std::fs::read_to_string(file) {
+                if let Ok(content) = fs.read_to_string(file) {
                     if content.contains("<packaging>so</packaging>")
                         || content.contains("<packaging>jni</packaging>")
                         || content.contains("jna")
@@ -77,7 +78,7 @@ impl JvmRuntime {
                 .file_name()
                 .is_some_and(|n| n == "build.gradle" || n == "build.gradle.kts")
             {
-                if let Ok(content) = std::fs::read_to_string(file) {
+                if let Ok(content) = fs.read_to_string(file) {
                     if content.contains("jni") || content.contains("jna") {
                         deps.insert("build-base".to_string());
                     }
@@ -100,10 +101,11 @@ impl Runtime for JvmRuntime {
         &self,
         files: &[PathBuf],
         framework: Option<&dyn Framework>,
+        fs: &dyn FileSystem,
     ) -> Option<RuntimeConfig> {
-        let env_vars = self.extract_env_vars(files);
-        let native_deps = self.extract_native_deps(files);
-        let detected_port = self.extract_ports(files);
+        let env_vars = self.extract_env_vars(files, fs);
+        let native_deps = self.extract_native_deps(files, fs);
+        let detected_port = self.extract_ports(files, fs);
 
         let port =
             detected_port.or_else(|| framework.and_then(|f| f.default_ports().first().copied()));
@@ -143,8 +145,9 @@ impl Runtime for JvmRuntime {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Vec<String> {
-        let requested = self.detect_version(service_path, manifest_content);
+        let requested = self.detect_version(service_path, manifest_content, fs);
         let available = wolfi_index.get_versions("openjdk");
 
         let base_version = requested
@@ -163,16 +166,17 @@ impl JvmRuntime {
         &self,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Option<String> {
         if let Some(content) = manifest_content {
             let pom_path = service_path.join("pom.xml");
-            if pom_path.exists() {
+            if fs.exists(&pom_path) {
                 return self.parse_pom_version(content);
             }
 
             let gradle_path = service_path.join("build.gradle");
             let gradle_kts_path = service_path.join("build.gradle.kts");
-            if gradle_path.exists() || gradle_kts_path.exists() {
+            if fs.exists(&gradle_path) || fs.exists(&gradle_kts_path) {
                 return self.parse_gradle_version(content);
             }
         }
@@ -215,6 +219,7 @@ impl JvmRuntime {
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::RealFileSystem;
     use std::fs;
     use tempfile::TempDir;
 
@@ -280,7 +285,7 @@ mod tests {
 
         let runtime = JvmRuntime;
         let files = vec![java_file];
-        let env_vars = runtime.extract_env_vars(&files);
+        let env_vars = runtime.extract_env_vars(&files, &RealFileSystem);
 
         assert_eq!(env_vars, vec!["API_KEY", "DATABASE_URL"]);
     }
@@ -304,7 +309,7 @@ mod tests {
 
         let runtime = JvmRuntime;
         let files = vec![java_file];
-        let port = runtime.extract_ports(&files);
+        let port = runtime.extract_ports(&files, &RealFileSystem);
 
         assert_eq!(port, Some(8080));
     }
@@ -327,7 +332,7 @@ mod tests {
 
         let runtime = JvmRuntime;
         let files = vec![java_file];
-        let port = runtime.extract_ports(&files);
+        let port = runtime.extract_ports(&files, &RealFileSystem);
 
         assert_eq!(port, Some(9090));
     }
@@ -353,7 +358,7 @@ mod tests {
 
         let runtime = JvmRuntime;
         let files = vec![pom_file];
-        let deps = runtime.extract_native_deps(&files);
+        let deps = runtime.extract_native_deps(&files, &RealFileSystem);
 
         assert_eq!(deps, vec!["build-base".to_string()]);
     }
@@ -374,7 +379,7 @@ mod tests {
 
         let runtime = JvmRuntime;
         let files = vec![gradle_file];
-        let deps = runtime.extract_native_deps(&files);
+        let deps = runtime.extract_native_deps(&files, &RealFileSystem);
 
         assert_eq!(deps, vec!["build-base".to_string()]);
     }
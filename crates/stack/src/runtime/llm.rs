@@ -1,5 +1,6 @@
 use super::{HealthCheck, Runtime, RuntimeConfig};
 use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
 use peelbox_llm::{ChatMessage, LLMClient, LLMRequest};
 use serde::{Deserialize, Serialize};
 use std::path::{Path, PathBuf};
@@ -46,6 +47,7 @@ impl Runtime for LLMRuntime {
         &self,
         files: &[PathBuf],
         framework: Option<&dyn Framework>,
+        _fs: &dyn FileSystem,
     ) -> Option<RuntimeConfig> {
         let port = framework.and_then(|f| f.default_ports().first().copied());
         let health = framework.and_then(|f| {
@@ -148,6 +150,7 @@ Response format ONLY:
         _wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         _service_path: &Path,
         _manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
     ) -> Vec<String> {
         vec!["glibc".to_string(), "ca-certificates".to_string()]
     }
@@ -156,6 +159,7 @@ Response format ONLY:
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::RealFileSystem;
     use peelbox_llm::MockLLMClient;
 
     #[test]
@@ -205,7 +209,7 @@ mod tests {
         client.add_response(MockResponse::text(json));
 
         let runtime = LLMRuntime::new(client);
-        let result = runtime.try_extract(&[PathBuf::from("test.txt")], None);
+        let result = runtime.try_extract(&[PathBuf::from("test.txt")], None, &RealFileSystem);
 
         assert!(result.is_none());
     }
@@ -1,9 +1,12 @@
 use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
 use peelbox_core::output::schema::HealthCheck;
 use serde::{Deserialize, Serialize};
 use std::path::{Path, PathBuf};
 
 pub mod beam;
+pub mod bun;
+pub mod deno;
 pub mod dotnet;
 pub mod jvm;
 pub mod llm;
@@ -25,12 +28,13 @@ pub struct RuntimeConfig {
 pub trait Runtime: Send + Sync {
     fn name(&self) -> &str;
 
-    /// Try to extract runtime configuration (parse known files)
+    /// Try to extract runtime configuration (parse known files read through `fs`)
     /// Returns None if config cannot be extracted deterministically
     fn try_extract(
         &self,
         files: &[PathBuf],
         framework: Option<&dyn Framework>,
+        fs: &dyn FileSystem,
     ) -> Option<RuntimeConfig>;
 
     /// Get runtime base image with optional version
@@ -49,10 +53,13 @@ pub trait Runtime: Send + Sync {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Vec<String>;
 }
 
 pub use beam::BeamRuntime;
+pub use bun::BunRuntime;
+pub use deno::DenoRuntime;
 pub use dotnet::DotNetRuntime;
 pub use jvm::JvmRuntime;
 pub use llm::LLMRuntime;
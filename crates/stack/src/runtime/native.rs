@@ -1,5 +1,6 @@
 use super::{HealthCheck, Runtime, RuntimeConfig};
 use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
 use regex::Regex;
 use std::collections::HashSet;
 use std::path::{Path, PathBuf};
@@ -7,14 +8,14 @@ use std::path::{Path, PathBuf};
 pub struct NativeRuntime;
 
 impl NativeRuntime {
-    fn extract_ports_from_source(&self, files: &[PathBuf]) -> Option<u16> {
+    fn extract_ports_from_source(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Option<u16> {
         let bind_pattern = Regex::new(r"bind\s*\([^,)]*,\s*[^,)]*,\s*(\d+)\s*\)").unwrap();
         let listen_pattern = Regex::new(r"listen\s*\(\s*(\d+)\s*\)").unwrap();
 
         for file in files {
             if let Some(ext) = file.extension() {
                 if ext == "c" || ext == "cpp" || ext == "cc" || ext == "rs" || ext == "go" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         if let Some(cap) = bind_pattern.captures(&content) {
                             if let Some(port_str) = cap.get(1) {
                                 if let Ok(port) = port_str.as_str().parse::<u16>() {
@@ -36,7 +37,11 @@ impl NativeRuntime {
         None
     }
 
-    fn extract_metadata_hints(&self, files: &[PathBuf]) -> (Option<u16>, Vec<String>) {
+    fn extract_metadata_hints(
+        &self,
+        files: &[PathBuf],
+        fs: &dyn FileSystem,
+    ) -> (Option<u16>, Vec<String>) {
         let mut port = None;
         let deps = HashSet::new();
 
@@ -45,7 +50,7 @@ impl NativeRuntime {
 
         for file in files {
             if file.file_name().is_some_and(|n| n == "Cargo.toml") {
-                if let Ok(content) = std::fs::read_to_string(file) {
+                if let Ok(content) = fs.read_to_string(file) {
                     if let Some(cap) = cargo_port_pattern.captures(&content) {
                         if let Some(port_str) = cap.get(1) {
                             if let Ok(p) = port_str.as_str().parse::<u16>() {
@@ -55,7 +60,7 @@ impl NativeRuntime {
                     }
                 }
             } else if file.file_name().is_some_and(|n| n == "go.mod") {
-                if let Ok(content) = std::fs::read_to_string(file) {
+                if let Ok(content) = fs.read_to_string(file) {
                     if let Some(cap) = go_port_pattern.captures(&content) {
                         if let Some(port_str) = cap.get(1) {
                             if let Ok(p) = port_str.as_str().parse::<u16>() {
@@ -82,9 +87,10 @@ impl Runtime for NativeRuntime {
         &self,
         files: &[PathBuf],
         framework: Option<&dyn Framework>,
+        fs: &dyn FileSystem,
     ) -> Option<RuntimeConfig> {
-        let source_port = self.extract_ports_from_source(files);
-        let (metadata_port, native_deps) = self.extract_metadata_hints(files);
+        let source_port = self.extract_ports_from_source(files, fs);
+        let (metadata_port, native_deps) = self.extract_metadata_hints(files, fs);
 
         let detected_port = source_port.or(metadata_port);
         let port =
@@ -119,16 +125,23 @@ impl Runtime for NativeRuntime {
     fn runtime_packages(
         &self,
         _wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
-        _service_path: &Path,
+        service_path: &Path,
         _manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Vec<String> {
-        vec!["glibc".to_string(), "ca-certificates".to_string()]
+        let mut packages = vec!["glibc".to_string(), "ca-certificates".to_string()];
+        // GHC links executables against libgmp and libffi dynamically
+        if crate::buildsystem::cabal::package_description(service_path, fs).is_some() {
+            packages.extend(["gmp".to_string(), "libffi".to_string()]);
+        }
+        packages
     }
 }
 
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::RealFileSystem;
     use std::fs;
     use tempfile::TempDir;
 
@@ -175,7 +188,7 @@ int main() {
 
         let runtime = NativeRuntime;
         let files = vec![c_file];
-        let port = runtime.extract_ports_from_source(&files);
+        let port = runtime.extract_ports_from_source(&files, &RealFileSystem);
 
         assert_eq!(port, Some(8080));
     }
@@ -196,7 +209,7 @@ fn main() {
 
         let runtime = NativeRuntime;
         let files = vec![rs_file];
-        let port = runtime.extract_ports_from_source(&files);
+        let port = runtime.extract_ports_from_source(&files, &RealFileSystem);
 
         assert_eq!(port, Some(3000));
     }
@@ -217,7 +230,7 @@ name = "myapp"
 
         let runtime = NativeRuntime;
         let files = vec![cargo_file];
-        let (port, _deps) = runtime.extract_metadata_hints(&files);
+        let (port, _deps) = runtime.extract_metadata_hints(&files, &RealFileSystem);
 
         assert_eq!(port, Some(9000));
     }
@@ -237,7 +250,7 @@ module myapp
 
         let runtime = NativeRuntime;
         let files = vec![go_mod_file];
-        let (port, _deps) = runtime.extract_metadata_hints(&files);
+        let (port, _deps) = runtime.extract_metadata_hints(&files, &RealFileSystem);
 
         assert_eq!(port, Some(8000));
     }
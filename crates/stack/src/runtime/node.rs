@@ -1,5 +1,6 @@
 use super::{HealthCheck, Runtime, RuntimeConfig};
 use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
 use regex::Regex;
 use std::collections::HashSet;
 use std::path::{Path, PathBuf};
@@ -7,14 +8,14 @@ use std::path::{Path, PathBuf};
 pub struct NodeRuntime;
 
 impl NodeRuntime {
-    fn extract_env_vars(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_env_vars(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut env_vars = HashSet::new();
         let env_pattern = Regex::new(r"process\.env\.([A-Z_][A-Z0-9_]*)").unwrap();
 
         for file in files {
             if let Some(ext) = file.extension() {
                 if ext == "js" || ext == "ts" || ext == "mjs" || ext == "cjs" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         for cap in env_pattern.captures_iter(&content) {
                             if let Some(var) = cap.get(1) {
                                 env_vars.insert(var.as_str().to_string());
@@ -30,13 +31,13 @@ impl NodeRuntime {
         vars
     }
 
-    fn extract_ports(&self, files: &[PathBuf]) -> Option<u16> {
+    fn extract_ports(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Option<u16> {
         let listen_pattern = Regex::new(r"\.listen\s*\(\s*(\d+)\s*\)").unwrap();
         let port_arg_pattern = Regex::new(r"--port\s+(\d+)").unwrap();
 
         for file in files {
             if file.file_name().is_some_and(|n| n == "package.json") {
-                if let Ok(content) = std::fs::read_to_string(file) {
+                if let Ok(content) = fs.read_to_string(file) {
                     for cap in port_arg_pattern.captures_iter(&content) {
                         if let Some(port_str) = cap.get(1) {
                             if let Ok(port) = port_str.as_str().parse::<u16>() {
@@ -47,7 +48,7 @@ impl NodeRuntime {
                 }
             } else if let Some(ext) = file.extension() {
                 if ext == "js" || ext == "ts" || ext == "mjs" || ext == "cjs" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         for cap in listen_pattern.captures_iter(&content) {
                             if let Some(port_str) = cap.get(1) {
                                 if let Ok(port) = port_str.as_str().parse::<u16>() {
@@ -72,9 +73,10 @@ impl Runtime for NodeRuntime {
         &self,
         files: &[PathBuf],
         framework: Option<&dyn Framework>,
+        fs: &dyn FileSystem,
     ) -> Option<RuntimeConfig> {
-        let env_vars = self.extract_env_vars(files);
-        let detected_port = self.extract_ports(files);
+        let env_vars = self.extract_env_vars(files, fs);
+        let detected_port = self.extract_ports(files, fs);
 
         let port =
             detected_port.or_else(|| framework.and_then(|f| f.default_ports().first().copied()));
@@ -84,8 +86,13 @@ impl Runtime for NodeRuntime {
             })
         });
 
+        // Without a framework command, assembly falls back to package.json scripts.start
+        let entrypoint = framework
+            .and_then(|f| f.entrypoint_command())
+            .map(|cmd| cmd.join(" "));
+
         Some(RuntimeConfig {
-            entrypoint: None,
+            entrypoint,
             port,
             env_vars,
             health,
@@ -111,8 +118,9 @@ impl Runtime for NodeRuntime {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Vec<String> {
-        let requested = self.detect_version(service_path, manifest_content);
+        let requested = self.detect_version(service_path, manifest_content, fs);
         let available = wolfi_index.get_versions("nodejs");
 
         let version = requested
@@ -130,10 +138,11 @@ impl NodeRuntime {
         &self,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Option<String> {
         for file_name in [".nvmrc", ".node-version"] {
             let path = service_path.join(file_name);
-            if let Ok(content) = std::fs::read_to_string(&path) {
+            if let Ok(content) = fs.read_to_string(&path) {
                 if let Some(ver) = self.normalize_version(&content) {
                     return Some(ver);
                 }
@@ -169,6 +178,7 @@ impl NodeRuntime {
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::RealFileSystem;
     use std::fs;
     use tempfile::TempDir;
 
@@ -219,7 +229,7 @@ mod tests {
 
         let runtime = NodeRuntime;
         let files = vec![js_file];
-        let env_vars = runtime.extract_env_vars(&files);
+        let env_vars = runtime.extract_env_vars(&files, &RealFileSystem);
 
         assert_eq!(env_vars, vec!["API_KEY", "DATABASE_URL", "PORT"]);
     }
@@ -240,7 +250,7 @@ mod tests {
 
         let runtime = NodeRuntime;
         let files = vec![js_file];
-        let port = runtime.extract_ports(&files);
+        let port = runtime.extract_ports(&files, &RealFileSystem);
 
         assert_eq!(port, Some(3000));
     }
@@ -263,7 +273,7 @@ mod tests {
 
         let runtime = NodeRuntime;
         let files = vec![pkg_file];
-        let port = runtime.extract_ports(&files);
+        let port = runtime.extract_ports(&files, &RealFileSystem);
 
         assert_eq!(port, Some(8080));
     }
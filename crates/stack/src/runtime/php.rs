@@ -1,5 +1,6 @@
 use super::{HealthCheck, Runtime, RuntimeConfig};
 use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
 use regex::Regex;
 use std::collections::HashSet;
 use std::path::{Path, PathBuf};
@@ -19,14 +20,14 @@ impl PhpRuntime {
         None
     }
 
-    fn extract_env_vars(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_env_vars(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut env_vars = HashSet::new();
         let env_pattern = Regex::new(r#"\$_ENV\[['"]([A-Z_][A-Z0-9_]*)['"]\]"#).unwrap();
 
         for file in files {
             if let Some(ext) = file.extension() {
                 if ext == "php" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         for cap in env_pattern.captures_iter(&content) {
                             if let Some(var) = cap.get(1) {
                                 env_vars.insert(var.as_str().to_string());
@@ -42,12 +43,12 @@ impl PhpRuntime {
         vars
     }
 
-    fn extract_native_deps(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_native_deps(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut deps = HashSet::new();
 
         for file in files {
             if file.file_name().is_some_and(|n| n == "composer.json") {
-                if let Ok(content) = std::fs::read_to_string(file) {
+                if let Ok(content) = fs.read_to_string(file) {
                     if content.contains("ext-")
                         || content.contains("imagick")
                         || content.contains("gd")
@@ -73,10 +74,11 @@ impl Runtime for PhpRuntime {
         &self,
         files: &[PathBuf],
         framework: Option<&dyn Framework>,
+        fs: &dyn FileSystem,
     ) -> Option<RuntimeConfig> {
         let entrypoint = self.find_entrypoint(files);
-        let env_vars = self.extract_env_vars(files);
-        let native_deps = self.extract_native_deps(files);
+        let env_vars = self.extract_env_vars(files, fs);
+        let native_deps = self.extract_native_deps(files, fs);
 
         let port = framework.and_then(|f| f.default_ports().first().copied());
         let health = framework.and_then(|f| {
@@ -114,6 +116,7 @@ impl Runtime for PhpRuntime {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        _fs: &dyn FileSystem,
     ) -> Vec<String> {
         let requested = self.detect_version(service_path, manifest_content);
         let available = wolfi_index.get_versions("php");
@@ -229,6 +232,7 @@ impl PhpRuntime {
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::RealFileSystem;
     use std::fs;
     use tempfile::TempDir;
 
@@ -284,7 +288,7 @@ $key = $_ENV["API_KEY"];
 
         let runtime = PhpRuntime;
         let files = vec![php_file];
-        let env_vars = runtime.extract_env_vars(&files);
+        let env_vars = runtime.extract_env_vars(&files, &RealFileSystem);
 
         assert_eq!(env_vars, vec!["API_KEY", "DATABASE_URL"]);
     }
@@ -308,7 +312,7 @@ $key = $_ENV["API_KEY"];
 
         let runtime = PhpRuntime;
         let files = vec![composer_file];
-        let deps = runtime.extract_native_deps(&files);
+        let deps = runtime.extract_native_deps(&files, &RealFileSystem);
 
         assert_eq!(deps, vec!["build-base".to_string()]);
     }
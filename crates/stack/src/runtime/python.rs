@@ -1,5 +1,7 @@
 use super::{HealthCheck, Runtime, RuntimeConfig};
+use crate::buildsystem::CondaEnvironment;
 use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
 use regex::Regex;
 use std::collections::HashSet;
 use std::path::{Path, PathBuf};
@@ -20,7 +22,7 @@ impl PythonRuntime {
         None
     }
 
-    fn extract_env_vars(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_env_vars(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut env_vars = HashSet::new();
         let os_environ_pattern = Regex::new(
             r#"os\.environ(?:\[['"]([A-Z_][A-Z0-9_]*)['"]\]|\.get\(['"]([A-Z_][A-Z0-9_]*)['"])"#,
@@ -30,7 +32,7 @@ impl PythonRuntime {
         for file in files {
             if let Some(ext) = file.extension() {
                 if ext == "py" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         for cap in os_environ_pattern.captures_iter(&content) {
                             if let Some(var) = cap.get(1).or_else(|| cap.get(2)) {
                                 env_vars.insert(var.as_str().to_string());
@@ -46,14 +48,14 @@ impl PythonRuntime {
         vars
     }
 
-    fn extract_ports(&self, files: &[PathBuf]) -> Option<u16> {
+    fn extract_ports(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Option<u16> {
         let app_run_pattern = Regex::new(r"app\.run\s*\([^)]*port\s*=\s*(\d+)").unwrap();
         let listen_pattern = Regex::new(r"\.listen\s*\(\s*(\d+)\s*\)").unwrap();
 
         for file in files {
             if let Some(ext) = file.extension() {
                 if ext == "py" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         if let Some(cap) = app_run_pattern.captures(&content) {
                             if let Some(port_str) = cap.get(1) {
                                 if let Ok(port) = port_str.as_str().parse::<u16>() {
@@ -75,12 +77,12 @@ impl PythonRuntime {
         None
     }
 
-    fn extract_native_deps(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_native_deps(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut deps = HashSet::new();
 
         for file in files {
             if file.file_name().is_some_and(|n| n == "requirements.txt") {
-                if let Ok(content) = std::fs::read_to_string(file) {
+                if let Ok(content) = fs.read_to_string(file) {
                     for line in content.lines() {
                         let lower = line.to_lowercase();
                         if lower.contains("numpy")
@@ -114,10 +116,11 @@ impl Runtime for PythonRuntime {
         &self,
         files: &[PathBuf],
         framework: Option<&dyn Framework>,
+        fs: &dyn FileSystem,
     ) -> Option<RuntimeConfig> {
-        let env_vars = self.extract_env_vars(files);
-        let native_deps = self.extract_native_deps(files);
-        let detected_port = self.extract_ports(files);
+        let env_vars = self.extract_env_vars(files, fs);
+        let native_deps = self.extract_native_deps(files, fs);
+        let detected_port = self.extract_ports(files, fs);
 
         let port =
             detected_port.or_else(|| framework.and_then(|f| f.default_ports().first().copied()));
@@ -163,8 +166,9 @@ impl Runtime for PythonRuntime {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Vec<String> {
-        let requested = self.detect_version(service_path, manifest_content);
+        let requested = self.detect_version(service_path, manifest_content, fs);
         let available = wolfi_index.get_versions("python");
 
         let version = requested
@@ -185,16 +189,17 @@ impl PythonRuntime {
         &self,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Option<String> {
         let runtime_txt = service_path.join("runtime.txt");
-        if let Ok(content) = std::fs::read_to_string(&runtime_txt) {
+        if let Ok(content) = fs.read_to_string(&runtime_txt) {
             if let Some(ver) = self.normalize_version(&content) {
                 return Some(ver);
             }
         }
 
         let python_version = service_path.join(".python-version");
-        if let Ok(content) = std::fs::read_to_string(&python_version) {
+        if let Ok(content) = fs.read_to_string(&python_version) {
             if let Some(ver) = self.normalize_version(&content) {
                 return Some(ver);
             }
@@ -204,6 +209,9 @@ impl PythonRuntime {
             if let Some(ver) = self.parse_pyproject_version(content) {
                 return Some(ver);
             }
+            if let Some(ver) = CondaEnvironment::parse(content).python {
+                return Some(ver);
+            }
         }
 
         None
@@ -249,6 +257,7 @@ impl PythonRuntime {
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::RealFileSystem;
     use std::fs;
     use tempfile::TempDir;
 
@@ -303,7 +312,7 @@ api_key = os.environ.get('API_KEY')
 
         let runtime = PythonRuntime;
         let files = vec![py_file];
-        let env_vars = runtime.extract_env_vars(&files);
+        let env_vars = runtime.extract_env_vars(&files, &RealFileSystem);
 
         assert_eq!(env_vars, vec!["API_KEY", "DATABASE_URL"]);
     }
@@ -325,7 +334,7 @@ if __name__ == '__main__':
 
         let runtime = PythonRuntime;
         let files = vec![py_file];
-        let port = runtime.extract_ports(&files);
+        let port = runtime.extract_ports(&files, &RealFileSystem);
 
         assert_eq!(port, Some(5000));
     }
@@ -345,7 +354,7 @@ server.listen(8000)
 
         let runtime = PythonRuntime;
         let files = vec![py_file];
-        let port = runtime.extract_ports(&files);
+        let port = runtime.extract_ports(&files, &RealFileSystem);
 
         assert_eq!(port, Some(8000));
     }
@@ -366,7 +375,7 @@ requests==2.28.0
 
         let runtime = PythonRuntime;
         let files = vec![req_file];
-        let deps = runtime.extract_native_deps(&files);
+        let deps = runtime.extract_native_deps(&files, &RealFileSystem);
 
         assert_eq!(deps, vec!["build-base".to_string()]);
     }
@@ -386,7 +395,7 @@ requests==2.28.0
 
         let runtime = PythonRuntime;
         let files = vec![req_file];
-        let deps = runtime.extract_native_deps(&files);
+        let deps = runtime.extract_native_deps(&files, &RealFileSystem);
 
         assert_eq!(deps, Vec::<String>::new());
     }
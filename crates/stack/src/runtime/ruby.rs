@@ -1,5 +1,6 @@
 use super::{HealthCheck, Runtime, RuntimeConfig};
 use crate::framework::Framework;
+use peelbox_core::fs::FileSystem;
 use regex::Regex;
 use std::collections::HashSet;
 use std::path::{Path, PathBuf};
@@ -7,14 +8,14 @@ use std::path::{Path, PathBuf};
 pub struct RubyRuntime;
 
 impl RubyRuntime {
-    fn extract_env_vars(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_env_vars(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut env_vars = HashSet::new();
         let env_pattern = Regex::new(r#"ENV\[['"]([A-Z_][A-Z0-9_]*)['"]\]"#).unwrap();
 
         for file in files {
             if let Some(ext) = file.extension() {
                 if ext == "rb" || ext == "ru" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         for cap in env_pattern.captures_iter(&content) {
                             if let Some(var) = cap.get(1) {
                                 env_vars.insert(var.as_str().to_string());
@@ -30,14 +31,14 @@ impl RubyRuntime {
         vars
     }
 
-    fn extract_ports(&self, files: &[PathBuf]) -> Option<u16> {
+    fn extract_ports(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Option<u16> {
         let rack_pattern = Regex::new(r"(?s)Rack::Server.*?Port:\s*(\d+)").unwrap();
         let webrick_pattern = Regex::new(r"(?s)WEBrick.*?:Port\s*=>\s*(\d+)").unwrap();
 
         for file in files {
             if let Some(ext) = file.extension() {
                 if ext == "rb" || ext == "ru" {
-                    if let Ok(content) = std::fs::read_to_string(file) {
+                    if let Ok(content) = fs.read_to_string(file) {
                         if let Some(cap) = rack_pattern.captures(&content) {
                             if let Some(port_str) = cap.get(1) {
                                 if let Ok(port) = port_str.as_str().parse::<u16>() {
@@ -59,12 +60,12 @@ impl RubyRuntime {
         None
     }
 
-    fn extract_native_deps(&self, files: &[PathBuf]) -> Vec<String> {
+    fn extract_native_deps(&self, files: &[PathBuf], fs: &dyn FileSystem) -> Vec<String> {
         let mut deps = HashSet::new();
 
         for file in files {
             if file.file_name().is_some_and(|n| n == "Gemfile") {
-                if let Ok(content) = std::fs::read_to_string(file) {
+                if let Ok(content) = fs.read_to_string(file) {
                     if content.contains("pg")
                         || content.contains("mysql2")
                         || content.contains("nokogiri")
@@ -113,10 +114,11 @@ impl Runtime for RubyRuntime {
         &self,
         files: &[PathBuf],
         framework: Option<&dyn Framework>,
+        fs: &dyn FileSystem,
     ) -> Option<RuntimeConfig> {
-        let env_vars = self.extract_env_vars(files);
-        let native_deps = self.extract_native_deps(files);
-        let detected_port = self.extract_ports(files);
+        let env_vars = self.extract_env_vars(files, fs);
+        let native_deps = self.extract_native_deps(files, fs);
+        let detected_port = self.extract_ports(files, fs);
         let entrypoint = self.find_entrypoint(files);
 
         let port =
@@ -154,8 +156,9 @@ impl Runtime for RubyRuntime {
         wolfi_index: &peelbox_wolfi::WolfiPackageIndex,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Vec<String> {
-        let requested = self.detect_version(service_path, manifest_content);
+        let requested = self.detect_version(service_path, manifest_content, fs);
         let available = wolfi_index.get_versions("ruby");
 
         let version = requested
@@ -167,7 +170,7 @@ impl Runtime for RubyRuntime {
         let mut packages = vec![version.clone()];
 
         let gemfile_path = service_path.join("Gemfile");
-        if gemfile_path.exists() {
+        if fs.exists(&gemfile_path) {
             let ruby_ver_num = version.trim_start_matches("ruby-");
             let bundler_package = format!("ruby{}-bundler", ruby_ver_num);
             if wolfi_index.has_package(&bundler_package) {
@@ -190,9 +193,10 @@ impl RubyRuntime {
         &self,
         service_path: &Path,
         manifest_content: Option<&str>,
+        fs: &dyn FileSystem,
     ) -> Option<String> {
         let ruby_version_file = service_path.join(".ruby-version");
-        if let Ok(content) = std::fs::read_to_string(&ruby_version_file) {
+        if let Ok(content) = fs.read_to_string(&ruby_version_file) {
             if let Some(ver) = self.normalize_version(&content) {
                 return Some(ver);
             }
@@ -227,8 +231,8 @@ impl RubyRuntime {
     fn parse_gemfile_version(&self, content: &str) -> Option<String> {
         for line in content.lines() {
             let trimmed = line.trim();
-            if trimmed.starts_with("ruby") && trimmed.contains('"') {
-                let parts: Vec<&str> = trimmed.split('"').collect();
+            if trimmed.starts_with("ruby ") && trimmed.contains(['"', '\'']) {
+                let parts: Vec<&str> = trimmed.split(['"', '\'']).collect();
                 if parts.len() >= 2 {
                     return self.normalize_version(parts[1]);
                 }
@@ -241,6 +245,7 @@ impl RubyRuntime {
 #[cfg(test)]
 mod tests {
     use super::*;
+    use peelbox_core::fs::RealFileSystem;
     use std::fs;
     use tempfile::TempDir;
 
@@ -291,7 +296,7 @@ api_key = ENV["API_KEY"]
 
         let runtime = RubyRuntime;
         let files = vec![rb_file];
-        let env_vars = runtime.extract_env_vars(&files);
+        let env_vars = runtime.extract_env_vars(&files, &RealFileSystem);
 
         assert_eq!(env_vars, vec!["API_KEY", "DATABASE_URL"]);
     }
@@ -308,7 +313,7 @@ Rack::Server.start(Port: 9292)
 
         let runtime = RubyRuntime;
         let files = vec![rb_file];
-        let port = runtime.extract_ports(&files);
+        let port = runtime.extract_ports(&files, &RealFileSystem);
 
         assert_eq!(port, Some(9292));
     }
@@ -329,7 +334,7 @@ gem 'rails'
 
         let runtime = RubyRuntime;
         let files = vec![gemfile];
-        let deps = runtime.extract_native_deps(&files);
+        let deps = runtime.extract_native_deps(&files, &RealFileSystem);
 
         assert_eq!(deps, vec!["build-base".to_string()]);
     }
@@ -1,14 +1,16 @@
 crate::define_id_enum_with_display! {
     /// Runtime identifier with support for LLM-discovered runtimes
     RuntimeId {
-        JVM => "jvm" : "JVM" | "java" | "kotlin",
+        JVM => "jvm" : "JVM" | "java" | "kotlin" | "scala",
         Node => "node" : "Node" | "node",
         Python => "python" : "Python" | "python",
         Ruby => "ruby" : "Ruby" | "ruby",
         PHP => "php" : "PHP" | "php",
         DotNet => "dotnet" : ".NET" | "dotnet" | "csharp" | "fsharp",
         BEAM => "beam" : "BEAM" | "elixir",
-        Native => "native" : "Native" | "rust" | "c++" | "go",
+        Deno => "deno" : "Deno" | "deno",
+        Bun => "bun" : "Bun" | "bun",
+        Native => "native" : "Native" | "rust" | "c++" | "go" | "swift" | "haskell" | "dart",
     }
 }
 
@@ -24,9 +26,9 @@ mod tests {
 
     #[test]
     fn test_custom_runtime_deserialization() {
-        let deserialized: RuntimeId = serde_json::from_str("\"bun\"").unwrap();
-        assert_eq!(deserialized, RuntimeId::Custom("bun".to_string()));
-        assert_eq!(deserialized.name(), "bun");
+        let deserialized: RuntimeId = serde_json::from_str("\"workerd\"").unwrap();
+        assert_eq!(deserialized, RuntimeId::Custom("workerd".to_string()));
+        assert_eq!(deserialized.name(), "workerd");
     }
 
     #[test]